@@ -0,0 +1,42 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/r4ulcl/api_template/api/middlewares"
+	"github.com/r4ulcl/api_template/database"
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+// ListAnnouncements returns the active announcements.
+//
+// Admins can pass ?all=true to also list scheduled and expired announcements.
+//
+// Returns:
+// - HTTP 500 if the retrieval fails.
+// - JSON array of announcements if successful.
+func (c *Controller) ListAnnouncements(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var (
+		announcements []models.Announcement
+		err           error
+	)
+
+	if r.URL.Query().Get("all") == "true" && middlewares.RoleFromContext(r.Context()) == string(models.AdminRole) {
+		err = c.BC.GetAllRecords(&announcements, database.QueryOptions{Sort: []string{"-created_at"}})
+	} else {
+		announcements, err = c.BC.GetActiveAnnouncements(time.Now())
+	}
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(announcements)
+}
@@ -5,31 +5,96 @@ package controllers
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
+	"log"
 	"net/http"
+	"net/mail"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/r4ulcl/api_template/api/middlewares"
 	"github.com/r4ulcl/api_template/database"
 	"github.com/r4ulcl/api_template/utils"
 	"github.com/r4ulcl/api_template/utils/models"
+	"gorm.io/gorm"
 )
 
 // AuthController Struct for secret and database.BaseController.
 type AuthController struct {
 	Secret string
 	BC     *database.BaseController
+
+	// Mailer sends the verification links. If nil, the links are logged.
+	Mailer *utils.Mailer
+
+	// PublicURL is the base URL of the API used in the verification links.
+	PublicURL string
+
+	// EmailTokenTTL is how long a verification link stays valid.
+	EmailTokenTTL time.Duration
+
+	// LoginByEmail allows logging in with the email address instead of the username.
+	LoginByEmail bool
+
+	// AllowRegistration serves POST /register, letting anyone create a user account.
+	AllowRegistration bool
+
+	// RequireEmailVerification rejects logins of users with an unverified email.
+	RequireEmailVerification bool
+
+	// ServiceTokenTTL is how long the access tokens of service accounts stay valid.
+	ServiceTokenTTL time.Duration
+
+	// Certificates maps TLS client certificates to accounts. If nil, mutual TLS authentication is disabled.
+	Certificates middlewares.CertificateResolver
+
+	// BootstrapToken lets POST /bootstrap create the first admin of an empty
+	// database. It is cleared once used. If empty, the endpoint is disabled.
+	BootstrapToken string
+
+	// DeviceTokenTTL is how long the device tokens of the logins with remember_me
+	// stay exchangeable. If zero, remember_me is ignored.
+	DeviceTokenTTL time.Duration
+
+	// bootstrapMu serializes the uses of BootstrapToken.
+	bootstrapMu sync.Mutex
 }
 
 var (
 	errInvalidInput      = errors.New("invalid input")
+	errInvalidEmail      = errors.New("invalid email address")
 	errUserAlreadyExists = errors.New("user already exists")
+	errEmailInUse        = errors.New("email already in use")
 )
 
+// defaultEmailTokenTTL is used when the AuthController has no EmailTokenTTL.
+const defaultEmailTokenTTL = 24 * time.Hour
+
 // RegisterUser contains the core logic for creating a new user in the DB.
-// It checks for existing usernames, hashes the password, and inserts into the DB.
+// It hashes the password and inserts the user into the DB, overwriting an
+// existing user with the same username (e.g. the admin seeded at startup).
 //
 // Return values:
 //  1. The newly created user (without the raw password).
 //  2. An error if something went wrong.
 func (ac *AuthController) RegisterUser(user models.User) (models.User, error) {
+	return ac.saveUser(user, true)
+}
+
+// CreateUser is RegisterUser without overwriting: it fails with
+// errUserAlreadyExists if the username or email is taken, including by a
+// concurrent registration, so an existing account is never replaced.
+func (ac *AuthController) CreateUser(user models.User) (models.User, error) {
+	return ac.saveUser(user, false)
+}
+
+// saveUser normalizes and hashes a user and stores it, overwriting an existing one only if asked to.
+func (ac *AuthController) saveUser(user models.User, overwrite bool) (models.User, error) {
+	// Usernames are case-insensitive
+	user.Username = utils.NormalizeUsername(user.Username)
+
 	// Basic validation
 	if user.Username == "" || user.Password == "" {
 		return user, errInvalidInput // you can define a sentinel error
@@ -51,7 +116,11 @@ func (ac *AuthController) RegisterUser(user models.User) (models.User, error) {
 	}
 
 	// Create the user in DB
-	if err := ac.BC.CreateOrUpdateRecord(&user, true); err != nil {
+	if _, err := ac.BC.CreateOrUpdateRecord(&user, overwrite); err != nil {
+		if !overwrite && database.IsDuplicateKeyError(err) {
+			return user, errUserAlreadyExists
+		}
+
 		return user, err
 	}
 
@@ -59,14 +128,17 @@ func (ac *AuthController) RegisterUser(user models.User) (models.User, error) {
 	return user, nil
 }
 
-// Register is the HTTP handler that leverages RegisterUser()
+// Register is the HTTP handler that leverages CreateUser()
 // to perform the actual user registration logic.
+//
+// Registered users always get the "user" role. If an email is given, a
+// verification link is sent to it.
 func (ac *AuthController) Register(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
 	// Decode user input
-	var user models.User
-	if err := json.NewDecoder(r.Body).Decode(&user); err != nil {
+	var input models.RegisterRequest
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 
 		if err := json.NewEncoder(w).Encode(models.ErrorResponse{Error: "Invalid input"}); err != nil {
@@ -76,19 +148,38 @@ func (ac *AuthController) Register(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	createdUser, err := ac.RegisterUser(user)
-	switch err {
-	case nil:
+	user := models.User{
+		Username: input.Username,
+		Password: input.Password,
+		Role:     models.UserRole,
+	}
+
+	err := ac.checkNewUser(&user, input.Email)
+	if err == nil {
+		user, err = ac.CreateUser(user)
+	}
+
+	if err == nil && user.Email != nil {
+		err = ac.sendVerification(user.Username, *user.Email)
+	}
+
+	switch {
+	case err == nil:
 		// Successfully created
 		w.WriteHeader(http.StatusCreated)
-		// NOTE: You may want to omit the password from the response here
-		_ = json.NewEncoder(w).Encode(createdUser)
-	case errInvalidInput:
+		_ = json.NewEncoder(w).Encode(user)
+	case errors.Is(err, errInvalidInput):
 		w.WriteHeader(http.StatusBadRequest)
 		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "Username and password cannot be empty"})
-	case errUserAlreadyExists:
+	case errors.Is(err, errInvalidEmail):
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "Invalid email address"})
+	case errors.Is(err, errUserAlreadyExists):
 		w.WriteHeader(http.StatusConflict)
 		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "User already exists"})
+	case errors.Is(err, errEmailInUse):
+		w.WriteHeader(http.StatusConflict)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "Email already in use"})
 	default:
 		// Any other error
 		w.WriteHeader(http.StatusInternalServerError)
@@ -96,15 +187,218 @@ func (ac *AuthController) Register(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// Login existing user.
-func (ac *AuthController) Login(w http.ResponseWriter, r *http.Request) {
+// checkNewUser validates a self-registered user, rejecting a taken username or
+// email before hashing the password. The normalized email, if any, is set on the user.
+func (ac *AuthController) checkNewUser(user *models.User, email string) error {
+	user.Username = utils.NormalizeUsername(user.Username)
+	if user.Username == "" || user.Password == "" {
+		return errInvalidInput
+	}
+
+	var existing models.User
+
+	err := ac.BC.GetRecordsByID(&existing, user.Username)
+	if err == nil {
+		return errUserAlreadyExists
+	} else if !errors.Is(err, database.ErrRecordNotFound) {
+		return err
+	}
+
+	if email == "" {
+		return nil
+	}
+
+	normalized, err := ac.checkEmail(email, "")
+	if err != nil {
+		return err
+	}
+
+	user.Email = &normalized
+
+	return nil
+}
+
+// checkEmail validates an email address and checks that no other user has it.
+//
+// Parameters:
+// - email: The address to check.
+// - username: The user the address is for, who may already have it.
+//
+// Returns the normalized address.
+func (ac *AuthController) checkEmail(email, username string) (string, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email {
+		return "", errInvalidEmail
+	}
+
+	owner, err := ac.BC.GetUserByEmail(email)
+	if err == nil && owner.Username != username {
+		return "", errEmailInUse
+	} else if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", err
+	}
+
+	return email, nil
+}
+
+// sendVerification creates a verification token and emails the link to the user.
+func (ac *AuthController) sendVerification(username, email string) error {
+	ttl := ac.EmailTokenTTL
+	if ttl <= 0 {
+		ttl = defaultEmailTokenTTL
+	}
+
+	token, err := ac.BC.CreateEmailVerification(username, email, ttl)
+	if err != nil {
+		return err
+	}
+
+	link := strings.TrimRight(ac.PublicURL, "/") + "/verify?token=" + url.QueryEscape(token)
+	body := fmt.Sprintf("Hi %s,\n\nConfirm your email address by opening the link below:\n\n%s\n\n"+
+		"The link expires in %s. If you did not request it, ignore this email.\n", username, link, ttl)
+
+	if err := ac.Mailer.Send(email, "Verify your email address", body); err != nil {
+		// The user can ask for a new link, so the registration still succeeds
+		log.Printf("Error sending verification email to %s: %v", email, err)
+	}
+
+	return nil
+}
+
+// VerifyEmail confirms the email address of a user with the token sent by email.
+//
+// Returns:
+// - HTTP 400 if the token is missing, unknown or expired.
+// - JSON confirmation message if successful.
+func (ac *AuthController) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "Missing token"})
+
+		return
+	}
+
+	user, err := ac.BC.VerifyEmail(token)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, database.ErrInvalidToken) {
+			status = http.StatusBadRequest
+		}
+
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(map[string]string{"message": "Email verified", "username": user.Username})
+}
+
+// ResendVerification sends a new verification link to the authenticated user.
+//
+// The body may set a new email address, which then has to be verified again.
+//
+// Returns:
+// - HTTP 400 if the email is invalid or the user has no email.
+// - HTTP 409 if the email belongs to another user or is already verified.
+// - HTTP 202 if the link was sent.
+func (ac *AuthController) ResendVerification(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
 	var input struct {
-		Username string `json:"username"`
-		Password string `json:"password"`
+		Email string `json:"email"`
+	}
+
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "Invalid input"})
+
+			return
+		}
+	}
+
+	var user models.User
+	if err := ac.BC.GetRecordsByID(&user, middlewares.UsernameFromContext(r.Context())); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	status, err := ac.updateEmail(&user, input.Email)
+	if err == nil {
+		err = ac.sendVerification(user.Username, *user.Email)
+	}
+
+	if err != nil {
+		if status == 0 {
+			status = http.StatusInternalServerError
+		}
+
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	_ = json.NewEncoder(w).Encode(map[string]string{"message": "Verification email sent"})
+}
+
+// updateEmail sets a new unverified email on the user, if given, and checks that
+// there is an email left to verify. It returns the HTTP status matching the error.
+func (ac *AuthController) updateEmail(user *models.User, email string) (int, error) {
+	if email == "" {
+		if user.Email == nil {
+			return http.StatusBadRequest, errors.New("no email address to verify")
+		}
+
+		if user.EmailVerifiedAt != nil {
+			return http.StatusConflict, errors.New("email already verified")
+		}
+
+		return 0, nil
 	}
 
+	normalized, err := ac.checkEmail(email, user.Username)
+
+	switch {
+	case errors.Is(err, errInvalidEmail):
+		return http.StatusBadRequest, err
+	case errors.Is(err, errEmailInUse):
+		return http.StatusConflict, err
+	case err != nil:
+		return 0, err
+	}
+
+	if user.Email != nil && *user.Email == normalized {
+		if user.EmailVerifiedAt != nil {
+			return http.StatusConflict, errors.New("email already verified")
+		}
+
+		return 0, nil
+	}
+
+	user.Email = &normalized
+	user.EmailVerifiedAt = nil
+
+	err = ac.BC.DB.Model(user).Select("email", "email_verified_at").Updates(user).Error
+
+	return 0, err
+}
+
+// Login existing user.
+func (ac *AuthController) Login(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var input models.LoginRequest
+
 	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "Invalid input"})
@@ -112,10 +406,19 @@ func (ac *AuthController) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Fetch the user by primary key (username)
+	// Fetch the user by primary key (username), which is stored in lower case,
+	// or by email address when enabled
 	var user models.User
 
-	err := ac.BC.GetRecordsByID(&user, input.Username)
+	var err error
+
+	login := utils.NormalizeUsername(input.Username)
+	if ac.LoginByEmail && strings.Contains(login, "@") {
+		user, err = ac.BC.GetUserByEmail(login)
+	} else {
+		err = ac.BC.GetRecordsByID(&user, login)
+	}
+
 	if err != nil {
 		// Either user not found or other DB error
 		w.WriteHeader(http.StatusUnauthorized)
@@ -132,6 +435,13 @@ func (ac *AuthController) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if ac.RequireEmailVerification && user.Email != nil && user.EmailVerifiedAt == nil {
+		w.WriteHeader(http.StatusForbidden)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "Email address not verified"})
+
+		return
+	}
+
 	// Generate JWT token
 	token, err := utils.GenerateJWT(user.Username, string(user.Role), ac.Secret)
 	if err != nil {
@@ -141,7 +451,83 @@ func (ac *AuthController) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	response := models.JWTResponse{Token: token}
+
+	// Remember the device, if enabled
+	if input.RememberMe && ac.DeviceTokenTTL > 0 {
+		device, deviceToken, err := ac.BC.CreateDeviceToken(user.Username, deviceName(input.Device, r),
+			ac.DeviceTokenTTL)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "Failed to generate device token"})
+
+			return
+		}
+
+		w.Header().Set("Cache-Control", "no-store")
+
+		response.DeviceToken, response.DeviceID = deviceToken, device.ID
+	}
+
 	// Return token
 	w.WriteHeader(http.StatusOK)
-	_ = json.NewEncoder(w).Encode(map[string]string{"token": token})
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// StepUp checks the password of the authenticated user again and returns a
+// fresh token, whose authentication time satisfies the step-up authentication
+// of the destructive actions (see middlewares.StepUp).
+//
+// Returns:
+// - HTTP 400 if the body is invalid or the principal is a service account, which requests a new token instead.
+// - HTTP 401 if the password is wrong.
+// - JSON token response if successful.
+func (ac *AuthController) StepUp(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+
+	if middlewares.AccountTypeFromContext(r.Context()) == models.ServiceAccountType {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{
+			Error: "Service accounts authenticate again by requesting a new token at /oauth/token",
+		})
+
+		return
+	}
+
+	var input struct {
+		Password string `json:"password"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil || input.Password == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "Invalid input"})
+
+		return
+	}
+
+	var user models.User
+	if err := ac.BC.GetRecordsByID(&user, middlewares.UsernameFromContext(r.Context())); err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "Invalid password"})
+
+		return
+	}
+
+	if err := utils.CheckPassword(user.Password, input.Password); err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "Invalid password"})
+
+		return
+	}
+
+	token, err := utils.GenerateJWT(user.Username, string(user.Role), ac.Secret)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "Failed to generate token"})
+
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(models.JWTResponse{Token: token})
 }
@@ -1,12 +1,42 @@
 package controllers
 
 import (
+	"context"
+	"crypto/ed25519"
+	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/r4ulcl/api_template/api/middlewares"
 	"github.com/r4ulcl/api_template/database"
+	"github.com/r4ulcl/api_template/utils"
+	"github.com/r4ulcl/api_template/utils/events"
 	"github.com/r4ulcl/api_template/utils/models"
+	"github.com/r4ulcl/api_template/utils/notify"
+	"github.com/r4ulcl/api_template/utils/protobuf"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// defaultPerPage is the page size used when only "page" is given.
+	defaultPerPage = 50
+
+	// maxPerPage is the largest page size accepted in "per_page".
+	maxPerPage = 1000
+
+	// dbBusyRetryAfter is the Retry-After header, in seconds, of the lists failing while the database is busy.
+	dbBusyRetryAfter = "1"
 )
 
 // Controller provides methods for handling CRUD operations.
@@ -14,34 +44,198 @@ import (
 // It encapsulates a reference to the BaseController for database interactions.
 type Controller struct {
 	BC *database.BaseController
+
+	// Latency tracks per-route latencies. It is optional.
+	Latency *middlewares.SlowRouteTracker
+
+	// Growth flags the tables growing too fast. It is optional.
+	Growth *database.GrowthMonitor
+
+	// Reporter receives panics and 5xx errors. It is optional.
+	Reporter middlewares.ErrorReporter
+
+	// Health checks the database connection and acts as circuit breaker. It is optional.
+	Health *database.HealthChecker
+
+	// Events publishes resource change events. It is optional.
+	Events *events.Bus
+
+	// Notifications sends Slack/Teams notifications based on the stored rules. It is optional.
+	Notifications *notify.Dispatcher
+
+	// Flags caches the feature flags checked per user and role. It is optional.
+	Flags *database.FeatureFlags
+
+	// Validation caches the validation rules checked on create and update. It is optional.
+	Validation *database.ValidationRules
+
+	// Mailer sends the emails of the scheduled reports. It is optional: without it, emails are logged.
+	Mailer *utils.Mailer
+
+	// Runtime holds the parameters that can be changed without restarting. It is optional.
+	Runtime *utils.RuntimeConfig
+
+	// TrustedProxies lists the proxies whose X-Forwarded-For and X-Real-IP headers are trusted. It is optional.
+	TrustedProxies []netip.Prefix
+
+	// IPFilter restricts every route to the allowed client IPs. It is optional.
+	IPFilter *middlewares.IPFilter
+
+	// AdminIPFilter restricts the admin routes to the allowed client IPs (e.g. a VPN subnet). It is optional.
+	AdminIPFilter *middlewares.IPFilter
+
+	// WriteLimiter limits the writes per user and resource, apart from the global rate limit. It is optional.
+	WriteLimiter *middlewares.WriteRateLimiter
+
+	// Concurrency limits the requests of each route group in flight, e.g. the exports. It is optional.
+	Concurrency *middlewares.ConcurrencyLimiter
+
+	// LoginThrottle requires a challenge, then blocks, after too many failed logins from a client. It is optional.
+	LoginThrottle *middlewares.LoginThrottle
+
+	// StepUp requires a recent authentication for the destructive actions. It is optional.
+	StepUp *middlewares.StepUp
+
+	// Usage meters the requests of each user and service account into daily rollups. It is optional.
+	Usage *middlewares.UsageMeter
+
+	// Policy decides on the admin routes in place of the admin-only check. It is optional.
+	Policy utils.Policy
+
+	// Proxy forwards /ext/{service}/ requests to the auxiliary services. It is optional.
+	Proxy *utils.ServiceProxy
+
+	// Static serves the front-end bundle for the GET requests no other route matches. It is optional.
+	Static http.Handler
+
+	// Console serves the interactive API console at /console/. It is optional.
+	Console http.Handler
+
+	// DevMode enables the development routes, like the test data generator.
+	DevMode bool
+
+	// Leader elects the instance running the scheduled jobs among the replicas. It is optional.
+	Leader *database.LeaderElector
+
+	// TxPolicy returns the transaction options of the operations on a resource
+	// (e.g. routes.TxPolicy). It is optional: without it, every resource uses database.DefaultTxPolicy.
+	TxPolicy func(resource string) database.TxPolicy
+
+	// ProtoCodec returns the protobuf codec of the records of a model type, or nil
+	// if the resource only speaks JSON (e.g. routes.ProtoCodec). It is optional.
+	ProtoCodec func(modelType reflect.Type) *protobuf.Codec
+
+	// Coalescer shares the result of a read between identical concurrent GetAll and GetByID requests. It is optional.
+	Coalescer *singleflight.Group
+
+	// PageBudget caps the page size of the lists to keep the pages under a byte budget. It is optional.
+	PageBudget *database.PageBudget
+
+	// LockTTL is how long a record lock lasts without being renewed. Zero uses defaultLockTTL.
+	LockTTL time.Duration
+
+	// Exports configures the exports of the resources. The zero value disables them.
+	Exports ExportSettings
+
+	// AlertWebhookURL is notified when a monitored dependency goes down or recovers. It is optional.
+	AlertWebhookURL string
+
+	// MailgunSigningKey verifies the emails Mailgun forwards to /inbound/mailgun. Empty disables the route.
+	MailgunSigningKey string
+
+	// AuditSigningKey signs the exports of the audit log. If nil, the exports are disabled.
+	AuditSigningKey ed25519.PrivateKey
 }
 
 // Create inserts a new record into the database.
 //
-// It decodes the request body into the provided model, validates the input,
-// and creates a new record in the database.
+// It decodes the request body into the provided model, sets the fields tagged
+// with `populate` (see utils.PopulateFields), validates the input,
+// and creates a new record in the database. Resources with a protobuf codec
+// also accept and return protobuf messages (see writeRecords).
 //
 // Parameters:
 // - w: The HTTP response writer.
-// - r: The HTTP request containing the JSON payload.
+// - r: The HTTP request containing the JSON (or protobuf) payload.
+// - resource: The name of the resource (e.g. "example1").
 // - model: A pointer to the struct representing the database entity.
 // - overwrite: Bool to create and overwrite if already exists
 //
 // Returns:
 // - HTTP 400 if the request body is invalid.
+// - HTTP 403 if the new record exceeds the quota of its owner (see models.Quota).
+// - HTTP 412 if overwrite replaces a record modified after the If-Unmodified-Since date.
+// - HTTP 423 if overwrite replaces a record locked by another user.
 // - HTTP 201 if the record is successfully created.
-func (c *Controller) Create(w http.ResponseWriter, r *http.Request, model interface{}, overwrite bool) {
+func (c *Controller) Create(w http.ResponseWriter, r *http.Request, resource string, model interface{}, overwrite bool) {
 	w.Header().Set("Content-Type", "application/json")
 
-	if err := json.NewDecoder(r.Body).Decode(model); err != nil {
+	if err := c.decodeRecord(r, model); err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
 
 		return
 	}
 
+	// Clean the string fields tagged with `sanitize`
+	utils.Sanitize(model)
+
+	// Set the fields tagged with `populate`, ignoring the client values
+	if err := utils.PopulateFields(model, middlewares.UsernameFromContext(r.Context()), time.Now()); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	// Replacing a record is an update, so it respects the lock of the record
+	if overwrite && !c.checkLock(w, r, resource, database.RecordID(model)) {
+		return
+	}
+
+	// and the If-Unmodified-Since date, when the record exists
+	if _, ok := middlewares.UnmodifiedSinceFromContext(r.Context()); ok && overwrite {
+		existing := reflect.New(reflect.TypeOf(model).Elem()).Interface()
+
+		err := c.BC.GetRecordsByID(existing, database.RecordID(model))
+		if err != nil && !errors.Is(err, database.ErrRecordNotFound) {
+			w.WriteHeader(http.StatusInternalServerError)
+			_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+			return
+		}
+
+		if err == nil && !checkUnmodified(w, r, existing) {
+			return
+		}
+	}
+
+	if !c.validate(w, resource, model) {
+		return
+	}
+
+	if !c.checkQuota(w, resource, model, overwrite) {
+		return
+	}
+
+	// Keep the role of a replaced user, to audit its change
+	var replaced interface{}
+
+	if _, ok := model.(*models.User); ok && overwrite {
+		var stored models.User
+		if err := c.BC.GetRecordsByID(&stored, database.RecordID(model)); err == nil {
+			replaced = stored
+		}
+	}
+
+	// Granting a role needs a recent authentication
+	if roleChanged(replaced, model) && !c.StepUp.Check(w, r) {
+		return
+	}
+
 	// Use the new CreateOrUpdateRecord function
-	if err := c.BC.CreateOrUpdateRecord(model, overwrite); err != nil {
+	created, err := c.transactions(r.Context(), resource).CreateOrUpdateRecord(model, overwrite)
+	if err != nil {
 		// If it's a duplicate key error and overwrite == false, or any other DB error
 		w.WriteHeader(http.StatusInternalServerError)
 		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
@@ -49,44 +243,376 @@ func (c *Controller) Create(w http.ResponseWriter, r *http.Request, model interf
 		return
 	}
 
+	eventType := models.EventUpdated
+	if created {
+		eventType = models.EventCreated
+	}
+
+	c.publishEvent(r, eventType, resource, database.RecordID(model), model)
+	c.notifyAssignees(r, resource, database.RecordID(model), model, nil)
+	c.auditUserRole(r, replaced, model)
+
 	// If the create (or update) succeeded
-	w.WriteHeader(http.StatusCreated)
-	_ = json.NewEncoder(w).Encode(model)
+	c.writeRecords(w, r, http.StatusCreated, model)
 }
 
-// GetAll retrieves all records with optional filtering.
+// GetAll retrieves all records with optional filtering, sorting, field selection and pagination.
 //
 // It parses query parameters to apply filters dynamically and returns the matching records.
+// The reserved parameters "sort" and "fields" take comma-separated field names, and
+// "view" loads a saved query whose parameters are merged with the request ones.
+// When "page" or "per_page" is present, only that page is returned and the RFC 8288
+// Link header points to the first, previous, next and last pages.
+// The X-Total-Count header always holds the number of matching records. On tables
+// above COUNT_ESTIMATE_THRESHOLD rows the paginated total is a database estimate,
+// flagged with the X-Total-Is-Estimate header.
+// Lists of resources tracking changes have an ETag, answering If-None-Match with
+// 304 or, with "A-IM: changes", the changes since that ETag (see conditionalList).
 //
 // Parameters:
 // - w: The HTTP response writer.
 // - r: The HTTP request containing optional filters as query parameters.
+// - resource: The name of the resource being listed (e.g. "example1").
 // - model: A pointer to a slice of structs representing the database entity.
 //
 // Returns:
+// - HTTP 226 with the models.ChangeSet since the If-None-Match ETag, with "A-IM: changes".
+// - HTTP 304 if the list did not change since the If-None-Match ETag.
+// - HTTP 400 if a filter, sort, field name or pagination parameter is invalid,
+// or the query is too expensive (see database.QueryCostLimits).
+// - HTTP 404 if the requested view does not exist.
+// - HTTP 503 with a Retry-After header if the database is busy (see database.QuerySemaphore).
 // - HTTP 500 if the retrieval fails.
-// - JSON array of records if successful.
-func (c *Controller) GetAll(w http.ResponseWriter, r *http.Request, model interface{}) {
+// - JSON array of records (or protobuf list message, see writeRecords) if successful.
+func (c *Controller) GetAll(w http.ResponseWriter, r *http.Request, resource string, model interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 
-	// Parse query parameters into filters
-	filters := make(map[string]interface{})
+	queryParams, err := c.resolveView(r, resource)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, database.ErrViewNotFound) {
+			status = http.StatusNotFound
+		}
+
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	if c.conditionalList(w, r, resource, model, queryParams) {
+		return
+	}
+
+	list, status, err := c.listRecords(c.transactions(r.Context(), resource), resource, model, queryParams,
+		middlewares.PublishedOnlyFromContext(r.Context()))
+	if err != nil {
+		if status == http.StatusServiceUnavailable {
+			w.Header().Set("Retry-After", dbBusyRetryAfter)
+		}
+
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	if list.perPage > 0 {
+		w.Header().Set("Link", paginationLinks(r.URL, list.page, list.perPage, list.total.count))
+	}
+
+	// The page size was capped by the byte budget: the Link header uses the applied one
+	if list.perPage < list.requestedPerPage {
+		w.Header().Set("X-Per-Page", strconv.Itoa(list.perPage))
+		w.Header().Set("X-Per-Page-Requested", strconv.Itoa(list.requestedPerPage))
+		w.Header().Set("X-Row-Size", strconv.FormatInt(list.rowSize, 10))
+	}
+
+	w.Header().Set("X-Total-Count", strconv.FormatInt(list.total.count, 10))
+
+	if list.total.estimated {
+		w.Header().Set("X-Total-Is-Estimate", "true")
+	}
+
+	c.writeRecords(w, r, http.StatusOK, model)
+}
+
+// recordList describes a list read by listRecords.
+type recordList struct {
+	total   recordTotal // The number of matching records
+	page    int         // The returned page, starting at 1
+	perPage int         // The page size, or zero if the list is not paginated
+
+	requestedPerPage int   // The page size asked for, above perPage if capped by the PageBudget
+	rowSize          int64 // The average row size in bytes, if the page size is capped
+}
+
+// listRecords fills a slice with the records matching the query parameters of
+// GET /{resource}: filters, "sort", "fields", and "page" and "per_page" to paginate.
+//
+// Parameters:
+// - bc: The database access of the request (see transactions).
+// - resource: The name of the resource being listed (e.g. "example1").
+// - model: A pointer to a slice of structs representing the database entity.
+// - queryParams: The query parameters, with any view already resolved.
+// - publishedOnly: Only list the published records of resources with a lifecycle.
+//
+// Returns:
+// - The total number of matching records and the pagination of the list, with
+// the page size capped by the PageBudget.
+// - The HTTP status of the error: 400 if a parameter is invalid or the query too
+// expensive, 503 if the database is busy (see database.QuerySemaphore), 500 otherwise.
+func (c *Controller) listRecords(bc *database.BaseController, resource string, model interface{},
+	queryParams url.Values, publishedOnly bool,
+) (recordList, int, error) {
+	page, perPage, err := parsePagination(queryParams)
+	if err != nil {
+		return recordList{}, http.StatusBadRequest, err
+	}
+
+	list := recordList{page: page, perPage: perPage, requestedPerPage: perPage}
+
+	// Pages of large records are shortened to stay under the byte budget
+	if perPage > 0 {
+		limit, rowSize, err := c.PageBudget.Cap(model)
+		if err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, database.ErrDBBusy) {
+				status = http.StatusServiceUnavailable
+			}
+
+			return recordList{}, status, err
+		}
+
+		if limit > 0 && perPage > limit {
+			perPage, list.perPage, list.rowSize = limit, limit, rowSize
+		}
+	}
+
+	opts, err := parseQueryOptions(queryParams)
+	if err != nil {
+		return recordList{}, http.StatusBadRequest, err
+	}
+
+	// Reject the pathologically expensive queries before they reach the database
+	cost := opts
+	if perPage > 0 {
+		cost.Offset = (page - 1) * perPage
+	}
+
+	if err := bc.CheckQueryCost(model, cost); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, database.ErrQueryTooExpensive) || errors.Is(err, database.ErrInvalidField) {
+			status = http.StatusBadRequest
+		} else if errors.Is(err, database.ErrDBBusy) {
+			status = http.StatusServiceUnavailable
+		}
+
+		return recordList{}, status, err
+	}
+
+	key := "list:" + resource + "?" + queryParams.Encode()
+
+	// Users other than admins only see the published records of resources with a lifecycle
+	if publishedOnly {
+		if filter, ok := bc.PublishedFilter(model); ok {
+			opts.Conditions = append(opts.Conditions, filter)
+			key += "#published"
+		}
+	}
+
+	// Lists read at a stricter isolation level asked by the request aren't shared with the others
+	if level := bc.IsolationHint(); level != sql.LevelDefault {
+		key += "#" + level.String()
+	}
+
+	// Identical lists have the same resource and (sorted) query parameters
+	total, err := c.coalesce(key, model, func() (recordTotal, error) {
+		var total recordTotal
+
+		// The page and its count are read in one transaction if the resource asks for it
+		err := bc.ReadTransaction(func(bc *database.BaseController) error {
+			if perPage > 0 {
+				count, estimated, err := bc.EstimateRecords(model, opts)
+				if err != nil {
+					return err
+				}
+
+				total = recordTotal{count: count, estimated: estimated}
+				opts.Limit = perPage
+				opts.Offset = (page - 1) * perPage
+			}
+
+			return bc.GetAllRecords(model, opts)
+		})
+
+		return total, err
+	})
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, database.ErrInvalidField) || errors.Is(err, database.ErrInvalidFilter) {
+			status = http.StatusBadRequest
+		} else if errors.Is(err, database.ErrDBBusy) {
+			status = http.StatusServiceUnavailable
+		}
+
+		return recordList{}, status, err
+	}
+
+	if perPage == 0 {
+		total.count = int64(reflect.ValueOf(model).Elem().Len())
+	}
+
+	list.total = total
+
+	return list, http.StatusOK, nil
+}
+
+// resolveView returns the query parameters of the request, merged on top of the
+// parameters of the saved query named in "view" when present.
+func (c *Controller) resolveView(r *http.Request, resource string) (url.Values, error) {
 	queryParams := r.URL.Query()
 
+	viewName := queryParams.Get("view")
+	if viewName == "" {
+		return queryParams, nil
+	}
+
+	queryParams.Del("view")
+
+	view, err := c.BC.GetSavedQuery(middlewares.UsernameFromContext(r.Context()), viewName)
+	if err != nil {
+		return nil, err
+	}
+
+	if view.Resource != resource {
+		return nil, database.ErrViewNotFound
+	}
+
+	merged, err := url.ParseQuery(view.Query)
+	if err != nil {
+		return nil, err
+	}
+
+	// Request parameters override the ones stored in the view
 	for key, values := range queryParams {
-		if len(values) > 0 {
-			filters[key] = values[0] // Assuming single value per key
+		merged[key] = values
+	}
+
+	return merged, nil
+}
+
+// parseQueryOptions converts query parameters into database query options.
+//
+// Every parameter except the reserved "sort", "fields", "page", "per_page" and "pretty" is
+// parsed as a filter: "field=value" and "filter[field]=value" for equality, or
+// "filter[field][op]=value" with another operator (see database.ParseFilter).
+func parseQueryOptions(queryParams url.Values) (database.QueryOptions, error) {
+	opts := database.QueryOptions{}
+
+	for key, values := range queryParams {
+		if len(values) == 0 {
+			continue
+		}
+
+		switch key {
+		case "sort":
+			opts.Sort = splitList(values[0])
+		case "fields":
+			opts.Fields = splitList(values[0])
+		case "page", "per_page":
+			// Handled by parsePagination
+		case "pretty":
+			// Handled by middlewares.Pretty
+		default:
+			filter, err := database.ParseFilter(key, values[0]) // Assuming single value per key
+			if err != nil {
+				return opts, err
+			}
+
+			opts.Conditions = append(opts.Conditions, filter)
 		}
 	}
 
-	if err := c.BC.GetAllRecords(model, filters); err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+	return opts, nil
+}
 
-		return
+// parsePagination reads the "page" and "per_page" query parameters.
+//
+// Returns a zero perPage when neither parameter is present (pagination disabled),
+// or an error when a value is not a positive integer or per_page exceeds maxPerPage.
+func parsePagination(queryParams url.Values) (int, int, error) {
+	if !queryParams.Has("page") && !queryParams.Has("per_page") {
+		return 0, 0, nil
 	}
 
-	_ = json.NewEncoder(w).Encode(model)
+	page, perPage := 1, defaultPerPage
+
+	if value := queryParams.Get("page"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return 0, 0, errors.New("page must be a positive integer")
+		}
+
+		page = n
+	}
+
+	if value := queryParams.Get("per_page"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > maxPerPage {
+			return 0, 0, fmt.Errorf("per_page must be an integer between 1 and %d", maxPerPage)
+		}
+
+		perPage = n
+	}
+
+	return page, perPage, nil
+}
+
+// paginationLinks builds the RFC 8288 Link header value for a paginated list.
+//
+// The links are relative to the request path and keep its query parameters,
+// replacing only "page" and "per_page".
+func paginationLinks(requestURL *url.URL, page, perPage int, total int64) string {
+	lastPage := int((total + int64(perPage) - 1) / int64(perPage))
+	if lastPage < 1 {
+		lastPage = 1
+	}
+
+	link := func(target int, rel string) string {
+		query := requestURL.Query()
+		query.Set("page", strconv.Itoa(target))
+		query.Set("per_page", strconv.Itoa(perPage))
+
+		return fmt.Sprintf(`<%s?%s>; rel="%s"`, requestURL.Path, query.Encode(), rel)
+	}
+
+	links := []string{link(1, "first")}
+
+	if page > 1 {
+		links = append(links, link(min(page-1, lastPage), "prev"))
+	}
+
+	if page < lastPage {
+		links = append(links, link(page+1, "next"))
+	}
+
+	links = append(links, link(lastPage, "last"))
+
+	return strings.Join(links, ", ")
+}
+
+// splitList splits a comma-separated list, dropping empty entries.
+func splitList(value string) []string {
+	items := []string{}
+
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
 }
 
 // GetByID retrieves a single record using composite primary keys.
@@ -99,8 +625,9 @@ func (c *Controller) GetAll(w http.ResponseWriter, r *http.Request, model interf
 // - model: A pointer to a struct representing the database entity.
 //
 // Returns:
+// - HTTP 404 if the record is not published and the PublishedOnly middleware restricts the request.
 // - HTTP 500 if the record is not found or retrieval fails.
-// - JSON object of the record if successful.
+// - JSON object (or protobuf message, see writeRecords) of the record if successful.
 func (c *Controller) GetByID(w http.ResponseWriter, r *http.Request, model interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 
@@ -108,36 +635,142 @@ func (c *Controller) GetByID(w http.ResponseWriter, r *http.Request, model inter
 	vars := mux.Vars(r)
 	tokenizedID := vars["id"]
 
-	if err := c.BC.GetRecordsByID(model, tokenizedID); err != nil {
+	_, err := c.coalesce(fmt.Sprintf("get:%T/%s", model, tokenizedID), model, func() (recordTotal, error) {
+		return recordTotal{}, c.BC.GetRecordsByID(model, tokenizedID)
+	})
+	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
 
 		return
 	}
 
-	_ = json.NewEncoder(w).Encode(model)
+	if middlewares.PublishedOnlyFromContext(r.Context()) && !database.IsPublished(model) {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: database.ErrRecordNotFound.Error()})
+
+		return
+	}
+
+	setLastModified(w, model)
+
+	c.writeRecords(w, r, http.StatusOK, model)
+}
+
+// recordTotal is the total number of records of a paginated list.
+type recordTotal struct {
+	count     int64
+	estimated bool
+}
+
+// coalescedResult is the result of a read shared between identical requests.
+type coalescedResult struct {
+	model interface{}
+	total recordTotal
+}
+
+// transactions returns the database access of the operations of a request on
+// a resource: its transactions run with the options of the resource (see
+// TxPolicy), at the isolation level asked by the request if it is stricter
+// (see middlewares.IsolationHint).
+func (c *Controller) transactions(ctx context.Context, resource string) *database.BaseController {
+	var policy database.TxPolicy
+	if c.TxPolicy != nil {
+		policy = c.TxPolicy(resource)
+	}
+
+	return c.BC.WithTransactions(policy, middlewares.IsolationFromContext(ctx))
+}
+
+// coalesce runs a read that fills model, sharing it with the identical reads
+// (same key) running at the same time when the Coalescer is set.
+//
+// Only the first request queries the database. The others get a copy of its
+// model value, so the records are shared and must not be modified.
+//
+// Parameters:
+// - key: Identifies the read, e.g. the resource and its query parameters.
+// - model: A pointer to the struct or slice filled by the read.
+// - read: The read to run, returning the total number of records when paginating.
+//
+// Returns:
+// - The total returned by the read.
+// - The error returned by the read.
+func (c *Controller) coalesce(key string, model interface{}, read func() (recordTotal, error)) (recordTotal, error) {
+	if c.Coalescer == nil {
+		return read()
+	}
+
+	value, err, _ := c.Coalescer.Do(key, func() (interface{}, error) {
+		total, err := read()
+
+		return coalescedResult{model: model, total: total}, err
+	})
+	if err != nil {
+		return recordTotal{}, err
+	}
+
+	result, _ := value.(coalescedResult)
+	if result.model != model {
+		reflect.ValueOf(model).Elem().Set(reflect.ValueOf(result.model).Elem())
+	}
+
+	return result.total, nil
 }
 
 // Update modifies an existing record identified by its tokenized ID.
 //
-// It extracts the ID from the URL, decodes the request body, and updates the record.
+// It extracts the ID from the URL, loads the current record, decodes the request
+// body on top of it (so omitted fields keep their values), and updates the record.
 //
 // Parameters:
 // - w: The HTTP response writer.
 // - r: The HTTP request containing the updated JSON payload.
+// - resource: The name of the resource (e.g. "example1").
 // - model: A pointer to the struct representing the database entity.
 //
 // Returns:
 // - HTTP 400 if the request body is invalid.
+// - HTTP 403 if the request changes fields the role of the user may not write.
+// - HTTP 404 if the record is not published and the PublishedOnly middleware restricts the request.
+// - HTTP 412 if the record was modified after the If-Unmodified-Since date.
+// - HTTP 423 if another user holds the lock of the record.
 // - HTTP 500 if the update fails.
 // - JSON object of the updated record if successful.
-func (c *Controller) Update(w http.ResponseWriter, r *http.Request, model interface{}) {
+func (c *Controller) Update(w http.ResponseWriter, r *http.Request, resource string, model interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 
 	// Extract the tokenized ID from the URL
 	vars := mux.Vars(r)
 	tokenizedID := vars["id"]
 
+	if !c.checkLock(w, r, resource, tokenizedID) {
+		return
+	}
+
+	// Load the current record so the body only overrides the fields it contains
+	if err := c.BC.GetRecordsByID(model, tokenizedID); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	// Users restricted to published records can't update the others either
+	if middlewares.PublishedOnlyFromContext(r.Context()) && !database.IsPublished(model) {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: database.ErrRecordNotFound.Error()})
+
+		return
+	}
+
+	if !checkUnmodified(w, r, model) {
+		return
+	}
+
+	// Keep a copy of the stored record to restore the server-populated fields
+	original := reflect.Indirect(reflect.ValueOf(model)).Interface()
+
 	// Decode the incoming request body
 	if err := json.NewDecoder(r.Body).Decode(model); err != nil {
 		w.WriteHeader(http.StatusBadRequest)
@@ -146,34 +779,220 @@ func (c *Controller) Update(w http.ResponseWriter, r *http.Request, model interf
 		return
 	}
 
-	if err := c.BC.UpdateRecords(model, tokenizedID); err != nil {
+	utils.Sanitize(model)
+	utils.KeepServerFields(model, original)
+
+	if !checkWritableFields(w, r, model, original) {
+		return
+	}
+
+	if roleChanged(original, model) && !c.StepUp.Check(w, r) {
+		return
+	}
+
+	if !c.validate(w, resource, model) {
+		return
+	}
+
+	if err := c.transactions(r.Context(), resource).UpdateRecords(model, tokenizedID); err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
 
 		return
 	}
 
+	c.publishEvent(r, models.EventUpdated, resource, tokenizedID, model)
+	c.notifyAssignees(r, resource, tokenizedID, model, original)
+	c.auditUserRole(r, original, model)
+
+	setLastModified(w, model)
+
 	_ = json.NewEncoder(w).Encode(model)
 }
 
+// GetSchema returns the JSON Schema of a resource, generated from its model and
+// completed with its validation rules.
+//
+// Parameters:
+// - w: The HTTP response writer.
+// - r: The HTTP request.
+// - resource: The name of the resource (e.g. "example1").
+// - model: A pointer to the struct representing the database entity.
+//
+// Returns:
+// - HTTP 500 if the model cannot be parsed.
+// - JSON Schema of the resource if successful.
+func (c *Controller) GetSchema(w http.ResponseWriter, _ *http.Request, resource string, model interface{}) {
+	w.Header().Set("Content-Type", "application/schema+json")
+
+	sch, err := c.BC.JSONSchema(resource, model)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	c.Validation.Describe(resource, &sch)
+
+	_ = json.NewEncoder(w).Encode(sch)
+}
+
+// validate checks the enum fields of a record and the validation rules of its
+// resource, writing a 400 response if it breaks any of them.
+func (c *Controller) validate(w http.ResponseWriter, resource string, model interface{}) bool {
+	if err := c.validateRecord(resource, model); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return false
+	}
+
+	return true
+}
+
+// validateRecord checks the enum fields of a record and the validation rules of its resource.
+func (c *Controller) validateRecord(resource string, model interface{}) error {
+	if err := utils.ValidateEnums(model); err != nil {
+		return err
+	}
+
+	return c.Validation.Validate(resource, model)
+}
+
+// checkUnmodified writes HTTP 412 and returns false if the record was modified
+// after the If-Unmodified-Since date of the request (see middlewares.IfUnmodifiedSince),
+// so a write based on a stale copy does not clobber a newer change.
+//
+// Records without an UpdatedAt field, or without a value, always pass.
+func checkUnmodified(w http.ResponseWriter, r *http.Request, model interface{}) bool {
+	since, ok := middlewares.UnmodifiedSinceFromContext(r.Context())
+	if !ok {
+		return true
+	}
+
+	// HTTP dates have a precision of one second
+	modified, ok := database.LastModified(model)
+	if !ok || !modified.Truncate(time.Second).After(since) {
+		return true
+	}
+
+	w.WriteHeader(http.StatusPreconditionFailed)
+	_ = json.NewEncoder(w).Encode(models.ErrorResponse{
+		Error: "record modified since " + since.UTC().Format(http.TimeFormat) + ", at " + modified.UTC().Format(http.TimeFormat),
+	})
+
+	return false
+}
+
+// checkWritableFields writes HTTP 403 and returns false if an update changes
+// fields the role of the user may not write (see middlewares.WritableFields),
+// listing them. Fields sent with their stored value are not changes.
+func checkWritableFields(w http.ResponseWriter, r *http.Request, model, original interface{}) bool {
+	writable, ok := middlewares.WritableFieldsFromContext(r.Context())
+	if !ok {
+		return true
+	}
+
+	encoded, err := json.Marshal(model)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return false
+	}
+
+	forbidden := slices.DeleteFunc(changedFields(encoded, original), func(field string) bool {
+		return slices.Contains(writable, field)
+	})
+
+	if len(forbidden) == 0 {
+		return true
+	}
+
+	w.WriteHeader(http.StatusForbidden)
+	_ = json.NewEncoder(w).Encode(models.ForbiddenFieldsResponse{
+		Error:  "Forbidden: fields not writable by your role: " + strings.Join(forbidden, ", "),
+		Fields: forbidden,
+	})
+
+	return false
+}
+
+// changedFields returns the fields of a JSON object whose value differs from a
+// stored record, sorted by name. It returns nil if record is not an object.
+func changedFields(record json.RawMessage, stored interface{}) []string {
+	var local, remote map[string]interface{}
+
+	if err := json.Unmarshal(record, &local); err != nil || local == nil {
+		return nil
+	}
+
+	encoded, err := json.Marshal(stored)
+	if err != nil || json.Unmarshal(encoded, &remote) != nil {
+		return nil
+	}
+
+	fields := []string{}
+
+	for name, value := range local {
+		if !reflect.DeepEqual(value, remote[name]) {
+			fields = append(fields, name)
+		}
+	}
+
+	slices.Sort(fields)
+
+	return fields
+}
+
+// setLastModified sets the Last-Modified header to the UpdatedAt field of a record, if any.
+func setLastModified(w http.ResponseWriter, model interface{}) {
+	if modified, ok := database.LastModified(model); ok {
+		w.Header().Set("Last-Modified", modified.UTC().Format(http.TimeFormat))
+	}
+}
+
 // Delete removes a record identified by its tokenized ID.
 //
 // Parameters:
 // - w: The HTTP response writer.
 // - r: The HTTP request containing the tokenized ID as a URL parameter.
+// - resource: The name of the resource (e.g. "example1").
 // - model: A pointer to the struct representing the database entity.
 //
 // Returns:
+// - HTTP 412 if the record was modified after the If-Unmodified-Since date.
+// - HTTP 423 if another user holds the lock of the record.
 // - HTTP 500 if deletion fails.
 // - JSON confirmation message if successful.
-func (c *Controller) Delete(w http.ResponseWriter, r *http.Request, model interface{}) {
+func (c *Controller) Delete(w http.ResponseWriter, r *http.Request, resource string, model interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 
 	// Extract the tokenized ID from the URL
 	vars := mux.Vars(r)
 	tokenizedID := vars["id"]
 
-	if err := c.BC.DeleteRecords(model, tokenizedID); err != nil {
+	if !c.checkLock(w, r, resource, tokenizedID) {
+		return
+	}
+
+	// Conditional deletes compare the stored record with the If-Unmodified-Since date
+	if _, ok := middlewares.UnmodifiedSinceFromContext(r.Context()); ok {
+		if err := c.BC.GetRecordsByID(model, tokenizedID); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+			return
+		}
+
+		if !checkUnmodified(w, r, model) {
+			return
+		}
+	}
+
+	if err := c.deleteRecord(r, resource, model, tokenizedID); err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
 
@@ -182,3 +1001,40 @@ func (c *Controller) Delete(w http.ResponseWriter, r *http.Request, model interf
 
 	_ = json.NewEncoder(w).Encode(map[string]string{"message": "Deleted successfully"})
 }
+
+// deleteRecord deletes a record, then releases its lock, removes it from the
+// favorites, records the deletion for the change feeds and publishes the event.
+func (c *Controller) deleteRecord(r *http.Request, resource string, model interface{}, tokenizedID string) error {
+	if err := c.transactions(r.Context(), resource).DeleteRecords(model, tokenizedID); err != nil {
+		return err
+	}
+
+	if err := c.BC.ReleaseRecordLock(resource, tokenizedID); err != nil {
+		log.Println("Error releasing the lock of a deleted record:", err)
+	}
+
+	if err := c.BC.DeleteRecordFavorites(resource, tokenizedID); err != nil {
+		log.Println("Error removing a deleted record from the favorites:", err)
+	}
+
+	c.recordDeletions(resource, model, []string{tokenizedID})
+
+	c.publishEvent(r, models.EventDeleted, resource, tokenizedID, nil)
+
+	return nil
+}
+
+// publishEvent sends a resource change event to the event bus, if configured.
+func (c *Controller) publishEvent(r *http.Request, eventType models.EventType, resource, recordID string,
+	data interface{},
+) {
+	c.Events.Publish(models.ResourceEvent{
+		Type:      eventType,
+		Resource:  resource,
+		RecordID:  recordID,
+		Actor:     middlewares.UsernameFromContext(r.Context()),
+		ActorType: middlewares.AccountTypeFromContext(r.Context()),
+		ClientIP:  middlewares.ClientIPFromContext(r.Context()),
+		Data:      data,
+	})
+}
@@ -0,0 +1,120 @@
+package controllers
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/r4ulcl/api_template/database"
+	"github.com/r4ulcl/api_template/utils"
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+// Bootstrap creates the first admin of a database without admin, with the
+// one-time bootstrap token, and returns a JWT for it so provisioning can go
+// on. The token is cleared once an admin exists, disabling the endpoint.
+//
+// Returns:
+// - HTTP 400 if the body is invalid or the username or password are empty.
+// - HTTP 401 if the token is wrong.
+// - HTTP 404 if the bootstrap is disabled or was already done.
+// - HTTP 409 if the username is taken, or if an admin was created in the meantime (e.g. by another
+// instance), which disables the endpoint.
+// - HTTP 201 with the JWT of the admin if successful.
+func (ac *AuthController) Bootstrap(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+
+	ac.bootstrapMu.Lock()
+	defer ac.bootstrapMu.Unlock()
+
+	if ac.BootstrapToken == "" {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "Bootstrap is disabled"})
+
+		return
+	}
+
+	var input models.BootstrapRequest
+
+	err := json.NewDecoder(r.Body).Decode(&input)
+	if err == nil && (utils.NormalizeUsername(input.Username) == "" || input.Password == "") {
+		err = errInvalidInput
+	}
+
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "Token, username and password are required"})
+
+		return
+	}
+
+	if subtle.ConstantTimeCompare([]byte(input.Token), []byte(ac.BootstrapToken)) != 1 {
+		w.WriteHeader(http.StatusUnauthorized)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "Invalid bootstrap token"})
+
+		return
+	}
+
+	user := models.User{Username: utils.NormalizeUsername(input.Username), Role: models.AdminRole}
+
+	if input.Email != "" {
+		email, err := ac.checkEmail(input.Email, "")
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "Invalid email address"})
+
+			return
+		}
+
+		now := time.Now()
+		user.Email = &email
+		user.EmailVerifiedAt = &now
+	}
+
+	user.Password, err = utils.HashPassword(input.Password)
+	if err == nil {
+		err = ac.BC.CreateFirstAdmin(&user)
+	}
+
+	if errors.Is(err, database.ErrAdminExists) {
+		ac.BootstrapToken = ""
+
+		w.WriteHeader(http.StatusConflict)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "An admin already exists"})
+
+		return
+	}
+
+	// The token stays valid, so the bootstrap can be retried with another username
+	if err != nil && database.IsDuplicateKeyError(err) {
+		w.WriteHeader(http.StatusConflict)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "User already exists"})
+
+		return
+	}
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	ac.BootstrapToken = ""
+	log.Printf("Bootstrap admin %q created, POST /bootstrap disabled", user.Username)
+
+	token, err := utils.GenerateJWT(user.Username, string(user.Role), ac.Secret)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "Failed to generate token"})
+
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(models.JWTResponse{Token: token})
+}
@@ -0,0 +1,523 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/r4ulcl/api_template/api/middlewares"
+	"github.com/r4ulcl/api_template/database"
+	"github.com/r4ulcl/api_template/utils"
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+const (
+	// maxBulkBytes is the largest payload of a bulk import.
+	maxBulkBytes = 64 << 20
+
+	// maxBulkRecords is the largest number of records of a bulk import.
+	maxBulkRecords = 100000
+)
+
+// BulkImport accepts a JSON array or a CSV file of records and creates them in
+// a background job, so imports taking longer than the write timeout of the
+// server don't hold the request open. The progress of the job is polled with
+// GetBulkJob.
+//
+// CSV files have a header row with the JSON names of the fields. Their cells
+// are strings, or JSON values for the fields that are not strings (numbers,
+// booleans, objects...); empty cells of those fields are left unset.
+//
+// The records are processed in chunks of DB_BATCH_SIZE like on create: the
+// fields tagged with `populate` are set, with the submitter as author, the
+// records are validated, and the quota of the resource is checked before
+// each chunk.
+//
+// Parameters:
+// - w: The HTTP response writer.
+// - r: The HTTP request with a JSON array (application/json) or a CSV file (text/csv).
+// - resource: The name of the resource (e.g. "example1").
+// - model: A pointer to a model of the resource.
+//
+// Returns:
+// - HTTP 400 if the payload is invalid, empty or has more than maxBulkRecords records.
+// - HTTP 413 if the payload is larger than maxBulkBytes.
+// - HTTP 415 if the payload is neither JSON nor CSV.
+// - HTTP 503 if too many imports are running (see middlewares.ConcurrencyLimiter).
+// - HTTP 202 with the queued models.BulkJob and its URL in the Location header.
+func (c *Controller) BulkImport(w http.ResponseWriter, r *http.Request, resource string, model interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+
+	format := "json"
+
+	if contentType := r.Header.Get("Content-Type"); contentType != "" {
+		mediaType, _, _ := mime.ParseMediaType(contentType)
+
+		switch mediaType {
+		case "application/json":
+		case "text/csv":
+			format = "csv"
+		default:
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "The payload must be a JSON array (application/json) or a CSV file (text/csv)"})
+
+			return
+		}
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBulkBytes))
+	if err != nil {
+		status := http.StatusBadRequest
+
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			status = http.StatusRequestEntityTooLarge
+		}
+
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	var records []json.RawMessage
+
+	if format == "csv" {
+		records, err = csvRecords(body, reflect.TypeOf(model).Elem())
+	} else {
+		err = json.Unmarshal(body, &records)
+	}
+
+	switch {
+	case err != nil:
+		err = fmt.Errorf("invalid %s payload: %w", format, err)
+	case len(records) == 0:
+		err = errors.New("the payload has no records")
+	case len(records) > maxBulkRecords:
+		err = fmt.Errorf("the payload has more than %d records", maxBulkRecords)
+	}
+
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	job := models.BulkJob{
+		Resource: resource,
+		Owner:    middlewares.UsernameFromContext(r.Context()),
+		Format:   format,
+		Total:    len(records),
+	}
+
+	// The slot is held until the job ends
+	release, ok := c.Concurrency.Check(w, r, middlewares.ConcurrencyImport)
+	if !ok {
+		return
+	}
+
+	if err := c.BC.CreateBulkJob(&job); err != nil {
+		release()
+
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	// The events of the created records are attributed to the submitter
+	event := models.ResourceEvent{
+		Resource:  resource,
+		Actor:     job.Owner,
+		ActorType: middlewares.AccountTypeFromContext(r.Context()),
+		ClientIP:  middlewares.ClientIPFromContext(r.Context()),
+	}
+
+	decode := func(i int, model interface{}) error { return json.Unmarshal(records[i], model) }
+
+	go func() {
+		defer release()
+
+		c.runBulkJob(job, decode, reflect.TypeOf(model).Elem(), event)
+	}()
+
+	w.Header().Set("Location", "/jobs/"+strconv.FormatUint(uint64(job.ID), 10))
+	w.WriteHeader(http.StatusAccepted)
+	_ = json.NewEncoder(w).Encode(job)
+}
+
+// GenerateRecords creates synthetic records of a resource in a background
+// bulk job, e.g. to exercise the pagination or load test a development
+// instance. The values follow the types and constraints of the columns (see
+// database.NewRecordGenerator), and the records are created like those of
+// BulkImport: populated, validated and counted against the quota.
+//
+// Parameters:
+// - w: The HTTP response writer.
+// - r: The HTTP request with the "count" query parameter.
+// - resource: The name of the resource (e.g. "example1").
+// - model: A pointer to a model of the resource.
+//
+// Returns:
+// - HTTP 400 if the count is not between 1 and maxBulkRecords.
+// - HTTP 409 if a foreign key of the model references an empty table.
+// - HTTP 503 if too many imports are running (see middlewares.ConcurrencyLimiter).
+// - HTTP 202 with the queued models.BulkJob and its URL in the Location header.
+func (c *Controller) GenerateRecords(w http.ResponseWriter, r *http.Request, resource string, model interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+
+	count, err := strconv.Atoi(r.URL.Query().Get("count"))
+	if err != nil || count < 1 || count > maxBulkRecords {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{
+			Error: fmt.Sprintf("count must be a number between 1 and %d", maxBulkRecords),
+		})
+
+		return
+	}
+
+	generator, err := c.BC.NewRecordGenerator(model)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, database.ErrNoParentRecords) {
+			status = http.StatusConflict
+		}
+
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	job := models.BulkJob{
+		Resource: resource,
+		Owner:    middlewares.UsernameFromContext(r.Context()),
+		Format:   "generated",
+		Total:    count,
+	}
+
+	// The slot is held until the job ends
+	release, ok := c.Concurrency.Check(w, r, middlewares.ConcurrencyImport)
+	if !ok {
+		return
+	}
+
+	if err := c.BC.CreateBulkJob(&job); err != nil {
+		release()
+
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	event := models.ResourceEvent{
+		Resource:  resource,
+		Actor:     job.Owner,
+		ActorType: middlewares.AccountTypeFromContext(r.Context()),
+		ClientIP:  middlewares.ClientIPFromContext(r.Context()),
+	}
+
+	go func() {
+		defer release()
+
+		c.runBulkJob(job, generator.Fill, reflect.TypeOf(model).Elem(), event)
+	}()
+
+	w.Header().Set("Location", "/jobs/"+strconv.FormatUint(uint64(job.ID), 10))
+	w.WriteHeader(http.StatusAccepted)
+	_ = json.NewEncoder(w).Encode(job)
+}
+
+// GetBulkJob returns the progress of a bulk import job. Users only see their
+// own jobs, admins see every job.
+//
+// Returns:
+// - HTTP 400 if the ID is invalid.
+// - HTTP 404 if the job does not exist or belongs to another user.
+// - HTTP 500 if the retrieval fails.
+// - JSON object of the models.BulkJob if successful.
+func (c *Controller) GetBulkJob(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "Invalid job ID"})
+
+		return
+	}
+
+	job, err := c.BC.GetBulkJob(uint(id))
+
+	if err == nil && job.Owner != middlewares.UsernameFromContext(r.Context()) &&
+		middlewares.RoleFromContext(r.Context()) != string(models.AdminRole) {
+		err = database.ErrBulkJobNotFound
+	}
+
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, database.ErrBulkJobNotFound) {
+			status = http.StatusNotFound
+		}
+
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(job)
+}
+
+// runBulkJob creates the Total records of a job chunk by chunk, storing its
+// progress after each chunk.
+//
+// Parameters:
+// - decode: Decodes the record at an index of the job into a model.
+func (c *Controller) runBulkJob(job models.BulkJob, decode func(i int, model interface{}) error,
+	modelType reflect.Type, event models.ResourceEvent,
+) {
+	job.Status = models.BulkJobRunning
+	c.saveBulkJob(&job)
+
+	size := c.BC.BulkBatchSize()
+
+	for start := 0; start < job.Total; start += size {
+		end := min(start+size, job.Total)
+
+		decodeChunk := func(i int, model interface{}) error { return decode(start+i, model) }
+
+		if err := c.createBulkChunk(&job, end-start, decodeChunk, start, modelType, event); err != nil {
+			job.Status = models.BulkJobFailed
+			job.Error = err.Error()
+
+			break
+		}
+
+		job.Processed = end
+		if end < job.Total {
+			c.saveBulkJob(&job)
+		}
+	}
+
+	if job.Status != models.BulkJobFailed {
+		job.Status = models.BulkJobDone
+	}
+
+	finished := time.Now()
+	job.FinishedAt = &finished
+	c.saveBulkJob(&job)
+}
+
+// createBulkChunk creates the valid records of a chunk of a job, in one
+// statement if possible. If the statement fails (e.g. on a duplicate key),
+// the records are created one by one to fail only the invalid ones.
+//
+// Parameters:
+// - count: The number of records of the chunk.
+// - decode: Decodes the record at an index of the chunk into a model.
+// - offset: The index of the first record of the chunk in the payload.
+//
+// Returns:
+// - An error stopping the job, e.g. if the database is unreachable.
+func (c *Controller) createBulkChunk(job *models.BulkJob, count int, decode func(i int, model interface{}) error,
+	offset int, modelType reflect.Type, event models.ResourceEvent,
+) error {
+	now := time.Now()
+	chunk := reflect.New(reflect.SliceOf(modelType))
+	indexes := []int{}
+
+	for i := range count {
+		model := reflect.New(modelType).Interface()
+
+		if err := decode(i, model); err != nil {
+			failBulkRecord(job, offset+i, err)
+
+			continue
+		}
+
+		if err := c.bulkRecord(job, model, now); err != nil {
+			failBulkRecord(job, offset+i, err)
+
+			continue
+		}
+
+		chunk.Elem().Set(reflect.Append(chunk.Elem(), reflect.ValueOf(model).Elem()))
+		indexes = append(indexes, offset+i)
+	}
+
+	if len(indexes) == 0 {
+		return nil
+	}
+
+	if err := c.BC.CheckQuota(job.Resource, chunk.Elem().Index(0).Addr().Interface()); err != nil {
+		if !errors.Is(err, database.ErrQuotaExceeded) {
+			return err
+		}
+
+		for _, index := range indexes {
+			failBulkRecord(job, index, err)
+		}
+
+		return nil
+	}
+
+	created := len(indexes)
+
+	var insertErr *database.BulkInsertError
+
+	err := c.BC.CreateRecords(chunk.Interface(), nil)
+	if errors.As(err, &insertErr) {
+		// The records before the failed insert were created
+		created = insertErr.Start
+
+		for i := insertErr.Start; i < len(indexes); i++ {
+			if _, err := c.BC.CreateOrUpdateRecord(chunk.Elem().Index(i).Addr().Interface(), false); err != nil {
+				failBulkRecord(job, indexes[i], err)
+
+				continue
+			}
+
+			c.publishBulkRecord(event, chunk.Elem().Index(i).Addr().Interface())
+			job.Created++
+		}
+	} else if err != nil {
+		return err
+	}
+
+	for i := range created {
+		c.publishBulkRecord(event, chunk.Elem().Index(i).Addr().Interface())
+	}
+
+	job.Created += created
+
+	return nil
+}
+
+// bulkRecord prepares a decoded record of a job like on create: sanitized,
+// with the fields tagged with `populate` set, and validated.
+func (c *Controller) bulkRecord(job *models.BulkJob, model interface{}, now time.Time) error {
+	utils.Sanitize(model)
+
+	if err := utils.PopulateFields(model, job.Owner, now); err != nil {
+		return err
+	}
+
+	return c.validateRecord(job.Resource, model)
+}
+
+// publishBulkRecord publishes the creation event of a record of a job.
+func (c *Controller) publishBulkRecord(event models.ResourceEvent, model interface{}) {
+	event.Type = models.EventCreated
+	event.RecordID = database.RecordID(model)
+	event.Data = model
+
+	c.Events.Publish(event)
+}
+
+// failBulkRecord counts a record of a job that could not be created, keeping
+// the error of the first MaxBulkJobFailures.
+func failBulkRecord(job *models.BulkJob, index int, err error) {
+	job.Failed++
+
+	if len(job.Failures) < models.MaxBulkJobFailures {
+		job.Failures = append(job.Failures, models.ImportFailure{Index: index, Error: err.Error()})
+	}
+}
+
+// saveBulkJob stores the progress of a job, logging the errors as the job
+// runs in the background.
+func (c *Controller) saveBulkJob(job *models.BulkJob) {
+	if err := c.BC.SaveBulkJob(job); err != nil {
+		log.Printf("Error storing the progress of bulk job %d: %v", job.ID, err)
+	}
+}
+
+// csvRecords converts the rows of a CSV file, whose header row holds the JSON
+// names of the fields of a model, to JSON records.
+func csvRecords(body []byte, modelType reflect.Type) ([]json.RawMessage, error) {
+	reader := csv.NewReader(bytes.NewReader(body))
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	kinds := jsonFieldKinds(modelType)
+	records := []json.RawMessage{}
+
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		record := map[string]json.RawMessage{}
+
+		for i, name := range header {
+			value := row[i]
+
+			kind, ok := kinds[name]
+			switch {
+			case !ok || kind == reflect.String:
+				record[name], _ = json.Marshal(value)
+			case value == "":
+				// Non-string fields have no empty value, so they are left unset
+			case json.Valid([]byte(value)):
+				record[name] = json.RawMessage(value)
+			default:
+				// e.g. the dates of time.Time fields
+				record[name], _ = json.Marshal(value)
+			}
+		}
+
+		encoded, err := json.Marshal(record)
+		if err != nil {
+			return nil, err
+		}
+
+		records = append(records, encoded)
+	}
+
+	return records, nil
+}
+
+// jsonFieldKinds returns the kind of the fields of a struct type by JSON name.
+func jsonFieldKinds(modelType reflect.Type) map[string]reflect.Kind {
+	kinds := map[string]reflect.Kind{}
+
+	for i := range modelType.NumField() {
+		field := modelType.Field(i)
+
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+
+		kinds[name] = fieldType.Kind()
+	}
+
+	return kinds
+}
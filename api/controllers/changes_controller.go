@@ -0,0 +1,234 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/r4ulcl/api_template/api/middlewares"
+	"github.com/r4ulcl/api_template/database"
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+const (
+	// maxChanges is the largest number of changed records returned at once.
+	maxChanges = 1000
+
+	// maxChangesWait is the longest a change request may wait for changes, in seconds.
+	maxChangesWait = 30
+
+	// changesPollInterval is how often a waiting change request checks for changes.
+	changesPollInterval = time.Second
+)
+
+// GetChanges returns the records of a resource created, updated or deleted
+// after a watermark, for clients that sync a resource without holding an
+// SSE or WebSocket connection.
+//
+// With "wait", the request waits up to that many seconds for a change before
+// answering with an empty change set (long polling).
+//
+// Parameters:
+// - w: The HTTP response writer.
+// - r: The HTTP request with the "since" and optional "wait" query parameters.
+// - resource: The name of the resource (e.g. "example1").
+// - model: A pointer to a slice of a model with an UpdatedAt field.
+//
+// Returns:
+// - HTTP 400 if "since" is not an RFC 3339 timestamp or "wait" is out of range.
+// - HTTP 410 if "since" is older than the retention of the deletions, so the client must reload the resource.
+// - HTTP 500 if the retrieval fails.
+// - JSON object of the models.ChangeSet if successful.
+func (c *Controller) GetChanges(w http.ResponseWriter, r *http.Request, resource string, model interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+
+	since, err := time.Parse(time.RFC3339Nano, r.URL.Query().Get("since"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "since must be an RFC 3339 timestamp"})
+
+		return
+	}
+
+	if time.Since(since) > database.DeletionRetention {
+		w.WriteHeader(http.StatusGone)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{
+			Error: "since is older than the deletion retention, reload the resource",
+		})
+
+		return
+	}
+
+	wait := 0
+
+	if raw := r.URL.Query().Get("wait"); raw != "" {
+		wait, err = strconv.Atoi(raw)
+		if err != nil || wait < 0 || wait > maxChangesWait {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "wait must be between 0 and 30 seconds"})
+
+			return
+		}
+	}
+
+	deadline := time.Now().Add(time.Duration(wait) * time.Second)
+
+	// Waiting requests outlive the write timeout of the server
+	if wait > 0 {
+		_ = http.NewResponseController(w).SetWriteDeadline(deadline.Add(10 * time.Second))
+	}
+
+	// Users restricted to published records get the other records as deleted
+	var conditions []database.Filter
+
+	if middlewares.PublishedOnlyFromContext(r.Context()) {
+		if filter, ok := c.BC.PublishedFilter(model); ok {
+			conditions = append(conditions, filter)
+		}
+	}
+
+	for {
+		changes, err := c.readChanges(resource, model, since, conditions)
+		if err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, database.ErrChangesNotTracked) {
+				status = http.StatusBadRequest
+			}
+
+			w.WriteHeader(status)
+			_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+			return
+		}
+
+		remaining := time.Until(deadline)
+		if reflect.ValueOf(changes.Changed).Len() > 0 || len(changes.Deleted) > 0 || remaining <= 0 {
+			_ = EncodeJSON(w, changes)
+
+			return
+		}
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-time.After(min(changesPollInterval, remaining)):
+		}
+	}
+}
+
+// readChanges reads the changes of a resource after a watermark.
+//
+// With conditions, only the records matching them are returned as changed:
+// the IDs of the records changed but no longer matching them are returned as
+// deleted, since the client can't see them anymore.
+func (c *Controller) readChanges(resource string, model interface{}, since time.Time,
+	conditions []database.Filter,
+) (models.ChangeSet, error) {
+	changes := models.ChangeSet{Deleted: []string{}, Watermark: since}
+
+	if err := c.BC.ChangedRecords(model, since, time.Time{}, database.QueryOptions{Limit: maxChanges + 1}); err != nil {
+		return changes, err
+	}
+
+	records := reflect.ValueOf(model).Elem()
+	count := records.Len()
+
+	if count > maxChanges {
+		changes.HasMore = true
+
+		// Leave the records sharing the timestamp of the first record left out
+		// for the next request, whose watermark excludes that timestamp
+		boundary, _ := database.LastModified(records.Index(maxChanges).Addr().Interface())
+
+		count = maxChanges
+		for count > 0 {
+			modified, _ := database.LastModified(records.Index(count - 1).Addr().Interface())
+			if !modified.Equal(boundary) {
+				break
+			}
+
+			count--
+		}
+
+		if count == 0 {
+			count = maxChanges
+		}
+	}
+
+	updated := make(map[string]time.Time, count)
+
+	for i := range count {
+		record := records.Index(i).Addr().Interface()
+
+		modified, _ := database.LastModified(record)
+		updated[database.RecordID(record)] = modified
+		changes.Watermark = modified
+	}
+
+	changes.Changed = reflect.AppendSlice(reflect.MakeSlice(records.Type(), 0, count), records.Slice(0, count)).Interface()
+
+	if len(conditions) > 0 && count > 0 {
+		// Read the same window again, keeping only the records matching the conditions
+		matching := reflect.New(records.Type())
+
+		err := c.BC.ChangedRecords(matching.Interface(), since, changes.Watermark,
+			database.QueryOptions{Conditions: conditions})
+		if err != nil {
+			return changes, err
+		}
+
+		matched := make(map[string]bool, matching.Elem().Len())
+		for i := range matching.Elem().Len() {
+			matched[database.RecordID(matching.Elem().Index(i).Addr().Interface())] = true
+		}
+
+		for i := range count {
+			if id := database.RecordID(records.Index(i).Addr().Interface()); !matched[id] {
+				changes.Deleted = append(changes.Deleted, id)
+			}
+		}
+
+		changes.Changed = matching.Elem().Interface()
+	}
+
+	var until time.Time
+	if changes.HasMore {
+		until = changes.Watermark
+	}
+
+	deletions, err := c.BC.DeletedRecords(resource, since, until)
+	if err != nil {
+		return changes, err
+	}
+
+	for _, deletion := range deletions {
+		if deletion.DeletedAt.After(changes.Watermark) {
+			changes.Watermark = deletion.DeletedAt
+		}
+
+		// The record was created again after its deletion
+		if modified, ok := updated[deletion.RecordID]; ok && modified.After(deletion.DeletedAt) {
+			continue
+		}
+
+		changes.Deleted = append(changes.Deleted, deletion.RecordID)
+	}
+
+	return changes, nil
+}
+
+// recordDeletions stores the deletion of records of the resources tracking
+// changes, so GET /{resource}/changes reports them.
+func (c *Controller) recordDeletions(resource string, model interface{}, ids []string) {
+	if !database.TracksChanges(model) {
+		return
+	}
+
+	if err := c.BC.RecordDeletions(resource, ids, time.Now()); err != nil {
+		log.Println("Error recording deleted records:", err)
+	}
+}
@@ -0,0 +1,76 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/r4ulcl/api_template/database"
+	"github.com/r4ulcl/api_template/utils"
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+var errUnknownAction = errors.New("unknown command action")
+
+// ApplyCommand executes a create or update command received from a message bus
+// on behalf of a service user, and publishes the resulting change event.
+//
+// Parameters:
+// - cmd: The command to execute.
+// - model: A pointer to a new instance of the resource model.
+// - actor: The username of the service user the command runs as.
+//
+// Returns:
+// - An error if the action is unknown, the data is invalid, a create exceeds its quota or the write fails.
+func (c *Controller) ApplyCommand(cmd models.ResourceCommand, model interface{}, actor string) error {
+	var eventType models.EventType
+
+	switch cmd.Action {
+	case models.CommandCreate:
+		if err := json.Unmarshal(cmd.Data, model); err != nil {
+			return err
+		}
+
+		utils.Sanitize(model)
+
+		if err := c.BC.CheckQuota(cmd.Resource, model); err != nil {
+			return err
+		}
+
+		if _, err := c.BC.CreateOrUpdateRecord(model, false); err != nil {
+			return err
+		}
+
+		eventType = models.EventCreated
+	case models.CommandUpdate:
+		// Load the current record so the data only overrides the fields it contains
+		if err := c.BC.GetRecordsByID(model, cmd.RecordID); err != nil {
+			return err
+		}
+
+		if err := json.Unmarshal(cmd.Data, model); err != nil {
+			return err
+		}
+
+		utils.Sanitize(model)
+
+		if err := c.BC.UpdateRecords(model, cmd.RecordID); err != nil {
+			return err
+		}
+
+		eventType = models.EventUpdated
+	default:
+		return fmt.Errorf("%w: %q", errUnknownAction, cmd.Action)
+	}
+
+	c.Events.Publish(models.ResourceEvent{
+		Type:      eventType,
+		Resource:  cmd.Resource,
+		RecordID:  database.RecordID(model),
+		Actor:     actor,
+		ActorType: models.UserAccountType,
+		Data:      model,
+	})
+
+	return nil
+}
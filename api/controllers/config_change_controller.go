@@ -0,0 +1,357 @@
+package controllers
+
+import (
+	"archive/zip"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/r4ulcl/api_template/api/middlewares"
+	"github.com/r4ulcl/api_template/database"
+	"github.com/r4ulcl/api_template/utils"
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+const (
+	// defaultConfigChanges is the number of audit entries returned without "limit".
+	defaultConfigChanges = 100
+
+	// maxConfigChanges is the largest "limit" of the audit feed.
+	maxConfigChanges = 1000
+)
+
+// GetConfigChanges returns the audit log of the configuration and permission
+// changes, newest first. The optional query parameters "kind", "key" and "actor"
+// filter the entries, "since" (RFC 3339) only returns the newer ones, and
+// "before" (an entry ID) the older ones, to page through the feed with "limit".
+//
+// Returns:
+// - HTTP 400 if a query parameter is invalid.
+// - HTTP 500 if the retrieval fails.
+// - JSON array of the models.ConfigChange if successful.
+func (c *Controller) GetConfigChanges(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	filter, err := parseConfigChangeFilter(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	changes, err := c.BC.ListConfigChanges(filter)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(changes)
+}
+
+// VerifyConfigChanges checks the chain of the audit log of configuration
+// changes: the hash of every entry and its link to the previous one, which
+// breaks if an entry was altered, inserted or removed in the database.
+//
+// Returns:
+// - HTTP 500 if the log cannot be read.
+// - JSON models.AuditVerification, with the hash of the last entry to record elsewhere.
+func (c *Controller) VerifyConfigChanges(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	verification, err := c.BC.VerifyConfigChanges()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(verification)
+}
+
+// ExportConfigChanges downloads a signed bundle of the audit log of
+// configuration changes: a ZIP file with the entries (entries.ndjson), a
+// manifest with their count, hashes and chain check (manifest.json), the
+// Ed25519 signature of the manifest (manifest.sig) and the public key
+// verifying it (public.pem). The optional "after" query parameter (an entry ID)
+// only exports the later entries.
+//
+// Returns:
+// - HTTP 404 if no AUDIT_SIGNING_KEY is configured.
+// - HTTP 400 if "after" is invalid.
+// - HTTP 500 if the log cannot be read; the bundle is then truncated.
+// - The ZIP bundle if successful.
+func (c *Controller) ExportConfigChanges(w http.ResponseWriter, r *http.Request) {
+	if c.AuditSigningKey == nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "Audit exports are disabled (no AUDIT_SIGNING_KEY)"})
+
+		return
+	}
+
+	var afterID uint
+
+	if value := r.URL.Query().Get("after"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "after must be an entry ID"})
+
+			return
+		}
+
+		afterID = uint(id)
+	}
+
+	// The exported entries follow the entry before them
+	previous, err := c.BC.ConfigChangeBefore(afterID + 1)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	publicKey, err := utils.PublicKeyPEM(c.AuditSigningKey)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	manifest := models.AuditManifest{
+		Log:        "config_changes",
+		PrevHash:   previous.Hash,
+		Algorithm:  "Ed25519",
+		ExportedBy: middlewares.UsernameFromContext(r.Context()),
+		ExportedAt: time.Now().UTC(),
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition",
+		fmt.Sprintf(`attachment; filename="audit-config-%s.zip"`, manifest.ExportedAt.Format("20060102T150405Z")))
+
+	bundle := zip.NewWriter(w)
+
+	entries, err := bundle.Create("entries.ndjson")
+	if err == nil {
+		err = writeAuditEntries(c.BC, afterID, entries, &manifest)
+	}
+
+	if err == nil {
+		err = writeSignedManifest(bundle, c.AuditSigningKey, manifest)
+	}
+
+	if err == nil {
+		err = writeZipFile(bundle, "public.pem", publicKey)
+	}
+
+	if err != nil {
+		// The response has started, so the error can only truncate the bundle
+		log.Println("Error exporting the audit log:", err)
+
+		return
+	}
+
+	_ = bundle.Close()
+}
+
+// writeAuditEntries writes the entries of the audit log after an ID as JSON
+// lines, checking their chain and filling the manifest with their count and hashes.
+func writeAuditEntries(bc *database.BaseController, afterID uint, out io.Writer,
+	manifest *models.AuditManifest,
+) error {
+	digest := sha256.New()
+	encoder := json.NewEncoder(io.MultiWriter(out, digest))
+	verification := models.NewAuditVerification(manifest.PrevHash)
+
+	err := bc.WalkConfigChanges(afterID, func(change models.ConfigChange) error {
+		if manifest.Entries == 0 {
+			manifest.FirstID = change.ID
+		}
+
+		manifest.Entries++
+		manifest.LastID = change.ID
+		manifest.HeadHash = change.Hash
+		verification.Check(change)
+
+		return encoder.Encode(change)
+	})
+
+	manifest.EntriesSHA256 = hex.EncodeToString(digest.Sum(nil))
+	manifest.ChainValid = verification.Valid
+	manifest.BrokenID = verification.BrokenID
+
+	return err
+}
+
+// writeSignedManifest writes the manifest of an audit export and its Ed25519 signature.
+func writeSignedManifest(bundle *zip.Writer, key ed25519.PrivateKey, manifest models.AuditManifest) error {
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := writeZipFile(bundle, "manifest.json", content); err != nil {
+		return err
+	}
+
+	return writeZipFile(bundle, "manifest.sig", ed25519.Sign(key, content))
+}
+
+// writeZipFile writes a file to a ZIP archive.
+func writeZipFile(bundle *zip.Writer, name string, content []byte) error {
+	file, err := bundle.Create(name)
+	if err != nil {
+		return err
+	}
+
+	_, err = file.Write(content)
+
+	return err
+}
+
+// parseConfigChangeFilter reads the query parameters of GET /admin/audit/config.
+func parseConfigChangeFilter(r *http.Request) (database.ConfigChangeFilter, error) {
+	query := r.URL.Query()
+
+	filter := database.ConfigChangeFilter{
+		Kind:  models.ConfigKind(query.Get("kind")),
+		Key:   query.Get("key"),
+		Actor: query.Get("actor"),
+		Limit: defaultConfigChanges,
+	}
+
+	if value := query.Get("since"); value != "" {
+		since, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return filter, errors.New("since must be an RFC 3339 timestamp")
+		}
+
+		filter.Since = since
+	}
+
+	if value := query.Get("before"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return filter, errors.New("before must be an entry ID")
+		}
+
+		filter.BeforeID = uint(id)
+	}
+
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxConfigChanges {
+			return filter, errors.New("limit must be an integer between 1 and " + strconv.Itoa(maxConfigChanges))
+		}
+
+		filter.Limit = limit
+	}
+
+	return filter, nil
+}
+
+// auditConfig records a configuration change made through an admin endpoint,
+// with its actor and the configuration before and after it. after is nil for
+// deletions, and before for creations.
+//
+// The change is already stored, so a failure to record it is logged.
+func (c *Controller) auditConfig(r *http.Request, kind models.ConfigKind, key string, before, after interface{}) {
+	change := models.ConfigChange{
+		Kind:      kind,
+		Key:       key,
+		Action:    models.ConfigUpdated,
+		Actor:     middlewares.UsernameFromContext(r.Context()),
+		ActorType: middlewares.AccountTypeFromContext(r.Context()),
+		ClientIP:  middlewares.ClientIPFromContext(r.Context()),
+	}
+
+	var err error
+
+	switch {
+	case after == nil:
+		change.Action = models.ConfigDeleted
+	case before == nil:
+		change.Action = models.ConfigCreated
+	}
+
+	if before != nil {
+		change.Before, err = json.Marshal(before)
+	}
+
+	if after != nil && err == nil {
+		change.After, err = json.Marshal(after)
+	}
+
+	if err == nil {
+		err = c.BC.RecordConfigChange(&change)
+	}
+
+	if err != nil {
+		log.Printf("Error auditing the change of %s %q: %v", kind, key, err)
+	}
+}
+
+// auditUserRole records the role change of a user made with the admin /user
+// routes. before is nil for new users. Other records, and changes keeping the
+// role, are not audited.
+func (c *Controller) auditUserRole(r *http.Request, before, after interface{}) {
+	user, ok := after.(*models.User)
+	if !ok {
+		return
+	}
+
+	role := map[string]models.Role{"role": user.Role}
+
+	switch stored := before.(type) {
+	case nil:
+		c.auditConfig(r, models.ConfigUserRole, user.Username, nil, role)
+	case models.User:
+		if stored.Role != user.Role {
+			c.auditConfig(r, models.ConfigUserRole, user.Username, map[string]models.Role{"role": stored.Role}, role)
+		}
+	}
+}
+
+// roleChanged reports whether a write to the admin /user routes grants a role:
+// it creates an admin, or changes the role of an existing user. before is nil
+// for new users, like for auditUserRole.
+func roleChanged(before, after interface{}) bool {
+	user, ok := after.(*models.User)
+	if !ok {
+		return false
+	}
+
+	if stored, ok := before.(models.User); ok {
+		return stored.Role != user.Role
+	}
+
+	return user.Role == models.AdminRole
+}
+
+// storedConfig returns the configuration loaded before a change, or nil if it
+// could not be loaded, which audits the change as a creation.
+func storedConfig(config interface{}, err error) interface{} {
+	if err != nil {
+		return nil
+	}
+
+	return config
+}
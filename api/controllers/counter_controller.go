@@ -0,0 +1,28 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+// RecomputeCounters recounts every denormalized counter (see database.Counter)
+// from the rows it counts, repairing the counters that drifted.
+//
+// Returns:
+// - HTTP 500 if the counters cannot be recomputed.
+// - JSON array of the models.CounterRepair of each counter if successful.
+func (c *Controller) RecomputeCounters(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	repairs, err := c.BC.RecomputeCounters()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(repairs)
+}
@@ -0,0 +1,180 @@
+package controllers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/r4ulcl/api_template/api/middlewares"
+	"github.com/r4ulcl/api_template/database"
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+// deltaManipulation is the RFC 3229 instance manipulation of the delta lists.
+const deltaManipulation = "changes"
+
+// conditionalList answers a GET /{resource} request from its If-None-Match
+// header, for the resources tracking changes, and sets the ETag of the list.
+//
+// The ETag holds the date of the latest change of the resource. If it matches,
+// the list is unchanged and the response is 304 Not Modified. With the header
+// "A-IM: changes" and the ETag of a previous response, the response is 226 IM
+// Used with the models.ChangeSet since that response instead of the whole list,
+// for dashboards polling the same list every few seconds. Paginated lists and
+// lists with "fields" are always sent whole.
+//
+// Parameters:
+// - w: The HTTP response writer.
+// - r: The HTTP request.
+// - resource: The name of the resource (e.g. "example1").
+// - model: A pointer to a slice of a model.
+// - queryParams: The query parameters of the list, with any view already resolved.
+//
+// Returns:
+// - true if the response was written, false if the whole list must be sent.
+func (c *Controller) conditionalList(w http.ResponseWriter, r *http.Request, resource string, model interface{},
+	queryParams url.Values,
+) bool {
+	if !database.TracksChanges(model) {
+		return false
+	}
+
+	publishedOnly := middlewares.PublishedOnlyFromContext(r.Context())
+
+	// Read before the list, so the list holds every change up to the ETag
+	latest, err := c.BC.LatestChange(resource, model)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, database.ErrDBBusy) {
+			w.Header().Set("Retry-After", dbBusyRetryAfter)
+			status = http.StatusServiceUnavailable
+		}
+
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return true
+	}
+
+	query := listQueryHash(queryParams, publishedOnly)
+	w.Header().Set("ETag", listETag(query, latest))
+	w.Header().Add("Vary", "A-IM")
+
+	tags := r.Header.Get("If-None-Match")
+	if tags == "" {
+		return false
+	}
+
+	if etagMatches(tags, listETag(query, latest)) {
+		w.WriteHeader(http.StatusNotModified)
+
+		return true
+	}
+
+	if !wantsDelta(r) || queryParams.Has("page") || queryParams.Has("per_page") || queryParams.Has("fields") {
+		return false
+	}
+
+	since, ok := parseListETag(tags, query)
+	if !ok || time.Since(since) > database.DeletionRetention {
+		return false
+	}
+
+	opts, err := parseQueryOptions(queryParams)
+	if err != nil {
+		return false
+	}
+
+	if publishedOnly {
+		if filter, ok := c.BC.PublishedFilter(model); ok {
+			opts.Conditions = append(opts.Conditions, filter)
+		}
+	}
+
+	changes, err := c.readChanges(resource, model, since, opts.Conditions)
+	if err != nil || changes.HasMore {
+		// The whole list reports the errors, and is smaller than too many changes
+		return false
+	}
+
+	w.Header().Set("ETag", listETag(query, changes.Watermark))
+	w.Header().Set("IM", deltaManipulation)
+	w.WriteHeader(http.StatusIMUsed)
+
+	_ = EncodeJSON(w, changes)
+
+	return true
+}
+
+// wantsDelta reports whether the A-IM header of a request accepts the delta lists.
+func wantsDelta(r *http.Request) bool {
+	for _, manipulation := range strings.Split(r.Header.Get("A-IM"), ",") {
+		name, _, _ := strings.Cut(manipulation, ";")
+		if strings.EqualFold(strings.TrimSpace(name), deltaManipulation) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// listQueryHash identifies the query of a list, so an ETag is only valid for it.
+func listQueryHash(queryParams url.Values, publishedOnly bool) string {
+	key := queryParams.Encode()
+	if publishedOnly {
+		key += "#published"
+	}
+
+	sum := sha256.Sum256([]byte(key))
+
+	return hex.EncodeToString(sum[:8])
+}
+
+// listETag returns the ETag of a list: the hash of its query and the date of
+// the latest change of its resource.
+func listETag(query string, latest time.Time) string {
+	var nanos int64
+	if !latest.IsZero() {
+		nanos = latest.UnixNano()
+	}
+
+	return `"` + query + "." + strconv.FormatInt(nanos, 10) + `"`
+}
+
+// parseListETag returns the date of the latest change held by the list ETag of
+// an If-None-Match header, if one was issued for the same query.
+func parseListETag(tags, query string) (time.Time, bool) {
+	for _, tag := range strings.Split(tags, ",") {
+		tag = strings.Trim(strings.TrimPrefix(strings.TrimSpace(tag), "W/"), `"`)
+
+		hash, nanos, ok := strings.Cut(tag, ".")
+		if !ok || hash != query {
+			continue
+		}
+
+		if n, err := strconv.ParseInt(nanos, 10, 64); err == nil && n > 0 {
+			return time.Unix(0, n), true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// etagMatches reports whether an If-None-Match header matches an ETag, with
+// the weak comparison of RFC 9110.
+func etagMatches(tags, etag string) bool {
+	for _, tag := range strings.Split(tags, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+
+	return false
+}
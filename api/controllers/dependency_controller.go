@@ -0,0 +1,345 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/r4ulcl/api_template/database"
+	"github.com/r4ulcl/api_template/utils"
+	"github.com/r4ulcl/api_template/utils/httpclient"
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+// maxDependencyBodyBytes is the part of the response of a check read before closing it.
+const maxDependencyBodyBytes = 64 << 10
+
+// ListDependencyChecks returns every dependency check, with its state.
+//
+// Returns:
+// - HTTP 500 if the retrieval fails.
+// - JSON array of dependency checks if successful.
+func (c *Controller) ListDependencyChecks(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	checks, err := c.BC.ListDependencyChecks()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(checks)
+}
+
+// CreateDependencyCheck stores a new dependency check. The dependency is
+// unknown until its first check.
+//
+// Returns:
+// - HTTP 400 if the check is invalid.
+// - HTTP 500 if the check cannot be stored.
+// - HTTP 201 with the stored check if successful.
+func (c *Controller) CreateDependencyCheck(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	check, ok := decodeDependencyCheck(w, r)
+	if !ok {
+		return
+	}
+
+	if err := c.BC.CreateDependencyCheck(&check); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(check)
+}
+
+// UpdateDependencyCheck replaces an existing dependency check. Its state is
+// kept unless the URL changed.
+//
+// Returns:
+// - HTTP 400 if the check or its ID is invalid.
+// - HTTP 404 if the check does not exist.
+// - HTTP 500 if the check cannot be stored.
+// - JSON object of the stored check if successful.
+func (c *Controller) UpdateDependencyCheck(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, ok := dependencyIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	check, ok := decodeDependencyCheck(w, r)
+	if !ok {
+		return
+	}
+
+	if err := c.BC.UpdateDependencyCheck(id, &check); err != nil {
+		writeDependencyError(w, err)
+
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(check)
+}
+
+// DeleteDependencyCheck removes a dependency check, and the dependency from /readyz.
+//
+// Returns:
+// - HTTP 400 if the ID is invalid.
+// - HTTP 404 if the check does not exist.
+// - HTTP 500 if deletion fails.
+// - JSON confirmation message if successful.
+func (c *Controller) DeleteDependencyCheck(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, ok := dependencyIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	if err := c.BC.DeleteDependencyCheck(id); err != nil {
+		writeDependencyError(w, err)
+
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(map[string]string{"message": "Deleted successfully"})
+}
+
+// RunDependencyCheck checks a dependency now, whether it is due or not, and
+// stores its new state like a scheduled check, alerting on its changes.
+//
+// Returns:
+// - HTTP 400 if the ID is invalid.
+// - HTTP 404 if the check does not exist.
+// - HTTP 500 if the state cannot be stored.
+// - JSON object of the check with its new state, also if the dependency is down.
+func (c *Controller) RunDependencyCheck(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, ok := dependencyIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	check, err := c.BC.GetDependencyCheck(id)
+	if err == nil {
+		check, err = c.checkDependency(r.Context(), check, time.Now())
+	}
+
+	if err != nil {
+		writeDependencyError(w, err)
+
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(check)
+}
+
+// WatchDependencies checks the due dependencies every interval until the
+// context is cancelled. The due checks run concurrently, each bounded by its
+// timeout.
+//
+// Parameters:
+// - ctx: Stops the checks when cancelled.
+// - interval: How often the due checks are looked for.
+func (c *Controller) WatchDependencies(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			checks, err := c.BC.DueDependencyChecks(time.Now())
+			if err != nil {
+				log.Println("Error listing due dependency checks:", err)
+
+				continue
+			}
+
+			var wg sync.WaitGroup
+
+			for _, check := range checks {
+				wg.Add(1)
+
+				go func() {
+					defer wg.Done()
+
+					if _, err := c.checkDependency(ctx, check, time.Now()); err != nil {
+						log.Println("Error storing the state of dependency "+check.Name+":", err)
+					}
+				}()
+			}
+
+			wg.Wait()
+		}
+	}
+}
+
+// checkDependency requests the URL of a dependency, updates its state and
+// stores it. The dependency goes down after FailureThreshold failed checks in
+// a row, and up on the first successful one; both changes are alerted.
+//
+// Returns:
+// - The check with its new state.
+// - An error if the state cannot be stored.
+func (c *Controller) checkDependency(ctx context.Context, check models.DependencyCheck,
+	now time.Time,
+) (models.DependencyCheck, error) {
+	status, latency, err := probeDependency(ctx, check)
+
+	previous := check.State
+	check.LastCheckedAt = &now
+	check.LastStatusCode = status
+	check.LastLatencyMs = latency.Milliseconds()
+	check.LastError = ""
+
+	switch {
+	case err != nil:
+		check.LastError = err.Error()
+	case !check.Healthy(status):
+		check.LastError = fmt.Sprintf("unexpected status %d", status)
+	}
+
+	if check.LastError == "" {
+		check.State = models.DependencyUp
+		check.ConsecutiveFailures = 0
+		check.DownSince = nil
+	} else {
+		check.ConsecutiveFailures++
+
+		if check.ConsecutiveFailures >= check.Threshold() && previous != models.DependencyDown {
+			check.State = models.DependencyDown
+			check.DownSince = &now
+		}
+	}
+
+	if err := c.BC.SetDependencyCheckResult(check); err != nil {
+		return check, err
+	}
+
+	// The first successful check of a new dependency is not a recovery
+	if check.State != previous && (check.State == models.DependencyDown || previous == models.DependencyDown) {
+		c.alertDependency(check)
+	}
+
+	return check, nil
+}
+
+// alertDependency logs a structured warning and notifies the dependency rules
+// and the alert webhook, if configured, of a dependency that went down or recovered.
+func (c *Controller) alertDependency(check models.DependencyCheck) {
+	log.Printf("WARN dependency name=%s state=%s status=%d failures=%d error=%q",
+		check.Name, check.State, check.LastStatusCode, check.ConsecutiveFailures, check.LastError)
+
+	if c.Notifications != nil {
+		c.Notifications.ReportDependency(check)
+	}
+
+	if c.AlertWebhookURL == "" {
+		return
+	}
+
+	go func() {
+		text := fmt.Sprintf("Dependency %s is %s", check.Name, check.State)
+		if err := utils.SendWebhookAlert(c.AlertWebhookURL, text, check); err != nil {
+			log.Println("Error sending dependency alert:", err)
+		}
+	}()
+}
+
+// probeDependency requests the URL of a dependency once, without retries.
+//
+// Returns:
+// - The HTTP status, or 0 without response.
+// - The duration of the request.
+// - An error if the request failed without response.
+func probeDependency(ctx context.Context, check models.DependencyCheck) (int, time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, check.TimeoutDuration())
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, check.URL, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	headers, _ := check.HeaderValues()
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	// A retried check would hide the flaky dependencies the threshold is for
+	client := &httpclient.Client{HTTP: &http.Client{Timeout: check.TimeoutDuration()}}
+
+	start := time.Now()
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, time.Since(start), err
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxDependencyBodyBytes))
+
+	return resp.StatusCode, time.Since(start), nil
+}
+
+// decodeDependencyCheck decodes and validates a dependency check, writing a 400 response on failure.
+func decodeDependencyCheck(w http.ResponseWriter, r *http.Request) (models.DependencyCheck, bool) {
+	var check models.DependencyCheck
+
+	err := json.NewDecoder(r.Body).Decode(&check)
+	if err == nil {
+		err = check.Validate()
+	}
+
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return check, false
+	}
+
+	return check, true
+}
+
+// dependencyIDParam parses the {id} URL parameter, writing a 400 response on failure.
+func dependencyIDParam(w http.ResponseWriter, r *http.Request) (uint, bool) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "Invalid dependency check ID"})
+
+		return 0, false
+	}
+
+	return uint(id), true
+}
+
+// writeDependencyError writes a 404 for unknown dependency checks and a 500 for any other error.
+func writeDependencyError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	if errors.Is(err, database.ErrDependencyCheckNotFound) {
+		status = http.StatusNotFound
+	}
+
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+}
@@ -0,0 +1,159 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/r4ulcl/api_template/api/middlewares"
+	"github.com/r4ulcl/api_template/database"
+	"github.com/r4ulcl/api_template/utils"
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+// maxDeviceNameLength is the longest device label stored.
+const maxDeviceNameLength = 191
+
+// LoginDevice exchanges the device token of a login with remember_me for a
+// fresh session token. The device tokens themselves are not accepted by the
+// API routes. The session token gets the current role of the user, and the
+// authentication time of the login that remembered the device, so it doesn't
+// satisfy the step-up authentication.
+//
+// Returns:
+// - HTTP 400 if the body is invalid.
+// - HTTP 401 if the device token is unknown, revoked or expired, or its user no longer exists.
+// - HTTP 403 if the email of the user must be verified first.
+// - JSON token response if successful.
+func (ac *AuthController) LoginDevice(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+
+	var input models.DeviceLoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil || input.DeviceToken == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "Invalid input"})
+
+		return
+	}
+
+	device, err := ac.BC.UseDeviceToken(input.DeviceToken)
+	if errors.Is(err, database.ErrInvalidToken) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "Invalid device token"})
+
+		return
+	} else if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	// A user created again with the same name doesn't inherit the devices of the previous one
+	var user models.User
+	if err := ac.BC.GetRecordsByID(&user, device.Username); err != nil || user.CreatedAt.After(device.CreatedAt) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "Invalid device token"})
+
+		return
+	}
+
+	if ac.RequireEmailVerification && user.Email != nil && user.EmailVerifiedAt == nil {
+		w.WriteHeader(http.StatusForbidden)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "Email address not verified"})
+
+		return
+	}
+
+	// The user authenticated when the device was remembered, not now
+	token, err := utils.GenerateSessionJWT(user.Username, string(user.Role), device.CreatedAt,
+		[]string{utils.AuthMethodPassword}, ac.Secret)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "Failed to generate token"})
+
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(models.JWTResponse{Token: token, DeviceID: device.ID})
+}
+
+// ListDevices returns the devices remembered for the authenticated user.
+//
+// Returns:
+// - HTTP 500 if the devices cannot be retrieved.
+// - JSON array of devices, without their tokens, if successful.
+func (ac *AuthController) ListDevices(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	devices, err := ac.BC.ListDeviceTokens(middlewares.UsernameFromContext(r.Context()))
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(devices)
+}
+
+// RevokeDevice revokes a device of the authenticated user, whose token can no
+// longer be exchanged. The session tokens already issued stay valid until they expire.
+//
+// Returns:
+// - HTTP 404 if the user has no device with the ID.
+// - HTTP 204 if the device was revoked.
+func (ac *AuthController) RevokeDevice(w http.ResponseWriter, r *http.Request) {
+	err := ac.BC.DeleteDeviceToken(middlewares.UsernameFromContext(r.Context()), mux.Vars(r)["id"])
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, database.ErrDeviceTokenNotFound) {
+			status = http.StatusNotFound
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RevokeDevices revokes every device of the authenticated user, e.g. after a lost device.
+//
+// Returns:
+// - HTTP 500 if the devices cannot be revoked.
+// - JSON object with the number of devices revoked if successful.
+func (ac *AuthController) RevokeDevices(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	revoked, err := ac.BC.DeleteDeviceTokens(middlewares.UsernameFromContext(r.Context()))
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(map[string]int64{"revoked": revoked})
+}
+
+// deviceName returns the label of a remembered device: the one given at
+// login, or the User-Agent of the request.
+func deviceName(name string, r *http.Request) string {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = r.UserAgent()
+	}
+
+	if len(name) > maxDeviceNameLength {
+		name = strings.ToValidUTF8(name[:maxDeviceNameLength], "")
+	}
+
+	return name
+}
@@ -0,0 +1,579 @@
+package controllers
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/mail"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/r4ulcl/api_template/api/middlewares"
+	"github.com/r4ulcl/api_template/database"
+	"github.com/r4ulcl/api_template/utils/mailbox"
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+const (
+	// maxMailgunBytes is the largest inbound email read from Mailgun, with its attachments.
+	maxMailgunBytes = 25 << 20
+
+	// mailgunMaxAge is how old the timestamp of a signed Mailgun request may be.
+	mailgunMaxAge = 15 * time.Minute
+
+	// maxPolledEmails is the largest number of emails read from the mailbox per poll.
+	maxPolledEmails = 50
+)
+
+// errMailgunSignature is returned for the Mailgun requests without a valid, recent signature.
+var errMailgunSignature = errors.New("invalid signature")
+
+// ListEmailRules returns every email rule, in the order they are checked.
+//
+// Returns:
+// - HTTP 500 if the retrieval fails.
+// - JSON array of email rules if successful.
+func (c *Controller) ListEmailRules(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	rules, err := c.BC.ListEmailRules()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(rules)
+}
+
+// CreateEmailRule stores a new email rule, owned by the authenticated admin.
+//
+// Parameters:
+// - w: The HTTP response writer.
+// - r: The HTTP request with a models.EmailRule.
+// - newModel: Returns a pointer to a model of a resource, to check the resource of the rule.
+//
+// Returns:
+// - HTTP 400 if the rule is invalid.
+// - HTTP 500 if the rule cannot be stored.
+// - HTTP 201 with the stored rule if successful.
+func (c *Controller) CreateEmailRule(w http.ResponseWriter, r *http.Request, newModel ModelFactory) {
+	w.Header().Set("Content-Type", "application/json")
+
+	rule, ok := decodeEmailRule(w, r, newModel)
+	if !ok {
+		return
+	}
+
+	if err := c.BC.CreateEmailRule(&rule); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(rule)
+}
+
+// UpdateEmailRule replaces an existing email rule, now owned by the authenticated admin.
+//
+// Parameters:
+// - w: The HTTP response writer.
+// - r: The HTTP request with the {id} URL parameter and a models.EmailRule.
+// - newModel: Returns a pointer to a model of a resource, to check the resource of the rule.
+//
+// Returns:
+// - HTTP 400 if the rule or its ID is invalid.
+// - HTTP 404 if the rule does not exist.
+// - HTTP 500 if the rule cannot be stored.
+// - JSON object of the stored rule if successful.
+func (c *Controller) UpdateEmailRule(w http.ResponseWriter, r *http.Request, newModel ModelFactory) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, ok := emailRuleIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	rule, ok := decodeEmailRule(w, r, newModel)
+	if !ok {
+		return
+	}
+
+	if err := c.BC.UpdateEmailRule(id, &rule); err != nil {
+		writeEmailRuleError(w, err)
+
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(rule)
+}
+
+// DeleteEmailRule removes an email rule. The records it created are kept.
+//
+// Returns:
+// - HTTP 400 if the ID is invalid.
+// - HTTP 404 if the rule does not exist.
+// - HTTP 500 if deletion fails.
+// - JSON confirmation message if successful.
+func (c *Controller) DeleteEmailRule(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, ok := emailRuleIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	if err := c.BC.DeleteEmailRule(id); err != nil {
+		writeEmailRuleError(w, err)
+
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(map[string]string{"message": "Deleted successfully"})
+}
+
+// ReceiveMailgun converts an email forwarded by a Mailgun inbound route into
+// a record (see IngestEmail).
+//
+// The request must be signed with the Mailgun webhook signing key: the
+// hex HMAC-SHA256 of its timestamp and token, at most 15 minutes old. The
+// email is read from the parsed fields Mailgun posts (sender, recipient,
+// subject, body-plain, body-html, message-headers and attachment-N files).
+//
+// Parameters:
+// - w: The HTTP response writer.
+// - r: The HTTP request with the multipart or URL-encoded form of Mailgun.
+// - newModel: Returns a pointer to a model of a resource, to decode the records.
+//
+// Returns:
+// - HTTP 404 if no signing key is configured.
+// - HTTP 400 if the form cannot be read.
+// - HTTP 401 if the signature is missing, invalid or too old.
+// - HTTP 500 if the rules cannot be read, for Mailgun to retry.
+// - JSON object of the models.EmailIngestResult otherwise, also if no rule matched or the record failed.
+func (c *Controller) ReceiveMailgun(w http.ResponseWriter, r *http.Request, newModel ModelFactory) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if c.MailgunSigningKey == "" {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "Mailgun inbound emails are disabled"})
+
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxMailgunBytes)
+
+	err := r.ParseMultipartForm(maxMailgunBytes)
+	if errors.Is(err, http.ErrNotMultipart) {
+		err = r.ParseForm()
+	}
+
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "Failed to read the form: " + err.Error()})
+
+		return
+	}
+
+	if !validMailgunSignature(c.MailgunSigningKey, r.PostFormValue("timestamp"), r.PostFormValue("token"),
+		r.PostFormValue("signature"), time.Now()) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: errMailgunSignature.Error()})
+
+		return
+	}
+
+	msg, err := mailgunMessage(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	result, err := c.IngestEmail(msg, newModel)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(result)
+}
+
+// WatchMailbox polls an IMAP mailbox every interval until the context is
+// cancelled, converting its unseen emails into records (see IngestEmail).
+// The emails are marked seen once handled; those that cannot be parsed are
+// logged and marked seen too, and a failure to read the rules leaves the
+// email unseen for the next poll.
+//
+// Parameters:
+// - ctx: Stops the polling when cancelled.
+// - interval: How often the mailbox is polled.
+// - box: The mailbox.
+// - newModel: Returns a pointer to a model of a resource, to decode the records.
+func (c *Controller) WatchMailbox(ctx context.Context, interval time.Duration, box *mailbox.Mailbox,
+	newModel ModelFactory,
+) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_, err := box.Poll(ctx, maxPolledEmails, func(raw []byte) error {
+				msg, err := mailbox.Parse(raw)
+				if err != nil {
+					log.Println("Error parsing an email:", err)
+
+					return nil
+				}
+
+				result, err := c.IngestEmail(msg, newModel)
+				if err == nil && result.Error != "" {
+					log.Println("Error creating the record of email "+result.MessageID+":", result.Error)
+				}
+
+				return err
+			})
+			if err != nil {
+				log.Println("Error polling the mailbox:", err)
+			}
+		}
+	}
+}
+
+// IngestEmail converts a received email into a record: the first enabled
+// rule, by priority, whose patterns match the email creates a record of its
+// resource, with the values of the email its mapping says (see
+// models.EmailSources). The record is created like an imported one (see
+// importRecord), failing if it already exists. The time of the match and the
+// error of the record, if any, are stored in the rule.
+//
+// Parameters:
+// - msg: The email.
+// - newModel: Returns a pointer to a model of a resource, to decode the record.
+//
+// Returns:
+// - The outcome of the email, with the rule matching it and the ID or the error of its record.
+// - An error if the rules cannot be read.
+func (c *Controller) IngestEmail(msg *mailbox.Message, newModel ModelFactory) (models.EmailIngestResult, error) {
+	result := models.EmailIngestResult{MessageID: msg.MessageID}
+
+	rules, err := c.BC.EnabledEmailRules()
+	if err != nil {
+		return result, err
+	}
+
+	for _, rule := range rules {
+		groups, ok := matchEmailRule(rule, msg)
+		if !ok {
+			continue
+		}
+
+		now := time.Now()
+		result.Rule, result.Resource = rule.Name, rule.Resource
+
+		record, err := emailRecord(rule, msg, groups)
+		if err == nil {
+			if _, ok := newModel(rule.Resource); !ok {
+				err = fmt.Errorf("invalid resource %q in email rule %s", rule.Resource, rule.Name)
+			}
+		}
+
+		if err == nil {
+			result.RecordID, _, err = c.importRecord(rule.Resource, rule.Owner, models.HookActionCreate, record,
+				newModel, now)
+		}
+
+		if err != nil {
+			result.Error = err.Error()
+		}
+
+		if storeErr := c.BC.SetEmailRuleResult(rule.ID, now, err); storeErr != nil {
+			log.Println("Error storing the result of an email rule:", storeErr)
+		}
+
+		break
+	}
+
+	return result, nil
+}
+
+// matchEmailRule reports whether the sender, one of the recipients and the
+// subject of an email match the patterns of a rule, and returns the named
+// groups of the subject pattern.
+func matchEmailRule(rule models.EmailRule, msg *mailbox.Message) (map[string]string, bool) {
+	from, err := regexp.Compile(rule.FromPattern)
+	if err != nil || !from.MatchString(msg.From) {
+		return nil, false
+	}
+
+	to, err := regexp.Compile(rule.ToPattern)
+	if err != nil || (rule.ToPattern != "" && !slices.ContainsFunc(msg.To, to.MatchString)) {
+		return nil, false
+	}
+
+	subject, err := regexp.Compile(rule.SubjectPattern)
+	if err != nil {
+		return nil, false
+	}
+
+	match := subject.FindStringSubmatch(msg.Subject)
+	if match == nil {
+		return nil, false
+	}
+
+	groups := map[string]string{}
+
+	for i, name := range subject.SubexpNames() {
+		if name != "" {
+			groups[name] = match[i]
+		}
+	}
+
+	return groups, true
+}
+
+// emailRecord builds the record of an email from the mapping of a rule.
+func emailRecord(rule models.EmailRule, msg *mailbox.Message, groups map[string]string) (map[string]interface{}, error) {
+	mapping, err := rule.FieldMapping()
+	if err != nil {
+		return nil, err
+	}
+
+	record := make(map[string]interface{}, len(mapping))
+
+	for field, source := range mapping {
+		if group, ok := strings.CutPrefix(source, "subject."); ok {
+			record[field] = groups[group]
+
+			continue
+		}
+
+		if value, ok := emailValue(msg, source); ok {
+			record[field] = value
+		}
+	}
+
+	return record, nil
+}
+
+// emailValue returns a value of an email by its name in models.EmailSources,
+// or false if the email doesn't have it (e.g. no valid Date header).
+func emailValue(msg *mailbox.Message, source string) (interface{}, bool) {
+	switch source {
+	case "subject":
+		return msg.Subject, true
+	case "body":
+		return msg.Body(), true
+	case "text":
+		return msg.Text, true
+	case "html":
+		return msg.HTML, true
+	case "from":
+		return msg.From, true
+	case "from_name":
+		return msg.FromName, true
+	case "to":
+		return strings.Join(msg.To, ", "), true
+	case "date":
+		if msg.Date.IsZero() {
+			return nil, false
+		}
+
+		return msg.Date.UTC().Format(time.RFC3339), true
+	case "message_id":
+		return msg.MessageID, true
+	case "attachments":
+		// Mapped to a JSON field, with the base64 content of each file
+		attachments := make([]map[string]interface{}, 0, len(msg.Attachments))
+		for _, attachment := range msg.Attachments {
+			attachments = append(attachments, map[string]interface{}{
+				"filename":     attachment.Filename,
+				"content_type": attachment.ContentType,
+				"size":         len(attachment.Content),
+				"content":      base64.StdEncoding.EncodeToString(attachment.Content),
+			})
+		}
+
+		return attachments, true
+	case "attachment_names":
+		names := make([]string, 0, len(msg.Attachments))
+		for _, attachment := range msg.Attachments {
+			names = append(names, attachment.Filename)
+		}
+
+		return strings.Join(names, ", "), true
+	}
+
+	return nil, false
+}
+
+// validMailgunSignature reports whether a Mailgun signature is the hex
+// HMAC-SHA256 of its timestamp and token with the signing key, compared in
+// constant time, and the timestamp is at most mailgunMaxAge away from now.
+func validMailgunSignature(key, timestamp, token, signature string, now time.Time) bool {
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || token == "" {
+		return false
+	}
+
+	if age := now.Sub(time.Unix(seconds, 0)); age > mailgunMaxAge || age < -mailgunMaxAge {
+		return false
+	}
+
+	decoded, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(timestamp + token))
+
+	return hmac.Equal(decoded, mac.Sum(nil))
+}
+
+// mailgunMessage builds an email from the parsed form Mailgun posts.
+func mailgunMessage(r *http.Request) (*mailbox.Message, error) {
+	msg := &mailbox.Message{
+		Subject: r.PostFormValue("subject"),
+		Text:    r.PostFormValue("body-plain"),
+		HTML:    r.PostFormValue("body-html"),
+	}
+
+	if from, err := mail.ParseAddress(r.PostFormValue("from")); err == nil {
+		msg.From, msg.FromName = from.Address, from.Name
+	} else {
+		msg.From = r.PostFormValue("sender")
+	}
+
+	for _, recipient := range strings.Split(r.PostFormValue("recipient"), ",") {
+		if recipient = strings.TrimSpace(recipient); recipient != "" {
+			msg.To = append(msg.To, recipient)
+		}
+	}
+
+	// message-headers is a JSON array of [name, value] pairs
+	var headers [][]string
+	if raw := r.PostFormValue("message-headers"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &headers); err != nil {
+			return nil, errors.New("message-headers is not a JSON array of headers")
+		}
+	}
+
+	for _, header := range headers {
+		if len(header) != 2 {
+			continue
+		}
+
+		switch strings.ToLower(header[0]) {
+		case "message-id":
+			msg.MessageID = strings.Trim(strings.TrimSpace(header[1]), "<>")
+		case "date":
+			if date, err := mail.ParseDate(header[1]); err == nil {
+				msg.Date = date
+			}
+		}
+	}
+
+	if r.MultipartForm == nil {
+		return msg, nil
+	}
+
+	count, _ := strconv.Atoi(r.PostFormValue("attachment-count"))
+
+	for i := 1; i <= count; i++ {
+		files := r.MultipartForm.File["attachment-"+strconv.Itoa(i)]
+		if len(files) == 0 {
+			continue
+		}
+
+		file, err := files[0].Open()
+		if err != nil {
+			return nil, err
+		}
+
+		content, err := io.ReadAll(file)
+		_ = file.Close()
+
+		if err != nil {
+			return nil, err
+		}
+
+		msg.Attachments = append(msg.Attachments, mailbox.Attachment{
+			Filename: files[0].Filename, ContentType: files[0].Header.Get("Content-Type"), Content: content,
+		})
+	}
+
+	return msg, nil
+}
+
+// decodeEmailRule decodes and validates an email rule, writing a 400 response
+// on failure. The owner of the rule is the authenticated admin.
+func decodeEmailRule(w http.ResponseWriter, r *http.Request, newModel ModelFactory) (models.EmailRule, bool) {
+	var rule models.EmailRule
+
+	err := json.NewDecoder(r.Body).Decode(&rule)
+	if err == nil {
+		rule.Owner = middlewares.UsernameFromContext(r.Context())
+		err = rule.Validate()
+	}
+
+	if err == nil {
+		if _, ok := newModel(rule.Resource); !ok {
+			err = errors.New("Invalid resource: " + rule.Resource)
+		}
+	}
+
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return rule, false
+	}
+
+	return rule, true
+}
+
+// emailRuleIDParam parses the {id} URL parameter, writing a 400 response on failure.
+func emailRuleIDParam(w http.ResponseWriter, r *http.Request) (uint, bool) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "Invalid email rule ID"})
+
+		return 0, false
+	}
+
+	return uint(id), true
+}
+
+// writeEmailRuleError writes a 404 for unknown email rules and a 500 for any other error.
+func writeEmailRuleError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	if errors.Is(err, database.ErrEmailRuleNotFound) {
+		status = http.StatusNotFound
+	}
+
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+}
@@ -0,0 +1,19 @@
+//go:build !fastjson
+
+package controllers
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// JSONEncoder is the name of the JSON encoder of the hot endpoints (GetAll and GetByID).
+const JSONEncoder = "encoding/json"
+
+// EncodeJSON writes the JSON encoding of a value, followed by a newline.
+//
+// It is used by the hot endpoints; build with -tags fastjson to replace
+// encoding/json with a faster, compatible encoder.
+func EncodeJSON(w io.Writer, v interface{}) error {
+	return json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,20 @@
+//go:build fastjson
+
+package controllers
+
+import (
+	"io"
+
+	"github.com/segmentio/encoding/json"
+)
+
+// JSONEncoder is the name of the JSON encoder of the hot endpoints (GetAll and GetByID).
+const JSONEncoder = "segmentio/encoding"
+
+// EncodeJSON writes the JSON encoding of a value, followed by a newline.
+//
+// segmentio/encoding produces the same output as encoding/json, with fewer
+// allocations and without reflection on every call.
+func EncodeJSON(w io.Writer, v interface{}) error {
+	return json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,676 @@
+package controllers
+
+import (
+	"bufio"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/r4ulcl/api_template/api/middlewares"
+	"github.com/r4ulcl/api_template/database"
+	"github.com/r4ulcl/api_template/utils"
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+// ExportSettings configures the exports of the resources.
+type ExportSettings struct {
+	// Dir is the directory of the files of the exports. Empty disables the exports.
+	Dir string
+
+	// PartRecords is the largest number of records of a file.
+	PartRecords int
+
+	// TTL is how long the files of a finished export, and their links, are kept.
+	TTL time.Duration
+
+	// Secret signs the download links.
+	Secret []byte
+}
+
+// sign returns the signature of the download link of a part.
+func (s ExportSettings) sign(id uint, part int, expires int64) string {
+	mac := hmac.New(sha256.New, s.Secret)
+	fmt.Fprintf(mac, "export:%d:%d:%d", id, part, expires)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// jobDir returns the directory of the files of an export.
+func (s ExportSettings) jobDir(id uint) string {
+	return filepath.Join(s.Dir, strconv.FormatUint(uint64(id), 10))
+}
+
+// partFile returns the name of the file of a part.
+func partFile(format string, number int) string {
+	return fmt.Sprintf("part-%04d.%s", number, format)
+}
+
+// exportSource is a resource written to the parts of an export.
+type exportSource struct {
+	resource string
+	opts     database.QueryOptions
+	model    interface{}
+}
+
+// CreateExport starts an export of the records of a resource matching the
+// filters and sort order of the query, like GET /{resource}. The reserved
+// "format" parameter is "json" (default) or "csv", and "anonymize=true"
+// replaces the personal data of the records (see utils.Anonymizer).
+//
+// Parameters:
+// - w: The HTTP response writer.
+// - r: The HTTP request with the query parameters.
+// - resource: The name of the resource (e.g. "example1").
+// - model: A pointer to a slice of the model of the resource.
+//
+// Returns:
+// - HTTP 400 if the format, a filter or the sort order is invalid.
+// - HTTP 404 if the exports are disabled.
+// - HTTP 503 if too many exports are running (see middlewares.ConcurrencyLimiter).
+// - HTTP 202 with the queued models.ExportJob and its URL in the Location header.
+func (c *Controller) CreateExport(w http.ResponseWriter, r *http.Request, resource string, model interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if c.Exports.Dir == "" {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "Exports are disabled"})
+
+		return
+	}
+
+	query := r.URL.Query()
+
+	format, anonymize, err := exportFormat(query)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	opts, err := parseQueryOptions(query)
+
+	var total int64
+	if err == nil {
+		total, err = c.BC.CountRecords(model, opts)
+	}
+
+	if err != nil {
+		status := http.StatusBadRequest
+		if !errors.Is(err, database.ErrInvalidField) && !errors.Is(err, database.ErrInvalidFilter) {
+			status = http.StatusInternalServerError
+		}
+
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	job := models.ExportJob{
+		Resource:   resource,
+		Owner:      middlewares.UsernameFromContext(r.Context()),
+		Format:     format,
+		Query:      query.Encode(),
+		Anonymized: anonymize,
+		Total:      total,
+	}
+
+	c.startExport(w, r, job, []exportSource{{resource: resource, opts: opts, model: model}})
+}
+
+// CreateDump starts an export of every record of several resources into one
+// job, e.g. with "anonymize=true" to share a realistic dataset without
+// personal data with the developers. The parts hold the records of one
+// resource each, and the "resources" parameter (comma-separated) limits the
+// resources dumped. The "format" and "anonymize" parameters are those of CreateExport.
+//
+// Parameters:
+// - w: The HTTP response writer.
+// - r: The HTTP request with the query parameters.
+// - resources: The resources dumped by default, in order.
+// - newSlice: Returns a pointer to a new empty slice of the model of a resource.
+//
+// Returns:
+// - HTTP 400 if the format or a resource is invalid.
+// - HTTP 404 if the exports are disabled.
+// - HTTP 503 if too many exports are running (see middlewares.ConcurrencyLimiter).
+// - HTTP 202 with the queued models.ExportJob and its URL in the Location header.
+func (c *Controller) CreateDump(w http.ResponseWriter, r *http.Request, resources []string, newSlice ModelFactory) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if c.Exports.Dir == "" {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "Exports are disabled"})
+
+		return
+	}
+
+	query := r.URL.Query()
+
+	format, anonymize, err := exportFormat(query)
+
+	if value := query.Get("resources"); value != "" && err == nil {
+		resources = strings.Split(value, ",")
+	}
+
+	sources := make([]exportSource, 0, len(resources))
+
+	var total int64
+
+	for _, resource := range resources {
+		if err != nil {
+			break
+		}
+
+		model, ok := newSlice(strings.TrimSpace(resource))
+		if !ok {
+			err = fmt.Errorf("unknown resource %q", resource)
+
+			break
+		}
+
+		var count int64
+
+		source := exportSource{resource: strings.TrimSpace(resource), opts: database.QueryOptions{}, model: model}
+		if count, err = c.BC.CountRecords(model, source.opts); err == nil {
+			total += count
+			sources = append(sources, source)
+		}
+	}
+
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	names := make([]string, len(sources))
+	for i, source := range sources {
+		names[i] = source.resource
+	}
+
+	job := models.ExportJob{
+		Resource:   "dump",
+		Owner:      middlewares.UsernameFromContext(r.Context()),
+		Format:     format,
+		Query:      url.Values{"resources": {strings.Join(names, ",")}}.Encode(),
+		Anonymized: anonymize,
+		Total:      total,
+	}
+
+	c.startExport(w, r, job, sources)
+}
+
+// startExport stores a new export and writes its parts in the background,
+// holding a slot of the export concurrency limit until they are written.
+func (c *Controller) startExport(w http.ResponseWriter, r *http.Request, job models.ExportJob, sources []exportSource) {
+	release, ok := c.Concurrency.Check(w, r, middlewares.ConcurrencyExport)
+	if !ok {
+		return
+	}
+
+	if err := c.BC.CreateExportJob(&job); err != nil {
+		release()
+
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	go func() {
+		defer release()
+
+		c.runExport(job, sources)
+	}()
+
+	w.Header().Set("Location", "/exports/"+strconv.FormatUint(uint64(job.ID), 10))
+	w.WriteHeader(http.StatusAccepted)
+	_ = json.NewEncoder(w).Encode(job)
+}
+
+// exportFormat reads and removes the "format" and "anonymize" parameters of an export.
+func exportFormat(query url.Values) (string, bool, error) {
+	format := query.Get("format")
+	if format == "" {
+		format = "json"
+	}
+
+	if format != "json" && format != "csv" {
+		return "", false, errors.New("Invalid format, expected json or csv")
+	}
+
+	anonymize := false
+
+	if value := query.Get("anonymize"); value != "" {
+		var err error
+		if anonymize, err = strconv.ParseBool(value); err != nil {
+			return "", false, errors.New("anonymize must be true or false")
+		}
+	}
+
+	query.Del("format")
+	query.Del("anonymize")
+
+	return format, anonymize, nil
+}
+
+// GetExport returns the progress of an export and, once it is done, the signed
+// download links of its parts. Users only see their own exports, admins see
+// every export.
+//
+// Returns:
+// - HTTP 400 if the ID is invalid.
+// - HTTP 404 if the export does not exist or belongs to another user.
+// - HTTP 500 if the retrieval fails.
+// - JSON object of the models.ExportJob if successful.
+func (c *Controller) GetExport(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "Invalid export ID"})
+
+		return
+	}
+
+	job, err := c.BC.GetExportJob(uint(id))
+
+	if err == nil && job.Owner != middlewares.UsernameFromContext(r.Context()) &&
+		middlewares.RoleFromContext(r.Context()) != string(models.AdminRole) {
+		err = database.ErrExportJobNotFound
+	}
+
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, database.ErrExportJobNotFound) {
+			status = http.StatusNotFound
+		}
+
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	if job.Status == models.BulkJobDone && job.ExpiresAt != nil {
+		expires := job.ExpiresAt.Unix()
+
+		for i := range job.Parts {
+			job.Parts[i].URL = fmt.Sprintf("/exports/%d/parts/%d?expires=%d&signature=%s",
+				job.ID, job.Parts[i].Number, expires, c.Exports.sign(job.ID, job.Parts[i].Number, expires))
+		}
+	}
+
+	_ = json.NewEncoder(w).Encode(job)
+}
+
+// DownloadExportPart serves a part of an export to the holders of its signed
+// link, without authentication so download managers can use it. Range
+// requests are supported, with the checksum of the part as ETag, so an
+// interrupted download is resumed instead of restarted.
+//
+// Returns:
+// - HTTP 403 if the signature is invalid or the link expired.
+// - HTTP 404 if the export or the part does not exist anymore.
+// - HTTP 200 with the part, or 206 with the requested range.
+func (c *Controller) DownloadExportPart(w http.ResponseWriter, r *http.Request) {
+	id, idErr := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	number, numberErr := strconv.Atoi(mux.Vars(r)["number"])
+	expires, expiresErr := strconv.ParseInt(r.URL.Query().Get("expires"), 10, 64)
+
+	if idErr != nil || numberErr != nil || expiresErr != nil || time.Now().Unix() >= expires ||
+		!hmac.Equal([]byte(r.URL.Query().Get("signature")), []byte(c.Exports.sign(uint(id), number, expires))) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "Invalid or expired download link"})
+
+		return
+	}
+
+	job, err := c.BC.GetExportJob(uint(id))
+	if err != nil || job.Status != models.BulkJobDone || number < 1 || number > len(job.Parts) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "Export part not found"})
+
+		return
+	}
+
+	name := partFile(job.Format, number)
+
+	file, err := os.Open(filepath.Join(c.Exports.jobDir(job.ID), name))
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "Export part not found"})
+
+		return
+	}
+	defer file.Close()
+
+	contentType := "application/json"
+	if job.Format == "csv" {
+		contentType = "text/csv"
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition",
+		fmt.Sprintf(`attachment; filename="%s-%d-%s"`, job.Parts[number-1].Resource, job.ID, name))
+	w.Header().Set("ETag", `"`+job.Parts[number-1].SHA256+`"`)
+
+	// ServeContent answers the Range and If-Range requests
+	http.ServeContent(w, r, name, *job.FinishedAt, file)
+}
+
+// WatchExports deletes the expired exports and their files every interval
+// until the context is cancelled.
+//
+// Parameters:
+// - ctx: Stops the cleanup when cancelled.
+// - interval: How often the expired exports are deleted.
+func (c *Controller) WatchExports(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		jobs, err := c.BC.ExpiredExportJobs(time.Now())
+		if err != nil {
+			log.Println("Error listing expired exports:", err)
+		}
+
+		for _, job := range jobs {
+			if err := os.RemoveAll(c.Exports.jobDir(job.ID)); err != nil {
+				log.Printf("Error deleting the files of export %d: %v", job.ID, err)
+
+				continue
+			}
+
+			if err := c.BC.DeleteExportJob(job.ID); err != nil {
+				log.Printf("Error deleting export %d: %v", job.ID, err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runExport writes the records of an export to its parts, storing its
+// progress after each batch of records read.
+func (c *Controller) runExport(job models.ExportJob, sources []exportSource) {
+	job.Status = models.BulkJobRunning
+	c.saveExportJob(&job)
+
+	var anonymizer *utils.Anonymizer
+
+	var err error
+
+	if job.Anonymized {
+		anonymizer, err = utils.NewAnonymizer()
+	}
+
+	for _, source := range sources {
+		if err != nil {
+			break
+		}
+
+		err = c.writeExport(&job, source, anonymizer)
+	}
+
+	now := time.Now()
+	job.FinishedAt = &now
+
+	if err != nil {
+		// The partial files are deleted right away
+		job.Status = models.BulkJobFailed
+		job.Error = err.Error()
+		job.ExpiresAt = &now
+	} else {
+		expires := now.Add(c.Exports.TTL)
+		job.Status = models.BulkJobDone
+		job.ExpiresAt = &expires
+	}
+
+	c.saveExportJob(&job)
+}
+
+// writeExport reads the records of a resource of an export in batches and
+// writes them to parts of at most PartRecords records, anonymized by the
+// anonymizer if not nil.
+func (c *Controller) writeExport(job *models.ExportJob, source exportSource, anonymizer *utils.Anonymizer) error {
+	dir := c.Exports.jobDir(job.ID)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+
+	partRecords := max(c.Exports.PartRecords, 1)
+	columns := exportColumns(reflect.TypeOf(source.model).Elem().Elem())
+
+	var part *exportPartWriter
+
+	err := c.BC.ForEachBatch(source.model, source.opts, c.BC.BulkBatchSize(), func(batch interface{}) error {
+		records := reflect.ValueOf(batch).Elem()
+
+		for i := range records.Len() {
+			if part != nil && part.part.Records >= partRecords {
+				if err := c.closeExportPart(job, part); err != nil {
+					return err
+				}
+
+				part = nil
+			}
+
+			if part == nil {
+				var err error
+				if part, err = newExportPartWriter(dir, job.Format, len(job.Parts)+1, columns); err != nil {
+					return err
+				}
+
+				part.part.Resource = source.resource
+			}
+
+			record := records.Index(i).Addr().Interface()
+			anonymizer.Anonymize(record)
+
+			if err := part.write(record); err != nil {
+				return err
+			}
+		}
+
+		job.Exported += int64(records.Len())
+		c.saveExportJob(job)
+
+		return nil
+	})
+
+	if part != nil {
+		if err == nil {
+			err = c.closeExportPart(job, part)
+		} else {
+			part.file.Close()
+		}
+	}
+
+	return err
+}
+
+// closeExportPart finishes the file of a part and adds it to the export.
+func (c *Controller) closeExportPart(job *models.ExportJob, part *exportPartWriter) error {
+	if err := part.close(); err != nil {
+		return err
+	}
+
+	job.Parts = append(job.Parts, part.part)
+
+	return nil
+}
+
+// saveExportJob stores the progress of an export, logging the errors as the
+// export runs in the background.
+func (c *Controller) saveExportJob(job *models.ExportJob) {
+	if err := c.BC.SaveExportJob(job); err != nil {
+		log.Printf("Error storing the progress of export %d: %v", job.ID, err)
+	}
+}
+
+// exportPartWriter writes the records of a part to its file, computing its checksum.
+type exportPartWriter struct {
+	part    models.ExportPart
+	format  string
+	columns []string
+	file    *os.File
+	hash    hash.Hash
+	out     *bufio.Writer
+	csv     *csv.Writer
+}
+
+// newExportPartWriter creates the file of a part and writes its header: the
+// opening bracket of the JSON array, or the header row of the CSV file.
+func newExportPartWriter(dir, format string, number int, columns []string) (*exportPartWriter, error) {
+	file, err := os.Create(filepath.Join(dir, partFile(format, number)))
+	if err != nil {
+		return nil, err
+	}
+
+	pw := &exportPartWriter{
+		part:    models.ExportPart{Number: number},
+		format:  format,
+		columns: columns,
+		file:    file,
+		hash:    sha256.New(),
+	}
+	pw.out = bufio.NewWriter(io.MultiWriter(file, pw.hash))
+
+	if format == "csv" {
+		pw.csv = csv.NewWriter(pw.out)
+		err = pw.csv.Write(columns)
+	} else {
+		_, err = pw.out.WriteString("[")
+	}
+
+	if err != nil {
+		file.Close()
+
+		return nil, err
+	}
+
+	return pw, nil
+}
+
+// write appends a record to the part. CSV cells hold the strings as they are
+// and the other values as JSON, as read by the bulk imports.
+func (pw *exportPartWriter) write(record interface{}) error {
+	encoded, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	pw.part.Records++
+
+	if pw.csv == nil {
+		separator := "\n"
+		if pw.part.Records > 1 {
+			separator = ",\n"
+		}
+
+		_, err = pw.out.WriteString(separator + string(encoded))
+
+		return err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(encoded, &fields); err != nil {
+		return err
+	}
+
+	row := make([]string, len(pw.columns))
+
+	for i, column := range pw.columns {
+		value := fields[column]
+
+		var text string
+
+		switch {
+		case len(value) == 0 || string(value) == "null":
+		case json.Unmarshal(value, &text) == nil:
+			row[i] = text
+		default:
+			row[i] = string(value)
+		}
+	}
+
+	return pw.csv.Write(row)
+}
+
+// close finishes the file of the part and records its size and checksum.
+func (pw *exportPartWriter) close() error {
+	var err error
+
+	if pw.csv != nil {
+		pw.csv.Flush()
+		err = pw.csv.Error()
+	} else {
+		_, err = pw.out.WriteString("\n]\n")
+	}
+
+	if err == nil {
+		err = pw.out.Flush()
+	}
+
+	if closeErr := pw.file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(pw.file.Name())
+	if err != nil {
+		return err
+	}
+
+	pw.part.Bytes = info.Size()
+	pw.part.SHA256 = hex.EncodeToString(pw.hash.Sum(nil))
+
+	return nil
+}
+
+// exportColumns returns the JSON names of the fields of a struct type, in
+// order, as the columns of the CSV exports.
+func exportColumns(modelType reflect.Type) []string {
+	columns := []string{}
+
+	for i := range modelType.NumField() {
+		name := strings.Split(modelType.Field(i).Tag.Get("json"), ",")[0]
+		if name != "" && name != "-" {
+			columns = append(columns, name)
+		}
+	}
+
+	return columns
+}
@@ -0,0 +1,187 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/r4ulcl/api_template/api/middlewares"
+	"github.com/r4ulcl/api_template/database"
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+// ModelFactory returns a new instance of the model of a resource, or false if
+// the resource is unknown or not readable by every user.
+type ModelFactory func(resource string) (interface{}, bool)
+
+// GetFavorites returns the records pinned by the authenticated user.
+//
+// With expand=true, each favorite also holds its record, fetched with one query
+// per resource. Records deleted since, or not visible to the user, have none.
+//
+// Parameters:
+// - w: The HTTP response writer.
+// - r: The HTTP request with the optional "expand" query parameter.
+// - newModel: Returns the model of a resource, to fetch the records.
+//
+// Returns:
+// - HTTP 400 if "expand" is not a boolean.
+// - HTTP 500 if the retrieval fails.
+// - JSON array of favorites if successful.
+func (c *Controller) GetFavorites(w http.ResponseWriter, r *http.Request, newModel ModelFactory) {
+	w.Header().Set("Content-Type", "application/json")
+
+	expand := false
+
+	if raw := r.URL.Query().Get("expand"); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "invalid expand: " + raw})
+
+			return
+		}
+
+		expand = parsed
+	}
+
+	favorites, err := c.BC.ListFavorites(middlewares.UsernameFromContext(r.Context()))
+	if err == nil && expand {
+		err = c.expandFavorites(r, favorites, newModel)
+	}
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(favorites)
+}
+
+// expandFavorites sets the Record of each favorite, grouping the IDs by resource.
+func (c *Controller) expandFavorites(r *http.Request, favorites []models.UserFavorite, newModel ModelFactory) error {
+	ids := make(map[string][]string)
+	for _, favorite := range favorites {
+		ids[favorite.Resource] = append(ids[favorite.Resource], favorite.RecordID)
+	}
+
+	publishedOnly := middlewares.PublishedOnlyFromContext(r.Context())
+
+	for resource, resourceIDs := range ids {
+		model, ok := newModel(resource)
+		if !ok {
+			continue
+		}
+
+		records, err := c.BC.GetRecordsByIDs(model, resourceIDs)
+		if err != nil {
+			return err
+		}
+
+		for i, favorite := range favorites {
+			record, ok := records[favorite.RecordID]
+			if favorite.Resource != resource || !ok || (publishedOnly && !database.IsPublished(record)) {
+				continue
+			}
+
+			favorites[i].Record = record
+		}
+	}
+
+	return nil
+}
+
+// AddFavorite pins the record in the URL for the authenticated user.
+//
+// Parameters:
+// - w: The HTTP response writer.
+// - r: The HTTP request with the {resource} and {id} URL parameters.
+// - newModel: Returns the model of a resource, to check that the record exists.
+//
+// Returns:
+// - HTTP 200 if the record was already pinned.
+// - HTTP 201 if the record is pinned.
+// - HTTP 404 if the resource or the record does not exist.
+// - HTTP 409 if the user already pinned too many records.
+// - HTTP 500 if the favorite cannot be stored.
+func (c *Controller) AddFavorite(w http.ResponseWriter, r *http.Request, newModel ModelFactory) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	resource, tokenizedID := vars["resource"], vars["id"]
+
+	model, ok := newModel(resource)
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "Invalid resource"})
+
+		return
+	}
+
+	err := c.BC.GetRecordsByID(model, tokenizedID)
+	if err == nil && middlewares.PublishedOnlyFromContext(r.Context()) && !database.IsPublished(model) {
+		err = database.ErrRecordNotFound
+	}
+
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, database.ErrRecordNotFound) {
+			status = http.StatusNotFound
+		}
+
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	favorite, created, err := c.BC.AddFavorite(middlewares.UsernameFromContext(r.Context()), resource, tokenizedID)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, database.ErrFavoriteLimit) {
+			status = http.StatusConflict
+		}
+
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	if created {
+		w.WriteHeader(http.StatusCreated)
+	}
+
+	_ = json.NewEncoder(w).Encode(favorite)
+}
+
+// RemoveFavorite unpins the record in the URL for the authenticated user.
+//
+// Returns:
+// - HTTP 404 if the record is not pinned.
+// - HTTP 500 if the favorite cannot be deleted.
+// - JSON confirmation message if successful.
+func (c *Controller) RemoveFavorite(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+
+	err := c.BC.RemoveFavorite(middlewares.UsernameFromContext(r.Context()), vars["resource"], vars["id"])
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, database.ErrFavoriteNotFound) {
+			status = http.StatusNotFound
+		}
+
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(map[string]string{"message": "Deleted successfully"})
+}
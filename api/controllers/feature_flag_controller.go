@@ -0,0 +1,116 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/r4ulcl/api_template/api/middlewares"
+	"github.com/r4ulcl/api_template/database"
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+// GetFlags returns the state of every feature flag for the authenticated user,
+// as a map of flag name to boolean.
+func (c *Controller) GetFlags(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	ctx := r.Context()
+	_ = json.NewEncoder(w).Encode(c.Flags.Evaluate(middlewares.UsernameFromContext(ctx), middlewares.RoleFromContext(ctx)))
+}
+
+// ListFeatureFlags returns every feature flag with its targeting.
+//
+// Returns:
+// - HTTP 500 if the retrieval fails.
+// - JSON array of flags if successful.
+func (c *Controller) ListFeatureFlags(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	flags, err := c.BC.ListFeatureFlags()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(flags)
+}
+
+// SaveFeatureFlag creates or replaces the feature flag named in the URL.
+//
+// Returns:
+// - HTTP 400 if the request body is invalid.
+// - HTTP 500 if the flag cannot be stored.
+// - JSON object of the stored flag if successful.
+func (c *Controller) SaveFeatureFlag(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var flag models.FeatureFlag
+	if err := json.NewDecoder(r.Body).Decode(&flag); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	flag.Name = mux.Vars(r)["name"]
+
+	before := storedConfig(c.BC.GetFeatureFlag(flag.Name))
+
+	if err := c.BC.SaveFeatureFlag(&flag); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	c.ReloadFeatureFlags()
+	c.auditConfig(r, models.ConfigFeatureFlag, flag.Name, before, flag)
+
+	_ = json.NewEncoder(w).Encode(flag)
+}
+
+// DeleteFeatureFlag removes the feature flag named in the URL.
+//
+// Returns:
+// - HTTP 404 if the flag does not exist.
+// - HTTP 500 if deletion fails.
+// - JSON confirmation message if successful.
+func (c *Controller) DeleteFeatureFlag(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	name := mux.Vars(r)["name"]
+	before := storedConfig(c.BC.GetFeatureFlag(name))
+
+	if err := c.BC.DeleteFeatureFlag(name); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, database.ErrFlagNotFound) {
+			status = http.StatusNotFound
+		}
+
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	c.ReloadFeatureFlags()
+	c.auditConfig(r, models.ConfigFeatureFlag, name, before, nil)
+
+	_ = json.NewEncoder(w).Encode(map[string]string{"message": "Deleted successfully"})
+}
+
+// ReloadFeatureFlags loads the flags from the database into the flag cache.
+func (c *Controller) ReloadFeatureFlags() {
+	if c.Flags == nil {
+		return
+	}
+
+	if err := c.Flags.Reload(); err != nil {
+		log.Println("Error loading feature flags:", err)
+	}
+}
@@ -0,0 +1,38 @@
+package controllers_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/r4ulcl/api_template/testsupport"
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+// TestGoldenCRUD compares the responses of the CRUD routes of a resource and of their errors with the golden files.
+func TestGoldenCRUD(t *testing.T) {
+	srv := testsupport.NewTestServer(t)
+	admin := srv.AdminToken(t)
+
+	record := models.Example1{Field1: "golden_1", Field2: "value"}
+
+	steps := []struct {
+		name   string
+		method string
+		path   string
+		body   interface{}
+	}{
+		{"example1_create", http.MethodPost, "/example1", record},
+		{"example1_get", http.MethodGet, "/example1/golden_1", nil},
+		{"example1_list", http.MethodGet, "/example1", nil},
+		{"example1_patch", http.MethodPatch, "/example1/golden_1", map[string]string{"field2": "patched"}},
+		{"example1_put", http.MethodPut, "/example1", models.Example1{Field1: "golden_1", Field2: "replaced"}},
+		{"example1_delete", http.MethodDelete, "/example1/golden_1", nil},
+		{"example1_get_not_found", http.MethodGet, "/example1/golden_1", nil},
+		{"example1_create_invalid_body", http.MethodPost, "/example1", "{"},
+		{"example1_list_invalid_filter", http.MethodGet, "/example1?unknown=1", nil},
+	}
+
+	for _, step := range steps {
+		testsupport.AssertGolden(t, step.name, srv.Do(t, step.method, step.path, step.body, admin))
+	}
+}
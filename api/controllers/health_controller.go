@@ -0,0 +1,58 @@
+package controllers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+// Healthz reports that the process is alive. It never touches the database.
+func (c *Controller) Healthz(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(models.HealthResponse{Status: "ok"})
+}
+
+// Readyz reports whether the service can handle requests, with the state of
+// the monitored dependencies (see WatchDependencies). A dependency down
+// degrades the status without failing the probe: the API still serves what
+// doesn't need it.
+//
+// Returns:
+// - HTTP 503 if the database circuit breaker is open.
+// - HTTP 200 with the database and dependencies state otherwise.
+func (c *Controller) Readyz(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	response := models.HealthResponse{Status: "ok"}
+
+	if c.Health != nil {
+		status := c.Health.Status()
+		response.Database = &status
+
+		if !status.Available {
+			response.Status = "unavailable"
+
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_ = json.NewEncoder(w).Encode(response)
+
+			return
+		}
+	}
+
+	dependencies, err := c.BC.DependencyHealth()
+	if err != nil {
+		log.Println("Error reading the dependencies health:", err)
+	}
+
+	for _, dependency := range dependencies {
+		if dependency.State == models.DependencyDown {
+			response.Status = "degraded"
+		}
+	}
+
+	response.Dependencies = dependencies
+
+	_ = json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,549 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/r4ulcl/api_template/api/middlewares"
+	"github.com/r4ulcl/api_template/database"
+	"github.com/r4ulcl/api_template/utils"
+	"github.com/r4ulcl/api_template/utils/httpclient"
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+const (
+	// maxImportRecords is the largest number of records an importer may fetch per run.
+	maxImportRecords = 10000
+
+	// maxImportBytes is the largest response an importer reads.
+	maxImportBytes = 32 << 20
+
+	// importTimeout bounds the request of an importer to the external API.
+	importTimeout = 60 * time.Second
+)
+
+var (
+	// importClient fetches the records of the importers, with retries.
+	importClient = httpclient.New(importTimeout)
+
+	// errImportTooLarge is returned when an external API returns more than maxImportRecords records.
+	errImportTooLarge = fmt.Errorf("the response has more than %d records", maxImportRecords)
+
+	// errImportRunning is returned when an importer is run while another run starts.
+	errImportRunning = errors.New("the importer is already running")
+
+	// errImportFetch is returned when the external API of an importer can't be read.
+	errImportFetch = errors.New("fetching the records failed")
+)
+
+// ListImporters returns every importer.
+//
+// Returns:
+// - HTTP 500 if the retrieval fails.
+// - JSON array of importers if successful.
+func (c *Controller) ListImporters(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	importers, err := c.BC.ListImporters()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(importers)
+}
+
+// CreateImporter stores a new importer, owned by the authenticated admin.
+//
+// Parameters:
+// - w: The HTTP response writer.
+// - r: The HTTP request with a models.Importer.
+// - newModel: Returns a pointer to a model of a resource, to check the resource of the importer.
+//
+// Returns:
+// - HTTP 400 if the importer is invalid.
+// - HTTP 500 if the importer cannot be stored.
+// - HTTP 201 with the stored importer if successful.
+func (c *Controller) CreateImporter(w http.ResponseWriter, r *http.Request, newModel ModelFactory) {
+	w.Header().Set("Content-Type", "application/json")
+
+	importer, ok := decodeImporter(w, r, newModel)
+	if !ok {
+		return
+	}
+
+	if err := c.BC.CreateImporter(&importer); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(importer)
+}
+
+// UpdateImporter replaces an existing importer, now owned by the authenticated admin.
+//
+// Parameters:
+// - w: The HTTP response writer.
+// - r: The HTTP request with a models.Importer.
+// - newModel: Returns a pointer to a model of a resource, to check the resource of the importer.
+//
+// Returns:
+// - HTTP 400 if the importer or its ID is invalid.
+// - HTTP 404 if the importer does not exist.
+// - HTTP 500 if the importer cannot be stored.
+// - JSON object of the stored importer if successful.
+func (c *Controller) UpdateImporter(w http.ResponseWriter, r *http.Request, newModel ModelFactory) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, ok := importerIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	importer, ok := decodeImporter(w, r, newModel)
+	if !ok {
+		return
+	}
+
+	if err := c.BC.UpdateImporter(id, &importer); err != nil {
+		writeImporterError(w, err)
+
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(importer)
+}
+
+// DeleteImporter removes an importer. The imported records are kept.
+//
+// Returns:
+// - HTTP 400 if the ID is invalid.
+// - HTTP 404 if the importer does not exist.
+// - HTTP 500 if deletion fails.
+// - JSON confirmation message if successful.
+func (c *Controller) DeleteImporter(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, ok := importerIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	if err := c.BC.DeleteImporter(id); err != nil {
+		writeImporterError(w, err)
+
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(map[string]string{"message": "Deleted successfully"})
+}
+
+// RunImporter runs an importer now, whether it is scheduled or not.
+//
+// Parameters:
+// - w: The HTTP response writer.
+// - r: The HTTP request with the {id} URL parameter.
+// - newModel: Returns a pointer to a model of a resource, to decode the imported records.
+//
+// Returns:
+// - HTTP 400 if the ID is invalid.
+// - HTTP 404 if the importer does not exist.
+// - HTTP 409 if the importer is already running.
+// - HTTP 502 if the external API can't be read.
+// - HTTP 500 if the run fails otherwise.
+// - JSON object of the models.ImportResult if successful, with the records that failed.
+func (c *Controller) RunImporter(w http.ResponseWriter, r *http.Request, newModel ModelFactory) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, ok := importerIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	importer, err := c.BC.GetImporter(id)
+	if err != nil {
+		writeImporterError(w, err)
+
+		return
+	}
+
+	result, err := c.claimAndRunImporter(importer, newModel)
+	if err != nil {
+		writeImporterError(w, err)
+
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(result)
+}
+
+// WatchImporters runs the due scheduled importers every interval until the context is cancelled.
+//
+// Parameters:
+// - ctx: Stops the scheduler when cancelled.
+// - interval: How often the due importers are checked.
+// - newModel: Returns a pointer to a model of a resource, to decode the imported records.
+func (c *Controller) WatchImporters(ctx context.Context, interval time.Duration, newModel ModelFactory) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			importers, err := c.BC.DueImporters(time.Now())
+			if err != nil {
+				log.Println("Error listing due importers:", err)
+
+				continue
+			}
+
+			for _, importer := range importers {
+				if _, err := c.claimAndRunImporter(importer, newModel); err != nil && !errors.Is(err, errImportRunning) {
+					log.Println("Error running importer "+importer.Name+":", err)
+				}
+			}
+		}
+	}
+}
+
+// claimAndRunImporter records the start of a run of an importer, runs it, and
+// stores its error, if any. Records failing to import are reported as the
+// error of the run, which still imports the others.
+func (c *Controller) claimAndRunImporter(importer models.Importer, newModel ModelFactory) (models.ImportResult, error) {
+	now := time.Now()
+
+	claimed, err := c.BC.ClaimImporter(importer, now)
+	if err != nil {
+		return models.ImportResult{}, err
+	}
+
+	if !claimed {
+		return models.ImportResult{}, errImportRunning
+	}
+
+	result, err := c.runImporter(importer, newModel, now)
+
+	stored := err
+	if stored == nil && len(result.Failed) > 0 {
+		stored = fmt.Errorf("%d of %d records failed, the first at index %d: %s",
+			len(result.Failed), result.Fetched, result.Failed[0].Index, result.Failed[0].Error)
+	}
+
+	if storeErr := c.BC.SetImporterError(importer.ID, stored); storeErr != nil {
+		log.Println("Error storing the result of an importer:", storeErr)
+	}
+
+	return result, err
+}
+
+// runImporter fetches the records of an importer, maps them to its resource
+// and upserts them one by one.
+func (c *Controller) runImporter(importer models.Importer, newModel ModelFactory, now time.Time) (models.ImportResult, error) {
+	result := models.ImportResult{Importer: importer.Name, Resource: importer.Resource, RanAt: now,
+		Failed: []models.ImportFailure{}}
+
+	if _, ok := newModel(importer.Resource); !ok {
+		return result, fmt.Errorf("invalid resource %q in importer %s", importer.Resource, importer.Name)
+	}
+
+	mapping, err := importer.FieldMapping()
+	if err != nil {
+		return result, err
+	}
+
+	records, err := fetchImport(importer)
+	if err != nil {
+		return result, err
+	}
+
+	result.Fetched = len(records)
+
+	for index, item := range records {
+		_, created, err := c.importRecord(importer.Resource, importer.Owner, models.HookActionUpsert,
+			mapImportRecord(item, mapping), newModel, now)
+
+		switch {
+		case err != nil:
+			result.Failed = append(result.Failed, models.ImportFailure{Index: index, Error: err.Error()})
+		case created:
+			result.Created++
+		default:
+			result.Updated++
+		}
+	}
+
+	return result, nil
+}
+
+// importRecord creates a record of a resource, or replaces it if a record with
+// the same primary key exists, as allowed by the action (the importers upsert).
+// The server-populated fields are filled like on create, with the owner of the
+// importer or hook as author.
+//
+// Returns:
+// - The primary key of the record.
+// - true if the record was created, false if it was replaced.
+func (c *Controller) importRecord(resource, owner string, action models.HookAction, record interface{},
+	newModel ModelFactory, now time.Time,
+) (string, bool, error) {
+	if _, ok := record.(map[string]interface{}); !ok {
+		return "", false, errors.New("the record is not a JSON object")
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return "", false, err
+	}
+
+	model, _ := newModel(resource)
+	if err := json.Unmarshal(data, model); err != nil {
+		return "", false, err
+	}
+
+	utils.Sanitize(model)
+
+	id := database.RecordID(model)
+	if id == "" {
+		return "", false, errors.New("the record has no primary key")
+	}
+
+	existing, _ := newModel(resource)
+
+	err = c.BC.GetRecordsByID(existing, id)
+	if err != nil && !errors.Is(err, database.ErrRecordNotFound) {
+		return "", false, err
+	}
+
+	created := err != nil
+
+	switch {
+	case created && action == models.HookActionUpdate:
+		return "", false, errors.New("the record does not exist")
+	case !created && action == models.HookActionCreate:
+		return "", false, errors.New("the record already exists")
+	}
+
+	if created {
+		err = utils.PopulateFields(model, owner, now)
+	} else {
+		utils.KeepServerFields(model, existing)
+	}
+
+	if err == nil {
+		err = c.validateRecord(resource, model)
+	}
+
+	if err != nil {
+		return "", false, err
+	}
+
+	event := models.EventUpdated
+
+	if created {
+		if err := c.BC.CheckQuota(resource, model); err != nil {
+			return "", false, err
+		}
+
+		if _, err := c.BC.CreateOrUpdateRecord(model, false); err != nil {
+			return "", false, err
+		}
+
+		event = models.EventCreated
+		id = database.RecordID(model)
+	} else if err := c.BC.UpdateRecords(model, id); err != nil {
+		return "", false, err
+	}
+
+	c.Events.Publish(models.ResourceEvent{
+		Type:      event,
+		Resource:  resource,
+		RecordID:  id,
+		Actor:     owner,
+		ActorType: models.UserAccountType,
+		Data:      model,
+	})
+
+	return id, created, nil
+}
+
+// fetchImport requests the URL of an importer with its headers, and returns
+// the array of records found at its records path.
+func fetchImport(importer models.Importer) ([]interface{}, error) {
+	headers, err := importer.HeaderValues()
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, importer.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := importClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errImportFetch, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("%w: the API returned status %d", errImportFetch, resp.StatusCode)
+	}
+
+	// Numbers are kept as written, without the rounding of float64
+	decoder := json.NewDecoder(io.LimitReader(resp.Body, maxImportBytes))
+	decoder.UseNumber()
+
+	var payload interface{}
+	if err := decoder.Decode(&payload); err != nil {
+		return nil, fmt.Errorf("%w: invalid JSON: %w", errImportFetch, err)
+	}
+
+	value, found := lookupJSONPath(payload, importer.RecordsPath)
+	records, ok := value.([]interface{})
+
+	if !found || !ok {
+		return nil, fmt.Errorf("%w: records_path %q is not an array in the response", errImportFetch, importer.RecordsPath)
+	}
+
+	if len(records) > maxImportRecords {
+		return nil, errImportTooLarge
+	}
+
+	return records, nil
+}
+
+// mapImportRecord builds the record of the resource from an external record
+// with a mapping, skipping the fields whose path is missing. Without a
+// mapping, the external record is returned as is.
+func mapImportRecord(item interface{}, mapping map[string]string) interface{} {
+	if len(mapping) == 0 {
+		return item
+	}
+
+	if _, ok := item.(map[string]interface{}); !ok {
+		return item
+	}
+
+	record := make(map[string]interface{}, len(mapping))
+
+	for field, path := range mapping {
+		if value, ok := lookupJSONPath(item, path); ok {
+			record[field] = value
+		}
+	}
+
+	return record
+}
+
+// lookupJSONPath returns the value at a dot path (e.g. "data.items.0.id") of a
+// decoded JSON document: object keys, and array indexes. An empty path returns
+// the document.
+func lookupJSONPath(value interface{}, path string) (interface{}, bool) {
+	if path == "" {
+		return value, true
+	}
+
+	for _, key := range strings.Split(path, ".") {
+		switch current := value.(type) {
+		case map[string]interface{}:
+			next, ok := current[key]
+			if !ok {
+				return nil, false
+			}
+
+			value = next
+		case []interface{}:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(current) {
+				return nil, false
+			}
+
+			value = current[index]
+		default:
+			return nil, false
+		}
+	}
+
+	return value, true
+}
+
+// decodeImporter decodes and validates an importer, writing a 400 response on
+// failure. The owner of the importer is the authenticated admin.
+func decodeImporter(w http.ResponseWriter, r *http.Request, newModel ModelFactory) (models.Importer, bool) {
+	var importer models.Importer
+
+	err := json.NewDecoder(r.Body).Decode(&importer)
+	if err == nil {
+		importer.Owner = middlewares.UsernameFromContext(r.Context())
+		err = importer.Validate()
+	}
+
+	if err == nil {
+		if _, ok := newModel(importer.Resource); !ok {
+			err = errors.New("Invalid resource: " + importer.Resource)
+		}
+	}
+
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return importer, false
+	}
+
+	return importer, true
+}
+
+// importerIDParam parses the {id} URL parameter, writing a 400 response on failure.
+func importerIDParam(w http.ResponseWriter, r *http.Request) (uint, bool) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "Invalid importer ID"})
+
+		return 0, false
+	}
+
+	return uint(id), true
+}
+
+// writeImporterError writes a 404 for unknown importers, a 409 for importers
+// already running, a 502 when the external API can't be read and a 500 for
+// any other error.
+func writeImporterError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+
+	switch {
+	case errors.Is(err, database.ErrImporterNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, errImportRunning):
+		status = http.StatusConflict
+	case errors.Is(err, errImportFetch), errors.Is(err, errImportTooLarge):
+		status = http.StatusBadGateway
+	}
+
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+}
@@ -0,0 +1,398 @@
+package controllers
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/r4ulcl/api_template/api/middlewares"
+	"github.com/r4ulcl/api_template/database"
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+// maxHookBytes is the largest payload an inbound hook reads.
+const maxHookBytes = 1 << 20
+
+var (
+	// errHookSignature is returned for the payloads without a valid signature.
+	errHookSignature = errors.New("invalid signature")
+
+	// errHookPayload is returned for the signed payloads that are not JSON or have no records.
+	errHookPayload = errors.New("invalid payload")
+)
+
+// ListInboundHooks returns every inbound hook, without their secrets.
+//
+// Returns:
+// - HTTP 500 if the retrieval fails.
+// - JSON array of inbound hooks if successful.
+func (c *Controller) ListInboundHooks(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	hooks, err := c.BC.ListInboundHooks()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	for i := range hooks {
+		hideHookSecret(&hooks[i])
+	}
+
+	_ = json.NewEncoder(w).Encode(hooks)
+}
+
+// CreateInboundHook stores a new inbound hook, owned by the authenticated admin.
+//
+// Parameters:
+// - w: The HTTP response writer.
+// - r: The HTTP request with a models.InboundHook, with its secret.
+// - newModel: Returns a pointer to a model of a resource, to check the resource of the hook.
+//
+// Returns:
+// - HTTP 400 if the hook is invalid or has no secret.
+// - HTTP 500 if the hook cannot be stored.
+// - HTTP 201 with the stored hook, without its secret, if successful.
+func (c *Controller) CreateInboundHook(w http.ResponseWriter, r *http.Request, newModel ModelFactory) {
+	w.Header().Set("Content-Type", "application/json")
+
+	hook, ok := decodeInboundHook(w, r, newModel, true)
+	if !ok {
+		return
+	}
+
+	if err := c.BC.CreateInboundHook(&hook); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	hideHookSecret(&hook)
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(hook)
+}
+
+// UpdateInboundHook replaces an existing inbound hook, now owned by the
+// authenticated admin. An empty secret keeps the stored one.
+//
+// Parameters:
+// - w: The HTTP response writer.
+// - r: The HTTP request with the {id} URL parameter and a models.InboundHook.
+// - newModel: Returns a pointer to a model of a resource, to check the resource of the hook.
+//
+// Returns:
+// - HTTP 400 if the hook or its ID is invalid.
+// - HTTP 404 if the hook does not exist.
+// - HTTP 500 if the hook cannot be stored.
+// - JSON object of the stored hook, without its secret, if successful.
+func (c *Controller) UpdateInboundHook(w http.ResponseWriter, r *http.Request, newModel ModelFactory) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, ok := hookIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	hook, ok := decodeInboundHook(w, r, newModel, false)
+	if !ok {
+		return
+	}
+
+	if err := c.BC.UpdateInboundHook(id, &hook); err != nil {
+		writeInboundHookError(w, err)
+
+		return
+	}
+
+	hideHookSecret(&hook)
+	_ = json.NewEncoder(w).Encode(hook)
+}
+
+// DeleteInboundHook removes an inbound hook. Its URL answers 404 from then
+// on; the records it stored are kept.
+//
+// Returns:
+// - HTTP 400 if the ID is invalid.
+// - HTTP 404 if the hook does not exist.
+// - HTTP 500 if deletion fails.
+// - JSON confirmation message if successful.
+func (c *Controller) DeleteInboundHook(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, ok := hookIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	if err := c.BC.DeleteInboundHook(id); err != nil {
+		writeInboundHookError(w, err)
+
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(map[string]string{"message": "Deleted successfully"})
+}
+
+// ReceiveHook stores the records of a payload POSTed to an inbound hook by a
+// third-party service.
+//
+// The payload must be signed with the secret of the hook: its HMAC-SHA256, in
+// the signature header of the hook, after its prefix. The record, or the array
+// of records, at the records path of the hook is mapped to the fields of the
+// resource and created, updated or upserted as the action of the hook says.
+// Each record is processed like an imported one (see importRecord); the failed
+// records don't stop the others. The time of the payload and its error, if
+// any, are stored in the hook.
+//
+// Parameters:
+// - w: The HTTP response writer.
+// - r: The HTTP request with the {name} URL parameter and the signed JSON payload.
+// - newModel: Returns a pointer to a model of a resource, to decode the records.
+//
+// Returns:
+// - HTTP 404 if no enabled hook has the name.
+// - HTTP 413 if the payload is larger than 1 MiB.
+// - HTTP 401 if the signature is missing or invalid.
+// - HTTP 400 if the payload is not JSON or has no records at the records path.
+// - JSON object of the models.HookResult otherwise, with the records that failed.
+func (c *Controller) ReceiveHook(w http.ResponseWriter, r *http.Request, newModel ModelFactory) {
+	w.Header().Set("Content-Type", "application/json")
+
+	hook, err := c.BC.GetEnabledInboundHook(mux.Vars(r)["name"])
+	if err != nil {
+		writeInboundHookError(w, err)
+
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxHookBytes+1))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "Failed to read the payload"})
+
+		return
+	}
+
+	if len(body) > maxHookBytes {
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "The payload is larger than 1 MiB"})
+
+		return
+	}
+
+	// Unsigned payloads are not recorded in the hook, anyone can send them
+	if !validHookSignature(hook, body, r.Header.Get(hook.Header())) {
+		writeInboundHookError(w, errHookSignature)
+
+		return
+	}
+
+	now := time.Now()
+	result, err := c.receiveHook(hook, body, newModel, now)
+
+	stored := err
+	if stored == nil && len(result.Failed) > 0 {
+		stored = fmt.Errorf("%d of %d records failed, the first at index %d: %s",
+			len(result.Failed), result.Received, result.Failed[0].Index, result.Failed[0].Error)
+	}
+
+	if storeErr := c.BC.SetInboundHookResult(hook.ID, now, stored); storeErr != nil {
+		log.Println("Error storing the result of an inbound hook:", storeErr)
+	}
+
+	if err != nil {
+		writeInboundHookError(w, err)
+
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(result)
+}
+
+// receiveHook maps the records of a signed payload to the resource of a hook
+// and stores them one by one.
+func (c *Controller) receiveHook(hook models.InboundHook, body []byte, newModel ModelFactory,
+	now time.Time,
+) (models.HookResult, error) {
+	result := models.HookResult{Hook: hook.Name, Resource: hook.Resource, ReceivedAt: now,
+		Failed: []models.ImportFailure{}}
+
+	if _, ok := newModel(hook.Resource); !ok {
+		return result, fmt.Errorf("invalid resource %q in inbound hook %s", hook.Resource, hook.Name)
+	}
+
+	mapping, err := hook.FieldMapping()
+	if err != nil {
+		return result, err
+	}
+
+	records, err := hookRecords(hook, body)
+	if err != nil {
+		return result, err
+	}
+
+	result.Received = len(records)
+
+	for index, item := range records {
+		_, created, err := c.importRecord(hook.Resource, hook.Owner, hook.Action, mapImportRecord(item, mapping),
+			newModel, now)
+
+		switch {
+		case err != nil:
+			result.Failed = append(result.Failed, models.ImportFailure{Index: index, Error: err.Error()})
+		case created:
+			result.Created++
+		default:
+			result.Updated++
+		}
+	}
+
+	return result, nil
+}
+
+// hookRecords returns the records of a payload: the array at the records path
+// of the hook, or the object there as the only record.
+func hookRecords(hook models.InboundHook, body []byte) ([]interface{}, error) {
+	// Numbers are kept as written, without the rounding of float64
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var payload interface{}
+	if err := decoder.Decode(&payload); err != nil {
+		return nil, fmt.Errorf("%w: %w", errHookPayload, err)
+	}
+
+	value, found := lookupJSONPath(payload, hook.RecordsPath)
+	if !found {
+		return nil, fmt.Errorf("%w: nothing at records_path %q", errHookPayload, hook.RecordsPath)
+	}
+
+	records, ok := value.([]interface{})
+	if !ok {
+		records = []interface{}{value}
+	}
+
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%w: no records", errHookPayload)
+	}
+
+	if len(records) > maxImportRecords {
+		return nil, errImportTooLarge
+	}
+
+	return records, nil
+}
+
+// validHookSignature reports whether a signature header holds the HMAC-SHA256
+// of a payload with the secret of a hook, compared in constant time.
+func validHookSignature(hook models.InboundHook, body []byte, header string) bool {
+	signature, ok := strings.CutPrefix(strings.TrimSpace(header), hook.SignaturePrefix)
+	if !ok || signature == "" || hook.Secret == "" {
+		return false
+	}
+
+	var (
+		decoded []byte
+		err     error
+	)
+
+	if hook.SignatureEncoding == models.HookSignatureBase64 {
+		decoded, err = base64.StdEncoding.DecodeString(signature)
+	} else {
+		decoded, err = hex.DecodeString(signature)
+	}
+
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(hook.Secret))
+	mac.Write(body)
+
+	return hmac.Equal(decoded, mac.Sum(nil))
+}
+
+// decodeInboundHook decodes and validates an inbound hook, writing a 400
+// response on failure. The owner of the hook is the authenticated admin.
+func decodeInboundHook(w http.ResponseWriter, r *http.Request, newModel ModelFactory,
+	requireSecret bool,
+) (models.InboundHook, bool) {
+	var hook models.InboundHook
+
+	err := json.NewDecoder(r.Body).Decode(&hook)
+	if err == nil {
+		hook.Owner = middlewares.UsernameFromContext(r.Context())
+		err = hook.Validate()
+	}
+
+	if err == nil && requireSecret && hook.Secret == "" {
+		err = errors.New("secret is required")
+	}
+
+	if err == nil {
+		if _, ok := newModel(hook.Resource); !ok {
+			err = errors.New("Invalid resource: " + hook.Resource)
+		}
+	}
+
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return hook, false
+	}
+
+	return hook, true
+}
+
+// hookIDParam parses the {id} URL parameter, writing a 400 response on failure.
+func hookIDParam(w http.ResponseWriter, r *http.Request) (uint, bool) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "Invalid inbound hook ID"})
+
+		return 0, false
+	}
+
+	return uint(id), true
+}
+
+// hideHookSecret removes the secret of a hook from a response.
+func hideHookSecret(hook *models.InboundHook) {
+	hook.HasSecret = hook.Secret != ""
+	hook.Secret = ""
+}
+
+// writeInboundHookError writes a 404 for unknown hooks, a 401 for invalid
+// signatures, a 400 for invalid payloads and a 500 for any other error.
+func writeInboundHookError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+
+	switch {
+	case errors.Is(err, database.ErrInboundHookNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, errHookSignature):
+		status = http.StatusUnauthorized
+	case errors.Is(err, errHookPayload), errors.Is(err, errImportTooLarge):
+		status = http.StatusBadRequest
+	}
+
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+}
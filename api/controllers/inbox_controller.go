@@ -0,0 +1,160 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/r4ulcl/api_template/api/middlewares"
+	"github.com/r4ulcl/api_template/database"
+	"github.com/r4ulcl/api_template/utils"
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+// GetInbox returns the most recent notifications of the authenticated user.
+//
+// Parameters:
+// - w: The HTTP response writer.
+// - r: The HTTP request with the optional "unread" query parameter.
+//
+// Returns:
+// - HTTP 400 if "unread" is not a boolean.
+// - HTTP 500 if the retrieval fails.
+// - JSON array of notifications, most recent first, if successful.
+func (c *Controller) GetInbox(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	unreadOnly := false
+
+	if raw := r.URL.Query().Get("unread"); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "invalid unread: " + raw})
+
+			return
+		}
+
+		unreadOnly = parsed
+	}
+
+	notifications, err := c.BC.ListInboxNotifications(middlewares.UsernameFromContext(r.Context()), unreadOnly)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(notifications)
+}
+
+// GetUnreadCount returns the number of unread notifications of the authenticated user.
+//
+// Returns:
+// - HTTP 500 if the count fails.
+// - JSON object of the models.UnreadCount if successful.
+func (c *Controller) GetUnreadCount(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	count, err := c.BC.CountUnreadInboxNotifications(middlewares.UsernameFromContext(r.Context()))
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(models.UnreadCount{Unread: count})
+}
+
+// MarkRead marks the notification in the URL as read.
+//
+// Returns:
+// - HTTP 400 if the ID is not a number.
+// - HTTP 404 if the user has no notification with that ID.
+// - HTTP 500 if the notification cannot be updated.
+// - JSON object of the remaining models.UnreadCount if successful.
+func (c *Controller) MarkRead(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "Invalid ID"})
+
+		return
+	}
+
+	if err := c.BC.MarkInboxNotificationRead(middlewares.UsernameFromContext(r.Context()), uint(id)); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, database.ErrInboxNotificationNotFound) {
+			status = http.StatusNotFound
+		}
+
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	c.GetUnreadCount(w, r)
+}
+
+// MarkAllRead marks every notification of the authenticated user as read.
+//
+// Returns:
+// - HTTP 500 if the notifications cannot be updated.
+// - JSON object of the remaining models.UnreadCount if successful.
+func (c *Controller) MarkAllRead(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if _, err := c.BC.MarkAllInboxNotificationsRead(middlewares.UsernameFromContext(r.Context())); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	c.GetUnreadCount(w, r)
+}
+
+// notifyAssignees adds a notification to the inbox of the users a record was
+// just assigned to (see utils.Assignees), other than the user making the change.
+//
+// Parameters:
+// - r: The HTTP request that changed the record.
+// - resource: The name of the resource (e.g. "example2").
+// - recordID: The tokenized ID of the record.
+// - model: The stored record.
+// - previous: The record before the change, or nil if it is new.
+func (c *Controller) notifyAssignees(r *http.Request, resource, recordID string, model, previous interface{}) {
+	actor := middlewares.UsernameFromContext(r.Context())
+	before := utils.Assignees(previous)
+
+	var notifications []models.InboxNotification
+
+	for _, username := range utils.Assignees(model) {
+		if username == actor || slices.Contains(before, username) {
+			continue
+		}
+
+		notifications = append(notifications, models.InboxNotification{
+			Username: username,
+			Kind:     models.InboxAssigned,
+			Title:    fmt.Sprintf("%s %s was assigned to you", resource, recordID),
+			Text:     fmt.Sprintf("%s assigned %s %s to you", actor, resource, recordID),
+			Resource: resource,
+			RecordID: recordID,
+		})
+	}
+
+	if err := c.BC.CreateInboxNotifications(notifications...); err != nil {
+		log.Println("Error notifying the assignees of a record:", err)
+	}
+}
@@ -0,0 +1,185 @@
+package controllers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"slices"
+
+	"github.com/r4ulcl/api_template/api/middlewares"
+	"github.com/r4ulcl/api_template/utils/models"
+	"github.com/r4ulcl/api_template/utils/protobuf"
+)
+
+// maxIngestLineBytes is the largest line of an NDJSON body, so one record,
+// and the largest record of a protobuf list.
+const maxIngestLineBytes = 1 << 20
+
+// ndjsonMediaTypes lists the media types of the bodies with one JSON record per line.
+var ndjsonMediaTypes = []string{
+	"application/x-ndjson", "application/ndjson", "application/jsonl", "application/x-jsonlines",
+}
+
+// isNDJSON reports whether a Content-Type is newline-delimited JSON (NDJSON or
+// JSON Lines), one record per line.
+func isNDJSON(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	return slices.Contains(ndjsonMediaTypes, mediaType)
+}
+
+// IsIngestBody reports whether a Content-Type is a stream of records handled
+// by Ingest: NDJSON, or a protobuf list message.
+func IsIngestBody(contentType string) bool {
+	_, list := protobuf.IsProtobuf(contentType)
+
+	return list || isNDJSON(contentType)
+}
+
+// Ingest creates the records of a streamed body: NDJSON, one JSON record per
+// line, for log-style ingestion pipelines, or a protobuf list message (see
+// protobuf.Codec) for high-throughput integrations. The records are created in
+// chunks of DB_BATCH_SIZE while the body is read, so it can be larger than the
+// memory of the server. Blank NDJSON lines are skipped.
+//
+// Each record is processed like on create (see BulkImport): sanitized, with
+// the fields tagged with `populate` set, validated, and checked against the
+// quota of the resource. The invalid records are counted and skipped without
+// stopping the ingestion.
+//
+// Parameters:
+// - w: The HTTP response writer.
+// - r: The HTTP request with an application/x-ndjson or protobuf list body (see IsIngestBody).
+// - resource: The name of the resource (e.g. "example1").
+// - model: A pointer to a model of the resource.
+//
+// Returns:
+// - HTTP 400 with the models.IngestResult so far if a record is longer than maxIngestLineBytes or the body is invalid.
+// - HTTP 400 if the body has no records.
+// - HTTP 415 if the resource has no protobuf messages.
+// - HTTP 500 with the models.IngestResult so far if the database fails.
+// - HTTP 200 with the models.IngestResult otherwise, even if some records failed.
+func (c *Controller) Ingest(w http.ResponseWriter, r *http.Request, resource string, model interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+
+	next, decode, format := ndjsonRecords(r.Body)
+
+	if _, list := protobuf.IsProtobuf(r.Header.Get("Content-Type")); list {
+		codec := c.protoCodec(model)
+		if codec == nil {
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "The resource has no protobuf messages"})
+
+			return
+		}
+
+		reader := protobuf.NewListReader(r.Body, maxIngestLineBytes)
+		next, decode, format = reader.Next, codec.Unmarshal, "protobuf"
+	}
+
+	// The failures of the records are counted in a job that is not stored
+	job := models.BulkJob{Resource: resource, Owner: middlewares.UsernameFromContext(r.Context()), Format: format}
+	event := models.ResourceEvent{
+		Resource:  resource,
+		Actor:     job.Owner,
+		ActorType: middlewares.AccountTypeFromContext(r.Context()),
+		ClientIP:  middlewares.ClientIPFromContext(r.Context()),
+	}
+
+	modelType := reflect.TypeOf(model).Elem()
+	size := c.BC.BulkBatchSize()
+	chunk := make([][]byte, 0, size)
+	status := http.StatusOK
+
+	for {
+		record, readErr := next()
+		if readErr == nil {
+			chunk = append(chunk, record)
+			job.Total++
+
+			if len(chunk) < size {
+				continue
+			}
+		}
+
+		if len(chunk) > 0 {
+			decodeAt := func(i int, model interface{}) error { return decode(chunk[i], model) }
+
+			if err := c.createBulkChunk(&job, len(chunk), decodeAt, job.Total-len(chunk), modelType, event); err != nil {
+				job.Error = err.Error()
+				status = http.StatusInternalServerError
+
+				break
+			}
+
+			chunk = chunk[:0]
+		}
+
+		if readErr != nil {
+			if !errors.Is(readErr, io.EOF) {
+				job.Error = readErr.Error()
+				status = http.StatusBadRequest
+			}
+
+			break
+		}
+	}
+
+	if job.Total == 0 && status == http.StatusOK {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "the payload has no records"})
+
+		return
+	}
+
+	failures := job.Failures
+	if failures == nil {
+		failures = []models.ImportFailure{}
+	}
+
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(models.IngestResult{
+		Received: job.Total,
+		Created:  job.Created,
+		Failed:   job.Failed,
+		Failures: failures,
+		Error:    job.Error,
+	})
+}
+
+// ndjsonRecords returns the reader of the records of an NDJSON body, returning
+// io.EOF after the last one, their decoder, and the name of the format.
+func ndjsonRecords(body io.Reader) (func() ([]byte, error), func([]byte, interface{}) error, string) {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64<<10), maxIngestLineBytes)
+
+	next := func() ([]byte, error) {
+		for scanner.Scan() {
+			// The scanner reuses its buffer for the next lines
+			if line := bytes.TrimSpace(scanner.Bytes()); len(line) > 0 {
+				return bytes.Clone(line), nil
+			}
+		}
+
+		err := scanner.Err()
+
+		switch {
+		case err == nil:
+			return nil, io.EOF
+		case errors.Is(err, bufio.ErrTooLong):
+			return nil, errors.New("a line is longer than 1 MiB")
+		default:
+			return nil, err
+		}
+	}
+
+	return next, json.Unmarshal, "ndjson"
+}
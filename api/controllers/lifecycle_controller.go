@@ -0,0 +1,60 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/r4ulcl/api_template/database"
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+// Transition applies the lifecycle action in the URL (publish, unpublish,
+// archive or restore) to the record identified by its tokenized ID.
+//
+// Parameters:
+// - w: The HTTP response writer.
+// - r: The HTTP request with the {id} and {action} URL parameters.
+// - resource: The name of the resource (e.g. "example1").
+// - model: A pointer to the struct representing the database entity.
+//
+// Returns:
+// - HTTP 400 if the action is unknown.
+// - HTTP 404 if the record does not exist.
+// - HTTP 409 if the action does not apply to the current status.
+// - HTTP 423 if another user holds the lock of the record.
+// - HTTP 500 if the status cannot be updated.
+// - JSON object of the updated record if successful.
+func (c *Controller) Transition(w http.ResponseWriter, r *http.Request, resource string, model interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	tokenizedID := vars["id"]
+
+	if !c.checkLock(w, r, resource, tokenizedID) {
+		return
+	}
+
+	if err := c.BC.TransitionRecord(model, tokenizedID, vars["action"]); err != nil {
+		status := http.StatusInternalServerError
+
+		switch {
+		case errors.Is(err, database.ErrUnknownAction):
+			status = http.StatusBadRequest
+		case errors.Is(err, database.ErrRecordNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, database.ErrInvalidTransition):
+			status = http.StatusConflict
+		}
+
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	c.publishEvent(r, models.EventUpdated, resource, tokenizedID, model)
+
+	_ = json.NewEncoder(w).Encode(model)
+}
@@ -0,0 +1,105 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/r4ulcl/api_template/api/middlewares"
+	"github.com/r4ulcl/api_template/database"
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+// GetDuplicates lists the groups of records with identical values on the fields
+// given in the comma-separated "fields" query parameter. Users restricted to
+// published records only compare the published ones.
+//
+// Parameters:
+// - w: The HTTP response writer.
+// - r: The HTTP request with the "fields" query parameter.
+// - model: A pointer to the struct representing the database entity.
+//
+// Returns:
+// - HTTP 400 if "fields" is missing or names an unknown field.
+// - HTTP 500 if the retrieval fails.
+// - JSON array of duplicate groups if successful.
+func (c *Controller) GetDuplicates(w http.ResponseWriter, r *http.Request, model interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+
+	// Users restricted to published records don't see the IDs of the others
+	var conditions []database.Filter
+
+	if middlewares.PublishedOnlyFromContext(r.Context()) {
+		if filter, ok := c.BC.PublishedFilter(model); ok {
+			conditions = append(conditions, filter)
+		}
+	}
+
+	groups, err := c.BC.FindDuplicates(model, splitList(r.URL.Query().Get("fields")), conditions)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, database.ErrInvalidField) {
+			status = http.StatusBadRequest
+		}
+
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(groups)
+}
+
+// Merge merges a set of records into a survivor, re-pointing the rows of other
+// resources that reference them, and deletes the merged records.
+//
+// Parameters:
+// - w: The HTTP response writer.
+// - r: The HTTP request with a models.MergeRequest body.
+// - resource: The name of the resource (e.g. "example1").
+// - model: A pointer to the struct representing the database entity.
+//
+// Returns:
+// - HTTP 400 if the body is invalid or the resource can't be merged.
+// - HTTP 404 if the survivor or a merged record does not exist.
+// - HTTP 500 if the merge fails.
+// - JSON object of the models.MergeResult if successful.
+func (c *Controller) Merge(w http.ResponseWriter, r *http.Request, resource string, model interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var input models.MergeRequest
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	repointed, err := c.transactions(r.Context(), resource).MergeRecords(model, input.Survivor, input.IDs)
+	if err != nil {
+		status := http.StatusInternalServerError
+
+		switch {
+		case errors.Is(err, database.ErrInvalidMerge):
+			status = http.StatusBadRequest
+		case errors.Is(err, database.ErrRecordNotFound):
+			status = http.StatusNotFound
+		}
+
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	c.publishEvent(r, models.EventUpdated, resource, input.Survivor, model)
+
+	for _, id := range input.IDs {
+		c.publishEvent(r, models.EventDeleted, resource, id, nil)
+	}
+
+	c.recordDeletions(resource, model, input.IDs)
+
+	_ = json.NewEncoder(w).Encode(models.MergeResult{Survivor: model, Merged: input.IDs, References: repointed})
+}
@@ -0,0 +1,147 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/r4ulcl/api_template/utils/httpclient"
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+// GetSlowRoutes returns the routes whose rolling p95 latency exceeds the configured threshold.
+//
+// Returns:
+// - JSON array of slow routes (empty if latency tracking is disabled).
+func (c *Controller) GetSlowRoutes(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	slow := []models.SlowRoute{}
+	if c.Latency != nil {
+		slow = c.Latency.SlowRoutes()
+	}
+
+	_ = json.NewEncoder(w).Encode(slow)
+}
+
+// GetOutboundHosts returns the metrics of the outbound HTTP calls per host:
+// attempts, failures, retries, latency and the state of the circuit breaker.
+//
+// Returns:
+// - JSON array of the hosts called since startup.
+func (c *Controller) GetOutboundHosts(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	_ = json.NewEncoder(w).Encode(httpclient.Metrics())
+}
+
+// GetAlerts returns the conditions currently alerting: the slow routes, the
+// tables growing faster than TABLE_GROWTH_THRESHOLD, the outbound hosts
+// whose circuit is open and the monitored dependencies that are down.
+//
+// Returns:
+// - HTTP 500 if the dependencies cannot be read.
+// - JSON object of the alerts (empty lists for the disabled monitors).
+func (c *Controller) GetAlerts(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	alerts := models.Alerts{SlowRoutes: []models.SlowRoute{}, TableGrowth: []models.TableGrowth{},
+		OpenCircuits: []models.OutboundHost{}, DependenciesDown: []models.DependencyHealth{}}
+
+	if c.Latency != nil {
+		alerts.SlowRoutes = c.Latency.SlowRoutes()
+	}
+
+	if c.Growth != nil {
+		alerts.TableGrowth = c.Growth.Growing()
+	}
+
+	for _, host := range httpclient.Metrics() {
+		if host.CircuitOpen {
+			alerts.OpenCircuits = append(alerts.OpenCircuits, host)
+		}
+	}
+
+	dependencies, err := c.BC.DependencyHealth()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	for _, dependency := range dependencies {
+		if dependency.State == models.DependencyDown {
+			alerts.DependenciesDown = append(alerts.DependenciesDown, dependency)
+		}
+	}
+
+	_ = json.NewEncoder(w).Encode(alerts)
+}
+
+// ListRoutes returns every route registered in the router.
+//
+// Parameters:
+// - w: The HTTP response writer.
+// - r: The HTTP request.
+// - routes: The routes collected from the router.
+//
+// Returns:
+// - JSON array of routes with their methods, required role and model type.
+func (c *Controller) ListRoutes(w http.ResponseWriter, _ *http.Request, routes []models.RouteInfo) {
+	w.Header().Set("Content-Type", "application/json")
+
+	_ = json.NewEncoder(w).Encode(routes)
+}
+
+// GetOpenAPI returns the OpenAPI document of the deployment, with a path per
+// resource (see openapi.Build), to generate typed clients from.
+//
+// Parameters:
+// - w: The HTTP response writer.
+// - r: The HTTP request.
+// - spec: Builds the encoded document.
+//
+// Returns:
+// - HTTP 500 if the document cannot be built.
+// - The JSON document otherwise.
+func (c *Controller) GetOpenAPI(w http.ResponseWriter, _ *http.Request, spec func() ([]byte, error)) {
+	w.Header().Set("Content-Type", "application/json")
+
+	encoded, err := spec()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "Failed to build the OpenAPI document"})
+
+		return
+	}
+
+	_, _ = w.Write(encoded)
+}
+
+// GetLeader returns which instance runs the scheduled jobs, as seen by the
+// instance answering.
+//
+// Returns:
+// - HTTP 404 if the leader election is not set up.
+// - HTTP 500 if the lease cannot be read.
+// - JSON object with the lease and the leadership of the instance otherwise.
+func (c *Controller) GetLeader(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if c.Leader == nil {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "Leader election is disabled"})
+
+		return
+	}
+
+	status, err := c.Leader.Status()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(status)
+}
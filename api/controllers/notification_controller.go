@@ -0,0 +1,220 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/r4ulcl/api_template/database"
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+var (
+	errInvalidChannel = errors.New("channel must be slack, teams or a configured channel like telegram")
+	errInvalidWebhook = errors.New("webhook_url must be an http(s) URL")
+	errInvalidEvent   = errors.New("event must be created, updated, deleted, error_burst, table_growth or empty")
+	errInvalidFilter  = errors.New("filter must be a valid query string")
+)
+
+// ListNotificationRules returns every notification rule.
+//
+// Returns:
+// - HTTP 500 if the retrieval fails.
+// - JSON array of rules if successful.
+func (c *Controller) ListNotificationRules(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	rules, err := c.BC.ListNotificationRules()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(rules)
+}
+
+// CreateNotificationRule stores a new notification rule.
+//
+// Returns:
+// - HTTP 400 if the rule is invalid.
+// - HTTP 500 if the rule cannot be stored.
+// - HTTP 201 with the stored rule if successful.
+func (c *Controller) CreateNotificationRule(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	rule, ok := c.decodeNotificationRule(w, r)
+	if !ok {
+		return
+	}
+
+	if err := c.BC.CreateNotificationRule(&rule); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	c.ReloadNotificationRules()
+	c.auditConfig(r, models.ConfigNotificationRule, strconv.FormatUint(uint64(rule.ID), 10), nil, rule)
+
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(rule)
+}
+
+// UpdateNotificationRule replaces an existing notification rule.
+//
+// Returns:
+// - HTTP 400 if the rule or its ID is invalid.
+// - HTTP 404 if the rule does not exist.
+// - HTTP 500 if the rule cannot be stored.
+// - JSON object of the stored rule if successful.
+func (c *Controller) UpdateNotificationRule(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, ok := ruleIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	rule, ok := c.decodeNotificationRule(w, r)
+	if !ok {
+		return
+	}
+
+	before := storedConfig(c.BC.GetNotificationRule(id))
+
+	if err := c.BC.UpdateNotificationRule(id, &rule); err != nil {
+		writeRuleError(w, err)
+
+		return
+	}
+
+	c.ReloadNotificationRules()
+	c.auditConfig(r, models.ConfigNotificationRule, strconv.FormatUint(uint64(id), 10), before, rule)
+
+	_ = json.NewEncoder(w).Encode(rule)
+}
+
+// DeleteNotificationRule removes a notification rule.
+//
+// Returns:
+// - HTTP 400 if the ID is invalid.
+// - HTTP 404 if the rule does not exist.
+// - HTTP 500 if deletion fails.
+// - JSON confirmation message if successful.
+func (c *Controller) DeleteNotificationRule(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, ok := ruleIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	before := storedConfig(c.BC.GetNotificationRule(id))
+
+	if err := c.BC.DeleteNotificationRule(id); err != nil {
+		writeRuleError(w, err)
+
+		return
+	}
+
+	c.ReloadNotificationRules()
+	c.auditConfig(r, models.ConfigNotificationRule, strconv.FormatUint(uint64(id), 10), before, nil)
+
+	_ = json.NewEncoder(w).Encode(map[string]string{"message": "Deleted successfully"})
+}
+
+// ReloadNotificationRules loads the rules from the database into the dispatcher.
+func (c *Controller) ReloadNotificationRules() {
+	if c.Notifications == nil {
+		return
+	}
+
+	rules, err := c.BC.ListNotificationRules()
+	if err != nil {
+		log.Println("Error loading notification rules:", err)
+
+		return
+	}
+
+	c.Notifications.SetRules(rules)
+}
+
+// decodeNotificationRule decodes and validates a rule, writing a 400 response on failure.
+func (c *Controller) decodeNotificationRule(w http.ResponseWriter, r *http.Request) (models.NotificationRule, bool) {
+	var rule models.NotificationRule
+
+	err := json.NewDecoder(r.Body).Decode(&rule)
+	if err == nil {
+		err = c.validateNotificationRule(rule)
+	}
+
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return rule, false
+	}
+
+	return rule, true
+}
+
+// validateNotificationRule checks the channel, webhook URL, event and filter of a rule.
+//
+// Only Slack and Teams rules need a webhook URL; other channels are configured
+// by the application (e.g. the Telegram bot).
+func (c *Controller) validateNotificationRule(rule models.NotificationRule) error {
+	if c.Notifications == nil || !c.Notifications.HasChannel(rule.Channel) {
+		return errInvalidChannel
+	}
+
+	if rule.Channel == "slack" || rule.Channel == "teams" {
+		webhook, err := url.Parse(rule.WebhookURL)
+		if err != nil || (webhook.Scheme != "http" && webhook.Scheme != "https") || webhook.Host == "" {
+			return errInvalidWebhook
+		}
+	}
+
+	validEvents := []string{"", string(models.EventCreated), string(models.EventUpdated),
+		string(models.EventDeleted), models.EventErrorBurst, models.EventTableGrowth, models.EventDependency}
+	if !slices.Contains(validEvents, rule.Event) {
+		return errInvalidEvent
+	}
+
+	if _, err := url.ParseQuery(rule.Filter); err != nil {
+		return errInvalidFilter
+	}
+
+	return nil
+}
+
+// ruleIDParam parses the {id} URL parameter, writing a 400 response on failure.
+func ruleIDParam(w http.ResponseWriter, r *http.Request) (uint, bool) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "Invalid rule ID"})
+
+		return 0, false
+	}
+
+	return uint(id), true
+}
+
+// writeRuleError writes a 404 for unknown rules and a 500 for any other error.
+func writeRuleError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	if errors.Is(err, database.ErrRuleNotFound) {
+		status = http.StatusNotFound
+	}
+
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+}
@@ -0,0 +1,110 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/r4ulcl/api_template/database"
+	"github.com/r4ulcl/api_template/utils"
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+// defaultServiceTokenTTL is used when the AuthController has no ServiceTokenTTL.
+const defaultServiceTokenTTL = time.Hour
+
+// Token issues access tokens to service accounts with the OAuth 2.0 client credentials grant.
+//
+// The form parameters are grant_type=client_credentials, client_id, client_secret
+// (or HTTP Basic authentication instead) and an optional space-separated scope,
+// which must be a subset of the scopes of the account.
+//
+// Returns:
+// - HTTP 400 with an OAuth error code if the request or the scope is invalid.
+// - HTTP 401 with "invalid_client" if the credentials are wrong or the account is disabled.
+// - JSON token response if successful.
+func (ac *AuthController) Token(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+
+	if err := r.ParseForm(); err != nil {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request")
+
+		return
+	}
+
+	if grantType := r.PostForm.Get("grant_type"); grantType != "client_credentials" {
+		writeOAuthError(w, http.StatusBadRequest, "unsupported_grant_type")
+
+		return
+	}
+
+	clientID, secret, ok := r.BasicAuth()
+	if !ok {
+		clientID, secret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+
+	if clientID == "" || secret == "" {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request")
+
+		return
+	}
+
+	account, err := ac.BC.AuthenticateServiceAccount(clientID, secret)
+	if errors.Is(err, database.ErrInvalidClient) {
+		w.Header().Set("WWW-Authenticate", `Basic realm="oauth"`)
+		writeOAuthError(w, http.StatusUnauthorized, "invalid_client")
+
+		return
+	} else if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	granted := account.ScopeList()
+
+	scopes := strings.Fields(r.PostForm.Get("scope"))
+	if len(scopes) == 0 {
+		scopes = granted
+	}
+
+	for _, scope := range scopes {
+		if !models.ScopeCovers(granted, scope) {
+			writeOAuthError(w, http.StatusBadRequest, "invalid_scope")
+
+			return
+		}
+	}
+
+	ttl := ac.ServiceTokenTTL
+	if ttl <= 0 {
+		ttl = defaultServiceTokenTTL
+	}
+
+	scope := strings.Join(scopes, " ")
+
+	token, err := utils.GenerateServiceJWT(account.ClientID, string(account.Role), scope, ttl, ac.Secret)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "Failed to generate token"})
+
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(models.TokenResponse{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(ttl.Seconds()),
+		Scope:       scope,
+	})
+}
+
+// writeOAuthError answers with an OAuth 2.0 error code.
+func writeOAuthError(w http.ResponseWriter, status int, code string) {
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: code})
+}
@@ -0,0 +1,63 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/r4ulcl/api_template/api/middlewares"
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+// maxPreferencesBytes is the maximum size of a preferences request body.
+const maxPreferencesBytes = 64 << 10
+
+// GetPreferences returns the preferences of the authenticated user.
+//
+// Returns:
+// - HTTP 500 if the retrieval fails.
+// - JSON object mapping each key to its value if successful.
+func (c *Controller) GetPreferences(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	preferences, err := c.BC.GetPreferences(middlewares.UsernameFromContext(r.Context()))
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(preferences)
+}
+
+// SetPreferences stores the preferences sent by the authenticated user.
+//
+// The body is a JSON object; its keys are merged into the stored preferences
+// and keys set to null are removed.
+//
+// Returns:
+// - HTTP 400 if the body is not a JSON object or is too large.
+// - HTTP 500 if the preferences cannot be stored.
+// - JSON object of all the preferences of the user if successful.
+func (c *Controller) SetPreferences(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	username := middlewares.UsernameFromContext(r.Context())
+
+	var input map[string]json.RawMessage
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxPreferencesBytes)).Decode(&input); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	if err := c.BC.SetPreferences(username, input); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	c.GetPreferences(w, r)
+}
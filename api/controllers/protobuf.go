@@ -0,0 +1,118 @@
+package controllers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"reflect"
+
+	"github.com/r4ulcl/api_template/utils/models"
+	"github.com/r4ulcl/api_template/utils/protobuf"
+)
+
+// protoCodec returns the protobuf codec of a model, a pointer to a struct or
+// to a slice of structs, or nil if its resource has none.
+func (c *Controller) protoCodec(model interface{}) *protobuf.Codec {
+	if c.ProtoCodec == nil {
+		return nil
+	}
+
+	modelType := reflect.TypeOf(model).Elem()
+	if modelType.Kind() == reflect.Slice {
+		modelType = modelType.Elem()
+	}
+
+	return c.ProtoCodec(modelType)
+}
+
+// GetProto returns the .proto file of the messages of a resource, to generate
+// the clients exchanging application/x-protobuf with its CRUD endpoints.
+//
+// Parameters:
+// - w: The HTTP response writer.
+// - r: The HTTP request.
+// - resource: The name of the resource (e.g. "example1").
+// - model: A pointer to the struct representing the database entity.
+//
+// Returns:
+// - HTTP 404 if the resource has no protobuf messages.
+// - The .proto file if successful.
+func (c *Controller) GetProto(w http.ResponseWriter, _ *http.Request, resource string, model interface{}) {
+	codec := c.protoCodec(model)
+	if codec == nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "The resource has no protobuf messages"})
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = io.WriteString(w, codec.Proto(resource))
+}
+
+// writeRecords writes a record, or a list of records, as protobuf if the
+// request accepts it and the resource has a codec, or as JSON otherwise.
+//
+// Parameters:
+// - status: The HTTP status of the response.
+// - model: A pointer to a struct or to a slice of structs.
+func (c *Controller) writeRecords(w http.ResponseWriter, r *http.Request, status int, model interface{}) {
+	codec := c.protoCodec(model)
+	if codec == nil {
+		w.WriteHeader(status)
+		_ = EncodeJSON(w, model)
+
+		return
+	}
+
+	w.Header().Add("Vary", "Accept")
+
+	if !protobuf.Accepted(r.Header.Get("Accept")) {
+		w.WriteHeader(status)
+		_ = EncodeJSON(w, model)
+
+		return
+	}
+
+	list := reflect.TypeOf(model).Elem().Kind() == reflect.Slice
+
+	var (
+		body []byte
+		err  error
+	)
+
+	if list {
+		body, err = codec.MarshalList(model)
+	} else {
+		body, err = codec.Marshal(model)
+	}
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	w.Header().Set("Content-Type", codec.ContentType(list))
+	w.WriteHeader(status)
+	_, _ = w.Write(body)
+}
+
+// decodeRecord decodes the body of a request into a model: a protobuf message
+// if its Content-Type is protobuf and the resource has a codec, JSON otherwise.
+func (c *Controller) decodeRecord(r *http.Request, model interface{}) error {
+	if isProtobuf, _ := protobuf.IsProtobuf(r.Header.Get("Content-Type")); isProtobuf {
+		if codec := c.protoCodec(model); codec != nil {
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				return err
+			}
+
+			return codec.Unmarshal(body, model)
+		}
+	}
+
+	return json.NewDecoder(r.Body).Decode(model)
+}
@@ -0,0 +1,36 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/r4ulcl/api_template/api/middlewares"
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+// ProxyService forwards a request under /ext/{service}/ to the named upstream
+// service, with the identity of the caller as signed headers.
+//
+// Returns:
+// - HTTP 404 if the service is not configured.
+// - HTTP 502 if the service can't be reached.
+// - The response of the service otherwise.
+func (c *Controller) ProxyService(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["service"]
+	if !c.Proxy.Has(name) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "Service not found"})
+
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/ext/"+name)
+	if path == "" {
+		path = "/"
+	}
+
+	c.Proxy.Forward(w, r, name, path, middlewares.PrincipalFromContext(r.Context()))
+}
@@ -0,0 +1,153 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/r4ulcl/api_template/api/middlewares"
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+// maxQueryOperations is the largest number of operations in a POST /query request.
+const maxQueryOperations = 20
+
+// Query runs several named reads at once, e.g. the lists of a dashboard, and
+// returns their results keyed by name. The reads run concurrently, each one as
+// GET /{resource} would with the same parameters, so one failing read does not
+// fail the others.
+//
+// Parameters:
+// - w: The HTTP response writer.
+// - r: The HTTP request with a JSON object of models.QueryOperation keyed by name.
+// - newSlice: Returns a pointer to a slice of the model of a resource, to run the reads.
+//
+// Returns:
+// - HTTP 400 if the body is invalid, empty or has more than maxQueryOperations operations.
+// - JSON object of models.QueryResult keyed by name otherwise.
+func (c *Controller) Query(w http.ResponseWriter, r *http.Request, newSlice ModelFactory) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var operations map[string]models.QueryOperation
+
+	err := json.NewDecoder(r.Body).Decode(&operations)
+	if err == nil && (len(operations) == 0 || len(operations) > maxQueryOperations) {
+		err = fmt.Errorf("between 1 and %d operations are required", maxQueryOperations)
+	}
+
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	principal := middlewares.PrincipalFromContext(r.Context())
+	publishedOnly := middlewares.PublishedOnlyFromContext(r.Context())
+
+	results := make(map[string]models.QueryResult, len(operations))
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+
+	for name, operation := range operations {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			result := c.runQueryOperation(r.Context(), operation, principal, publishedOnly, newSlice)
+
+			mu.Lock()
+			results[name] = result
+			mu.Unlock()
+		}()
+	}
+
+	wg.Wait()
+
+	_ = EncodeJSON(w, results)
+}
+
+// runQueryOperation runs one read of a POST /query request.
+func (c *Controller) runQueryOperation(ctx context.Context, operation models.QueryOperation,
+	principal models.Principal, publishedOnly bool, newSlice ModelFactory,
+) models.QueryResult {
+	// Service accounts need the read scope of every resource they query
+	if principal.Scopes != nil && !models.ScopeAllows(principal.Scopes, operation.Resource, models.ScopeRead) {
+		return models.QueryResult{
+			Status: http.StatusForbidden,
+			Error:  "Forbidden: missing scope " + operation.Resource + ":" + models.ScopeRead,
+		}
+	}
+
+	records, ok := newSlice(operation.Resource)
+	if !ok {
+		return models.QueryResult{Status: http.StatusNotFound, Error: "Invalid resource: " + operation.Resource}
+	}
+
+	list, status, err := c.listRecords(c.transactions(ctx, operation.Resource), operation.Resource, records,
+		queryOperationParams(operation), publishedOnly)
+	if err != nil {
+		return models.QueryResult{Status: status, Error: err.Error()}
+	}
+
+	result := models.QueryResult{
+		Status:          http.StatusOK,
+		Data:            records,
+		Total:           list.total.count,
+		TotalIsEstimate: list.total.estimated,
+	}
+
+	if list.perPage < list.requestedPerPage {
+		result.PerPage = list.perPage
+	}
+
+	return result
+}
+
+// queryOperationParams converts an operation into the query parameters of GET /{resource}.
+func queryOperationParams(operation models.QueryOperation) url.Values {
+	queryParams := url.Values{}
+
+	for key, value := range operation.Filters {
+		queryParams.Set(key, value)
+	}
+
+	if len(operation.Sort) > 0 {
+		queryParams.Set("sort", strings.Join(operation.Sort, ","))
+	}
+
+	if len(operation.Fields) > 0 {
+		queryParams.Set("fields", strings.Join(operation.Fields, ","))
+	}
+
+	if operation.Page != 0 {
+		queryParams.Set("page", strconv.Itoa(operation.Page))
+	}
+
+	if operation.PerPage != 0 {
+		queryParams.Set("per_page", strconv.Itoa(operation.PerPage))
+	}
+
+	return queryParams
+}
+
+// ListResources returns the names of the resources that can be listed and
+// filtered with GET /{resource}, e.g. to fill a resource picker.
+//
+// Parameters:
+// - w: The HTTP response writer.
+// - resources: The names of the resources.
+func (c *Controller) ListResources(w http.ResponseWriter, _ *http.Request, resources []string) {
+	w.Header().Set("Content-Type", "application/json")
+
+	_ = json.NewEncoder(w).Encode(resources)
+}
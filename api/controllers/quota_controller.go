@@ -0,0 +1,319 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/r4ulcl/api_template/database"
+	"github.com/r4ulcl/api_template/utils"
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+// ListQuotas returns every quota.
+//
+// Returns:
+// - HTTP 500 if the retrieval fails.
+// - JSON array of quotas if successful.
+func (c *Controller) ListQuotas(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	quotas, err := c.BC.ListQuotas()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(quotas)
+}
+
+// CreateQuota stores a new quota.
+//
+// Parameters:
+// - w: The HTTP response writer.
+// - r: The HTTP request with a models.Quota.
+// - newModel: Returns a pointer to a model of a resource, to check the resource of the quota.
+//
+// Returns:
+// - HTTP 400 if the quota is invalid.
+// - HTTP 409 if the resource already has a quota for the same owner.
+// - HTTP 500 if the quota cannot be stored.
+// - HTTP 201 with the stored quota if successful.
+func (c *Controller) CreateQuota(w http.ResponseWriter, r *http.Request, newModel ModelFactory) {
+	w.Header().Set("Content-Type", "application/json")
+
+	quota, ok := decodeQuota(w, r, newModel)
+	if !ok {
+		return
+	}
+
+	if err := c.BC.CreateQuota(&quota); err != nil {
+		writeQuotaError(w, err)
+
+		return
+	}
+
+	c.auditConfig(r, models.ConfigQuota, strconv.FormatUint(uint64(quota.ID), 10), nil, quota)
+
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(quota)
+}
+
+// UpdateQuota replaces an existing quota.
+//
+// Parameters:
+// - w: The HTTP response writer.
+// - r: The HTTP request with a models.Quota.
+// - newModel: Returns a pointer to a model of a resource, to check the resource of the quota.
+//
+// Returns:
+// - HTTP 400 if the quota or its ID is invalid.
+// - HTTP 404 if the quota does not exist.
+// - HTTP 409 if the resource already has another quota for the same owner.
+// - HTTP 500 if the quota cannot be stored.
+// - JSON object of the stored quota if successful.
+func (c *Controller) UpdateQuota(w http.ResponseWriter, r *http.Request, newModel ModelFactory) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, ok := quotaIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	quota, ok := decodeQuota(w, r, newModel)
+	if !ok {
+		return
+	}
+
+	before := storedConfig(c.BC.GetQuota(id))
+
+	if err := c.BC.UpdateQuota(id, &quota); err != nil {
+		writeQuotaError(w, err)
+
+		return
+	}
+
+	c.auditConfig(r, models.ConfigQuota, strconv.FormatUint(uint64(id), 10), before, quota)
+
+	_ = json.NewEncoder(w).Encode(quota)
+}
+
+// DeleteQuota removes a quota.
+//
+// Returns:
+// - HTTP 400 if the ID is invalid.
+// - HTTP 404 if the quota does not exist.
+// - HTTP 500 if deletion fails.
+// - JSON confirmation message if successful.
+func (c *Controller) DeleteQuota(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, ok := quotaIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	before := storedConfig(c.BC.GetQuota(id))
+
+	if err := c.BC.DeleteQuota(id); err != nil {
+		writeQuotaError(w, err)
+
+		return
+	}
+
+	c.auditConfig(r, models.ConfigQuota, strconv.FormatUint(uint64(id), 10), before, nil)
+
+	_ = json.NewEncoder(w).Encode(map[string]string{"message": "Deleted successfully"})
+}
+
+// QuotaUsage reports the usage of every quota: the number of records of each
+// owner of the resources with a quota, next to the quota applying to them.
+// Owners with a quota of their own are listed even without records.
+//
+// Parameters:
+// - w: The HTTP response writer.
+// - r: The HTTP request.
+// - newSlice: Returns a pointer to a slice of the model of a resource, to count its records.
+//
+// Returns:
+// - HTTP 500 if the retrieval fails.
+// - JSON array of the models.QuotaUsage, sorted by resource and owner, if successful.
+func (c *Controller) QuotaUsage(w http.ResponseWriter, _ *http.Request, newSlice ModelFactory) {
+	w.Header().Set("Content-Type", "application/json")
+
+	quotas, err := c.BC.ListQuotas()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	// Quotas of each resource, by owner
+	byResource := map[string]map[string]int64{}
+	for _, quota := range quotas {
+		if byResource[quota.Resource] == nil {
+			byResource[quota.Resource] = map[string]int64{}
+		}
+
+		byResource[quota.Resource][quota.Owner] = quota.MaxRows
+	}
+
+	usages := []models.QuotaUsage{}
+
+	for resource, limits := range byResource {
+		model, ok := newSlice(resource)
+		if !ok {
+			// The resource was removed after its quota was stored
+			continue
+		}
+
+		rows, err := c.BC.QuotaUsage(model)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: resource + ": " + err.Error()})
+
+			return
+		}
+
+		for owner := range limits {
+			if _, ok := rows[owner]; !ok && owner != "" {
+				rows[owner] = 0
+			}
+		}
+
+		for owner, count := range rows {
+			maxRows, ok := limits[owner]
+			if !ok {
+				if maxRows, ok = limits[""]; !ok {
+					// Owners without a quota when the resource has no default one
+					continue
+				}
+			}
+
+			usages = append(usages, models.QuotaUsage{
+				Resource: resource,
+				Owner:    owner,
+				Rows:     count,
+				MaxRows:  maxRows,
+				Exceeded: count >= maxRows,
+			})
+		}
+	}
+
+	sort.Slice(usages, func(i, j int) bool {
+		if usages[i].Resource != usages[j].Resource {
+			return usages[i].Resource < usages[j].Resource
+		}
+
+		return usages[i].Owner < usages[j].Owner
+	})
+
+	_ = json.NewEncoder(w).Encode(usages)
+}
+
+// checkQuota checks the quota of the owner of a new record, writing a 403
+// response if it is exceeded. Replacing an existing record is not checked, as
+// it does not add a record.
+//
+// Returns:
+// - true if the record can be stored, false if a response was written.
+func (c *Controller) checkQuota(w http.ResponseWriter, resource string, model interface{}, overwrite bool) bool {
+	if overwrite {
+		existing := reflect.New(reflect.TypeOf(model).Elem()).Interface()
+
+		err := c.BC.GetRecordsByID(existing, database.RecordID(model))
+		if err == nil {
+			return true
+		}
+
+		if !errors.Is(err, database.ErrRecordNotFound) {
+			w.WriteHeader(http.StatusInternalServerError)
+			_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+			return false
+		}
+	}
+
+	if err := c.BC.CheckQuota(resource, model); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, database.ErrQuotaExceeded) {
+			status = http.StatusForbidden
+		}
+
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return false
+	}
+
+	return true
+}
+
+// quotaIDParam parses the quota ID of the URL, writing a 400 response if it is invalid.
+func quotaIDParam(w http.ResponseWriter, r *http.Request) (uint, bool) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "Invalid quota ID"})
+
+		return 0, false
+	}
+
+	return uint(id), true
+}
+
+// decodeQuota decodes and validates a quota, writing a 400 response on failure.
+// Only the resources with an owner field take quotas per owner.
+func decodeQuota(w http.ResponseWriter, r *http.Request, newModel ModelFactory) (models.Quota, bool) {
+	var quota models.Quota
+
+	err := json.NewDecoder(r.Body).Decode(&quota)
+	if err == nil {
+		err = quota.Validate()
+	}
+
+	if err == nil {
+		model, ok := newModel(quota.Resource)
+
+		switch {
+		case !ok:
+			err = errors.New("Invalid resource: " + quota.Resource)
+		case quota.Owner != "":
+			if _, ok := utils.OwnerField(reflect.TypeOf(model).Elem()); !ok {
+				err = errors.New(quota.Resource + " has no owner field, so its quota can't have an owner")
+			}
+		}
+	}
+
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return quota, false
+	}
+
+	return quota, true
+}
+
+// writeQuotaError writes a 404 for unknown quotas, a 409 for duplicate quotas
+// and a 500 for any other error.
+func writeQuotaError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+
+	switch {
+	case errors.Is(err, database.ErrQuotaNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, database.ErrQuotaExists):
+		status = http.StatusConflict
+	}
+
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+}
@@ -0,0 +1,169 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/r4ulcl/api_template/api/middlewares"
+	"github.com/r4ulcl/api_template/database"
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+// defaultLockTTL is how long a record lock lasts when Controller.LockTTL is zero.
+const defaultLockTTL = 15 * time.Minute
+
+// GetLock returns the active lock of the record identified by its tokenized ID.
+//
+// Parameters:
+// - w: The HTTP response writer.
+// - r: The HTTP request with the {id} URL parameter.
+// - resource: The name of the resource (e.g. "example1").
+//
+// Returns:
+// - HTTP 404 if the record is not locked.
+// - HTTP 500 if the lock cannot be retrieved.
+// - JSON object of the lock if successful.
+func (c *Controller) GetLock(w http.ResponseWriter, r *http.Request, resource string) {
+	w.Header().Set("Content-Type", "application/json")
+
+	lock, err := c.BC.GetRecordLock(resource, mux.Vars(r)["id"])
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, database.ErrLockNotFound) {
+			status = http.StatusNotFound
+		}
+
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(lock)
+}
+
+// Lock takes, or renews, the lock of a record for the authenticated user, so
+// other users can't update or delete it until it is released or expires.
+//
+// Parameters:
+// - w: The HTTP response writer.
+// - r: The HTTP request with the {id} URL parameter.
+// - resource: The name of the resource (e.g. "example1").
+// - model: A pointer to the struct representing the database entity.
+//
+// Returns:
+// - HTTP 404 if the record does not exist.
+// - HTTP 423 if another user holds the lock.
+// - HTTP 500 if the lock cannot be stored.
+// - JSON object of the lock if successful.
+func (c *Controller) Lock(w http.ResponseWriter, r *http.Request, resource string, model interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+
+	tokenizedID := mux.Vars(r)["id"]
+
+	if err := c.BC.GetRecordsByID(model, tokenizedID); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, database.ErrRecordNotFound) {
+			status = http.StatusNotFound
+		}
+
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	ttl := c.LockTTL
+	if ttl <= 0 {
+		ttl = defaultLockTTL
+	}
+
+	lock, err := c.BC.LockRecord(resource, tokenizedID, middlewares.UsernameFromContext(r.Context()), ttl)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, database.ErrRecordLocked) {
+			status = http.StatusLocked
+		}
+
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(lock)
+}
+
+// Unlock releases the lock of a record. Only the lock holder can release it,
+// unless the "force" query parameter is true.
+//
+// Parameters:
+// - w: The HTTP response writer.
+// - r: The HTTP request with the {id} URL parameter and the optional "force" query parameter.
+// - resource: The name of the resource (e.g. "example1").
+//
+// Returns:
+// - HTTP 400 if "force" is not a boolean.
+// - HTTP 404 if the record is not locked.
+// - HTTP 423 if another user holds the lock and "force" is not true.
+// - HTTP 500 if the lock cannot be released.
+// - JSON confirmation message if successful.
+func (c *Controller) Unlock(w http.ResponseWriter, r *http.Request, resource string) {
+	w.Header().Set("Content-Type", "application/json")
+
+	force := false
+
+	if raw := r.URL.Query().Get("force"); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "invalid force: " + raw})
+
+			return
+		}
+
+		force = parsed
+	}
+
+	err := c.BC.UnlockRecord(resource, mux.Vars(r)["id"], middlewares.UsernameFromContext(r.Context()), force)
+	if err != nil {
+		status := http.StatusInternalServerError
+
+		switch {
+		case errors.Is(err, database.ErrLockNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, database.ErrRecordLocked):
+			status = http.StatusLocked
+		}
+
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(map[string]string{"message": "Record unlocked"})
+}
+
+// checkLock writes HTTP 423 and returns false if another user holds the lock
+// of a record, so Update and Delete don't clobber an edit in progress.
+func (c *Controller) checkLock(w http.ResponseWriter, r *http.Request, resource, tokenizedID string) bool {
+	err := c.BC.CheckRecordLock(resource, tokenizedID, middlewares.UsernameFromContext(r.Context()))
+	if err == nil {
+		return true
+	}
+
+	status := http.StatusInternalServerError
+	if errors.Is(err, database.ErrRecordLocked) {
+		status = http.StatusLocked
+	}
+
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+	return false
+}
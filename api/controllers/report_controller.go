@@ -0,0 +1,409 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/r4ulcl/api_template/api/middlewares"
+	"github.com/r4ulcl/api_template/database"
+	"github.com/r4ulcl/api_template/utils"
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+const (
+	// maxReportRows is the largest number of rows a report query may return.
+	maxReportRows = 10000
+
+	// maxReportListed is the largest number of IDs listed per section of a report email.
+	maxReportListed = 50
+)
+
+var (
+	// errReportTooLarge is returned when a report query returns more than maxReportRows rows.
+	errReportTooLarge = fmt.Errorf("the view returns more than %d rows", maxReportRows)
+
+	// errReportRunning is returned when a report is run while another run starts.
+	errReportRunning = errors.New("the report is already running")
+)
+
+// ListReports returns every report.
+//
+// Returns:
+// - HTTP 500 if the retrieval fails.
+// - JSON array of reports if successful.
+func (c *Controller) ListReports(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	reports, err := c.BC.ListReports()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(reports)
+}
+
+// CreateReport stores a new report running a view of the authenticated admin.
+//
+// Returns:
+// - HTTP 400 if the report is invalid or its view does not exist.
+// - HTTP 500 if the report cannot be stored.
+// - HTTP 201 with the stored report if successful.
+func (c *Controller) CreateReport(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	report, ok := c.decodeReport(w, r)
+	if !ok {
+		return
+	}
+
+	if err := c.BC.CreateReport(&report); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(report)
+}
+
+// UpdateReport replaces an existing report. The view is resolved for the authenticated admin.
+//
+// Returns:
+// - HTTP 400 if the report, its view or its ID is invalid.
+// - HTTP 404 if the report does not exist.
+// - HTTP 500 if the report cannot be stored.
+// - JSON object of the stored report if successful.
+func (c *Controller) UpdateReport(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, ok := reportIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	report, ok := c.decodeReport(w, r)
+	if !ok {
+		return
+	}
+
+	if err := c.BC.UpdateReport(id, &report); err != nil {
+		writeReportError(w, err)
+
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(report)
+}
+
+// DeleteReport removes a report.
+//
+// Returns:
+// - HTTP 400 if the ID is invalid.
+// - HTTP 404 if the report does not exist.
+// - HTTP 500 if deletion fails.
+// - JSON confirmation message if successful.
+func (c *Controller) DeleteReport(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, ok := reportIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	if err := c.BC.DeleteReport(id); err != nil {
+		writeReportError(w, err)
+
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(map[string]string{"message": "Deleted successfully"})
+}
+
+// RunReport runs a report now, whether it is enabled or not, and sends its
+// differences with the previous run.
+//
+// Parameters:
+// - w: The HTTP response writer.
+// - r: The HTTP request with the {id} URL parameter.
+// - newSlice: Returns a pointer to a slice of the model of a resource, to run the view.
+//
+// Returns:
+// - HTTP 400 if the ID is invalid.
+// - HTTP 404 if the report does not exist.
+// - HTTP 409 if the report is already running.
+// - HTTP 500 if the report fails.
+// - JSON object of the models.ReportDiff if successful.
+func (c *Controller) RunReport(w http.ResponseWriter, r *http.Request, newSlice ModelFactory) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, ok := reportIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	report, err := c.BC.GetReport(id)
+	if err != nil {
+		writeReportError(w, err)
+
+		return
+	}
+
+	diff, err := c.claimAndRunReport(report, newSlice)
+	if err != nil {
+		writeReportError(w, err)
+
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(diff)
+}
+
+// WatchReports runs the due reports every interval until the context is cancelled.
+//
+// Parameters:
+// - ctx: Stops the scheduler when cancelled.
+// - interval: How often the due reports are checked.
+// - newSlice: Returns a pointer to a slice of the model of a resource, to run the views.
+func (c *Controller) WatchReports(ctx context.Context, interval time.Duration, newSlice ModelFactory) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reports, err := c.BC.DueReports(time.Now())
+			if err != nil {
+				log.Println("Error listing due reports:", err)
+
+				continue
+			}
+
+			for _, report := range reports {
+				if _, err := c.claimAndRunReport(report, newSlice); err != nil && !errors.Is(err, errReportRunning) {
+					log.Println("Error running report "+report.Name+":", err)
+				}
+			}
+		}
+	}
+}
+
+// claimAndRunReport records the start of a run of a report, runs it, and stores its error, if any.
+func (c *Controller) claimAndRunReport(report models.Report, newSlice ModelFactory) (models.ReportDiff, error) {
+	now := time.Now()
+
+	claimed, err := c.BC.ClaimReport(report, now)
+	if err != nil {
+		return models.ReportDiff{}, err
+	}
+
+	if !claimed {
+		return models.ReportDiff{}, errReportRunning
+	}
+
+	diff, err := c.runReport(report, newSlice, now)
+
+	if storeErr := c.BC.SetReportError(report.ID, err); storeErr != nil {
+		log.Println("Error storing the result of a report:", storeErr)
+	}
+
+	return diff, err
+}
+
+// runReport runs the view of a report, compares its rows with the previous
+// run and, if they differ, sends the differences to the report recipients.
+func (c *Controller) runReport(report models.Report, newSlice ModelFactory, now time.Time) (models.ReportDiff, error) {
+	view, err := c.BC.GetSavedQuery(report.Owner, report.View)
+	if err != nil {
+		return models.ReportDiff{}, err
+	}
+
+	records, ok := newSlice(view.Resource)
+	if !ok {
+		return models.ReportDiff{}, fmt.Errorf("invalid resource %q in view %s", view.Resource, view.Name)
+	}
+
+	queryParams, err := url.ParseQuery(view.Query)
+	if err != nil {
+		return models.ReportDiff{}, err
+	}
+
+	opts, err := parseQueryOptions(queryParams)
+	if err != nil {
+		return models.ReportDiff{}, err
+	}
+
+	opts.Limit = maxReportRows + 1
+
+	if err := c.BC.GetAllRecords(records, opts); err != nil {
+		return models.ReportDiff{}, err
+	}
+
+	list := reflect.ValueOf(records).Elem()
+	if list.Len() > maxReportRows {
+		return models.ReportDiff{}, errReportTooLarge
+	}
+
+	rows := make(map[string]json.RawMessage, list.Len())
+
+	for i := range list.Len() {
+		record := list.Index(i).Addr().Interface()
+
+		row, err := json.Marshal(record)
+		if err != nil {
+			return models.ReportDiff{}, err
+		}
+
+		rows[database.RecordID(record)] = row
+	}
+
+	diff, err := c.BC.DiffReportSnapshot(report.ID, rows, now)
+	if err != nil {
+		return diff, err
+	}
+
+	diff.Report = report.Name
+	diff.Resource = view.Resource
+
+	if diff.Baseline || diff.Empty() {
+		return diff, nil
+	}
+
+	return diff, c.sendReport(report, diff)
+}
+
+// sendReport emails the differences found by a report and posts them to its webhook.
+func (c *Controller) sendReport(report models.Report, diff models.ReportDiff) error {
+	summary := fmt.Sprintf("Report %s (%s): %d new, %d changed and %d removed of %d rows",
+		report.Name, diff.Resource, len(diff.New), len(diff.Changed), len(diff.Removed), diff.Rows)
+
+	var errs []error
+
+	if recipients := report.Recipients(); len(recipients) > 0 {
+		body := reportEmailBody(summary, diff)
+
+		for _, recipient := range recipients {
+			if err := c.Mailer.Send(recipient, "Report "+report.Name, body); err != nil {
+				errs = append(errs, fmt.Errorf("email to %s: %w", recipient, err))
+			}
+		}
+	}
+
+	if report.WebhookURL != "" {
+		if err := utils.SendWebhookAlert(report.WebhookURL, summary, diff); err != nil {
+			errs = append(errs, fmt.Errorf("webhook: %w", err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// reportEmailBody lists the new, changed and removed rows of a diff, up to maxReportListed per section.
+func reportEmailBody(summary string, diff models.ReportDiff) string {
+	var body strings.Builder
+
+	body.WriteString(summary + "\n")
+
+	section := func(title string, lines []string) {
+		if len(lines) == 0 {
+			return
+		}
+
+		body.WriteString("\n" + title + ":\n")
+
+		for i, line := range lines {
+			if i == maxReportListed {
+				fmt.Fprintf(&body, "- ... and %d more\n", len(lines)-maxReportListed)
+
+				break
+			}
+
+			body.WriteString("- " + line + "\n")
+		}
+	}
+
+	changed := make([]string, 0, len(diff.Changed))
+	for _, change := range diff.Changed {
+		changed = append(changed, change.ID+" ("+strings.Join(change.Fields, ", ")+")")
+	}
+
+	section("New", diff.New)
+	section("Changed", changed)
+	section("Removed", diff.Removed)
+
+	return body.String()
+}
+
+// decodeReport decodes and validates a report, writing a 400 response on failure.
+//
+// The owner of the report is the authenticated admin, who must be able to use its view.
+func (c *Controller) decodeReport(w http.ResponseWriter, r *http.Request) (models.Report, bool) {
+	var report models.Report
+
+	err := json.NewDecoder(r.Body).Decode(&report)
+	if err == nil {
+		report.Owner = middlewares.UsernameFromContext(r.Context())
+		err = report.Validate()
+	}
+
+	status := http.StatusBadRequest
+
+	if err == nil {
+		if _, err = c.BC.GetSavedQuery(report.Owner, report.View); err != nil && !errors.Is(err, database.ErrViewNotFound) {
+			status = http.StatusInternalServerError
+		}
+	}
+
+	if err != nil {
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return report, false
+	}
+
+	return report, true
+}
+
+// reportIDParam parses the {id} URL parameter, writing a 400 response on failure.
+func reportIDParam(w http.ResponseWriter, r *http.Request) (uint, bool) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "Invalid report ID"})
+
+		return 0, false
+	}
+
+	return uint(id), true
+}
+
+// writeReportError writes a 404 for unknown reports, a 409 for reports
+// already running and a 500 for any other error.
+func writeReportError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+
+	switch {
+	case errors.Is(err, database.ErrReportNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, errReportRunning):
+		status = http.StatusConflict
+	}
+
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+}
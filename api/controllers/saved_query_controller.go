@@ -0,0 +1,115 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/gorilla/mux"
+	"github.com/r4ulcl/api_template/api/middlewares"
+	"github.com/r4ulcl/api_template/database"
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+// ListViews returns the saved queries visible to the authenticated user.
+//
+// The optional "resource" query parameter limits the list to one resource.
+//
+// Returns:
+// - HTTP 500 if the retrieval fails.
+// - JSON array of saved queries if successful.
+func (c *Controller) ListViews(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	username := middlewares.UsernameFromContext(r.Context())
+
+	views, err := c.BC.ListSavedQueries(username, r.URL.Query().Get("resource"))
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(views)
+}
+
+// SaveView creates or replaces a saved query owned by the authenticated user.
+//
+// Only admins may create shared views.
+//
+// Returns:
+// - HTTP 400 if the request body or the stored query string is invalid.
+// - HTTP 403 if a non-admin user tries to share a view.
+// - HTTP 500 if the view cannot be stored.
+// - HTTP 201 with the stored view if successful.
+func (c *Controller) SaveView(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var input models.SavedQueryRequest
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil || input.Name == "" || input.Resource == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "Name and resource cannot be empty"})
+
+		return
+	}
+
+	if _, err := url.ParseQuery(input.Query); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "Invalid query: " + err.Error()})
+
+		return
+	}
+
+	if input.Shared && middlewares.RoleFromContext(r.Context()) != string(models.AdminRole) {
+		w.WriteHeader(http.StatusForbidden)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "Forbidden: only admins can share views"})
+
+		return
+	}
+
+	view := models.SavedQuery{
+		Name:     input.Name,
+		Owner:    middlewares.UsernameFromContext(r.Context()),
+		Resource: input.Resource,
+		Query:    input.Query,
+		Shared:   input.Shared,
+	}
+
+	if err := c.BC.SaveQuery(&view); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(view)
+}
+
+// DeleteView removes a saved query owned by the authenticated user.
+//
+// Returns:
+// - HTTP 404 if the user does not own a view with that name.
+// - HTTP 500 if deletion fails.
+// - JSON confirmation message if successful.
+func (c *Controller) DeleteView(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	username := middlewares.UsernameFromContext(r.Context())
+
+	if err := c.BC.DeleteSavedQuery(username, mux.Vars(r)["name"]); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, database.ErrViewNotFound) {
+			status = http.StatusNotFound
+		}
+
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(map[string]string{"message": "Deleted successfully"})
+}
@@ -0,0 +1,214 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/r4ulcl/api_template/api/middlewares"
+	"github.com/r4ulcl/api_template/database"
+	"github.com/r4ulcl/api_template/utils"
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+// ListServiceAccounts returns every service account, without secrets.
+//
+// Returns:
+// - HTTP 500 if the retrieval fails.
+// - JSON array of service accounts if successful.
+func (c *Controller) ListServiceAccounts(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	accounts, err := c.BC.ListServiceAccounts()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(accounts)
+}
+
+// CreateServiceAccount creates a service account with a generated client ID and secret.
+//
+// Returns:
+// - HTTP 400 if the request body or the scopes are invalid.
+// - HTTP 500 if the account cannot be stored.
+// - HTTP 201 with the account and its client secret, which is only shown once.
+func (c *Controller) CreateServiceAccount(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var account models.ServiceAccount
+	if err := json.NewDecoder(r.Body).Decode(&account); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	account.CreatedBy = middlewares.UsernameFromContext(r.Context())
+	account.LastUsedAt = nil
+
+	if err := validateServiceAccount(&account); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	secret, err := c.BC.CreateServiceAccount(&account)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	c.auditConfig(r, models.ConfigServiceAccount, account.ClientID, nil, account)
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(models.ServiceAccountCredentials{ServiceAccount: account, ClientSecret: secret})
+}
+
+// UpdateServiceAccount changes the name, description, role, scopes or state of a service account.
+//
+// Omitted fields keep their values. Disabling an account blocks new tokens; tokens
+// already issued stay valid until they expire.
+//
+// Returns:
+// - HTTP 400 if the request body or the scopes are invalid.
+// - HTTP 404 if the account does not exist.
+// - JSON object of the updated account if successful.
+func (c *Controller) UpdateServiceAccount(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	account, err := c.BC.GetServiceAccount(mux.Vars(r)["id"])
+	if err != nil {
+		writeServiceAccountError(w, err)
+
+		return
+	}
+
+	before := account
+
+	// Decode on top of a copy so the body can't change the ID, secret or audit fields
+	update := account
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	account.Name = update.Name
+	account.Description = update.Description
+	account.Role = update.Role
+	account.Scopes = update.Scopes
+	account.Disabled = update.Disabled
+
+	if err := validateServiceAccount(&account); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	if err := c.BC.UpdateServiceAccount(&account); err != nil {
+		writeServiceAccountError(w, err)
+
+		return
+	}
+
+	c.auditConfig(r, models.ConfigServiceAccount, account.ClientID, before, account)
+
+	_ = json.NewEncoder(w).Encode(account)
+}
+
+// RotateServiceAccountSecret replaces the secret of a service account.
+//
+// Returns:
+// - HTTP 404 if the account does not exist.
+// - JSON object with the account and its new client secret if successful.
+func (c *Controller) RotateServiceAccountSecret(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	account, secret, err := c.BC.RotateServiceAccountSecret(mux.Vars(r)["id"])
+	if err != nil {
+		writeServiceAccountError(w, err)
+
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	_ = json.NewEncoder(w).Encode(models.ServiceAccountCredentials{ServiceAccount: account, ClientSecret: secret})
+}
+
+// DeleteServiceAccount removes a service account.
+//
+// Returns:
+// - HTTP 404 if the account does not exist.
+// - JSON confirmation message if successful.
+func (c *Controller) DeleteServiceAccount(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	clientID := mux.Vars(r)["id"]
+	before := storedConfig(c.BC.GetServiceAccount(clientID))
+
+	if err := c.BC.DeleteServiceAccount(clientID); err != nil {
+		writeServiceAccountError(w, err)
+
+		return
+	}
+
+	c.auditConfig(r, models.ConfigServiceAccount, clientID, before, nil)
+
+	_ = json.NewEncoder(w).Encode(map[string]string{"message": "Deleted successfully"})
+}
+
+// validateServiceAccount cleans the account fields and checks the name, role and scopes.
+func validateServiceAccount(account *models.ServiceAccount) error {
+	utils.Sanitize(account)
+
+	if account.Name == "" {
+		return errors.New("name is required")
+	}
+
+	switch account.Role {
+	case "":
+		account.Role = models.UserRole
+	case models.UserRole, models.AdminRole:
+	default:
+		return fmt.Errorf("invalid role %q", account.Role)
+	}
+
+	scopes := account.ScopeList()
+	if len(scopes) == 0 {
+		return errors.New("at least one scope is required")
+	}
+
+	for _, scope := range scopes {
+		if !models.ValidScope(scope) {
+			return fmt.Errorf("invalid scope %q, expected <resource>:<read|write|*>", scope)
+		}
+	}
+
+	account.Scopes = strings.Join(scopes, ",")
+
+	return nil
+}
+
+// writeServiceAccountError answers with 404 for unknown accounts and 500 otherwise.
+func writeServiceAccountError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	if errors.Is(err, database.ErrServiceAccountNotFound) {
+		status = http.StatusNotFound
+	}
+
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+}
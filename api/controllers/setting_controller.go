@@ -0,0 +1,185 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/r4ulcl/api_template/api/middlewares"
+	"github.com/r4ulcl/api_template/database"
+	"github.com/r4ulcl/api_template/utils"
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+// ListSettings returns every runtime setting.
+//
+// Returns:
+// - HTTP 500 if the retrieval fails.
+// - JSON array of settings if successful.
+func (c *Controller) ListSettings(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	settings, err := c.BC.ListSettings()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(settings)
+}
+
+// GetSetting returns the setting named in the URL.
+//
+// Returns:
+// - HTTP 404 if the setting does not exist.
+// - HTTP 500 if the retrieval fails.
+// - JSON object of the setting if successful.
+func (c *Controller) GetSetting(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	setting, err := c.BC.GetSetting(mux.Vars(r)["key"])
+	if err != nil {
+		writeSettingError(w, err)
+
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(setting)
+}
+
+// SaveSetting creates or replaces the setting named in the URL and records
+// the change in its history.
+//
+// Returns:
+// - HTTP 400 if the body is invalid, the value does not match the type, or the
+// value is invalid for the runtime parameter with the same key.
+// - HTTP 500 if the setting cannot be stored.
+// - JSON object of the stored setting if successful.
+func (c *Controller) SaveSetting(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var setting models.Setting
+	if err := json.NewDecoder(r.Body).Decode(&setting); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	setting.Key = mux.Vars(r)["key"]
+
+	err := setting.Validate()
+	if err == nil {
+		err = utils.ValidateRuntimeSetting(setting.Key, setting.Value)
+	}
+
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	before := storedConfig(c.BC.GetSetting(setting.Key))
+
+	ctx := r.Context()
+	if err := c.BC.SaveSetting(&setting, middlewares.UsernameFromContext(ctx), middlewares.ClientIPFromContext(ctx)); err != nil {
+		writeSettingError(w, err)
+
+		return
+	}
+
+	c.ReloadRuntimeConfig()
+	c.auditConfig(r, models.ConfigSetting, setting.Key, before, setting)
+
+	_ = json.NewEncoder(w).Encode(setting)
+}
+
+// DeleteSetting removes the setting named in the URL and records the change
+// in its history.
+//
+// Returns:
+// - HTTP 404 if the setting does not exist.
+// - HTTP 500 if deletion fails.
+// - JSON confirmation message if successful.
+func (c *Controller) DeleteSetting(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	key := mux.Vars(r)["key"]
+	before := storedConfig(c.BC.GetSetting(key))
+
+	if err := c.BC.DeleteSetting(key, middlewares.UsernameFromContext(r.Context()),
+		middlewares.ClientIPFromContext(r.Context())); err != nil {
+		writeSettingError(w, err)
+
+		return
+	}
+
+	c.ReloadRuntimeConfig()
+	c.auditConfig(r, models.ConfigSetting, key, before, nil)
+
+	_ = json.NewEncoder(w).Encode(map[string]string{"message": "Deleted successfully"})
+}
+
+// GetSettingHistory returns the audit log of the setting named in the URL, newest first.
+//
+// Returns:
+// - HTTP 500 if the retrieval fails.
+// - JSON array of changes if successful.
+func (c *Controller) GetSettingHistory(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	changes, err := c.BC.ListSettingChanges(mux.Vars(r)["key"])
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(changes)
+}
+
+// writeSettingError writes a 404 for unknown settings, a 400 for invalid
+// values and a 500 for any other error.
+func writeSettingError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+
+	switch {
+	case errors.Is(err, database.ErrSettingNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, models.ErrInvalidSetting):
+		status = http.StatusBadRequest
+	}
+
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+}
+
+// GetRuntimeConfig returns the effective value and source of every runtime parameter.
+func (c *Controller) GetRuntimeConfig(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	values := []models.ConfigValue{}
+	if c.Runtime != nil {
+		values = c.Runtime.Values()
+	}
+
+	_ = json.NewEncoder(w).Encode(values)
+}
+
+// ReloadRuntimeConfig applies the settings to the runtime parameters, so changes
+// made through the API take effect without waiting for the next poll.
+func (c *Controller) ReloadRuntimeConfig() {
+	if c.Runtime == nil {
+		return
+	}
+
+	if err := c.BC.LoadRuntimeConfig(c.Runtime); err != nil {
+		log.Println("Error reloading runtime config:", err)
+	}
+}
@@ -0,0 +1,188 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/r4ulcl/api_template/database"
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+const (
+	// defaultStatsSampleSize is the number of rows sampled for the top values.
+	defaultStatsSampleSize = 10000
+
+	// defaultStatsTopValues is the number of top values returned per column.
+	defaultStatsTopValues = 5
+)
+
+// StatsSummary returns the database statistics as plain text, one line per table.
+//
+// It is used by chat integrations like the Telegram bot "/stats" command.
+func (c *Controller) StatsSummary(_ context.Context) (string, error) {
+	stats, err := c.BC.GetDBStats()
+	if err != nil {
+		return "", err
+	}
+
+	var summary strings.Builder
+
+	for _, table := range stats.Tables {
+		fmt.Fprintf(&summary, "%s: %d rows, %d bytes\n", table.Table, table.Rows, table.DataBytes+table.IndexBytes)
+	}
+
+	fmt.Fprintf(&summary, "Total: %d rows, %d bytes", stats.TotalRows, stats.TotalBytes)
+
+	return summary.String(), nil
+}
+
+// GetStats returns the row counts and sizes of every table in the database,
+// along with the active announcements.
+//
+// Returns:
+// - HTTP 500 if the statistics cannot be computed.
+// - JSON object with the database statistics if successful.
+func (c *Controller) GetStats(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	stats, err := c.BC.GetDBStats()
+	if err == nil {
+		stats.Announcements, err = c.BC.GetActiveAnnouncements(time.Now())
+	}
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(stats)
+}
+
+// GetColumnStats returns per-column cardinality, null counts, min/max and top
+// values of a table.
+//
+// The optional "sample" and "top" query parameters control the number of rows
+// sampled and the number of top values returned per column.
+//
+// Returns:
+// - HTTP 400 if "sample" or "top" are not positive integers.
+// - HTTP 404 if the table does not exist.
+// - HTTP 500 if the statistics cannot be computed.
+// - JSON object with the column statistics if successful.
+func (c *Controller) GetColumnStats(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	sampleSize, errSample := positiveIntParam(r, "sample", defaultStatsSampleSize)
+	topN, errTop := positiveIntParam(r, "top", defaultStatsTopValues)
+
+	if errSample != nil || errTop != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "sample and top must be positive integers"})
+
+		return
+	}
+
+	stats, err := c.BC.GetColumnStats(mux.Vars(r)["table"], sampleSize, topN)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, database.ErrUnknownTable) {
+			status = http.StatusNotFound
+		}
+
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(stats)
+}
+
+// GetStatsHistory returns the stored snapshots of the table statistics, oldest first.
+//
+// The optional "table" query parameter limits the snapshots to one table, and
+// "from" and "to" (RFC 3339 timestamps) to a time range.
+//
+// Returns:
+// - HTTP 400 if "from" or "to" are not RFC 3339 timestamps.
+// - HTTP 500 if the snapshots cannot be retrieved.
+// - JSON array of snapshots if successful.
+func (c *Controller) GetStatsHistory(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	from, errFrom := timeParam(r, "from")
+	to, errTo := timeParam(r, "to")
+
+	if errFrom != nil || errTo != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "from and to must be RFC 3339 timestamps"})
+
+		return
+	}
+
+	snapshots, err := c.BC.GetStatsHistory(r.URL.Query().Get("table"), from, to)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(snapshots)
+}
+
+// GetSchemaGraph returns the tables and their relationships as nodes and edges,
+// ready to render an ER diagram.
+//
+// Returns:
+// - HTTP 500 if the schema cannot be read.
+// - JSON object with the schema graph if successful.
+func (c *Controller) GetSchemaGraph(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	graph, err := c.BC.SchemaGraph()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(graph)
+}
+
+// timeParam reads an RFC 3339 timestamp query parameter, returning the zero
+// time when the parameter is absent.
+func timeParam(r *http.Request, name string) (time.Time, error) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return time.Time{}, nil
+	}
+
+	return time.Parse(time.RFC3339, raw)
+}
+
+// positiveIntParam reads a positive integer query parameter, returning the
+// default value when the parameter is absent.
+func positiveIntParam(r *http.Request, name string, defaultVal int) (int, error) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return defaultVal, nil
+	}
+
+	value, err := strconv.Atoi(raw)
+	if err != nil || value <= 0 {
+		return 0, errors.New("invalid " + name)
+	}
+
+	return value, nil
+}
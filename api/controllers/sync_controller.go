@@ -0,0 +1,378 @@
+package controllers
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"time"
+
+	"github.com/r4ulcl/api_template/api/middlewares"
+	"github.com/r4ulcl/api_template/database"
+	"github.com/r4ulcl/api_template/utils"
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+// maxSyncChanges is the largest number of changes pushed in a POST /sync request.
+const maxSyncChanges = 100
+
+// errInvalidSyncToken is returned when a sync token can't be decoded.
+var errInvalidSyncToken = errors.New("invalid sync token")
+
+// syncCursor is the position of a client in the changes of a resource, kept in its sync token.
+type syncCursor struct {
+	// Watermark is the watermark of the changes of the previous sync.
+	Watermark time.Time `json:"w"`
+
+	// Subset is the hash of the filters of the previous sync (see syncSubsetHash).
+	Subset string `json:"s"`
+}
+
+// syncPull is a resource pulled by a POST /sync request.
+type syncPull struct {
+	subset     string
+	conditions []database.Filter
+}
+
+// Sync mirrors subsets of resources on offline-first clients. The client sends
+// the token of its previous sync, the subsets it mirrors and its local changes;
+// it gets back the changes of each subset since the previous sync, the outcome
+// of each local change and the token of the next sync.
+//
+// The pull runs before the local changes are applied, so a failing pull applies
+// none of them. The applied changes come back in the next pull.
+//
+// Parameters:
+// - w: The HTTP response writer.
+// - r: The HTTP request with a models.SyncRequest.
+// - newModel: Returns a pointer to a model of a resource, to apply the changes.
+// - newSlice: Returns a pointer to a slice of the model of a resource, to pull its changes.
+//
+// Returns:
+// - HTTP 400 if the body, the token, a resource or a filter is invalid.
+// - HTTP 403 if a service account lacks the read scope of a pulled resource.
+// - HTTP 500 if a pull fails.
+// - JSON object of the models.SyncResponse otherwise.
+func (c *Controller) Sync(w http.ResponseWriter, r *http.Request, newModel, newSlice ModelFactory) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var request models.SyncRequest
+
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err == nil && len(request.Changes) > maxSyncChanges {
+		err = fmt.Errorf("at most %d changes are allowed", maxSyncChanges)
+	}
+
+	var cursors map[string]syncCursor
+	if err == nil {
+		cursors, err = decodeSyncToken(request.Token)
+	}
+
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	principal := middlewares.PrincipalFromContext(r.Context())
+
+	pulls, status, err := c.syncPulls(r, request.Resources, principal, newSlice)
+	if err != nil {
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	response := models.SyncResponse{
+		Resources: make(map[string]models.SyncResourceChanges, len(pulls)),
+		Results:   make([]models.SyncResult, 0, len(request.Changes)),
+	}
+
+	for resource, pull := range pulls {
+		cursor, ok := cursors[resource]
+
+		// The client reloads the subset when it has no usable watermark for it
+		reset := !ok || cursor.Subset != pull.subset || time.Since(cursor.Watermark) > database.DeletionRetention
+		if reset {
+			cursor = syncCursor{Subset: pull.subset}
+		}
+
+		model, _ := newSlice(resource)
+
+		changes, err := c.readChanges(resource, model, cursor.Watermark, pull.conditions)
+		if err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, database.ErrInvalidField) || errors.Is(err, database.ErrInvalidFilter) {
+				status = http.StatusBadRequest
+			}
+
+			w.WriteHeader(status)
+			_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: resource + ": " + err.Error()})
+
+			return
+		}
+
+		// The client drops its copy on reset, so there is nothing to delete
+		if reset {
+			changes.Deleted = []string{}
+		}
+
+		response.Resources[resource] = models.SyncResourceChanges{
+			Changed: changes.Changed,
+			Deleted: changes.Deleted,
+			HasMore: changes.HasMore,
+			Reset:   reset,
+		}
+
+		cursors[resource] = syncCursor{Watermark: changes.Watermark, Subset: pull.subset}
+	}
+
+	for _, change := range request.Changes {
+		response.Results = append(response.Results, c.applySyncChange(r, change, principal, newModel))
+	}
+
+	// The cursors of the resources not pulled this time are kept for the next sync
+	response.Token = encodeSyncToken(cursors)
+
+	_ = EncodeJSON(w, response)
+}
+
+// syncPulls checks the resources pulled by a POST /sync request and parses their filters.
+//
+// Returns the HTTP status of the error: 403 for a missing scope, 400 otherwise.
+func (c *Controller) syncPulls(r *http.Request, subsets map[string]models.SyncSubset,
+	principal models.Principal, newSlice ModelFactory,
+) (map[string]syncPull, int, error) {
+	pulls := make(map[string]syncPull, len(subsets))
+
+	for resource, subset := range subsets {
+		// Service accounts need the read scope of every resource they pull
+		if principal.Scopes != nil && !models.ScopeAllows(principal.Scopes, resource, models.ScopeRead) {
+			return nil, http.StatusForbidden, errors.New("Forbidden: missing scope " + resource + ":" + models.ScopeRead)
+		}
+
+		model, ok := newSlice(resource)
+		if !ok || !database.TracksChanges(model) {
+			return nil, http.StatusBadRequest, errors.New("Invalid resource: " + resource)
+		}
+
+		queryParams := url.Values{}
+		for key, value := range subset.Filters {
+			queryParams.Set(key, value)
+		}
+
+		opts, err := parseQueryOptions(queryParams)
+		if err != nil {
+			return nil, http.StatusBadRequest, fmt.Errorf("%s: %w", resource, err)
+		}
+
+		// Users other than admins only mirror the published records
+		if middlewares.PublishedOnlyFromContext(r.Context()) {
+			if filter, ok := c.BC.PublishedFilter(model); ok {
+				opts.Conditions = append(opts.Conditions, filter)
+			}
+		}
+
+		pulls[resource] = syncPull{subset: syncSubsetHash(queryParams), conditions: opts.Conditions}
+	}
+
+	return pulls, http.StatusOK, nil
+}
+
+// applySyncChange applies a local change pushed with POST /sync, as PUT or
+// DELETE /{resource}/{id} would, unless the record changed on the server since
+// the client read it.
+func (c *Controller) applySyncChange(r *http.Request, change models.SyncChange, principal models.Principal,
+	newModel ModelFactory,
+) models.SyncResult {
+	result := models.SyncResult{Resource: change.Resource, ID: change.ID, Status: models.SyncRejected}
+
+	model, ok := newModel(change.Resource)
+
+	switch {
+	case principal.Role != models.AdminRole:
+		result.Error = "Forbidden: Admins only"
+	case principal.Scopes != nil && !models.ScopeAllows(principal.Scopes, change.Resource, models.ScopeWrite):
+		result.Error = "Forbidden: missing scope " + change.Resource + ":" + models.ScopeWrite
+	case !ok:
+		result.Error = "Invalid resource: " + change.Resource
+	case change.Op != models.SyncUpsert && change.Op != models.SyncDelete:
+		result.Error = "op must be upsert or delete"
+	case change.Op == models.SyncUpsert && len(change.Record) == 0:
+		result.Error = "record is required"
+	}
+
+	if result.Error != "" {
+		return result
+	}
+
+	if err := c.BC.CheckRecordLock(change.Resource, change.ID, principal.Username); err != nil {
+		result.Error = err.Error()
+
+		return result
+	}
+
+	err := c.BC.GetRecordsByID(model, change.ID)
+	if err != nil && !errors.Is(err, database.ErrRecordNotFound) {
+		result.Error = err.Error()
+
+		return result
+	}
+
+	exists := err == nil
+
+	if hint := syncConflict(change, model, exists); hint != "" {
+		result.Status = models.SyncConflict
+		result.Hint = hint
+
+		if exists {
+			result.Server = model
+			result.Fields = changedFields(change.Record, model)
+		}
+
+		return result
+	}
+
+	if change.Op == models.SyncDelete {
+		if exists {
+			if err := c.deleteRecord(r, change.Resource, model, change.ID); err != nil {
+				result.Error = err.Error()
+
+				return result
+			}
+		}
+
+		result.Status = models.SyncApplied
+
+		return result
+	}
+
+	if err := c.upsertSyncRecord(r, change, model, exists); err != nil {
+		result.Error = err.Error()
+
+		return result
+	}
+
+	result.Status = models.SyncApplied
+	result.Server = model
+
+	return result
+}
+
+// syncConflict returns the hint of the conflict between a pushed change and the
+// server copy of its record, or an empty hint if the change can be applied.
+//
+// Deleting a record already deleted is not a conflict.
+func syncConflict(change models.SyncChange, model interface{}, exists bool) models.SyncHint {
+	if !exists {
+		if change.Op == models.SyncUpsert && change.BaseUpdatedAt != nil {
+			return models.SyncServerDeleted
+		}
+
+		return ""
+	}
+
+	if change.BaseUpdatedAt == nil {
+		if change.Op == models.SyncUpsert {
+			return models.SyncAlreadyExists
+		}
+
+		return ""
+	}
+
+	if modified, ok := database.LastModified(model); ok && modified.After(*change.BaseUpdatedAt) {
+		return models.SyncServerChanged
+	}
+
+	return ""
+}
+
+// upsertSyncRecord stores the record of a pushed upsert: the record is updated
+// on top of its server copy when it exists, and created otherwise.
+func (c *Controller) upsertSyncRecord(r *http.Request, change models.SyncChange, model interface{},
+	exists bool,
+) error {
+	// Keep a copy of the stored record to restore the server-populated fields
+	original := reflect.Indirect(reflect.ValueOf(model)).Interface()
+
+	if err := json.Unmarshal(change.Record, model); err != nil {
+		return err
+	}
+
+	utils.Sanitize(model)
+
+	if exists {
+		utils.KeepServerFields(model, original)
+	} else if err := utils.PopulateFields(model, middlewares.UsernameFromContext(r.Context()), time.Now()); err != nil {
+		return err
+	}
+
+	if database.RecordID(model) != change.ID {
+		return errors.New("the primary key of the record does not match the id")
+	}
+
+	if err := c.validateRecord(change.Resource, model); err != nil {
+		return err
+	}
+
+	if exists {
+		if err := c.BC.UpdateRecords(model, change.ID); err != nil {
+			return err
+		}
+
+		c.publishEvent(r, models.EventUpdated, change.Resource, change.ID, model)
+		c.notifyAssignees(r, change.Resource, change.ID, model, original)
+
+		return nil
+	}
+
+	if err := c.BC.CheckQuota(change.Resource, model); err != nil {
+		return err
+	}
+
+	if _, err := c.BC.CreateOrUpdateRecord(model, false); err != nil {
+		return err
+	}
+
+	c.publishEvent(r, models.EventCreated, change.Resource, change.ID, model)
+	c.notifyAssignees(r, change.Resource, change.ID, model, nil)
+
+	return nil
+}
+
+// syncSubsetHash identifies the filters of a pulled subset, to reset the client
+// when they change.
+func syncSubsetHash(filters url.Values) string {
+	sum := sha256.Sum256([]byte(filters.Encode()))
+
+	return hex.EncodeToString(sum[:8])
+}
+
+// decodeSyncToken decodes the cursors of a sync token. An empty token has none.
+func decodeSyncToken(token string) (map[string]syncCursor, error) {
+	cursors := map[string]syncCursor{}
+	if token == "" {
+		return cursors, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || json.Unmarshal(raw, &cursors) != nil || cursors == nil {
+		return nil, errInvalidSyncToken
+	}
+
+	return cursors, nil
+}
+
+// encodeSyncToken encodes the cursors of a client into its sync token.
+func encodeSyncToken(cursors map[string]syncCursor) string {
+	raw, _ := json.Marshal(cursors)
+
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
@@ -0,0 +1,130 @@
+package controllers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+const (
+	// defaultUsageDays is the number of days of usage returned without "from".
+	defaultUsageDays = 30
+
+	// maxUsageDays is the largest number of days of usage returned at once.
+	maxUsageDays = 366
+)
+
+// GetUsage returns the daily usage rollups of the users and service accounts:
+// their requests, client and server errors, error rate and bytes transferred.
+//
+// The optional "from" and "to" query parameters (YYYY-MM-DD, UTC, included)
+// select the days, by default the last 30 up to today; "principal" and
+// "account_type" ("user" or "service") limit the rollups. With "format=csv"
+// the rollups are downloaded as a CSV file instead.
+//
+// Returns:
+// - HTTP 400 if a parameter is invalid or the range is longer than 366 days.
+// - HTTP 500 if the rollups cannot be retrieved.
+// - JSON array (or CSV file) of rollups, by day and principal, if successful.
+func (c *Controller) GetUsage(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	from, to, err := usageRange(query.Get("from"), query.Get("to"), time.Now())
+
+	accountType := models.AccountType(query.Get("account_type"))
+	if err == nil && accountType != "" && accountType != models.UserAccountType &&
+		accountType != models.ServiceAccountType {
+		err = errors.New("account_type must be user or service")
+	}
+
+	format := query.Get("format")
+	if err == nil && format != "" && format != "json" && format != "csv" {
+		err = errors.New("format must be json or csv")
+	}
+
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	rollups, err := c.BC.ListUsage(from, to, query.Get("principal"), accountType)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	if format != "csv" {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(rollups)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", `attachment; filename="usage-`+from+"-"+to+`.csv"`)
+
+	writer := csv.NewWriter(w)
+	_ = writer.Write([]string{"day", "principal", "account_type", "requests", "client_errors", "server_errors",
+		"error_rate", "bytes_in", "bytes_out"})
+
+	for _, rollup := range rollups {
+		_ = writer.Write([]string{
+			rollup.Day, rollup.Principal, string(rollup.AccountType),
+			strconv.FormatInt(rollup.Requests, 10),
+			strconv.FormatInt(rollup.ClientErrors, 10),
+			strconv.FormatInt(rollup.ServerErrors, 10),
+			strconv.FormatFloat(rollup.ErrorRate, 'f', 4, 64),
+			strconv.FormatInt(rollup.BytesIn, 10),
+			strconv.FormatInt(rollup.BytesOut, 10),
+		})
+	}
+
+	writer.Flush()
+}
+
+// usageRange parses the first and last days of a usage report, defaulting to
+// the last defaultUsageDays days up to today.
+func usageRange(rawFrom, rawTo string, now time.Time) (string, string, error) {
+	to := now.UTC().Truncate(24 * time.Hour)
+
+	if rawTo != "" {
+		parsed, err := time.Parse(models.UsageDayFormat, rawTo)
+		if err != nil {
+			return "", "", errors.New("to must be a day (YYYY-MM-DD)")
+		}
+
+		to = parsed
+	}
+
+	from := to.AddDate(0, 0, 1-defaultUsageDays)
+
+	if rawFrom != "" {
+		parsed, err := time.Parse(models.UsageDayFormat, rawFrom)
+		if err != nil {
+			return "", "", errors.New("from must be a day (YYYY-MM-DD)")
+		}
+
+		from = parsed
+	}
+
+	if from.After(to) {
+		return "", "", errors.New("from must not be after to")
+	}
+
+	if to.Sub(from) >= maxUsageDays*24*time.Hour {
+		return "", "", errors.New("the range must not be longer than 366 days")
+	}
+
+	return from.Format(models.UsageDayFormat), to.Format(models.UsageDayFormat), nil
+}
@@ -0,0 +1,96 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/r4ulcl/api_template/database"
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+// SearchUsers searches the user directory. The optional query parameters
+// "username" and "email" are patterns where "*" matches any characters (e.g.
+// "al*"), "role" selects a role, and "created_after" and "created_before"
+// (RFC 3339) bound the creation date. Results are ordered by username and
+// paginated with "page" and "per_page" (50 users by default).
+//
+// Returns:
+// - HTTP 400 if a query parameter is invalid.
+// - HTTP 500 if the search fails.
+// - JSON array of models.UserSummary, without the credentials, if successful,
+// with the X-Total-Count and Link headers.
+func (c *Controller) SearchUsers(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	search, page, perPage, err := parseUserSearch(r.URL.Query())
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	users, total, err := c.BC.SearchUsers(search)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	w.Header().Set("Link", paginationLinks(r.URL, page, perPage, total))
+	w.Header().Set("X-Total-Count", strconv.FormatInt(total, 10))
+
+	_ = json.NewEncoder(w).Encode(users)
+}
+
+// parseUserSearch reads the query parameters of GET /user/search.
+//
+// Returns:
+// - The search, the page and the page size.
+// - An error if a parameter is invalid.
+func parseUserSearch(query url.Values) (database.UserSearch, int, int, error) {
+	search := database.UserSearch{
+		Username: query.Get("username"),
+		Email:    query.Get("email"),
+		Role:     models.Role(query.Get("role")),
+	}
+
+	if search.Role != "" && !slices.Contains(search.Role.EnumValues(), string(search.Role)) {
+		return search, 0, 0, errors.New("role must be one of " + strings.Join(search.Role.EnumValues(), ", "))
+	}
+
+	for name, date := range map[string]*time.Time{
+		"created_after":  &search.CreatedAfter,
+		"created_before": &search.CreatedBefore,
+	} {
+		if value := query.Get(name); value != "" {
+			parsed, err := time.Parse(time.RFC3339Nano, value)
+			if err != nil {
+				return search, 0, 0, errors.New(name + " must be an RFC 3339 timestamp")
+			}
+
+			*date = parsed
+		}
+	}
+
+	page, perPage, err := parsePagination(query)
+	if err != nil {
+		return search, 0, 0, err
+	}
+
+	if perPage == 0 {
+		page, perPage = 1, defaultPerPage
+	}
+
+	search.Offset = (page - 1) * perPage
+	search.Limit = perPage
+
+	return search, page, perPage, nil
+}
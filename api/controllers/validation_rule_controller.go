@@ -0,0 +1,163 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/r4ulcl/api_template/database"
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+// ListValidationRules returns every validation rule.
+//
+// Returns:
+// - HTTP 500 if the retrieval fails.
+// - JSON array of rules if successful.
+func (c *Controller) ListValidationRules(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	rules, err := c.BC.ListValidationRules()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(rules)
+}
+
+// CreateValidationRule stores a new validation rule.
+//
+// Returns:
+// - HTTP 400 if the rule is invalid.
+// - HTTP 500 if the rule cannot be stored.
+// - HTTP 201 with the stored rule if successful.
+func (c *Controller) CreateValidationRule(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	rule, ok := decodeValidationRule(w, r)
+	if !ok {
+		return
+	}
+
+	if err := c.BC.CreateValidationRule(&rule); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return
+	}
+
+	c.ReloadValidationRules()
+	c.auditConfig(r, models.ConfigValidationRule, strconv.FormatUint(uint64(rule.ID), 10), nil, rule)
+
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(rule)
+}
+
+// UpdateValidationRule replaces an existing validation rule.
+//
+// Returns:
+// - HTTP 400 if the rule or its ID is invalid.
+// - HTTP 404 if the rule does not exist.
+// - HTTP 500 if the rule cannot be stored.
+// - JSON object of the stored rule if successful.
+func (c *Controller) UpdateValidationRule(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, ok := ruleIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	rule, ok := decodeValidationRule(w, r)
+	if !ok {
+		return
+	}
+
+	before := storedConfig(c.BC.GetValidationRule(id))
+
+	if err := c.BC.UpdateValidationRule(id, &rule); err != nil {
+		writeValidationRuleError(w, err)
+
+		return
+	}
+
+	c.ReloadValidationRules()
+	c.auditConfig(r, models.ConfigValidationRule, strconv.FormatUint(uint64(id), 10), before, rule)
+
+	_ = json.NewEncoder(w).Encode(rule)
+}
+
+// DeleteValidationRule removes a validation rule.
+//
+// Returns:
+// - HTTP 400 if the ID is invalid.
+// - HTTP 404 if the rule does not exist.
+// - HTTP 500 if deletion fails.
+// - JSON confirmation message if successful.
+func (c *Controller) DeleteValidationRule(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, ok := ruleIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	before := storedConfig(c.BC.GetValidationRule(id))
+
+	if err := c.BC.DeleteValidationRule(id); err != nil {
+		writeValidationRuleError(w, err)
+
+		return
+	}
+
+	c.ReloadValidationRules()
+	c.auditConfig(r, models.ConfigValidationRule, strconv.FormatUint(uint64(id), 10), before, nil)
+
+	_ = json.NewEncoder(w).Encode(map[string]string{"message": "Deleted successfully"})
+}
+
+// ReloadValidationRules loads the rules from the database into the rule cache.
+func (c *Controller) ReloadValidationRules() {
+	if c.Validation == nil {
+		return
+	}
+
+	if err := c.Validation.Reload(); err != nil {
+		log.Println("Error loading validation rules:", err)
+	}
+}
+
+// decodeValidationRule decodes and validates a rule, writing a 400 response on failure.
+func decodeValidationRule(w http.ResponseWriter, r *http.Request) (models.ValidationRule, bool) {
+	var rule models.ValidationRule
+
+	err := json.NewDecoder(r.Body).Decode(&rule)
+	if err == nil {
+		err = rule.Validate()
+	}
+
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+		return rule, false
+	}
+
+	return rule, true
+}
+
+// writeValidationRuleError writes a 404 for unknown rules and a 500 for any other error.
+func writeValidationRuleError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	if errors.Is(err, database.ErrValidationRuleNotFound) {
+		status = http.StatusNotFound
+	}
+
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+}
@@ -0,0 +1,451 @@
+package middlewares
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+// APIVersionHeader is the header clients send to request the payloads of an
+// older API version. It is echoed in the responses.
+const APIVersionHeader = "X-API-Version"
+
+// Shim adapts the payloads of a resource for the clients of an older API
+// version, so a model can be refactored without breaking them at once. The
+// requests of old clients are upgraded to the current payload before the
+// handlers see them, and the responses downgraded before they are sent.
+type Shim struct {
+	// Version is the last API version with the old payload: clients requesting
+	// it, or an older one, get the shim.
+	Version int
+
+	// Rename maps the old JSON names of fields to the current ones.
+	Rename map[string]string
+
+	// Defaults holds the values of the current fields old clients don't send,
+	// by current JSON name. They fill the creations (POST and PUT), not the
+	// partial updates.
+	Defaults map[string]interface{}
+
+	// Request upgrades what Rename and Defaults can't, after them. It is optional.
+	Request func(record map[string]interface{})
+
+	// Response downgrades what Rename can't, before it. It is optional.
+	Response func(record map[string]interface{})
+}
+
+// upgrade changes a record sent by an old client to the current payload.
+func (s Shim) upgrade(record map[string]interface{}, create bool) {
+	for old, current := range s.Rename {
+		if value, ok := record[old]; ok {
+			delete(record, old)
+			record[current] = value
+		}
+	}
+
+	if create {
+		for field, value := range s.Defaults {
+			if _, ok := record[field]; !ok {
+				record[field] = value
+			}
+		}
+	}
+
+	if s.Request != nil {
+		s.Request(record)
+	}
+}
+
+// downgrade changes a current record to the payload of an old client.
+func (s Shim) downgrade(record map[string]interface{}) {
+	if s.Response != nil {
+		s.Response(record)
+	}
+
+	for old, current := range s.Rename {
+		if value, ok := record[current]; ok {
+			delete(record, current)
+			record[old] = value
+		}
+	}
+}
+
+// APIVersion is a middleware serving the clients of older API versions. The
+// version is read from the X-API-Version header; without it, the current
+// version is served untouched. The shims of the resource (the first segment of
+// the path) for the requested version and the later ones are applied to the
+// JSON bodies of the requests and of the successful responses: oldest first on
+// requests, newest first on responses. Responses to old versions are flagged
+// with a Deprecation header.
+//
+// Parameters:
+// - current: The current API version.
+// - shims: The shims of each resource.
+//
+// Returns:
+// - A middleware function that answers 400 to unknown versions.
+func APIVersion(current int, shims map[string][]Shim) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			value := r.Header.Get(APIVersionHeader)
+			if value == "" {
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			version, err := strconv.Atoi(value)
+			if err != nil || version < 1 || version > current {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusBadRequest)
+				_ = json.NewEncoder(w).Encode(models.ErrorResponse{
+					Error:     "Unsupported API version " + strconv.Quote(value) + ", the current version is " + strconv.Itoa(current),
+					RequestID: RequestIDFromContext(r.Context()),
+				})
+
+				return
+			}
+
+			w.Header().Set(APIVersionHeader, strconv.Itoa(version))
+
+			if version < current {
+				w.Header().Set("Deprecation", "true")
+			}
+
+			resource, _, _ := strings.Cut(strings.Trim(r.URL.Path, "/"), "/")
+
+			applied := shimsSince(shims[resource], version)
+			if len(applied) == 0 {
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			if err := upgradeRequest(r, applied); err != nil {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusBadRequest)
+				_ = json.NewEncoder(w).Encode(models.ErrorResponse{
+					Error:     "Invalid request body: " + err.Error(),
+					RequestID: RequestIDFromContext(r.Context()),
+				})
+
+				return
+			}
+
+			buffer := &bufferedWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(buffer, r)
+			downgradeResponse(w, buffer, applied)
+		})
+	}
+}
+
+// shimsSince returns the shims applying to a version, oldest first.
+func shimsSince(shims []Shim, version int) []Shim {
+	applied := []Shim{}
+
+	for _, shim := range shims {
+		if shim.Version >= version {
+			applied = append(applied, shim)
+		}
+	}
+
+	sort.SliceStable(applied, func(i, j int) bool { return applied[i].Version < applied[j].Version })
+
+	return applied
+}
+
+// upgradeRequest replaces a JSON body holding a record, or an array of
+// records, with its current payload. Other bodies are left untouched.
+func upgradeRequest(r *http.Request, shims []Shim) error {
+	if r.Body == nil || !isJSON(r.Header.Get("Content-Type")) {
+		return nil
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+
+	if len(bytes.TrimSpace(body)) > 0 {
+		create := r.Method == http.MethodPost || r.Method == http.MethodPut
+
+		rename := func(field string) string {
+			for _, shim := range shims {
+				if current, ok := shim.Rename[field]; ok {
+					field = current
+				}
+			}
+
+			return field
+		}
+
+		body, err = transformRecords(body, rename, func(record map[string]interface{}) {
+			for _, shim := range shims {
+				shim.upgrade(record, create)
+			}
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	r.ContentLength = int64(len(body))
+
+	return nil
+}
+
+// downgradeResponse writes a buffered response, with the records of the
+// successful JSON responses downgraded to the payload of the old version.
+func downgradeResponse(w http.ResponseWriter, buffer *bufferedWriter, shims []Shim) {
+	body := buffer.body.Bytes()
+
+	if buffer.status >= http.StatusOK && buffer.status < http.StatusMultipleChoices &&
+		isJSON(w.Header().Get("Content-Type")) && len(bytes.TrimSpace(body)) > 0 {
+		rename := func(field string) string {
+			for i := len(shims) - 1; i >= 0; i-- {
+				for old, current := range shims[i].Rename {
+					if current == field {
+						field = old
+
+						break
+					}
+				}
+			}
+
+			return field
+		}
+
+		downgraded, err := transformRecords(body, rename, func(record map[string]interface{}) {
+			for i := len(shims) - 1; i >= 0; i-- {
+				shims[i].downgrade(record)
+			}
+		})
+		if err == nil {
+			body = downgraded
+			w.Header().Del("Content-Length")
+		}
+	}
+
+	w.WriteHeader(buffer.status)
+	_, _ = w.Write(body)
+}
+
+// transformRecords applies a function to a JSON object, or to each object of
+// a JSON array, and returns the new encoding. Other values are returned as is.
+//
+// The fields keep their order, renamed ones in place, so the records still
+// follow the order of their struct; the fields added by the function come
+// last, sorted by name. The values left untouched are copied as written.
+//
+// Parameters:
+// - body: The JSON body.
+// - rename: Returns the name a field of the body gets from the function.
+// - transform: Changes a record.
+func transformRecords(body []byte, rename func(field string) string,
+	transform func(record map[string]interface{}),
+) ([]byte, error) {
+	trimmed := bytes.TrimSpace(body)
+
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		encoded, err := transformRecord(trimmed, rename, transform)
+		if err != nil {
+			return nil, err
+		}
+
+		return append(encoded, '\n'), nil
+	case bytes.HasPrefix(trimmed, []byte("[")):
+		var items []json.RawMessage
+		if err := json.Unmarshal(trimmed, &items); err != nil {
+			return nil, err
+		}
+
+		encoded := []byte{'['}
+
+		for i, item := range items {
+			if i > 0 {
+				encoded = append(encoded, ',')
+			}
+
+			if bytes.HasPrefix(item, []byte("{")) {
+				var err error
+				if item, err = transformRecord(item, rename, transform); err != nil {
+					return nil, err
+				}
+			}
+
+			encoded = append(encoded, item...)
+		}
+
+		return append(encoded, ']', '\n'), nil
+	default:
+		return body, nil
+	}
+}
+
+// transformRecord applies a function to a JSON object, keeping the order of
+// its fields (see transformRecords).
+func transformRecord(object []byte, rename func(field string) string,
+	transform func(record map[string]interface{}),
+) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(object))
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+
+	var (
+		order  []string
+		raw    = map[string]json.RawMessage{}
+		record = map[string]interface{}{}
+	)
+
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		field, _ := token.(string)
+
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return nil, err
+		}
+
+		decoded, err := decodeValue(value)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, ok := raw[field]; !ok {
+			order = append(order, field)
+		}
+
+		raw[field] = value
+		record[field] = decoded
+	}
+
+	// The values are compared after the function to copy the untouched ones as
+	// written, so they are decoded again in case it changes them in place
+	original := make(map[string]interface{}, len(record))
+	for _, field := range order {
+		original[rename(field)], _ = decodeValue(raw[field])
+	}
+
+	transform(record)
+
+	fields := make([]string, 0, len(record))
+	written := make(map[string]bool, len(record))
+
+	for _, field := range order {
+		if name := rename(field); !written[name] {
+			if _, ok := record[name]; ok {
+				fields = append(fields, name)
+				written[name] = true
+			}
+		}
+	}
+
+	added := []string{}
+
+	for name := range record {
+		if !written[name] {
+			added = append(added, name)
+		}
+	}
+
+	sort.Strings(added)
+
+	sources := make(map[string]string, len(order))
+	for _, field := range order {
+		sources[rename(field)] = field
+	}
+
+	encoded := []byte{'{'}
+
+	for i, name := range append(fields, added...) {
+		if i > 0 {
+			encoded = append(encoded, ',')
+		}
+
+		key, err := json.Marshal(name)
+		if err != nil {
+			return nil, err
+		}
+
+		encoded = append(append(encoded, key...), ':')
+
+		source, ok := sources[name]
+		if ok && reflect.DeepEqual(record[name], original[name]) {
+			encoded = append(encoded, raw[source]...)
+
+			continue
+		}
+
+		value, err := json.Marshal(record[name])
+		if err != nil {
+			return nil, err
+		}
+
+		encoded = append(encoded, value...)
+	}
+
+	return append(encoded, '}'), nil
+}
+
+// decodeValue decodes a JSON value, keeping the numbers as written, without the
+// rounding of float64.
+func decodeValue(value []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(value))
+	decoder.UseNumber()
+
+	var decoded interface{}
+	err := decoder.Decode(&decoded)
+
+	return decoded, err
+}
+
+// isJSON reports whether a Content-Type is JSON. An empty one is assumed to be.
+func isJSON(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+
+	return err == nil && mediaType == "application/json"
+}
+
+// bufferedWriter keeps a response in memory so it can be rewritten before
+// being sent. The headers are those of the underlying writer.
+type bufferedWriter struct {
+	http.ResponseWriter
+
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+// WriteHeader records the status code, written with the body.
+func (bw *bufferedWriter) WriteHeader(status int) {
+	if !bw.wroteHeader {
+		bw.status = status
+		bw.wroteHeader = true
+	}
+}
+
+// Write appends to the buffered body.
+func (bw *bufferedWriter) Write(b []byte) (int, error) {
+	bw.wroteHeader = true
+
+	return bw.body.Write(b)
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/r4ulcl/api_template/utils"
 	"github.com/r4ulcl/api_template/utils/models"
@@ -19,12 +20,22 @@ const (
 
 	// ContextRole is the key used to store the user's role in the request context.
 	ContextRole ContextKey = "role"
+
+	// ContextAccountType is the key used to store the account type ("user" or "service") in the request context.
+	ContextAccountType ContextKey = "account_type"
+
+	// ContextScopes is the key used to store the scopes of a service account in the request context.
+	ContextScopes ContextKey = "scopes"
+
+	// ContextAuthTime is the key used to store when the principal authenticated in the request context.
+	ContextAuthTime ContextKey = "auth_time"
 )
 
 // AuthMiddleware is a middleware that validates JWT authentication.
 //
 // It extracts the JWT token from the Authorization header, verifies it,
-// and attaches the user ID and role to the request context.
+// and attaches the user ID and role to the request context. Requests already
+// authenticated by ClientCertAuth are passed through.
 //
 // Parameters:
 // - secret: The secret key used for JWT signing.
@@ -34,6 +45,13 @@ const (
 func AuthMiddleware(secret string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Already authenticated with a client certificate
+			if UsernameFromContext(r.Context()) != "" {
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
 			// Extract Authorization header
 			authHeader := r.Header.Get("Authorization")
 			if authHeader == "" {
@@ -55,9 +73,21 @@ func AuthMiddleware(secret string) func(http.Handler) http.Handler {
 			}
 
 			// Attach user ID and role to the request context
-			ctx := context.WithValue(r.Context(), ContextUserID, claims["user_id"])
+			ctx := context.WithValue(r.Context(), ContextUserID, claims["username"])
 			ctx = context.WithValue(ctx, ContextRole, claims["role"])
 
+			// Tokens issued before the auth_time claim have no authentication time
+			if authTime, ok := claims["auth_time"].(float64); ok {
+				ctx = context.WithValue(ctx, ContextAuthTime, time.Unix(int64(authTime), 0))
+			}
+
+			// Service account tokens are limited to their scopes
+			if claims["account_type"] == string(models.ServiceAccountType) {
+				scope, _ := claims["scope"].(string)
+				ctx = context.WithValue(ctx, ContextAccountType, models.ServiceAccountType)
+				ctx = context.WithValue(ctx, ContextScopes, strings.Fields(scope))
+			}
+
 			// Forward request with modified context
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
@@ -89,3 +119,50 @@ func AdminOnly(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r)
 	})
 }
+
+// UsernameFromContext returns the username of the authenticated user stored in
+// the context by AuthMiddleware, or an empty string if there is none.
+func UsernameFromContext(ctx context.Context) string {
+	username, _ := ctx.Value(ContextUserID).(string)
+
+	return username
+}
+
+// RoleFromContext returns the role of the authenticated user stored in the
+// context by AuthMiddleware, or an empty string if there is none.
+func RoleFromContext(ctx context.Context) string {
+	role, _ := ctx.Value(ContextRole).(string)
+
+	return role
+}
+
+// AccountTypeFromContext returns the account type of the authenticated user
+// stored in the context by AuthMiddleware, "user" unless it is a service account.
+func AccountTypeFromContext(ctx context.Context) models.AccountType {
+	if accountType, ok := ctx.Value(ContextAccountType).(models.AccountType); ok {
+		return accountType
+	}
+
+	return models.UserAccountType
+}
+
+// AuthTimeFromContext returns when the authenticated user or service account
+// stored in the context last proved its credentials, and false if unknown.
+func AuthTimeFromContext(ctx context.Context) (time.Time, bool) {
+	authTime, ok := ctx.Value(ContextAuthTime).(time.Time)
+
+	return authTime, ok
+}
+
+// PrincipalFromContext returns the authenticated user or service account
+// stored in the context by AuthMiddleware or ClientCertAuth.
+func PrincipalFromContext(ctx context.Context) models.Principal {
+	scopes, _ := ctx.Value(ContextScopes).([]string)
+
+	return models.Principal{
+		Username:    UsernameFromContext(ctx),
+		Role:        models.Role(RoleFromContext(ctx)),
+		AccountType: AccountTypeFromContext(ctx),
+		Scopes:      scopes,
+	}
+}
@@ -0,0 +1,66 @@
+package middlewares
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/r4ulcl/api_template/utils"
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+// CertificateResolver maps the names of a client certificate to an account.
+type CertificateResolver interface {
+	PrincipalForNames(names []string) (models.Principal, error)
+}
+
+// ClientCertAuth is a middleware that authenticates requests with a verified
+// TLS client certificate, mapping its common name or subject alternative names
+// to a user or service account. It must run before AuthMiddleware, which then
+// skips the bearer token check.
+//
+// Requests without a client certificate are passed through unchanged.
+//
+// Parameters:
+// - resolver: Maps certificate names to accounts. If nil, certificates are ignored.
+//
+// Returns:
+// - A middleware function that processes HTTP requests.
+func ClientCertAuth(resolver CertificateResolver) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if resolver == nil || r.TLS == nil || len(r.TLS.VerifiedChains) == 0 {
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			names := utils.CertificateNames(r.TLS.VerifiedChains[0][0])
+
+			principal, err := resolver.PrincipalForNames(names)
+			if err != nil {
+				log.Printf("Client certificate %v from %s rejected: %v", names, ClientIPFromContext(r.Context()), err)
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusUnauthorized)
+				_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "Client certificate does not match any account"})
+
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), ContextUserID, principal.Username)
+			ctx = context.WithValue(ctx, ContextRole, string(principal.Role))
+
+			// The certificate is proved with every request
+			ctx = context.WithValue(ctx, ContextAuthTime, time.Now())
+
+			if principal.AccountType == models.ServiceAccountType {
+				ctx = context.WithValue(ctx, ContextAccountType, principal.AccountType)
+				ctx = context.WithValue(ctx, ContextScopes, principal.Scopes)
+			}
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
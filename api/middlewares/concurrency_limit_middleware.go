@@ -0,0 +1,216 @@
+package middlewares
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+// Route groups limited by a ConcurrencyLimiter.
+const (
+	// ConcurrencyList is the reads scanning tables: GET /{resource}, its changes and
+	// duplicates, /query, /sync and the user search.
+	ConcurrencyList = "list"
+
+	// ConcurrencyExport is the exports: POST /{resource}/export and /admin/export,
+	// held until the export is written, and the audit log export.
+	ConcurrencyExport = "export"
+
+	// ConcurrencyImport is the bulk imports and the test data generator, held until the job ends.
+	ConcurrencyImport = "import"
+
+	// ConcurrencyReport is the admin reports: the table stats, the schema graph,
+	// the report runs and the audit log verification.
+	ConcurrencyReport = "report"
+)
+
+// concurrencyGroups lists the route groups, in order.
+var concurrencyGroups = []string{ConcurrencyList, ConcurrencyExport, ConcurrencyImport, ConcurrencyReport}
+
+// errConcurrencyLimit is returned by Acquire when the queue of a group is full or the wait timed out.
+var errConcurrencyLimit = errors.New("too many concurrent requests")
+
+// ConcurrencyLimiter limits the requests of each route group in flight, e.g.
+// two exports at a time, to protect the database from concurrent expensive
+// scans. The requests above the limit wait in a bounded queue for a slot, and
+// get 503 when the queue is full or the wait times out.
+type ConcurrencyLimiter struct {
+	// QueueTimeout is how long a request waits in the queue for a slot.
+	QueueTimeout time.Duration
+
+	groups map[string]*concurrencyGroup
+}
+
+// concurrencyGroup holds the slots and the queue of a route group.
+type concurrencyGroup struct {
+	slots chan struct{}
+	queue int
+
+	mu      sync.Mutex
+	waiting int
+}
+
+// NewConcurrencyLimiter creates a concurrency limiter from a comma-separated
+// list of "group=N" and "group=N:Q" entries, where N is the requests of the
+// group in flight and Q the requests waiting for a slot (N by default).
+//
+// Parameters:
+// - spec: The limits, e.g. "export=2:10,list=50".
+// - queueTimeout: How long a request waits for a slot.
+//
+// Returns:
+// - The limiter, or nil if spec is empty.
+// - An error naming the first invalid entry.
+func NewConcurrencyLimiter(spec string, queueTimeout time.Duration) (*ConcurrencyLimiter, error) {
+	groups := map[string]*concurrencyGroup{}
+
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		invalid := fmt.Errorf("invalid concurrency limit %q, expected group=N[:queue] with a group of %s",
+			item, strings.Join(concurrencyGroups, ", "))
+
+		group, value, ok := strings.Cut(item, "=")
+		group = strings.TrimSpace(group)
+
+		if !ok || !slices.Contains(concurrencyGroups, group) {
+			return nil, invalid
+		}
+
+		limitValue, queueValue, hasQueue := strings.Cut(value, ":")
+
+		limit, err := strconv.Atoi(strings.TrimSpace(limitValue))
+		if err != nil || limit < 1 {
+			return nil, invalid
+		}
+
+		queue := limit
+		if hasQueue {
+			if queue, err = strconv.Atoi(strings.TrimSpace(queueValue)); err != nil || queue < 0 {
+				return nil, invalid
+			}
+		}
+
+		groups[group] = &concurrencyGroup{slots: make(chan struct{}, limit), queue: queue}
+	}
+
+	if len(groups) == 0 {
+		return nil, nil
+	}
+
+	return &ConcurrencyLimiter{QueueTimeout: queueTimeout, groups: groups}, nil
+}
+
+// Limit limits the requests of a route to the slots of its group. It must
+// run after AuthMiddleware, so anonymous requests don't take slots. A nil
+// limiter, or a group without limit, lets every request through.
+func (l *ConcurrencyLimiter) Limit(group string, next http.Handler) http.Handler {
+	if l == nil || l.groups[group] == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		release, ok := l.Check(w, r, group)
+		if !ok {
+			return
+		}
+
+		defer release()
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Check takes a slot of a group for a request, waiting in the queue if needed,
+// for handlers holding the slot after answering (e.g. until a background job
+// ends). A nil limiter, or a group without limit, accepts every request.
+//
+// Returns:
+// - The function releasing the slot, to call once, and true.
+// - false, after writing HTTP 503 with a Retry-After header, if the queue is
+// full or the wait timed out.
+func (l *ConcurrencyLimiter) Check(w http.ResponseWriter, r *http.Request, group string) (func(), bool) {
+	release, err := l.Acquire(r.Context(), group)
+	if err == nil {
+		return release, true
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Retry-After", retryAfterSeconds)
+	w.WriteHeader(http.StatusServiceUnavailable)
+	_ = json.NewEncoder(w).Encode(models.ErrorResponse{
+		Error:     fmt.Sprintf("Too many concurrent %s requests, please retry later", group),
+		RequestID: RequestIDFromContext(r.Context()),
+	})
+
+	return nil, false
+}
+
+// Acquire takes a slot of a group, waiting in the queue until one is free,
+// the queue timeout expires or the context is canceled.
+//
+// Returns:
+// - The function releasing the slot, to call once.
+// - An error if the queue is full, or the wait timed out or was canceled.
+func (l *ConcurrencyLimiter) Acquire(ctx context.Context, group string) (func(), error) {
+	if l == nil || l.groups[group] == nil {
+		return func() {}, nil
+	}
+
+	g := l.groups[group]
+
+	select {
+	case g.slots <- struct{}{}:
+		return g.release(), nil
+	default:
+	}
+
+	g.mu.Lock()
+	if g.waiting >= g.queue {
+		g.mu.Unlock()
+
+		return nil, errConcurrencyLimit
+	}
+
+	g.waiting++
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		g.waiting--
+		g.mu.Unlock()
+	}()
+
+	timer := time.NewTimer(l.QueueTimeout)
+	defer timer.Stop()
+
+	select {
+	case g.slots <- struct{}{}:
+		return g.release(), nil
+	case <-timer.C:
+		return nil, errConcurrencyLimit
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// release returns the function releasing a slot taken, at most once.
+func (g *concurrencyGroup) release() func() {
+	var once sync.Once
+
+	return func() {
+		once.Do(func() { <-g.slots })
+	}
+}
@@ -0,0 +1,37 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// ContextUnmodifiedSince is the key used to store the If-Unmodified-Since date of a write.
+const ContextUnmodifiedSince ContextKey = "unmodified_since"
+
+// IfUnmodifiedSince is a middleware that stores the If-Unmodified-Since date of
+// PUT, PATCH and DELETE requests in the request context, so the handlers can
+// answer 412 Precondition Failed when the record changed after that date.
+//
+// Invalid dates are ignored, as required by RFC 9110.
+func IfUnmodifiedSince(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get("If-Unmodified-Since")
+
+		if header != "" && (r.Method == http.MethodPut || r.Method == http.MethodPatch || r.Method == http.MethodDelete) {
+			if since, err := http.ParseTime(header); err == nil {
+				r = r.WithContext(context.WithValue(r.Context(), ContextUnmodifiedSince, since))
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// UnmodifiedSinceFromContext returns the If-Unmodified-Since date stored by
+// IfUnmodifiedSince, and whether the request has one.
+func UnmodifiedSinceFromContext(ctx context.Context) (time.Time, bool) {
+	since, ok := ctx.Value(ContextUnmodifiedSince).(time.Time)
+
+	return since, ok
+}
@@ -0,0 +1,44 @@
+package middlewares
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+// retryAfterSeconds is the value of the Retry-After header sent while the database is down.
+const retryAfterSeconds = "5"
+
+// AvailabilityChecker reports whether a dependency is currently available.
+type AvailabilityChecker interface {
+	Available() bool
+}
+
+// DBAvailable is a middleware that fails fast with 503 while the database
+// circuit breaker is open, instead of letting requests hang until timeout.
+//
+// Parameters:
+// - checker: The database health checker. If nil, requests are always forwarded.
+//
+// Returns:
+// - A middleware function that processes HTTP requests.
+func DBAvailable(checker AvailabilityChecker) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if checker != nil && !checker.Available() {
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("Retry-After", retryAfterSeconds)
+				w.WriteHeader(http.StatusServiceUnavailable)
+				_ = json.NewEncoder(w).Encode(models.ErrorResponse{
+					Error:     "Database unavailable, please retry later",
+					RequestID: RequestIDFromContext(r.Context()),
+				})
+
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package middlewares
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+// ContextFlags is the key used to store the feature flag checker in the request context.
+const ContextFlags ContextKey = "feature_flags"
+
+// FlagChecker reports whether a feature flag is on for a user.
+type FlagChecker interface {
+	FlagEnabled(name, username, role string) bool
+}
+
+// FeatureFlags is a middleware that stores the flag checker in the request
+// context, so handlers can call FlagEnabled. It must run after AuthMiddleware.
+//
+// Parameters:
+// - checker: The feature flag checker.
+//
+// Returns:
+// - A middleware function that processes HTTP requests.
+func FeatureFlags(checker FlagChecker) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), ContextFlags, checker)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// FlagEnabled reports whether a feature flag is on for the authenticated user.
+// It returns false if the FeatureFlags middleware did not run.
+func FlagEnabled(ctx context.Context, name string) bool {
+	checker, ok := ctx.Value(ContextFlags).(FlagChecker)
+	if !ok || checker == nil {
+		return false
+	}
+
+	return checker.FlagEnabled(name, UsernameFromContext(ctx), RoleFromContext(ctx))
+}
+
+// RequireFlag is a middleware that answers 404 unless the feature flag is on
+// for the authenticated user, hiding gated routes from everyone else.
+//
+// Parameters:
+// - name: The name of the feature flag.
+//
+// Returns:
+// - A middleware function that processes HTTP requests.
+func RequireFlag(name string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !FlagEnabled(r.Context(), name) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusNotFound)
+				_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "Not found"})
+
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
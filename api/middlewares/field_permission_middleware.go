@@ -0,0 +1,67 @@
+package middlewares
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/r4ulcl/api_template/utils"
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+// ContextWritableFields is the key used to store the fields a write may change.
+const ContextWritableFields ContextKey = "writable_fields"
+
+// WritableFields is a middleware for the update routes opened to roles other
+// than admin. It stores the fields the role of the user may write in the
+// request context, so the handlers reject the changes to any other field, and
+// answers 403 to the roles without fields. Admins write every field, and so
+// do the roles a policy grants the write to, as on the admin routes.
+// It must run after AuthMiddleware.
+//
+// Parameters:
+// - roles: The JSON names of the fields each role may write.
+// - policy: The authorization policy of the admin routes. It is optional.
+// - loadRecord: Loads the record of the request for the policy. It is optional.
+func WritableFields(roles map[models.Role][]string, policy utils.Policy,
+	loadRecord RecordLoader,
+) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			role := RoleFromContext(r.Context())
+			if role == string(models.AdminRole) {
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			// A policy that can't decide leaves the role to its fields
+			if policy != nil {
+				if allowed, _ := policyAllows(r, policy, loadRecord); allowed {
+					next.ServeHTTP(w, r)
+
+					return
+				}
+			}
+
+			fields, ok := roles[models.Role(role)]
+			if !ok {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusForbidden)
+				_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "Forbidden: Admins only"})
+
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), ContextWritableFields, fields)))
+		})
+	}
+}
+
+// WritableFieldsFromContext returns the fields stored by WritableFields, and
+// whether the request is restricted to them.
+func WritableFieldsFromContext(ctx context.Context) ([]string, bool) {
+	fields, ok := ctx.Value(ContextWritableFields).([]string)
+
+	return fields, ok
+}
@@ -0,0 +1,82 @@
+package middlewares
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/netip"
+
+	"github.com/r4ulcl/api_template/utils"
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+// IPFilter restricts requests to client IPs in the allowlist and outside the denylist.
+type IPFilter struct {
+	// Allow lists the ranges allowed to connect. If empty, every address not denied is allowed.
+	Allow []netip.Prefix
+
+	// Deny lists the ranges rejected even if they are in the allowlist.
+	Deny []netip.Prefix
+}
+
+// NewIPFilter creates an IP filter from comma-separated CIDR lists.
+//
+// Parameters:
+// - allow: The allowed ranges; empty allows every address not denied.
+// - deny: The denied ranges.
+//
+// Returns:
+// - The filter, or nil if both allow and deny are empty.
+// - An error if a range is invalid.
+func NewIPFilter(allow, deny string) (*IPFilter, error) {
+	filter := &IPFilter{}
+
+	var err error
+
+	if filter.Allow, err = utils.ParseCIDRs(allow); err != nil {
+		return nil, err
+	}
+
+	if filter.Deny, err = utils.ParseCIDRs(deny); err != nil {
+		return nil, err
+	}
+
+	if len(filter.Allow) == 0 && len(filter.Deny) == 0 {
+		return nil, nil
+	}
+
+	return filter, nil
+}
+
+// Allowed reports whether an address may connect. The denylist wins over the allowlist.
+func (f *IPFilter) Allowed(addr netip.Addr) bool {
+	if utils.ContainsIP(f.Deny, addr) {
+		return false
+	}
+
+	return len(f.Allow) == 0 || utils.ContainsIP(f.Allow, addr)
+}
+
+// Middleware rejects requests from client IPs that are not allowed with 403.
+// The client IP is the one found by RealIP, which must run before. A nil
+// filter allows every request.
+func (f *IPFilter) Middleware(next http.Handler) http.Handler {
+	if f == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		addr, ok := clientAddr(r)
+		if !ok || !f.Allowed(addr) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusForbidden)
+			_ = json.NewEncoder(w).Encode(models.ErrorResponse{
+				Error:     "Forbidden: IP address not allowed",
+				RequestID: RequestIDFromContext(r.Context()),
+			})
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,48 @@
+package middlewares
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+
+	"github.com/r4ulcl/api_template/utils"
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+// ContextIsolation is the key used to store the isolation level asked by the request.
+const ContextIsolation ContextKey = "isolation"
+
+// IsolationHint is a middleware reading the X-Isolation-Level header (e.g.
+// "serializable"), the isolation level a client asks for the transactions of
+// its request. The handlers only use it when it is stricter than the level of
+// the resource (see database.TxPolicy). Invalid levels are answered with 400.
+func IsolationHint(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get("X-Isolation-Level")
+		if header == "" {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		level, err := utils.ParseIsolationLevel(header)
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: err.Error()})
+
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), ContextIsolation, level)))
+	})
+}
+
+// IsolationFromContext returns the isolation level stored by IsolationHint, or
+// sql.LevelDefault if the request asked for none.
+func IsolationFromContext(ctx context.Context) sql.IsolationLevel {
+	level, _ := ctx.Value(ContextIsolation).(sql.IsolationLevel)
+
+	return level
+}
@@ -0,0 +1,187 @@
+package middlewares
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/r4ulcl/api_template/utils"
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+const (
+	// minLatencySamples is the number of requests needed before a route can be reported as slow.
+	minLatencySamples = 20
+
+	// slowRouteAlertCooldown is the minimum time between two alerts for the same route.
+	slowRouteAlertCooldown = 10 * time.Minute
+)
+
+// routeLatency holds the recent latencies of a single route.
+type routeLatency struct {
+	method    string
+	route     string
+	samples   []time.Duration
+	next      int
+	p95       time.Duration
+	slowSince time.Time
+	lastAlert time.Time
+}
+
+// SlowRouteTracker tracks the rolling p95 latency of every route and reports
+// the routes exceeding a configured threshold.
+type SlowRouteTracker struct {
+	threshold  time.Duration
+	window     int
+	webhookURL string
+
+	mu     sync.Mutex
+	routes map[string]*routeLatency
+}
+
+// NewSlowRouteTracker creates a tracker.
+//
+// Parameters:
+// - thresholdMs: The p95 latency in milliseconds above which a route is slow.
+// - window: The number of recent requests per route used to compute the p95.
+// - webhookURL: Optional webhook notified when a route becomes slow.
+func NewSlowRouteTracker(thresholdMs, window int, webhookURL string) *SlowRouteTracker {
+	if window < minLatencySamples {
+		window = minLatencySamples
+	}
+
+	return &SlowRouteTracker{
+		threshold:  time.Duration(thresholdMs) * time.Millisecond,
+		window:     window,
+		webhookURL: webhookURL,
+		routes:     make(map[string]*routeLatency),
+	}
+}
+
+// Middleware measures the latency of each request and records it against the
+// matched route template.
+func (t *SlowRouteTracker) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		next.ServeHTTP(w, r)
+
+		route := r.URL.Path
+		if current := mux.CurrentRoute(r); current != nil {
+			if template, err := current.GetPathTemplate(); err == nil {
+				route = template
+			}
+		}
+
+		t.Observe(r.Method, route, time.Since(start))
+	})
+}
+
+// Observe records the latency of a request and emits an alert when the route's
+// p95 latency goes above the threshold.
+func (t *SlowRouteTracker) Observe(method, route string, duration time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := method + " " + route
+
+	stats, ok := t.routes[key]
+	if !ok {
+		stats = &routeLatency{method: method, route: route}
+		t.routes[key] = stats
+	}
+
+	// Ring buffer of the last `window` samples
+	if len(stats.samples) < t.window {
+		stats.samples = append(stats.samples, duration)
+	} else {
+		stats.samples[stats.next] = duration
+		stats.next = (stats.next + 1) % t.window
+	}
+
+	if len(stats.samples) < minLatencySamples {
+		return
+	}
+
+	stats.p95 = percentile(stats.samples, 0.95)
+
+	if stats.p95 <= t.threshold {
+		stats.slowSince = time.Time{}
+
+		return
+	}
+
+	now := time.Now()
+	if stats.slowSince.IsZero() {
+		stats.slowSince = now
+	}
+
+	if now.Sub(stats.lastAlert) >= slowRouteAlertCooldown {
+		stats.lastAlert = now
+		t.alert(t.toModel(stats))
+	}
+}
+
+// SlowRoutes returns the routes whose current p95 latency exceeds the threshold.
+func (t *SlowRouteTracker) SlowRoutes() []models.SlowRoute {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	slow := []models.SlowRoute{}
+
+	for _, stats := range t.routes {
+		if !stats.slowSince.IsZero() {
+			slow = append(slow, t.toModel(stats))
+		}
+	}
+
+	sort.Slice(slow, func(i, j int) bool { return slow[i].P95Ms > slow[j].P95Ms })
+
+	return slow
+}
+
+// toModel converts the internal route statistics into the API representation.
+func (t *SlowRouteTracker) toModel(stats *routeLatency) models.SlowRoute {
+	return models.SlowRoute{
+		Route:       stats.route,
+		Method:      stats.method,
+		P95Ms:       float64(stats.p95.Microseconds()) / 1000,
+		ThresholdMs: int(t.threshold.Milliseconds()),
+		Samples:     len(stats.samples),
+		SlowSince:   stats.slowSince,
+	}
+}
+
+// alert logs a structured warning and notifies the webhook, if configured.
+func (t *SlowRouteTracker) alert(slow models.SlowRoute) {
+	log.Printf("WARN slow_route method=%s route=%s p95_ms=%.1f threshold_ms=%d samples=%d",
+		slow.Method, slow.Route, slow.P95Ms, slow.ThresholdMs, slow.Samples)
+
+	if t.webhookURL == "" {
+		return
+	}
+
+	go func() {
+		text := fmt.Sprintf("Slow route %s %s: p95 %.1f ms (threshold %d ms)",
+			slow.Method, slow.Route, slow.P95Ms, slow.ThresholdMs)
+		if err := utils.SendWebhookAlert(t.webhookURL, text, slow); err != nil {
+			log.Println("Error sending slow route alert:", err)
+		}
+	}()
+}
+
+// percentile returns the p-th percentile (0 < p <= 1) of the given durations.
+func percentile(samples []time.Duration, p float64) time.Duration {
+	sorted := slices.Clone(samples)
+	slices.Sort(sorted)
+
+	index := int(float64(len(sorted))*p+0.5) - 1
+	index = max(0, min(index, len(sorted)-1))
+
+	return sorted[index]
+}
@@ -0,0 +1,30 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+)
+
+// ContextPublishedOnly is the key used to flag the reads restricted to published records.
+const ContextPublishedOnly ContextKey = "published_only"
+
+// PublishedOnly is a middleware for the read routes of resources with a
+// draft → published → archived lifecycle. It flags the requests of users other
+// than admins, so the handlers only return published records to them.
+// It must run after AuthMiddleware.
+func PublishedOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if RoleFromContext(r.Context()) != "admin" {
+			r = r.WithContext(context.WithValue(r.Context(), ContextPublishedOnly, true))
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// PublishedOnlyFromContext reports whether the request may only read published records.
+func PublishedOnlyFromContext(ctx context.Context) bool {
+	publishedOnly, _ := ctx.Value(ContextPublishedOnly).(bool)
+
+	return publishedOnly
+}
@@ -0,0 +1,219 @@
+package middlewares
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/r4ulcl/api_template/utils"
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+// ChallengeTokenHeader is the header of the challenge token solved by the client.
+const ChallengeTokenHeader = "X-Challenge-Token"
+
+// maxThrottledClients is the number of clients tracked above which the expired ones are removed.
+const maxThrottledClients = 10000
+
+// loginFailures holds the failed logins of a client within the window.
+type loginFailures struct {
+	count int
+	start time.Time
+}
+
+// LoginThrottle counts the failed logins per client IP, apart from the API
+// rate limit, to resist credential stuffing: after ChallengeAfter failures
+// within the window, the next logins need a solved challenge (CAPTCHA), and
+// after BlockAfter failures they are rejected until the window ends. A
+// successful login clears the failures of the client. The counts are kept in
+// memory, per replica.
+type LoginThrottle struct {
+	// Challenge verifies the challenge tokens. Without it, the logins are only blocked.
+	Challenge utils.Challenge
+
+	// SiteKey is the public key of the site at the provider, told to the clients to render the challenge.
+	SiteKey string
+
+	// ChallengeAfter is the number of failures from which a challenge is required. 0 disables the challenges.
+	ChallengeAfter int
+
+	// BlockAfter is the number of failures from which the logins are rejected. 0 disables the blocking.
+	BlockAfter int
+
+	// Window is how long the failures of a client are counted, from the first one.
+	Window time.Duration
+
+	mu       sync.Mutex
+	failures map[string]*loginFailures
+}
+
+// NewLoginThrottle creates a login throttle.
+//
+// Returns:
+// - The throttle, or nil if both the challenges and the blocking are disabled.
+func NewLoginThrottle(challenge utils.Challenge, siteKey string, challengeAfter, blockAfter int,
+	window time.Duration,
+) *LoginThrottle {
+	if challenge == nil {
+		challengeAfter = 0
+	}
+
+	if challengeAfter <= 0 && blockAfter <= 0 {
+		return nil
+	}
+
+	return &LoginThrottle{
+		Challenge:      challenge,
+		SiteKey:        siteKey,
+		ChallengeAfter: challengeAfter,
+		BlockAfter:     blockAfter,
+		Window:         window,
+		failures:       map[string]*loginFailures{},
+	}
+}
+
+// Middleware throttles a login route. The route must answer 401 to the
+// failed logins and 2xx to the successful ones. A nil throttle lets every login through.
+//
+// Returns:
+// - HTTP 429 with Retry-After if the client is blocked.
+// - HTTP 401 with models.ChallengeResponse if a challenge is required and the token is missing or invalid.
+// - HTTP 503 if the challenge provider cannot be reached.
+func (t *LoginThrottle) Middleware(next http.Handler) http.Handler {
+	if t == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		client := ""
+		if addr, ok := clientAddr(r); ok {
+			client = addr.String()
+		}
+
+		failures, retryAfter := t.current(client, time.Now())
+
+		if t.BlockAfter > 0 && failures >= t.BlockAfter {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Seconds())+1))
+			w.WriteHeader(http.StatusTooManyRequests)
+			_ = json.NewEncoder(w).Encode(models.ErrorResponse{
+				Error:     "Too many failed logins, please retry later",
+				RequestID: RequestIDFromContext(r.Context()),
+			})
+
+			return
+		}
+
+		if t.ChallengeAfter > 0 && failures >= t.ChallengeAfter {
+			if !t.verifyChallenge(w, r, client) {
+				return
+			}
+		}
+
+		recorder := newStatusRecorder(w)
+
+		next.ServeHTTP(recorder, r)
+
+		switch {
+		case recorder.status == http.StatusUnauthorized:
+			t.fail(client, time.Now())
+		case recorder.status >= 200 && recorder.status <= 299:
+			t.clear(client)
+		}
+	})
+}
+
+// verifyChallenge checks the challenge token of a login, writing the error
+// response if it is missing or invalid. An invalid token counts as a failure.
+func (t *LoginThrottle) verifyChallenge(w http.ResponseWriter, r *http.Request, client string) bool {
+	response := models.ChallengeResponse{
+		Error:     "Challenge required",
+		Provider:  t.Challenge.Provider(),
+		SiteKey:   t.SiteKey,
+		Header:    ChallengeTokenHeader,
+		RequestID: RequestIDFromContext(r.Context()),
+	}
+
+	token := r.Header.Get(ChallengeTokenHeader)
+	if token != "" {
+		err := t.Challenge.Verify(r.Context(), token, client)
+		if err == nil {
+			return true
+		}
+
+		if !errors.Is(err, utils.ErrChallengeFailed) {
+			log.Println("Error verifying a login challenge:", err)
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_ = json.NewEncoder(w).Encode(models.ErrorResponse{
+				Error:     "The challenge cannot be verified, please retry later",
+				RequestID: response.RequestID,
+			})
+
+			return false
+		}
+
+		response.Error = "Invalid challenge"
+		t.fail(client, time.Now())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnauthorized)
+	_ = json.NewEncoder(w).Encode(response)
+
+	return false
+}
+
+// current returns the failures of a client within the window, and how long until the window ends.
+func (t *LoginThrottle) current(client string, now time.Time) (int, time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	entry, ok := t.failures[client]
+	if !ok {
+		return 0, 0
+	}
+
+	if now.Sub(entry.start) >= t.Window {
+		delete(t.failures, client)
+
+		return 0, 0
+	}
+
+	return entry.count, t.Window - now.Sub(entry.start)
+}
+
+// fail counts a failed login of a client.
+func (t *LoginThrottle) fail(client string, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.failures) >= maxThrottledClients {
+		for key, entry := range t.failures {
+			if now.Sub(entry.start) >= t.Window {
+				delete(t.failures, key)
+			}
+		}
+	}
+
+	entry, ok := t.failures[client]
+	if !ok || now.Sub(entry.start) >= t.Window {
+		entry = &loginFailures{start: now}
+		t.failures[client] = entry
+	}
+
+	entry.count++
+}
+
+// clear forgets the failures of a client.
+func (t *LoginThrottle) clear(client string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.failures, client)
+}
@@ -0,0 +1,78 @@
+package middlewares
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/r4ulcl/api_template/utils"
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+// RecordLoader returns the tokenized ID and the stored record of a
+// /{resource}/{id} request, or an empty ID for the other routes.
+type RecordLoader func(r *http.Request, resource string) (string, interface{})
+
+// Authorize is a middleware that delegates the authorization of the requests
+// to a policy (an OPA server or a policy file), in place of AdminOnly. The
+// policy gets the account, the resource, the method and, for /{resource}/{id}
+// routes, the stored record. It must run after AuthMiddleware.
+//
+// Requests the policy can't decide on (e.g. OPA is down) are denied with 503.
+//
+// Parameters:
+// - policy: The policy deciding on the requests.
+// - loadRecord: Loads the record of the request. It is optional.
+func Authorize(policy utils.Policy, loadRecord RecordLoader) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			allowed, err := policyAllows(r, policy, loadRecord)
+			if !allowed {
+				status, message := http.StatusForbidden, "Forbidden: denied by the authorization policy"
+				if err != nil {
+					status, message = http.StatusServiceUnavailable, "Authorization policy unavailable"
+				}
+
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(status)
+				_ = json.NewEncoder(w).Encode(models.ErrorResponse{
+					Error:     message,
+					RequestID: RequestIDFromContext(r.Context()),
+				})
+
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// policyAllows asks a policy whether it allows a request. Errors are logged,
+// and the request is not allowed.
+func policyAllows(r *http.Request, policy utils.Policy, loadRecord RecordLoader) (bool, error) {
+	principal := PrincipalFromContext(r.Context())
+	resource, _ := scopeTarget(r)
+
+	input := models.PolicyInput{
+		Username:    principal.Username,
+		Role:        principal.Role,
+		AccountType: principal.AccountType,
+		Resource:    resource,
+		Method:      r.Method,
+		Path:        r.URL.Path,
+	}
+
+	if loadRecord != nil {
+		input.RecordID, input.Record = loadRecord(r, resource)
+	}
+
+	allowed, err := policy.Allow(r.Context(), input)
+	if err != nil {
+		log.Println("Error evaluating the authorization policy:", err)
+
+		return false, err
+	}
+
+	return allowed, nil
+}
@@ -0,0 +1,97 @@
+package middlewares
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+// Pretty is a middleware indenting the JSON responses of the requests with
+// "?pretty=true", for humans reading them with curl. The fields keep their
+// order, the one of the structs of the models. Other responses (e.g. exports
+// and event streams) are sent untouched, without buffering.
+func Pretty(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if pretty, _ := strconv.ParseBool(r.URL.Query().Get("pretty")); !pretty {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		pw := &prettyWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(pw, r)
+		pw.finish()
+	})
+}
+
+// prettyWriter buffers the JSON responses to indent them, and passes the
+// others through to the underlying writer.
+type prettyWriter struct {
+	http.ResponseWriter
+
+	status      int
+	wroteHeader bool
+	buffering   bool
+	body        bytes.Buffer
+}
+
+// WriteHeader decides from the Content-Type whether the response is buffered.
+func (pw *prettyWriter) WriteHeader(status int) {
+	if pw.wroteHeader {
+		return
+	}
+
+	pw.status = status
+	pw.wroteHeader = true
+	pw.buffering = isJSON(pw.Header().Get("Content-Type"))
+
+	if !pw.buffering {
+		pw.ResponseWriter.WriteHeader(status)
+	}
+}
+
+// Write buffers the JSON responses and writes the others.
+func (pw *prettyWriter) Write(b []byte) (int, error) {
+	pw.WriteHeader(http.StatusOK)
+
+	if pw.buffering {
+		return pw.body.Write(b)
+	}
+
+	return pw.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher for the responses that are not buffered.
+func (pw *prettyWriter) Flush() {
+	if pw.buffering {
+		return
+	}
+
+	if flusher, ok := pw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap returns the underlying writer, for use by http.ResponseController.
+func (pw *prettyWriter) Unwrap() http.ResponseWriter {
+	return pw.ResponseWriter
+}
+
+// finish writes the buffered response, indented if it is valid JSON.
+func (pw *prettyWriter) finish() {
+	if !pw.buffering {
+		return
+	}
+
+	body := pw.body.Bytes()
+
+	var indented bytes.Buffer
+	if len(bytes.TrimSpace(body)) > 0 && json.Indent(&indented, body, "", "  ") == nil {
+		body = indented.Bytes()
+		pw.Header().Del("Content-Length")
+	}
+
+	pw.ResponseWriter.WriteHeader(pw.status)
+	_, _ = pw.ResponseWriter.Write(body)
+}
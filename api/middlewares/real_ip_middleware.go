@@ -0,0 +1,105 @@
+package middlewares
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/r4ulcl/api_template/utils"
+)
+
+// ContextClientIP is the key used to store the real client IP in the request context.
+const ContextClientIP ContextKey = "client_ip"
+
+// RealIP is a middleware that derives the real client IP and stores it in the
+// request context, for logging, rate limiting, IP filtering and audit entries.
+//
+// X-Forwarded-For and X-Real-IP are only used when the peer is a trusted proxy,
+// since any client can set them.
+//
+// Parameters:
+// - trustedProxies: The proxies whose forwarding headers are trusted.
+//
+// Returns:
+// - A middleware function that processes HTTP requests.
+func RealIP(trustedProxies []netip.Prefix) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if addr, ok := realClientAddr(r, trustedProxies); ok {
+				r = r.WithContext(context.WithValue(r.Context(), ContextClientIP, addr))
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ClientIPFromContext returns the client IP stored in the context by RealIP,
+// or an empty string if there is none.
+func ClientIPFromContext(ctx context.Context) string {
+	if addr, ok := ctx.Value(ContextClientIP).(netip.Addr); ok {
+		return addr.String()
+	}
+
+	return ""
+}
+
+// clientAddr returns the client IP of a request: the one stored by RealIP, or
+// the peer address if the middleware did not run.
+func clientAddr(r *http.Request) (netip.Addr, bool) {
+	if addr, ok := r.Context().Value(ContextClientIP).(netip.Addr); ok {
+		return addr, true
+	}
+
+	return realClientAddr(r, nil)
+}
+
+// realClientAddr returns the IP address of the client.
+//
+// If the peer is a trusted proxy, X-Forwarded-For is read from right to left
+// and the first address that is not a trusted proxy is the client. Addresses
+// added by untrusted hops can be forged, so they are never used. Without
+// X-Forwarded-For, X-Real-IP is used.
+func realClientAddr(r *http.Request, trustedProxies []netip.Prefix) (netip.Addr, bool) {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+
+	addr = addr.Unmap()
+	if !utils.ContainsIP(trustedProxies, addr) {
+		return addr, true
+	}
+
+	forwarded := r.Header.Values("X-Forwarded-For")
+	if len(forwarded) == 0 {
+		if realIP, err := netip.ParseAddr(strings.TrimSpace(r.Header.Get("X-Real-IP"))); err == nil {
+			return realIP.Unmap(), true
+		}
+
+		return addr, true
+	}
+
+	hops := strings.Split(strings.Join(forwarded, ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			// A malformed entry can't be trusted, so stop at the last known hop
+			return addr, true
+		}
+
+		addr = hop.Unmap()
+		if !utils.ContainsIP(trustedProxies, addr) {
+			return addr, true
+		}
+	}
+
+	return addr, true
+}
@@ -0,0 +1,95 @@
+package middlewares
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+// ErrorReporter receives panics and server errors together with the request
+// that caused them, e.g. to forward them to an error tracker like Sentry.
+type ErrorReporter interface {
+	// ReportPanic is called with the value recovered from a panicking handler.
+	ReportPanic(r *http.Request, recovered interface{})
+
+	// ReportError is called when a handler answers with a 5xx status.
+	ReportError(r *http.Request, status int, body string)
+}
+
+// Recover is a middleware that recovers from panics in the next handlers.
+//
+// The panic is logged with its stack trace and request ID and forwarded to the
+// reporter (if any), and the client receives a JSON 500 response including the
+// request ID instead of a dropped connection. Responses with
+// a 5xx status are also forwarded to the reporter.
+//
+// Parameters:
+// - reporter: Optional error reporter. It may be nil.
+//
+// Returns:
+// - A middleware function that processes HTTP requests.
+func Recover(reporter ErrorReporter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			recorder := newStatusRecorder(w)
+
+			defer func() {
+				recovered := recover()
+				if recovered == nil {
+					if reporter != nil && recorder.status >= http.StatusInternalServerError {
+						reporter.ReportError(r, recorder.status, string(recorder.errorBody))
+					}
+
+					return
+				}
+
+				// http.ErrAbortHandler is used to abort a response on purpose
+				if recovered == http.ErrAbortHandler {
+					panic(recovered)
+				}
+
+				requestID := RequestIDFromContext(r.Context())
+				log.Printf("Panic serving %s %s (request_id=%s client_ip=%s): %v\n%s",
+					r.Method, r.URL.Path, requestID, ClientIPFromContext(r.Context()), recovered, debug.Stack())
+
+				if reporter != nil {
+					reporter.ReportPanic(r, recovered)
+				}
+
+				// Headers already sent, nothing else can be written safely
+				if recorder.wroteHeader {
+					return
+				}
+
+				recorder.Header().Set("Content-Type", "application/json")
+				recorder.WriteHeader(http.StatusInternalServerError)
+				_ = json.NewEncoder(recorder).Encode(models.ErrorResponse{
+					Error:     "Internal server error",
+					RequestID: requestID,
+				})
+			}()
+
+			next.ServeHTTP(recorder, r)
+		})
+	}
+}
+
+// Reporters combines several error reporters into one.
+type Reporters []ErrorReporter
+
+// ReportPanic forwards the panic to every reporter.
+func (rs Reporters) ReportPanic(r *http.Request, recovered interface{}) {
+	for _, reporter := range rs {
+		reporter.ReportPanic(r, recovered)
+	}
+}
+
+// ReportError forwards the error to every reporter.
+func (rs Reporters) ReportError(r *http.Request, status int, body string) {
+	for _, reporter := range rs {
+		reporter.ReportError(r, status, body)
+	}
+}
@@ -0,0 +1,71 @@
+package middlewares
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+// RequestIDHeader is the header used to receive and return the request ID.
+const RequestIDHeader = "X-Request-ID"
+
+// ContextRequestID is the key used to store the request ID in the request context.
+const ContextRequestID ContextKey = "request_id"
+
+// maxRequestIDLength is the maximum length accepted for a client-supplied request ID.
+const maxRequestIDLength = 128
+
+// RequestID is a middleware that assigns an ID to every request.
+//
+// The ID supplied by the client in the X-Request-ID header is reused when it is
+// valid (see validRequestID), otherwise a random one is generated, so the logs
+// and the responses never carry arbitrary client input. The ID is stored in the request context
+// and returned in the X-Request-ID response header.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = newRequestID()
+		}
+
+		w.Header().Set(RequestIDHeader, requestID)
+
+		ctx := context.WithValue(r.Context(), ContextRequestID, requestID)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// RequestIDFromContext returns the request ID stored in the context by the
+// RequestID middleware, or an empty string if there is none.
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(ContextRequestID).(string)
+
+	return requestID
+}
+
+// validRequestID reports whether a client-supplied request ID can be reused: 1
+// to maxRequestIDLength letters, digits, dots, underscores or dashes.
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+
+	for _, c := range []byte(requestID) {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '.', c == '_', c == '-':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// newRequestID generates a random 16-byte hex-encoded ID.
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
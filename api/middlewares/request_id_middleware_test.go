@@ -0,0 +1,53 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestRequestID checks that only the valid client-supplied request IDs are reused.
+func TestRequestID(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		reused bool
+	}{
+		{"valid", "req-1.a_B", true},
+		{"longest", strings.Repeat("a", maxRequestIDLength), true},
+		{"empty", "", false},
+		{"too long", strings.Repeat("a", maxRequestIDLength+1), false},
+		{"line break", "req-1\nlevel=admin", false},
+		{"space", "req 1", false},
+		{"quote", `req"1`, false},
+		{"non-ASCII", "req-é", false},
+	}
+
+	for _, test := range tests {
+		var fromContext string
+
+		handler := RequestID(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+			fromContext = RequestIDFromContext(r.Context())
+		}))
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(RequestIDHeader, test.header)
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		returned := rec.Header().Get(RequestIDHeader)
+		if returned != fromContext {
+			t.Errorf("%s: returned ID %q differs from the context ID %q", test.name, returned, fromContext)
+		}
+
+		if reused := returned == test.header; reused != test.reused {
+			t.Errorf("%s: ID %q reused = %t, want %t", test.name, test.header, reused, test.reused)
+		}
+
+		if !validRequestID(returned) {
+			t.Errorf("%s: returned ID %q is not valid", test.name, returned)
+		}
+	}
+}
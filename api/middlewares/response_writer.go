@@ -0,0 +1,59 @@
+package middlewares
+
+import "net/http"
+
+// maxRecordedErrorBody is the number of response bytes kept for 5xx responses.
+const maxRecordedErrorBody = 1024
+
+// statusRecorder wraps an http.ResponseWriter to record the status code, the
+// number of bytes written and the beginning of error response bodies.
+type statusRecorder struct {
+	http.ResponseWriter
+
+	status      int
+	bytes       int
+	wroteHeader bool
+	errorBody   []byte
+}
+
+// newStatusRecorder wraps a response writer. The status defaults to 200.
+func newStatusRecorder(w http.ResponseWriter) *statusRecorder {
+	return &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+}
+
+// WriteHeader records the status code before writing it.
+func (sr *statusRecorder) WriteHeader(status int) {
+	if !sr.wroteHeader {
+		sr.status = status
+		sr.wroteHeader = true
+	}
+
+	sr.ResponseWriter.WriteHeader(status)
+}
+
+// Write records the number of bytes written and keeps the start of 5xx bodies.
+func (sr *statusRecorder) Write(b []byte) (int, error) {
+	sr.wroteHeader = true
+
+	if sr.status >= http.StatusInternalServerError && len(sr.errorBody) < maxRecordedErrorBody {
+		remaining := maxRecordedErrorBody - len(sr.errorBody)
+		sr.errorBody = append(sr.errorBody, b[:min(len(b), remaining)]...)
+	}
+
+	n, err := sr.ResponseWriter.Write(b)
+	sr.bytes += n
+
+	return n, err
+}
+
+// Flush implements http.Flusher when the underlying writer supports it.
+func (sr *statusRecorder) Flush() {
+	if flusher, ok := sr.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap returns the underlying writer, for use by http.ResponseController.
+func (sr *statusRecorder) Unwrap() http.ResponseWriter {
+	return sr.ResponseWriter
+}
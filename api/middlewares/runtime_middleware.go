@@ -0,0 +1,157 @@
+package middlewares
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/r4ulcl/api_template/utils"
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+const (
+	// rateLimitWindow is the length of the rate limiting window.
+	rateLimitWindow = time.Minute
+
+	// maintenanceRetryAfterSeconds is the value of the Retry-After header sent during maintenance.
+	maintenanceRetryAfterSeconds = "60"
+)
+
+// CORS is a middleware that adds the CORS headers for the origins allowed by
+// the runtime configuration, and answers preflight requests.
+//
+// Parameters:
+// - rc: The runtime configuration. If nil, no CORS headers are added.
+//
+// Returns:
+// - A middleware function that processes HTTP requests.
+func CORS(rc *utils.RuntimeConfig) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			allowed := rc.CORSOrigins()
+
+			if origin == "" || (!slices.Contains(allowed, "*") && !slices.Contains(allowed, origin)) {
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Add("Vary", "Origin")
+			w.Header().Set("Access-Control-Expose-Headers", "X-Request-ID, Retry-After, Link, X-Total-Count, X-Total-Is-Estimate, X-API-Version, Deprecation")
+
+			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+				w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+				w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type, X-Request-ID, If-Unmodified-Since, X-API-Version")
+				w.Header().Set("Access-Control-Max-Age", "600")
+				w.WriteHeader(http.StatusNoContent)
+
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RateLimit is a middleware that limits the requests per client and minute
+// to the value of the runtime configuration, answering 429 once exceeded.
+//
+// Clients are identified by username when authenticated, by the client IP
+// found by RealIP otherwise.
+//
+// Parameters:
+// - rc: The runtime configuration. If nil or the limit is 0, requests are not limited.
+//
+// Returns:
+// - A middleware function that processes HTTP requests.
+func RateLimit(rc *utils.RuntimeConfig) func(http.Handler) http.Handler {
+	var (
+		mu          sync.Mutex
+		windowStart time.Time
+		counts      = map[string]int{}
+	)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			limit := rc.RateLimit()
+			if limit <= 0 {
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			client := UsernameFromContext(r.Context())
+			if client == "" {
+				if addr, ok := clientAddr(r); ok {
+					client = addr.String()
+				}
+			}
+
+			mu.Lock()
+
+			now := time.Now()
+			if now.Sub(windowStart) >= rateLimitWindow {
+				windowStart = now
+				counts = map[string]int{}
+			}
+
+			counts[client]++
+			count := counts[client]
+			resetIn := rateLimitWindow - now.Sub(windowStart)
+			mu.Unlock()
+
+			w.Header().Set("X-RateLimit-Limit", strconv.Itoa(limit))
+			w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(max(limit-count, 0)))
+
+			if count > limit {
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("Retry-After", strconv.Itoa(int(resetIn.Seconds())+1))
+				w.WriteHeader(http.StatusTooManyRequests)
+				_ = json.NewEncoder(w).Encode(models.ErrorResponse{
+					Error:     "Rate limit exceeded, please retry later",
+					RequestID: RequestIDFromContext(r.Context()),
+				})
+
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Maintenance is a middleware that rejects writes from non-admin users with
+// 503 while maintenance mode is on. Reads keep working. It must run after
+// AuthMiddleware.
+//
+// Parameters:
+// - rc: The runtime configuration. If nil, requests are always forwarded.
+//
+// Returns:
+// - A middleware function that processes HTTP requests.
+func Maintenance(rc *utils.RuntimeConfig) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			readOnly := r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions
+
+			if rc.MaintenanceMode() && !readOnly && RoleFromContext(r.Context()) != string(models.AdminRole) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("Retry-After", maintenanceRetryAfterSeconds)
+				w.WriteHeader(http.StatusServiceUnavailable)
+				_ = json.NewEncoder(w).Encode(models.ErrorResponse{
+					Error:     "Maintenance in progress, writes are temporarily disabled",
+					RequestID: RequestIDFromContext(r.Context()),
+				})
+
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package middlewares
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+// Scopes is a middleware that limits service accounts to the resources and
+// actions of their scopes. Requests from users are not affected. It must run
+// after AuthMiddleware.
+//
+// The resource is the first path segment, or the second one for "/admin/..."
+// routes. GET and HEAD requests need the "read" action, any other method "write".
+// POST /query and POST /sync check the scopes of each of their reads and writes themselves.
+func Scopes(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scopes, ok := r.Context().Value(ContextScopes).([]string)
+		if !ok || (r.Method == http.MethodPost && (r.URL.Path == "/query" || r.URL.Path == "/sync")) {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		resource, action := scopeTarget(r)
+		if !models.ScopeAllows(scopes, resource, action) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusForbidden)
+			_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "Forbidden: missing scope " + resource + ":" + action})
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// scopeTarget returns the resource and action a request needs a scope for.
+func scopeTarget(r *http.Request) (string, string) {
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+
+	resource := segments[0]
+	if resource == "admin" && len(segments) > 1 {
+		resource = segments[1]
+	}
+
+	action := models.ScopeWrite
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		action = models.ScopeRead
+	}
+
+	return resource, action
+}
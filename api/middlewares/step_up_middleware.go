@@ -0,0 +1,82 @@
+package middlewares
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+// StepUpURL is the route issuing a fresh token after checking the password again.
+const StepUpURL = "/me/step-up"
+
+// StepUp requires a recent authentication for destructive actions, like
+// deleting records or changing the role of a user: a token issued with the
+// password (the "auth_time" claim) at most MaxAge ago. A stolen or forgotten
+// long-lived token is then not enough to do the most damage.
+type StepUp struct {
+	// MaxAge is how long ago the principal may have authenticated.
+	MaxAge time.Duration
+}
+
+// NewStepUp creates the step-up authentication requirement.
+//
+// Returns:
+// - The requirement, or nil if maxAge is not positive, which disables it.
+func NewStepUp(maxAge time.Duration) *StepUp {
+	if maxAge <= 0 {
+		return nil
+	}
+
+	return &StepUp{MaxAge: maxAge}
+}
+
+// Middleware requires a recent authentication for every request of a route.
+// It must run after AuthMiddleware. A nil StepUp lets every request through.
+func (s *StepUp) Middleware(next http.Handler) http.Handler {
+	if s == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.Check(w, r) {
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+// Check checks that the principal of a request authenticated recently enough,
+// writing the step-up challenge otherwise, for handlers where only some
+// requests are destructive. A nil StepUp accepts every request.
+//
+// Returns:
+// - false, after writing HTTP 401 with models.StepUpResponse and an
+// "insufficient_user_authentication" WWW-Authenticate challenge (RFC 9470),
+// if the authentication is too old or unknown.
+func (s *StepUp) Check(w http.ResponseWriter, r *http.Request) bool {
+	if s == nil {
+		return true
+	}
+
+	authTime, ok := AuthTimeFromContext(r.Context())
+	if ok && time.Since(authTime) <= s.MaxAge {
+		return true
+	}
+
+	maxAge := int(s.MaxAge.Seconds())
+
+	w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer error="insufficient_user_authentication", `+
+		`error_description="A more recent authentication is required", max_age=%d`, maxAge))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnauthorized)
+	_ = json.NewEncoder(w).Encode(models.StepUpResponse{
+		Error:     "Step-up authentication required: authenticate again with your password",
+		MaxAge:    maxAge,
+		StepUpURL: StepUpURL,
+		RequestID: RequestIDFromContext(r.Context()),
+	})
+
+	return false
+}
@@ -0,0 +1,157 @@
+package middlewares
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+// usageKey identifies the rollup of a principal during a day.
+type usageKey struct {
+	day         string
+	principal   string
+	accountType models.AccountType
+}
+
+// UsageMeter counts the requests, errors and bytes of each authenticated user
+// and service account per day, in memory, until they are flushed to a store.
+// Every replica meters its own requests and adds them to the shared rollups.
+type UsageMeter struct {
+	mu     sync.Mutex
+	counts map[usageKey]*models.UsageRollup
+}
+
+// NewUsageMeter creates a usage meter with no counts.
+func NewUsageMeter() *UsageMeter {
+	return &UsageMeter{counts: map[usageKey]*models.UsageRollup{}}
+}
+
+// Middleware meters the requests authenticated by AuthMiddleware or
+// ClientCertAuth, so it must run after them. A nil meter meters nothing.
+func (m *UsageMeter) Middleware(next http.Handler) http.Handler {
+	if m == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal := PrincipalFromContext(r.Context())
+		if principal.Username == "" {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		body := &countingReader{ReadCloser: r.Body}
+		if r.Body != nil {
+			r.Body = body
+		}
+
+		recorder := newStatusRecorder(w)
+
+		next.ServeHTTP(recorder, r)
+
+		usage := models.UsageRollup{Requests: 1, BytesIn: body.n, BytesOut: int64(recorder.bytes)}
+
+		switch {
+		case recorder.status >= http.StatusInternalServerError:
+			usage.ServerErrors = 1
+		case recorder.status >= http.StatusBadRequest:
+			usage.ClientErrors = 1
+		}
+
+		m.add(usageKey{
+			day:         time.Now().UTC().Format(models.UsageDayFormat),
+			principal:   principal.Username,
+			accountType: principal.AccountType,
+		}, usage)
+	})
+}
+
+// Run flushes the counts to the store every interval until the context is
+// cancelled, and once more then. The counts a flush fails to store are kept
+// for the next one.
+//
+// Parameters:
+// - ctx: Stops the flushes when cancelled.
+// - interval: How often the counts are flushed.
+// - store: Adds the counts to the stored rollups (e.g. database.BaseController.AddUsage).
+func (m *UsageMeter) Run(ctx context.Context, interval time.Duration, store func([]models.UsageRollup) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			m.flush(store)
+
+			return
+		case <-ticker.C:
+			m.flush(store)
+		}
+	}
+}
+
+// flush stores the counts, and takes them back if the store fails.
+func (m *UsageMeter) flush(store func([]models.UsageRollup) error) {
+	rollups := m.take()
+	if len(rollups) == 0 {
+		return
+	}
+
+	if err := store(rollups); err != nil {
+		log.Println("Error storing the usage rollups:", err)
+
+		for _, rollup := range rollups {
+			m.add(usageKey{day: rollup.Day, principal: rollup.Principal, accountType: rollup.AccountType}, rollup)
+		}
+	}
+}
+
+// add adds a usage to the counts of its principal and day.
+func (m *UsageMeter) add(key usageKey, usage models.UsageRollup) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rollup, ok := m.counts[key]
+	if !ok {
+		rollup = &models.UsageRollup{Day: key.day, Principal: key.principal, AccountType: key.accountType}
+		m.counts[key] = rollup
+	}
+
+	rollup.Add(usage)
+}
+
+// take returns the counts and resets them.
+func (m *UsageMeter) take() []models.UsageRollup {
+	m.mu.Lock()
+	counts := m.counts
+	m.counts = map[usageKey]*models.UsageRollup{}
+	m.mu.Unlock()
+
+	rollups := make([]models.UsageRollup, 0, len(counts))
+	for _, rollup := range counts {
+		rollups = append(rollups, *rollup)
+	}
+
+	return rollups
+}
+
+// countingReader counts the bytes read from a request body.
+type countingReader struct {
+	io.ReadCloser
+
+	n int64
+}
+
+// Read reads from the body and counts the bytes read.
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.ReadCloser.Read(p)
+	cr.n += int64(n)
+
+	return n, err
+}
@@ -0,0 +1,175 @@
+package middlewares
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+// Write operations limited by a WriteRateLimiter.
+const (
+	// WriteCreate is a POST or PUT on the resource collection (e.g. POST /example1).
+	WriteCreate = "create"
+
+	// WriteUpdate is any other POST, PUT or PATCH under the resource (e.g. PATCH /example1/{id}).
+	WriteUpdate = "update"
+
+	// WriteDelete is a DELETE under the resource.
+	WriteDelete = "delete"
+)
+
+// WriteRateLimiter limits the writes per user, resource and minute, apart from
+// the global rate limit, to stop runaway import scripts from flooding a resource.
+type WriteRateLimiter struct {
+	// Limits maps a resource (e.g. "example1") or a resource and an operation
+	// (e.g. "example1:create") to the writes allowed per user and minute.
+	Limits map[string]int
+
+	mu          sync.Mutex
+	windowStart time.Time
+	counts      map[string]int
+}
+
+// NewWriteRateLimiter creates a write rate limiter from a comma-separated list
+// of "resource=N" and "resource:operation=N" entries, where the operation is
+// "create", "update" or "delete" and N the writes allowed per user and minute.
+//
+// Parameters:
+// - spec: The limits, e.g. "example1:create=60,example2=300".
+//
+// Returns:
+// - The limiter, or nil if spec is empty.
+// - An error naming the first invalid entry.
+func NewWriteRateLimiter(spec string) (*WriteRateLimiter, error) {
+	limits := map[string]int{}
+
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		key, value, ok := strings.Cut(item, "=")
+		limit, err := strconv.Atoi(strings.TrimSpace(value))
+
+		if !ok || err != nil || limit < 1 {
+			return nil, fmt.Errorf("invalid write rate limit %q, expected resource[:operation]=N", item)
+		}
+
+		key = strings.TrimSpace(key)
+
+		resource, operation, _ := strings.Cut(key, ":")
+		if resource == "" || (operation != "" && operation != WriteCreate && operation != WriteUpdate && operation != WriteDelete) {
+			return nil, fmt.Errorf("invalid write rate limit %q, expected resource[:operation]=N", item)
+		}
+
+		limits[key] = limit
+	}
+
+	if len(limits) == 0 {
+		return nil, nil
+	}
+
+	return &WriteRateLimiter{Limits: limits, counts: map[string]int{}}, nil
+}
+
+// Middleware answers 429 to the writes of a user above the limits of the
+// resource. The resource is the first segment of the path, so the limits
+// apply to the resource routes (/example1, /example1/{id}...) and not to the
+// routes of other features. Clients are identified like in RateLimit, which
+// this middleware must follow. A nil limiter allows every request.
+func (l *WriteRateLimiter) Middleware(next http.Handler) http.Handler {
+	if l == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resource, operation, ok := writeOperation(r)
+		if !ok {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		client := UsernameFromContext(r.Context())
+		if client == "" {
+			if addr, ok := clientAddr(r); ok {
+				client = addr.String()
+			}
+		}
+
+		// Both the limit of the resource and of the operation apply
+		for _, key := range []string{resource + ":" + operation, resource} {
+			limit, ok := l.Limits[key]
+			if !ok {
+				continue
+			}
+
+			count, resetIn := l.count(key + "|" + client)
+
+			if count > limit {
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("Retry-After", strconv.Itoa(int(resetIn.Seconds())+1))
+				w.WriteHeader(http.StatusTooManyRequests)
+				_ = json.NewEncoder(w).Encode(models.ErrorResponse{
+					Error:     fmt.Sprintf("Write rate limit of %s exceeded (%d per minute), please retry later", key, limit),
+					RequestID: RequestIDFromContext(r.Context()),
+				})
+
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// count adds a write to a counter of the current window.
+//
+// Returns:
+// - The writes counted in the window, including this one.
+// - The time left before the window resets.
+func (l *WriteRateLimiter) count(key string) (int, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(l.windowStart) >= rateLimitWindow {
+		l.windowStart = now
+		l.counts = map[string]int{}
+	}
+
+	l.counts[key]++
+
+	return l.counts[key], rateLimitWindow - now.Sub(l.windowStart)
+}
+
+// writeOperation returns the resource and the operation of a write request.
+//
+// Returns:
+// - The resource and the operation, and true if the request is a write.
+func writeOperation(r *http.Request) (string, string, bool) {
+	path := strings.Trim(r.URL.Path, "/")
+	resource, _, nested := strings.Cut(path, "/")
+
+	switch r.Method {
+	case http.MethodDelete:
+		return resource, WriteDelete, true
+	case http.MethodPost, http.MethodPut:
+		if nested {
+			return resource, WriteUpdate, true
+		}
+
+		return resource, WriteCreate, true
+	case http.MethodPatch:
+		return resource, WriteUpdate, true
+	}
+
+	return "", "", false
+}
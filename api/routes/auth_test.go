@@ -0,0 +1,149 @@
+package routes_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/r4ulcl/api_template/api/routes"
+	"github.com/r4ulcl/api_template/testsupport"
+	"github.com/r4ulcl/api_template/utils"
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+// TestLogin checks that the token returned by the login route authenticates the requests.
+func TestLogin(t *testing.T) {
+	srv := testsupport.NewTestServer(t)
+	srv.CreateUser(t, "reader", "reader-password", models.UserRole)
+
+	rec := srv.Do(t, http.MethodPost, "/login",
+		models.LoginRequest{Username: "reader", Password: "wrong-password"}, "")
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("login with a wrong password: status %d, want 401", rec.Code)
+	}
+
+	rec = srv.Do(t, http.MethodPost, "/login", models.LoginRequest{Username: "reader", Password: "reader-password"}, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("login: status %d: %s", rec.Code, rec.Body)
+	}
+
+	var response models.JWTResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil || response.Token == "" {
+		t.Fatalf("login response %s: %v", rec.Body, err)
+	}
+
+	if rec := srv.Do(t, http.MethodGet, "/example1", nil, response.Token); rec.Code != http.StatusOK {
+		t.Fatalf("list with the login token: status %d: %s", rec.Code, rec.Body)
+	}
+}
+
+// TestAuthorization checks the status of the routes for each kind of caller.
+func TestAuthorization(t *testing.T) {
+	srv := testsupport.NewTestServer(t)
+	admin := srv.AdminToken(t)
+	user := srv.CreateUser(t, "reader", "reader-password", models.UserRole)
+
+	forged, err := utils.GenerateJWT(testsupport.AdminUsername, string(models.AdminRole), "not-the-secret")
+	if err != nil {
+		t.Fatalf("generating token: %v", err)
+	}
+
+	record := models.Example1{Field1: "auth_1", Field2: "value"}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   interface{}
+		token  string
+		want   int
+	}{
+		{"no token", http.MethodGet, "/example1", nil, "", http.StatusUnauthorized},
+		{"malformed token", http.MethodGet, "/example1", nil, "not.a.jwt", http.StatusUnauthorized},
+		{"forged token", http.MethodGet, "/example1", nil, forged, http.StatusUnauthorized},
+		{"user reads", http.MethodGet, "/example1", nil, user, http.StatusOK},
+		{"user creates", http.MethodPost, "/example1", record, user, http.StatusForbidden},
+		{"admin creates", http.MethodPost, "/example1", record, admin, http.StatusCreated},
+		{"user deletes", http.MethodDelete, "/example1/auth_1", nil, user, http.StatusForbidden},
+		{"user reads admin stats", http.MethodGet, "/admin/stats", nil, user, http.StatusForbidden},
+		{"admin reads admin stats", http.MethodGet, "/admin/stats", nil, admin, http.StatusOK},
+		{"admin deletes", http.MethodDelete, "/example1/auth_1", nil, admin, http.StatusOK},
+	}
+
+	for _, test := range tests {
+		if rec := srv.Do(t, test.method, test.path, test.body, test.token); rec.Code != test.want {
+			t.Errorf("%s: %s %s: status %d, want %d: %s", test.name, test.method, test.path, rec.Code, test.want,
+				rec.Body)
+		}
+	}
+}
+
+// TestRegistration checks that self-registration is only served when enabled.
+func TestRegistration(t *testing.T) {
+	srv := testsupport.NewTestServer(t)
+	input := models.RegisterRequest{Username: "newcomer", Password: "newcomer-password"}
+
+	if rec := srv.Do(t, http.MethodPost, "/register", input, ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("register while disabled: status %d: %s", rec.Code, rec.Body)
+	}
+
+	srv.Auth.AllowRegistration = true
+	srv.Handler = routes.SetupRouter(srv.Controller, srv.Auth, testsupport.JWTSecret)
+
+	if rec := srv.Do(t, http.MethodPost, "/register", input, ""); rec.Code != http.StatusCreated {
+		t.Fatalf("register: status %d: %s", rec.Code, rec.Body)
+	}
+
+	taken := models.RegisterRequest{Username: testsupport.AdminUsername, Password: "attacker-password"}
+	if rec := srv.Do(t, http.MethodPost, "/register", taken, ""); rec.Code != http.StatusConflict {
+		t.Fatalf("register a taken username: status %d, want 409: %s", rec.Code, rec.Body)
+	}
+
+	// Past the check of the handler, as a concurrent registration would be
+	intruder := models.User{Username: testsupport.AdminUsername, Password: "attacker-password"}
+	if _, err := srv.Auth.CreateUser(intruder); err == nil {
+		t.Fatal("creating a user with a taken username succeeded")
+	}
+
+	login := models.LoginRequest{Username: testsupport.AdminUsername, Password: testsupport.AdminPassword}
+	if rec := srv.Do(t, http.MethodPost, "/login", login, ""); rec.Code != http.StatusOK {
+		t.Fatalf("admin login after the registrations: status %d: %s", rec.Code, rec.Body)
+	}
+}
+
+// TestBootstrap checks that users without the admin role don't prevent the bootstrap of the first admin.
+func TestBootstrap(t *testing.T) {
+	srv := testsupport.NewTestServer(t)
+
+	if err := srv.DB.Where("username = ?", testsupport.AdminUsername).Delete(&models.User{}).Error; err != nil {
+		t.Fatalf("deleting the admin: %v", err)
+	}
+
+	srv.CreateUser(t, "early", "early-password", models.UserRole)
+	srv.Auth.BootstrapToken = "bootstrap-token"
+
+	bootstrap := func(username string) int {
+		t.Helper()
+
+		input := models.BootstrapRequest{Token: "bootstrap-token", Username: username, Password: "root-password"}
+
+		return srv.Do(t, http.MethodPost, "/bootstrap", input, "").Code
+	}
+
+	if code := bootstrap("early"); code != http.StatusConflict {
+		t.Fatalf("bootstrap with a taken username: status %d, want 409", code)
+	}
+
+	if code := bootstrap("root"); code != http.StatusCreated {
+		t.Fatalf("bootstrap: status %d, want 201", code)
+	}
+
+	if code := bootstrap("other"); code != http.StatusNotFound {
+		t.Fatalf("second bootstrap: status %d, want 404", code)
+	}
+
+	login := models.LoginRequest{Username: "early", Password: "early-password"}
+	if rec := srv.Do(t, http.MethodPost, "/login", login, ""); rec.Code != http.StatusOK {
+		t.Fatalf("login of the existing user: status %d: %s", rec.Code, rec.Body)
+	}
+}
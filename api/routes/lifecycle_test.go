@@ -0,0 +1,119 @@
+package routes_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/r4ulcl/api_template/testsupport"
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+// TestLifecycleVisibility checks that users other than admins only read the published records.
+func TestLifecycleVisibility(t *testing.T) {
+	srv := testsupport.NewTestServer(t)
+	admin := srv.AdminToken(t)
+	user := srv.CreateUser(t, "reader", "reader-password", models.UserRole)
+
+	rec := srv.Do(t, http.MethodPost, "/example1", models.Example1{Field1: "life_1", Field2: "value"}, admin)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: status %d: %s", rec.Code, rec.Body)
+	}
+
+	visible := func(token string) (bool, bool) {
+		t.Helper()
+
+		rec := srv.Do(t, http.MethodGet, "/example1", nil, token)
+
+		var records []models.Example1
+		if err := json.Unmarshal(rec.Body.Bytes(), &records); rec.Code != http.StatusOK || err != nil {
+			t.Fatalf("list: status %d: %s", rec.Code, rec.Body)
+		}
+
+		return len(records) == 1, srv.Do(t, http.MethodGet, "/example1/life_1", nil, token).Code == http.StatusOK
+	}
+
+	steps := []struct {
+		action string
+		status models.LifecycleStatus
+		user   bool
+	}{
+		{"", models.StatusDraft, false},
+		{"publish", models.StatusPublished, true},
+		{"archive", models.StatusArchived, false},
+		{"restore", models.StatusDraft, false},
+	}
+
+	for _, step := range steps {
+		if step.action != "" {
+			rec := srv.Do(t, http.MethodPost, "/example1/life_1/"+step.action, nil, admin)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("%s: status %d: %s", step.action, rec.Code, rec.Body)
+			}
+		}
+
+		if listed, found := visible(user); listed != step.user || found != step.user {
+			t.Errorf("%s record: user lists it %t and gets it %t, want %t", step.status, listed, found, step.user)
+		}
+
+		if listed, found := visible(admin); !listed || !found {
+			t.Errorf("%s record: admin lists it %t and gets it %t, want true", step.status, listed, found)
+		}
+	}
+
+	if rec := srv.Do(t, http.MethodPost, "/example1/life_1/archive", nil, user); rec.Code != http.StatusForbidden {
+		t.Errorf("user archive: status %d, want 403", rec.Code)
+	}
+}
+
+// TestDuplicatesPublishedOnly checks that users other than admins only compare the published records.
+func TestDuplicatesPublishedOnly(t *testing.T) {
+	srv := testsupport.NewTestServer(t)
+	admin := srv.AdminToken(t)
+	user := srv.CreateUser(t, "reader", "reader-password", models.UserRole)
+
+	for _, id := range []string{"dup_1", "dup_2", "dup_3"} {
+		rec := srv.Do(t, http.MethodPost, "/example1", models.Example1{Field1: id, Field2: "same"}, admin)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("create %s: status %d: %s", id, rec.Code, rec.Body)
+		}
+	}
+
+	duplicates := func(token string) []models.DuplicateGroup {
+		t.Helper()
+
+		rec := srv.Do(t, http.MethodGet, "/example1/duplicates?fields=field2", nil, token)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("duplicates: status %d: %s", rec.Code, rec.Body)
+		}
+
+		var groups []models.DuplicateGroup
+		if err := json.Unmarshal(rec.Body.Bytes(), &groups); err != nil {
+			t.Fatalf("decoding duplicates: %v", err)
+		}
+
+		return groups
+	}
+
+	if groups := duplicates(user); len(groups) != 0 {
+		t.Fatalf("user sees the duplicate drafts: %+v", groups)
+	}
+
+	for _, id := range []string{"dup_1", "dup_2"} {
+		if rec := srv.Do(t, http.MethodPost, "/example1/"+id+"/publish", nil, admin); rec.Code != http.StatusOK {
+			t.Fatalf("publish %s: status %d: %s", id, rec.Code, rec.Body)
+		}
+	}
+
+	if groups := duplicates(user); len(groups) != 1 || groups[0].Count != 2 || len(groups[0].IDs) != 2 {
+		t.Fatalf("user duplicates = %+v, want one group of the 2 published records", groups)
+	}
+
+	if groups := duplicates(admin); len(groups) != 1 || groups[0].Count != 3 || len(groups[0].IDs) != 3 {
+		t.Fatalf("admin duplicates = %+v, want one group of the 3 records", groups)
+	}
+
+	if rec := srv.Do(t, http.MethodGet, "/example1/dup_3", nil, user); rec.Code != http.StatusNotFound {
+		t.Fatalf("user get of a draft: status %d, want 404", rec.Code)
+	}
+}
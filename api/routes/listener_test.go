@@ -0,0 +1,74 @@
+package routes_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/r4ulcl/api_template/api/routes"
+	"github.com/r4ulcl/api_template/testsupport"
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+// TestManagementListener checks that the admin-only routes are only served by the management listener.
+func TestManagementListener(t *testing.T) {
+	srv := testsupport.NewTestServer(t)
+	admin := srv.AdminToken(t)
+	user := srv.CreateUser(t, "reader", "reader-password", models.UserRole)
+	router := srv.Handler
+
+	rec := srv.Do(t, http.MethodPost, "/example1", models.Example1{Field1: "listener_1", Field2: "value"}, admin)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: status %d: %s", rec.Code, rec.Body)
+	}
+
+	if rec := srv.Do(t, http.MethodPost, "/example1/listener_1/publish", nil, admin); rec.Code != http.StatusOK {
+		t.Fatalf("publish: status %d: %s", rec.Code, rec.Body)
+	}
+
+	public, management := routes.PublicHandler(router), routes.ManagementHandler(router)
+	record := models.Example1{Field1: "listener_2", Field2: "value"}
+
+	tests := []struct {
+		name    string
+		handler http.Handler
+		method  string
+		path    string
+		body    interface{}
+		token   string
+		want    int
+	}{
+		{"public admin stats", public, http.MethodGet, "/admin/stats", nil, admin, http.StatusNotFound},
+		{"public admin create", public, http.MethodPost, "/example1", record, admin, http.StatusNotFound},
+		{"public user list", public, http.MethodGet, "/user", nil, admin, http.StatusNotFound},
+		{"public user search", public, http.MethodGet, "/user/search?q=adm", nil, admin, http.StatusNotFound},
+		{"public admin patch", public, http.MethodPatch, "/example1/listener_1", map[string]string{"field2": "a"},
+			admin, http.StatusNotFound},
+		{"public list", public, http.MethodGet, "/example1", nil, user, http.StatusOK},
+		{"public user patch", public, http.MethodPatch, "/example1/listener_1", map[string]string{"field2": "u"},
+			user, http.StatusOK},
+		{"management admin stats", management, http.MethodGet, "/admin/stats", nil, admin, http.StatusOK},
+		{"management admin create", management, http.MethodPost, "/example1", record, admin, http.StatusCreated},
+		{"management user list", management, http.MethodGet, "/user", nil, admin, http.StatusOK},
+		{"management admin patch", management, http.MethodPatch, "/example1/listener_1",
+			map[string]string{"field2": "a"}, admin, http.StatusOK},
+		{"management list", management, http.MethodGet, "/example1", nil, user, http.StatusNotFound},
+		{"management user patch", management, http.MethodPatch, "/example1/listener_1",
+			map[string]string{"field2": "u"}, user, http.StatusNotFound},
+	}
+
+	for _, test := range tests {
+		srv.Handler = test.handler
+
+		if rec := srv.Do(t, test.method, test.path, test.body, test.token); rec.Code != test.want {
+			t.Errorf("%s: %s %s: status %d, want %d: %s", test.name, test.method, test.path, rec.Code, test.want,
+				rec.Body)
+		}
+	}
+
+	// Without ADMIN_LISTEN_ADDR the router serves every route
+	srv.Handler = router
+
+	if rec := srv.Do(t, http.MethodGet, "/admin/stats", nil, admin); rec.Code != http.StatusOK {
+		t.Errorf("router admin stats: status %d, want 200", rec.Code)
+	}
+}
@@ -0,0 +1,57 @@
+package routes_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/r4ulcl/api_template/api/routes"
+	"github.com/r4ulcl/api_template/testsupport"
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+// rolePolicy allows the requests of the roles it lists.
+type rolePolicy map[models.Role]bool
+
+// Allow allows the requests of the listed roles.
+func (p rolePolicy) Allow(_ context.Context, input models.PolicyInput) (bool, error) {
+	return p[input.Role], nil
+}
+
+// TestWritableFieldsPolicy checks that the policy decides on the PATCH requests of the writable fields routes.
+func TestWritableFieldsPolicy(t *testing.T) {
+	srv := testsupport.NewTestServer(t)
+	admin := srv.AdminToken(t)
+	user := srv.CreateUser(t, "reader", "reader-password", models.UserRole)
+	editor := srv.Token(t, "editor", "editor")
+
+	rec := srv.Do(t, http.MethodPost, "/example1", models.Example1{Field1: "policy_1", Field2: "value"}, admin)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: status %d: %s", rec.Code, rec.Body)
+	}
+
+	if rec := srv.Do(t, http.MethodPost, "/example1/policy_1/publish", nil, admin); rec.Code != http.StatusOK {
+		t.Fatalf("publish: status %d: %s", rec.Code, rec.Body)
+	}
+
+	srv.Controller.Policy = rolePolicy{"editor": true}
+	srv.Handler = routes.SetupRouter(srv.Controller, srv.Auth, testsupport.JWTSecret)
+
+	tests := []struct {
+		name  string
+		body  map[string]string
+		token string
+		want  int
+	}{
+		{"admin denied by the policy", map[string]string{"field2": "admin"}, admin, http.StatusForbidden},
+		{"user on a writable field", map[string]string{"field2": "user"}, user, http.StatusOK},
+		{"user on another field", map[string]string{"field1": "policy_2"}, user, http.StatusForbidden},
+		{"role granted by the policy", map[string]string{"field2": "editor"}, editor, http.StatusOK},
+	}
+
+	for _, test := range tests {
+		if rec := srv.Do(t, http.MethodPatch, "/example1/policy_1", test.body, test.token); rec.Code != test.want {
+			t.Errorf("%s: status %d, want %d: %s", test.name, rec.Code, test.want, rec.Body)
+		}
+	}
+}
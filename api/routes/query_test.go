@@ -0,0 +1,32 @@
+package routes_test
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/r4ulcl/api_template/testsupport"
+)
+
+// TestHiddenFieldsNotQueryable checks that the fields hidden from JSON can't be filtered, sorted or selected on.
+func TestHiddenFieldsNotQueryable(t *testing.T) {
+	srv := testsupport.NewTestServer(t)
+	admin := srv.AdminToken(t)
+
+	if rec := srv.Do(t, http.MethodGet, "/user?sort=username", nil, admin); rec.Code != http.StatusOK {
+		t.Fatalf("list users: status %d: %s", rec.Code, rec.Body)
+	}
+
+	for _, name := range []string{"password", "Password"} {
+		for _, query := range []string{
+			"filter[" + name + "][like]=" + url.QueryEscape("$2a$%"),
+			name + "=" + url.QueryEscape("$2a$"),
+			"sort=" + name,
+			"fields=" + name,
+		} {
+			if rec := srv.Do(t, http.MethodGet, "/user?"+query, nil, admin); rec.Code != http.StatusBadRequest {
+				t.Errorf("GET /user?%s: status %d, want 400: %s", query, rec.Code, rec.Body)
+			}
+		}
+	}
+}
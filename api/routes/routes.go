@@ -1,126 +1,2049 @@
 package routes
 
 import (
+	"context"
+	"database/sql"
+	"encoding/json"
 	"log"
 	"net/http"
 	"reflect"
+	"slices"
+	"strings"
+	"sync"
 
 	"github.com/gorilla/mux"
 	"github.com/r4ulcl/api_template/api/controllers"
 	"github.com/r4ulcl/api_template/api/middlewares"
-	_ "github.com/r4ulcl/api_template/docs"
+	"github.com/r4ulcl/api_template/database"
+	"github.com/r4ulcl/api_template/docs"
 	"github.com/r4ulcl/api_template/utils/models"
+	"github.com/r4ulcl/api_template/utils/openapi"
+	"github.com/r4ulcl/api_template/utils/protobuf"
 	httpSwagger "github.com/swaggo/http-swagger"
 )
 
+// resources lists the data resources readable by every authenticated user.
+var resources = []string{"example1", "example2", "exampleRelational"}
+
+// resourcesAdmin lists the resources managed through the admin routes.
+var resourcesAdmin = []string{"user", "example1", "example2", "exampleRelational", "announcements"}
+
+// modelMap associates resource names with the correct model type.
+var modelMap = map[string]interface{}{
+	"user":              &models.User{},
+	"example1":          &models.Example1{},
+	"example2":          &models.Example2{},
+	"exampleRelational": &models.ExampleRelational{},
+	"announcements":     &models.Announcement{},
+}
+
+// lifecycleResources lists the resources with a draft → published → archived
+// status lifecycle. Their model needs a models.LifecycleStatus field.
+var lifecycleResources = []string{"example1"}
+
+// writableFields lists, per resource, the fields roles other than admin may
+// change with PATCH /{resource}/{id}, by JSON name. Admins change every field.
+var writableFields = map[string]map[models.Role][]string{
+	"example1": {models.UserRole: {"field2", "meta"}},
+}
+
+// txPolicies lists, per resource, the transaction options of its operations,
+// on top of database.DefaultTxPolicy, which merges records SERIALIZABLE.
+// Clients ask for a stricter isolation level per request with X-Isolation-Level.
+var txPolicies = map[string]database.TxPolicy{
+	// The pages of example1 and their X-Total-Count are read from the same snapshot
+	"example1": {database.TxList: {Isolation: sql.LevelRepeatableRead, ReadOnly: true}},
+}
+
+// apiVersion is the current version of the API payloads, served to the clients
+// without an X-API-Version header.
+const apiVersion = 2
+
+// apiShims lists, per resource, the shims serving the clients of older API
+// versions after a refactor of the model. Add one when a field is renamed or
+// becomes required, with the version of the payload it replaces.
+var apiShims = map[string][]middlewares.Shim{
+	// Version 1 named the fields of example1 "name" and "description"
+	"example1": {{Version: 1, Rename: map[string]string{"name": "field1", "description": "field2"}}},
+}
+
+// protoFields registers, per resource, the protobuf field numbers of the JSON
+// fields of its model, so its CRUD endpoints also negotiate application/x-protobuf
+// (see protobuf.Codec). Numbers must never change or be reused once clients use them.
+var protoFields = map[string]map[string]int{
+	"example1": {"field1": 1, "field2": 2, "meta": 3, "status": 4, "relational_count": 5, "updated_at": 6},
+	"example2": {
+		"field1": 1, "field2": 2, "location": 3, "assigned_to": 4, "reference": 5, "created_by": 6, "updated_at": 7,
+	},
+}
+
+// resourceType holds the model of a resource and its settings, checked once at startup.
+type resourceType struct {
+	model     interface{}     // A pointer to the model, as in modelMap (e.g. &models.Example1{})
+	lifecycle bool            // Whether the resource is in lifecycleResources
+	proto     *protobuf.Codec // The codec of the fields in protoFields, if any
+}
+
+// resourceTypes associates resource names with the models in modelMap and their settings.
+var resourceTypes = func() map[string]resourceType {
+	types := make(map[string]resourceType, len(modelMap))
+
+	for resource, model := range modelMap {
+		lifecycle := slices.Contains(lifecycleResources, resource)
+
+		if lifecycle && !database.HasLifecycle(model) {
+			panic("routes: lifecycle resource " + resource + " has no models.LifecycleStatus field")
+		}
+
+		var codec *protobuf.Codec
+
+		if numbers, ok := protoFields[resource]; ok {
+			var err error
+			if codec, err = protobuf.NewCodec(reflect.TypeOf(model).Elem(), numbers); err != nil {
+				panic("routes: " + err.Error())
+			}
+		}
+
+		types[resource] = resourceType{model: model, lifecycle: lifecycle, proto: codec}
+	}
+
+	return types
+}()
+
+// NewResourceModel returns a new instance of the model of a data resource.
+//
+// Only the resources in the public list are returned, so users can't be
+// created through it. The boolean is false if the resource is unknown.
+func NewResourceModel(resource string) (interface{}, bool) {
+	if !slices.Contains(resources, resource) {
+		return nil, false
+	}
+
+	return resourceTypes[resource].newModel(), true
+}
+
+// NewResourceSlice returns a pointer to a new empty slice of the model of a data resource,
+// as passed to GetAll.
+//
+// The boolean is false if the resource is unknown.
+func NewResourceSlice(resource string) (interface{}, bool) {
+	if !slices.Contains(resources, resource) {
+		return nil, false
+	}
+
+	return resourceTypes[resource].newSlice(), true
+}
+
+// ProtoCodec returns the protobuf codec of the records of a model type,
+// registered in protoFields, or nil if its resource has none.
+func ProtoCodec(modelType reflect.Type) *protobuf.Codec {
+	for _, resourceType := range resourceTypes {
+		if resourceType.proto != nil && reflect.TypeOf(resourceType.model).Elem() == modelType {
+			return resourceType.proto
+		}
+	}
+
+	return nil
+}
+
+// TxPolicy returns the transaction options of the operations on a resource,
+// listed in txPolicies.
+func TxPolicy(resource string) database.TxPolicy {
+	return txPolicies[resource]
+}
+
+// setupHealthRoutes documents the unauthenticated health endpoints
+// @Summary Liveness and readiness probes
+// @Tags health
+// @Description /healthz reports that the process is alive, /readyz reports whether the database is reachable, and
+// @Description the state of the dependencies monitored with /admin/dependencies. A dependency down turns the status
+// @Description to "degraded" without failing the probe.
+// @Produce json
+// @Success 200 {object} models.HealthResponse
+// @Failure 503 {object} models.HealthResponse
+// @Router /healthz [get]
+// @Router /readyz [get]
+func setupHealthRoutes(router *mux.Router, controller *controllers.Controller) {
+	router.HandleFunc("/healthz", controller.Healthz).Methods("GET")
+	router.HandleFunc("/readyz", controller.Readyz).Methods("GET")
+}
+
 // SetupRouter sets up Gorilla Mux with our handlers and Swagger
 // @Summary Login and generate JWT token
 // @Description Login using username and password, and return a JWT token for authorized access
+// @Description After LOGIN_CHALLENGE_AFTER failed logins from a client IP within LOGIN_FAILURE_WINDOW seconds, the
+// @Description logins need the token of a solved challenge (CHALLENGE_PROVIDER: hcaptcha, recaptcha or turnstile) in
+// @Description X-Challenge-Token, and answer 401 with the provider and site key without it; after LOGIN_BLOCK_AFTER
+// @Description failures they answer 429 until the window ends. A successful login clears the failures.
+// @Description With remember_me, the response also has a device token, only exchangeable for new session tokens at
+// @Description POST /login/device for DEVICE_TOKEN_TTL days, and revocable with DELETE /me/devices/{id}.
 // @Tags authentication
 // @Accept json
 // @Produce json
-// @Param body body models.LoginRequest true "Login request with username and password"
-// @Success 200 {object} models.JWTResponse
-// @Failure 400 {string} string "Invalid input"
-// @Failure 401 {Object} models.ErrorResponse "Unauthorized"
-// @Router /login [post]
+// @Param body body models.LoginRequest true "Login request with username and password"
+// @Param X-Challenge-Token header string false "Token of the solved challenge, once required"
+// @Success 200 {object} models.JWTResponse
+// @Failure 400 {string} string "Invalid input"
+// @Failure 401 {object} models.ChallengeResponse "Unauthorized, or challenge required"
+// @Failure 429 {object} models.ErrorResponse "Too many failed logins"
+// @Failure 503 {object} models.ErrorResponse "The challenge cannot be verified"
+// @Router /login [post]
+// @security ApiKeyAuth
+func SetupRouter(baseController *controllers.Controller, authController *controllers.AuthController,
+	jwtSecret string,
+) *mux.Router {
+	r := mux.NewRouter()
+
+	r.Use(middlewares.RequestID)
+	r.Use(middlewares.RealIP(baseController.TrustedProxies))
+	r.Use(middlewares.Recover(baseController.Reporter))
+	r.Use(baseController.IPFilter.Middleware)
+
+	if baseController.Latency != nil {
+		r.Use(baseController.Latency.Middleware)
+	}
+
+	r.Use(middlewares.CORS(baseController.Runtime))
+	r.Use(middlewares.Pretty)
+
+	// Preflight requests don't match any route method, so they are answered here
+	r.MethodNotAllowedHandler = middlewares.CORS(baseController.Runtime)(methodNotAllowed(r))
+
+	r.PathPrefix("/swagger/").Handler(httpSwagger.WrapHandler)
+
+	setupHealthRoutes(r, baseController)
+
+	// Fail fast with 503 while the database is down
+	dbAvailable := middlewares.DBAvailable(baseController.Health)
+
+	rateLimit := middlewares.RateLimit(baseController.Runtime)
+
+	r.Handle("/login", rateLimit(dbAvailable(baseController.LoginThrottle.Middleware(
+		http.HandlerFunc(authController.Login))))).Methods("POST")
+	public := func(h http.Handler) http.Handler { return rateLimit(dbAvailable(h)) }
+	setupDeviceLoginRoutes(r, authController, func(h http.Handler) http.Handler {
+		return public(baseController.LoginThrottle.Middleware(h))
+	})
+	setupRegistrationRoutes(r, authController, public)
+	setupBootstrapRoutes(r, authController, public)
+	setupOAuthRoutes(r, authController, public)
+	setupExportDownloadRoutes(r, baseController, public)
+	setupInboundHookRoutes(r, baseController, public)
+	setupInboundEmailRoutes(r, baseController, public)
+
+	// General API subrouter with authentication middleware
+	all := r.NewRoute().Subrouter()
+	all.Use(dbAvailable)
+	all.Use(middlewares.ClientCertAuth(authController.Certificates))
+	all.Use(middlewares.AuthMiddleware(jwtSecret)) // Protect API routes
+	all.Use(baseController.Usage.Middleware)
+	all.Use(middlewares.Scopes)
+	all.Use(middlewares.FeatureFlags(baseController.Flags))
+	all.Use(rateLimit)
+	all.Use(baseController.WriteLimiter.Middleware)
+	all.Use(middlewares.APIVersion(apiVersion, apiShims))
+	all.Use(middlewares.Maintenance(baseController.Runtime))
+	all.Use(middlewares.IfUnmodifiedSince)
+	all.Use(middlewares.IsolationHint)
+
+	// Generic route setup for resources like /users, /servers, /employee, /groups, etc.
+	root := "/"
+	setupChangesRoutes(all, baseController, root, resources, resourceTypes)
+	setupURLResourceRoutes(all, baseController, root, resources, resourceTypes)
+	setupBatchQueryRoutes(all, baseController)
+	setupSyncRoutes(all, baseController)
+	// Served by both listeners: admins update on the management one, the other roles on the public one
+	writable := all.NewRoute().Subrouter()
+	setupWritableFieldRoutes(writable, baseController, root, writableFields, resourceTypes)
+	setupSavedQueryRoutes(all, baseController)
+	setupResourceListRoutes(all, baseController, resources)
+	setupAnnouncementRoutes(all, baseController)
+	setupFlagRoutes(all, baseController)
+	setupPreferenceRoutes(all, baseController)
+	setupFavoriteRoutes(all, baseController)
+	setupInboxRoutes(all, baseController)
+	setupEmailVerificationRoutes(all, authController)
+	setupDeviceRoutes(all, authController)
+	setupStepUpRoutes(all, authController, baseController.LoginThrottle)
+	setupProxyRoutes(all, baseController)
+	setupBulkJobRoutes(all, baseController)
+	setupExportRoutes(all, baseController)
+
+	// Admin-only subrouter
+	adminOnly := all.NewRoute().Subrouter()
+	adminOnly.Use(baseController.AdminIPFilter.Middleware)
+
+	// Complex installations delegate the authorization to a policy instead
+	if baseController.Policy != nil {
+		adminOnly.Use(middlewares.Authorize(baseController.Policy, policyRecord(baseController)))
+	} else {
+		adminOnly.Use(middlewares.AdminOnly)
+	}
+
+	// Generic admin route setup for resources
+	rootAdmin := "/"
+	// Separated to have different Swagger comments
+	setupUserSearchRoutes(adminOnly, baseController)
+	setupURLAdminResourceRoutes(adminOnly, baseController, rootAdmin, resourcesAdmin, resourceTypes)
+	setupBodyAdminResourceRoutes(adminOnly, baseController, rootAdmin, resourcesAdmin, resourceTypes)
+	setupStatsRoutes(adminOnly, baseController)
+	setupSchemaGraphRoutes(adminOnly, baseController)
+	setupMonitoringRoutes(adminOnly, baseController)
+	setupLeaderRoutes(adminOnly, baseController)
+	setupRouteListRoutes(adminOnly, baseController, r, all, adminOnly)
+	setupNotificationRoutes(adminOnly, baseController)
+	setupFeatureFlagAdminRoutes(adminOnly, baseController)
+	setupValidationRuleRoutes(adminOnly, baseController)
+	setupQuotaRoutes(adminOnly, baseController)
+	setupQuotaUsageRoutes(adminOnly, baseController)
+	setupMergeRoutes(adminOnly, baseController, rootAdmin, resources, resourceTypes)
+	setupBulkImportRoutes(adminOnly, baseController, rootAdmin, resourcesAdmin, resourceTypes)
+
+	if baseController.DevMode {
+		setupGenerateRoutes(adminOnly, baseController, resourcesAdmin, resourceTypes)
+	}
+
+	setupExportCreateRoutes(adminOnly, baseController, rootAdmin, resourcesAdmin, resourceTypes)
+	setupDumpRoutes(adminOnly, baseController, resourcesAdmin, resourceTypes)
+	setupLifecycleRoutes(adminOnly, baseController, rootAdmin, lifecycleResources, resourceTypes)
+	setupRecordLockRoutes(adminOnly, baseController, rootAdmin, resourcesAdmin, resourceTypes)
+	setupCounterRoutes(adminOnly, baseController)
+	setupReportRoutes(adminOnly, baseController)
+	setupImporterRoutes(adminOnly, baseController)
+	setupImporterRunRoutes(adminOnly, baseController)
+	setupInboundHookAdminRoutes(adminOnly, baseController)
+	setupEmailRuleRoutes(adminOnly, baseController)
+	setupDependencyRoutes(adminOnly, baseController)
+	setupUsageRoutes(adminOnly, baseController)
+	setupSettingRoutes(adminOnly, baseController)
+	setupRuntimeConfigRoutes(adminOnly, baseController)
+	setupServiceAccountRoutes(adminOnly, baseController)
+	setupConfigAuditRoutes(adminOnly, baseController)
+	setupConfigAuditIntegrityRoutes(adminOnly, baseController)
+	setupOpenAPIRoutes(r, baseController, rateLimit, all, adminOnly)
+
+	// With ADMIN_LISTEN_ADDR, the admin-only routes move to the management listener
+	routeListeners := map[*mux.Route]listener{}
+	setRouteListener(routeListeners, writable, anyListener)
+	setRouteListener(routeListeners, adminOnly, managementListener)
+	r.Use(listenerFilter(routeListeners))
+
+	if baseController.Console != nil {
+		r.Handle("/console", http.RedirectHandler("/console/", http.StatusMovedPermanently)).Methods("GET", "HEAD")
+		r.PathPrefix("/console/").Handler(http.StripPrefix("/console", baseController.Console)).Methods("GET", "HEAD")
+	}
+
+	// The front-end bundle gets the GET requests no other route matched
+	if baseController.Static != nil {
+		r.PathPrefix("/").Handler(baseController.Static).Methods("GET", "HEAD")
+	}
+
+	return r
+}
+
+// listener is a listener serving the routes of a router (see PublicHandler and ManagementHandler).
+type listener int
+
+const (
+	// anyListener marks the routes served by both listeners.
+	anyListener listener = iota
+	// publicListener serves every route except the admin-only ones.
+	publicListener
+	// managementListener serves the admin-only routes when ADMIN_LISTEN_ADDR is set.
+	managementListener
+)
+
+// listenerKey is the context key of the listener serving a request.
+type listenerKey struct{}
+
+// PublicHandler serves the routes of a router except the admin-only ones,
+// which answer 404 as if they did not exist, whatever the middlewares of the router.
+func PublicHandler(router http.Handler) http.Handler {
+	return servedBy(router, publicListener)
+}
+
+// ManagementHandler serves the admin-only routes of a router only. They keep
+// their authentication, so admins log in on the public port.
+func ManagementHandler(router http.Handler) http.Handler {
+	return servedBy(router, managementListener)
+}
+
+// servedBy marks the requests of a handler as served by a listener, for the listenerFilter of the router.
+func servedBy(router http.Handler, served listener) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		router.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), listenerKey{}, served)))
+	})
+}
+
+// listenerFromContext returns the listener serving a request, or anyListener
+// if the router serves every route (no ADMIN_LISTEN_ADDR).
+func listenerFromContext(ctx context.Context) listener {
+	served, _ := ctx.Value(listenerKey{}).(listener)
+
+	return served
+}
+
+// listenerFilter answers 404 to the requests of the routes served by another
+// listener than the one of the request. routeListeners holds the listener of
+// the admin-only routes (managementListener) and of the routes of both
+// (anyListener); the other routes are public.
+func listenerFilter(routeListeners map[*mux.Route]listener) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			served := listenerFromContext(r.Context())
+
+			route, ok := routeListeners[mux.CurrentRoute(r)]
+			if !ok {
+				route = publicListener
+			}
+
+			if served != anyListener && route != anyListener && route != served {
+				http.NotFound(w, r)
+
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// setRouteListener records the listener of every route of a router.
+func setRouteListener(routeListeners map[*mux.Route]listener, router *mux.Router, served listener) {
+	_ = router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		routeListeners[route] = served
+
+		return nil
+	})
+}
+
+// setupURLResourceRoutes sets up the common routes for CRUD operations for resources
+// @Summary Setup GET resource routes
+// @Tags user
+// @Description Setup routes for CRUD operations on resources like users, servers, employees, etc.
+// @Param resource path string true "Resource type" Enums(example1, example2, exampleRelational)
+// @Param id path string false "Resource ID (for operations on specific resources)"
+// @Param page query int false "Page number, starting at 1"
+// @Param per_page query int false "Records per page (default 50, max 1000)"
+// @Param pretty query bool false "Indent the JSON response (every endpoint accepts it)"
+// @Description Resources registered in protoFields answer application/x-protobuf when asked with Accept; their messages
+// @Description are described by the .proto file of GET /{resource}/proto.
+// @Produce json
+// @Produce application/x-protobuf
+// @Header 200 {string} Link "RFC 8288 first, prev, next and last page links (paginated lists only)"
+// @Header 200 {integer} X-Total-Count "Number of matching records"
+// @Header 200 {boolean} X-Total-Is-Estimate "Set when X-Total-Count is a database estimate"
+// @Header 200 {integer} X-Per-Page "Page size applied when per_page was capped by LIST_BYTE_BUDGET"
+// @Header 200 {integer} X-Per-Page-Requested "Page size asked for, when capped"
+// @Header 200 {integer} X-Row-Size "Average row size in bytes of the resource, when capped"
+// @Param If-None-Match header string false "ETag of a previous list: 304 if the list did not change"
+// @Param A-IM header string false "With If-None-Match, \"changes\" returns only the changes since that list"
+// @Param X-Isolation-Level header string false "Read the list in a read-only transaction at this level" Enums(read_committed, repeatable_read, serializable)
+// @Header 200 {string} ETag "Latest change of the resource (resources with updated_at)"
+// @Success 226 {object} models.ChangeSet
+// @Success 304
+// @Failure 503 {object} models.ErrorResponse "Too many concurrent requests of the route group, or the database is busy"
+// @Router /{resource} [get]
+// @Param fields query string false "Comma-separated fields compared by /{resource}/duplicates"
+// @Router /{resource}/schema [get]
+// @Router /{resource}/proto [get]
+// @Router /{resource}/duplicates [get]
+// @Router /{resource}/{id} [get]
+// @security ApiKeyAuth
+func setupURLResourceRoutes(router *mux.Router, controller *controllers.Controller,
+	root string, resources []string, modelTypes map[string]resourceType,
+) {
+	for _, resource := range resources {
+		resourcePath := root + resource
+		log.Println("resourcePath setupResourceRoutes", resourcePath)
+
+		// Users other than admins only read the published records of resources with a lifecycle
+		read := func(handler http.HandlerFunc) http.Handler {
+			if modelTypes[resource].lifecycle {
+				return middlewares.PublishedOnly(handler)
+			}
+
+			return handler
+		}
+
+		router.Handle(resourcePath, controller.Concurrency.Limit(middlewares.ConcurrencyList,
+			read(func(w http.ResponseWriter, r *http.Request) {
+				modelType, ok := modelTypes[resource]
+				if !ok {
+					http.Error(w, "Invalid resource", http.StatusBadRequest)
+
+					return
+				}
+
+				// Call GetAll with a pointer to a new slice (e.g., *[]models.User)
+				controller.GetAll(w, r, resource, modelType.newSlice())
+			}))).Methods("GET")
+
+		// Registered before /{id} so "schema" is not taken as an ID
+		router.HandleFunc(resourcePath+"/schema", func(w http.ResponseWriter, r *http.Request) {
+			modelType, ok := modelTypes[resource]
+			if !ok {
+				http.Error(w, "Invalid resource", http.StatusBadRequest)
+
+				return
+			}
+
+			controller.GetSchema(w, r, resource, modelType.newModel())
+		}).Methods("GET")
+
+		router.HandleFunc(resourcePath+"/proto", func(w http.ResponseWriter, r *http.Request) {
+			modelType, ok := modelTypes[resource]
+			if !ok {
+				http.Error(w, "Invalid resource", http.StatusBadRequest)
+
+				return
+			}
+
+			controller.GetProto(w, r, resource, modelType.newModel())
+		}).Methods("GET")
+
+		router.Handle(resourcePath+"/duplicates", controller.Concurrency.Limit(middlewares.ConcurrencyList,
+			read(func(w http.ResponseWriter, r *http.Request) {
+				modelType, ok := modelTypes[resource]
+				if !ok {
+					http.Error(w, "Invalid resource", http.StatusBadRequest)
+
+					return
+				}
+
+				controller.GetDuplicates(w, r, modelType.newModel())
+			}))).Methods("GET")
+
+		router.Handle(resourcePath+"/{id}", read(func(w http.ResponseWriter, r *http.Request) {
+			modelType, ok := modelTypes[resource]
+			if !ok {
+				http.Error(w, "Invalid resource", http.StatusBadRequest)
+
+				return
+			}
+
+			// Call GetByID with the correct model type
+			controller.GetByID(w, r, modelType.newModel())
+		})).Methods("GET")
+	}
+}
+
+// setupChangesRoutes sets up the change feeds of the resources with an updated_at field.
+// They are registered before /{resource}/{id} so "changes" is not taken as an ID.
+// @Summary Changes of a resource
+// @Tags resources
+// @Description Records created, updated or deleted after the "since" watermark, oldest first. Pass the returned
+// @Description watermark as "since" of the next request. With "wait", the request waits up to that many seconds for
+// @Description a change (long polling). Users other than admins get the records no longer published as deleted.
+// @Produce json
+// @Param resource path string true "Resource type" Enums(example1, example2)
+// @Param since query string true "RFC 3339 watermark"
+// @Param wait query int false "Seconds to wait for a change (0 to 30)"
+// @Success 200 {object} models.ChangeSet
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 410 {object} models.ErrorResponse
+// @Router /{resource}/changes [get]
+// @security ApiKeyAuth
+func setupChangesRoutes(router *mux.Router, controller *controllers.Controller,
+	root string, resources []string, modelTypes map[string]resourceType,
+) {
+	for _, resource := range resources {
+		modelType, ok := modelTypes[resource]
+		if !ok || !database.TracksChanges(modelType.newModel()) {
+			continue
+		}
+
+		router.Handle(root+resource+"/changes", controller.Concurrency.Limit(middlewares.ConcurrencyList,
+			middlewares.PublishedOnly(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				controller.GetChanges(w, r, resource, modelType.newSlice())
+			})))).Methods("GET")
+	}
+}
+
+// setupBatchQueryRoutes sets up the route running several reads in one request
+// @Summary Batch reads
+// @Tags resources
+// @Description Run up to 20 named reads concurrently, each one as GET /{resource} with the same filters, sort, fields
+// @Description and pagination, e.g. {"latest": {"resource": "example1", "sort": ["-field1"], "per_page": 5},
+// @Description "todo": {"resource": "example2", "filters": {"field2": "todo"}}}. The results are keyed by name and
+// @Description hold the status, records and total of each read, so one failing read does not fail the others.
+// @Accept json
+// @Produce json
+// @Param body body map[string]models.QueryOperation true "Reads keyed by name"
+// @Success 200 {object} map[string]models.QueryResult
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 503 {object} models.ErrorResponse "Too many concurrent requests of the route group"
+// @Router /query [post]
+// @security ApiKeyAuth
+func setupBatchQueryRoutes(router *mux.Router, controller *controllers.Controller) {
+	router.Handle("/query", controller.Concurrency.Limit(middlewares.ConcurrencyList,
+		middlewares.PublishedOnly(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			controller.Query(w, r, NewResourceSlice)
+		})))).Methods("POST")
+}
+
+// setupSyncRoutes sets up the route syncing offline-first clients
+// @Summary Sync offline-first clients
+// @Tags resources
+// @Description Pull the changes of the subsets of resources a client mirrors and push its local changes, e.g.
+// @Description {"token": "...", "resources": {"example1": {"filters": {"field2": "foo"}}}, "changes": [{"resource":
+// @Description "example1", "id": "a", "op": "upsert", "record": {"field2": "foo"}, "base_updated_at": "..."}]}.
+// @Description Send the token of the response with the next sync. Deleted records, and records leaving the subset,
+// @Description are returned as tombstones in "deleted". "reset" asks the client to reload a resource. Pushed changes
+// @Description (admins only) are a conflict when the record changed on the server after base_updated_at; the result
+// @Description holds a hint, the server copy and the fields that differ.
+// @Accept json
+// @Produce json
+// @Param body body models.SyncRequest true "Token, mirrored subsets and local changes"
+// @Success 200 {object} models.SyncResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Router /sync [post]
+// @security ApiKeyAuth
+func setupSyncRoutes(router *mux.Router, controller *controllers.Controller) {
+	router.Handle("/sync", controller.Concurrency.Limit(middlewares.ConcurrencyList,
+		middlewares.PublishedOnly(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			controller.Sync(w, r, NewResourceModel, NewResourceSlice)
+		})))).Methods("POST")
+}
+
+// setupWritableFieldRoutes opens PATCH /{resource}/{id} to the roles other than
+// admin listed in writableFields, restricted to their fields. It is documented
+// with the admin update route (see setupBodyAdminResourceRoutes).
+func setupWritableFieldRoutes(router *mux.Router, controller *controllers.Controller,
+	root string, fields map[string]map[models.Role][]string, modelTypes map[string]resourceType,
+) {
+	for resource, roles := range fields {
+		modelType, ok := modelTypes[resource]
+		if !ok {
+			panic("routes: writable fields of unknown resource " + resource)
+		}
+
+		update := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			controller.Update(w, r, resource, modelType.newModel())
+		})
+
+		// Admins keep the restrictions of the admin routes, including their policy
+		var adminUpdate http.Handler = update
+		if controller.Policy != nil {
+			adminUpdate = middlewares.Authorize(controller.Policy, policyRecord(controller))(adminUpdate)
+		}
+
+		adminUpdate = controller.AdminIPFilter.Middleware(adminUpdate)
+
+		// The other roles only update the published records of resources with a lifecycle
+		var restrictedUpdate http.Handler = update
+		if modelType.lifecycle {
+			restrictedUpdate = middlewares.PublishedOnly(update)
+		}
+
+		restrictedUpdate = middlewares.WritableFields(roles, controller.Policy, policyRecord(controller))(restrictedUpdate)
+
+		router.HandleFunc(root+resource+"/{id}", func(w http.ResponseWriter, r *http.Request) {
+			admin := middlewares.RoleFromContext(r.Context()) == string(models.AdminRole)
+
+			// The admin update is an admin-only route, on the management listener when there is one
+			if served := listenerFromContext(r.Context()); served != anyListener && admin != (served == managementListener) {
+				http.NotFound(w, r)
+
+				return
+			}
+
+			if admin {
+				adminUpdate.ServeHTTP(w, r)
+
+				return
+			}
+
+			restrictedUpdate.ServeHTTP(w, r)
+		}).Methods("PATCH")
+	}
+}
+
+// setupSavedQueryRoutes sets up the routes to manage saved queries (named views)
+// @Summary Manage saved queries
+// @Tags views
+// @Description List, create or replace, and delete named views. A view is executed with GET /{resource}?view=name.
+// @Accept json
+// @Produce json
+// @Param resource query string false "Limit the list to one resource"
+// @Param name path string false "View name (for DELETE)"
+// @Param body body models.SavedQueryRequest false "View to store (for POST)"
+// @Success 200 {array} models.SavedQuery
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /views [get]
+// @Router /views [post]
+// @Router /views/{name} [delete]
+// @security ApiKeyAuth
+func setupSavedQueryRoutes(router *mux.Router, controller *controllers.Controller) {
+	router.HandleFunc("/views", controller.ListViews).Methods("GET")
+	router.HandleFunc("/views", controller.SaveView).Methods("POST")
+	router.HandleFunc("/views/{name}", controller.DeleteView).Methods("DELETE")
+}
+
+// setupResourceListRoutes sets up the route listing the resources
+// @Summary List resources
+// @Tags views
+// @Description Names of the resources that can be listed and filtered with GET /{resource}.
+// @Produce json
+// @Success 200 {array} string
+// @Router /resources [get]
+// @security ApiKeyAuth
+func setupResourceListRoutes(router *mux.Router, controller *controllers.Controller, resources []string) {
+	router.HandleFunc("/resources", func(w http.ResponseWriter, r *http.Request) {
+		controller.ListResources(w, r, resources)
+	}).Methods("GET")
+}
+
+// setupAnnouncementRoutes sets up the route listing announcements to every authenticated user
+// @Summary List announcements
+// @Tags announcements
+// @Description Active announcements (e.g. maintenance notices). Admins can pass all=true to include scheduled and expired ones, and manage them with POST/PUT /announcements, PATCH/DELETE /announcements/{id}.
+// @Produce json
+// @Param all query bool false "Include inactive announcements (admins only)"
+// @Success 200 {array} models.Announcement
+// @Router /announcements [get]
+// @security ApiKeyAuth
+func setupAnnouncementRoutes(router *mux.Router, controller *controllers.Controller) {
+	router.HandleFunc("/announcements", controller.ListAnnouncements).Methods("GET")
+}
+
+// setupFlagRoutes sets up the route evaluating feature flags for the current user
+// @Summary Evaluate feature flags
+// @Tags flags
+// @Description State of every feature flag for the authenticated user, as a map of flag name to boolean.
+// @Produce json
+// @Success 200 {object} map[string]bool
+// @Router /flags [get]
+// @security ApiKeyAuth
+func setupFlagRoutes(router *mux.Router, controller *controllers.Controller) {
+	router.HandleFunc("/flags", controller.GetFlags).Methods("GET")
+}
+
+// setupPreferenceRoutes sets up the routes storing UI settings of the current user
+// @Summary User preferences
+// @Tags preferences
+// @Description Key-value UI settings of the authenticated user (e.g. page size, theme). PUT merges the given keys; keys set to null are removed.
+// @Accept json
+// @Produce json
+// @Param body body object false "Preferences to store (for PUT)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} models.ErrorResponse
+// @Router /me/preferences [get]
+// @Router /me/preferences [put]
+// @security ApiKeyAuth
+func setupPreferenceRoutes(router *mux.Router, controller *controllers.Controller) {
+	router.HandleFunc("/me/preferences", controller.GetPreferences).Methods("GET")
+	router.HandleFunc("/me/preferences", controller.SetPreferences).Methods("PUT")
+}
+
+// setupFavoriteRoutes sets up the routes pinning records for the current user
+// @Summary User favorites
+// @Tags preferences
+// @Description Records pinned by the authenticated user, most recent first. With expand=true, each favorite also holds
+// @Description its record; records deleted since, or not visible to the user, have none.
+// @Produce json
+// @Param resource path string false "Resource type (for POST and DELETE)" Enums(example1, example2, exampleRelational)
+// @Param id path string false "Resource ID (for POST and DELETE)"
+// @Param expand query bool false "Include the pinned records (for GET)"
+// @Success 200 {array} models.UserFavorite
+// @Success 201 {object} models.UserFavorite
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /me/favorites [get]
+// @Router /me/favorites/{resource}/{id} [post]
+// @Router /me/favorites/{resource}/{id} [delete]
+// @security ApiKeyAuth
+func setupFavoriteRoutes(router *mux.Router, controller *controllers.Controller) {
+	router.Handle("/me/favorites", middlewares.PublishedOnly(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		controller.GetFavorites(w, r, NewResourceModel)
+	}))).Methods("GET")
+	router.Handle("/me/favorites/{resource}/{id}", middlewares.PublishedOnly(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			controller.AddFavorite(w, r, NewResourceModel)
+		}))).Methods("POST")
+	router.HandleFunc("/me/favorites/{resource}/{id}", controller.RemoveFavorite).Methods("DELETE")
+}
+
+// setupInboxRoutes sets up the routes reading the notifications of the current user
+// @Summary User notification inbox
+// @Tags preferences
+// @Description Notifications sent to the authenticated user by the server hooks: a record assigned to them
+// @Description (record_assigned) and, for admins, a notification rule that could not be delivered (webhook_failed).
+// @Description GET returns the 100 most recent; the read endpoints return the remaining unread count.
+// @Produce json
+// @Param id path int false "Notification ID (for POST /me/notifications/{id}/read)"
+// @Param unread query bool false "Only return the unread notifications (for GET /me/notifications)"
+// @Success 200 {array} models.InboxNotification
+// @Success 200 {object} models.UnreadCount
+// @Failure 404 {object} models.ErrorResponse
+// @Router /me/notifications [get]
+// @Router /me/notifications/unread-count [get]
+// @Router /me/notifications/{id}/read [post]
+// @Router /me/notifications/read [post]
+// @security ApiKeyAuth
+func setupInboxRoutes(router *mux.Router, controller *controllers.Controller) {
+	router.HandleFunc("/me/notifications", controller.GetInbox).Methods("GET")
+	router.HandleFunc("/me/notifications/unread-count", controller.GetUnreadCount).Methods("GET")
+	router.HandleFunc("/me/notifications/read", controller.MarkAllRead).Methods("POST")
+	router.HandleFunc("/me/notifications/{id}/read", controller.MarkRead).Methods("POST")
+}
+
+// setupRegistrationRoutes sets up the public routes to create an account and verify its email
+// @Summary Register and verify email
+// @Tags authentication
+// @Description POST /register creates a user with the "user" role and emails a verification link when an email is given. It is only served with ALLOW_REGISTRATION=true. GET /verify confirms the email with the token of the link.
+// @Accept json
+// @Produce json
+// @Param body body models.RegisterRequest false "New user (for POST /register)"
+// @Param token query string false "Verification token (for GET /verify)"
+// @Success 200 {object} map[string]string
+// @Success 201 {object} models.User
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /register [post]
+// @Router /verify [get]
+func setupRegistrationRoutes(router *mux.Router, authController *controllers.AuthController,
+	wrap func(http.Handler) http.Handler,
+) {
+	// Self-registration is opt-in: without it, only admins create accounts
+	if authController.AllowRegistration {
+		router.Handle("/register", wrap(http.HandlerFunc(authController.Register))).Methods("POST")
+	}
+
+	router.Handle("/verify", wrap(http.HandlerFunc(authController.VerifyEmail))).Methods("GET")
+}
+
+// setupBootstrapRoutes sets up the public route creating the first admin
+// @Summary Create the first admin
+// @Tags authentication
+// @Description Creates the first admin of a database without admin, with the one-time token of BOOTSTRAP_TOKEN or
+// @Description printed at startup, and returns a JWT for it. The endpoint disables itself once an admin exists.
+// @Accept json
+// @Produce json
+// @Param body body models.BootstrapRequest true "Bootstrap token and admin account"
+// @Success 201 {object} models.JWTResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /bootstrap [post]
+func setupBootstrapRoutes(router *mux.Router, authController *controllers.AuthController,
+	wrap func(http.Handler) http.Handler,
+) {
+	router.Handle("/bootstrap", wrap(http.HandlerFunc(authController.Bootstrap))).Methods("POST")
+}
+
+// setupOAuthRoutes sets up the public route issuing access tokens to service accounts
+// @Summary Issue a service account token
+// @Tags authentication
+// @Description OAuth 2.0 client credentials grant. Send grant_type=client_credentials with client_id and client_secret (or HTTP Basic authentication); the optional space-separated scope must be a subset of the scopes of the account.
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param grant_type formData string true "Must be client_credentials"
+// @Param client_id formData string false "Client ID of the service account"
+// @Param client_secret formData string false "Client secret of the service account"
+// @Param scope formData string false "Requested scopes, space-separated"
+// @Success 200 {object} models.TokenResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Router /oauth/token [post]
+func setupOAuthRoutes(router *mux.Router, authController *controllers.AuthController,
+	wrap func(http.Handler) http.Handler,
+) {
+	router.Handle("/oauth/token", wrap(http.HandlerFunc(authController.Token))).Methods("POST")
+}
+
+// setupEmailVerificationRoutes sets up the route sending a new verification link to the current user
+// @Summary Resend email verification
+// @Tags authentication
+// @Description Sends a new verification link. An optional email in the body replaces the address of the user, which then has to be verified.
+// @Accept json
+// @Produce json
+// @Param body body object false "Optional new email, e.g. {\"email\": \"user@example.com\"}"
+// @Success 202 {object} map[string]string
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /me/verify-email [post]
+// @security ApiKeyAuth
+func setupEmailVerificationRoutes(router *mux.Router, authController *controllers.AuthController) {
+	router.HandleFunc("/me/verify-email", authController.ResendVerification).Methods("POST")
+}
+
+// setupDeviceLoginRoutes sets up the public route exchanging device tokens for session tokens
+// @Summary Login with a device token
+// @Tags authentication
+// @Description Exchanges the device token returned by a login with remember_me for a fresh session token with the
+// @Description current role of the user. Device tokens are only accepted here, not by the API routes. The failures
+// @Description count towards the login throttling of the client IP.
+// @Accept json
+// @Produce json
+// @Param body body models.DeviceLoginRequest true "Device token"
+// @Success 200 {object} models.JWTResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 429 {object} models.ErrorResponse
+// @Router /login/device [post]
+func setupDeviceLoginRoutes(router *mux.Router, authController *controllers.AuthController,
+	wrap func(http.Handler) http.Handler,
+) {
+	router.Handle("/login/device", wrap(http.HandlerFunc(authController.LoginDevice))).Methods("POST")
+}
+
+// setupDeviceRoutes sets up the routes managing the devices remembered for the current user
+// @Summary Remembered devices
+// @Tags authentication
+// @Description Lists the devices with a device token of the current user, and revokes one or all of them. A revoked
+// @Description device token can no longer be exchanged; the session tokens already issued stay valid until they
+// @Description expire.
+// @Produce json
+// @Param id path string false "Device ID (for DELETE /me/devices/{id})"
+// @Success 200 {array} models.DeviceToken
+// @Success 204 "Device revoked"
+// @Failure 404 {object} models.ErrorResponse
+// @Router /me/devices [get]
+// @Router /me/devices [delete]
+// @Router /me/devices/{id} [delete]
+// @security ApiKeyAuth
+func setupDeviceRoutes(router *mux.Router, authController *controllers.AuthController) {
+	router.HandleFunc("/me/devices", authController.ListDevices).Methods("GET")
+	router.HandleFunc("/me/devices", authController.RevokeDevices).Methods("DELETE")
+	router.HandleFunc("/me/devices/{id}", authController.RevokeDevice).Methods("DELETE")
+}
+
+// setupStepUpRoutes sets up the route authenticating the current user again
+// @Summary Step-up authentication
+// @Tags authentication
+// @Description Checks the password of the current user again and returns a fresh token. With STEP_UP_MAX_AGE, the
+// @Description destructive actions (deleting, merging or restoring records, granting roles, importing users) answer
+// @Description 401 with models.StepUpResponse to tokens issued longer ago. The wrong passwords count towards the login
+// @Description throttling of the client IP.
+// @Accept json
+// @Produce json
+// @Param body body object true "Password of the user, e.g. {\"password\": \"secret\"}"
+// @Success 200 {object} models.JWTResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Router /me/step-up [post]
+// @security ApiKeyAuth
+func setupStepUpRoutes(router *mux.Router, authController *controllers.AuthController,
+	throttle *middlewares.LoginThrottle,
+) {
+	router.Handle("/me/step-up", throttle.Middleware(http.HandlerFunc(authController.StepUp))).Methods("POST")
+}
+
+// setupServiceAccountRoutes sets up the admin routes managing service accounts
+// @Summary Service accounts
+// @Tags admin
+// @Description Non-interactive clients that get tokens from POST /oauth/token. The client secret is only returned on creation and rotation. Scopes are comma-separated "<resource>:<read|write|*>" entries, where "*" matches any resource.
+// @Accept json
+// @Produce json
+// @Param id path string false "Client ID (for operations on a specific account)"
+// @Param body body models.ServiceAccount false "Service account (for POST and PUT)"
+// @Success 200 {object} models.ServiceAccount
+// @Success 201 {object} models.ServiceAccountCredentials
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /admin/service-accounts [get]
+// @Router /admin/service-accounts [post]
+// @Router /admin/service-accounts/{id} [put]
+// @Router /admin/service-accounts/{id} [delete]
+// @Router /admin/service-accounts/{id}/secret [post]
+// @security ApiKeyAuth
+func setupServiceAccountRoutes(router *mux.Router, controller *controllers.Controller) {
+	router.HandleFunc("/admin/service-accounts", controller.ListServiceAccounts).Methods("GET")
+	router.HandleFunc("/admin/service-accounts", controller.CreateServiceAccount).Methods("POST")
+	router.HandleFunc("/admin/service-accounts/{id}", controller.UpdateServiceAccount).Methods("PUT")
+	router.HandleFunc("/admin/service-accounts/{id}", controller.DeleteServiceAccount).Methods("DELETE")
+	router.HandleFunc("/admin/service-accounts/{id}/secret", controller.RotateServiceAccountSecret).Methods("POST")
+}
+
+// setupProxyRoutes sets up the pass-through routes to the auxiliary services
+// @Summary Proxy to auxiliary services
+// @Tags proxy
+// @Description Forwards the request to the upstream service configured in PROXY_SERVICES, replacing the bearer token with signed X-Auth-* identity headers. The path after /ext/{service} is sent to the service.
+// @Param service path string true "Service name"
+// @Success 200 {string} string "Response of the service"
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 502 {object} models.ErrorResponse
+// @Router /ext/{service}/ [get]
+// @Router /ext/{service}/ [post]
+// @Router /ext/{service}/ [put]
+// @Router /ext/{service}/ [delete]
+// @security ApiKeyAuth
+func setupProxyRoutes(router *mux.Router, controller *controllers.Controller) {
+	router.HandleFunc("/ext/{service}", controller.ProxyService)
+	router.PathPrefix("/ext/{service}/").HandlerFunc(controller.ProxyService)
+}
+
+// setupStatsRoutes sets up the admin routes exposing database statistics
+// @Summary Database statistics
+// @Tags admin
+// @Description Row counts and sizes per table, per-column cardinality, null counts, min/max and sampled top values,
+// @Description and the history of the table statistics stored every STATS_SNAPSHOT_INTERVAL seconds.
+// @Produce json
+// @Param table path string false "Table name (for column statistics)"
+// @Param sample query int false "Number of rows sampled for top values" default(10000)
+// @Param top query int false "Number of top values per column" default(5)
+// @Param table query string false "Table name (for the history)"
+// @Param from query string false "Earliest snapshot, RFC 3339 (for the history)"
+// @Param to query string false "Latest snapshot, RFC 3339 (for the history)"
+// @Success 200 {object} models.TableColumnStats
+// @Success 200 {array} models.StatsSnapshot
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 503 {object} models.ErrorResponse "Too many concurrent requests of the route group"
+// @Router /admin/stats [get]
+// @Router /admin/stats/history [get]
+// @Router /admin/stats/{table}/columns [get]
+// @security ApiKeyAuth
+func setupStatsRoutes(router *mux.Router, controller *controllers.Controller) {
+	limit := func(handler http.HandlerFunc) http.Handler {
+		return controller.Concurrency.Limit(middlewares.ConcurrencyReport, handler)
+	}
+
+	router.Handle("/admin/stats", limit(controller.GetStats)).Methods("GET")
+	router.Handle("/admin/stats/history", limit(controller.GetStatsHistory)).Methods("GET")
+	router.Handle("/admin/stats/{table}/columns", limit(controller.GetColumnStats)).Methods("GET")
+}
+
+// setupSchemaGraphRoutes sets up the admin route describing the database schema
+// @Summary Schema graph
+// @Tags admin
+// @Description The tables of the models and their relationships as nodes and edges, to render an ER diagram. The
+// @Description relationships come from the GORM tags of the models and, on MySQL, the foreign keys of
+// @Description information_schema; "source" tells which of them declares each table and relationship.
+// @Produce json
+// @Success 200 {object} models.SchemaGraph
+// @Failure 500 {object} models.ErrorResponse
+// @Router /admin/schema/graph [get]
+// @security ApiKeyAuth
+func setupSchemaGraphRoutes(router *mux.Router, controller *controllers.Controller) {
+	router.Handle("/admin/schema/graph", controller.Concurrency.Limit(middlewares.ConcurrencyReport,
+		http.HandlerFunc(controller.GetSchemaGraph))).Methods("GET")
+}
+
+// setupMonitoringRoutes sets up the admin routes exposing runtime monitoring data
+// @Summary Slow routes, outbound hosts and alerts
+// @Tags admin
+// @Description Routes whose rolling p95 latency currently exceeds SLOW_ROUTE_THRESHOLD_MS, the metrics of the outbound
+// @Description HTTP calls per host (webhooks, notification channels, importers, OPA), and every active alert (slow
+// @Description routes, tables growing faster than TABLE_GROWTH_THRESHOLD, outbound hosts with an open circuit and
+// @Description dependencies down).
+// @Produce json
+// @Success 200 {array} models.SlowRoute
+// @Success 200 {array} models.OutboundHost
+// @Success 200 {object} models.Alerts
+// @Router /admin/slow-routes [get]
+// @Router /admin/outbound [get]
+// @Router /admin/alerts [get]
+// @security ApiKeyAuth
+func setupMonitoringRoutes(router *mux.Router, controller *controllers.Controller) {
+	router.HandleFunc("/admin/slow-routes", controller.GetSlowRoutes).Methods("GET")
+	router.HandleFunc("/admin/outbound", controller.GetOutboundHosts).Methods("GET")
+	router.HandleFunc("/admin/alerts", controller.GetAlerts).Methods("GET")
+}
+
+// setupLeaderRoutes sets up the admin route showing the leader of the replicas
+// @Summary Scheduler leader
+// @Tags admin
+// @Description The instance running the scheduled jobs (stats snapshots, reports, importers, export cleanup) among the
+// @Description replicas sharing the database, its lease, and whether the instance answering is the leader.
+// @Produce json
+// @Success 200 {object} models.LeaderStatus
+// @Failure 404 {object} models.ErrorResponse
+// @Router /admin/leader [get]
+// @security ApiKeyAuth
+func setupLeaderRoutes(router *mux.Router, controller *controllers.Controller) {
+	router.HandleFunc("/admin/leader", controller.GetLeader).Methods("GET")
+}
+
+// setupRouteListRoutes sets up the admin route listing every registered route
+// @Summary Registered routes
+// @Tags admin
+// @Description Every route of the router with its methods, the role required (public, user or admin) and the model type of resource routes.
+// @Produce json
+// @Success 200 {array} models.RouteInfo
+// @Router /admin/routes [get]
+// @security ApiKeyAuth
+func setupRouteListRoutes(router *mux.Router, controller *controllers.Controller,
+	root, authenticated, admin *mux.Router,
+) {
+	router.HandleFunc("/admin/routes", func(w http.ResponseWriter, r *http.Request) {
+		controller.ListRoutes(w, r, describeRoutes(root, authenticated, admin))
+	}).Methods("GET")
+}
+
+// setupOpenAPIRoutes sets up the public route of the OpenAPI document of the deployment
+// @Summary OpenAPI document of the deployment
+// @Tags documentation
+// @Description The Swagger 2.0 document of the routes registered on this instance, with a path per resource instead of
+// @Description /{resource}, typed with the model of the resource, and an operationId per operation, to generate typed
+// @Description clients from (see `make clients`). Built once, on the first request.
+// @Produce json
+// @Success 200 {object} object
+// @Failure 500 {object} models.ErrorResponse
+// @Router /openapi.json [get]
+func setupOpenAPIRoutes(router *mux.Router, controller *controllers.Controller,
+	wrap func(http.Handler) http.Handler, authenticated, admin *mux.Router,
+) {
+	// The routes are all registered by the first request
+	spec := sync.OnceValues(func() ([]byte, error) {
+		resources := make([]openapi.Resource, 0, len(resourceTypes))
+		for name, resourceType := range resourceTypes {
+			resources = append(resources, openapi.Resource{Name: name, Model: reflect.TypeOf(resourceType.model).Elem()})
+		}
+
+		document, err := openapi.Build([]byte(docs.SwaggerInfo.ReadDoc()), resources,
+			describeRoutes(router, authenticated, admin))
+		if err != nil {
+			return nil, err
+		}
+
+		return json.Marshal(document)
+	})
+
+	router.Handle("/openapi.json", wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		controller.GetOpenAPI(w, r, spec)
+	}))).Methods("GET")
+}
+
+// describeRoutes walks the router and describes every route with a path.
+//
+// The required role is "admin" for the routes of the admin subrouter, "user" for the
+// routes of the authenticated subrouter and "public" for the rest.
+func describeRoutes(root, authenticated, admin *mux.Router) []models.RouteInfo {
+	routes := []models.RouteInfo{}
+
+	_ = root.Walk(func(route *mux.Route, router *mux.Router, _ []*mux.Route) error {
+		// Subrouter entries have no path and are skipped
+		path, pathErr := route.GetPathTemplate()
+		if pathErr != nil {
+			return nil
+		}
+
+		info := models.RouteInfo{Path: path, Methods: []string{}, Role: models.RouteAccessPublic}
+
+		if pattern, err := route.GetPathRegexp(); err == nil {
+			info.Prefix = !strings.HasSuffix(pattern, "$")
+		}
+
+		if methods, err := route.GetMethods(); err == nil {
+			info.Methods = methods
+		}
+
+		switch router {
+		case admin:
+			info.Role = models.RouteAccessAdmin
+		case authenticated:
+			info.Role = models.RouteAccessUser
+		}
+
+		resource := strings.SplitN(strings.TrimPrefix(path, "/"), "/", 2)[0]
+		if model, ok := modelMap[resource]; ok {
+			info.Model = reflect.TypeOf(model).Elem().String()
+		}
+
+		routes = append(routes, info)
+
+		return nil
+	})
+
+	return routes
+}
+
+// setupNotificationRoutes sets up the admin routes managing Slack/Teams notification rules
+// @Summary Manage notification rules
+// @Tags admin
+// @Description List, create, replace and delete rules that send Slack or Microsoft Teams messages on resource events or bursts of 5xx errors.
+// @Accept json
+// @Produce json
+// @Param id path int false "Rule ID (for PUT and DELETE)"
+// @Param body body models.NotificationRule false "Rule to store (for POST and PUT)"
+// @Success 200 {array} models.NotificationRule
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /admin/notification-rules [get]
+// @Router /admin/notification-rules [post]
+// @Router /admin/notification-rules/{id} [put]
+// @Router /admin/notification-rules/{id} [delete]
+// @security ApiKeyAuth
+func setupNotificationRoutes(router *mux.Router, controller *controllers.Controller) {
+	router.HandleFunc("/admin/notification-rules", controller.ListNotificationRules).Methods("GET")
+	router.HandleFunc("/admin/notification-rules", controller.CreateNotificationRule).Methods("POST")
+	router.HandleFunc("/admin/notification-rules/{id}", controller.UpdateNotificationRule).Methods("PUT")
+	router.HandleFunc("/admin/notification-rules/{id}", controller.DeleteNotificationRule).Methods("DELETE")
+}
+
+// setupMergeRoutes sets up the admin route merging duplicate records
+// @Summary Merge records
+// @Tags admin
+// @Description Merge records into a survivor: its empty fields are filled from the merged records, the rows of other
+// @Description resources referencing them are re-pointed to it, and the merged records are deleted, in one transaction.
+// @Accept json
+// @Produce json
+// @Param resource path string true "Resource type" Enums(example1, example2, exampleRelational)
+// @Param body body models.MergeRequest true "Survivor and merged IDs"
+// @Success 200 {object} models.MergeResult
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.StepUpResponse "Step-up authentication required"
+// @Failure 404 {object} models.ErrorResponse
+// @Router /{resource}/merge [post]
+// @security ApiKeyAuth
+func setupMergeRoutes(router *mux.Router, controller *controllers.Controller,
+	root string, resources []string, modelTypes map[string]resourceType,
+) {
+	for _, resource := range resources {
+		router.HandleFunc(root+resource+"/merge", func(w http.ResponseWriter, r *http.Request) {
+			modelType, ok := modelTypes[resource]
+			if !ok {
+				http.Error(w, "Invalid resource", http.StatusBadRequest)
+
+				return
+			}
+
+			// Merging deletes the duplicates, so it needs a recent authentication
+			if !controller.StepUp.Check(w, r) {
+				return
+			}
+
+			controller.Merge(w, r, resource, modelType.newModel())
+		}).Methods("POST")
+	}
+}
+
+// setupGenerateRoutes sets up the development route creating synthetic records in the background
+// @Summary Generate test records
+// @Tags admin
+// @Description Create count synthetic records of a resource in a background job, like a bulk import, to exercise the
+// @Description pagination or load test quickly. Only registered with DEV_MODE. The values follow the column types:
+// @Description unique for the primary keys and unique columns, one of the values of enums, existing records for the
+// @Description foreign keys. Answers 202 with the job, polled at the URL of the Location header.
+// @Produce json
+// @Param resource query string true "Resource type" Enums(user, example1, example2, exampleRelational, announcements)
+// @Param count query int true "Number of records, up to 100000"
+// @Success 202 {object} models.BulkJob
+// @Header 202 {string} Location "URL of the job"
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.StepUpResponse "Step-up authentication required (users)"
+// @Failure 409 {object} models.ErrorResponse "A foreign key references an empty table"
+// @Failure 503 {object} models.ErrorResponse "Too many concurrent requests of the route group"
+// @Router /admin/generate [post]
+// @security ApiKeyAuth
+func setupGenerateRoutes(router *mux.Router, controller *controllers.Controller,
+	resources []string, modelTypes map[string]resourceType,
+) {
+	router.HandleFunc("/admin/generate", func(w http.ResponseWriter, r *http.Request) {
+		resource := r.URL.Query().Get("resource")
+
+		modelType, ok := modelTypes[resource]
+		if !ok || !slices.Contains(resources, resource) {
+			http.Error(w, "Invalid resource", http.StatusBadRequest)
+
+			return
+		}
+
+		// The generated users may be admins, so they need a recent authentication
+		if resource == "user" && !controller.StepUp.Check(w, r) {
+			return
+		}
+
+		controller.GenerateRecords(w, r, resource, modelType.newModel())
+	}).Methods("POST")
+}
+
+// setupBulkImportRoutes sets up the admin route importing many records in the background
+// @Summary Bulk import records
+// @Tags admin
+// @Description Accept a JSON array or a CSV file (header row with the JSON field names) of records, and create them in
+// @Description a background job, in chunks of DB_BATCH_SIZE records. Answers 202 with the job, whose progress (records
+// @Description processed, created and failed, with their errors) is polled at the URL of the Location header.
+// @Accept json
+// @Accept text/csv
+// @Produce json
+// @Param resource path string true "Resource type" Enums(user, example1, example2, exampleRelational, announcements)
+// @Param body body []models.DefaultRequest true "JSON array of records, or CSV file"
+// @Success 202 {object} models.BulkJob
+// @Header 202 {string} Location "URL of the job"
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.StepUpResponse "Step-up authentication required (users)"
+// @Failure 413 {object} models.ErrorResponse
+// @Failure 415 {object} models.ErrorResponse
+// @Failure 503 {object} models.ErrorResponse "Too many concurrent requests of the route group"
+// @Router /{resource}/import [post]
 // @security ApiKeyAuth
-func SetupRouter(baseController *controllers.Controller, authController *controllers.AuthController,
-	jwtSecret string,
-) *mux.Router {
-	r := mux.NewRouter()
+func setupBulkImportRoutes(router *mux.Router, controller *controllers.Controller,
+	root string, resources []string, modelTypes map[string]resourceType,
+) {
+	for _, resource := range resources {
+		router.HandleFunc(root+resource+"/import", func(w http.ResponseWriter, r *http.Request) {
+			modelType, ok := modelTypes[resource]
+			if !ok {
+				http.Error(w, "Invalid resource", http.StatusBadRequest)
 
-	r.PathPrefix("/swagger/").Handler(httpSwagger.WrapHandler)
+				return
+			}
 
-	r.HandleFunc("/login", authController.Login).Methods("POST")
+			// The imported users may be admins, so they need a recent authentication
+			if resource == "user" && !controller.StepUp.Check(w, r) {
+				return
+			}
 
-	// General API subrouter with authentication middleware
-	all := r.NewRoute().Subrouter()
-	all.Use(middlewares.AuthMiddleware(jwtSecret)) // Protect API routes
+			controller.BulkImport(w, r, resource, modelType.newModel())
+		}).Methods("POST")
+	}
+}
 
-	// Generic route setup for resources like /users, /servers, /employee, /groups, etc.
-	root := "/"
-	resources := []string{"example1", "example2", "exampleRelational"}
-	// Define a map to associate resource names with the correct model type
-	modelMap := map[string]interface{}{
-		"user":              &models.User{},
-		"example1":          &models.Example1{},
-		"example2":          &models.Example2{},
-		"exampleRelational": &models.ExampleRelational{},
+// setupBulkJobRoutes sets up the route polling the progress of a bulk import
+// @Summary Get a bulk import job
+// @Tags jobs
+// @Description Get the status and progress of a bulk import: records processed, created and failed, and the errors
+// @Description of the first 100 failed records. Users see their own jobs, admins every job.
+// @Produce json
+// @Param id path int true "Job ID"
+// @Success 200 {object} models.BulkJob
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /jobs/{id} [get]
+// @security ApiKeyAuth
+func setupBulkJobRoutes(router *mux.Router, controller *controllers.Controller) {
+	router.HandleFunc("/jobs/{id}", controller.GetBulkJob).Methods("GET")
+}
+
+// setupExportCreateRoutes sets up the admin route exporting the records of a resource in the background
+// @Summary Export records
+// @Tags admin
+// @Description Start an export of the records matching the filters and sort order of the query (as in GET /{resource}).
+// @Description Answers 202 with the export, whose progress and, once done, signed download links are read at the URL
+// @Description of the Location header. The records are written to files of at most EXPORT_PART_RECORDS records, each a
+// @Description JSON array or a CSV file with a header row, both accepted by POST /{resource}/import.
+// @Produce json
+// @Param resource path string true "Resource type" Enums(user, example1, example2, exampleRelational, announcements)
+// @Param format query string false "Format of the files" Enums(json, csv)
+// @Param anonymize query bool false "Replace the personal data of the records (see POST /admin/export)"
+// @Success 202 {object} models.ExportJob
+// @Header 202 {string} Location "URL of the export"
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 503 {object} models.ErrorResponse "Too many concurrent requests of the route group"
+// @Router /{resource}/export [post]
+// @security ApiKeyAuth
+func setupExportCreateRoutes(router *mux.Router, controller *controllers.Controller,
+	root string, resources []string, modelTypes map[string]resourceType,
+) {
+	for _, resource := range resources {
+		router.HandleFunc(root+resource+"/export", func(w http.ResponseWriter, r *http.Request) {
+			modelType, ok := modelTypes[resource]
+			if !ok {
+				http.Error(w, "Invalid resource", http.StatusBadRequest)
+
+				return
+			}
+
+			controller.CreateExport(w, r, resource, modelType.newSlice())
+		}).Methods("POST")
 	}
-	setupURLResourceRoutes(all, baseController, root, resources, modelMap)
+}
 
-	// Admin-only subrouter
-	adminOnly := all.NewRoute().Subrouter()
-	adminOnly.Use(middlewares.AdminOnly)
+// setupDumpRoutes sets up the admin route dumping every record of the admin resources in the background
+// @Summary Dump the resources
+// @Tags admin
+// @Description Start an export of every record of the resources, e.g. with anonymize=true to share a realistic dataset
+// @Description without personal data. Works like POST /{resource}/export, with the records of one resource per file,
+// @Description named after the resource. Anonymizing replaces the fields tagged with `anonymize` (hash, faker=<kind> or
+// @Description nullify) with pseudonyms consistent across the dump, so the references between records still match.
+// @Produce json
+// @Param resources query string false "Comma-separated resources to dump (default all)"
+// @Param format query string false "Format of the files" Enums(json, csv)
+// @Param anonymize query bool false "Replace the personal data of the records"
+// @Success 202 {object} models.ExportJob
+// @Header 202 {string} Location "URL of the export"
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 503 {object} models.ErrorResponse "Too many concurrent requests of the route group"
+// @Router /admin/export [post]
+// @security ApiKeyAuth
+func setupDumpRoutes(router *mux.Router, controller *controllers.Controller,
+	resources []string, modelTypes map[string]resourceType,
+) {
+	router.HandleFunc("/admin/export", func(w http.ResponseWriter, r *http.Request) {
+		controller.CreateDump(w, r, resources, func(resource string) (interface{}, bool) {
+			if !slices.Contains(resources, resource) {
+				return nil, false
+			}
 
-	// Generic admin route setup for resources
-	rootAdmin := "/"
-	resourcesAdmin := []string{"user", "example1", "example2", "exampleRelational"}
-	// Separated to have different Swagger comments
-	setupURLAdminResourceRoutes(adminOnly, baseController, rootAdmin, resourcesAdmin, modelMap)
-	setupBodyAdminResourceRoutes(adminOnly, baseController, rootAdmin, resourcesAdmin, modelMap)
+			return modelTypes[resource].newSlice(), true
+		})
+	}).Methods("POST")
+}
 
-	return r
+// setupExportRoutes sets up the route polling the progress of an export
+// @Summary Get an export
+// @Tags exports
+// @Description Get the status and progress of an export: records exported and files written. Once done, each file has
+// @Description a signed download link, valid until the export expires (EXPORT_TTL). Users see their own exports, admins
+// @Description every export.
+// @Produce json
+// @Param id path int true "Export ID"
+// @Success 200 {object} models.ExportJob
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /exports/{id} [get]
+// @security ApiKeyAuth
+func setupExportRoutes(router *mux.Router, controller *controllers.Controller) {
+	router.HandleFunc("/exports/{id}", controller.GetExport).Methods("GET")
 }
 
-// setupURLResourceRoutes sets up the common routes for CRUD operations for resources
-// @Summary Setup GET resource routes
-// @Tags user
-// @Description Setup routes for CRUD operations on resources like users, servers, employees, etc.
-// @Param resource path string true "Resource type" Enums(example1, example2, exampleRelational)
-// @Param id path string false "Resource ID (for operations on specific resources)"
-// @Router /{resource} [get]
-// @Router /{resource}/{id} [get]
+// setupExportDownloadRoutes sets up the route downloading a file of an export with its signed link
+// @Summary Download an export file
+// @Tags exports
+// @Description Download a file of an export. The signed link is the authorization, so download managers can use it
+// @Description without a token. Range requests resume interrupted downloads (206 with Content-Range), and the ETag is the
+// @Description SHA-256 of the file, for If-Range.
+// @Produce json
+// @Produce text/csv
+// @Param id path int true "Export ID"
+// @Param number path int true "File number, starting at 1"
+// @Param expires query int true "Expiry of the link (Unix time)"
+// @Param signature query string true "Signature of the link"
+// @Param Range header string false "Byte range to download, e.g. bytes=1048576-"
+// @Success 200 {file} file
+// @Success 206 {file} file
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /exports/{id}/parts/{number} [get]
+func setupExportDownloadRoutes(router *mux.Router, controller *controllers.Controller,
+	wrap func(http.Handler) http.Handler,
+) {
+	router.Handle("/exports/{id}/parts/{number}", wrap(http.HandlerFunc(controller.DownloadExportPart))).
+		Methods("GET", "HEAD")
+}
+
+// setupLifecycleRoutes sets up the admin routes changing the status of the resources with a lifecycle
+// @Summary Change the lifecycle status of a record
+// @Tags admin
+// @Description Apply a lifecycle action: publish (draft → published), unpublish (published → draft),
+// @Description archive (draft or published → archived) or restore (archived → draft).
+// @Description Users other than admins only see published records.
+// @Produce json
+// @Param resource path string true "Resource type" Enums(example1)
+// @Param id path string true "Resource ID"
+// @Param action path string true "Lifecycle action" Enums(publish, unpublish, archive, restore)
+// @Success 200 {object} models.Example1
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.StepUpResponse "Step-up authentication required (restore)"
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 423 {object} models.ErrorResponse
+// @Router /{resource}/{id}/{action} [post]
 // @security ApiKeyAuth
-func setupURLResourceRoutes(router *mux.Router, controller *controllers.Controller,
-	root string, resources []string, modelMap map[string]interface{},
+func setupLifecycleRoutes(router *mux.Router, controller *controllers.Controller,
+	root string, resources []string, modelTypes map[string]resourceType,
 ) {
 	for _, resource := range resources {
-		resourcePath := root + resource
-		log.Println("resourcePath setupResourceRoutes", resourcePath)
-
-		router.HandleFunc(resourcePath, func(w http.ResponseWriter, r *http.Request) {
-			modelType := modelMap[resource]
-			if modelType == nil {
+		router.HandleFunc(root+resource+"/{id}/{action:publish|unpublish|archive|restore}", func(w http.ResponseWriter, r *http.Request) {
+			modelType, ok := modelTypes[resource]
+			if !ok {
 				http.Error(w, "Invalid resource", http.StatusBadRequest)
 
 				return
 			}
 
-			// Ensure modelType is a pointer to a slice (e.g., *[]models.User)
-			sliceValue := reflect.New(reflect.SliceOf(reflect.TypeOf(modelType).Elem())).Interface()
+			// Restoring archived records needs a recent authentication
+			if mux.Vars(r)["action"] == "restore" && !controller.StepUp.Check(w, r) {
+				return
+			}
+
+			controller.Transition(w, r, resource, modelType.newModel())
+		}).Methods("POST")
+	}
+}
+
+// setupRecordLockRoutes sets up the admin routes locking records during edit sessions
+// @Summary Lock a record while editing it
+// @Tags admin
+// @Description Lock a record so other admins get 423 Locked when updating, deleting or transitioning it until the
+// @Description lock is released or expires (RECORD_LOCK_TTL). Locking again renews the lock. Unlocking a record
+// @Description locked by another admin requires force=true.
+// @Produce json
+// @Param resource path string true "Resource type" Enums(user, example1, example2, exampleRelational, announcements)
+// @Param id path string true "Resource ID"
+// @Param force query bool false "Release a lock held by another admin (unlock only)"
+// @Success 200 {object} models.RecordLock
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 423 {object} models.ErrorResponse
+// @Router /{resource}/{id}/lock [get]
+// @Router /{resource}/{id}/lock [post]
+// @Router /{resource}/{id}/unlock [post]
+// @security ApiKeyAuth
+func setupRecordLockRoutes(router *mux.Router, controller *controllers.Controller,
+	root string, resources []string, modelTypes map[string]resourceType,
+) {
+	for _, resource := range resources {
+		resourcePath := root + resource
 
-			// Call GetAll with the correct slice reference
-			controller.GetAll(w, r, sliceValue)
+		router.HandleFunc(resourcePath+"/{id}/lock", func(w http.ResponseWriter, r *http.Request) {
+			controller.GetLock(w, r, resource)
 		}).Methods("GET")
 
-		router.HandleFunc(resourcePath+"/{id}", func(w http.ResponseWriter, r *http.Request) {
-			modelType := modelMap[resource]
-			if modelType == nil {
+		router.HandleFunc(resourcePath+"/{id}/lock", func(w http.ResponseWriter, r *http.Request) {
+			modelType, ok := modelTypes[resource]
+			if !ok {
 				http.Error(w, "Invalid resource", http.StatusBadRequest)
 
 				return
 			}
 
-			// Call GetByID with the correct model type
-			controller.GetByID(w, r, reflect.New(reflect.TypeOf(modelType)).Interface())
-		}).Methods("GET")
+			controller.Lock(w, r, resource, modelType.newModel())
+		}).Methods("POST")
+
+		router.HandleFunc(resourcePath+"/{id}/unlock", func(w http.ResponseWriter, r *http.Request) {
+			controller.Unlock(w, r, resource)
+		}).Methods("POST")
+	}
+}
+
+// setupCounterRoutes sets up the admin route repairing the denormalized counters
+// @Summary Recompute the counters
+// @Tags admin
+// @Description Recount every denormalized counter (e.g. example1.relational_count) from the rows it counts and
+// @Description report how many records had a wrong value.
+// @Produce json
+// @Success 200 {array} models.CounterRepair
+// @Failure 500 {object} models.ErrorResponse
+// @Router /admin/counters/recompute [post]
+// @security ApiKeyAuth
+func setupCounterRoutes(router *mux.Router, controller *controllers.Controller) {
+	router.HandleFunc("/admin/counters/recompute", controller.RecomputeCounters).Methods("POST")
+}
+
+// setupReportRoutes sets up the admin routes managing the scheduled reports
+// @Summary Manage scheduled reports
+// @Tags admin
+// @Description List, create, replace and delete reports running a saved query (view) of the admin every interval
+// @Description seconds. Each run is compared with the previous one and the new, changed and removed rows are sent
+// @Description to the emails and/or webhook. The first run is a baseline and sends nothing. POST /run runs it now.
+// @Accept json
+// @Produce json
+// @Param id path int false "Report ID (for PUT, DELETE and POST /run)"
+// @Param body body models.Report false "Report to store (for POST and PUT)"
+// @Success 200 {array} models.Report
+// @Success 201 {object} models.Report
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /admin/reports [get]
+// @Router /admin/reports [post]
+// @Router /admin/reports/{id} [put]
+// @Router /admin/reports/{id} [delete]
+// @Router /admin/reports/{id}/run [post]
+// @security ApiKeyAuth
+func setupReportRoutes(router *mux.Router, controller *controllers.Controller) {
+	router.HandleFunc("/admin/reports", controller.ListReports).Methods("GET")
+	router.HandleFunc("/admin/reports", controller.CreateReport).Methods("POST")
+	router.HandleFunc("/admin/reports/{id}", controller.UpdateReport).Methods("PUT")
+	router.HandleFunc("/admin/reports/{id}", controller.DeleteReport).Methods("DELETE")
+	router.Handle("/admin/reports/{id}/run", controller.Concurrency.Limit(middlewares.ConcurrencyReport,
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			controller.RunReport(w, r, NewResourceSlice)
+		}))).Methods("POST")
+}
+
+// setupImporterRoutes sets up the admin routes managing the importers
+// @Summary Manage importers from external REST APIs
+// @Tags admin
+// @Description List, create, replace and delete importers pulling a JSON array of records from an external URL (with
+// @Description the headers of the importer, e.g. Authorization), mapping their fields to a resource with the mapping
+// @Description (resource field → dot path in the external record), and upserting them by primary key. Importers with an
+// @Description interval run on a schedule; POST /run runs one now and returns the created, updated and failed records.
+// @Accept json
+// @Produce json
+// @Param id path int false "Importer ID (for PUT, DELETE and POST /run)"
+// @Param body body models.Importer false "Importer to store (for POST and PUT)"
+// @Success 200 {array} models.Importer
+// @Success 201 {object} models.Importer
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 502 {object} models.ErrorResponse
+// @Router /admin/importers [get]
+// @Router /admin/importers [post]
+// @Router /admin/importers/{id} [put]
+// @Router /admin/importers/{id} [delete]
+// @security ApiKeyAuth
+func setupImporterRoutes(router *mux.Router, controller *controllers.Controller) {
+	router.HandleFunc("/admin/importers", controller.ListImporters).Methods("GET")
+	router.HandleFunc("/admin/importers", func(w http.ResponseWriter, r *http.Request) {
+		controller.CreateImporter(w, r, NewResourceModel)
+	}).Methods("POST")
+	router.HandleFunc("/admin/importers/{id}", func(w http.ResponseWriter, r *http.Request) {
+		controller.UpdateImporter(w, r, NewResourceModel)
+	}).Methods("PUT")
+	router.HandleFunc("/admin/importers/{id}", controller.DeleteImporter).Methods("DELETE")
+}
+
+// setupInboundHookAdminRoutes sets up the admin routes managing the inbound hooks
+// @Summary Manage inbound webhooks
+// @Tags admin
+// @Description List, create, replace and delete the inbound hooks receiving the payloads third-party services POST to
+// @Description /hooks/{name}. The secret signs the payloads (HMAC-SHA256) and is never returned (has_secret tells if
+// @Description there is one); an empty secret on PUT keeps the stored one. The record, or array of records, at
+// @Description records_path is mapped to the resource with the mapping (resource field → dot path in the payload), like
+// @Description the importers, and created, updated or upserted by primary key as the action says.
+// @Accept json
+// @Produce json
+// @Param id path int false "Inbound hook ID (for PUT and DELETE)"
+// @Param body body models.InboundHook false "Inbound hook to store (for POST and PUT)"
+// @Success 200 {array} models.InboundHook
+// @Success 201 {object} models.InboundHook
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /admin/hooks [get]
+// @Router /admin/hooks [post]
+// @Router /admin/hooks/{id} [put]
+// @Router /admin/hooks/{id} [delete]
+// @security ApiKeyAuth
+func setupInboundHookAdminRoutes(router *mux.Router, controller *controllers.Controller) {
+	router.HandleFunc("/admin/hooks", controller.ListInboundHooks).Methods("GET")
+	router.HandleFunc("/admin/hooks", func(w http.ResponseWriter, r *http.Request) {
+		controller.CreateInboundHook(w, r, NewResourceModel)
+	}).Methods("POST")
+	router.HandleFunc("/admin/hooks/{id}", func(w http.ResponseWriter, r *http.Request) {
+		controller.UpdateInboundHook(w, r, NewResourceModel)
+	}).Methods("PUT")
+	router.HandleFunc("/admin/hooks/{id}", controller.DeleteInboundHook).Methods("DELETE")
+}
+
+// setupInboundHookRoutes sets up the public route receiving the payloads of the inbound hooks
+// @Summary Receive an inbound webhook
+// @Tags hooks
+// @Description Stores the records of a JSON payload POSTed by a third-party service. The payload is authenticated by
+// @Description its signature instead of a token: the HMAC-SHA256 of the body with the secret of the hook, in its
+// @Description signature_header (default X-Signature-256) after its signature_prefix (e.g. "sha256="), hex or base64.
+// @Description Failed records (invalid, over the quota, existing on create...) are listed in "failed" without stopping
+// @Description the others, and summarized in the last_error of the hook.
+// @Accept json
+// @Produce json
+// @Param name path string true "Name of the hook"
+// @Param X-Signature-256 header string false "Signature of the payload (the header of the hook)"
+// @Success 200 {object} models.HookResult
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 413 {object} models.ErrorResponse
+// @Router /hooks/{name} [post]
+func setupInboundHookRoutes(router *mux.Router, controller *controllers.Controller,
+	wrap func(http.Handler) http.Handler,
+) {
+	router.Handle("/hooks/{name}", wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		controller.ReceiveHook(w, r, NewResourceModel)
+	}))).Methods("POST")
+}
+
+// setupEmailRuleRoutes sets up the admin routes managing the email rules
+// @Summary Manage email rules
+// @Tags admin
+// @Description List, create, replace and delete the rules converting the received emails (the EMAIL_IMAP_URL mailbox
+// @Description and the Mailgun inbound route) into records. The enabled rules are checked by priority, lowest first;
+// @Description the first whose from_pattern, to_pattern and subject_pattern (regular expressions, empty matches
+// @Description everything) match the email creates a record of its resource. The mapping maps the resource fields to
+// @Description subject, body, text, html, from, from_name, to, date, message_id, attachments (JSON array with the
+// @Description base64 content), attachment_names, or subject.<group> for a named group of the subject_pattern. The
+// @Description primary key must be mapped, e.g. to message_id, so a redelivered email is not created twice.
+// @Accept json
+// @Produce json
+// @Param id path int false "Email rule ID (for PUT and DELETE)"
+// @Param body body models.EmailRule false "Email rule to store (for POST and PUT)"
+// @Success 200 {array} models.EmailRule
+// @Success 201 {object} models.EmailRule
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /admin/email-rules [get]
+// @Router /admin/email-rules [post]
+// @Router /admin/email-rules/{id} [put]
+// @Router /admin/email-rules/{id} [delete]
+// @security ApiKeyAuth
+func setupEmailRuleRoutes(router *mux.Router, controller *controllers.Controller) {
+	router.HandleFunc("/admin/email-rules", controller.ListEmailRules).Methods("GET")
+	router.HandleFunc("/admin/email-rules", func(w http.ResponseWriter, r *http.Request) {
+		controller.CreateEmailRule(w, r, NewResourceModel)
+	}).Methods("POST")
+	router.HandleFunc("/admin/email-rules/{id}", func(w http.ResponseWriter, r *http.Request) {
+		controller.UpdateEmailRule(w, r, NewResourceModel)
+	}).Methods("PUT")
+	router.HandleFunc("/admin/email-rules/{id}", controller.DeleteEmailRule).Methods("DELETE")
+}
+
+// setupDependencyRoutes sets up the admin routes managing the dependency checks
+// @Summary Manage dependency health checks
+// @Tags admin
+// @Description List, create, replace and delete the checks of the external services the API depends on (object
+// @Description storage, OIDC provider...). Each enabled check GETs its url every interval seconds (at least 10), with
+// @Description its headers and timeout (default 5, at most 60 seconds); the dependency is up when the response has
+// @Description expected_status (0 accepts any 2xx) and down after failure_threshold failed checks in a row (default 1).
+// @Description Its state is shown in /readyz, and its changes are sent to the "dependency" notification rules and to
+// @Description ALERT_WEBHOOK_URL. POST /admin/dependencies/{id}/check checks a dependency now. Changing the url of a
+// @Description check resets its state to unknown.
+// @Accept json
+// @Produce json
+// @Param id path int false "Dependency check ID (for PUT, DELETE and check)"
+// @Param body body models.DependencyCheck false "Dependency check to store (for POST and PUT)"
+// @Success 200 {array} models.DependencyCheck
+// @Success 201 {object} models.DependencyCheck
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /admin/dependencies [get]
+// @Router /admin/dependencies [post]
+// @Router /admin/dependencies/{id} [put]
+// @Router /admin/dependencies/{id} [delete]
+// @Router /admin/dependencies/{id}/check [post]
+// @security ApiKeyAuth
+func setupDependencyRoutes(router *mux.Router, controller *controllers.Controller) {
+	router.HandleFunc("/admin/dependencies", controller.ListDependencyChecks).Methods("GET")
+	router.HandleFunc("/admin/dependencies", controller.CreateDependencyCheck).Methods("POST")
+	router.HandleFunc("/admin/dependencies/{id}", controller.UpdateDependencyCheck).Methods("PUT")
+	router.HandleFunc("/admin/dependencies/{id}", controller.DeleteDependencyCheck).Methods("DELETE")
+	router.HandleFunc("/admin/dependencies/{id}/check", controller.RunDependencyCheck).Methods("POST")
+}
+
+// setupUsageRoutes sets up the admin route reporting the usage per user and service account
+// @Summary Usage per user and service account
+// @Tags admin
+// @Description Daily rollups (UTC) of the authenticated requests of each user and service account: requests, 4xx and
+// @Description 5xx responses, error rate and bytes read and written, e.g. for internal chargeback or to spot abusive
+// @Description clients. The counts are metered by every replica and flushed every USAGE_FLUSH_INTERVAL seconds.
+// @Produce json
+// @Produce text/csv
+// @Param from query string false "First day, YYYY-MM-DD (default: 29 days before to)"
+// @Param to query string false "Last day, YYYY-MM-DD (default: today)"
+// @Param principal query string false "Username or client ID"
+// @Param account_type query string false "user or service"
+// @Param format query string false "json (default) or csv"
+// @Success 200 {array} models.UsageRollup
+// @Failure 400 {object} models.ErrorResponse
+// @Router /admin/usage [get]
+// @security ApiKeyAuth
+func setupUsageRoutes(router *mux.Router, controller *controllers.Controller) {
+	router.HandleFunc("/admin/usage", controller.GetUsage).Methods("GET")
+}
+
+// setupInboundEmailRoutes sets up the public route receiving the emails forwarded by Mailgun
+// @Summary Receive an email forwarded by Mailgun
+// @Tags hooks
+// @Description Converts an email forwarded by a Mailgun inbound route (forward action) into a record, by the email
+// @Description rules. The request is authenticated by its signature instead of a token: the hex HMAC-SHA256 of
+// @Description timestamp+token with MAILGUN_SIGNING_KEY, at most 15 minutes old. Answers 404 without the key. An email
+// @Description no rule matches, or whose record fails, is still accepted (200) with the outcome, not to be retried.
+// @Accept multipart/form-data
+// @Produce json
+// @Param timestamp formData string true "Timestamp of the signature"
+// @Param token formData string true "Random token of the signature"
+// @Param signature formData string true "Signature"
+// @Success 200 {object} models.EmailIngestResult
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /inbound/mailgun [post]
+func setupInboundEmailRoutes(router *mux.Router, controller *controllers.Controller,
+	wrap func(http.Handler) http.Handler,
+) {
+	router.Handle("/inbound/mailgun", wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		controller.ReceiveMailgun(w, r, NewResourceModel)
+	}))).Methods("POST")
+}
+
+// setupImporterRunRoutes sets up the admin route running an importer now
+// @Summary Run an importer now
+// @Tags admin
+// @Description Runs an importer whether it is scheduled or not. Records failing to import (invalid, over the quota...)
+// @Description are listed in "failed" and don't stop the others; the summary is stored in last_error.
+// @Produce json
+// @Param id path int true "Importer ID"
+// @Success 200 {object} models.ImportResult
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 502 {object} models.ErrorResponse
+// @Router /admin/importers/{id}/run [post]
+// @security ApiKeyAuth
+func setupImporterRunRoutes(router *mux.Router, controller *controllers.Controller) {
+	router.HandleFunc("/admin/importers/{id}/run", func(w http.ResponseWriter, r *http.Request) {
+		controller.RunImporter(w, r, NewResourceModel)
+	}).Methods("POST")
+}
+
+// setupValidationRuleRoutes sets up the admin routes managing the validation rules
+// @Summary Manage validation rules
+// @Tags admin
+// @Description List, create, replace and delete rules checked on create and update in addition to the struct tags,
+// @Description e.g. {"resource": "example1", "field": "field2", "rule": "regex", "params": "^[a-z]+$", "enabled": true}.
+// @Accept json
+// @Produce json
+// @Param id path int false "Rule ID (for PUT and DELETE)"
+// @Param body body models.ValidationRule false "Rule to store (for POST and PUT)"
+// @Success 200 {array} models.ValidationRule
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /admin/validation-rules [get]
+// @Router /admin/validation-rules [post]
+// @Router /admin/validation-rules/{id} [put]
+// @Router /admin/validation-rules/{id} [delete]
+// @security ApiKeyAuth
+func setupValidationRuleRoutes(router *mux.Router, controller *controllers.Controller) {
+	router.HandleFunc("/admin/validation-rules", controller.ListValidationRules).Methods("GET")
+	router.HandleFunc("/admin/validation-rules", controller.CreateValidationRule).Methods("POST")
+	router.HandleFunc("/admin/validation-rules/{id}", controller.UpdateValidationRule).Methods("PUT")
+	router.HandleFunc("/admin/validation-rules/{id}", controller.DeleteValidationRule).Methods("DELETE")
+}
+
+// setupQuotaRoutes sets up the admin routes managing the quotas and reporting their usage
+// @Summary Manage quotas
+// @Tags admin
+// @Description List, create, replace and delete the quotas capping the records each owner may create,
+// @Description e.g. {"resource": "example2", "owner": "", "max_rows": 1000}. The owner of a record is the user
+// @Description who created it; an empty owner is the default quota of the resource. Creates over the quota get 403.
+// @Accept json
+// @Produce json
+// @Param id path int false "Quota ID (for PUT and DELETE)"
+// @Param body body models.Quota false "Quota to store (for POST and PUT)"
+// @Success 200 {array} models.Quota
+// @Success 201 {object} models.Quota
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /admin/quotas [get]
+// @Router /admin/quotas [post]
+// @Router /admin/quotas/{id} [put]
+// @Router /admin/quotas/{id} [delete]
+// @security ApiKeyAuth
+func setupQuotaRoutes(router *mux.Router, controller *controllers.Controller) {
+	router.HandleFunc("/admin/quotas", controller.ListQuotas).Methods("GET")
+	router.HandleFunc("/admin/quotas", func(w http.ResponseWriter, r *http.Request) {
+		controller.CreateQuota(w, r, NewResourceModel)
+	}).Methods("POST")
+	router.HandleFunc("/admin/quotas/{id}", func(w http.ResponseWriter, r *http.Request) {
+		controller.UpdateQuota(w, r, NewResourceModel)
+	}).Methods("PUT")
+	router.HandleFunc("/admin/quotas/{id}", controller.DeleteQuota).Methods("DELETE")
+}
+
+// setupQuotaUsageRoutes sets up the admin route reporting the usage of the quotas
+// @Summary Quota usage
+// @Tags admin
+// @Description Number of records of each owner of the resources with a quota, next to the quota applying to them.
+// @Produce json
+// @Success 200 {array} models.QuotaUsage
+// @Router /admin/quotas/usage [get]
+// @security ApiKeyAuth
+func setupQuotaUsageRoutes(router *mux.Router, controller *controllers.Controller) {
+	router.HandleFunc("/admin/quotas/usage", func(w http.ResponseWriter, r *http.Request) {
+		controller.QuotaUsage(w, r, NewResourceSlice)
+	}).Methods("GET")
+}
+
+// setupConfigAuditRoutes sets up the admin route reading the audit log of the configuration changes
+// @Summary Configuration audit log
+// @Tags admin
+// @Description Changes of settings, feature flags, validation and notification rules, service accounts, quotas and
+// @Description user roles made through the admin endpoints, newest first, with their actor and the configuration
+// @Description before and after them. The entries can't be changed or deleted.
+// @Produce json
+// @Param kind query string false "Kind of configuration" Enums(setting, feature_flag, validation_rule, notification_rule, service_account, quota, user_role)
+// @Param key query string false "Key of the configuration within its kind (setting key, flag name, client ID, username or rule ID)"
+// @Param actor query string false "Username who made the changes"
+// @Param since query string false "Only the changes after this RFC 3339 timestamp"
+// @Param before query int false "Only the entries older than this entry ID, to read the next page"
+// @Param limit query int false "Number of entries (default 100, max 1000)"
+// @Success 200 {array} models.ConfigChange
+// @Failure 400 {object} models.ErrorResponse
+// @Router /admin/audit/config [get]
+// @security ApiKeyAuth
+func setupConfigAuditRoutes(router *mux.Router, controller *controllers.Controller) {
+	router.HandleFunc("/admin/audit/config", controller.GetConfigChanges).Methods("GET")
+}
+
+// setupConfigAuditIntegrityRoutes sets up the admin routes proving the audit log of the configuration changes intact
+// @Summary Verify and export the configuration audit log
+// @Tags admin
+// @Description The entries are chained by hash. /verify checks that none was altered, inserted or removed in the
+// @Description database since it was recorded, and returns the hash of the last entry to keep elsewhere. /export
+// @Description downloads a ZIP bundle of the entries (entries.ndjson) with a manifest of their count, hashes and chain
+// @Description check (manifest.json), its Ed25519 signature with AUDIT_SIGNING_KEY (manifest.sig) and the public key
+// @Description (public.pem); it answers 404 without AUDIT_SIGNING_KEY.
+// @Produce json
+// @Produce application/zip
+// @Param after query int false "Only export the entries after this entry ID (/export)"
+// @Success 200 {object} models.AuditVerification
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /admin/audit/config/verify [get]
+// @Router /admin/audit/config/export [get]
+// @security ApiKeyAuth
+func setupConfigAuditIntegrityRoutes(router *mux.Router, controller *controllers.Controller) {
+	router.Handle("/admin/audit/config/verify", controller.Concurrency.Limit(middlewares.ConcurrencyReport,
+		http.HandlerFunc(controller.VerifyConfigChanges))).Methods("GET")
+	router.Handle("/admin/audit/config/export", controller.Concurrency.Limit(middlewares.ConcurrencyExport,
+		http.HandlerFunc(controller.ExportConfigChanges))).Methods("GET")
+}
+
+// setupFeatureFlagAdminRoutes sets up the admin routes managing feature flags
+// @Summary Manage feature flags
+// @Tags admin
+// @Description List, create or replace, and delete feature flags. A flag without roles or users applies to everyone.
+// @Accept json
+// @Produce json
+// @Param name path string false "Flag name (for PUT and DELETE)"
+// @Param body body models.FeatureFlag false "Flag to store (for PUT)"
+// @Success 200 {array} models.FeatureFlag
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /admin/flags [get]
+// @Router /admin/flags/{name} [put]
+// @Router /admin/flags/{name} [delete]
+// @security ApiKeyAuth
+func setupFeatureFlagAdminRoutes(router *mux.Router, controller *controllers.Controller) {
+	router.HandleFunc("/admin/flags", controller.ListFeatureFlags).Methods("GET")
+	router.HandleFunc("/admin/flags/{name}", controller.SaveFeatureFlag).Methods("PUT")
+	router.HandleFunc("/admin/flags/{name}", controller.DeleteFeatureFlag).Methods("DELETE")
+}
+
+// setupSettingRoutes sets up the admin routes managing runtime settings
+// @Summary Manage runtime settings
+// @Tags admin
+// @Description Typed key-value parameters adjustable without redeploying. Every change is recorded in the setting history.
+// @Accept json
+// @Produce json
+// @Param key path string false "Setting key"
+// @Param body body models.Setting false "Setting to store (for PUT); value must match type (string, int, float, bool or json)"
+// @Success 200 {array} models.Setting
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /admin/settings [get]
+// @Router /admin/settings/{key} [get]
+// @Router /admin/settings/{key} [put]
+// @Router /admin/settings/{key} [delete]
+// @Router /admin/settings/{key}/history [get]
+// @security ApiKeyAuth
+func setupSettingRoutes(router *mux.Router, controller *controllers.Controller) {
+	router.HandleFunc("/admin/settings", controller.ListSettings).Methods("GET")
+	router.HandleFunc("/admin/settings/{key}", controller.GetSetting).Methods("GET")
+	router.HandleFunc("/admin/settings/{key}", controller.SaveSetting).Methods("PUT")
+	router.HandleFunc("/admin/settings/{key}", controller.DeleteSetting).Methods("DELETE")
+	router.HandleFunc("/admin/settings/{key}/history", controller.GetSettingHistory).Methods("GET")
+}
+
+// setupRuntimeConfigRoutes sets up the admin route showing the runtime configuration
+// @Summary Effective runtime configuration
+// @Tags admin
+// @Description Parameters that can be changed without restarting (log_level, rate_limit_per_minute, cors_origins, maintenance_mode), with their effective value and source (default, env or settings). Change them with PUT /admin/settings/{key}.
+// @Produce json
+// @Success 200 {array} models.ConfigValue
+// @Router /admin/config [get]
+// @security ApiKeyAuth
+func setupRuntimeConfigRoutes(router *mux.Router, controller *controllers.Controller) {
+	router.HandleFunc("/admin/config", controller.GetRuntimeConfig).Methods("GET")
+}
+
+// methodNotAllowed answers preflight requests with 204 and any other request
+// whose method does not match the route with 405 and a JSON error.
+//
+// Both responses carry an Allow header listing the methods registered for the path.
+func methodNotAllowed(router *mux.Router) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Allow", strings.Join(allowedMethods(router, r), ", "))
+
+		if r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusNoContent)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "Method not allowed"})
+	}
+}
+
+// allowedMethods returns the methods of the registered routes matching the request path.
+//
+// OPTIONS is always included because preflight requests are answered for every path.
+func allowedMethods(router *mux.Router, r *http.Request) []string {
+	registered := []string{}
+
+	_ = router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		// Routes without a method matcher return an error and are skipped
+		methods, _ := route.GetMethods()
+
+		for _, method := range methods {
+			if !slices.Contains(registered, method) {
+				registered = append(registered, method)
+			}
+		}
+
+		return nil
+	})
+
+	allowed := []string{}
+
+	for _, method := range registered {
+		req := r.Clone(r.Context())
+		req.Method = method
+
+		var match mux.RouteMatch
+		if router.Match(req, &match) && match.MatchErr == nil {
+			allowed = append(allowed, method)
+		}
+	}
+
+	if !slices.Contains(allowed, http.MethodOptions) {
+		allowed = append(allowed, http.MethodOptions)
 	}
+
+	slices.Sort(allowed)
+
+	return allowed
+}
+
+// setupUserSearchRoutes sets up the admin route searching the user directory
+// @Summary Search users
+// @Tags admin
+// @Description Searches the users by username and email patterns ("*" matches any characters), role and creation date,
+// @Description ordered by username and paginated. Users are returned without their credentials.
+// @Produce json
+// @Param username query string false "Username pattern, e.g. al*"
+// @Param email query string false "Email pattern, e.g. *@example.com"
+// @Param role query string false "Role" Enums(admin, user)
+// @Param created_after query string false "Only users created at or after this RFC 3339 timestamp"
+// @Param created_before query string false "Only users created before this RFC 3339 timestamp"
+// @Param page query int false "Page number, starting at 1"
+// @Param per_page query int false "Page size (default 50, max 1000)"
+// @Success 200 {array} models.UserSummary
+// @Header 200 {integer} X-Total-Count "Number of matching users"
+// @Failure 400 {object} models.ErrorResponse
+// @Router /user/search [get]
+// @security ApiKeyAuth
+func setupUserSearchRoutes(router *mux.Router, controller *controllers.Controller) {
+	router.Handle("/user/search", controller.Concurrency.Limit(middlewares.ConcurrencyList,
+		http.HandlerFunc(controller.SearchUsers))).Methods("GET")
 }
 
 // setupURLAdminResourceRoutes sets up the admin routes for resources like /users, /servers, /employee, /groups, etc.
 // @Summary Setup admin routes
 // @Tags admin
 // @Description Setup routes for administrative resources like users, servers, employees, etc.
-// @Param resource path string true "Resource type" Enums(user, example1, example2, exampleRelational)
+// @Param resource path string true "Resource type" Enums(user, example1, example2, exampleRelational, announcements)
 // @Param id path string false "Resource ID (for operations on specific resources)"
+// @Param If-Unmodified-Since header string false "Fail with 412 if the record was modified after this HTTP date"
+// @Failure 401 {object} models.StepUpResponse "Step-up authentication required (DELETE)"
+// @Failure 412 {object} models.ErrorResponse
 // @Router /user [get]                     // GET route: No body parameter
 // @Router /{resource}/{id} [delete]       // DELETE route: No body parameter
 // @security ApiKeyAuth
 // @security ApiKeyAuth.
 func setupURLAdminResourceRoutes(router *mux.Router, controller *controllers.Controller,
-	root string, resources []string, modelMap map[string]interface{},
+	root string, resources []string, modelTypes map[string]resourceType,
 ) {
 	for _, resource := range resources {
 		resourcePath := root + resource
@@ -128,25 +2051,29 @@ func setupURLAdminResourceRoutes(router *mux.Router, controller *controllers.Con
 		// Admin POST route to create a new resource (special handling for "users")
 		if resource == "user" {
 			router.HandleFunc(resourcePath, func(w http.ResponseWriter, r *http.Request) {
-				modelType := modelMap[resource]
-				if modelType == nil {
+				modelType, ok := modelTypes[resource]
+				if !ok {
 					http.Error(w, "Invalid resource", http.StatusBadRequest)
 
 					return
 				}
-				sliceValue := reflect.New(reflect.SliceOf(reflect.TypeOf(modelType).Elem())).Interface()
-				controller.GetAll(w, r, sliceValue)
+				controller.GetAll(w, r, resource, modelType.newSlice())
 			}).Methods("GET")
 		}
 
 		router.HandleFunc(resourcePath+"/{id}", func(w http.ResponseWriter, r *http.Request) {
-			modelType := modelMap[resource]
-			if modelType == nil {
+			modelType, ok := modelTypes[resource]
+			if !ok {
 				http.Error(w, "Invalid resource", http.StatusBadRequest)
 
 				return
 			}
-			controller.Delete(w, r, modelType)
+			// Deleting a record needs a recent authentication
+			if !controller.StepUp.Check(w, r) {
+				return
+			}
+
+			controller.Delete(w, r, resource, modelType.newModel())
 		}).Methods("DELETE")
 	}
 }
@@ -155,8 +2082,21 @@ func setupURLAdminResourceRoutes(router *mux.Router, controller *controllers.Con
 // @Summary Setup admin routes
 // @Tags admin
 // @Description Setup routes for administrative resources like users, servers, employees, etc.
-// @Param resource path string true "Resource type" Enums(user, example1, example2, exampleRelational)
+// @Param resource path string true "Resource type" Enums(user, example1, example2, exampleRelational, announcements)
 // @Param id path string false "Resource ID (for operations on specific resources)"
+// @Description Roles other than admin may PATCH the fields listed in writableFields (e.g. "user" may change example1.field2
+// @Description and example1.meta). Changing another field answers 403 with the forbidden fields; fields sent with their
+// @Description stored value are not changes.
+// @Description POST accepts an application/x-ndjson (or JSON Lines) body with one record per line, created in chunks
+// @Description while it is read; it answers 200 with the models.IngestResult, the records created and the failed ones.
+// @Accept json
+// @Accept application/x-ndjson
+// @Produce json
+// @Param If-Unmodified-Since header string false "Fail with 412 if the record was modified after this HTTP date (PUT and PATCH)"
+// @Success 200 {object} models.IngestResult
+// @Failure 401 {object} models.StepUpResponse "Step-up authentication required (granting a role, ingesting users)"
+// @Failure 403 {object} models.ForbiddenFieldsResponse
+// @Failure 412 {object} models.ErrorResponse
 // @security ApiKeyAuth
 // @Router /{resource} [post]
 // @Router /{resource} [put]
@@ -164,45 +2104,88 @@ func setupURLAdminResourceRoutes(router *mux.Router, controller *controllers.Con
 // @Param defaultRequest body models.DefaultRequest true "JSON request body for POST and PATCH operations"
 // @param example1 body models.Example1 false "Example1 object to create"
 // @param example2 body models.Example2 false "Example2 object to create"
+// @param announcements body models.Announcement false "Announcement object to create"
 // @param example2 body models.Example2 false "Example2 object to create".
 func setupBodyAdminResourceRoutes(router *mux.Router, controller *controllers.Controller,
-	root string, resources []string, modelMap map[string]interface{},
+	root string, resources []string, modelTypes map[string]resourceType,
 ) {
 	for _, resource := range resources {
 		resourcePath := root + resource
 
 		// Admin POST route to create a new resource
 		router.HandleFunc(resourcePath, func(w http.ResponseWriter, r *http.Request) {
-			modelType := modelMap[resource]
-			if modelType == nil {
+			modelType, ok := modelTypes[resource]
+			if !ok {
 				http.Error(w, "Invalid resource", http.StatusBadRequest)
 
 				return
 			}
+			// NDJSON bodies and protobuf lists hold many records, created in chunks.
+			// Their users may be admins, so they need a recent authentication
+			if controllers.IsIngestBody(r.Header.Get("Content-Type")) {
+				if resource == "user" && !controller.StepUp.Check(w, r) {
+					return
+				}
+
+				controller.Ingest(w, r, resource, modelType.newModel())
+
+				return
+			}
+
 			overwrite := false
-			controller.Create(w, r, modelType, overwrite)
+			controller.Create(w, r, resource, modelType.newModel(), overwrite)
 		}).Methods("POST")
 
 		// Admin POST route to create a new resource
 		router.HandleFunc(resourcePath, func(w http.ResponseWriter, r *http.Request) {
-			modelType := modelMap[resource]
-			if modelType == nil {
+			modelType, ok := modelTypes[resource]
+			if !ok {
 				http.Error(w, "Invalid resource", http.StatusBadRequest)
 
 				return
 			}
 			overwrite := true
-			controller.Create(w, r, modelType, overwrite)
+			controller.Create(w, r, resource, modelType.newModel(), overwrite)
 		}).Methods("PUT")
 
 		router.HandleFunc(resourcePath+"/{id}", func(w http.ResponseWriter, r *http.Request) {
-			modelType := modelMap[resource]
-			if modelType == nil {
+			modelType, ok := modelTypes[resource]
+			if !ok {
 				http.Error(w, "Invalid resource", http.StatusBadRequest)
 
 				return
 			}
-			controller.Update(w, r, modelType)
+			controller.Update(w, r, resource, modelType.newModel())
 		}).Methods("PATCH")
 	}
 }
+
+// policyRecord loads the record of the /{resource}/{id} admin routes for the authorization policy.
+func policyRecord(controller *controllers.Controller) middlewares.RecordLoader {
+	return func(r *http.Request, resource string) (string, interface{}) {
+		id := mux.Vars(r)["id"]
+
+		modelType, ok := resourceTypes[resource]
+		if id == "" || !ok {
+			return id, nil
+		}
+
+		model := modelType.newModel()
+		if err := controller.BC.GetRecordsByID(model, id); err != nil {
+			return id, nil
+		}
+
+		return id, model
+	}
+}
+
+// newModel returns a new zero-valued instance of the model type, so concurrent
+// requests never share the same struct.
+func (rt resourceType) newModel() interface{} {
+	return reflect.New(reflect.TypeOf(rt.model).Elem()).Interface()
+}
+
+// newSlice returns a pointer to a new empty slice of the model type, as passed to GetAll.
+func (rt resourceType) newSlice() interface{} {
+	return reflect.New(reflect.SliceOf(reflect.TypeOf(rt.model).Elem())).Interface()
+}
@@ -0,0 +1,318 @@
+// Package client is a small Go client of the API. It logs in with POST /login,
+// keeps the JWT fresh, and gives an *http.Client adding it to every request,
+// for the clients generated from GET /openapi.json (see `make clients`) or for
+// the calls made with Do.
+//
+// The package only uses the standard library, so importing it doesn't pull
+// the dependencies of the server.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultRefreshBefore is how long before its expiry a token is renewed.
+const defaultRefreshBefore = time.Minute
+
+// ErrNoCredentials is returned when the token expired and there is no
+// username and password to log in again.
+var ErrNoCredentials = errors.New("client: no credentials to renew the token")
+
+// Config configures a Client.
+type Config struct {
+	// BaseURL is the URL of the API (e.g. "https://api.example.com").
+	BaseURL string
+
+	// Username and Password log in with POST /login, again when the token expires.
+	Username string
+	Password string
+
+	// Token is a token used as is instead of logging in, e.g. the token of a
+	// service account. It is not renewed.
+	Token string
+
+	// HTTPClient sends the requests. nil uses http.DefaultClient.
+	HTTPClient *http.Client
+
+	// RefreshBefore is how long before its expiry the token is renewed. Zero
+	// uses one minute.
+	RefreshBefore time.Duration
+}
+
+// Client calls the API with a valid token.
+type Client struct {
+	config Config
+	http   *http.Client
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time // Zero if the token has no expiry
+}
+
+// APIError is the error of a response with a status code of 400 or more.
+type APIError struct {
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+
+	// Message is the error of the body, or the body itself if it has none.
+	Message string
+}
+
+// Error returns the status code and the message of the error.
+func (e *APIError) Error() string {
+	return fmt.Sprintf("client: HTTP %d: %s", e.StatusCode, e.Message)
+}
+
+// New creates a client.
+//
+// Returns:
+// - An error if BaseURL is empty, or if there is neither a token nor a username.
+func New(config Config) (*Client, error) {
+	if config.BaseURL == "" {
+		return nil, errors.New("client: BaseURL is required")
+	}
+
+	if config.Token == "" && config.Username == "" {
+		return nil, errors.New("client: a Token or a Username and Password are required")
+	}
+
+	config.BaseURL = strings.TrimRight(config.BaseURL, "/")
+
+	if config.HTTPClient == nil {
+		config.HTTPClient = http.DefaultClient
+	}
+
+	if config.RefreshBefore == 0 {
+		config.RefreshBefore = defaultRefreshBefore
+	}
+
+	c := &Client{config: config, token: config.Token}
+	c.expires = tokenExpiry(config.Token)
+
+	// The requests of the generated clients go through the transport adding the token
+	authenticated := *config.HTTPClient
+	authenticated.Transport = &transport{client: c, base: transportOf(config.HTTPClient)}
+	c.http = &authenticated
+
+	return c, nil
+}
+
+// HTTPClient returns an *http.Client sending the requests with the token,
+// renewed before it expires and once more if the API answers 401. Give it to
+// the generated clients (e.g. the HTTPClient of their configuration).
+func (c *Client) HTTPClient() *http.Client {
+	return c.http
+}
+
+// Token returns a valid token, logging in if there is none or if it expires
+// within RefreshBefore.
+func (c *Client) Token(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token != "" && (c.expires.IsZero() || time.Until(c.expires) > c.config.RefreshBefore) {
+		return c.token, nil
+	}
+
+	return c.login(ctx)
+}
+
+// renew logs in again after a 401, unless another request already did.
+func (c *Client) renew(ctx context.Context, rejected string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token != rejected {
+		return c.token, nil
+	}
+
+	return c.login(ctx)
+}
+
+// login gets a new token with the username and password. The caller holds mu.
+func (c *Client) login(ctx context.Context) (string, error) {
+	if c.config.Username == "" {
+		return "", ErrNoCredentials
+	}
+
+	var response struct {
+		Token string `json:"token"`
+	}
+
+	credentials := map[string]string{"username": c.config.Username, "password": c.config.Password}
+
+	// The login is sent without the transport adding the token
+	if err := c.send(ctx, c.config.HTTPClient, http.MethodPost, "/login", credentials, &response); err != nil {
+		return "", err
+	}
+
+	c.token = response.Token
+	c.expires = tokenExpiry(response.Token)
+
+	return c.token, nil
+}
+
+// Do calls the API with a JSON body and decodes the JSON response.
+//
+// Parameters:
+// - ctx: The context of the request.
+// - method: The HTTP method (e.g. http.MethodGet).
+// - path: The path and query of the route (e.g. "/example1?status=published").
+// - body: The value sent as JSON, or nil for no body.
+// - out: A pointer decoded from the response, or nil to discard it.
+//
+// Returns:
+// - An *APIError if the API answers a status code of 400 or more.
+func (c *Client) Do(ctx context.Context, method, path string, body, out interface{}) error {
+	return c.send(ctx, c.http, method, path, body, out)
+}
+
+// send sends a JSON request with an HTTP client.
+func (c *Client) send(ctx context.Context, httpClient *http.Client, method, path string, body, out interface{}) error {
+	var reader io.Reader
+
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+
+		reader = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.config.BaseURL+path, reader)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return responseError(resp)
+	}
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// responseError returns the APIError of a response.
+func responseError(resp *http.Response) error {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+
+	var body struct {
+		Error string `json:"error"`
+	}
+
+	message := strings.TrimSpace(string(data))
+	if json.Unmarshal(data, &body) == nil && body.Error != "" {
+		message = body.Error
+	}
+
+	return &APIError{StatusCode: resp.StatusCode, Message: message}
+}
+
+// tokenExpiry returns the expiry of a JWT (its "exp" claim), or zero if it
+// has none or can't be read. The signature is not checked, the API does.
+func tokenExpiry(token string) time.Time {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return time.Time{}
+	}
+
+	var claims struct {
+		Exp float64 `json:"exp"`
+	}
+
+	if json.Unmarshal(payload, &claims) != nil || claims.Exp == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(int64(claims.Exp), 0)
+}
+
+// transport adds the token of a Client to the requests.
+type transport struct {
+	client *Client
+	base   http.RoundTripper
+}
+
+// RoundTrip sends a request with the token, and once more with a new token
+// if the API answers 401 and the body can be sent again.
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.client.Token(req.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := t.base.RoundTrip(withToken(req, token))
+	if err != nil || resp.StatusCode != http.StatusUnauthorized || t.client.config.Username == "" {
+		return resp, err
+	}
+
+	if req.Body != nil && req.GetBody == nil {
+		return resp, nil
+	}
+
+	// If the token can't be renewed, the 401 is returned as is
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		if retry.Body, err = req.GetBody(); err != nil {
+			return resp, nil
+		}
+	}
+
+	if token, err = t.client.renew(req.Context(), token); err != nil {
+		return resp, nil
+	}
+
+	_ = resp.Body.Close()
+
+	return t.base.RoundTrip(withToken(retry, token))
+}
+
+// withToken returns a copy of a request with the token, since a RoundTripper
+// must not change the request it is given.
+func withToken(req *http.Request, token string) *http.Request {
+	authorized := req.Clone(req.Context())
+	authorized.Header.Set("Authorization", "Bearer "+token)
+
+	return authorized
+}
+
+// transportOf returns the transport of an HTTP client.
+func transportOf(httpClient *http.Client) http.RoundTripper {
+	if httpClient.Transport != nil {
+		return httpClient.Transport
+	}
+
+	return http.DefaultTransport
+}
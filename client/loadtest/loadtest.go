@@ -0,0 +1,365 @@
+// Package loadtest sends a mix of CRUD requests to a running instance of the
+// API at a constant rate, like vegeta, and reports the latency percentiles and
+// the error rates of each operation, as JSON for the CI to compare the runs
+// (see Compare).
+//
+// Like the client package, it only uses the standard library.
+package loadtest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/r4ulcl/api_template/client"
+)
+
+// Operations of the load test.
+const (
+	// OpList lists a page of records: GET /{resource}?page=N&per_page=PageSize.
+	OpList = "list"
+
+	// OpGet reads a record: GET /{resource}/{id}.
+	OpGet = "get"
+
+	// OpCreate creates a record with CreateBody: POST /{resource}.
+	OpCreate = "create"
+
+	// OpUpdate updates a record with UpdateBody: PATCH /{resource}/{id}.
+	OpUpdate = "update"
+)
+
+// idPlaceholder is replaced by a unique ID in CreateBody and UpdateBody.
+const idPlaceholder = "{id}"
+
+// defaultTimeout is the timeout of the requests when Timeout is zero.
+const defaultTimeout = 10 * time.Second
+
+// Config configures a load test.
+type Config struct {
+	// Client sends the requests, authenticated. The creates and updates need an admin.
+	Client *client.Client
+
+	// BaseURL is the URL of the API (e.g. "http://localhost:8080").
+	BaseURL string
+
+	// Resource is the resource of the requests (e.g. "example1").
+	Resource string
+
+	// Mix is the relative weight of each operation (e.g. {"list": 4, "get": 3}).
+	Mix map[string]int
+
+	// Rate is the requests sent per second. Zero sends them as fast as the workers answer.
+	Rate float64
+
+	// Duration is how long the requests are sent.
+	Duration time.Duration
+
+	// Workers is the largest number of requests in flight. The requests the
+	// rate calls for while every worker is busy are dropped and counted.
+	Workers int
+
+	// Timeout is the timeout of each request. Zero uses 10 seconds.
+	Timeout time.Duration
+
+	// PageSize is the per_page of the lists.
+	PageSize int
+
+	// Seed is the number of records created before the test, for the gets and updates.
+	Seed int
+
+	// CreateBody is the JSON body of the creates, whose {id} is replaced by a
+	// unique ID, also the ID of the record (e.g. {"field1":"{id}"}).
+	CreateBody string
+
+	// UpdateBody is the JSON body of the updates, whose {id} is replaced by a unique value.
+	UpdateBody string
+
+	// Cleanup deletes the records created by the test once it ends.
+	Cleanup bool
+}
+
+// runner holds the state of a running load test.
+type runner struct {
+	config Config
+	http   *http.Client
+	prefix string
+	ops    []string
+
+	mu       sync.Mutex
+	ids      []string
+	sequence int
+	results  map[string]*operationResults
+}
+
+// Run seeds the records, sends the requests of a load test and reports them.
+// The seed and cleanup requests are not measured.
+//
+// Parameters:
+// - ctx: Stops the test early when canceled.
+// - config: The test to run.
+//
+// Returns:
+// - The report of the requests sent.
+// - An error if the configuration is invalid or the seed records can't be created.
+func Run(ctx context.Context, config Config) (*Report, error) {
+	r := &runner{
+		config:  config,
+		http:    config.Client.HTTPClient(),
+		prefix:  fmt.Sprintf("loadtest_%d", time.Now().Unix()),
+		results: map[string]*operationResults{},
+	}
+
+	for _, op := range []string{OpList, OpGet, OpCreate, OpUpdate} {
+		weight := config.Mix[op]
+		if weight < 0 {
+			return nil, fmt.Errorf("the weight of %s must not be negative", op)
+		}
+
+		for range weight {
+			r.ops = append(r.ops, op)
+		}
+	}
+
+	for op := range config.Mix {
+		if op != OpList && op != OpGet && op != OpCreate && op != OpUpdate {
+			return nil, fmt.Errorf("unknown operation %q", op)
+		}
+	}
+
+	switch {
+	case len(r.ops) == 0:
+		return nil, errors.New("the mix has no operation")
+	case config.Workers < 1:
+		return nil, errors.New("workers must be at least 1")
+	case config.Duration <= 0:
+		return nil, errors.New("duration must be positive")
+	}
+
+	needsIDs := config.Mix[OpGet] > 0 || config.Mix[OpUpdate] > 0
+	if needsIDs && config.Seed < 1 {
+		return nil, errors.New("the gets and updates need at least one seed record")
+	}
+
+	if r.config.Timeout <= 0 {
+		r.config.Timeout = defaultTimeout
+	}
+
+	httpClient := *r.http
+	httpClient.Timeout = r.config.Timeout
+	r.http = &httpClient
+
+	for range config.Seed {
+		status, err := r.create(ctx)
+		if err == nil && status >= http.StatusBadRequest {
+			err = fmt.Errorf("POST /%s answered %d", config.Resource, status)
+		}
+
+		if err != nil {
+			r.cleanup()
+
+			return nil, fmt.Errorf("seed: %w", err)
+		}
+	}
+
+	for _, op := range r.ops {
+		r.results[op] = &operationResults{statuses: map[string]int{}}
+	}
+
+	started := time.Now()
+	dropped := r.attack(ctx)
+	elapsed := time.Since(started)
+
+	report := r.report(started, elapsed, dropped)
+
+	if config.Cleanup {
+		r.cleanup()
+	}
+
+	return report, nil
+}
+
+// attack sends the requests for the duration of the test, at the rate of the
+// configuration, with at most Workers requests in flight. The requests in
+// flight at the end of the test are waited for.
+//
+// Returns:
+// - The number of requests dropped because every worker was busy.
+func (r *runner) attack(ctx context.Context) int {
+	stop, cancel := context.WithTimeout(ctx, r.config.Duration)
+	defer cancel()
+
+	ticks := make(chan struct{})
+
+	var workers sync.WaitGroup
+
+	for range r.config.Workers {
+		workers.Add(1)
+
+		go func() {
+			defer workers.Done()
+
+			for range ticks {
+				r.hit(ctx)
+			}
+		}()
+	}
+
+	dropped := 0
+
+	if r.config.Rate <= 0 {
+		// Closed model: each worker sends its next request when the previous one is answered
+		for stop.Err() == nil {
+			select {
+			case ticks <- struct{}{}:
+			case <-stop.Done():
+			}
+		}
+	} else {
+		// Open model: the requests are sent on schedule, however slow the answers
+		ticker := time.NewTicker(max(time.Duration(float64(time.Second)/r.config.Rate), time.Microsecond))
+
+		for stop.Err() == nil {
+			select {
+			case <-ticker.C:
+				select {
+				case ticks <- struct{}{}:
+				default:
+					dropped++
+				}
+			case <-stop.Done():
+			}
+		}
+
+		ticker.Stop()
+	}
+
+	close(ticks)
+	workers.Wait()
+
+	return dropped
+}
+
+// hit sends a request of a random operation of the mix and records its result.
+func (r *runner) hit(ctx context.Context) {
+	op := r.ops[rand.IntN(len(r.ops))]
+
+	start := time.Now()
+
+	var (
+		status int
+		err    error
+	)
+
+	switch op {
+	case OpList:
+		page := rand.IntN(10) + 1
+		status, err = r.send(ctx, http.MethodGet,
+			fmt.Sprintf("/%s?page=%d&per_page=%d", r.config.Resource, page, r.config.PageSize), "")
+	case OpGet:
+		status, err = r.send(ctx, http.MethodGet, r.recordPath(r.randomID()), "")
+	case OpCreate:
+		status, err = r.create(ctx)
+	case OpUpdate:
+		status, err = r.send(ctx, http.MethodPatch, r.recordPath(r.randomID()),
+			strings.ReplaceAll(r.config.UpdateBody, idPlaceholder, r.nextID()))
+	}
+
+	// The requests interrupted by canceling the test are not counted
+	if ctx.Err() != nil && errors.Is(err, ctx.Err()) {
+		return
+	}
+
+	r.mu.Lock()
+	r.results[op].add(time.Since(start), status, err)
+	r.mu.Unlock()
+}
+
+// create creates a record with a new ID, kept for the gets, updates and cleanup.
+func (r *runner) create(ctx context.Context) (int, error) {
+	id := r.nextID()
+
+	status, err := r.send(ctx, http.MethodPost, "/"+r.config.Resource,
+		strings.ReplaceAll(r.config.CreateBody, idPlaceholder, id))
+	if err == nil && status < http.StatusBadRequest {
+		r.mu.Lock()
+		r.ids = append(r.ids, id)
+		r.mu.Unlock()
+	}
+
+	return status, err
+}
+
+// send sends a request and reads its response, returning its status code. A
+// status code of 400 or more is not an error, so it is reported by code.
+func (r *runner) send(ctx context.Context, method, path, body string) (int, error) {
+	var reader io.Reader
+	if body != "" {
+		reader = bytes.NewReader([]byte(body))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimRight(r.config.BaseURL, "/")+path, reader)
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := r.http.Do(req)
+	if err != nil {
+		return 0, err
+	}
+
+	// The body is read whole, as a client would, so the latency includes the transfer
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+
+	return resp.StatusCode, nil
+}
+
+// nextID returns a new ID, unique to the test.
+func (r *runner) nextID() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.sequence++
+
+	// The API splits the IDs of composite keys on "-"
+	return r.prefix + "_" + strconv.Itoa(r.sequence)
+}
+
+// randomID returns the ID of a random record created by the test.
+func (r *runner) randomID() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.ids[rand.IntN(len(r.ids))]
+}
+
+// recordPath returns the path of a record.
+func (r *runner) recordPath(id string) string {
+	return "/" + r.config.Resource + "/" + url.PathEscape(id)
+}
+
+// cleanup deletes the records created by the test, ignoring the errors.
+func (r *runner) cleanup() {
+	for _, id := range r.ids {
+		ctx, cancel := context.WithTimeout(context.Background(), r.config.Timeout)
+		_, _ = r.send(ctx, http.MethodDelete, r.recordPath(id), "")
+
+		cancel()
+	}
+}
@@ -0,0 +1,283 @@
+package loadtest
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// statusTransportError is the status of the requests without a response (e.g. timeouts).
+const statusTransportError = "error"
+
+// Report is the result of a load test, written as JSON for the CI.
+type Report struct {
+	// Resource is the resource of the requests.
+	Resource string `json:"resource"`
+
+	// StartedAt is when the requests started.
+	StartedAt time.Time `json:"started_at"`
+
+	// DurationSeconds is how long the requests were sent, with the last answers.
+	DurationSeconds float64 `json:"duration_seconds"`
+
+	// Rate is the requests per second asked for, 0 for as fast as possible.
+	Rate float64 `json:"rate"`
+
+	// Throughput is the requests answered per second.
+	Throughput float64 `json:"throughput"`
+
+	// Dropped is the number of requests not sent because every worker was busy.
+	Dropped int `json:"dropped"`
+
+	// Total holds the results of every request.
+	Total OperationReport `json:"total"`
+
+	// Operations holds the results of the requests of each operation.
+	Operations map[string]OperationReport `json:"operations"`
+}
+
+// OperationReport holds the results of the requests of an operation.
+type OperationReport struct {
+	// Requests is the number of requests answered or failed.
+	Requests int `json:"requests"`
+
+	// Errors is the number of requests failed or answered with a status code of 400 or more.
+	Errors int `json:"errors"`
+
+	// ErrorRate is Errors divided by Requests.
+	ErrorRate float64 `json:"error_rate"`
+
+	// Latency holds the latency percentiles, in milliseconds.
+	Latency Latency `json:"latency_ms"`
+
+	// StatusCodes counts the requests per status code, "error" for those without a response.
+	StatusCodes map[string]int `json:"status_codes"`
+}
+
+// Latency holds the latency percentiles of requests, in milliseconds.
+type Latency struct {
+	Mean float64 `json:"mean"`
+	P50  float64 `json:"p50"`
+	P95  float64 `json:"p95"`
+	P99  float64 `json:"p99"`
+	Max  float64 `json:"max"`
+}
+
+// operationResults collects the results of the requests of an operation.
+type operationResults struct {
+	latencies []time.Duration
+	errors    int
+	statuses  map[string]int
+}
+
+// add records the result of a request.
+func (o *operationResults) add(latency time.Duration, status int, err error) {
+	o.latencies = append(o.latencies, latency)
+
+	switch {
+	case err != nil:
+		o.errors++
+		o.statuses[statusTransportError]++
+	default:
+		if status >= 400 {
+			o.errors++
+		}
+
+		o.statuses[strconv.Itoa(status)]++
+	}
+}
+
+// summary returns the report of the results.
+func (o *operationResults) summary() OperationReport {
+	report := OperationReport{
+		Requests:    len(o.latencies),
+		Errors:      o.errors,
+		Latency:     latencyOf(o.latencies),
+		StatusCodes: o.statuses,
+	}
+
+	if report.Requests > 0 {
+		report.ErrorRate = float64(report.Errors) / float64(report.Requests)
+	}
+
+	return report
+}
+
+// report builds the report of the requests sent.
+func (r *runner) report(started time.Time, elapsed time.Duration, dropped int) *Report {
+	report := &Report{
+		Resource:        r.config.Resource,
+		StartedAt:       started.UTC(),
+		DurationSeconds: elapsed.Seconds(),
+		Rate:            r.config.Rate,
+		Dropped:         dropped,
+		Operations:      map[string]OperationReport{},
+	}
+
+	total := &operationResults{statuses: map[string]int{}}
+
+	for op, results := range r.results {
+		report.Operations[op] = results.summary()
+
+		total.latencies = append(total.latencies, results.latencies...)
+		total.errors += results.errors
+
+		for status, count := range results.statuses {
+			total.statuses[status] += count
+		}
+	}
+
+	report.Total = total.summary()
+
+	if elapsed > 0 {
+		report.Throughput = float64(report.Total.Requests) / elapsed.Seconds()
+	}
+
+	return report
+}
+
+// latencyOf returns the percentiles of latencies (nearest rank).
+func latencyOf(latencies []time.Duration) Latency {
+	if len(latencies) == 0 {
+		return Latency{}
+	}
+
+	sorted := slices.Clone(latencies)
+	slices.Sort(sorted)
+
+	var sum time.Duration
+	for _, latency := range sorted {
+		sum += latency
+	}
+
+	percentile := func(p float64) float64 {
+		rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+
+		return milliseconds(sorted[min(max(rank, 0), len(sorted)-1)])
+	}
+
+	return Latency{
+		Mean: milliseconds(sum / time.Duration(len(sorted))),
+		P50:  percentile(0.50),
+		P95:  percentile(0.95),
+		P99:  percentile(0.99),
+		Max:  milliseconds(sorted[len(sorted)-1]),
+	}
+}
+
+// milliseconds converts a duration to milliseconds, rounded to microseconds.
+func milliseconds(d time.Duration) float64 {
+	return math.Round(float64(d.Microseconds())) / 1000
+}
+
+// Print writes the report as a table, for humans.
+func (r *Report) Print(w io.Writer) {
+	fmt.Fprintf(w, "%s: %d requests in %.1fs, %.1f req/s", r.Resource, r.Total.Requests, r.DurationSeconds,
+		r.Throughput)
+
+	if r.Dropped > 0 {
+		fmt.Fprintf(w, ", %d dropped (every worker busy)", r.Dropped)
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "%-8s %9s %8s %10s %10s %10s %10s\n", "op", "requests", "errors", "p50 ms", "p95 ms", "p99 ms",
+		"max ms")
+
+	ops := make([]string, 0, len(r.Operations))
+	for op := range r.Operations {
+		ops = append(ops, op)
+	}
+
+	sort.Strings(ops)
+
+	for _, op := range append(ops, "total") {
+		result, ok := r.Operations[op]
+		if !ok {
+			result = r.Total
+		}
+
+		fmt.Fprintf(w, "%-8s %9d %7.2f%% %10.2f %10.2f %10.2f %10.2f\n", op, result.Requests, result.ErrorRate*100,
+			result.Latency.P50, result.Latency.P95, result.Latency.P99, result.Latency.Max)
+	}
+}
+
+// Thresholds are the limits of a load test for the CI. The zero values disable them.
+type Thresholds struct {
+	// MaxErrorRate is the largest error rate of the requests (e.g. 0.01 for 1%).
+	MaxErrorRate float64
+
+	// MaxP99 is the largest 99th percentile latency of the requests.
+	MaxP99 time.Duration
+
+	// MaxRegression is the largest increase of the p95 and p99 latencies of
+	// each operation over the baseline (e.g. 0.2 for 20%).
+	MaxRegression float64
+
+	// MaxErrorRateIncrease is the largest increase of the error rate of each
+	// operation over the baseline, in absolute terms (e.g. 0.01 for 1 point).
+	MaxErrorRateIncrease float64
+}
+
+// Compare checks a report against the thresholds and, if not nil, the report
+// of a baseline run (e.g. of the main branch), for a performance regression gate.
+//
+// Returns:
+// - The thresholds exceeded, empty if the report passes.
+func Compare(report, baseline *Report, thresholds Thresholds) []string {
+	failures := []string{}
+
+	if thresholds.MaxErrorRate > 0 && report.Total.ErrorRate > thresholds.MaxErrorRate {
+		failures = append(failures, fmt.Sprintf("error rate %.2f%% above %.2f%%",
+			report.Total.ErrorRate*100, thresholds.MaxErrorRate*100))
+	}
+
+	if maxP99 := milliseconds(thresholds.MaxP99); maxP99 > 0 && report.Total.Latency.P99 > maxP99 {
+		failures = append(failures, fmt.Sprintf("p99 %.2fms above %.2fms", report.Total.Latency.P99, maxP99))
+	}
+
+	if baseline == nil {
+		return failures
+	}
+
+	ops := make([]string, 0, len(report.Operations))
+	for op := range report.Operations {
+		ops = append(ops, op)
+	}
+
+	sort.Strings(ops)
+
+	for _, op := range ops {
+		current := report.Operations[op]
+
+		previous, ok := baseline.Operations[op]
+		if !ok || previous.Requests == 0 || current.Requests == 0 {
+			continue
+		}
+
+		if thresholds.MaxRegression > 0 {
+			for _, p := range []struct {
+				name              string
+				current, previous float64
+			}{
+				{"p95", current.Latency.P95, previous.Latency.P95},
+				{"p99", current.Latency.P99, previous.Latency.P99},
+			} {
+				if p.previous > 0 && p.current > p.previous*(1+thresholds.MaxRegression) {
+					failures = append(failures, fmt.Sprintf("%s %s %.2fms is %.0f%% above the baseline %.2fms",
+						op, p.name, p.current, (p.current/p.previous-1)*100, p.previous))
+				}
+			}
+		}
+
+		if thresholds.MaxErrorRateIncrease > 0 && current.ErrorRate > previous.ErrorRate+thresholds.MaxErrorRateIncrease {
+			failures = append(failures, fmt.Sprintf("%s error rate %.2f%% above the baseline %.2f%%",
+				op, current.ErrorRate*100, previous.ErrorRate*100))
+		}
+	}
+
+	return failures
+}
@@ -0,0 +1,25 @@
+package database
+
+import (
+	"time"
+
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+// GetActiveAnnouncements returns the announcements active at the given time,
+// newest first.
+//
+// Returns:
+// - The active announcements.
+// - An error if the query fails.
+func (bc *BaseController) GetActiveAnnouncements(now time.Time) ([]models.Announcement, error) {
+	announcements := []models.Announcement{}
+
+	err := bc.DB.
+		Where("starts_at IS NULL OR starts_at <= ?", now).
+		Where("ends_at IS NULL OR ends_at > ?", now).
+		Order("created_at DESC").
+		Find(&announcements).Error
+
+	return announcements, err
+}
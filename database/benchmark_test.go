@@ -0,0 +1,31 @@
+package database_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/r4ulcl/api_template/testsupport"
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+// benchBulkSize is the number of records inserted per iteration by BenchmarkCreateBulk.
+const benchBulkSize = 100
+
+// BenchmarkCreateBulk measures inserting benchBulkSize records in one call.
+func BenchmarkCreateBulk(b *testing.B) {
+	srv := testsupport.NewTestServer(b)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := range b.N {
+		records := make([]models.Example1, benchBulkSize)
+		for j := range records {
+			records[j] = models.Example1{Field1: fmt.Sprintf("bench_%d_%d", i, j), Field2: "value"}
+		}
+
+		if _, err := srv.Controller.BC.CreateOrUpdateRecord(&records, false); err != nil {
+			b.Fatalf("bulk create: %v", err)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+)
+
+// defaultBatchSize is the number of rows inserted per statement when BatchSize is zero.
+const defaultBatchSize = 500
+
+// bulkLogInterval is the number of records between two progress logs of a
+// CreateRecords call without progress function.
+const bulkLogInterval = 10000
+
+// BulkInsertError is returned by CreateRecords when a chunk fails. The
+// records before Start were inserted, those from Start on were not.
+type BulkInsertError struct {
+	// Start is the index of the first record of the chunk that failed.
+	Start int
+
+	// End is the index of the last record of the chunk that failed.
+	End int
+
+	// Err is the error of the insert.
+	Err error
+}
+
+// Error describes the failed chunk.
+func (e *BulkInsertError) Error() string {
+	return fmt.Sprintf("records %d to %d: %v", e.Start, e.End, e.Err)
+}
+
+// Unwrap returns the error of the insert.
+func (e *BulkInsertError) Unwrap() error {
+	return e.Err
+}
+
+// BulkBatchSize returns the number of rows inserted per statement by CreateRecords.
+func (bc *BaseController) BulkBatchSize() int {
+	if bc.BatchSize <= 0 {
+		return defaultBatchSize
+	}
+
+	return bc.BatchSize
+}
+
+// CreateRecords inserts a slice of records in chunks of BatchSize rows, one
+// INSERT per chunk, so very large arrays stay below max_allowed_packet and
+// each statement holds its locks briefly. Each chunk is retried on transient
+// errors (see RetryPolicy). Records of models with counters or sequences are
+// inserted one by one, as CreateOrUpdateRecord does.
+//
+// The chunks are not inserted in a single transaction: if a chunk fails, the
+// previous ones stay inserted.
+//
+// Parameters:
+// - records: A pointer to a slice of the model (e.g. *[]models.Example1).
+// - progress: Called after each chunk with the records inserted so far and the total. It is optional.
+//
+// Returns:
+// - A *BulkInsertError with the range of the chunk that failed.
+func (bc *BaseController) CreateRecords(records interface{}, progress func(done, total int)) error {
+	slice := reflect.ValueOf(records)
+	if slice.Kind() != reflect.Ptr || slice.Elem().Kind() != reflect.Slice {
+		return errors.New("records must be a pointer to a slice")
+	}
+
+	slice = slice.Elem()
+	total := slice.Len()
+
+	sch, err := bc.modelSchema(reflect.New(slice.Type().Elem()).Interface())
+	if err != nil {
+		return err
+	}
+
+	size := bc.BulkBatchSize()
+
+	// The counters and the sequences are updated record by record
+	if bc.hasCounters(sch) || len(sequenceFields(sch)) > 0 {
+		size = 1
+	}
+
+	for start := 0; start < total; start += size {
+		end := min(start+size, total)
+
+		if size == 1 {
+			_, err = bc.CreateOrUpdateRecord(slice.Index(start).Addr().Interface(), false)
+		} else {
+			// The chunk shares the array of the slice, so the generated keys are set on the records
+			chunk := reflect.New(slice.Type())
+			chunk.Elem().Set(slice.Slice(start, end))
+
+			err = bc.retry(func() error { return bc.DB.Create(chunk.Interface()).Error })
+		}
+
+		if err != nil {
+			return &BulkInsertError{Start: start, End: end - 1, Err: err}
+		}
+
+		if progress != nil {
+			progress(end, total)
+		} else if end < total && end/bulkLogInterval != start/bulkLogInterval {
+			log.Printf("Inserted %d/%d %s records", end, total, sch.Table)
+		}
+	}
+
+	return nil
+}
+
+// ForEachBatch reads the records matching the options in batches, and calls a
+// function with each batch, e.g. to export a table without loading it whole.
+// The records are ordered by the sort of the options, then by primary key, so
+// every record is read once while the table is not modified.
+//
+// Parameters:
+// - model: A pointer to a slice of the model (e.g. *[]models.Example1), used for its type.
+// - opts: The filters, sort order and fields of the query. Limit and Offset are ignored.
+// - size: The number of records per batch.
+// - fn: Called with a pointer to a slice of each batch of records. Its error stops the reading.
+//
+// Returns:
+// - The error of the query or of fn.
+func (bc *BaseController) ForEachBatch(model interface{}, opts QueryOptions, size int,
+	fn func(batch interface{}) error,
+) error {
+	sch, err := bc.modelSchema(model)
+	if err != nil {
+		return err
+	}
+
+	opts.Sort = append([]string{}, opts.Sort...)
+	for _, field := range sch.PrimaryFields {
+		opts.Sort = append(opts.Sort, field.DBName)
+	}
+
+	sliceType := reflect.TypeOf(model).Elem()
+
+	for offset := 0; ; offset += size {
+		batch := reflect.New(sliceType)
+
+		opts.Limit = size
+		opts.Offset = offset
+
+		if err := bc.GetAllRecords(batch.Interface(), opts); err != nil {
+			return err
+		}
+
+		count := batch.Elem().Len()
+		if count == 0 {
+			return nil
+		}
+
+		if err := fn(batch.Interface()); err != nil {
+			return err
+		}
+
+		if count < size {
+			return nil
+		}
+	}
+}
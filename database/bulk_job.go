@@ -0,0 +1,57 @@
+package database
+
+import (
+	"errors"
+	"time"
+
+	"github.com/r4ulcl/api_template/utils/models"
+	"gorm.io/gorm"
+)
+
+// ErrBulkJobNotFound is returned when a bulk job does not exist.
+var ErrBulkJobNotFound = errors.New("job not found")
+
+// errBulkJobInterrupted is the error of the jobs that were running when the server stopped.
+const errBulkJobInterrupted = "interrupted by a restart of the server, the records not processed were not created"
+
+// CreateBulkJob stores a new queued job.
+func (bc *BaseController) CreateBulkJob(job *models.BulkJob) error {
+	job.ID = 0
+	job.Status = models.BulkJobQueued
+	job.Failures = []models.ImportFailure{}
+
+	return bc.DB.Create(job).Error
+}
+
+// GetBulkJob returns a job by ID.
+//
+// Returns:
+// - ErrBulkJobNotFound if no job has the given ID.
+func (bc *BaseController) GetBulkJob(id uint) (models.BulkJob, error) {
+	var job models.BulkJob
+
+	err := bc.DB.First(&job, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return job, ErrBulkJobNotFound
+	}
+
+	return job, err
+}
+
+// SaveBulkJob stores the progress of a job.
+func (bc *BaseController) SaveBulkJob(job *models.BulkJob) error {
+	return bc.DB.Save(job).Error
+}
+
+// FailInterruptedBulkJobs marks the jobs left queued or running by a previous
+// run of the server as failed, as their payload was only kept in memory.
+//
+// Returns:
+// - The number of jobs marked as failed.
+func (bc *BaseController) FailInterruptedBulkJobs(now time.Time) (int64, error) {
+	res := bc.DB.Model(&models.BulkJob{}).
+		Where("status IN ?", []models.BulkJobStatus{models.BulkJobQueued, models.BulkJobRunning}).
+		Updates(map[string]interface{}{"status": models.BulkJobFailed, "error": errBulkJobInterrupted, "finished_at": now})
+
+	return res.RowsAffected, res.Error
+}
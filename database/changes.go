@@ -0,0 +1,188 @@
+package database
+
+import (
+	"errors"
+	"reflect"
+	"time"
+
+	"github.com/r4ulcl/api_template/utils/models"
+	"gorm.io/gorm"
+)
+
+// DeletionRetention is how long record deletions are kept for the change feeds.
+const DeletionRetention = 30 * 24 * time.Hour
+
+// ErrChangesNotTracked is returned when a model has no UpdatedAt field.
+var ErrChangesNotTracked = errors.New("the resource does not track changes")
+
+// TracksChanges reports whether the records of a model have an UpdatedAt field,
+// needed to list the records changed after a date.
+func TracksChanges(model interface{}) bool {
+	modelType := reflect.TypeOf(model)
+	for modelType.Kind() == reflect.Pointer || modelType.Kind() == reflect.Slice {
+		modelType = modelType.Elem()
+	}
+
+	field, ok := modelType.FieldByName("UpdatedAt")
+
+	return ok && field.Type == reflect.TypeOf(time.Time{})
+}
+
+// backfillUpdatedAt sets the UpdatedAt field of the rows stored before it
+// existed, so the change feeds return them and move their watermark past them.
+func backfillUpdatedAt(db *gorm.DB) error {
+	now := time.Now()
+
+	for _, model := range migratedModels {
+		if !TracksChanges(model) {
+			continue
+		}
+
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return err
+		}
+
+		field := stmt.Schema.LookUpField("UpdatedAt")
+		if field == nil {
+			continue
+		}
+
+		if err := db.Table(stmt.Schema.Table).Where(db.Statement.Quote(field.DBName)+" IS NULL").
+			Update(field.DBName, now).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RecordDeletions stores the deletion of records, and removes the deletions
+// older than DeletionRetention.
+//
+// Parameters:
+// - resource: The name of the resource (e.g. "example1").
+// - ids: The tokenized IDs of the deleted records.
+// - at: The timestamp of the deletion.
+func (bc *BaseController) RecordDeletions(resource string, ids []string, at time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	deletions := make([]models.RecordDeletion, 0, len(ids))
+	for _, id := range ids {
+		deletions = append(deletions, models.RecordDeletion{Resource: resource, RecordID: id, DeletedAt: at})
+	}
+
+	return bc.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&deletions).Error; err != nil {
+			return err
+		}
+
+		return tx.Where("deleted_at < ?", at.Add(-DeletionRetention)).Delete(&models.RecordDeletion{}).Error
+	})
+}
+
+// ChangedRecords retrieves the records created or updated in a time window, oldest first.
+//
+// Parameters:
+// - model: A pointer to a slice of a model with an UpdatedAt field.
+// - since: Only the records updated after this date are returned. Zero returns every record.
+// - until: Only the records updated up to this date are returned. Zero has no bound.
+// - opts: The conditions restricting the records, and the maximum number of records.
+//
+// Returns:
+// - ErrChangesNotTracked if the model has no UpdatedAt field.
+// - ErrInvalidField if a condition references a field that does not exist on the model.
+func (bc *BaseController) ChangedRecords(model interface{}, since, until time.Time, opts QueryOptions) error {
+	sch, err := bc.modelSchema(model)
+	if err != nil {
+		return err
+	}
+
+	field := sch.LookUpField("UpdatedAt")
+	if field == nil || !TracksChanges(model) {
+		return ErrChangesNotTracked
+	}
+
+	column := bc.DB.Statement.Quote(field.DBName)
+
+	tx, err := applyQueryOptions(bc.DB, sch, QueryOptions{Conditions: opts.Conditions, Limit: opts.Limit})
+	if err != nil {
+		return err
+	}
+
+	if !since.IsZero() {
+		tx = tx.Where(column+" > ?", since)
+	}
+
+	if !until.IsZero() {
+		tx = tx.Where(column+" <= ?", until)
+	}
+
+	tx = tx.Order(field.DBName)
+	for _, primaryKey := range sch.PrimaryFields {
+		tx = tx.Order(primaryKey.DBName)
+	}
+
+	return tx.Find(model).Error
+}
+
+// DeletedRecords returns the deletions of records of a resource after a date, oldest first.
+//
+// Parameters:
+// - resource: The name of the resource (e.g. "example1").
+// - since: Only the deletions after this date are returned.
+// - until: Only the deletions up to this date are returned. Zero has no bound.
+func (bc *BaseController) DeletedRecords(resource string, since, until time.Time) ([]models.RecordDeletion, error) {
+	tx := bc.DB.Where("resource = ? AND deleted_at > ?", resource, since)
+	if !until.IsZero() {
+		tx = tx.Where("deleted_at <= ?", until)
+	}
+
+	var deletions []models.RecordDeletion
+	err := tx.Order("deleted_at, id").Find(&deletions).Error
+
+	return deletions, err
+}
+
+// LatestChange returns the date of the latest change of a resource: the latest
+// UpdatedAt of its records, or the latest deletion if it is more recent.
+// It is zero when the resource has no records and no deletions.
+//
+// Parameters:
+// - resource: The name of the resource (e.g. "example1").
+// - model: A pointer to a model, or a slice of it, with an UpdatedAt field.
+//
+// Returns:
+// - ErrChangesNotTracked if the model has no UpdatedAt field.
+func (bc *BaseController) LatestChange(resource string, model interface{}) (time.Time, error) {
+	sch, err := bc.modelSchema(model)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	field := sch.LookUpField("UpdatedAt")
+	if field == nil || !TracksChanges(model) {
+		return time.Time{}, ErrChangesNotTracked
+	}
+
+	// Read the latest record rather than MAX(), whose type depends on the database
+	latest := reflect.New(sch.ModelType).Interface()
+	if err := bc.DB.Order(bc.DB.Statement.Quote(field.DBName) + " DESC").Limit(1).Find(latest).Error; err != nil {
+		return time.Time{}, err
+	}
+
+	modified, _ := LastModified(latest)
+
+	var deletion models.RecordDeletion
+	if err := bc.DB.Where("resource = ?", resource).Order("deleted_at DESC").Limit(1).Find(&deletion).Error; err != nil {
+		return time.Time{}, err
+	}
+
+	if deletion.DeletedAt.After(modified) {
+		return deletion.DeletedAt, nil
+	}
+
+	return modified, nil
+}
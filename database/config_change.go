@@ -0,0 +1,146 @@
+package database
+
+import (
+	"errors"
+	"time"
+
+	"github.com/r4ulcl/api_template/utils/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// errChainBroken stops walking the audit log at the first entry breaking the chain.
+var errChainBroken = errors.New("audit chain broken")
+
+// configChangeBatch is the number of audit entries read at once when walking the log.
+const configChangeBatch = 500
+
+// ConfigChangeFilter selects the entries returned by ListConfigChanges. Zero
+// fields don't filter.
+type ConfigChangeFilter struct {
+	// Kind only returns the changes of a kind of configuration.
+	Kind models.ConfigKind
+
+	// Key only returns the changes of a configuration. It is used with Kind.
+	Key string
+
+	// Actor only returns the changes made by a username.
+	Actor string
+
+	// Since only returns the changes made after a date.
+	Since time.Time
+
+	// BeforeID only returns the entries older than another, to read the next page of the feed.
+	BeforeID uint
+
+	// Limit caps the number of entries.
+	Limit int
+}
+
+// RecordConfigChange appends an entry to the audit log of configuration changes.
+// The log is append-only: there is no way to update or delete its entries.
+//
+// The entry is chained to the last one, locked meanwhile so concurrent
+// changes of several replicas don't fork the chain. Its time is truncated to
+// milliseconds, the precision of the database, so its hash can be recomputed.
+func (bc *BaseController) RecordConfigChange(change *models.ConfigChange) error {
+	change.ID = 0
+	change.ChangedAt = time.Now().UTC().Truncate(time.Millisecond)
+
+	return bc.DB.Transaction(func(tx *gorm.DB) error {
+		var last models.ConfigChange
+
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "hash").
+			Order("id DESC").Limit(1).Find(&last).Error
+		if err != nil {
+			return err
+		}
+
+		change.PrevHash = last.Hash
+		change.Hash = change.ComputeHash()
+
+		return tx.Create(change).Error
+	})
+}
+
+// WalkConfigChanges calls fn with the entries of the audit log of configuration
+// changes in ID order, read in batches, until fn returns an error.
+//
+// Parameters:
+// - afterID: Only walks the entries after this one; 0 walks the whole log.
+// - fn: Called with every entry.
+func (bc *BaseController) WalkConfigChanges(afterID uint, fn func(models.ConfigChange) error) error {
+	for {
+		batch := []models.ConfigChange{}
+		if err := bc.DB.Where("id > ?", afterID).Order("id").Limit(configChangeBatch).Find(&batch).Error; err != nil {
+			return err
+		}
+
+		for _, change := range batch {
+			if err := fn(change); err != nil {
+				return err
+			}
+		}
+
+		if len(batch) < configChangeBatch {
+			return nil
+		}
+
+		afterID = batch[len(batch)-1].ID
+	}
+}
+
+// VerifyConfigChanges checks the chain of the audit log of configuration
+// changes from its start: the hash of each entry, and its link to the previous one.
+func (bc *BaseController) VerifyConfigChanges() (*models.AuditVerification, error) {
+	verification := models.NewAuditVerification("")
+
+	err := bc.WalkConfigChanges(0, func(change models.ConfigChange) error {
+		if !verification.Check(change) {
+			return errChainBroken
+		}
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, errChainBroken) {
+		return nil, err
+	}
+
+	return verification, nil
+}
+
+// ConfigChangeBefore returns the entry of the audit log before another, to
+// check the chain of a part of the log.
+//
+// Returns:
+// - An empty entry if there is none before.
+func (bc *BaseController) ConfigChangeBefore(id uint) (models.ConfigChange, error) {
+	var change models.ConfigChange
+	err := bc.DB.Where("id < ?", id).Order("id DESC").Limit(1).Find(&change).Error
+
+	return change, err
+}
+
+// ListConfigChanges returns the entries of the audit log of configuration
+// changes, newest first.
+func (bc *BaseController) ListConfigChanges(filter ConfigChangeFilter) ([]models.ConfigChange, error) {
+	// Struct conditions skip the zero fields, and quote the reserved "key" column
+	tx := bc.DB.Where(&models.ConfigChange{Kind: filter.Kind, Key: filter.Key, Actor: filter.Actor}).Order("id DESC")
+
+	if !filter.Since.IsZero() {
+		tx = tx.Where("changed_at > ?", filter.Since)
+	}
+
+	if filter.BeforeID > 0 {
+		tx = tx.Where("id < ?", filter.BeforeID)
+	}
+
+	if filter.Limit > 0 {
+		tx = tx.Limit(filter.Limit)
+	}
+
+	changes := []models.ConfigChange{}
+	err := tx.Find(&changes).Error
+
+	return changes, err
+}
@@ -0,0 +1,74 @@
+package database
+
+import "gorm.io/gorm"
+
+// explainRow holds the row estimate of a MySQL EXPLAIN output row.
+type explainRow struct {
+	Rows     int64
+	Filtered float64
+}
+
+// EstimateRecords counts the records of a given type matching the filters of the
+// options, estimating the count on large MySQL tables.
+//
+// When the table has more rows than CountEstimateThreshold according to
+// information_schema, the table estimate is returned without filters, and the
+// EXPLAIN row estimate with filters, which avoid scanning the table. Otherwise
+// (small tables, other databases or a threshold of 0) it counts exactly like
+// CountRecords.
+//
+// Parameters:
+// - model: A pointer to a slice of the model being counted.
+// - opts: The query options whose filters are applied.
+//
+// Returns:
+// - The number of matching records.
+// - Whether the number is an estimate.
+// - ErrInvalidField if a filter name does not exist on the model.
+// - An error if the count fails.
+func (bc *BaseController) EstimateRecords(model interface{}, opts QueryOptions) (int64, bool, error) {
+	if bc.CountEstimateThreshold <= 0 || bc.DB.Dialector.Name() != "mysql" {
+		total, err := bc.CountRecords(model, opts)
+
+		return total, false, err
+	}
+
+	sch, err := bc.modelSchema(model)
+	if err != nil {
+		return 0, false, err
+	}
+
+	tableRows, err := bc.tableRows(sch)
+	if err != nil {
+		return 0, false, err
+	}
+
+	if tableRows < bc.CountEstimateThreshold {
+		total, err := bc.CountRecords(model, opts)
+
+		return total, false, err
+	}
+
+	if len(opts.Filters) == 0 && len(opts.Conditions) == 0 {
+		return tableRows, true, nil
+	}
+
+	tx, err := applyQueryOptions(bc.DB.Model(model), sch, QueryOptions{Filters: opts.Filters, Conditions: opts.Conditions})
+	if err != nil {
+		return 0, false, err
+	}
+
+	// Build the filtered SELECT without running it, then ask MySQL for its plan
+	stmt := tx.Session(&gorm.Session{DryRun: true}).Find(model).Statement
+
+	var plan []explainRow
+	if err := bc.DB.Raw("EXPLAIN "+stmt.SQL.String(), stmt.Vars...).Scan(&plan).Error; err != nil {
+		return 0, false, err
+	}
+
+	if len(plan) == 0 {
+		return 0, true, nil
+	}
+
+	return int64(float64(plan[0].Rows) * plan[0].Filtered / 100), true, nil
+}
@@ -0,0 +1,310 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/r4ulcl/api_template/utils/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// Counter is a denormalized column of a parent model holding the number of
+// child rows that reference it, e.g. the ExampleRelational rows of each Example1.
+//
+// The counter column should be read-only (gorm "->") so saving the parent never
+// overwrites it.
+type Counter struct {
+	Parent     interface{} // The parent model, with a single primary key (e.g. &models.Example1{})
+	Column     string      // The counter column of the parent (e.g. "relational_count")
+	Child      interface{} // The child model (e.g. &models.ExampleRelational{})
+	ForeignKey string      // The column of the child holding the primary key of the parent
+}
+
+// counters lists the counters maintained when child records are created,
+// updated, deleted or merged through the BaseController.
+var counters = []Counter{
+	{
+		Parent:     &models.Example1{},
+		Column:     "relational_count",
+		Child:      &models.ExampleRelational{},
+		ForeignKey: "example1_field1",
+	},
+}
+
+// parsedCounter holds a counter and the parsed schemas of its models.
+type parsedCounter struct {
+	counter    Counter
+	parent     *schema.Schema
+	child      *schema.Schema
+	foreignKey *schema.Field
+}
+
+// parsedCounters parses the models of every counter.
+func (bc *BaseController) parsedCounters() ([]parsedCounter, error) {
+	parsed := make([]parsedCounter, 0, len(counters))
+
+	for _, counter := range counters {
+		parent, err := bc.modelSchema(counter.Parent)
+		if err != nil {
+			return nil, err
+		}
+
+		child, err := bc.modelSchema(counter.Child)
+		if err != nil {
+			return nil, err
+		}
+
+		foreignKey := child.LookUpField(counter.ForeignKey)
+		if len(parent.PrimaryFields) != 1 || foreignKey == nil || parent.LookUpField(counter.Column) == nil {
+			return nil, fmt.Errorf("invalid counter %s.%s: the parent needs a single primary key and "+
+				"both columns must exist", parent.Table, counter.Column)
+		}
+
+		parsed = append(parsed, parsedCounter{counter: counter, parent: parent, child: child, foreignKey: foreignKey})
+	}
+
+	return parsed, nil
+}
+
+// parentID returns the parent primary key held by a child record, or false if it is empty.
+func (c parsedCounter) parentID(child interface{}) (interface{}, bool) {
+	value, zero := c.foreignKey.ValueOf(context.Background(), reflect.Indirect(reflect.ValueOf(child)))
+
+	return value, !zero
+}
+
+// addToCounter adds delta to the counter of a parent record.
+func (c parsedCounter) addToCounter(tx *gorm.DB, parentID interface{}, delta int) error {
+	column := tx.Statement.Quote(c.counter.Column)
+
+	return tx.Exec(fmt.Sprintf("UPDATE %s SET %s = %s + ? WHERE %s = ?",
+		tx.Statement.Quote(c.parent.Table), column, column, tx.Statement.Quote(c.parent.PrimaryFields[0].DBName)),
+		delta, parentID).Error
+}
+
+// updateCounters keeps the counters in sync with a child record that is
+// created (before is nil), updated, or deleted (after is nil).
+//
+// Parameters:
+// - tx: The transaction writing the child record.
+// - before: A pointer to the child record before the change, or nil.
+// - after: A pointer to the child record after the change, or nil.
+func (bc *BaseController) updateCounters(tx *gorm.DB, before, after interface{}) error {
+	model := after
+	if model == nil {
+		model = before
+	}
+
+	sch, err := bc.modelSchema(model)
+	if err != nil {
+		return err
+	}
+
+	parsed, err := bc.parsedCounters()
+	if err != nil {
+		return err
+	}
+
+	for _, c := range parsed {
+		if c.child.Table != sch.Table {
+			continue
+		}
+
+		var oldID, newID interface{}
+
+		oldOK, newOK := false, false
+
+		if before != nil {
+			oldID, oldOK = c.parentID(before)
+		}
+
+		if after != nil {
+			newID, newOK = c.parentID(after)
+		}
+
+		if oldOK && newOK && fmt.Sprint(oldID) == fmt.Sprint(newID) {
+			continue
+		}
+
+		if oldOK {
+			if err := c.addToCounter(tx, oldID, -1); err != nil {
+				return err
+			}
+		}
+
+		if newOK {
+			if err := c.addToCounter(tx, newID, 1); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// hasCounters reports whether writing records of a model may change a counter:
+// it is the child of a counter, or the child of a counter references it.
+func (bc *BaseController) hasCounters(sch *schema.Schema) bool {
+	parsed, err := bc.parsedCounters()
+	if err != nil {
+		return false
+	}
+
+	for _, c := range parsed {
+		if c.child.Table == sch.Table || len(childReferences(c.child, sch)) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// childReferences returns the foreign key columns of a child schema referencing
+// the primary key of another model through a belongs-to relation.
+func childReferences(child, target *schema.Schema) []string {
+	var columns []string
+
+	for _, relation := range child.Relationships.BelongsTo {
+		if relation.FieldSchema.Table != target.Table {
+			continue
+		}
+
+		for _, ref := range relation.References {
+			if ref.PrimaryKey != nil && ref.PrimaryKey.PrimaryKey && ref.ForeignKey != nil {
+				columns = append(columns, ref.ForeignKey.DBName)
+			}
+		}
+	}
+
+	return columns
+}
+
+// referencedParents returns, for each counter, the parents of the child rows
+// referencing the given records of a model (or the records themselves, when
+// they are the parents). Deleting or merging those records
+// removes or re-points the child rows (e.g. ON DELETE CASCADE), so the counters
+// of these parents are recounted afterwards with recount.
+//
+// Parameters:
+// - tx: The transaction deleting or merging the records.
+// - sch: The schema of the model, with a single primary key.
+// - ids: The primary keys of the records.
+func (bc *BaseController) referencedParents(tx *gorm.DB, sch *schema.Schema, ids []string) (map[int][]string, error) {
+	parsed, err := bc.parsedCounters()
+	if err != nil {
+		return nil, err
+	}
+
+	parents := map[int][]string{}
+
+	for i, c := range parsed {
+		if c.child.Table == sch.Table {
+			continue
+		}
+
+		// The child rows of the records themselves are counted on the records
+		if c.parent.Table == sch.Table {
+			parents[i] = append(parents[i], ids...)
+
+			continue
+		}
+
+		for _, column := range childReferences(c.child, sch) {
+			var found []string
+			if err := tx.Table(c.child.Table).Distinct(c.foreignKey.DBName).
+				Where(tx.Statement.Quote(column)+" IN ?", ids).Pluck(c.foreignKey.DBName, &found).Error; err != nil {
+				return nil, err
+			}
+
+			if len(found) > 0 {
+				parents[i] = append(parents[i], found...)
+			}
+		}
+	}
+
+	return parents, nil
+}
+
+// recount sets the counters of the given parents to the number of child rows
+// referencing them. Without parent IDs, every parent is recounted.
+//
+// Returns:
+// - The number of parents whose counter was wrong.
+func (c parsedCounter) recount(tx *gorm.DB, parentIDs []string) (int64, error) {
+	parent := tx.Statement.Quote(c.parent.Table)
+	primaryKey := parent + "." + tx.Statement.Quote(c.parent.PrimaryFields[0].DBName)
+	column := tx.Statement.Quote(c.counter.Column)
+	child := tx.Statement.Quote(c.child.Table)
+	count := fmt.Sprintf("(SELECT COUNT(*) FROM %s WHERE %s.%s = %s)",
+		child, child, tx.Statement.Quote(c.foreignKey.DBName), primaryKey)
+
+	query := fmt.Sprintf("UPDATE %s SET %s = %s WHERE %s <> %s", parent, column, count, column, count)
+
+	var args []interface{}
+
+	if parentIDs != nil {
+		if len(parentIDs) == 0 {
+			return 0, nil
+		}
+
+		query += " AND " + primaryKey + " IN ?"
+		args = append(args, parentIDs)
+	}
+
+	res := tx.Exec(query, args...)
+
+	return res.RowsAffected, res.Error
+}
+
+// recountParents recounts the counters of the parents returned by referencedParents.
+func (bc *BaseController) recountParents(tx *gorm.DB, parents map[int][]string) error {
+	if len(parents) == 0 {
+		return nil
+	}
+
+	parsed, err := bc.parsedCounters()
+	if err != nil {
+		return err
+	}
+
+	for i, ids := range parents {
+		if _, err := parsed[i].recount(tx, ids); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RecomputeCounters recounts every counter from the child rows, repairing the
+// counters changed outside the BaseController (e.g. by SQL run by hand).
+//
+// Returns:
+// - The number of parents fixed for each counter.
+func (bc *BaseController) RecomputeCounters() ([]models.CounterRepair, error) {
+	parsed, err := bc.parsedCounters()
+	if err != nil {
+		return nil, err
+	}
+
+	repairs := make([]models.CounterRepair, 0, len(parsed))
+
+	err = bc.DB.Transaction(func(tx *gorm.DB) error {
+		for _, c := range parsed {
+			fixed, err := c.recount(tx, nil)
+			if err != nil {
+				return err
+			}
+
+			repairs = append(repairs, models.CounterRepair{
+				Table: c.parent.Table, Column: c.counter.Column, Child: c.child.Table, Fixed: fixed,
+			})
+		}
+
+		return nil
+	})
+
+	return repairs, err
+}
@@ -1,6 +1,7 @@
 package database
 
 import (
+	"database/sql"
 	"errors"
 	"fmt"
 	"log"
@@ -8,6 +9,7 @@ import (
 	"strings"
 	"time"
 
+	gomysql "github.com/go-sql-driver/mysql"
 	"github.com/lib/pq"
 	"github.com/r4ulcl/api_template/utils"
 	"github.com/r4ulcl/api_template/utils/models"
@@ -20,106 +22,313 @@ import (
 // DB is the global database connection instance.
 var DB *gorm.DB
 
+// ErrRecordNotFound is returned by GetRecordsByID when no record has the given ID.
+var ErrRecordNotFound = errors.New("Record not found")
+
 // BaseController provides a wrapper around database operations.
 //
 // It embeds the GORM database instance to facilitate CRUD operations.
 type BaseController struct {
 	DB *gorm.DB
+
+	// CountEstimateThreshold is the table size (in rows) above which EstimateRecords
+	// uses the database estimates instead of COUNT(*). 0 always counts exactly.
+	CountEstimateThreshold int64
+
+	// QueryLimits rejects the list queries that would be too expensive (see CheckQueryCost).
+	QueryLimits QueryCostLimits
+
+	// Retry retries the record operations failing with a transient error (see RetryPolicy).
+	Retry RetryPolicy
+
+	// BatchSize is the number of rows inserted per statement by CreateRecords. Zero uses defaultBatchSize.
+	BatchSize int
+
+	// txPolicy and txHint set the transaction options of the operations (see WithTransactions).
+	txPolicy TxPolicy
+	txHint   sql.IsolationLevel
 }
 
-// ConnectDB initializes and establishes a connection to the database.
+// migratedModels lists the models created and updated by AutoMigrate, in order.
 //
-// It attempts to connect up to 5 times with a 5-second delay between attempts.
-// If the connection fails after 5 attempts, the application exits with an error.
+// Relational models come last so the tables they reference already exist.
+var migratedModels = []interface{}{
+	&models.Example1{}, &models.Example2{}, &models.User{}, &models.SavedQuery{},
+	&models.NotificationRule{}, &models.Announcement{}, &models.FeatureFlag{},
+	&models.UserPreference{}, &models.Setting{}, &models.SettingChange{}, &models.EmailVerification{},
+	&models.ServiceAccount{}, &models.StatsSnapshot{}, &models.ValidationRule{}, &models.RecordLock{},
+	&models.UserFavorite{}, &models.InboxNotification{}, &models.Sequence{},
+	&models.Report{}, &models.ReportSnapshot{}, &models.RecordDeletion{}, &models.Quota{},
+	&models.ConfigChange{}, &models.Importer{}, &models.BulkJob{}, &models.ExportJob{}, &models.LeaderLease{},
+	&models.ExampleRelational{}, &models.InboundHook{}, &models.EmailRule{}, &models.DependencyCheck{},
+	&models.UsageRollup{}, &models.DeviceToken{},
+}
+
+// OpenDB opens a connection to the database without retrying or migrating.
+//
+// With DB_TLS, the connections are encrypted with the TLS configuration of
+// NewDBTLSConfig. With DB_PREPARE_STMT, statements are prepared once and cached per connection,
+// and the cache hits and misses are counted (see StatementCache). With
+// DB_MAX_CONCURRENT_QUERIES, the statements run at once are limited (see QuerySemaphore).
 //
 // Parameters:
 // - cfg: A pointer to the configuration containing database credentials.
 //
-// This function also performs automatic migrations for all registered models.
-func ConnectDB(cfg *utils.Config) {
-	dsn := cfg.DSN() // Generate the database connection string
+// Returns:
+// - The database connection.
+// - An error if the connection fails.
+func OpenDB(cfg *utils.Config) (*gorm.DB, error) {
+	tlsConfig, err := utils.NewDBTLSConfig(cfg.DBTLS, cfg.DBTLSCAFile, cfg.DBTLSCertFile, cfg.DBTLSKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("database TLS: %w", err)
+	}
+
+	if tlsConfig != nil {
+		if err := gomysql.RegisterTLSConfig(utils.DBTLSConfigName, tlsConfig); err != nil {
+			return nil, fmt.Errorf("database TLS: %w", err)
+		}
+	}
+
+	db, err := gorm.Open(mysql.Open(cfg.DSN()), &gorm.Config{
+		SkipDefaultTransaction: true,
+		PrepareStmt:            cfg.DBPrepareStmt,
+		NamingStrategy:         schema.NamingStrategy{},
+		Logger:                 logger.Default.LogMode(logger.Silent),
+		NowFunc:                time.Now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	instrumentStatementCache(db)
+
+	err = InstallQuerySemaphore(db, cfg.DBMaxConcurrentQueries, time.Duration(cfg.DBQueryWaitTimeout)*time.Millisecond)
+	if err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
 
-	var db *gorm.DB
+// Errors of ConnectDB and WaitForDB, turned into exit codes by main.
+var (
+	// ErrDBUnavailable is returned when the database doesn't answer in time.
+	ErrDBUnavailable = errors.New("database unavailable")
+
+	// ErrMigrationFailed is returned when a migration fails.
+	ErrMigrationFailed = errors.New("migration failed")
+
+	// ErrPendingMigrations is returned when migrations are pending and DB_AUTO_MIGRATE is false.
+	ErrPendingMigrations = errors.New("pending migrations")
+)
 
-	var err error
+// dbWaitInterval is the delay between two connection attempts of WaitForDB.
+const dbWaitInterval = 5 * time.Second
+
+// WaitForDB connects to the database, retrying every few seconds until it
+// answers or DB_WAIT_TIMEOUT expires.
+//
+// Parameters:
+// - cfg: A pointer to the configuration containing database credentials.
+//
+// Returns:
+// - The database connection.
+// - ErrDBUnavailable, with the last error, if the database didn't answer in time.
+func WaitForDB(cfg *utils.Config) (*gorm.DB, error) {
+	deadline := time.Now().Add(time.Duration(cfg.DBWaitTimeout) * time.Second)
 
-	seconds := 5
+	for attempt := 1; ; attempt++ {
+		db, err := OpenDB(cfg)
+		if err == nil {
+			// gorm.Open doesn't always connect, so the connection is checked explicitly
+			var sqlDB *sql.DB
+			if sqlDB, err = db.DB(); err == nil {
+				err = sqlDB.Ping()
+			}
+		}
 
-	// Retry connection up to 5 times
-	for attempts := 1; attempts <= 5; attempts++ {
-		db, err = gorm.Open(mysql.Open(dsn), &gorm.Config{
-			SkipDefaultTransaction: true,
-			NamingStrategy:         schema.NamingStrategy{},
-			Logger:                 logger.Default.LogMode(logger.Silent),
-			NowFunc:                time.Now,
-		})
 		if err == nil {
 			log.Println("Connected to MySQL successfully.")
 
-			break
+			return db, nil
 		}
 
-		const maxRetries = 5
-		if attempts == maxRetries {
-			log.Fatalf("Failed to connect to MySQL after %d attempts: %v", attempts, err)
+		if time.Now().Add(dbWaitInterval).After(deadline) {
+			return nil, fmt.Errorf("%w after %d attempts: %w", ErrDBUnavailable, attempt, err)
 		}
 
-		log.Printf("Failed to connect to MySQL, retrying in %d seconds... (Attempt %d/5)", seconds, attempts)
-		time.Sleep(time.Duration(seconds) * time.Second)
+		log.Printf("Failed to connect to MySQL, retrying in %s... (attempt %d): %v", dbWaitInterval, attempt, err)
+		time.Sleep(dbWaitInterval)
 	}
+}
 
-	// AutoMigrate all models
-	err = db.Debug().AutoMigrate(&models.Example1{}, &models.Example2{}, &models.User{})
+// ConnectDB initializes and establishes a connection to the database.
+//
+// It waits for the database with WaitForDB, then applies the migrations, or
+// with DB_AUTO_MIGRATE=false checks that none are pending, so the schema can
+// be migrated separately (e.g. with --migrate-only in an init container).
+//
+// Parameters:
+// - cfg: A pointer to the configuration containing database credentials.
+//
+// Returns:
+// - ErrDBUnavailable, ErrMigrationFailed or ErrPendingMigrations, wrapping the cause.
+func ConnectDB(cfg *utils.Config) error {
+	db, err := WaitForDB(cfg)
 	if err != nil {
-		log.Fatalf("AutoMigrate failed: %v", err)
+		return err
 	}
 
-	// AutoMigrate relational models separately
-	err = db.Debug().AutoMigrate(&models.ExampleRelational{})
+	// Recycle pooled connections so connections broken by a MySQL restart are discarded
+	sqlDB, err := db.DB()
 	if err != nil {
-		log.Fatalf("AutoMigrate failed: %v", err)
+		return fmt.Errorf("%w: %w", ErrDBUnavailable, err)
+	}
+
+	sqlDB.SetConnMaxLifetime(5 * time.Minute)
+	sqlDB.SetConnMaxIdleTime(time.Minute)
+
+	if cfg.DBAutoMigrate {
+		if err := Migrate(db.Debug()); err != nil {
+			return fmt.Errorf("%w: %w", ErrMigrationFailed, err)
+		}
+	} else {
+		pending, err := PendingMigrations(db)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrMigrationFailed, err)
+		}
+
+		if len(pending) > 0 {
+			return fmt.Errorf("%w: %s", ErrPendingMigrations, strings.Join(pending, ", "))
+		}
 	}
 
 	// Assign the global database instance
 	DB = db
+
+	return nil
+}
+
+// Migrate creates and updates the tables of every model and normalizes the stored usernames.
+//
+// Parameters:
+// - db: The database connection to migrate.
+//
+// Returns:
+// - An error if a migration fails.
+func Migrate(db *gorm.DB) error {
+	// AutoMigrate all models, one at a time so relational models are migrated last
+	for _, model := range migratedModels {
+		if err := db.AutoMigrate(model); err != nil {
+			return fmt.Errorf("AutoMigrate failed: %w", err)
+		}
+	}
+
+	// Usernames are case-insensitive since they are stored in lower case
+	if err := normalizeUsernames(db); err != nil {
+		return fmt.Errorf("username normalization failed: %w", err)
+	}
+
+	if err := backfillUpdatedAt(db); err != nil {
+		return fmt.Errorf("updated_at backfill failed: %w", err)
+	}
+
+	return nil
+}
+
+// PendingMigrations lists the changes AutoMigrate would make to the database,
+// without applying them.
+//
+// Only missing tables and columns are reported; type and index changes are not.
+//
+// Parameters:
+// - db: The database connection to inspect.
+//
+// Returns:
+// - The pending changes (e.g. "create table users", "add column users.email").
+// - An error if a model cannot be parsed.
+func PendingMigrations(db *gorm.DB) ([]string, error) {
+	pending := []string{}
+	migrator := db.Migrator()
+
+	for _, model := range migratedModels {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return nil, err
+		}
+
+		table := stmt.Schema.Table
+
+		if !migrator.HasTable(model) {
+			pending = append(pending, "create table "+table)
+
+			continue
+		}
+
+		for _, field := range stmt.Schema.Fields {
+			if field.DBName != "" && !migrator.HasColumn(model, field.DBName) {
+				pending = append(pending, "add column "+table+"."+field.DBName)
+			}
+		}
+	}
+
+	return pending, nil
 }
 
 // CreateOrUpdateRecord attempts to create a new record. If a duplicate key error
 // is encountered (and overwrite == true), it falls back to an update.
 //
+// A pointer to a slice is inserted in chunks by CreateRecords, without overwrite.
+//
 // Parameters:
 // - model: A pointer to the struct representing the database entity.
 // - overwrite: Whether to update the record on duplicate key conflict.
 //
 // Returns:
+// - true if a new record was created, false if an existing one was updated.
 // - An error if creation fails and overwrite is false, or if the update fails.
-func (bc *BaseController) CreateOrUpdateRecord(model interface{}, overwrite bool) error {
+func (bc *BaseController) CreateOrUpdateRecord(model interface{}, overwrite bool) (bool, error) {
+	if reflect.Indirect(reflect.ValueOf(model)).Kind() == reflect.Slice {
+		return true, bc.CreateRecords(model, nil)
+	}
+
 	// Try to create the record
-	if err := bc.DB.Create(model).Error; err != nil {
+	err := bc.withHooks(model, func(tx *gorm.DB) error {
+		if err := bc.assignSequences(tx, model); err != nil {
+			return err
+		}
+
+		if err := tx.Create(model).Error; err != nil {
+			return err
+		}
+
+		return bc.updateCounters(tx, nil, model)
+	})
+	if err != nil {
 		// Check if it's a duplicate key error
-		if isDuplicateKeyError(err) {
+		if IsDuplicateKeyError(err) {
 			// Only overwrite (update) if the overwrite flag is true
 			if overwrite {
 				// Pass an empty string as ID here, so UpdateRecords reads
 				// the primary key from the struct itself
 				if updateErr := bc.UpdateRecords(model, ""); updateErr != nil {
-					return updateErr
+					return false, updateErr
 				}
 
-				return nil
+				return false, nil
 			}
 		}
 		// Return any other error (or the duplicate key error if overwrite==false)
-		return err
+		return false, err
 	}
 
 	// If record is created successfully, return nil
-	return nil
+	return true, nil
 }
 
-// isDuplicateKeyError checks if the error indicates a unique constraint violation.
+// IsDuplicateKeyError checks if the error indicates a unique constraint violation.
 // Adjust the checks for your specific DB engine (MySQL, PostgreSQL, etc.).
-func isDuplicateKeyError(err error) bool {
+func IsDuplicateKeyError(err error) bool {
 	// For PostgreSQL (error code 23505)
 	var pqErr *pq.Error
 	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
@@ -132,26 +341,38 @@ func isDuplicateKeyError(err error) bool {
 		return true
 	}
 
+	// For SQLite, and dialects configured with TranslateError
+	if errors.Is(err, gorm.ErrDuplicatedKey) || strings.Contains(err.Error(), "UNIQUE constraint failed") {
+		return true
+	}
+
 	return false
 }
 
-// GetAllRecords retrieves all records of a given type with optional filters.
+// GetAllRecords retrieves all records of a given type with optional filters,
+// sort order, field selection and limit.
 //
 // Filters are applied dynamically, and relationships are preloaded if foreign keys exist.
 //
 // Parameters:
 // - model: A pointer to a slice where retrieved records will be stored.
-// - filters: A map of key-value pairs used for filtering results.
+// - opts: The filters, sort order, fields and limit used to build the query.
 //
 // Returns:
+// - ErrInvalidField if a filter, sort or field name does not exist on the model.
 // - An error if retrieval fails.
-func (bc *BaseController) GetAllRecords(model interface{}, filters map[string]interface{}) error {
-	tx := bc.DB
+func (bc *BaseController) GetAllRecords(model interface{}, opts QueryOptions) error {
 	modelType := reflect.TypeOf(model).Elem().Elem() // Get slice element type
 
-	// Apply dynamic filters
-	for key, value := range filters {
-		tx = tx.Where(key+" = ?", value)
+	sch, err := bc.modelSchema(model)
+	if err != nil {
+		return err
+	}
+
+	// Apply dynamic filters, sort order and field selection
+	tx, err := applyQueryOptions(bc.DB, sch, opts)
+	if err != nil {
+		return err
 	}
 
 	// Preload relationships dynamically
@@ -162,8 +383,41 @@ func (bc *BaseController) GetAllRecords(model interface{}, filters map[string]in
 		}
 	}
 
-	// Execute query
-	return tx.Find(model).Error
+	// Execute query, in a session so a retry starts from the same conditions
+	tx = tx.Session(&gorm.Session{})
+
+	return bc.retry(func() error { return tx.Find(model).Error })
+}
+
+// CountRecords counts the records of a given type matching the filters of the options.
+//
+// Sort order, field selection and limits are ignored, so the result is the total
+// number of records a paginated GetAllRecords call can return.
+//
+// Parameters:
+// - model: A pointer to a slice of the model being counted.
+// - opts: The query options whose filters are applied.
+//
+// Returns:
+// - The number of matching records.
+// - ErrInvalidField if a filter name does not exist on the model.
+// - An error if the count fails.
+func (bc *BaseController) CountRecords(model interface{}, opts QueryOptions) (int64, error) {
+	sch, err := bc.modelSchema(model)
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := applyQueryOptions(bc.DB.Model(model), sch, QueryOptions{Filters: opts.Filters, Conditions: opts.Conditions})
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+
+	tx = tx.Session(&gorm.Session{})
+
+	return total, bc.retry(func() error { return tx.Count(&total).Error })
 }
 
 // GetRecordsByID retrieves a record by its primary key(s).
@@ -178,22 +432,30 @@ func (bc *BaseController) GetAllRecords(model interface{}, filters map[string]in
 // - An error if the record is not found.
 func (bc *BaseController) GetRecordsByID(model interface{}, id string) error {
 	parts := strings.Split(id, "-")
-	primaryKeys := getPrimaryKeyFields(model)
 
-	if len(primaryKeys) != len(parts) {
+	sch, err := bc.modelSchema(model)
+	if err != nil {
+		return err
+	}
+
+	if len(sch.PrimaryFields) != len(parts) {
 		ErrMismatch := errors.New("mismatch between primary keys and tokenized ID")
 
 		return fmt.Errorf("%w", ErrMismatch)
 	}
 
-	conditions := []interface{}{}
-	for i := range primaryKeys {
-		conditions = append(conditions, parts[i])
+	// Match each primary key column explicitly: passing a bare string to First
+	// would be interpreted as a SQL condition instead of a key value
+	tx := bc.DB
+	for i, field := range sch.PrimaryFields {
+		tx = tx.Where(bc.DB.Statement.Quote(field.DBName)+" = ?", parts[i])
 	}
 
-	if err := bc.DB.First(model, conditions...).Error; err != nil {
+	tx = tx.Session(&gorm.Session{})
+
+	if err := bc.retry(func() error { return tx.First(model).Error }); err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("Record not found")
+			return ErrRecordNotFound
 		}
 
 		return err
@@ -245,23 +507,36 @@ func (bc *BaseController) UpdateRecords(model interface{}, id string) error {
 		}
 	}
 
-	// Construct the query based on primary keys and their values
-	query := bc.DB.Model(model)
-	for i, pk := range primaryKeys {
-		query = query.Where(pk+" = ?", keyValues[i])
-	}
+	return bc.withHooks(model, func(tx *gorm.DB) error {
+		// Construct the query based on primary keys and their values. A fresh
+		// instance is used so the lookup does not overwrite the updated data.
+		existing := reflect.New(reflect.TypeOf(model).Elem()).Interface()
 
-	// Attempt to find the existing record
-	if err := query.First(model).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("record not found")
+		query := tx.Model(existing)
+		for i, pk := range primaryKeys {
+			query = query.Where(pk+" = ?", keyValues[i])
 		}
 
-		return err
-	}
+		// Attempt to find the existing record
+		if err := query.First(existing).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("record not found")
+			}
+
+			return err
+		}
+
+		if err := bc.keepSequences(model, existing); err != nil {
+			return err
+		}
+
+		// Save the updated model
+		if err := tx.Save(model).Error; err != nil {
+			return err
+		}
 
-	// Save the updated model
-	return bc.DB.Save(model).Error
+		return bc.updateCounters(tx, existing, model)
+	})
 }
 
 // DeleteRecords deletes a record identified by its primary key(s).
@@ -273,67 +548,86 @@ func (bc *BaseController) UpdateRecords(model interface{}, id string) error {
 // Returns:
 // - An error if deletion fails.
 func (bc *BaseController) DeleteRecords(model interface{}, id string) error {
-	tx := bc.DB.Debug().
-		Session(&gorm.Session{NewDB: true}).
-		Model(model)
-
 	// Split the incoming ID by "-" for potential composite keys.
 	parts := strings.Split(id, "-")
 
-	// Get all JSON field names where GORM tag includes "primaryKey".
-	primaryKeys := getJSONPrimaryKeys(model)
+	sch, err := bc.modelSchema(model)
+	if err != nil {
+		return err
+	}
 
-	if len(primaryKeys) != len(parts) {
+	if len(sch.PrimaryFields) != len(parts) {
 		return fmt.Errorf("mismatch between primary keys (%d) and tokenized ID parts (%d)",
-			len(primaryKeys), len(parts))
+			len(sch.PrimaryFields), len(parts))
 	}
 
-	// Reflect on the `model` pointer to reach its underlying struct fields.
-	val := reflect.ValueOf(model)
-	if val.Kind() != reflect.Ptr || val.IsNil() {
-		return errors.New("model must be a non-nil pointer to a struct")
-	}
+	return bc.withHooks(model, func(db *gorm.DB) error {
+		// Match each primary key column explicitly so numeric keys work too.
+		// GORM will generate a delete statement like:
+		//    DELETE FROM `example1` WHERE `field1` = 'id'
+		where := func() *gorm.DB {
+			tx := db.Debug().Session(&gorm.Session{NewDB: true})
+			for i, field := range sch.PrimaryFields {
+				tx = tx.Where(bc.DB.Statement.Quote(field.DBName)+" = ?", parts[i])
+			}
 
-	elem := val.Elem()
-	if elem.Kind() != reflect.Struct {
-		return errors.New("model must point to a struct")
-	}
+			return tx
+		}
 
-	// We'll iterate through fields in the struct in the same order as `getJSONPrimaryKeys`.
-	// Each time we find a primaryKey field, we assign the corresponding `parts[i]`.
-	pkCount := 0
+		// Keep the deleted record and the parents of the rows its deletion cascades
+		// to, to update their counters
+		var existing interface{}
 
-	for i := range elem.NumField() {
-		fieldType := elem.Type().Field(i)
+		var parents map[int][]string
 
-		gormTag := fieldType.Tag.Get("gorm")
-		if strings.Contains(gormTag, "primaryKey") {
-			// This field is a primary key. We set its value to parts[pkCount].
-			// NOTE: If your PK is an integer, parse parts[pkCount] accordingly.
-			fieldValue := elem.Field(i)
-			if !fieldValue.CanSet() {
-				return fmt.Errorf("cannot set value for field %s", fieldType.Name)
+		if bc.hasCounters(sch) {
+			existing = reflect.New(sch.ModelType).Interface()
+			if err := where().First(existing).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
 			}
-			// For simplicity, assume string primary keys. Adjust if numeric.
-			fieldValue.SetString(parts[pkCount])
 
-			pkCount++
+			if len(parts) == 1 {
+				var err error
+				if parents, err = bc.referencedParents(db, sch, parts); err != nil {
+					return err
+				}
+			}
 		}
-	}
 
-	// Now that the primary key fields are updated to match `id`,
-	// GORM will generate a delete statement like:
-	//    DELETE FROM `example1` WHERE `example1`.`field1` = 'id'
-	res := tx.Delete(model)
-	if res.Error != nil {
-		return res.Error
-	}
+		res := where().Delete(model)
+		if res.Error != nil {
+			return res.Error
+		}
+
+		if res.RowsAffected == 0 {
+			return fmt.Errorf("no records deleted for ID %s", id)
+		}
 
-	if res.RowsAffected == 0 {
-		return fmt.Errorf("no records deleted for ID %s", id)
+		if existing != nil {
+			if err := bc.updateCounters(db, existing, nil); err != nil {
+				return err
+			}
+		}
+
+		return bc.recountParents(db, parents)
+	})
+}
+
+// withHooks runs a write in a transaction when it also writes other rows: the
+// counters (see Counter) and the sequences of the reference numbers (see
+// NextSequence), so they are updated atomically with the records, or when the
+// TxWrite options ask for it (see TxPolicy). Other writes run directly on the
+// connection. Writes failing with a transient error are retried, the whole
+// transaction if any.
+func (bc *BaseController) withHooks(model interface{}, write func(tx *gorm.DB) error) error {
+	opts := bc.txOptions(TxWrite)
+
+	sch, err := bc.modelSchema(model)
+	if opts == nil && (err != nil || (!bc.hasCounters(sch) && len(sequenceFields(sch)) == 0)) {
+		return bc.retry(func() error { return write(bc.DB) })
 	}
 
-	return nil
+	return bc.retry(func() error { return bc.DB.Transaction(write, opts) })
 }
 
 // getPrimaryKeyFields extracts the GORM primary key fields from a struct.
@@ -393,3 +687,30 @@ func getPrimaryKeyValues(model interface{}) ([]string, error) {
 
 	return values, nil
 }
+
+// LastModified returns the UpdatedAt field of a record, and false if the record
+// has none or it is not set (e.g. rows stored before the field existed).
+func LastModified(model interface{}) (time.Time, bool) {
+	val := reflect.Indirect(reflect.ValueOf(model))
+	if val.Kind() != reflect.Struct {
+		return time.Time{}, false
+	}
+
+	updatedAt, ok := val.FieldByName("UpdatedAt").Interface().(time.Time)
+	if !ok || updatedAt.IsZero() {
+		return time.Time{}, false
+	}
+
+	return updatedAt, true
+}
+
+// RecordID returns the tokenized primary key of a record, joining composite
+// keys with "-" as expected by the /{resource}/{id} routes.
+func RecordID(model interface{}) string {
+	values, err := getPrimaryKeyValues(model)
+	if err != nil {
+		return ""
+	}
+
+	return strings.Join(values, "-")
+}
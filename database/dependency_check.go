@@ -0,0 +1,155 @@
+package database
+
+import (
+	"errors"
+	"time"
+
+	"github.com/r4ulcl/api_template/utils/models"
+	"gorm.io/gorm"
+)
+
+// ErrDependencyCheckNotFound is returned when a dependency check does not exist.
+var ErrDependencyCheckNotFound = errors.New("dependency check not found")
+
+// ListDependencyChecks returns every dependency check.
+func (bc *BaseController) ListDependencyChecks() ([]models.DependencyCheck, error) {
+	checks := []models.DependencyCheck{}
+	err := bc.DB.Order("id").Find(&checks).Error
+
+	return checks, err
+}
+
+// GetDependencyCheck returns a dependency check by ID.
+//
+// Returns:
+// - ErrDependencyCheckNotFound if no check has the given ID.
+func (bc *BaseController) GetDependencyCheck(id uint) (models.DependencyCheck, error) {
+	var check models.DependencyCheck
+
+	err := bc.DB.First(&check, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return check, ErrDependencyCheckNotFound
+	}
+
+	return check, err
+}
+
+// CreateDependencyCheck stores a new dependency check, in the unknown state.
+func (bc *BaseController) CreateDependencyCheck(check *models.DependencyCheck) error {
+	check.ID = 0
+	resetDependencyState(check)
+
+	return bc.DB.Create(check).Error
+}
+
+// UpdateDependencyCheck replaces an existing dependency check. The state is
+// kept, unless the URL changed: then the dependency is unknown until checked.
+//
+// Returns:
+// - ErrDependencyCheckNotFound if no check has the given ID.
+func (bc *BaseController) UpdateDependencyCheck(id uint, check *models.DependencyCheck) error {
+	existing, err := bc.GetDependencyCheck(id)
+	if err != nil {
+		return err
+	}
+
+	check.ID = existing.ID
+	check.CreatedAt = existing.CreatedAt
+
+	if check.URL == existing.URL {
+		check.State = existing.State
+		check.ConsecutiveFailures = existing.ConsecutiveFailures
+		check.LastCheckedAt = existing.LastCheckedAt
+		check.LastStatusCode = existing.LastStatusCode
+		check.LastLatencyMs = existing.LastLatencyMs
+		check.LastError = existing.LastError
+		check.DownSince = existing.DownSince
+	} else {
+		resetDependencyState(check)
+	}
+
+	return bc.DB.Save(check).Error
+}
+
+// DeleteDependencyCheck removes a dependency check.
+//
+// Returns:
+// - ErrDependencyCheckNotFound if no check has the given ID.
+func (bc *BaseController) DeleteDependencyCheck(id uint) error {
+	res := bc.DB.Delete(&models.DependencyCheck{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return ErrDependencyCheckNotFound
+	}
+
+	return nil
+}
+
+// DueDependencyChecks returns the enabled dependency checks that were never
+// checked or whose interval elapsed.
+func (bc *BaseController) DueDependencyChecks(now time.Time) ([]models.DependencyCheck, error) {
+	var checks []models.DependencyCheck
+	if err := bc.DB.Where("enabled = ?", true).Order("id").Find(&checks).Error; err != nil {
+		return nil, err
+	}
+
+	due := checks[:0]
+
+	for _, check := range checks {
+		if check.LastCheckedAt == nil || !check.LastCheckedAt.Add(time.Duration(check.Interval)*time.Second).After(now) {
+			due = append(due, check)
+		}
+	}
+
+	return due, nil
+}
+
+// SetDependencyCheckResult stores the state of a dependency after a check.
+func (bc *BaseController) SetDependencyCheckResult(check models.DependencyCheck) error {
+	return bc.DB.Model(&models.DependencyCheck{}).Where("id = ?", check.ID).Updates(map[string]interface{}{
+		"state":                check.State,
+		"consecutive_failures": check.ConsecutiveFailures,
+		"last_checked_at":      check.LastCheckedAt,
+		"last_status_code":     check.LastStatusCode,
+		"last_latency_ms":      check.LastLatencyMs,
+		"last_error":           check.LastError,
+		"down_since":           check.DownSince,
+	}).Error
+}
+
+// DependencyHealth returns the state of the enabled dependencies, for /readyz.
+func (bc *BaseController) DependencyHealth() ([]models.DependencyHealth, error) {
+	var checks []models.DependencyCheck
+	if err := bc.DB.Where("enabled = ?", true).Order("name").Find(&checks).Error; err != nil {
+		return nil, err
+	}
+
+	health := make([]models.DependencyHealth, 0, len(checks))
+
+	for _, check := range checks {
+		health = append(health, models.DependencyHealth{
+			Name:           check.Name,
+			State:          check.State,
+			LastCheckedAt:  check.LastCheckedAt,
+			LastStatusCode: check.LastStatusCode,
+			LastLatencyMs:  check.LastLatencyMs,
+			DownSince:      check.DownSince,
+		})
+	}
+
+	return health, nil
+}
+
+// resetDependencyState clears the state of a dependency, until its first check.
+func resetDependencyState(check *models.DependencyCheck) {
+	check.State = models.DependencyUnknown
+	check.ConsecutiveFailures = 0
+	check.LastCheckedAt = nil
+	check.LastStatusCode = 0
+	check.LastLatencyMs = 0
+	check.LastError = ""
+	check.DownSince = nil
+}
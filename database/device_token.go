@@ -0,0 +1,110 @@
+package database
+
+import (
+	"errors"
+	"time"
+
+	"github.com/r4ulcl/api_template/utils/models"
+	"gorm.io/gorm"
+)
+
+// ErrDeviceTokenNotFound is returned when a device of the user does not exist.
+var ErrDeviceTokenNotFound = errors.New("device not found")
+
+// CreateDeviceToken issues a device token to a user.
+//
+// Parameters:
+// - username: The user the token logs in.
+// - name: The label of the device.
+// - ttl: How long the token stays exchangeable.
+//
+// Returns:
+// - The stored device and the token to return to the client. Only its hash is stored.
+// - An error if the token cannot be stored.
+func (bc *BaseController) CreateDeviceToken(username, name string, ttl time.Duration,
+) (models.DeviceToken, string, error) {
+	id, err := randomHex(8)
+	if err != nil {
+		return models.DeviceToken{}, "", err
+	}
+
+	token, err := randomHex(32)
+	if err != nil {
+		return models.DeviceToken{}, "", err
+	}
+
+	device := models.DeviceToken{
+		ID:        id,
+		TokenHash: hashToken(token),
+		Username:  username,
+		Name:      name,
+		ExpiresAt: time.Now().Add(ttl),
+	}
+
+	return device, token, bc.DB.Create(&device).Error
+}
+
+// UseDeviceToken finds the device of a token and records its use.
+//
+// Returns:
+// - The device.
+// - ErrInvalidToken if the token is unknown, revoked or expired. Expired tokens are deleted.
+func (bc *BaseController) UseDeviceToken(token string) (models.DeviceToken, error) {
+	var device models.DeviceToken
+
+	err := bc.DB.Where("token_hash = ?", hashToken(token)).First(&device).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return device, ErrInvalidToken
+	} else if err != nil {
+		return device, err
+	}
+
+	now := time.Now()
+
+	if now.After(device.ExpiresAt) {
+		if err := bc.DB.Delete(&device).Error; err != nil {
+			return device, err
+		}
+
+		return device, ErrInvalidToken
+	}
+
+	device.LastUsedAt = &now
+
+	return device, bc.DB.Model(&device).Update("last_used_at", now).Error
+}
+
+// ListDeviceTokens returns the devices of a user, the latest first.
+func (bc *BaseController) ListDeviceTokens(username string) ([]models.DeviceToken, error) {
+	devices := []models.DeviceToken{}
+	err := bc.DB.Where("username = ?", username).Order("created_at DESC").Find(&devices).Error
+
+	return devices, err
+}
+
+// DeleteDeviceToken revokes a device of a user.
+//
+// Returns:
+// - ErrDeviceTokenNotFound if the user has no device with the ID.
+func (bc *BaseController) DeleteDeviceToken(username, id string) error {
+	res := bc.DB.Where("id = ? AND username = ?", id, username).Delete(&models.DeviceToken{})
+	if res.Error != nil {
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return ErrDeviceTokenNotFound
+	}
+
+	return nil
+}
+
+// DeleteDeviceTokens revokes every device of a user.
+//
+// Returns:
+// - The number of devices revoked.
+func (bc *BaseController) DeleteDeviceTokens(username string) (int64, error) {
+	res := bc.DB.Where("username = ?", username).Delete(&models.DeviceToken{})
+
+	return res.RowsAffected, res.Error
+}
@@ -0,0 +1,99 @@
+package database
+
+import (
+	"errors"
+	"time"
+
+	"github.com/r4ulcl/api_template/utils/models"
+	"gorm.io/gorm"
+)
+
+// ErrEmailRuleNotFound is returned when an email rule does not exist.
+var ErrEmailRuleNotFound = errors.New("email rule not found")
+
+// ListEmailRules returns every email rule in the order they are checked.
+func (bc *BaseController) ListEmailRules() ([]models.EmailRule, error) {
+	rules := []models.EmailRule{}
+	err := bc.DB.Order("priority, id").Find(&rules).Error
+
+	return rules, err
+}
+
+// EnabledEmailRules returns the enabled email rules in the order they are checked.
+func (bc *BaseController) EnabledEmailRules() ([]models.EmailRule, error) {
+	rules := []models.EmailRule{}
+	err := bc.DB.Where("enabled = ?", true).Order("priority, id").Find(&rules).Error
+
+	return rules, err
+}
+
+// GetEmailRule returns an email rule by ID.
+//
+// Returns:
+// - ErrEmailRuleNotFound if no rule has the given ID.
+func (bc *BaseController) GetEmailRule(id uint) (models.EmailRule, error) {
+	var rule models.EmailRule
+
+	err := bc.DB.First(&rule, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return rule, ErrEmailRuleNotFound
+	}
+
+	return rule, err
+}
+
+// CreateEmailRule stores a new email rule.
+func (bc *BaseController) CreateEmailRule(rule *models.EmailRule) error {
+	rule.ID = 0
+	rule.LastMatchedAt = nil
+	rule.LastError = ""
+
+	return bc.DB.Create(rule).Error
+}
+
+// UpdateEmailRule replaces an existing email rule, keeping the state of its last match.
+//
+// Returns:
+// - ErrEmailRuleNotFound if no rule has the given ID.
+func (bc *BaseController) UpdateEmailRule(id uint, rule *models.EmailRule) error {
+	existing, err := bc.GetEmailRule(id)
+	if err != nil {
+		return err
+	}
+
+	rule.ID = existing.ID
+	rule.CreatedAt = existing.CreatedAt
+	rule.LastMatchedAt = existing.LastMatchedAt
+	rule.LastError = existing.LastError
+
+	return bc.DB.Save(rule).Error
+}
+
+// DeleteEmailRule removes an email rule. The records it created are kept.
+//
+// Returns:
+// - ErrEmailRuleNotFound if no rule has the given ID.
+func (bc *BaseController) DeleteEmailRule(id uint) error {
+	res := bc.DB.Delete(&models.EmailRule{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return ErrEmailRuleNotFound
+	}
+
+	return nil
+}
+
+// SetEmailRuleResult stores the time of the last email matched by a rule and
+// the error of its record, or clears it if err is nil.
+func (bc *BaseController) SetEmailRuleResult(id uint, matchedAt time.Time, err error) error {
+	message := ""
+	if err != nil {
+		message = err.Error()
+	}
+
+	return bc.DB.Model(&models.EmailRule{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"last_matched_at": matchedAt, "last_error": message}).Error
+}
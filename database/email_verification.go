@@ -0,0 +1,108 @@
+package database
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/r4ulcl/api_template/utils/models"
+	"gorm.io/gorm"
+)
+
+// ErrInvalidToken is returned when a verification token does not exist or has expired.
+var ErrInvalidToken = errors.New("invalid or expired token")
+
+// GetUserByEmail retrieves a user by email address.
+//
+// Returns:
+// - gorm.ErrRecordNotFound if no user has the email.
+func (bc *BaseController) GetUserByEmail(email string) (models.User, error) {
+	var user models.User
+	err := bc.DB.Where("email = ?", email).First(&user).Error
+
+	return user, err
+}
+
+// CreateEmailVerification creates a verification token for the email of a user.
+//
+// Previous tokens of the user are discarded, so only the latest link works.
+//
+// Parameters:
+// - username: The user whose email is verified.
+// - email: The address the token is sent to.
+// - ttl: How long the token stays valid.
+//
+// Returns:
+// - The token to send to the user. Only its hash is stored.
+// - An error if the token cannot be stored.
+func (bc *BaseController) CreateEmailVerification(username, email string, ttl time.Duration) (string, error) {
+	token, err := randomHex(32)
+	if err != nil {
+		return "", err
+	}
+
+	err = bc.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("username = ?", username).Delete(&models.EmailVerification{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&models.EmailVerification{
+			TokenHash: hashToken(token),
+			Username:  username,
+			Email:     email,
+			ExpiresAt: time.Now().Add(ttl),
+		}).Error
+	})
+
+	return token, err
+}
+
+// VerifyEmail consumes a verification token and marks the email of its user as verified.
+//
+// Returns:
+// - The verified user.
+// - ErrInvalidToken if the token is unknown, expired, or the user changed the email since.
+func (bc *BaseController) VerifyEmail(token string) (models.User, error) {
+	var user models.User
+
+	err := bc.DB.Transaction(func(tx *gorm.DB) error {
+		var verification models.EmailVerification
+
+		err := tx.Where("token_hash = ?", hashToken(token)).First(&verification).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidToken
+		} else if err != nil {
+			return err
+		}
+
+		if err := tx.Delete(&verification).Error; err != nil {
+			return err
+		}
+
+		if time.Now().After(verification.ExpiresAt) {
+			return ErrInvalidToken
+		}
+
+		err = tx.Where("username = ? AND email = ?", verification.Username, verification.Email).First(&user).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidToken
+		} else if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+
+		return tx.Model(&user).Update("email_verified_at", now).Error
+	})
+
+	return user, err
+}
+
+// hashToken returns the hex-encoded SHA-256 hash of a token.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,58 @@
+package database
+
+import (
+	"gorm.io/gorm"
+)
+
+// EncryptionStatus reports how the data of the database is protected.
+type EncryptionStatus struct {
+	// Cipher is the TLS cipher of the connection, or empty if it is not encrypted.
+	Cipher string
+
+	// EncryptedTables is the number of tables of the schema encrypted at rest.
+	EncryptedTables int64
+
+	// Tables is the number of tables of the schema.
+	Tables int64
+}
+
+// Encryption reports whether the connection is encrypted in transit and how
+// many tables are encrypted at rest (InnoDB tablespace encryption), as seen by
+// the MySQL server.
+//
+// Parameters:
+// - db: The database connection to check.
+//
+// Returns:
+// - The encryption status.
+// - An error if the server does not report it.
+func Encryption(db *gorm.DB) (EncryptionStatus, error) {
+	var status EncryptionStatus
+
+	// Value is the column of the value in the SHOW STATUS results
+	var variable struct{ Value string }
+
+	// Every connection of the pool is opened with the same DSN, so one session tells for all
+	if err := db.Raw("SHOW SESSION STATUS LIKE 'Ssl_cipher'").Scan(&variable).Error; err != nil {
+		return status, err
+	}
+
+	var tables struct {
+		TotalTables     int64
+		EncryptedTables int64
+	}
+
+	err := db.Raw("SELECT COUNT(*) AS total_tables, " +
+		"COALESCE(SUM(UPPER(CREATE_OPTIONS) LIKE '%ENCRYPTION=''Y''%'), 0) AS encrypted_tables " +
+		"FROM information_schema.TABLES WHERE TABLE_SCHEMA = DATABASE() AND TABLE_TYPE = 'BASE TABLE'").
+		Scan(&tables).Error
+	if err != nil {
+		return status, err
+	}
+
+	status.Cipher = variable.Value
+	status.Tables = tables.TotalTables
+	status.EncryptedTables = tables.EncryptedTables
+
+	return status, nil
+}
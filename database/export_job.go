@@ -0,0 +1,71 @@
+package database
+
+import (
+	"errors"
+	"time"
+
+	"github.com/r4ulcl/api_template/utils/models"
+	"gorm.io/gorm"
+)
+
+// ErrExportJobNotFound is returned when an export does not exist.
+var ErrExportJobNotFound = errors.New("export not found")
+
+// CreateExportJob stores a new queued export.
+func (bc *BaseController) CreateExportJob(job *models.ExportJob) error {
+	job.ID = 0
+	job.Status = models.BulkJobQueued
+	job.Parts = []models.ExportPart{}
+
+	return bc.DB.Create(job).Error
+}
+
+// GetExportJob returns an export by ID.
+//
+// Returns:
+// - ErrExportJobNotFound if no export has the given ID.
+func (bc *BaseController) GetExportJob(id uint) (models.ExportJob, error) {
+	var job models.ExportJob
+
+	err := bc.DB.First(&job, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return job, ErrExportJobNotFound
+	}
+
+	return job, err
+}
+
+// SaveExportJob stores the progress of an export.
+func (bc *BaseController) SaveExportJob(job *models.ExportJob) error {
+	return bc.DB.Save(job).Error
+}
+
+// ExpiredExportJobs returns the exports expired at a time.
+func (bc *BaseController) ExpiredExportJobs(now time.Time) ([]models.ExportJob, error) {
+	jobs := []models.ExportJob{}
+	err := bc.DB.Where("expires_at <= ?", now).Find(&jobs).Error
+
+	return jobs, err
+}
+
+// DeleteExportJob removes an export. Its files are deleted by the caller.
+func (bc *BaseController) DeleteExportJob(id uint) error {
+	return bc.DB.Delete(&models.ExportJob{}, id).Error
+}
+
+// FailInterruptedExportJobs marks the exports left queued or running by a
+// previous run of the server as failed, expiring them so their partial files
+// are deleted.
+//
+// Returns:
+// - The number of exports marked as failed.
+func (bc *BaseController) FailInterruptedExportJobs(now time.Time) (int64, error) {
+	res := bc.DB.Model(&models.ExportJob{}).
+		Where("status IN ?", []models.BulkJobStatus{models.BulkJobQueued, models.BulkJobRunning}).
+		Updates(map[string]interface{}{
+			"status": models.BulkJobFailed, "error": "interrupted by a restart of the server",
+			"finished_at": now, "expires_at": now,
+		})
+
+	return res.RowsAffected, res.Error
+}
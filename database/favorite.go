@@ -0,0 +1,159 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/r4ulcl/api_template/utils/models"
+	"gorm.io/gorm"
+)
+
+// maxFavorites is the number of records a user can pin.
+const maxFavorites = 500
+
+var (
+	// ErrFavoriteNotFound is returned when a record is not in the favorites of a user.
+	ErrFavoriteNotFound = errors.New("favorite not found")
+
+	// ErrFavoriteLimit is returned when a user already pinned maxFavorites records.
+	ErrFavoriteLimit = errors.New("too many favorites")
+)
+
+// ListFavorites returns the favorites of a user, most recent first.
+func (bc *BaseController) ListFavorites(username string) ([]models.UserFavorite, error) {
+	favorites := []models.UserFavorite{}
+
+	err := bc.DB.Where("username = ?", username).Order("created_at DESC").Find(&favorites).Error
+
+	return favorites, err
+}
+
+// AddFavorite pins a record for a user. Pinning a record twice keeps the first favorite.
+//
+// Parameters:
+// - username: The user pinning the record.
+// - resource: The name of the resource (e.g. "example1").
+// - id: The tokenized ID of the record.
+//
+// Returns:
+// - The stored favorite, and whether it was created.
+// - ErrFavoriteLimit if the user already pinned maxFavorites records.
+func (bc *BaseController) AddFavorite(username, resource, id string) (models.UserFavorite, bool, error) {
+	var favorite models.UserFavorite
+
+	created := false
+
+	err := bc.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("username = ? AND resource = ? AND record_id = ?", username, resource, id).First(&favorite).Error
+		if err == nil || !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		var count int64
+		if err := tx.Model(&models.UserFavorite{}).Where("username = ?", username).Count(&count).Error; err != nil {
+			return err
+		}
+
+		if count >= maxFavorites {
+			return fmt.Errorf("%w: at most %d records can be pinned", ErrFavoriteLimit, maxFavorites)
+		}
+
+		favorite = models.UserFavorite{Username: username, Resource: resource, RecordID: id}
+		created = true
+
+		return tx.Create(&favorite).Error
+	})
+	if err != nil && IsDuplicateKeyError(err) {
+		// The same record was pinned by a concurrent request
+		return bc.getFavorite(username, resource, id)
+	}
+
+	return favorite, created, err
+}
+
+// getFavorite returns a favorite of a user.
+func (bc *BaseController) getFavorite(username, resource, id string) (models.UserFavorite, bool, error) {
+	var favorite models.UserFavorite
+
+	err := bc.DB.Where("username = ? AND resource = ? AND record_id = ?", username, resource, id).First(&favorite).Error
+
+	return favorite, false, err
+}
+
+// RemoveFavorite unpins a record for a user.
+//
+// Returns:
+// - ErrFavoriteNotFound if the record is not in the favorites of the user.
+func (bc *BaseController) RemoveFavorite(username, resource, id string) error {
+	res := bc.DB.Where("username = ? AND resource = ? AND record_id = ?", username, resource, id).
+		Delete(&models.UserFavorite{})
+	if res.Error != nil {
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return ErrFavoriteNotFound
+	}
+
+	return nil
+}
+
+// DeleteRecordFavorites unpins a record for every user, e.g. once the record is deleted.
+func (bc *BaseController) DeleteRecordFavorites(resource, id string) error {
+	return bc.DB.Where("resource = ? AND record_id = ?", resource, id).Delete(&models.UserFavorite{}).Error
+}
+
+// GetRecordsByIDs retrieves the records of a model with the given tokenized IDs
+// in a single query. IDs that don't match the primary key of the model are ignored.
+//
+// Parameters:
+// - model: A pointer to a struct of the model; it is not modified.
+// - ids: The tokenized IDs, with composite keys separated by "-".
+//
+// Returns:
+// - The records found, as pointers to structs, keyed by tokenized ID.
+func (bc *BaseController) GetRecordsByIDs(model interface{}, ids []string) (map[string]interface{}, error) {
+	sch, err := bc.modelSchema(model)
+	if err != nil {
+		return nil, err
+	}
+
+	var cond *gorm.DB
+
+	for _, id := range ids {
+		parts := strings.Split(id, "-")
+		if len(parts) != len(sch.PrimaryFields) {
+			continue
+		}
+
+		group := bc.DB.Session(&gorm.Session{NewDB: true})
+		for i, field := range sch.PrimaryFields {
+			group = group.Where(bc.DB.Statement.Quote(field.DBName)+" = ?", parts[i])
+		}
+
+		if cond == nil {
+			cond = group
+		} else {
+			cond = cond.Or(group)
+		}
+	}
+
+	records := make(map[string]interface{})
+	if cond == nil {
+		return records, nil
+	}
+
+	rows := reflect.New(reflect.SliceOf(reflect.TypeOf(model).Elem()))
+	if err := bc.DB.Where(cond).Find(rows.Interface()).Error; err != nil {
+		return nil, err
+	}
+
+	for i := range rows.Elem().Len() {
+		record := rows.Elem().Index(i).Addr().Interface()
+		records[RecordID(record)] = record
+	}
+
+	return records, nil
+}
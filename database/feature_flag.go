@@ -0,0 +1,140 @@
+package database
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/r4ulcl/api_template/utils/models"
+	"gorm.io/gorm"
+)
+
+// ErrFlagNotFound is returned when a feature flag does not exist.
+var ErrFlagNotFound = errors.New("feature flag not found")
+
+// ListFeatureFlags returns every feature flag ordered by name.
+func (bc *BaseController) ListFeatureFlags() ([]models.FeatureFlag, error) {
+	flags := []models.FeatureFlag{}
+	err := bc.DB.Order("name").Find(&flags).Error
+
+	return flags, err
+}
+
+// GetFeatureFlag returns a feature flag by name.
+//
+// Returns:
+// - ErrFlagNotFound if no flag has the given name.
+func (bc *BaseController) GetFeatureFlag(name string) (models.FeatureFlag, error) {
+	var flag models.FeatureFlag
+
+	err := bc.DB.Where("name = ?", name).First(&flag).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return flag, ErrFlagNotFound
+	}
+
+	return flag, err
+}
+
+// SaveFeatureFlag creates a feature flag or replaces the existing one with the same name.
+func (bc *BaseController) SaveFeatureFlag(flag *models.FeatureFlag) error {
+	var existing models.FeatureFlag
+
+	err := bc.DB.Where("name = ?", flag.Name).First(&existing).Error
+
+	switch {
+	case err == nil:
+		flag.CreatedAt = existing.CreatedAt
+
+		return bc.DB.Save(flag).Error
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return bc.DB.Create(flag).Error
+	default:
+		return err
+	}
+}
+
+// DeleteFeatureFlag removes a feature flag.
+//
+// Returns:
+// - ErrFlagNotFound if no flag has the given name.
+func (bc *BaseController) DeleteFeatureFlag(name string) error {
+	res := bc.DB.Where("name = ?", name).Delete(&models.FeatureFlag{})
+	if res.Error != nil {
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return ErrFlagNotFound
+	}
+
+	return nil
+}
+
+// FeatureFlags keeps the feature flags in memory so they can be checked on
+// every request without querying the database.
+//
+// Call Reload after the flags change.
+type FeatureFlags struct {
+	bc *BaseController
+
+	mu    sync.RWMutex
+	flags map[string]models.FeatureFlag
+}
+
+// NewFeatureFlags creates an empty flag cache backed by the database.
+func NewFeatureFlags(bc *BaseController) *FeatureFlags {
+	return &FeatureFlags{bc: bc, flags: map[string]models.FeatureFlag{}}
+}
+
+// Reload loads the flags from the database.
+func (ff *FeatureFlags) Reload() error {
+	list, err := ff.bc.ListFeatureFlags()
+	if err != nil {
+		return err
+	}
+
+	flags := make(map[string]models.FeatureFlag, len(list))
+	for _, flag := range list {
+		flags[flag.Name] = flag
+	}
+
+	ff.mu.Lock()
+	defer ff.mu.Unlock()
+
+	ff.flags = flags
+
+	return nil
+}
+
+// FlagEnabled reports whether a flag is on for a user. Unknown flags are off.
+// It is safe to call on a nil *FeatureFlags.
+func (ff *FeatureFlags) FlagEnabled(name, username, role string) bool {
+	if ff == nil {
+		return false
+	}
+
+	ff.mu.RLock()
+	defer ff.mu.RUnlock()
+
+	flag, ok := ff.flags[name]
+
+	return ok && flag.EnabledFor(username, role)
+}
+
+// Evaluate returns the state of every flag for a user.
+// It is safe to call on a nil *FeatureFlags.
+func (ff *FeatureFlags) Evaluate(username, role string) map[string]bool {
+	result := map[string]bool{}
+
+	if ff == nil {
+		return result
+	}
+
+	ff.mu.RLock()
+	defer ff.mu.RUnlock()
+
+	for name, flag := range ff.flags {
+		result[name] = flag.EnabledFor(username, role)
+	}
+
+	return result
+}
@@ -0,0 +1,380 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"gorm.io/gorm/schema"
+)
+
+// ErrInvalidFilter is returned when a filter key or operator can't be parsed.
+var ErrInvalidFilter = errors.New("invalid filter")
+
+// FilterOp is the comparison applied by a filter.
+type FilterOp string
+
+const (
+	// FilterEq matches records whose field is equal to the value.
+	FilterEq FilterOp = "eq"
+
+	// FilterNe matches records whose field is not equal to the value.
+	FilterNe FilterOp = "ne"
+
+	// FilterGt matches records whose field is greater than the value.
+	FilterGt FilterOp = "gt"
+
+	// FilterGte matches records whose field is greater than or equal to the value.
+	FilterGte FilterOp = "gte"
+
+	// FilterLt matches records whose field is less than the value.
+	FilterLt FilterOp = "lt"
+
+	// FilterLte matches records whose field is less than or equal to the value.
+	FilterLte FilterOp = "lte"
+
+	// FilterLike matches records whose field matches the SQL LIKE pattern of the value.
+	FilterLike FilterOp = "like"
+
+	// FilterIn matches records whose field is one of the comma-separated values.
+	FilterIn FilterOp = "in"
+
+	// FilterNear matches records whose models.GeoPoint field lies within a radius
+	// of a point, given as "lat,lon,radius_km".
+	FilterNear FilterOp = "near"
+)
+
+// filterOperators maps each operator to its SQL template.
+var filterOperators = map[FilterOp]string{
+	FilterEq:   "%s = ?",
+	FilterNe:   "%s <> ?",
+	FilterGt:   "%s > ?",
+	FilterGte:  "%s >= ?",
+	FilterLt:   "%s < ?",
+	FilterLte:  "%s <= ?",
+	FilterLike: "%s LIKE ?",
+	FilterIn:   "%s IN ?",
+	FilterNear: "%s <= ?",
+}
+
+// Filter is a node of the filter AST: a comparison between a field and a value.
+type Filter struct {
+	// Field is the field name (JSON name, struct name or column name).
+	Field string
+
+	// Op is the comparison operator.
+	Op FilterOp
+
+	// Value is the raw value from the query string.
+	Value string
+}
+
+// ParseFilter parses a query parameter into a filter.
+//
+// The key is either a plain field name ("field2", equality) or uses the bracket
+// syntax "filter[field2]" (equality) or "filter[field2][op]" with one of the
+// FilterOp operators.
+//
+// Parameters:
+// - key: The query parameter name.
+// - value: The query parameter value.
+//
+// Returns:
+// - The parsed filter.
+// - ErrInvalidFilter if the key is malformed or the operator is unknown.
+func ParseFilter(key, value string) (Filter, error) {
+	p := filterParser{input: key}
+
+	filter, err := p.parse()
+	if err != nil {
+		return Filter{}, fmt.Errorf("%w: %q: %s", ErrInvalidFilter, key, err.Error())
+	}
+
+	filter.Value = value
+
+	return filter, nil
+}
+
+// filterParser is a recursive-descent parser of filter keys:
+//
+//	key     = field | "filter" "[" field "]" [ "[" op "]" ]
+//	field   = name
+//	op      = name
+type filterParser struct {
+	input string
+	pos   int
+}
+
+// parse parses the whole key.
+func (p *filterParser) parse() (Filter, error) {
+	name := p.name()
+	if name == "" {
+		return Filter{}, errors.New("missing field name")
+	}
+
+	if p.done() {
+		return Filter{Field: name, Op: FilterEq}, nil
+	}
+
+	if name != "filter" {
+		return Filter{}, fmt.Errorf("unexpected %q at %d", p.input[p.pos], p.pos)
+	}
+
+	field, err := p.bracket()
+	if err != nil {
+		return Filter{}, err
+	}
+
+	filter := Filter{Field: field, Op: FilterEq}
+
+	if !p.done() {
+		op, err := p.bracket()
+		if err != nil {
+			return Filter{}, err
+		}
+
+		filter.Op = FilterOp(strings.ToLower(op))
+		if _, ok := filterOperators[filter.Op]; !ok {
+			return Filter{}, fmt.Errorf("unknown operator %q", op)
+		}
+	}
+
+	if !p.done() {
+		return Filter{}, fmt.Errorf("unexpected %q at %d", p.input[p.pos], p.pos)
+	}
+
+	return filter, nil
+}
+
+// bracket parses "[" name "]".
+func (p *filterParser) bracket() (string, error) {
+	if p.done() || p.input[p.pos] != '[' {
+		return "", fmt.Errorf("expected '[' at %d", p.pos)
+	}
+
+	p.pos++
+
+	name := p.name()
+	if name == "" {
+		return "", fmt.Errorf("expected a name at %d", p.pos)
+	}
+
+	if p.done() || p.input[p.pos] != ']' {
+		return "", fmt.Errorf("expected ']' at %d", p.pos)
+	}
+
+	p.pos++
+
+	return name, nil
+}
+
+// name parses a run of letters, digits, underscores, dots and dashes.
+func (p *filterParser) name() string {
+	start := p.pos
+
+	for !p.done() {
+		c := p.input[p.pos]
+		if c != '_' && c != '.' && c != '-' &&
+			(c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			break
+		}
+
+		p.pos++
+	}
+
+	return p.input[start:p.pos]
+}
+
+// done reports whether the whole input has been consumed.
+func (p *filterParser) done() bool {
+	return p.pos >= len(p.input)
+}
+
+// filterPlan is a filter set compiled to a parameterized SQL condition.
+type filterPlan struct {
+	sql string
+	ops []FilterOp
+
+	// numeric flags the range filters on JSON paths, whose values are compared as numbers.
+	numeric []bool
+
+	// dialect is the database dialect the SQL was built for.
+	dialect string
+}
+
+// maxFilterPlans caps the number of cached plans, since clients choose the shapes.
+const maxFilterPlans = 1024
+
+var (
+	// filterPlans caches the compiled plans by table and filter shape.
+	filterPlans sync.Map
+
+	// filterPlanCount is the number of plans in filterPlans.
+	filterPlanCount atomic.Int64
+)
+
+// compileFilters returns the SQL condition and arguments of a filter set.
+//
+// Filters are sorted so the same set of fields and operators (the shape) always
+// produces the same plan, which is compiled once per table and dialect and cached.
+// Only the arguments change between requests.
+//
+// Returns ErrInvalidField if a field does not exist on the model.
+func compileFilters(sch *schema.Schema, dialect string, filters []Filter) (string, []interface{}, error) {
+	sorted := slices.Clone(filters)
+	slices.SortFunc(sorted, func(a, b Filter) int {
+		if c := strings.Compare(a.Field, b.Field); c != 0 {
+			return c
+		}
+
+		return strings.Compare(string(a.Op), string(b.Op))
+	})
+
+	shape := dialect + "|" + sch.Table
+	for _, filter := range sorted {
+		shape += "|" + filter.Field + ":" + string(filter.Op)
+	}
+
+	cached, ok := filterPlans.Load(shape)
+	if !ok {
+		plan, err := newFilterPlan(sch, dialect, sorted)
+		if err != nil {
+			return "", nil, err
+		}
+
+		cached = plan
+
+		if filterPlanCount.Load() < maxFilterPlans {
+			if _, loaded := filterPlans.LoadOrStore(shape, plan); !loaded {
+				filterPlanCount.Add(1)
+			}
+		}
+	}
+
+	plan, _ := cached.(filterPlan)
+	args := make([]interface{}, 0, len(sorted))
+
+	for i, filter := range sorted {
+		switch {
+		case plan.ops[i] == FilterIn:
+			args = append(args, splitValues(filter.Value))
+		case plan.ops[i] == FilterNear:
+			lat, lon, radius, err := parseNear(filter.Value)
+			if err != nil {
+				return "", nil, err
+			}
+
+			args = append(append(args, distanceArgs(plan.dialect, lat, lon)...), radius)
+		case plan.numeric[i]:
+			if number, err := strconv.ParseFloat(filter.Value, 64); err == nil {
+				args = append(args, number)
+			} else {
+				args = append(args, filter.Value)
+			}
+		default:
+			args = append(args, filter.Value)
+		}
+	}
+
+	return plan.sql, args, nil
+}
+
+// newFilterPlan validates the fields of sorted filters and builds their SQL condition.
+func newFilterPlan(sch *schema.Schema, dialect string, sorted []Filter) (filterPlan, error) {
+	conditions := make([]string, len(sorted))
+	ops := make([]FilterOp, len(sorted))
+	numeric := make([]bool, len(sorted))
+
+	for i, filter := range sorted {
+		if filter.Op == FilterNear {
+			latColumn, lonColumn, ok := geoColumns(sch, filter.Field)
+			if !ok {
+				return filterPlan{}, fmt.Errorf("%w: %s is not a location field", ErrInvalidField, filter.Field)
+			}
+
+			conditions[i] = fmt.Sprintf(filterOperators[FilterNear], distanceSQL(dialect, latColumn, lonColumn, "?", "?"))
+			ops[i] = filter.Op
+
+			continue
+		}
+
+		column, err := lookupColumn(sch, filter.Field)
+		if errors.Is(err, ErrInvalidField) && strings.Contains(filter.Field, ".") {
+			column, err = jsonPathExpression(sch, dialect, filter.Field)
+			numeric[i] = filter.Op == FilterGt || filter.Op == FilterGte || filter.Op == FilterLt || filter.Op == FilterLte
+		}
+
+		if err != nil {
+			return filterPlan{}, err
+		}
+
+		template, ok := filterOperators[filter.Op]
+		if !ok {
+			return filterPlan{}, fmt.Errorf("%w: unknown operator %q", ErrInvalidFilter, filter.Op)
+		}
+
+		conditions[i] = fmt.Sprintf(template, column)
+		ops[i] = filter.Op
+	}
+
+	return filterPlan{sql: strings.Join(conditions, " AND "), ops: ops, numeric: numeric, dialect: dialect}, nil
+}
+
+// jsonPathExpression returns the SQL expression extracting a path from a JSON
+// column, for a field name like "meta.color" or "meta.tags.0".
+//
+// The first segment must be a field of type models.JSON (a "json" column). The
+// others are object keys, or array indexes when they are numbers. On MySQL the
+// value is unquoted, so strings compare as plain text.
+//
+// Returns ErrInvalidField if the field is not a JSON column or the path is malformed.
+func jsonPathExpression(sch *schema.Schema, dialect, name string) (string, error) {
+	segments := strings.Split(name, ".")
+
+	column, err := lookupColumn(sch, segments[0])
+	if err != nil {
+		return "", err
+	}
+
+	if field := sch.LookUpField(column); field == nil || field.DataType != "json" {
+		return "", fmt.Errorf("%w: %s is not a JSON field", ErrInvalidField, segments[0])
+	}
+
+	path := "$"
+
+	for _, segment := range segments[1:] {
+		switch {
+		case !jsonPathSegmentPattern.MatchString(segment):
+			return "", fmt.Errorf("%w: %s", ErrInvalidField, name)
+		case strings.Trim(segment, "0123456789") == "":
+			path += "[" + segment + "]"
+		default:
+			// Quoted keys may contain dashes
+			path += `."` + segment + `"`
+		}
+	}
+
+	if dialect == "mysql" {
+		return fmt.Sprintf("JSON_UNQUOTE(JSON_EXTRACT(%s, '%s'))", column, path), nil
+	}
+
+	return fmt.Sprintf("json_extract(%s, '%s')", column, path), nil
+}
+
+// jsonPathSegmentPattern matches the keys allowed in a JSON path filter.
+var jsonPathSegmentPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// splitValues splits the comma-separated values of an "in" filter.
+func splitValues(value string) []string {
+	values := strings.Split(value, ",")
+	for i := range values {
+		values[i] = strings.TrimSpace(values[i])
+	}
+
+	return values
+}
@@ -0,0 +1,288 @@
+package database
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	mathrand "math/rand/v2"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/r4ulcl/api_template/utils"
+	"gorm.io/gorm/schema"
+)
+
+const (
+	// maxGeneratorParents is the largest number of referenced records a foreign key is picked from.
+	maxGeneratorParents = 10000
+
+	// maxGeneratorAttempts is how many times a new combination of referenced
+	// records is picked for a primary key made of foreign keys.
+	maxGeneratorAttempts = 100
+
+	// generatorDistinctValues is the number of distinct values of the fields
+	// that are not unique, so filters match several records.
+	generatorDistinctValues = 100
+
+	// generatorTimeSpan is how far back the times are spread.
+	generatorTimeSpan = 365 * 24 * time.Hour
+)
+
+var (
+	// ErrNoParentRecords is returned when a foreign key references a table without records.
+	ErrNoParentRecords = errors.New("the referenced table has no records")
+
+	// ErrGeneratorExhausted is returned when every combination of referenced records is already used.
+	ErrGeneratorExhausted = errors.New("no unused combination of referenced records left")
+)
+
+// RecordGenerator fills new records of a model with synthetic values, e.g. to
+// exercise the pagination or load test a table. See NewRecordGenerator.
+type RecordGenerator struct {
+	schema *schema.Schema
+	prefix string
+
+	// parents holds the keys of the referenced records of each foreign key field.
+	parents map[string]reflect.Value
+
+	// unique holds the names of the fields with a unique index of their own.
+	unique map[string]bool
+
+	// keys holds the primary keys used, for the primary keys made of foreign keys.
+	keys map[string]bool
+}
+
+// NewRecordGenerator creates a generator of records of a model, following the
+// types and constraints of its columns:
+// - Primary keys and unique columns get values unique to the generator (emails
+// end in @example.com), the other strings one of a few values, so filters match.
+// - Foreign keys reference random existing records, and primary keys made of
+// foreign keys don't repeat a stored combination.
+// - Enums (see utils.EnumValues) get one of their values, numbers, booleans and
+// times (in the last year) random values, "lat" and "lon" fields valid coordinates.
+//
+// Auto-incremented keys, read-only columns, fields populated by the server (see
+// utils.PopulateFields) and optional (pointer) fields are left empty, as well as
+// the types without a random value, like JSON fields.
+//
+// Parameters:
+// - model: A pointer to the struct of the model.
+//
+// Returns:
+// - ErrNoParentRecords if a foreign key references an empty table.
+func (bc *BaseController) NewRecordGenerator(model interface{}) (*RecordGenerator, error) {
+	sch, err := bc.modelSchema(model)
+	if err != nil {
+		return nil, err
+	}
+
+	token := make([]byte, 4)
+	if _, err := rand.Read(token); err != nil {
+		return nil, err
+	}
+
+	g := &RecordGenerator{
+		schema:  sch,
+		prefix:  "gen_" + hex.EncodeToString(token),
+		parents: map[string]reflect.Value{},
+		unique:  map[string]bool{},
+	}
+
+	for _, index := range sch.ParseIndexes() {
+		if index.Class == "UNIQUE" && len(index.Fields) == 1 {
+			g.unique[index.Fields[0].Name] = true
+		}
+	}
+
+	keyReferences := false
+
+	for _, relation := range sch.Relationships.Relations {
+		if relation.Type != schema.BelongsTo {
+			continue
+		}
+
+		for _, ref := range relation.References {
+			if ref.PrimaryKey == nil || ref.ForeignKey == nil {
+				continue
+			}
+
+			keys := reflect.New(reflect.SliceOf(ref.PrimaryKey.FieldType))
+
+			err := bc.DB.Model(reflect.New(relation.FieldSchema.ModelType).Interface()).
+				Limit(maxGeneratorParents).Pluck(ref.PrimaryKey.DBName, keys.Interface()).Error
+			if err != nil {
+				return nil, err
+			}
+
+			if keys.Elem().Len() == 0 {
+				return nil, fmt.Errorf("%w: %s", ErrNoParentRecords, relation.FieldSchema.Table)
+			}
+
+			g.parents[ref.ForeignKey.Name] = keys.Elem()
+			keyReferences = keyReferences || ref.ForeignKey.PrimaryKey
+		}
+	}
+
+	if keyReferences {
+		if err := g.loadKeys(bc, model); err != nil {
+			return nil, err
+		}
+	}
+
+	return g, nil
+}
+
+// loadKeys reads the stored primary keys, so they are not generated again.
+func (g *RecordGenerator) loadKeys(bc *BaseController, model interface{}) error {
+	columns := make([]string, len(g.schema.PrimaryFields))
+	for i, field := range g.schema.PrimaryFields {
+		columns[i] = field.DBName
+	}
+
+	rows, err := bc.DB.Model(model).Select(columns).Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	g.keys = map[string]bool{}
+
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		pointers := make([]interface{}, len(columns))
+
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+
+		if err := rows.Scan(pointers...); err != nil {
+			return err
+		}
+
+		// The drivers scan strings as bytes
+		for i, value := range values {
+			if bytes, ok := value.([]byte); ok {
+				values[i] = string(bytes)
+			}
+		}
+
+		g.keys[fmt.Sprint(values...)] = true
+	}
+
+	return rows.Err()
+}
+
+// Fill sets the fields of a new record to synthetic values.
+//
+// A generator is not safe for concurrent use.
+//
+// Parameters:
+// - index: The index of the record, making its unique values unique.
+// - model: A pointer to a new record of the model of the generator.
+//
+// Returns:
+// - ErrGeneratorExhausted if no new primary key can be made of the referenced records.
+func (g *RecordGenerator) Fill(index int, model interface{}) error {
+	ctx := context.Background()
+	value := reflect.ValueOf(model)
+
+	for attempt := 0; ; attempt++ {
+		for _, field := range g.schema.Fields {
+			if fieldValue, ok := g.value(field, index); ok {
+				if err := field.Set(ctx, value, fieldValue); err != nil {
+					return err
+				}
+			}
+		}
+
+		if g.keys == nil {
+			return nil
+		}
+
+		keys := make([]interface{}, len(g.schema.PrimaryFields))
+		for i, field := range g.schema.PrimaryFields {
+			keys[i], _ = field.ValueOf(ctx, value)
+		}
+
+		if key := fmt.Sprint(keys...); !g.keys[key] {
+			g.keys[key] = true
+
+			return nil
+		}
+
+		if attempt == maxGeneratorAttempts {
+			return ErrGeneratorExhausted
+		}
+	}
+}
+
+// value returns the synthetic value of a field, and false if the field is left empty.
+func (g *RecordGenerator) value(field *schema.Field, index int) (interface{}, bool) {
+	if field.DBName == "" || !field.Creatable || field.AutoIncrement || field.FieldType.Kind() == reflect.Ptr ||
+		utils.ServerPopulated(field.StructField) {
+		return nil, false
+	}
+
+	if parents, ok := g.parents[field.Name]; ok {
+		return parents.Index(mathrand.IntN(parents.Len())).Interface(), true
+	}
+
+	if values := utils.EnumValues(field.StructField); values != nil {
+		return reflect.ValueOf(values[mathrand.IntN(len(values))]).Convert(field.FieldType).Interface(), true
+	}
+
+	typ := field.FieldType
+
+	switch {
+	case typ == reflect.TypeOf(time.Time{}):
+		return time.Now().Add(-time.Duration(mathrand.Int64N(int64(generatorTimeSpan)))).UTC(), true
+	case typ.Kind() == reflect.String:
+		return g.stringValue(field, index), true
+	case typ.Kind() == reflect.Bool:
+		return mathrand.IntN(2) == 1, true
+	case typ.Kind() >= reflect.Int && typ.Kind() <= reflect.Uint64:
+		if field.PrimaryKey || field.Unique || g.unique[field.Name] {
+			return index + 1, true
+		}
+
+		return mathrand.IntN(generatorDistinctValues * 10), true
+	case typ.Kind() == reflect.Float32 || typ.Kind() == reflect.Float64:
+		switch strings.ToLower(field.Name) {
+		case "lat", "latitude":
+			return mathrand.Float64()*180 - 90, true
+		case "lon", "lng", "longitude":
+			return mathrand.Float64()*360 - 180, true
+		}
+
+		return mathrand.Float64() * generatorDistinctValues * 10, true
+	default:
+		return nil, false
+	}
+}
+
+// stringValue returns the synthetic value of a string field, unique to the
+// generator for the primary keys and unique columns.
+func (g *RecordGenerator) stringValue(field *schema.Field, index int) string {
+	var value string
+
+	if field.PrimaryKey || field.Unique || g.unique[field.Name] {
+		// The API splits the IDs of composite keys on "-"
+		value = fmt.Sprintf("%s_%06d", g.prefix, index)
+	} else {
+		value = fmt.Sprintf("%s-%d", field.DBName, mathrand.IntN(generatorDistinctValues))
+	}
+
+	if strings.Contains(strings.ToLower(field.Name), "email") {
+		value += "@example.com"
+	}
+
+	// The end of the unique values is what makes them unique
+	if field.Size > 0 && len(value) > field.Size {
+		value = value[len(value)-field.Size:]
+	}
+
+	return value
+}
@@ -0,0 +1,136 @@
+package database
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/r4ulcl/api_template/utils/models"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// earthRadiusKm is the mean radius of the Earth used by the haversine formula.
+const earthRadiusKm = 6371.0
+
+// geoPointType is the reflect type of models.GeoPoint.
+var geoPointType = reflect.TypeOf(models.GeoPoint{})
+
+// geoColumns resolves a models.GeoPoint field (JSON name or struct name) to its
+// latitude and longitude columns.
+func geoColumns(sch *schema.Schema, name string) (string, string, bool) {
+	var parent string
+
+	for i := range sch.ModelType.NumField() {
+		field := sch.ModelType.Field(i)
+
+		jsonName := strings.Split(field.Tag.Get("json"), ",")[0]
+		if field.Type == geoPointType && (jsonName == name || field.Name == name) {
+			parent = field.Name
+
+			break
+		}
+	}
+
+	if parent == "" {
+		return "", "", false
+	}
+
+	var lat, lon string
+
+	for _, field := range sch.Fields {
+		if len(field.BindNames) != 2 || field.BindNames[0] != parent {
+			continue
+		}
+
+		switch field.Name {
+		case "Lat":
+			lat = field.DBName
+		case "Lon":
+			lon = field.DBName
+		}
+	}
+
+	return lat, lon, lat != "" && lon != ""
+}
+
+// parseNear parses the "lat,lon,radius_km" value of a near filter.
+//
+// Returns ErrInvalidFilter if a number is malformed or out of range.
+func parseNear(value string) (float64, float64, float64, error) {
+	parts := splitValues(value)
+	if len(parts) != 3 {
+		return 0, 0, 0, fmt.Errorf("%w: near expects lat,lon,radius_km, got %q", ErrInvalidFilter, value)
+	}
+
+	numbers := make([]float64, len(parts))
+
+	for i, part := range parts {
+		number, err := strconv.ParseFloat(part, 64)
+		if err != nil {
+			return 0, 0, 0, fmt.Errorf("%w: near expects lat,lon,radius_km, got %q", ErrInvalidFilter, value)
+		}
+
+		numbers[i] = number
+	}
+
+	lat, lon, radius := numbers[0], numbers[1], numbers[2]
+	if lat < -90 || lat > 90 || lon < -180 || lon > 180 || radius < 0 {
+		return 0, 0, 0, fmt.Errorf("%w: near point or radius out of range: %q", ErrInvalidFilter, value)
+	}
+
+	return lat, lon, radius, nil
+}
+
+// distanceSQL returns the SQL expression of the distance, in kilometers, between
+// the point stored in the latitude and longitude columns and the point (lat, lon).
+//
+// lat and lon are either "?" placeholders, filled with distanceArgs, or number literals.
+// MySQL uses ST_Distance_Sphere; other databases use the haversine formula.
+func distanceSQL(dialect, latColumn, lonColumn, lat, lon string) string {
+	if dialect == "mysql" {
+		return fmt.Sprintf("ST_Distance_Sphere(POINT(%s, %s), POINT(%s, %s)) / 1000", lonColumn, latColumn, lon, lat)
+	}
+
+	return fmt.Sprintf("%g * 2 * ASIN(SQRT(POWER(SIN(RADIANS(%s - %s) / 2), 2) + "+
+		"COS(RADIANS(%s)) * COS(RADIANS(%s)) * POWER(SIN(RADIANS(%s - %s) / 2), 2)))",
+		earthRadiusKm, latColumn, lat, lat, latColumn, lonColumn, lon)
+}
+
+// distanceArgs returns the arguments of the placeholders of distanceSQL, in order.
+func distanceArgs(dialect string, lat, lon float64) []interface{} {
+	if dialect == "mysql" {
+		return []interface{}{lon, lat}
+	}
+
+	return []interface{}{lat, lat, lon}
+}
+
+// distanceOrder returns the ORDER BY column sorting by the distance between a
+// models.GeoPoint field and the point of the near filter applied to it.
+func distanceOrder(sch *schema.Schema, dialect, name string, desc bool, filters []Filter) (clause.OrderByColumn, bool) {
+	latColumn, lonColumn, ok := geoColumns(sch, name)
+	if !ok {
+		return clause.OrderByColumn{}, false
+	}
+
+	for _, filter := range filters {
+		if filter.Op != FilterNear || filter.Field != name {
+			continue
+		}
+
+		lat, lon, _, err := parseNear(filter.Value)
+		if err != nil {
+			return clause.OrderByColumn{}, false
+		}
+
+		// The values are parsed numbers, so they are safe as literals
+		expression := distanceSQL(dialect, latColumn, lonColumn,
+			strconv.FormatFloat(lat, 'f', -1, 64), strconv.FormatFloat(lon, 'f', -1, 64))
+
+		return clause.OrderByColumn{Column: clause.Column{Name: expression, Raw: true}, Desc: desc}, true
+	}
+
+	return clause.OrderByColumn{}, false
+}
@@ -0,0 +1,185 @@
+package database
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/r4ulcl/api_template/utils"
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+const (
+	// minGrowthBaselineRows is the row count below which a table is too small for its growth rate to matter.
+	minGrowthBaselineRows = 100
+
+	// minGrowthBaselineBytes is the size below which a table is too small for its growth rate to matter.
+	minGrowthBaselineBytes = 1 << 20
+)
+
+// GrowthReporter receives the tables that start growing faster than the threshold.
+type GrowthReporter interface {
+	ReportTableGrowth(growth models.TableGrowth)
+}
+
+// GrowthMonitor compares the stats history snapshots to flag the tables whose
+// row count or size grows faster than a threshold, catching runaway writers early.
+type GrowthMonitor struct {
+	bc         *BaseController
+	window     time.Duration
+	threshold  float64
+	webhookURL string
+	reporter   GrowthReporter
+
+	mu      sync.Mutex
+	growing []models.TableGrowth
+}
+
+// NewGrowthMonitor creates a growth monitor.
+//
+// Parameters:
+// - bc: The controller reading the stats history.
+// - window: The period over which the growth is computed.
+// - thresholdPercent: The growth over the window, in percent, above which a table is flagged.
+// - webhookURL: Optional webhook notified when a table starts growing too fast.
+// - reporter: Optional receiver of the new alerts (e.g. the notification dispatcher).
+func NewGrowthMonitor(bc *BaseController, window time.Duration, thresholdPercent float64,
+	webhookURL string, reporter GrowthReporter,
+) *GrowthMonitor {
+	return &GrowthMonitor{
+		bc:         bc,
+		window:     window,
+		threshold:  thresholdPercent,
+		webhookURL: webhookURL,
+		reporter:   reporter,
+		growing:    []models.TableGrowth{},
+	}
+}
+
+// Check computes the growth of every table over the window and alerts on the
+// tables that just exceeded the threshold. Tables still above the threshold are
+// not alerted again until they fall below it.
+//
+// Returns:
+// - An error if the stats history cannot be read.
+func (gm *GrowthMonitor) Check() error {
+	snapshots, err := gm.bc.GetStatsHistory("", time.Now().Add(-gm.window), time.Time{})
+	if err != nil {
+		return err
+	}
+
+	growing := detectGrowth(snapshots, gm.threshold)
+
+	gm.mu.Lock()
+	previous := gm.growing
+	gm.growing = growing
+	gm.mu.Unlock()
+
+	for _, growth := range growing {
+		alerting := slices.ContainsFunc(previous, func(p models.TableGrowth) bool {
+			return p.Table == growth.Table && p.Metric == growth.Metric
+		})
+		if !alerting {
+			gm.alert(growth)
+		}
+	}
+
+	return nil
+}
+
+// Growing returns the tables currently growing faster than the threshold.
+func (gm *GrowthMonitor) Growing() []models.TableGrowth {
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+
+	return slices.Clone(gm.growing)
+}
+
+// alert logs a structured warning and notifies the reporter and the webhook, if configured.
+func (gm *GrowthMonitor) alert(growth models.TableGrowth) {
+	log.Printf("WARN table_growth table=%s metric=%s from=%d to=%d growth_percent=%.1f threshold_percent=%.1f",
+		growth.Table, growth.Metric, growth.From, growth.To, growth.GrowthPercent, growth.ThresholdPercent)
+
+	if gm.reporter != nil {
+		gm.reporter.ReportTableGrowth(growth)
+	}
+
+	if gm.webhookURL == "" {
+		return
+	}
+
+	go func() {
+		text := fmt.Sprintf("Table %s is growing too fast: %s grew %.1f%% (threshold %.1f%%)",
+			growth.Table, growth.Metric, growth.GrowthPercent, growth.ThresholdPercent)
+		if err := utils.SendWebhookAlert(gm.webhookURL, text, growth); err != nil {
+			log.Println("Error sending table growth alert:", err)
+		}
+	}()
+}
+
+// detectGrowth compares the first and last snapshot of every table and returns
+// the metrics that grew more than thresholdPercent.
+//
+// The snapshots must be sorted by time, as returned by GetStatsHistory.
+func detectGrowth(snapshots []models.StatsSnapshot, thresholdPercent float64) []models.TableGrowth {
+	first := map[string]models.StatsSnapshot{}
+	last := map[string]models.StatsSnapshot{}
+
+	for _, snapshot := range snapshots {
+		if _, ok := first[snapshot.Table]; !ok {
+			first[snapshot.Table] = snapshot
+		}
+
+		last[snapshot.Table] = snapshot
+	}
+
+	growing := []models.TableGrowth{}
+
+	for table, from := range first {
+		to := last[table]
+		if to.ID == from.ID {
+			continue
+		}
+
+		metrics := []struct {
+			name     string
+			from, to int64
+			baseline int64
+		}{
+			{"rows", from.Rows, to.Rows, minGrowthBaselineRows},
+			{"bytes", from.DataBytes + from.IndexBytes, to.DataBytes + to.IndexBytes, minGrowthBaselineBytes},
+		}
+
+		for _, metric := range metrics {
+			if metric.from < metric.baseline {
+				continue
+			}
+
+			percent := float64(metric.to-metric.from) * 100 / float64(metric.from)
+			if percent > thresholdPercent {
+				growing = append(growing, models.TableGrowth{
+					Table:            table,
+					Metric:           metric.name,
+					From:             metric.from,
+					To:               metric.to,
+					GrowthPercent:    percent,
+					ThresholdPercent: thresholdPercent,
+					Since:            from.TakenAt,
+				})
+			}
+		}
+	}
+
+	slices.SortFunc(growing, func(a, b models.TableGrowth) int {
+		if a.Table != b.Table {
+			return strings.Compare(a.Table, b.Table)
+		}
+
+		return strings.Compare(a.Metric, b.Metric)
+	})
+
+	return growing
+}
@@ -0,0 +1,159 @@
+package database
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/r4ulcl/api_template/utils/models"
+	"gorm.io/gorm"
+)
+
+const (
+	// pingTimeout is the maximum time a health check ping can take.
+	pingTimeout = 2 * time.Second
+
+	// minRetryBackoff is the first delay between health checks once the database is down.
+	minRetryBackoff = time.Second
+)
+
+// HealthChecker periodically pings the database and acts as a circuit breaker.
+//
+// After `threshold` consecutive failed pings the circuit opens and Available
+// returns false, so requests can fail fast with 503 instead of hanging until
+// timeout. While open, pings are retried with exponential backoff (capped at
+// the regular interval) and the circuit closes on the first successful ping.
+type HealthChecker struct {
+	db        *gorm.DB
+	interval  time.Duration
+	threshold int
+
+	mu                  sync.RWMutex
+	available           bool
+	consecutiveFailures int
+	lastError           error
+	lastCheck           time.Time
+	unavailableSince    time.Time
+}
+
+// NewHealthChecker creates a health checker for a database connection.
+//
+// Parameters:
+// - db: The database connection to check.
+// - interval: The delay between health checks while the database is healthy.
+// - threshold: The number of consecutive failures that opens the circuit.
+func NewHealthChecker(db *gorm.DB, interval time.Duration, threshold int) *HealthChecker {
+	return &HealthChecker{
+		db:        db,
+		interval:  interval,
+		threshold: max(threshold, 1),
+		available: true,
+	}
+}
+
+// Start runs the health checks until the context is cancelled.
+func (hc *HealthChecker) Start(ctx context.Context) {
+	backoff := minRetryBackoff
+
+	for {
+		delay := hc.interval
+		if hc.Check(ctx) != nil {
+			delay = min(backoff, hc.interval)
+			backoff *= 2
+		} else {
+			backoff = minRetryBackoff
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+	}
+}
+
+// Check pings the database once and updates the circuit state.
+//
+// Returns the ping error, if any.
+func (hc *HealthChecker) Check(ctx context.Context) error {
+	err := hc.ping(ctx)
+
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+
+	hc.lastCheck = time.Now()
+	hc.lastError = err
+
+	if err == nil {
+		if !hc.available {
+			log.Println("Database connection restored, closing circuit breaker")
+		}
+
+		hc.consecutiveFailures = 0
+		hc.available = true
+		hc.unavailableSince = time.Time{}
+
+		return nil
+	}
+
+	hc.consecutiveFailures++
+	if hc.available && hc.consecutiveFailures >= hc.threshold {
+		log.Printf("Database health check failed %d times, opening circuit breaker: %v",
+			hc.consecutiveFailures, err)
+
+		hc.available = false
+		hc.unavailableSince = hc.lastCheck
+	}
+
+	return err
+}
+
+// Available reports whether the circuit is closed and requests may use the database.
+// A nil checker is always available.
+func (hc *HealthChecker) Available() bool {
+	if hc == nil {
+		return true
+	}
+
+	hc.mu.RLock()
+	defer hc.mu.RUnlock()
+
+	return hc.available
+}
+
+// Status returns the current state of the health checker.
+func (hc *HealthChecker) Status() models.DBHealth {
+	hc.mu.RLock()
+	defer hc.mu.RUnlock()
+
+	status := models.DBHealth{
+		Available:           hc.available,
+		ConsecutiveFailures: hc.consecutiveFailures,
+		LastCheck:           hc.lastCheck,
+	}
+
+	if hc.lastError != nil {
+		status.LastError = hc.lastError.Error()
+	}
+
+	if !hc.unavailableSince.IsZero() {
+		since := hc.unavailableSince
+		status.UnavailableSince = &since
+	}
+
+	return status
+}
+
+// ping checks the underlying connection pool with a timeout.
+func (hc *HealthChecker) ping(ctx context.Context) error {
+	sqlDB, err := hc.db.DB()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+
+	return sqlDB.PingContext(ctx)
+}
@@ -0,0 +1,131 @@
+package database
+
+import (
+	"errors"
+	"time"
+
+	"github.com/r4ulcl/api_template/utils/models"
+	"gorm.io/gorm"
+)
+
+// ErrImporterNotFound is returned when an importer does not exist.
+var ErrImporterNotFound = errors.New("importer not found")
+
+// ListImporters returns every importer ordered by ID.
+func (bc *BaseController) ListImporters() ([]models.Importer, error) {
+	importers := []models.Importer{}
+	err := bc.DB.Order("id").Find(&importers).Error
+
+	return importers, err
+}
+
+// GetImporter returns an importer by ID.
+//
+// Returns:
+// - ErrImporterNotFound if no importer has the given ID.
+func (bc *BaseController) GetImporter(id uint) (models.Importer, error) {
+	var importer models.Importer
+
+	err := bc.DB.First(&importer, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return importer, ErrImporterNotFound
+	}
+
+	return importer, err
+}
+
+// CreateImporter stores a new importer. Scheduled importers run as soon as the
+// scheduler checks them.
+func (bc *BaseController) CreateImporter(importer *models.Importer) error {
+	importer.ID = 0
+	importer.LastRunAt = nil
+	importer.LastError = ""
+
+	return bc.DB.Create(importer).Error
+}
+
+// UpdateImporter replaces an existing importer, keeping the state of its last run.
+//
+// Returns:
+// - ErrImporterNotFound if no importer has the given ID.
+func (bc *BaseController) UpdateImporter(id uint, importer *models.Importer) error {
+	existing, err := bc.GetImporter(id)
+	if err != nil {
+		return err
+	}
+
+	importer.ID = existing.ID
+	importer.CreatedAt = existing.CreatedAt
+	importer.LastRunAt = existing.LastRunAt
+	importer.LastError = existing.LastError
+
+	return bc.DB.Save(importer).Error
+}
+
+// DeleteImporter removes an importer. The records it imported are kept.
+//
+// Returns:
+// - ErrImporterNotFound if no importer has the given ID.
+func (bc *BaseController) DeleteImporter(id uint) error {
+	res := bc.DB.Delete(&models.Importer{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return ErrImporterNotFound
+	}
+
+	return nil
+}
+
+// DueImporters returns the enabled scheduled importers that never ran or whose interval elapsed.
+func (bc *BaseController) DueImporters(now time.Time) ([]models.Importer, error) {
+	var importers []models.Importer
+	if err := bc.DB.Where("enabled = ?", true).Order("id").Find(&importers).Error; err != nil {
+		return nil, err
+	}
+
+	due := importers[:0]
+
+	for _, importer := range importers {
+		if importer.Interval <= 0 {
+			// Only run on demand
+			continue
+		}
+
+		if importer.LastRunAt == nil || !importer.LastRunAt.Add(time.Duration(importer.Interval)*time.Second).After(now) {
+			due = append(due, importer)
+		}
+	}
+
+	return due, nil
+}
+
+// ClaimImporter records the start of a run of an importer, so other instances
+// checking the same due importer don't run it too.
+//
+// Returns:
+// - false if the importer ran since it was read.
+func (bc *BaseController) ClaimImporter(importer models.Importer, now time.Time) (bool, error) {
+	tx := bc.DB.Model(&models.Importer{}).Where("id = ?", importer.ID)
+	if importer.LastRunAt == nil {
+		tx = tx.Where("last_run_at IS NULL")
+	} else {
+		tx = tx.Where("last_run_at = ?", *importer.LastRunAt)
+	}
+
+	res := tx.Update("last_run_at", now)
+
+	return res.RowsAffected == 1, res.Error
+}
+
+// SetImporterError stores the error of the last run of an importer, or clears it if err is nil.
+func (bc *BaseController) SetImporterError(id uint, err error) error {
+	message := ""
+	if err != nil {
+		message = err.Error()
+	}
+
+	return bc.DB.Model(&models.Importer{}).Where("id = ?", id).Update("last_error", message).Error
+}
@@ -0,0 +1,111 @@
+package database
+
+import (
+	"errors"
+	"time"
+
+	"github.com/r4ulcl/api_template/utils/models"
+	"gorm.io/gorm"
+)
+
+// ErrInboundHookNotFound is returned when an inbound hook does not exist.
+var ErrInboundHookNotFound = errors.New("inbound hook not found")
+
+// ListInboundHooks returns every inbound hook ordered by ID.
+func (bc *BaseController) ListInboundHooks() ([]models.InboundHook, error) {
+	hooks := []models.InboundHook{}
+	err := bc.DB.Order("id").Find(&hooks).Error
+
+	return hooks, err
+}
+
+// GetInboundHook returns an inbound hook by ID.
+//
+// Returns:
+// - ErrInboundHookNotFound if no hook has the given ID.
+func (bc *BaseController) GetInboundHook(id uint) (models.InboundHook, error) {
+	var hook models.InboundHook
+
+	err := bc.DB.First(&hook, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return hook, ErrInboundHookNotFound
+	}
+
+	return hook, err
+}
+
+// GetEnabledInboundHook returns an enabled inbound hook by name.
+//
+// Returns:
+// - ErrInboundHookNotFound if no enabled hook has the given name.
+func (bc *BaseController) GetEnabledInboundHook(name string) (models.InboundHook, error) {
+	var hook models.InboundHook
+
+	err := bc.DB.Where("name = ? AND enabled = ?", name, true).First(&hook).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return hook, ErrInboundHookNotFound
+	}
+
+	return hook, err
+}
+
+// CreateInboundHook stores a new inbound hook.
+func (bc *BaseController) CreateInboundHook(hook *models.InboundHook) error {
+	hook.ID = 0
+	hook.LastReceivedAt = nil
+	hook.LastError = ""
+
+	return bc.DB.Create(hook).Error
+}
+
+// UpdateInboundHook replaces an existing inbound hook, keeping the state of
+// its last payload, and its secret if the new one is empty.
+//
+// Returns:
+// - ErrInboundHookNotFound if no hook has the given ID.
+func (bc *BaseController) UpdateInboundHook(id uint, hook *models.InboundHook) error {
+	existing, err := bc.GetInboundHook(id)
+	if err != nil {
+		return err
+	}
+
+	hook.ID = existing.ID
+	hook.CreatedAt = existing.CreatedAt
+	hook.LastReceivedAt = existing.LastReceivedAt
+	hook.LastError = existing.LastError
+
+	if hook.Secret == "" {
+		hook.Secret = existing.Secret
+	}
+
+	return bc.DB.Save(hook).Error
+}
+
+// DeleteInboundHook removes an inbound hook. The records it stored are kept.
+//
+// Returns:
+// - ErrInboundHookNotFound if no hook has the given ID.
+func (bc *BaseController) DeleteInboundHook(id uint) error {
+	res := bc.DB.Delete(&models.InboundHook{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return ErrInboundHookNotFound
+	}
+
+	return nil
+}
+
+// SetInboundHookResult stores the time of the last payload of an inbound hook
+// and its error, or clears it if err is nil.
+func (bc *BaseController) SetInboundHookResult(id uint, receivedAt time.Time, err error) error {
+	message := ""
+	if err != nil {
+		message = err.Error()
+	}
+
+	return bc.DB.Model(&models.InboundHook{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"last_received_at": receivedAt, "last_error": message}).Error
+}
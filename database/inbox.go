@@ -0,0 +1,119 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/r4ulcl/api_template/utils/models"
+	"gorm.io/gorm"
+)
+
+// maxInboxNotifications is the number of notifications returned by ListInboxNotifications.
+const maxInboxNotifications = 100
+
+// ErrInboxNotificationNotFound is returned when a notification does not exist
+// or belongs to another user.
+var ErrInboxNotificationNotFound = errors.New("notification not found")
+
+// CreateInboxNotifications stores notifications in the inbox of their recipients.
+func (bc *BaseController) CreateInboxNotifications(notifications ...models.InboxNotification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+
+	return bc.DB.Create(&notifications).Error
+}
+
+// NotifyAdmins stores a copy of a notification in the inbox of every admin.
+func (bc *BaseController) NotifyAdmins(notification models.InboxNotification) error {
+	var admins []string
+	if err := bc.DB.Model(&models.User{}).Where("role = ?", models.AdminRole).
+		Pluck("username", &admins).Error; err != nil {
+		return err
+	}
+
+	notifications := make([]models.InboxNotification, 0, len(admins))
+
+	for _, admin := range admins {
+		notification.Username = admin
+		notifications = append(notifications, notification)
+	}
+
+	return bc.CreateInboxNotifications(notifications...)
+}
+
+// ListInboxNotifications returns the most recent notifications of a user.
+//
+// Parameters:
+// - username: The recipient.
+// - unreadOnly: Only return the notifications not marked as read.
+func (bc *BaseController) ListInboxNotifications(username string, unreadOnly bool) ([]models.InboxNotification, error) {
+	notifications := []models.InboxNotification{}
+
+	tx := bc.DB.Where("username = ?", username)
+	if unreadOnly {
+		tx = tx.Where("is_read = ?", false)
+	}
+
+	err := tx.Order("id DESC").Limit(maxInboxNotifications).Find(&notifications).Error
+
+	return notifications, err
+}
+
+// CountUnreadInboxNotifications returns the number of unread notifications of a user.
+func (bc *BaseController) CountUnreadInboxNotifications(username string) (int64, error) {
+	var count int64
+
+	err := bc.DB.Model(&models.InboxNotification{}).Where("username = ? AND is_read = ?", username, false).
+		Count(&count).Error
+
+	return count, err
+}
+
+// MarkInboxNotificationRead marks a notification of a user as read.
+//
+// Returns:
+// - ErrInboxNotificationNotFound if the user has no notification with that ID.
+func (bc *BaseController) MarkInboxNotificationRead(username string, id uint) error {
+	var notification models.InboxNotification
+	if err := bc.DB.Where("id = ? AND username = ?", id, username).First(&notification).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInboxNotificationNotFound
+		}
+
+		return err
+	}
+
+	if notification.Read {
+		return nil
+	}
+
+	return bc.DB.Model(&notification).Updates(map[string]interface{}{"is_read": true, "read_at": time.Now()}).Error
+}
+
+// MarkAllInboxNotificationsRead marks every unread notification of a user as read.
+//
+// Returns:
+// - The number of notifications marked as read.
+func (bc *BaseController) MarkAllInboxNotificationsRead(username string) (int64, error) {
+	res := bc.DB.Model(&models.InboxNotification{}).Where("username = ? AND is_read = ?", username, false).
+		Updates(map[string]interface{}{"is_read": true, "read_at": time.Now()})
+
+	return res.RowsAffected, res.Error
+}
+
+// ReportNotificationFailure tells the admins, through their inbox, that a
+// notification rule could not be delivered. It implements notify.FailureReporter.
+func (bc *BaseController) ReportNotificationFailure(rule models.NotificationRule, err error) {
+	notification := models.InboxNotification{
+		Kind:  models.InboxWebhookFailed,
+		Title: fmt.Sprintf("Notification rule %q failed", rule.Name),
+		Text:  fmt.Sprintf("The %s notification of rule %d could not be delivered: %v", rule.Channel, rule.ID, err),
+	}
+
+	if err := bc.NotifyAdmins(notification); err != nil {
+		log.Println("Error notifying the admins of a failed notification:", err)
+	}
+}
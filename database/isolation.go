@@ -0,0 +1,106 @@
+package database
+
+import (
+	"database/sql"
+	"maps"
+
+	"gorm.io/gorm"
+)
+
+// TxOperation names the operations of the BaseController whose transactions
+// are configured by a TxPolicy.
+type TxOperation string
+
+const (
+	// TxList is the read of a page of records and their count (see ReadTransaction).
+	TxList TxOperation = "list"
+
+	// TxWrite is the creation, update or deletion of records.
+	TxWrite TxOperation = "write"
+
+	// TxMerge is the merge of duplicate records (see MergeRecords).
+	TxMerge TxOperation = "merge"
+)
+
+// TxPolicy sets the transaction options of the operations on a resource,
+// instead of the default isolation level of the database server. The
+// operations without options run as they always did: the lists and the
+// writes without counters or sequences outside transactions.
+type TxPolicy map[TxOperation]sql.TxOptions
+
+// DefaultTxPolicy is the policy of every resource, overridden per operation by
+// the policy of the resource: merges re-point references across tables, so
+// they run SERIALIZABLE.
+var DefaultTxPolicy = TxPolicy{
+	TxMerge: {Isolation: sql.LevelSerializable},
+}
+
+// WithTransactions returns a copy of the BaseController running its
+// transactions with the options of a resource and, when stricter, the
+// isolation level asked by the request.
+//
+// Parameters:
+// - policy: The policy of the resource, on top of DefaultTxPolicy. Nil uses the default.
+// - hint: The isolation level asked by the request, only used when stricter. Zero asks nothing.
+func (bc *BaseController) WithTransactions(policy TxPolicy, hint sql.IsolationLevel) *BaseController {
+	merged := maps.Clone(DefaultTxPolicy)
+	maps.Copy(merged, policy)
+
+	tc := *bc
+	tc.txPolicy = merged
+	tc.txHint = hint
+
+	return &tc
+}
+
+// IsolationHint returns the isolation level asked by the request (see WithTransactions).
+func (bc *BaseController) IsolationHint() sql.IsolationLevel {
+	return bc.txHint
+}
+
+// txOptions returns the transaction options of an operation, or nil to use the
+// defaults of the database server.
+func (bc *BaseController) txOptions(operation TxOperation) *sql.TxOptions {
+	policy := bc.txPolicy
+	if policy == nil {
+		policy = DefaultTxPolicy
+	}
+
+	opts, ok := policy[operation]
+
+	// Reads asked for an isolation level run in a read-only transaction
+	if bc.txHint > opts.Isolation {
+		opts.Isolation = bc.txHint
+		opts.ReadOnly = opts.ReadOnly || operation == TxList
+		ok = true
+	}
+
+	if !ok {
+		return nil
+	}
+
+	return &opts
+}
+
+// ReadTransaction runs the reads of a list, its page and its count, in one
+// transaction when the TxList options ask for it (e.g. REPEATABLE READ, or
+// READ ONLY), so the count matches the page. Without options, the reads run
+// directly on the connection.
+//
+// Parameters:
+// - read: The reads, run with a BaseController on the transaction.
+func (bc *BaseController) ReadTransaction(read func(bc *BaseController) error) error {
+	opts := bc.txOptions(TxList)
+	if opts == nil {
+		return read(bc)
+	}
+
+	return bc.retry(func() error {
+		return bc.DB.Transaction(func(tx *gorm.DB) error {
+			tc := *bc
+			tc.DB = tx
+
+			return read(&tc)
+		}, opts)
+	})
+}
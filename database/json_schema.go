@@ -0,0 +1,167 @@
+package database
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/r4ulcl/api_template/utils"
+	"github.com/r4ulcl/api_template/utils/models"
+	"gorm.io/gorm/schema"
+)
+
+// enumTypePattern matches a gorm "type:enum('a','b')" column type.
+var enumTypePattern = regexp.MustCompile(`(?i)^enum\((.*)\)$`)
+
+// JSONSchema generates the JSON Schema of a model from its struct and gorm tags.
+//
+// Fields hidden from JSON and relations are skipped. Primary keys that are not
+// auto-incremented, and not null fields without a default value, are required.
+// String sizes become maxLength, and enum fields (see utils.EnumValues) and
+// "type:enum(...)" columns become enums. Fields populated by the server (see
+// utils.PopulateFields) and read-only columns (gorm "->", e.g. generated columns)
+// are read-only, and the defaults are documented.
+//
+// Parameters:
+// - title: The title of the schema, usually the resource name.
+// - model: A pointer to the struct of the model.
+//
+// Returns:
+// - The JSON Schema of the model.
+// - An error if the model cannot be parsed.
+func (bc *BaseController) JSONSchema(title string, model interface{}) (models.JSONSchema, error) {
+	result := models.JSONSchema{
+		Schema:     models.JSONSchemaDraft,
+		Title:      title,
+		Type:       "object",
+		Properties: map[string]*models.JSONSchemaProperty{},
+		Required:   []string{},
+	}
+
+	sch, err := bc.modelSchema(model)
+	if err != nil {
+		return result, err
+	}
+
+	for _, field := range sch.Fields {
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if field.DBName == "" || name == "-" {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		property := jsonSchemaType(field.FieldType)
+
+		if field.Size > 0 && field.DataType == schema.String {
+			size := field.Size
+			property.MaxLength = &size
+		}
+
+		if values := utils.EnumValues(field.StructField); values != nil {
+			property.Enum = values
+		} else if match := enumTypePattern.FindStringSubmatch(field.TagSettings["TYPE"]); match != nil {
+			for _, value := range strings.Split(match[1], ",") {
+				property.Enum = append(property.Enum, strings.Trim(strings.TrimSpace(value), `'"`))
+			}
+		}
+
+		if value, ok := utils.DefaultValue(field.StructField); ok {
+			property.Default = value
+			if field.DataType != schema.String {
+				// Numbers and booleans are written as JSON values
+				var typed interface{}
+				if json.Unmarshal([]byte(value), &typed) == nil {
+					property.Default = typed
+				}
+			}
+		}
+
+		property.ReadOnly = field.AutoCreateTime > 0 || field.AutoUpdateTime > 0 ||
+			(field.PrimaryKey && field.AutoIncrement) || utils.ServerPopulated(field.StructField) ||
+			(!field.Creatable && !field.Updatable)
+
+		// Named embedded structs (e.g. models.GeoPoint) are nested objects in JSON
+		if parent, ok := embeddedParent(sch, field); ok {
+			object, exists := result.Properties[parent]
+			if !exists {
+				object = &models.JSONSchemaProperty{Type: "object", Properties: map[string]*models.JSONSchemaProperty{}}
+				result.Properties[parent] = object
+			}
+
+			object.Properties[name] = property
+
+			continue
+		}
+
+		if !property.ReadOnly && property.Default == nil &&
+			((field.PrimaryKey && !field.AutoIncrement) || (field.NotNull && !field.HasDefaultValue)) {
+			result.Required = append(result.Required, name)
+		}
+
+		result.Properties[name] = property
+	}
+
+	return result, nil
+}
+
+// jsonSchemaType maps a Go type to its JSON Schema type. Pointers are nullable.
+func jsonSchemaType(typ reflect.Type) *models.JSONSchemaProperty {
+	nullable := typ.Kind() == reflect.Ptr
+	if nullable {
+		typ = typ.Elem()
+	}
+
+	property := &models.JSONSchemaProperty{}
+
+	var name string
+
+	switch {
+	case typ == reflect.TypeOf(time.Time{}):
+		name = "string"
+		property.Format = "date-time"
+	case typ.Kind() == reflect.String:
+		name = "string"
+	case typ.Kind() == reflect.Bool:
+		name = "boolean"
+	case typ.Kind() >= reflect.Int && typ.Kind() <= reflect.Uint64:
+		name = "integer"
+	case typ.Kind() == reflect.Float32 || typ.Kind() == reflect.Float64:
+		name = "number"
+	default:
+		// Raw JSON and other types accept any value
+		return property
+	}
+
+	if nullable {
+		property.Type = []string{name, "null"}
+	} else {
+		property.Type = name
+	}
+
+	return property
+}
+
+// embeddedParent returns the JSON name of the named struct field a field is embedded
+// from (gorm "embedded"), or false for top-level and anonymous embedded fields.
+func embeddedParent(sch *schema.Schema, field *schema.Field) (string, bool) {
+	if len(field.BindNames) < 2 {
+		return "", false
+	}
+
+	parent, ok := sch.ModelType.FieldByName(field.BindNames[0])
+	if !ok || parent.Anonymous {
+		return "", false
+	}
+
+	name := strings.Split(parent.Tag.Get("json"), ",")[0]
+	if name == "" {
+		name = parent.Name
+	}
+
+	return name, true
+}
@@ -0,0 +1,241 @@
+package database
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/r4ulcl/api_template/utils/models"
+	"gorm.io/gorm"
+)
+
+// SchedulerElection is the election of the instance running the scheduled jobs.
+const SchedulerElection = "scheduler"
+
+// defaultLeaderLeaseTTL is used when NewLeaderElector gets no TTL.
+const defaultLeaderLeaseTTL = 30 * time.Second
+
+// GetLeaderLease returns the lease of an election, with an empty holder if no
+// instance ever led it.
+func (bc *BaseController) GetLeaderLease(name string) (models.LeaderLease, error) {
+	lease := models.LeaderLease{Name: name}
+
+	err := bc.DB.Where("name = ?", name).Limit(1).Find(&lease).Error
+
+	return lease, err
+}
+
+// AcquireLeaderLease takes or renews the lease of an election for an instance.
+//
+// Expired leases of other instances are taken over.
+//
+// Parameters:
+// - name: The name of the election.
+// - instance: The ID of the instance.
+// - ttl: How long the lease lasts without being renewed.
+// - now: The current time.
+//
+// Returns:
+// - true if the instance holds the lease, false if another one does.
+func (bc *BaseController) AcquireLeaderLease(name, instance string, ttl time.Duration, now time.Time) (bool, error) {
+	// The holder is compared before it is replaced, so AcquiredAt only changes with the holder
+	res := bc.DB.Model(&models.LeaderLease{}).
+		Where("name = ? AND (holder = ? OR expires_at <= ?)", name, instance, now).
+		Updates(map[string]interface{}{
+			"acquired_at": gorm.Expr("CASE WHEN holder = ? THEN acquired_at ELSE ? END", instance, now),
+			"expires_at":  now.Add(ttl),
+			"holder":      instance,
+			"renewed_at":  now,
+		})
+	if res.Error != nil {
+		return false, res.Error
+	}
+
+	if res.RowsAffected > 0 {
+		return true, nil
+	}
+
+	lease := models.LeaderLease{Name: name, Holder: instance, AcquiredAt: now, RenewedAt: now, ExpiresAt: now.Add(ttl)}
+
+	err := bc.DB.Create(&lease).Error
+	if err != nil && IsDuplicateKeyError(err) {
+		// Another instance holds the lease
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+// ReleaseLeaderLease gives up the lease of an election held by an instance, so
+// another instance takes it over without waiting for it to expire.
+func (bc *BaseController) ReleaseLeaderLease(name, instance string) error {
+	return bc.DB.Where("name = ? AND holder = ?", name, instance).Delete(&models.LeaderLease{}).Error
+}
+
+// LeaderElector elects one instance among the replicas sharing the database,
+// with a lease in the leader_leases table renewed every third of its TTL.
+// Jobs started with Run only run while the instance leads.
+type LeaderElector struct {
+	bc       *BaseController
+	name     string
+	instance string
+	ttl      time.Duration
+
+	mu      sync.Mutex
+	leader  bool
+	changed chan struct{} // Closed and replaced on every change of leadership
+	jobs    []string
+}
+
+// NewLeaderElector creates an elector of an instance for an election.
+//
+// Parameters:
+// - bc: The database access.
+// - name: The name of the election (e.g. SchedulerElection).
+// - instance: The unique ID of the instance (INSTANCE_ID).
+// - ttl: How long the lease lasts without being renewed, so how long the jobs stop when the leader dies.
+//
+// A zero TTL uses defaultLeaderLeaseTTL.
+func NewLeaderElector(bc *BaseController, name, instance string, ttl time.Duration) *LeaderElector {
+	if ttl <= 0 {
+		ttl = defaultLeaderLeaseTTL
+	}
+
+	return &LeaderElector{bc: bc, name: name, instance: instance, ttl: ttl, changed: make(chan struct{})}
+}
+
+// Start takes and renews the lease until the context is cancelled, then
+// releases it.
+func (le *LeaderElector) Start(ctx context.Context) {
+	ticker := time.NewTicker(le.ttl / 3)
+	defer ticker.Stop()
+
+	for {
+		leader, err := le.bc.AcquireLeaderLease(le.name, le.instance, le.ttl, time.Now())
+		if err != nil {
+			log.Printf("Error renewing the %s lease: %v", le.name, err)
+
+			// The lease may expire before the database answers again
+			leader = false
+		}
+
+		le.set(leader)
+
+		select {
+		case <-ctx.Done():
+			le.set(false)
+
+			if err := le.bc.ReleaseLeaderLease(le.name, le.instance); err != nil {
+				log.Printf("Error releasing the %s lease: %v", le.name, err)
+			}
+
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// set records the leadership of the instance, waking up the jobs when it changes.
+func (le *LeaderElector) set(leader bool) {
+	le.mu.Lock()
+	defer le.mu.Unlock()
+
+	if le.leader == leader {
+		return
+	}
+
+	le.leader = leader
+	close(le.changed)
+	le.changed = make(chan struct{})
+
+	if leader {
+		log.Printf("Instance %s is now the %s leader", le.instance, le.name)
+	} else {
+		log.Printf("Instance %s is no longer the %s leader", le.instance, le.name)
+	}
+}
+
+// state returns the leadership of the instance and a channel closed when it changes.
+func (le *LeaderElector) state() (bool, <-chan struct{}) {
+	le.mu.Lock()
+	defer le.mu.Unlock()
+
+	return le.leader, le.changed
+}
+
+// IsLeader reports whether the instance currently leads.
+func (le *LeaderElector) IsLeader() bool {
+	leader, _ := le.state()
+
+	return leader
+}
+
+// Instance returns the ID of the instance.
+func (le *LeaderElector) Instance() string {
+	return le.instance
+}
+
+// Jobs returns the names of the jobs started with Run.
+func (le *LeaderElector) Jobs() []string {
+	le.mu.Lock()
+	defer le.mu.Unlock()
+
+	return append([]string{}, le.jobs...)
+}
+
+// Status returns the lease of the election as seen by the instance.
+func (le *LeaderElector) Status() (models.LeaderStatus, error) {
+	lease, err := le.bc.GetLeaderLease(le.name)
+
+	return models.LeaderStatus{Lease: lease, Instance: le.instance, IsLeader: le.IsLeader(), Jobs: le.Jobs()}, err
+}
+
+// Run runs a job in the background while the instance leads: it is started
+// with a context cancelled when the instance loses the lease, and started
+// again when it gets it back.
+//
+// Parameters:
+// - ctx: Stops the job for good when cancelled.
+// - name: The name of the job, listed in the status of the election.
+// - job: The job, which must return once its context is cancelled.
+func (le *LeaderElector) Run(ctx context.Context, name string, job func(ctx context.Context)) {
+	le.mu.Lock()
+	le.jobs = append(le.jobs, name)
+	le.mu.Unlock()
+
+	go func() {
+		for {
+			leader, changed := le.state()
+
+			if !leader {
+				select {
+				case <-ctx.Done():
+					return
+				case <-changed:
+					continue
+				}
+			}
+
+			jobCtx, cancel := context.WithCancel(ctx)
+			done := make(chan struct{})
+
+			go func() {
+				defer close(done)
+				job(jobCtx)
+			}()
+
+			select {
+			case <-ctx.Done():
+			case <-changed:
+			}
+
+			cancel()
+			<-done
+
+			if ctx.Err() != nil {
+				return
+			}
+		}
+	}()
+}
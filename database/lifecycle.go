@@ -0,0 +1,140 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/r4ulcl/api_template/utils/models"
+	"gorm.io/gorm/schema"
+)
+
+var (
+	// ErrUnknownAction is returned when a lifecycle action does not exist.
+	ErrUnknownAction = errors.New("unknown lifecycle action")
+
+	// ErrInvalidTransition is returned when a lifecycle action does not apply to the current status.
+	ErrInvalidTransition = errors.New("invalid lifecycle transition")
+)
+
+// lifecycleType is the reflect type of models.LifecycleStatus.
+var lifecycleType = reflect.TypeOf(models.LifecycleStatus(""))
+
+// HasLifecycle reports whether a model (a pointer to a struct) has a models.LifecycleStatus field.
+func HasLifecycle(model interface{}) bool {
+	_, ok := lifecycleValue(model)
+
+	return ok
+}
+
+// IsPublished reports whether a record is visible to every user: it has no
+// lifecycle, or its status is published.
+func IsPublished(model interface{}) bool {
+	status, ok := lifecycleValue(model)
+
+	return !ok || models.LifecycleStatus(status.String()) == models.StatusPublished
+}
+
+// lifecycleValue returns the models.LifecycleStatus field of a record.
+func lifecycleValue(model interface{}) (reflect.Value, bool) {
+	val := reflect.Indirect(reflect.ValueOf(model))
+	if val.Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+
+	for i := range val.NumField() {
+		if val.Field(i).Type() == lifecycleType {
+			return val.Field(i), true
+		}
+	}
+
+	return reflect.Value{}, false
+}
+
+// lifecycleField returns the schema field of type models.LifecycleStatus, if any.
+func lifecycleField(sch *schema.Schema) *schema.Field {
+	for _, field := range sch.Fields {
+		if field.FieldType == lifecycleType && field.DBName != "" {
+			return field
+		}
+	}
+
+	return nil
+}
+
+// PublishedFilter returns the filter keeping only the published records of a
+// model, or false if the model has no lifecycle.
+//
+// Parameters:
+// - model: A pointer to the struct, or to a slice of structs, of the model.
+func (bc *BaseController) PublishedFilter(model interface{}) (Filter, bool) {
+	sch, err := bc.modelSchema(model)
+	if err != nil {
+		return Filter{}, false
+	}
+
+	field := lifecycleField(sch)
+	if field == nil {
+		return Filter{}, false
+	}
+
+	return Filter{Field: field.DBName, Op: FilterEq, Value: string(models.StatusPublished)}, true
+}
+
+// TransitionRecord applies a lifecycle action (see models.LifecycleActions) to a record.
+//
+// The status is only changed if it is still one the action applies to, so
+// concurrent transitions of the same record can't both succeed.
+//
+// Parameters:
+// - model: A pointer to the struct where the updated record is stored.
+// - id: The tokenized ID of the record.
+// - action: The action, e.g. "publish".
+//
+// Returns:
+// - ErrUnknownAction if the action does not exist or the model has no lifecycle.
+// - ErrRecordNotFound if the record does not exist.
+// - ErrInvalidTransition if the action does not apply to the current status.
+func (bc *BaseController) TransitionRecord(model interface{}, id, action string) error {
+	transition, ok := models.LifecycleActions[action]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownAction, action)
+	}
+
+	sch, err := bc.modelSchema(model)
+	if err != nil {
+		return err
+	}
+
+	field := lifecycleField(sch)
+	if field == nil {
+		return fmt.Errorf("%w: %s has no lifecycle", ErrUnknownAction, sch.Table)
+	}
+
+	if err := bc.GetRecordsByID(model, id); err != nil {
+		return err
+	}
+
+	status, _ := lifecycleValue(model)
+	current := models.LifecycleStatus(status.String())
+
+	if !slices.Contains(transition.From, current) {
+		return fmt.Errorf("%w: can't %s a %s record", ErrInvalidTransition, action, current)
+	}
+
+	column := bc.DB.Statement.Quote(field.DBName)
+
+	res := bc.DB.Model(model).Where(column+" = ?", current).Update(field.DBName, transition.To)
+	if res.Error != nil {
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("%w: the status of the record changed", ErrInvalidTransition)
+	}
+
+	status.SetString(string(transition.To))
+
+	return nil
+}
@@ -0,0 +1,315 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/r4ulcl/api_template/utils/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// ErrInvalidMerge is returned when a merge request can't be applied.
+var ErrInvalidMerge = errors.New("invalid merge")
+
+// maxDuplicateGroups caps the number of groups returned by FindDuplicates.
+const maxDuplicateGroups = 100
+
+// reference is a foreign key column of another model holding the primary key of a model.
+type reference struct {
+	schema *schema.Schema
+	column string
+}
+
+// FindDuplicates groups the records that have identical values on the given fields.
+//
+// Groups are sorted by size, largest first, and capped at maxDuplicateGroups.
+//
+// Parameters:
+// - model: A pointer to the struct of the model.
+// - fields: The compared fields (JSON name, struct name or column name).
+// - conditions: Filters restricting the compared records (e.g. PublishedFilter).
+//
+// Returns:
+// - The groups of two or more records.
+// - ErrInvalidField if no field is given or a field does not exist on the model.
+func (bc *BaseController) FindDuplicates(model interface{}, fields []string,
+	conditions []Filter,
+) ([]models.DuplicateGroup, error) {
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("%w: no fields to compare", ErrInvalidField)
+	}
+
+	sch, err := bc.modelSchema(model)
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make([]string, len(fields))
+	quoted := make([]string, len(fields))
+
+	for i, name := range fields {
+		if columns[i], err = lookupColumn(sch, name); err != nil {
+			return nil, err
+		}
+
+		quoted[i] = bc.DB.Statement.Quote(columns[i])
+	}
+
+	scope, err := applyQueryOptions(bc.DB.Model(model), sch, QueryOptions{Conditions: conditions})
+	if err != nil {
+		return nil, err
+	}
+
+	// Reused by the query of every group
+	scope = scope.Session(&gorm.Session{})
+
+	rows := []map[string]interface{}{}
+
+	err = scope.
+		Select(strings.Join(quoted, ", ") + ", COUNT(*) AS duplicate_count").
+		Group(strings.Join(quoted, ", ")).
+		Having("COUNT(*) > 1").
+		Order("duplicate_count DESC").
+		Limit(maxDuplicateGroups).
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	primaryKeys := make([]string, len(sch.PrimaryFields))
+	for i, field := range sch.PrimaryFields {
+		primaryKeys[i] = field.DBName
+	}
+
+	groups := make([]models.DuplicateGroup, 0, len(rows))
+
+	for _, row := range rows {
+		group := models.DuplicateGroup{Values: map[string]interface{}{}}
+		values := map[string]interface{}{}
+
+		for i, column := range columns {
+			value := row[column]
+			if raw, ok := value.([]byte); ok {
+				value = string(raw)
+			}
+
+			group.Values[fields[i]] = value
+			values[column] = value
+		}
+
+		group.Count, _ = row["duplicate_count"].(int64)
+
+		// A map condition matches NULL values with IS NULL
+		records := reflect.New(reflect.SliceOf(sch.ModelType))
+		if err := scope.Select(primaryKeys).Where(values).Find(records.Interface()).Error; err != nil {
+			return nil, err
+		}
+
+		for i := range records.Elem().Len() {
+			group.IDs = append(group.IDs, RecordID(records.Elem().Index(i).Addr().Interface()))
+		}
+
+		groups = append(groups, group)
+	}
+
+	return groups, nil
+}
+
+// MergeRecords merges records into a survivor, in a single transaction.
+//
+// The empty fields of the survivor are filled with the first non-empty value of
+// the merged records, in the given order. The rows of other models referencing
+// the merged records through a belongs-to relation (gorm "foreignKey") are
+// re-pointed to the survivor; rows that would then clash with an existing row
+// of the survivor (same primary key) are deleted instead. Finally the merged
+// records are deleted.
+//
+// Parameters:
+// - model: A pointer to the struct of the model, where the survivor is stored.
+// - survivorID: The tokenized ID of the record that is kept.
+// - ids: The tokenized IDs of the records merged into the survivor.
+//
+// Returns:
+// - The number of referencing rows re-pointed to the survivor.
+// - ErrInvalidMerge if the model has a composite key or the IDs are not valid.
+// - ErrRecordNotFound if the survivor or a merged record does not exist.
+func (bc *BaseController) MergeRecords(model interface{}, survivorID string, ids []string) (int64, error) {
+	sch, err := bc.modelSchema(model)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(sch.PrimaryFields) != 1 {
+		return 0, fmt.Errorf("%w: only records with a single primary key can be merged", ErrInvalidMerge)
+	}
+
+	if survivorID == "" || len(ids) == 0 || slices.Contains(ids, survivorID) || slices.Contains(ids, "") {
+		return 0, fmt.Errorf("%w: give a survivor and the IDs merged into it", ErrInvalidMerge)
+	}
+
+	references, err := bc.referencesTo(sch)
+	if err != nil {
+		return 0, err
+	}
+
+	ids = slices.Compact(slices.Sorted(slices.Values(ids)))
+	primaryKey := bc.DB.Statement.Quote(sch.PrimaryFields[0].DBName)
+
+	var repointed int64
+
+	// The merge runs with the TxMerge options (SERIALIZABLE by default, see TxPolicy), retried on deadlocks
+	err = bc.retry(func() error {
+		repointed = 0
+
+		return bc.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Where(primaryKey+" = ?", survivorID).First(model).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return ErrRecordNotFound
+				}
+
+				return err
+			}
+
+			merged := reflect.New(reflect.SliceOf(sch.ModelType))
+			if err := tx.Where(primaryKey+" IN ?", ids).Find(merged.Interface()).Error; err != nil {
+				return err
+			}
+
+			if merged.Elem().Len() != len(ids) {
+				return fmt.Errorf("%w: %d of %d merged records exist", ErrRecordNotFound, merged.Elem().Len(), len(ids))
+			}
+
+			fillEmptyFields(sch.Fields, reflect.ValueOf(model).Elem(), merged.Elem())
+
+			// Re-pointing the references changes the counters of their parents (see Counter)
+			parents, err := bc.referencedParents(tx, sch, append(slices.Clone(ids), survivorID))
+			if err != nil {
+				return err
+			}
+
+			for _, reference := range references {
+				count, err := repointReference(tx, reference, survivorID, ids)
+				if err != nil {
+					return err
+				}
+
+				repointed += count
+			}
+
+			if err := tx.Save(model).Error; err != nil {
+				return err
+			}
+
+			if err := tx.Where(primaryKey+" IN ?", ids).Delete(reflect.New(sch.ModelType).Interface()).Error; err != nil {
+				return err
+			}
+
+			if err := bc.recountParents(tx, parents); err != nil {
+				return err
+			}
+
+			if len(parents) > 0 {
+				// Reload the survivor with its recounted counters
+				return tx.Where(primaryKey+" = ?", survivorID).First(model).Error
+			}
+
+			return nil
+		}, bc.txOptions(TxMerge))
+	})
+
+	return repointed, err
+}
+
+// fillEmptyFields sets the zero-valued fields of the survivor to the first
+// non-zero value of the merged records.
+func fillEmptyFields(fields []*schema.Field, survivor, merged reflect.Value) {
+	ctx := context.Background()
+
+	for _, field := range fields {
+		if field.DBName == "" || field.PrimaryKey || !field.Updatable {
+			continue
+		}
+
+		if _, zero := field.ValueOf(ctx, survivor); !zero {
+			continue
+		}
+
+		for i := range merged.Len() {
+			if value, zero := field.ValueOf(ctx, merged.Index(i)); !zero {
+				_ = field.Set(ctx, survivor, value)
+
+				break
+			}
+		}
+	}
+}
+
+// referencesTo returns the foreign key columns of the migrated models that
+// reference the primary key of a model through a belongs-to relation.
+func (bc *BaseController) referencesTo(target *schema.Schema) ([]reference, error) {
+	var references []reference
+
+	for _, model := range migratedModels {
+		sch, err := bc.modelSchema(model)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, relation := range sch.Relationships.BelongsTo {
+			if relation.FieldSchema.Table != target.Table {
+				continue
+			}
+
+			for _, ref := range relation.References {
+				if ref.PrimaryKey != nil && ref.PrimaryKey.PrimaryKey && ref.ForeignKey != nil {
+					references = append(references, reference{schema: sch, column: ref.ForeignKey.DBName})
+				}
+			}
+		}
+	}
+
+	return references, nil
+}
+
+// repointReference updates the rows of a referencing model from the merged IDs to the survivor.
+//
+// When the referencing column is part of a composite primary key, the rows that
+// would duplicate a row already pointing to the survivor are deleted first.
+func repointReference(tx *gorm.DB, ref reference, survivorID string, ids []string) (int64, error) {
+	sch, column := ref.schema, ref.column
+
+	table := tx.Statement.Quote(sch.Table)
+	quotedColumn := tx.Statement.Quote(column)
+
+	var others []string
+
+	isKey := false
+
+	for _, field := range sch.PrimaryFields {
+		if field.DBName == column {
+			isKey = true
+		} else {
+			others = append(others, tx.Statement.Quote(field.DBName))
+		}
+	}
+
+	if isKey && len(others) > 0 {
+		keys := strings.Join(others, ", ")
+
+		// The derived table lets MySQL read the table being deleted from
+		clash := fmt.Sprintf("DELETE FROM %s WHERE %s IN ? AND (%s) IN (SELECT * FROM (SELECT %s FROM %s WHERE %s = ?) AS survivor_rows)",
+			table, quotedColumn, keys, keys, table, quotedColumn)
+		if err := tx.Exec(clash, ids, survivorID).Error; err != nil {
+			return 0, err
+		}
+	}
+
+	res := tx.Exec(fmt.Sprintf("UPDATE %s SET %s = ? WHERE %s IN ?", table, quotedColumn, quotedColumn), survivorID, ids)
+
+	return res.RowsAffected, res.Error
+}
@@ -0,0 +1,78 @@
+package database
+
+import (
+	"errors"
+
+	"github.com/r4ulcl/api_template/utils/models"
+	"gorm.io/gorm"
+)
+
+// ErrRuleNotFound is returned when a notification rule does not exist.
+var ErrRuleNotFound = errors.New("notification rule not found")
+
+// ListNotificationRules returns every notification rule ordered by ID.
+func (bc *BaseController) ListNotificationRules() ([]models.NotificationRule, error) {
+	rules := []models.NotificationRule{}
+	err := bc.DB.Order("id").Find(&rules).Error
+
+	return rules, err
+}
+
+// GetNotificationRule returns a notification rule by ID.
+//
+// Returns:
+// - ErrRuleNotFound if no rule has the given ID.
+func (bc *BaseController) GetNotificationRule(id uint) (models.NotificationRule, error) {
+	var rule models.NotificationRule
+
+	err := bc.DB.First(&rule, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return rule, ErrRuleNotFound
+	}
+
+	return rule, err
+}
+
+// CreateNotificationRule stores a new notification rule.
+func (bc *BaseController) CreateNotificationRule(rule *models.NotificationRule) error {
+	rule.ID = 0
+
+	return bc.DB.Create(rule).Error
+}
+
+// UpdateNotificationRule replaces an existing notification rule.
+//
+// Returns:
+// - ErrRuleNotFound if no rule has the given ID.
+func (bc *BaseController) UpdateNotificationRule(id uint, rule *models.NotificationRule) error {
+	var existing models.NotificationRule
+	if err := bc.DB.First(&existing, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrRuleNotFound
+		}
+
+		return err
+	}
+
+	rule.ID = existing.ID
+	rule.CreatedAt = existing.CreatedAt
+
+	return bc.DB.Save(rule).Error
+}
+
+// DeleteNotificationRule removes a notification rule.
+//
+// Returns:
+// - ErrRuleNotFound if no rule has the given ID.
+func (bc *BaseController) DeleteNotificationRule(id uint) error {
+	res := bc.DB.Delete(&models.NotificationRule{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return ErrRuleNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,134 @@
+package database
+
+import (
+	"encoding/json"
+	"reflect"
+	"sync"
+	"time"
+)
+
+const (
+	// rowSizeTTL is how long the average row size of a table is cached.
+	rowSizeTTL = 5 * time.Minute
+
+	// rowSizeSample is the number of records whose JSON size is averaged on the databases without table statistics.
+	rowSizeSample = 100
+)
+
+// PageBudget caps the page size of the lists, so a page of large records
+// (e.g. with long texts or JSON fields) stays under a byte budget instead of
+// building responses of hundreds of megabytes with a large per_page.
+//
+// The average row size of each table is read from information_schema on
+// MySQL (AVG_ROW_LENGTH), and estimated from the JSON size of a sample of
+// records on the other databases. It is cached for a few minutes.
+type PageBudget struct {
+	// Bytes is the size a page should stay under.
+	Bytes int64
+
+	bc *BaseController
+
+	mu    sync.Mutex
+	sizes map[string]rowSize
+}
+
+// rowSize is the cached average row size of a table.
+type rowSize struct {
+	bytes  int64
+	readAt time.Time
+}
+
+// NewPageBudget creates a page budget reading the row sizes with a BaseController.
+//
+// Parameters:
+// - bc: The database access.
+// - bytes: The size a page should stay under.
+//
+// Returns:
+// - The page budget, or nil if bytes is zero.
+func NewPageBudget(bc *BaseController, bytes int64) *PageBudget {
+	if bytes <= 0 {
+		return nil
+	}
+
+	return &PageBudget{Bytes: bytes, bc: bc, sizes: map[string]rowSize{}}
+}
+
+// Cap returns the largest page size of a model within the budget. A nil budget never caps.
+//
+// Parameters:
+// - model: A pointer to a slice of the model being listed.
+//
+// Returns:
+// - The largest page size, at least 1, or 0 if the pages are not capped (no
+// budget, or an empty table).
+// - The average row size, in bytes.
+// - An error if the row size can't be read.
+func (pb *PageBudget) Cap(model interface{}) (int, int64, error) {
+	if pb == nil {
+		return 0, 0, nil
+	}
+
+	size, err := pb.rowSize(model)
+	if err != nil || size <= 0 {
+		return 0, size, err
+	}
+
+	return int(max(pb.Bytes/size, 1)), size, nil
+}
+
+// rowSize returns the average row size of the table of a model, cached for rowSizeTTL.
+func (pb *PageBudget) rowSize(model interface{}) (int64, error) {
+	sch, err := pb.bc.modelSchema(model)
+	if err != nil {
+		return 0, err
+	}
+
+	pb.mu.Lock()
+	cached, ok := pb.sizes[sch.Table]
+	pb.mu.Unlock()
+
+	if ok && time.Since(cached.readAt) < rowSizeTTL {
+		return cached.bytes, nil
+	}
+
+	var size int64
+
+	if pb.bc.DB.Dialector.Name() == "mysql" {
+		err = pb.bc.DB.Raw("SELECT COALESCE(MAX(AVG_ROW_LENGTH), 0) FROM information_schema.TABLES "+
+			"WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?", sch.Table).Scan(&size).Error
+	} else {
+		size, err = pb.sampleRowSize(model)
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	pb.mu.Lock()
+	pb.sizes[sch.Table] = rowSize{bytes: size, readAt: time.Now()}
+	pb.mu.Unlock()
+
+	return size, nil
+}
+
+// sampleRowSize returns the average JSON size of the first records of a model, 0 if there are none.
+func (pb *PageBudget) sampleRowSize(model interface{}) (int64, error) {
+	sample := reflect.New(reflect.TypeOf(model).Elem())
+
+	if err := pb.bc.DB.Limit(rowSizeSample).Find(sample.Interface()).Error; err != nil {
+		return 0, err
+	}
+
+	count := sample.Elem().Len()
+	if count == 0 {
+		return 0, nil
+	}
+
+	data, err := json.Marshal(sample.Interface())
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(len(data) / count), nil
+}
@@ -0,0 +1,48 @@
+package database
+
+import (
+	"encoding/json"
+
+	"github.com/r4ulcl/api_template/utils/models"
+	"gorm.io/gorm"
+)
+
+// GetPreferences returns the preferences of a user as a map of key to JSON value.
+func (bc *BaseController) GetPreferences(username string) (map[string]json.RawMessage, error) {
+	var rows []models.UserPreference
+	if err := bc.DB.Where("username = ?", username).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	preferences := make(map[string]json.RawMessage, len(rows))
+	for _, row := range rows {
+		preferences[row.Key] = json.RawMessage(row.Value)
+	}
+
+	return preferences, nil
+}
+
+// SetPreferences stores the given preferences of a user in a single transaction.
+//
+// Keys set to JSON null are deleted; keys not present are left unchanged.
+func (bc *BaseController) SetPreferences(username string, preferences map[string]json.RawMessage) error {
+	return bc.DB.Transaction(func(tx *gorm.DB) error {
+		for key, value := range preferences {
+			if string(value) == "null" {
+				if err := tx.Where(&models.UserPreference{Username: username, Key: key}).
+					Delete(&models.UserPreference{}).Error; err != nil {
+					return err
+				}
+
+				continue
+			}
+
+			row := models.UserPreference{Username: username, Key: key, Value: string(value)}
+			if err := tx.Save(&row).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,82 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"sync/atomic"
+
+	"github.com/r4ulcl/api_template/utils/models"
+	"gorm.io/gorm"
+)
+
+// StatementCache wraps GORM's prepared statement cache to count its hits and misses.
+//
+// Statements run inside transactions use the cache too, but are not counted.
+type StatementCache struct {
+	*gorm.PreparedStmtDB
+
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+// instrumentStatementCache replaces the prepared statement pool of a connection
+// opened with PrepareStmt by a StatementCache. It does nothing otherwise.
+//
+// It must be called right after opening the connection, before any session is created.
+func instrumentStatementCache(db *gorm.DB) {
+	prepared, ok := db.ConnPool.(*gorm.PreparedStmtDB)
+	if !ok {
+		return
+	}
+
+	cache := &StatementCache{PreparedStmtDB: prepared}
+	db.ConnPool = cache
+	db.Statement.ConnPool = cache
+}
+
+// ExecContext runs a statement, counting whether it was already prepared.
+func (sc *StatementCache) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	sc.count(query)
+
+	return sc.PreparedStmtDB.ExecContext(ctx, query, args...)
+}
+
+// QueryContext runs a query, counting whether it was already prepared.
+func (sc *StatementCache) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	sc.count(query)
+
+	return sc.PreparedStmtDB.QueryContext(ctx, query, args...)
+}
+
+// QueryRowContext runs a single-row query, counting whether it was already prepared.
+func (sc *StatementCache) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	sc.count(query)
+
+	return sc.PreparedStmtDB.QueryRowContext(ctx, query, args...)
+}
+
+// count records a hit if the statement is in the cache and a miss otherwise.
+func (sc *StatementCache) count(query string) {
+	sc.Mux.RLock()
+	_, ok := sc.Stmts[query]
+	sc.Mux.RUnlock()
+
+	if ok {
+		sc.hits.Add(1)
+	} else {
+		sc.misses.Add(1)
+	}
+}
+
+// Stats returns the number of cached statements and the hits and misses since startup.
+func (sc *StatementCache) Stats() models.StatementCacheStats {
+	sc.Mux.RLock()
+	cached := len(sc.Stmts)
+	sc.Mux.RUnlock()
+
+	return models.StatementCacheStats{
+		Statements: cached,
+		Hits:       sc.hits.Load(),
+		Misses:     sc.misses.Load(),
+	}
+}
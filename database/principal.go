@@ -0,0 +1,63 @@
+package database
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/r4ulcl/api_template/utils"
+	"github.com/r4ulcl/api_template/utils/models"
+	"gorm.io/gorm"
+)
+
+// ErrNoPrincipal is returned when none of the names belongs to an account.
+var ErrNoPrincipal = errors.New("no account matches the certificate")
+
+// PrincipalForNames maps the names of a client certificate to an account.
+//
+// Each name is tried in order as a username, as the email of a user (if it
+// contains "@") and as the client ID of an enabled service account. The first
+// match wins.
+//
+// Returns:
+// - The matching account.
+// - ErrNoPrincipal if no name matches.
+func (bc *BaseController) PrincipalForNames(names []string) (models.Principal, error) {
+	for _, name := range names {
+		var user models.User
+
+		err := bc.DB.Where("username = ?", utils.NormalizeUsername(name)).First(&user).Error
+		if err == nil {
+			return userPrincipal(user), nil
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.Principal{}, err
+		}
+
+		if strings.Contains(name, "@") {
+			user, err := bc.GetUserByEmail(strings.ToLower(name))
+			if err == nil {
+				return userPrincipal(user), nil
+			} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return models.Principal{}, err
+			}
+		}
+
+		account, err := bc.GetServiceAccount(name)
+		if err == nil && !account.Disabled {
+			return models.Principal{
+				Username:    account.ClientID,
+				Role:        account.Role,
+				AccountType: models.ServiceAccountType,
+				Scopes:      account.ScopeList(),
+			}, nil
+		} else if err != nil && !errors.Is(err, ErrServiceAccountNotFound) {
+			return models.Principal{}, err
+		}
+	}
+
+	return models.Principal{}, ErrNoPrincipal
+}
+
+// userPrincipal returns the principal of a user.
+func userPrincipal(user models.User) models.Principal {
+	return models.Principal{Username: user.Username, Role: user.Role, AccountType: models.UserAccountType}
+}
@@ -0,0 +1,148 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// ErrInvalidField is returned when a query references a field that does not
+// exist on the model.
+var ErrInvalidField = errors.New("invalid field")
+
+// QueryOptions groups the optional modifiers applied by GetAllRecords.
+type QueryOptions struct {
+	// Filters is a map of field names to the value they must be equal to.
+	Filters map[string]interface{}
+
+	// Conditions lists the parsed filters (see ParseFilter), combined with Filters.
+	Conditions []Filter
+
+	// Sort lists the fields used to order the results. A leading "-" sorts descending.
+	Sort []string
+
+	// Fields restricts the selected columns. Primary keys are always selected.
+	Fields []string
+
+	// Limit caps the number of returned records. Zero returns every record.
+	Limit int
+
+	// Offset skips the given number of records before returning results.
+	Offset int
+}
+
+// modelSchema parses the GORM schema of a model (or a pointer to a slice of models).
+func (bc *BaseController) modelSchema(model interface{}) (*schema.Schema, error) {
+	stmt := &gorm.Statement{DB: bc.DB}
+	if err := stmt.Parse(model); err != nil {
+		return nil, err
+	}
+
+	return stmt.Schema, nil
+}
+
+// lookupColumn resolves a field name (JSON name, struct name or column name)
+// to its database column name.
+//
+// Fields hidden from JSON (json:"-", e.g. password hashes) never resolve, so
+// they can't be filtered, sorted or selected on.
+//
+// Returns ErrInvalidField if the field does not map to a column of the model.
+func lookupColumn(sch *schema.Schema, name string) (string, error) {
+	for _, field := range sch.Fields {
+		if field.DBName == "" {
+			continue
+		}
+
+		jsonName := jsonFieldName(field)
+		if jsonName == name && jsonName != "-" {
+			return field.DBName, nil
+		}
+	}
+
+	if field := sch.LookUpField(name); field != nil && field.DBName != "" && jsonFieldName(field) != "-" {
+		return field.DBName, nil
+	}
+
+	return "", fmt.Errorf("%w: %s", ErrInvalidField, name)
+}
+
+// jsonFieldName returns the JSON name of a field from its json tag, "-" for hidden fields.
+func jsonFieldName(field *schema.Field) string {
+	return strings.Split(field.Tag.Get("json"), ",")[0]
+}
+
+// applyQueryOptions adds the filters, sort order, field selection and limit to a query.
+//
+// Every field name is validated against the model schema before being used,
+// so user input never reaches the SQL statement as an identifier.
+func applyQueryOptions(tx *gorm.DB, sch *schema.Schema, opts QueryOptions) (*gorm.DB, error) {
+	filters := slices.Clone(opts.Conditions)
+	for key, value := range opts.Filters {
+		filters = append(filters, Filter{Field: key, Op: FilterEq, Value: fmt.Sprint(value)})
+	}
+
+	if len(filters) > 0 {
+		condition, args, err := compileFilters(sch, tx.Dialector.Name(), filters)
+		if err != nil {
+			return nil, err
+		}
+
+		tx = tx.Where(condition, args...)
+	}
+
+	for _, sortField := range opts.Sort {
+		desc := strings.HasPrefix(sortField, "-")
+		name := strings.TrimPrefix(sortField, "-")
+
+		column, err := lookupColumn(sch, name)
+		if err != nil {
+			// Location fields sort by the distance to the point of their near filter
+			order, ok := distanceOrder(sch, tx.Dialector.Name(), name, desc, filters)
+			if !ok {
+				return nil, err
+			}
+
+			tx = tx.Order(order)
+
+			continue
+		}
+
+		if desc {
+			column += " DESC"
+		}
+
+		tx = tx.Order(column)
+	}
+
+	if len(opts.Fields) > 0 {
+		columns := []string{}
+
+		for _, pk := range sch.PrimaryFields {
+			columns = append(columns, pk.DBName)
+		}
+
+		for _, name := range opts.Fields {
+			column, err := lookupColumn(sch, name)
+			if err != nil {
+				return nil, err
+			}
+
+			if !slices.Contains(columns, column) {
+				columns = append(columns, column)
+			}
+		}
+
+		tx = tx.Select(columns)
+	}
+
+	if opts.Limit > 0 {
+		tx = tx.Limit(opts.Limit).Offset(opts.Offset)
+	}
+
+	return tx, nil
+}
@@ -0,0 +1,153 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"gorm.io/gorm/schema"
+)
+
+// ErrQueryTooExpensive is returned when a query exceeds the QueryCostLimits.
+var ErrQueryTooExpensive = errors.New("query too expensive")
+
+// QueryCostLimits are the heuristics rejecting the list queries that would tie
+// up the database. Zero disables each limit.
+type QueryCostLimits struct {
+	// MaxInValues is the largest number of values of an "in" filter.
+	MaxInValues int
+
+	// LargeTableRows is the table size (in rows) from which "like" patterns
+	// starting with a wildcard, which scan the whole table, are rejected.
+	LargeTableRows int64
+
+	// MaxUnindexedOffset is the largest offset of a query sorted by a field
+	// without index, which sorts every matching row to skip them.
+	MaxUnindexedOffset int
+}
+
+// CheckQueryCost checks a list query against the QueryCostLimits before it runs.
+//
+// Parameters:
+// - model: A pointer to a slice of the model being listed.
+// - opts: The query options, with the offset of the requested page.
+//
+// Returns:
+// - ErrQueryTooExpensive, with the way to rewrite the query, if a limit is exceeded.
+// - ErrInvalidField if a sorted field does not exist on the model.
+func (bc *BaseController) CheckQueryCost(model interface{}, opts QueryOptions) error {
+	limits := bc.QueryLimits
+
+	sch, err := bc.modelSchema(model)
+	if err != nil {
+		return err
+	}
+
+	for _, filter := range opts.Conditions {
+		if filter.Op != FilterIn || limits.MaxInValues <= 0 {
+			continue
+		}
+
+		if values := len(splitValues(filter.Value)); values > limits.MaxInValues {
+			return fmt.Errorf("%w: the in filter of %s has %d values, above the limit of %d; "+
+				"split it in several requests, or filter on a range with gte and lte",
+				ErrQueryTooExpensive, filter.Field, values, limits.MaxInValues)
+		}
+	}
+
+	if err := bc.checkLeadingWildcards(sch, opts.Conditions); err != nil {
+		return err
+	}
+
+	if limits.MaxUnindexedOffset <= 0 || opts.Offset <= limits.MaxUnindexedOffset {
+		return nil
+	}
+
+	indexed := indexedColumns(sch)
+
+	for _, sortField := range opts.Sort {
+		name := strings.TrimPrefix(sortField, "-")
+
+		column, err := lookupColumn(sch, name)
+		if err != nil {
+			// Location fields sort by distance, checked when the query runs
+			continue
+		}
+
+		if !slices.Contains(indexed, column) {
+			return fmt.Errorf("%w: %s has no index, so sorting by it with an offset of %d, above the limit of %d, "+
+				"sorts every matching record; sort by an indexed field (%s), or narrow the filters",
+				ErrQueryTooExpensive, name, opts.Offset, limits.MaxUnindexedOffset, strings.Join(indexed, ", "))
+		}
+	}
+
+	return nil
+}
+
+// checkLeadingWildcards rejects the "like" filters starting with a wildcard on
+// tables of LargeTableRows rows or more. The table is only sized when such a
+// filter is present.
+func (bc *BaseController) checkLeadingWildcards(sch *schema.Schema, filters []Filter) error {
+	if bc.QueryLimits.LargeTableRows <= 0 {
+		return nil
+	}
+
+	for _, filter := range filters {
+		if filter.Op != FilterLike || (!strings.HasPrefix(filter.Value, "%") && !strings.HasPrefix(filter.Value, "_")) {
+			continue
+		}
+
+		rows, err := bc.tableRows(sch)
+		if err != nil {
+			return err
+		}
+
+		if rows < bc.QueryLimits.LargeTableRows {
+			return nil
+		}
+
+		return fmt.Errorf("%w: the like filter of %s starts with a wildcard, which reads all the %d records; "+
+			"anchor the pattern at the start (e.g. %q), or add another filter on an indexed field",
+			ErrQueryTooExpensive, filter.Field, rows, strings.TrimLeft(filter.Value, "%_"))
+	}
+
+	return nil
+}
+
+// tableRows returns the number of rows of the table of a model: the estimate of
+// information_schema on MySQL, which avoids scanning large tables, or COUNT(*).
+func (bc *BaseController) tableRows(sch *schema.Schema) (int64, error) {
+	var rows int64
+
+	if bc.DB.Dialector.Name() == "mysql" {
+		err := bc.DB.Raw("SELECT COALESCE(MAX(TABLE_ROWS), 0) FROM information_schema.TABLES "+
+			"WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?", sch.Table).Scan(&rows).Error
+
+		return rows, err
+	}
+
+	err := bc.DB.Table(sch.Table).Count(&rows).Error
+
+	return rows, err
+}
+
+// indexedColumns returns the columns a query can be sorted by with an index:
+// the first primary key and the first column of each index of the model.
+func indexedColumns(sch *schema.Schema) []string {
+	columns := []string{}
+
+	if len(sch.PrimaryFields) > 0 {
+		columns = append(columns, sch.PrimaryFields[0].DBName)
+	}
+
+	for _, index := range sch.ParseIndexes() {
+		if len(index.Fields) > 0 && index.Fields[0].Field != nil && !slices.Contains(columns, index.Fields[0].DBName) {
+			columns = append(columns, index.Fields[0].DBName)
+		}
+	}
+
+	slices.Sort(columns)
+
+	return columns
+}
@@ -0,0 +1,206 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/r4ulcl/api_template/utils/models"
+	"gorm.io/gorm"
+)
+
+// querySemaphoreName is the name of the QuerySemaphore plugin of a connection.
+const querySemaphoreName = "query_semaphore"
+
+// querySlotKey is the instance key of the function releasing the slot of a statement.
+const querySlotKey = "query_semaphore:release"
+
+// ErrDBBusy is returned when a statement waited too long for a slot of the QuerySemaphore.
+var ErrDBBusy = errors.New("too many concurrent database queries, please retry later")
+
+// QuerySemaphore limits the statements run at once by GORM, so a spike of
+// requests queues in the application, where it is bounded by a timeout,
+// instead of exhausting the MySQL connections and timing out every request.
+//
+// A slot is held while a statement runs, not while the rows of Rows() are
+// read. The statements of transactions already hold a connection and are
+// not limited, so a transaction never waits for a slot while holding locks.
+type QuerySemaphore struct {
+	slots   chan struct{}
+	timeout time.Duration
+
+	waiting  atomic.Int64
+	acquired atomic.Int64
+	waited   atomic.Int64
+	rejected atomic.Int64
+
+	mu        sync.Mutex
+	totalWait time.Duration
+	maxWait   time.Duration
+}
+
+// InstallQuerySemaphore limits the concurrent statements of a connection. It
+// does nothing if limit is zero.
+//
+// Parameters:
+// - db: The connection, right after opening it.
+// - limit: The largest number of statements run at once.
+// - timeout: How long a statement waits for a slot before failing with ErrDBBusy.
+//
+// Returns:
+// - An error if the callbacks can't be registered.
+func InstallQuerySemaphore(db *gorm.DB, limit int, timeout time.Duration) error {
+	if limit <= 0 {
+		return nil
+	}
+
+	return db.Use(&QuerySemaphore{slots: make(chan struct{}, limit), timeout: timeout})
+}
+
+// Name returns the name of the plugin.
+func (qs *QuerySemaphore) Name() string {
+	return querySemaphoreName
+}
+
+// Initialize registers the callbacks taking and releasing a slot around the
+// statements. Only the statement itself holds the slot: it is taken after the
+// associations saved before it and released before the ones saved or preloaded
+// after it, so their statements take a slot of their own instead of waiting
+// for the one their parent still holds.
+func (qs *QuerySemaphore) Initialize(db *gorm.DB) error {
+	callback := db.Callback()
+
+	for _, err := range []error{
+		callback.Create().After("gorm:save_before_associations").Before("gorm:create").
+			Register("query_semaphore:acquire", qs.before),
+		callback.Create().After("gorm:create").Before("gorm:save_after_associations").
+			Register("query_semaphore:release", qs.after),
+		callback.Query().Before("gorm:query").Register("query_semaphore:acquire", qs.before),
+		callback.Query().After("gorm:query").Before("gorm:preload").Register("query_semaphore:release", qs.after),
+		callback.Update().After("gorm:save_before_associations").Before("gorm:update").
+			Register("query_semaphore:acquire", qs.before),
+		callback.Update().After("gorm:update").Before("gorm:save_after_associations").
+			Register("query_semaphore:release", qs.after),
+		callback.Delete().Before("gorm:delete").Register("query_semaphore:acquire", qs.before),
+		callback.Delete().After("gorm:delete").Register("query_semaphore:release", qs.after),
+		callback.Row().Before("gorm:row").Register("query_semaphore:acquire", qs.before),
+		callback.Row().After("gorm:row").Register("query_semaphore:release", qs.after),
+		callback.Raw().Before("gorm:raw").Register("query_semaphore:acquire", qs.before),
+		callback.Raw().After("gorm:raw").Register("query_semaphore:release", qs.after),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// before takes a slot for a statement, failing it with ErrDBBusy if none frees up in time.
+func (qs *QuerySemaphore) before(db *gorm.DB) {
+	if db.Error != nil || db.DryRun {
+		return
+	}
+
+	if _, inTransaction := db.Statement.ConnPool.(gorm.TxCommitter); inTransaction {
+		return
+	}
+
+	ctx := db.Statement.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if err := qs.acquire(ctx); err != nil {
+		_ = db.AddError(err)
+
+		return
+	}
+
+	var once sync.Once
+
+	db.InstanceSet(querySlotKey, func() {
+		once.Do(func() { <-qs.slots })
+	})
+}
+
+// after releases the slot of a statement, if it took one.
+func (qs *QuerySemaphore) after(db *gorm.DB) {
+	if release, ok := db.InstanceGet(querySlotKey); ok {
+		release.(func())()
+	}
+}
+
+// acquire takes a slot, waiting until one is free, the timeout expires or the context is canceled.
+func (qs *QuerySemaphore) acquire(ctx context.Context) error {
+	select {
+	case qs.slots <- struct{}{}:
+		qs.acquired.Add(1)
+
+		return nil
+	default:
+	}
+
+	qs.waiting.Add(1)
+	defer qs.waiting.Add(-1)
+
+	start := time.Now()
+
+	timer := time.NewTimer(qs.timeout)
+	defer timer.Stop()
+
+	select {
+	case qs.slots <- struct{}{}:
+	case <-timer.C:
+		qs.rejected.Add(1)
+
+		return ErrDBBusy
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	wait := time.Since(start)
+
+	qs.acquired.Add(1)
+	qs.waited.Add(1)
+
+	qs.mu.Lock()
+	qs.totalWait += wait
+	qs.maxWait = max(qs.maxWait, wait)
+	qs.mu.Unlock()
+
+	return nil
+}
+
+// Stats returns the usage of the semaphore and the time the statements waited for a slot since startup.
+func (qs *QuerySemaphore) Stats() models.QuerySemaphoreStats {
+	stats := models.QuerySemaphoreStats{
+		Limit:    cap(qs.slots),
+		InFlight: len(qs.slots),
+		Waiting:  qs.waiting.Load(),
+		Acquired: qs.acquired.Load(),
+		Waited:   qs.waited.Load(),
+		Rejected: qs.rejected.Load(),
+	}
+
+	qs.mu.Lock()
+	defer qs.mu.Unlock()
+
+	stats.TotalWaitMs = qs.totalWait.Milliseconds()
+	stats.MaxWaitMs = qs.maxWait.Milliseconds()
+
+	if stats.Waited > 0 {
+		stats.AvgWaitMs = float64(qs.totalWait.Microseconds()) / float64(stats.Waited) / 1000
+	}
+
+	return stats
+}
+
+// QuerySemaphore returns the semaphore limiting the statements of the connection, or nil if none does.
+func (bc *BaseController) QuerySemaphore() *QuerySemaphore {
+	semaphore, _ := bc.DB.Config.Plugins[querySemaphoreName].(*QuerySemaphore)
+
+	return semaphore
+}
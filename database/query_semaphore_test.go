@@ -0,0 +1,45 @@
+package database_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/r4ulcl/api_template/database"
+	"github.com/r4ulcl/api_template/testsupport"
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+// TestQuerySemaphoreAssociations checks that the statements of the associations don't wait for the slot
+// of their parent, which deadlocks until the timeout at a limit of one.
+func TestQuerySemaphoreAssociations(t *testing.T) {
+	srv := testsupport.NewTestServer(t)
+
+	if err := database.InstallQuerySemaphore(srv.DB, 1, 500*time.Millisecond); err != nil {
+		t.Fatalf("install semaphore: %v", err)
+	}
+
+	record := models.ExampleRelational{
+		Example1Field1:    "semaphore_1",
+		Example2Field1:    "semaphore_2",
+		Field3:            "value",
+		Example1Reference: models.Example1{Field1: "semaphore_1", Field2: "value"},
+		Example2Reference: models.Example2{Field1: "semaphore_2", Field2: "value"},
+	}
+	if err := srv.DB.Create(&record).Error; err != nil {
+		t.Fatalf("create with associations: %v", err)
+	}
+
+	var records []models.ExampleRelational
+	if err := srv.DB.Preload("Example1Reference").Preload("Example2Reference").Find(&records).Error; err != nil {
+		t.Fatalf("preloaded find: %v", err)
+	}
+
+	if len(records) != 1 || records[0].Example1Reference.Field1 != "semaphore_1" ||
+		records[0].Example2Reference.Field1 != "semaphore_2" {
+		t.Fatalf("preloaded find returned %+v", records)
+	}
+
+	if stats := srv.Controller.BC.QuerySemaphore().Stats(); stats.Rejected != 0 || stats.InFlight != 0 {
+		t.Fatalf("semaphore stats = %+v, want no rejected and no in-flight statement", stats)
+	}
+}
@@ -0,0 +1,226 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/r4ulcl/api_template/utils"
+	"github.com/r4ulcl/api_template/utils/models"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrQuotaNotFound is returned when a quota does not exist.
+	ErrQuotaNotFound = errors.New("quota not found")
+
+	// ErrQuotaExists is returned when a resource already has a quota for the same owner.
+	ErrQuotaExists = errors.New("the resource already has a quota for this owner")
+
+	// ErrQuotaExceeded is returned when creating a record would exceed the quota of its owner.
+	ErrQuotaExceeded = errors.New("quota exceeded")
+)
+
+// ListQuotas returns every quota ordered by resource and owner.
+func (bc *BaseController) ListQuotas() ([]models.Quota, error) {
+	quotas := []models.Quota{}
+	err := bc.DB.Order("resource, owner").Find(&quotas).Error
+
+	return quotas, err
+}
+
+// GetQuota returns a quota by ID.
+//
+// Returns:
+// - ErrQuotaNotFound if no quota has the given ID.
+func (bc *BaseController) GetQuota(id uint) (models.Quota, error) {
+	var quota models.Quota
+
+	err := bc.DB.First(&quota, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return quota, ErrQuotaNotFound
+	}
+
+	return quota, err
+}
+
+// CreateQuota stores a new quota.
+//
+// Returns:
+// - ErrQuotaExists if the resource already has a quota for the same owner.
+func (bc *BaseController) CreateQuota(quota *models.Quota) error {
+	quota.ID = 0
+
+	if err := bc.DB.Create(quota).Error; err != nil {
+		if IsDuplicateKeyError(err) {
+			return ErrQuotaExists
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// UpdateQuota replaces an existing quota.
+//
+// Returns:
+// - ErrQuotaNotFound if no quota has the given ID.
+// - ErrQuotaExists if the resource already has another quota for the same owner.
+func (bc *BaseController) UpdateQuota(id uint, quota *models.Quota) error {
+	var existing models.Quota
+	if err := bc.DB.First(&existing, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrQuotaNotFound
+		}
+
+		return err
+	}
+
+	quota.ID = existing.ID
+	quota.CreatedAt = existing.CreatedAt
+
+	if err := bc.DB.Save(quota).Error; err != nil {
+		if IsDuplicateKeyError(err) {
+			return ErrQuotaExists
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// DeleteQuota removes a quota.
+//
+// Returns:
+// - ErrQuotaNotFound if no quota has the given ID.
+func (bc *BaseController) DeleteQuota(id uint) error {
+	res := bc.DB.Delete(&models.Quota{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return ErrQuotaNotFound
+	}
+
+	return nil
+}
+
+// CheckQuota checks that the owner of a new record may create it: the owner's
+// quota of the resource, or else its default quota, must be above the number
+// of records the owner has. Resources without owner field are counted as a whole.
+//
+// Parameters:
+// - resource: The name of the resource (e.g. "example2").
+// - model: A pointer to the new record, with its fields populated.
+//
+// Returns:
+// - ErrQuotaExceeded if the owner has reached its quota.
+// - nil if the record can be created, or the resource has no quota.
+func (bc *BaseController) CheckQuota(resource string, model interface{}) error {
+	column, owner, err := bc.recordOwner(model)
+	if err != nil {
+		return err
+	}
+
+	var quotas []models.Quota
+	if err := bc.DB.Where("resource = ? AND owner IN ?", resource, []string{"", owner}).Find(&quotas).Error; err != nil {
+		return err
+	}
+
+	var quota *models.Quota
+
+	for i := range quotas {
+		// The quota of the owner takes precedence over the default one
+		if quota == nil || (column != "" && quotas[i].Owner == owner) {
+			quota = &quotas[i]
+		}
+	}
+
+	if quota == nil {
+		return nil
+	}
+
+	tx := bc.DB.Model(reflect.New(reflect.Indirect(reflect.ValueOf(model)).Type()).Interface())
+	if column != "" {
+		tx = tx.Where(bc.DB.Statement.Quote(column)+" = ?", owner)
+	}
+
+	var rows int64
+	if err := tx.Count(&rows).Error; err != nil {
+		return err
+	}
+
+	if rows < quota.MaxRows {
+		return nil
+	}
+
+	if column == "" {
+		return fmt.Errorf("%w: %s allows %d records", ErrQuotaExceeded, resource, quota.MaxRows)
+	}
+
+	return fmt.Errorf("%w: %s allows %d records per owner, and %s has %d",
+		ErrQuotaExceeded, resource, quota.MaxRows, owner, rows)
+}
+
+// QuotaUsage returns the number of records of each owner of a resource, or the
+// total under the empty owner for resources without owner field.
+//
+// Parameters:
+// - model: A pointer to a model, or a slice of it.
+func (bc *BaseController) QuotaUsage(model interface{}) (map[string]int64, error) {
+	sch, err := bc.modelSchema(model)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := map[string]int64{}
+
+	name, ok := utils.OwnerField(sch.ModelType)
+	if !ok {
+		var rows int64
+		err := bc.DB.Model(model).Count(&rows).Error
+		usage[""] = rows
+
+		return usage, err
+	}
+
+	// Rows stored before the owner field existed have no owner
+	column := "COALESCE(" + bc.DB.Statement.Quote(sch.LookUpField(name).DBName) + ", '')"
+
+	var counts []struct {
+		Owner string
+		Total int64
+	}
+
+	if err := bc.DB.Model(model).Select(column + " AS owner, COUNT(*) AS total").Group(column).
+		Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+
+	for _, count := range counts {
+		usage[count.Owner] = count.Total
+	}
+
+	return usage, nil
+}
+
+// recordOwner returns the column of the owner field of a record and its value,
+// or an empty column if its model has no owner field.
+func (bc *BaseController) recordOwner(model interface{}) (string, string, error) {
+	sch, err := bc.modelSchema(model)
+	if err != nil {
+		return "", "", err
+	}
+
+	name, ok := utils.OwnerField(sch.ModelType)
+	if !ok {
+		return "", "", nil
+	}
+
+	owner := fmt.Sprint(reflect.Indirect(reflect.ValueOf(model)).FieldByName(name).Interface())
+
+	return sch.LookUpField(name).DBName, owner, nil
+}
@@ -0,0 +1,151 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/r4ulcl/api_template/utils/models"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrRecordLocked is returned when another user holds the lock of a record.
+	ErrRecordLocked = errors.New("record locked")
+
+	// ErrLockNotFound is returned when a record is not locked.
+	ErrLockNotFound = errors.New("lock not found")
+)
+
+// GetRecordLock returns the active lock of a record.
+//
+// Returns:
+// - ErrLockNotFound if the record is not locked or its lock expired.
+func (bc *BaseController) GetRecordLock(resource, id string) (models.RecordLock, error) {
+	var lock models.RecordLock
+
+	err := bc.DB.Where("resource = ? AND record_id = ?", resource, id).First(&lock).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && !lock.Active(time.Now())) {
+		return models.RecordLock{}, ErrLockNotFound
+	}
+
+	return lock, err
+}
+
+// LockRecord takes or renews the lock of a record for a user.
+//
+// Expired locks of other users are taken over.
+//
+// Parameters:
+// - resource: The name of the resource (e.g. "example1").
+// - id: The tokenized ID of the record.
+// - username: The user taking the lock.
+// - ttl: How long the lock lasts without being renewed.
+//
+// Returns:
+// - The stored lock.
+// - ErrRecordLocked if another user holds an active lock.
+func (bc *BaseController) LockRecord(resource, id, username string, ttl time.Duration) (models.RecordLock, error) {
+	now := time.Now()
+	lock := models.RecordLock{Resource: resource, RecordID: id, LockedBy: username, ExpiresAt: now.Add(ttl)}
+
+	err := bc.DB.Transaction(func(tx *gorm.DB) error {
+		var existing models.RecordLock
+
+		err := tx.Where("resource = ? AND record_id = ?", resource, id).First(&existing).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return tx.Create(&lock).Error
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if existing.LockedBy != username && existing.Active(now) {
+			return lockedError(existing)
+		}
+
+		if existing.LockedBy == username {
+			lock.CreatedAt = existing.CreatedAt
+		} else {
+			lock.CreatedAt = now
+		}
+
+		// Only replace the lock read above, in case another user took it meanwhile
+		res := tx.Model(&models.RecordLock{}).
+			Where("resource = ? AND record_id = ? AND locked_by = ? AND expires_at = ?",
+				resource, id, existing.LockedBy, existing.ExpiresAt).
+			Updates(map[string]interface{}{"locked_by": username, "expires_at": lock.ExpiresAt, "created_at": lock.CreatedAt})
+		if res.Error != nil {
+			return res.Error
+		}
+
+		if res.RowsAffected == 0 {
+			return fmt.Errorf("%w: by another user", ErrRecordLocked)
+		}
+
+		return nil
+	})
+	if err != nil && IsDuplicateKeyError(err) {
+		// Another user created the lock at the same time
+		return models.RecordLock{}, fmt.Errorf("%w: by another user", ErrRecordLocked)
+	}
+
+	return lock, err
+}
+
+// UnlockRecord releases the lock of a record.
+//
+// Parameters:
+// - resource: The name of the resource (e.g. "example1").
+// - id: The tokenized ID of the record.
+// - username: The user releasing the lock.
+// - force: Release the lock even if another user holds it.
+//
+// Returns:
+// - ErrLockNotFound if the record is not locked.
+// - ErrRecordLocked if another user holds an active lock and force is false.
+func (bc *BaseController) UnlockRecord(resource, id, username string, force bool) error {
+	lock, err := bc.GetRecordLock(resource, id)
+	if err != nil {
+		return err
+	}
+
+	if lock.LockedBy != username && !force {
+		return lockedError(lock)
+	}
+
+	return bc.DB.Where("resource = ? AND record_id = ?", resource, id).Delete(&models.RecordLock{}).Error
+}
+
+// CheckRecordLock reports whether a user may modify a record: it is not locked,
+// its lock expired, or the user holds the lock.
+//
+// Returns:
+// - ErrRecordLocked, with the lock holder and expiry, if another user holds the lock.
+func (bc *BaseController) CheckRecordLock(resource, id, username string) error {
+	lock, err := bc.GetRecordLock(resource, id)
+	if errors.Is(err, ErrLockNotFound) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if lock.LockedBy != username {
+		return lockedError(lock)
+	}
+
+	return nil
+}
+
+// ReleaseRecordLock removes the lock of a record, e.g. once the record is deleted.
+func (bc *BaseController) ReleaseRecordLock(resource, id string) error {
+	return bc.DB.Where("resource = ? AND record_id = ?", resource, id).Delete(&models.RecordLock{}).Error
+}
+
+// lockedError returns ErrRecordLocked with the holder and expiry of a lock.
+func lockedError(lock models.RecordLock) error {
+	return fmt.Errorf("%w by %s until %s", ErrRecordLocked, lock.LockedBy, lock.ExpiresAt.UTC().Format(time.RFC3339))
+}
@@ -0,0 +1,243 @@
+package database
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/r4ulcl/api_template/utils/models"
+	"gorm.io/gorm"
+)
+
+// ErrReportNotFound is returned when a report does not exist.
+var ErrReportNotFound = errors.New("report not found")
+
+// ListReports returns every report ordered by ID.
+func (bc *BaseController) ListReports() ([]models.Report, error) {
+	reports := []models.Report{}
+	err := bc.DB.Order("id").Find(&reports).Error
+
+	return reports, err
+}
+
+// GetReport returns a report by ID.
+//
+// Returns:
+// - ErrReportNotFound if no report has the given ID.
+func (bc *BaseController) GetReport(id uint) (models.Report, error) {
+	var report models.Report
+
+	err := bc.DB.First(&report, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return report, ErrReportNotFound
+	}
+
+	return report, err
+}
+
+// CreateReport stores a new report, which runs as soon as the scheduler checks it.
+func (bc *BaseController) CreateReport(report *models.Report) error {
+	report.ID = 0
+	report.LastRunAt = nil
+	report.LastError = ""
+
+	return bc.DB.Create(report).Error
+}
+
+// UpdateReport replaces an existing report, keeping the state of its last run.
+//
+// Changing the view drops the rows of the last run, so the next run is a new baseline.
+//
+// Returns:
+// - ErrReportNotFound if no report has the given ID.
+func (bc *BaseController) UpdateReport(id uint, report *models.Report) error {
+	existing, err := bc.GetReport(id)
+	if err != nil {
+		return err
+	}
+
+	report.ID = existing.ID
+	report.CreatedAt = existing.CreatedAt
+	report.LastRunAt = existing.LastRunAt
+	report.LastError = existing.LastError
+
+	return bc.DB.Transaction(func(tx *gorm.DB) error {
+		if existing.View != report.View || existing.Owner != report.Owner {
+			if err := tx.Delete(&models.ReportSnapshot{}, id).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Save(report).Error
+	})
+}
+
+// DeleteReport removes a report and the rows of its last run.
+//
+// Returns:
+// - ErrReportNotFound if no report has the given ID.
+func (bc *BaseController) DeleteReport(id uint) error {
+	return bc.DB.Transaction(func(tx *gorm.DB) error {
+		res := tx.Delete(&models.Report{}, id)
+		if res.Error != nil {
+			return res.Error
+		}
+
+		if res.RowsAffected == 0 {
+			return ErrReportNotFound
+		}
+
+		return tx.Delete(&models.ReportSnapshot{}, id).Error
+	})
+}
+
+// DueReports returns the enabled reports that never ran or whose interval elapsed.
+func (bc *BaseController) DueReports(now time.Time) ([]models.Report, error) {
+	var reports []models.Report
+	if err := bc.DB.Where("enabled = ?", true).Order("id").Find(&reports).Error; err != nil {
+		return nil, err
+	}
+
+	due := reports[:0]
+
+	for _, report := range reports {
+		if report.LastRunAt == nil || !report.LastRunAt.Add(time.Duration(report.Interval)*time.Second).After(now) {
+			due = append(due, report)
+		}
+	}
+
+	return due, nil
+}
+
+// ClaimReport records the start of a run of a report, so other instances
+// checking the same due report don't run it too.
+//
+// Returns:
+// - false if the report ran since it was read.
+func (bc *BaseController) ClaimReport(report models.Report, now time.Time) (bool, error) {
+	tx := bc.DB.Model(&models.Report{}).Where("id = ?", report.ID)
+	if report.LastRunAt == nil {
+		tx = tx.Where("last_run_at IS NULL")
+	} else {
+		tx = tx.Where("last_run_at = ?", *report.LastRunAt)
+	}
+
+	res := tx.Update("last_run_at", now)
+
+	return res.RowsAffected == 1, res.Error
+}
+
+// SetReportError stores the error of the last run of a report, or clears it if err is nil.
+func (bc *BaseController) SetReportError(id uint, err error) error {
+	message := ""
+	if err != nil {
+		message = err.Error()
+	}
+
+	return bc.DB.Model(&models.Report{}).Where("id = ?", id).Update("last_error", message).Error
+}
+
+// DiffReportSnapshot compares the rows returned by a run of a report with the
+// rows of its previous run, then stores them for the next run.
+//
+// Parameters:
+// - reportID: The ID of the report.
+// - rows: The JSON of the rows returned by the run, keyed by their tokenized ID.
+// - ranAt: The timestamp of the run.
+//
+// Returns:
+// - The differences with the previous run, or a baseline diff if there is none.
+func (bc *BaseController) DiffReportSnapshot(reportID uint, rows map[string]json.RawMessage,
+	ranAt time.Time,
+) (models.ReportDiff, error) {
+	diff := models.ReportDiff{RanAt: ranAt, Rows: len(rows), New: []string{}, Changed: []models.ReportChange{},
+		Removed: []string{}}
+
+	encoded, err := json.Marshal(rows)
+	if err != nil {
+		return diff, err
+	}
+
+	err = bc.DB.Transaction(func(tx *gorm.DB) error {
+		var snapshot models.ReportSnapshot
+
+		err := tx.First(&snapshot, reportID).Error
+
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			diff.Baseline = true
+		case err != nil:
+			return err
+		default:
+			var previous map[string]json.RawMessage
+			if err := json.Unmarshal([]byte(snapshot.Rows), &previous); err != nil {
+				return err
+			}
+
+			diffRows(&diff, previous, rows)
+		}
+
+		return tx.Save(&models.ReportSnapshot{ReportID: reportID, Rows: string(encoded), TakenAt: ranAt}).Error
+	})
+
+	return diff, err
+}
+
+// diffRows fills the new, changed and removed rows of a diff, sorted by ID.
+func diffRows(diff *models.ReportDiff, previous, current map[string]json.RawMessage) {
+	for id, row := range current {
+		before, ok := previous[id]
+		if !ok {
+			diff.New = append(diff.New, id)
+
+			continue
+		}
+
+		if fields := changedFields(before, row); len(fields) > 0 {
+			diff.Changed = append(diff.Changed, models.ReportChange{ID: id, Fields: fields})
+		}
+	}
+
+	for id := range previous {
+		if _, ok := current[id]; !ok {
+			diff.Removed = append(diff.Removed, id)
+		}
+	}
+
+	slices.Sort(diff.New)
+	slices.Sort(diff.Removed)
+	slices.SortFunc(diff.Changed, func(a, b models.ReportChange) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+}
+
+// changedFields returns the sorted names of the fields whose JSON value differs between two rows.
+func changedFields(before, after json.RawMessage) []string {
+	var old, updated map[string]json.RawMessage
+
+	// The rows are encoded models, so they are always JSON objects
+	if json.Unmarshal(before, &old) != nil || json.Unmarshal(after, &updated) != nil {
+		return nil
+	}
+
+	var fields []string
+
+	for field, value := range updated {
+		if previous, ok := old[field]; !ok || !bytes.Equal(previous, value) {
+			fields = append(fields, field)
+		}
+	}
+
+	for field := range old {
+		if _, ok := updated[field]; !ok {
+			fields = append(fields, field)
+		}
+	}
+
+	slices.Sort(fields)
+
+	return fields
+}
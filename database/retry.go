@@ -0,0 +1,75 @@
+package database
+
+import (
+	"database/sql/driver"
+	"errors"
+	"log"
+	"math/rand/v2"
+	"syscall"
+	"time"
+
+	gomysql "github.com/go-sql-driver/mysql"
+)
+
+// MySQL error numbers of the transient errors.
+const (
+	// mysqlLockWaitTimeout is "Lock wait timeout exceeded; try restarting transaction".
+	mysqlLockWaitTimeout = 1205
+
+	// mysqlDeadlock is "Deadlock found when trying to get lock; try restarting transaction".
+	mysqlDeadlock = 1213
+)
+
+// RetryPolicy retries the operations of the BaseController failing with a
+// transient error (deadlock, lock wait timeout, connection reset), which
+// succeed when run again, instead of surfacing them as 500s. The zero value
+// runs each operation once.
+type RetryPolicy struct {
+	// MaxAttempts is the number of attempts of an operation, including the first one.
+	MaxAttempts int
+
+	// BaseDelay is the delay before the first retry, doubled on each retry, with jitter.
+	BaseDelay time.Duration
+
+	// MaxDelay caps the delay between two attempts.
+	MaxDelay time.Duration
+}
+
+// retry runs an operation until it succeeds, fails with an error that is not
+// transient, or exhausts the attempts of the policy. Writes must run in a
+// transaction or be idempotent, as an attempt failing on a connection reset
+// may have been applied.
+//
+// Returns:
+// - The error of the last attempt.
+func (bc *BaseController) retry(operation func() error) error {
+	policy := bc.Retry
+
+	for attempt := 1; ; attempt++ {
+		err := operation()
+		if err == nil || attempt >= policy.MaxAttempts || !IsTransientError(err) {
+			return err
+		}
+
+		delay := min(policy.BaseDelay<<(attempt-1), policy.MaxDelay)
+		if delay > 0 {
+			// Full jitter, so the transactions that deadlocked don't collide again
+			delay = time.Duration(rand.Int64N(int64(delay)) + 1)
+		}
+
+		log.Printf("Transient database error, retrying in %s (attempt %d/%d): %v", delay, attempt+1, policy.MaxAttempts, err)
+		time.Sleep(delay)
+	}
+}
+
+// IsTransientError reports whether an error is likely to go away when the
+// operation is run again: a deadlock, a lock wait timeout or a broken connection.
+func IsTransientError(err error) bool {
+	var mysqlErr *gomysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return mysqlErr.Number == mysqlDeadlock || mysqlErr.Number == mysqlLockWaitTimeout
+	}
+
+	return errors.Is(err, gomysql.ErrInvalidConn) || errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, syscall.ECONNRESET)
+}
@@ -0,0 +1,98 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/r4ulcl/api_template/utils"
+	"github.com/r4ulcl/api_template/utils/models"
+	"gorm.io/gorm/logger"
+)
+
+// LoadRuntimeConfig applies the settings overriding the runtime parameters.
+func (bc *BaseController) LoadRuntimeConfig(rc *utils.RuntimeConfig) error {
+	var settings []models.Setting
+	if err := bc.DB.Where(map[string]interface{}{"key": utils.RuntimeKeys}).Find(&settings).Error; err != nil {
+		return err
+	}
+
+	values := make(map[string]json.RawMessage, len(settings))
+	for _, setting := range settings {
+		values[setting.Key] = setting.Value
+	}
+
+	rc.Apply(values)
+
+	return nil
+}
+
+// WatchRuntimeConfig reloads the runtime parameters from the settings every
+// interval until the context is cancelled.
+func (bc *BaseController) WatchRuntimeConfig(ctx context.Context, rc *utils.RuntimeConfig, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := bc.LoadRuntimeConfig(rc); err != nil {
+				log.Println("Error reloading runtime config:", err)
+			}
+		}
+	}
+}
+
+// runtimeLogger is a GORM logger whose level follows the log_level runtime parameter.
+type runtimeLogger struct {
+	rc *utils.RuntimeConfig
+}
+
+// NewRuntimeLogger returns a GORM logger whose level can be changed at runtime.
+func NewRuntimeLogger(rc *utils.RuntimeConfig) logger.Interface {
+	return runtimeLogger{rc: rc}
+}
+
+// LogMode returns a logger with a fixed level, as used by db.Debug().
+func (rl runtimeLogger) LogMode(level logger.LogLevel) logger.Interface {
+	return logger.Default.LogMode(level)
+}
+
+// Info logs an informational message.
+func (rl runtimeLogger) Info(ctx context.Context, msg string, data ...interface{}) {
+	rl.current().Info(ctx, msg, data...)
+}
+
+// Warn logs a warning.
+func (rl runtimeLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	rl.current().Warn(ctx, msg, data...)
+}
+
+// Error logs an error.
+func (rl runtimeLogger) Error(ctx context.Context, msg string, data ...interface{}) {
+	rl.current().Error(ctx, msg, data...)
+}
+
+// Trace logs a SQL statement.
+func (rl runtimeLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	rl.current().Trace(ctx, begin, fc, err)
+}
+
+// current returns the default logger with the current level.
+func (rl runtimeLogger) current() logger.Interface {
+	levels := map[string]logger.LogLevel{
+		"error": logger.Error,
+		"warn":  logger.Warn,
+		"info":  logger.Info,
+	}
+
+	level, ok := levels[rl.rc.LogLevel()]
+	if !ok {
+		level = logger.Silent
+	}
+
+	return logger.Default.LogMode(level)
+}
@@ -0,0 +1,90 @@
+package database
+
+import (
+	"errors"
+
+	"github.com/r4ulcl/api_template/utils/models"
+	"gorm.io/gorm"
+)
+
+// ErrViewNotFound is returned when a saved query does not exist or is not
+// visible to the requesting user.
+var ErrViewNotFound = errors.New("view not found")
+
+// SaveQuery creates a saved query or updates the existing one with the same
+// owner and name.
+//
+// Parameters:
+// - query: A pointer to the saved query. On success it holds the stored record.
+//
+// Returns:
+// - An error if the record cannot be stored.
+func (bc *BaseController) SaveQuery(query *models.SavedQuery) error {
+	var existing models.SavedQuery
+
+	err := bc.DB.Where("owner = ? AND name = ?", query.Owner, query.Name).First(&existing).Error
+
+	switch {
+	case err == nil:
+		query.ID = existing.ID
+		query.CreatedAt = existing.CreatedAt
+
+		return bc.DB.Save(query).Error
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return bc.DB.Create(query).Error
+	default:
+		return err
+	}
+}
+
+// GetSavedQuery retrieves a view by name for a user.
+//
+// The user's own view takes precedence over a shared view with the same name.
+//
+// Returns:
+// - ErrViewNotFound if no matching view is visible to the user.
+func (bc *BaseController) GetSavedQuery(username, name string) (models.SavedQuery, error) {
+	var query models.SavedQuery
+
+	err := bc.DB.Where("name = ? AND owner = ?", name, username).First(&query).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = bc.DB.Where("name = ? AND shared = ?", name, true).First(&query).Error
+	}
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return query, ErrViewNotFound
+	}
+
+	return query, err
+}
+
+// ListSavedQueries returns the views visible to a user, optionally limited to one resource.
+func (bc *BaseController) ListSavedQueries(username, resource string) ([]models.SavedQuery, error) {
+	queries := []models.SavedQuery{}
+
+	tx := bc.DB.Where("owner = ? OR shared = ?", username, true)
+	if resource != "" {
+		tx = tx.Where("resource = ?", resource)
+	}
+
+	err := tx.Order("name").Find(&queries).Error
+
+	return queries, err
+}
+
+// DeleteSavedQuery removes a view owned by the given user.
+//
+// Returns:
+// - ErrViewNotFound if the user does not own a view with that name.
+func (bc *BaseController) DeleteSavedQuery(username, name string) error {
+	res := bc.DB.Where("owner = ? AND name = ?", username, name).Delete(&models.SavedQuery{})
+	if res.Error != nil {
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return ErrViewNotFound
+	}
+
+	return nil
+}
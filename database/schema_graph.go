@@ -0,0 +1,218 @@
+package database
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/r4ulcl/api_template/utils/models"
+	"gorm.io/gorm/schema"
+)
+
+// foreignKeyColumn is a column of a foreign key read from information_schema.
+type foreignKeyColumn struct {
+	TableName            string
+	ConstraintName       string
+	ColumnName           string
+	ReferencedTableName  string
+	ReferencedColumnName string
+}
+
+// SchemaGraph returns the tables of the migrated models and their
+// relationships, declared by the GORM tags of the models (belongs-to, has-one,
+// has-many and many-to-many relations). On MySQL, the foreign keys of
+// information_schema are added, flagging the relationships enforced by the
+// database and the ones only it knows about.
+//
+// Returns:
+// - The graph, with the nodes sorted by table and the edges by referencing table.
+// - An error if a model can't be parsed or information_schema can't be read.
+func (bc *BaseController) SchemaGraph() (models.SchemaGraph, error) {
+	graph := models.SchemaGraph{Nodes: []models.SchemaNode{}, Edges: []models.SchemaEdge{}}
+	nodes := map[string]*models.SchemaNode{}
+	edges := map[string]*models.SchemaEdge{}
+
+	addEdge := func(edge models.SchemaEdge) {
+		key := edge.From + "|" + edge.To + "|" + strings.Join(edge.Columns, ",")
+		if existing, ok := edges[key]; ok {
+			if existing.Source != edge.Source {
+				existing.Source = models.SchemaSourceBoth
+			}
+
+			return
+		}
+
+		edges[key] = &edge
+	}
+
+	for _, model := range migratedModels {
+		sch, err := bc.modelSchema(model)
+		if err != nil {
+			return graph, err
+		}
+
+		nodes[sch.Table] = &models.SchemaNode{ID: sch.Table, Model: sch.Name, Source: models.SchemaSourceModel,
+			Columns: schemaColumns(sch)}
+
+		for _, relation := range sch.Relationships.Relations {
+			for _, edge := range relationEdges(sch, relation) {
+				addEdge(edge)
+			}
+		}
+	}
+
+	if bc.DB.Dialector.Name() == "mysql" {
+		var columns []foreignKeyColumn
+
+		err := bc.DB.Raw("SELECT TABLE_NAME AS table_name, CONSTRAINT_NAME AS constraint_name, " +
+			"COLUMN_NAME AS column_name, REFERENCED_TABLE_NAME AS referenced_table_name, " +
+			"REFERENCED_COLUMN_NAME AS referenced_column_name FROM information_schema.KEY_COLUMN_USAGE " +
+			"WHERE TABLE_SCHEMA = DATABASE() AND REFERENCED_TABLE_NAME IS NOT NULL " +
+			"ORDER BY TABLE_NAME, CONSTRAINT_NAME, ORDINAL_POSITION").Scan(&columns).Error
+		if err != nil {
+			return graph, err
+		}
+
+		for _, edge := range foreignKeyEdges(columns) {
+			for _, table := range []string{edge.From, edge.To} {
+				if _, ok := nodes[table]; !ok {
+					nodes[table] = &models.SchemaNode{ID: table, Source: models.SchemaSourceDatabase,
+						Columns: []models.SchemaColumn{}}
+				}
+			}
+
+			addEdge(edge)
+		}
+	}
+
+	for _, edge := range edges {
+		graph.Edges = append(graph.Edges, *edge)
+
+		// Flag the foreign key columns on their node
+		if node, ok := nodes[edge.From]; ok {
+			for i := range node.Columns {
+				if slices.Contains(edge.Columns, node.Columns[i].Name) {
+					node.Columns[i].ForeignKey = true
+				}
+			}
+		}
+	}
+
+	for _, node := range nodes {
+		graph.Nodes = append(graph.Nodes, *node)
+	}
+
+	slices.SortFunc(graph.Nodes, func(a, b models.SchemaNode) int { return strings.Compare(a.ID, b.ID) })
+	slices.SortFunc(graph.Edges, func(a, b models.SchemaEdge) int {
+		return strings.Compare(a.From+"|"+a.To+"|"+strings.Join(a.Columns, ","),
+			b.From+"|"+b.To+"|"+strings.Join(b.Columns, ","))
+	})
+
+	return graph, nil
+}
+
+// schemaColumns returns the columns of a model, in order.
+func schemaColumns(sch *schema.Schema) []models.SchemaColumn {
+	columns := []models.SchemaColumn{}
+
+	for _, field := range sch.Fields {
+		if field.DBName == "" {
+			continue
+		}
+
+		columnType := string(field.DataType)
+		if declared, ok := field.TagSettings["TYPE"]; ok {
+			columnType = declared
+		} else if columnType == "" {
+			columnType = field.FieldType.String()
+		}
+
+		jsonName := strings.Split(field.Tag.Get("json"), ",")[0]
+		if jsonName == "-" {
+			jsonName = ""
+		}
+
+		columns = append(columns, models.SchemaColumn{
+			Name:       field.DBName,
+			Field:      jsonName,
+			Type:       columnType,
+			PrimaryKey: field.PrimaryKey,
+			Unique:     field.Unique,
+		})
+	}
+
+	return columns
+}
+
+// relationEdges returns the edges of a GORM relation of a model, from the
+// table holding the foreign key to the table it references. Many-to-many
+// relations have an edge from their join table to each side.
+func relationEdges(sch *schema.Schema, relation *schema.Relationship) []models.SchemaEdge {
+	switch relation.Type {
+	case schema.BelongsTo, schema.HasOne, schema.HasMany:
+		edge := models.SchemaEdge{Cardinality: "many-to-one", Source: models.SchemaSourceModel}
+
+		// Belongs-to relations are declared on the referencing model, the others on the referenced one
+		edge.From, edge.To = sch.Table, relation.FieldSchema.Table
+		if relation.Type != schema.BelongsTo {
+			edge.From, edge.To = relation.FieldSchema.Table, sch.Table
+		}
+
+		if relation.Type == schema.HasOne {
+			edge.Cardinality = "one-to-one"
+		}
+
+		for _, ref := range relation.References {
+			if ref.PrimaryKey != nil && ref.ForeignKey != nil {
+				edge.Columns = append(edge.Columns, ref.ForeignKey.DBName)
+				edge.References = append(edge.References, ref.PrimaryKey.DBName)
+			}
+		}
+
+		if len(edge.Columns) == 0 {
+			return nil
+		}
+
+		return []models.SchemaEdge{edge}
+	case schema.Many2Many:
+		own := models.SchemaEdge{From: relation.JoinTable.Table, To: sch.Table, Cardinality: "many-to-one",
+			Source: models.SchemaSourceModel}
+		other := models.SchemaEdge{From: relation.JoinTable.Table, To: relation.FieldSchema.Table,
+			Cardinality: "many-to-one", Source: models.SchemaSourceModel}
+
+		for _, ref := range relation.References {
+			if ref.PrimaryKey == nil || ref.ForeignKey == nil {
+				continue
+			}
+
+			edge := &other
+			if ref.OwnPrimaryKey {
+				edge = &own
+			}
+
+			edge.Columns = append(edge.Columns, ref.ForeignKey.DBName)
+			edge.References = append(edge.References, ref.PrimaryKey.DBName)
+		}
+
+		return []models.SchemaEdge{own, other}
+	}
+
+	return nil
+}
+
+// foreignKeyEdges groups the columns of the foreign keys of information_schema into edges.
+func foreignKeyEdges(columns []foreignKeyColumn) []models.SchemaEdge {
+	edges := []models.SchemaEdge{}
+
+	for i, column := range columns {
+		if i == 0 || column.TableName != columns[i-1].TableName || column.ConstraintName != columns[i-1].ConstraintName {
+			edges = append(edges, models.SchemaEdge{From: column.TableName, To: column.ReferencedTableName,
+				Cardinality: "many-to-one", Source: models.SchemaSourceDatabase})
+		}
+
+		edge := &edges[len(edges)-1]
+		edge.Columns = append(edge.Columns, column.ColumnName)
+		edge.References = append(edge.References, column.ReferencedColumnName)
+	}
+
+	return edges
+}
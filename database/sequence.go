@@ -0,0 +1,182 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/r4ulcl/api_template/utils"
+	"github.com/r4ulcl/api_template/utils/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// ErrInvalidSequence is returned when a sequence template has no {seq} placeholder.
+var ErrInvalidSequence = errors.New("invalid sequence template")
+
+// sequencePlaceholder matches the placeholders of a sequence template.
+var sequencePlaceholder = regexp.MustCompile(`\{(year|month|day|seq(?::(\d+))?)\}`)
+
+// NextSequence increments a named sequence and returns its new value, starting at 1.
+//
+// Concurrent calls never return the same value: the increment locks the row of
+// the sequence until the transaction ends.
+func (bc *BaseController) NextSequence(name string) (int64, error) {
+	var value int64
+
+	err := bc.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+
+		value, err = nextSequence(tx, name)
+
+		return err
+	})
+
+	return value, err
+}
+
+// nextSequence increments a named sequence within a transaction, so the number
+// is only used if the transaction commits.
+func nextSequence(tx *gorm.DB, name string) (int64, error) {
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.Sequence{Name: name}).Error; err != nil {
+		return 0, err
+	}
+
+	if err := tx.Model(&models.Sequence{}).Where("name = ?", name).
+		Updates(map[string]interface{}{"value": gorm.Expr("value + 1"), "updated_at": time.Now()}).Error; err != nil {
+		return 0, err
+	}
+
+	var sequence models.Sequence
+	if err := tx.Where("name = ?", name).First(&sequence).Error; err != nil {
+		return 0, err
+	}
+
+	return sequence.Value, nil
+}
+
+// FormatSequence expands a reference number template with a date and a number.
+//
+// The placeholders are {year}, {month} and {day} of the date, and {seq} for the
+// number, or {seq:N} to pad it with zeros to N digits. For example
+// "INV-{year}-{seq:4}" gives "INV-2024-0001".
+//
+// Returns:
+// - The sequence name prefix (the template with the dates expanded), so each
+// period (e.g. each year) gets its own sequence.
+// - The reference number.
+// - ErrInvalidSequence if the template has no {seq} placeholder.
+func FormatSequence(template string, date time.Time, number int64) (string, string, error) {
+	hasSeq := false
+	expand := func(withNumber bool) string {
+		return sequencePlaceholder.ReplaceAllStringFunc(template, func(placeholder string) string {
+			match := sequencePlaceholder.FindStringSubmatch(placeholder)
+
+			switch match[1] {
+			case "year":
+				return fmt.Sprintf("%04d", date.Year())
+			case "month":
+				return fmt.Sprintf("%02d", int(date.Month()))
+			case "day":
+				return fmt.Sprintf("%02d", date.Day())
+			}
+
+			hasSeq = true
+
+			if !withNumber {
+				return placeholder
+			}
+
+			width, _ := strconv.Atoi(match[2])
+
+			return fmt.Sprintf("%0*d", width, number)
+		})
+	}
+
+	name, reference := expand(false), expand(true)
+	if !hasSeq {
+		return "", "", fmt.Errorf("%w: %q has no {seq}", ErrInvalidSequence, template)
+	}
+
+	return name, reference, nil
+}
+
+// sequenceFields returns the fields of a schema with a `populate:"sequence=..."` tag.
+func sequenceFields(sch *schema.Schema) []*schema.Field {
+	var fields []*schema.Field
+
+	for _, field := range sch.Fields {
+		if _, ok := utils.SequenceTemplate(field.StructField); ok && field.DBName != "" {
+			fields = append(fields, field)
+		}
+	}
+
+	return fields
+}
+
+// assignSequences sets the empty sequence fields of a new record to the next
+// reference number of their sequence, within the transaction inserting it.
+// The sequence of a field is named after its table, column and expanded template.
+func (bc *BaseController) assignSequences(tx *gorm.DB, model interface{}) error {
+	sch, err := bc.modelSchema(model)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	record := reflect.Indirect(reflect.ValueOf(model))
+	now := time.Now()
+
+	for _, field := range sequenceFields(sch) {
+		if _, zero := field.ValueOf(ctx, record); !zero {
+			continue
+		}
+
+		template, _ := utils.SequenceTemplate(field.StructField)
+
+		prefix, _, err := FormatSequence(template, now, 0)
+		if err != nil {
+			return err
+		}
+
+		number, err := nextSequence(tx, sch.Table+"."+field.DBName+":"+prefix)
+		if err != nil {
+			return err
+		}
+
+		_, reference, _ := FormatSequence(template, now, number)
+		if err := field.Set(ctx, record, reference); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// keepSequences copies the sequence fields of the stored record into an updated
+// one: a reference number never changes once assigned.
+func (bc *BaseController) keepSequences(model, existing interface{}) error {
+	sch, err := bc.modelSchema(model)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	record := reflect.Indirect(reflect.ValueOf(model))
+	stored := reflect.Indirect(reflect.ValueOf(existing))
+
+	for _, field := range sequenceFields(sch) {
+		value, _ := field.ValueOf(ctx, stored)
+		if err := field.Set(ctx, record, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,171 @@
+package database
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/r4ulcl/api_template/utils"
+	"github.com/r4ulcl/api_template/utils/models"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrServiceAccountNotFound is returned when a service account does not exist.
+	ErrServiceAccountNotFound = errors.New("service account not found")
+
+	// ErrInvalidClient is returned when client credentials are wrong or the account is disabled.
+	ErrInvalidClient = errors.New("invalid client credentials")
+)
+
+// ListServiceAccounts returns every service account ordered by name.
+func (bc *BaseController) ListServiceAccounts() ([]models.ServiceAccount, error) {
+	accounts := []models.ServiceAccount{}
+	err := bc.DB.Order("name").Find(&accounts).Error
+
+	return accounts, err
+}
+
+// GetServiceAccount retrieves a service account by client ID.
+//
+// Returns:
+// - ErrServiceAccountNotFound if no account has the client ID.
+func (bc *BaseController) GetServiceAccount(clientID string) (models.ServiceAccount, error) {
+	var account models.ServiceAccount
+
+	err := bc.DB.Where("client_id = ?", clientID).First(&account).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return account, ErrServiceAccountNotFound
+	}
+
+	return account, err
+}
+
+// CreateServiceAccount stores a new service account with a generated client ID and secret.
+//
+// Parameters:
+// - account: The account to create. Its ClientID and SecretHash are set.
+//
+// Returns:
+// - The plain client secret, which is only stored hashed.
+// - An error if the account cannot be stored.
+func (bc *BaseController) CreateServiceAccount(account *models.ServiceAccount) (string, error) {
+	id, err := randomHex(8)
+	if err != nil {
+		return "", err
+	}
+
+	secret, err := newClientSecret(account)
+	if err != nil {
+		return "", err
+	}
+
+	account.ClientID = "sa-" + id
+
+	return secret, bc.DB.Create(account).Error
+}
+
+// UpdateServiceAccount saves the name, description, role, scopes and state of a service account.
+//
+// Returns:
+// - ErrServiceAccountNotFound if the account does not exist.
+func (bc *BaseController) UpdateServiceAccount(account *models.ServiceAccount) error {
+	res := bc.DB.Model(account).
+		Select("name", "description", "role", "scopes", "disabled").
+		Updates(account)
+	if res.Error != nil {
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return ErrServiceAccountNotFound
+	}
+
+	return nil
+}
+
+// RotateServiceAccountSecret replaces the secret of a service account.
+//
+// Tokens already issued stay valid until they expire.
+//
+// Returns:
+// - The new plain client secret.
+// - ErrServiceAccountNotFound if the account does not exist.
+func (bc *BaseController) RotateServiceAccountSecret(clientID string) (models.ServiceAccount, string, error) {
+	account, err := bc.GetServiceAccount(clientID)
+	if err != nil {
+		return account, "", err
+	}
+
+	secret, err := newClientSecret(&account)
+	if err != nil {
+		return account, "", err
+	}
+
+	err = bc.DB.Model(&account).Update("secret_hash", account.SecretHash).Error
+
+	return account, secret, err
+}
+
+// DeleteServiceAccount removes a service account.
+//
+// Returns:
+// - ErrServiceAccountNotFound if no account has the client ID.
+func (bc *BaseController) DeleteServiceAccount(clientID string) error {
+	res := bc.DB.Where("client_id = ?", clientID).Delete(&models.ServiceAccount{})
+	if res.Error != nil {
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return ErrServiceAccountNotFound
+	}
+
+	return nil
+}
+
+// AuthenticateServiceAccount checks client credentials and records the use of the account.
+//
+// Returns:
+// - The authenticated account.
+// - ErrInvalidClient if the account does not exist, is disabled, or the secret is wrong.
+func (bc *BaseController) AuthenticateServiceAccount(clientID, secret string) (models.ServiceAccount, error) {
+	account, err := bc.GetServiceAccount(clientID)
+	if errors.Is(err, ErrServiceAccountNotFound) {
+		return account, ErrInvalidClient
+	} else if err != nil {
+		return account, err
+	}
+
+	if account.Disabled || utils.CheckPassword(account.SecretHash, secret) != nil {
+		return account, ErrInvalidClient
+	}
+
+	now := time.Now()
+	account.LastUsedAt = &now
+
+	return account, bc.DB.Model(&account).UpdateColumn("last_used_at", now).Error
+}
+
+// newClientSecret generates a client secret and stores its hash in the account.
+func newClientSecret(account *models.ServiceAccount) (string, error) {
+	secret, err := randomHex(32)
+	if err != nil {
+		return "", err
+	}
+
+	account.SecretHash, err = utils.HashPassword(secret)
+
+	return secret, err
+}
+
+// randomHex returns n random bytes encoded as hex.
+func randomHex(n int) (string, error) {
+	raw := make([]byte, n)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(raw), nil
+}
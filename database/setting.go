@@ -0,0 +1,123 @@
+package database
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/r4ulcl/api_template/utils/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrSettingNotFound is returned when a setting does not exist.
+var ErrSettingNotFound = errors.New("setting not found")
+
+// ListSettings returns every setting ordered by key.
+func (bc *BaseController) ListSettings() ([]models.Setting, error) {
+	settings := []models.Setting{}
+	err := bc.DB.Order(clause.OrderByColumn{Column: clause.Column{Name: "key"}}).Find(&settings).Error
+
+	return settings, err
+}
+
+// GetSetting returns a setting by key.
+//
+// Returns:
+// - ErrSettingNotFound if no setting has the given key.
+func (bc *BaseController) GetSetting(key string) (models.Setting, error) {
+	var setting models.Setting
+
+	err := bc.DB.Where(&models.Setting{Key: key}).First(&setting).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return setting, ErrSettingNotFound
+	}
+
+	return setting, err
+}
+
+// SaveSetting creates or replaces a setting and records the change in the
+// audit log, in a single transaction.
+//
+// Parameters:
+// - setting: The setting to store. Its value must match its type.
+// - actor: The username of the admin making the change.
+// - clientIP: The IP address the change was requested from.
+func (bc *BaseController) SaveSetting(setting *models.Setting, actor, clientIP string) error {
+	if err := setting.Validate(); err != nil {
+		return err
+	}
+
+	setting.UpdatedBy = actor
+
+	return bc.DB.Transaction(func(tx *gorm.DB) error {
+		var existing models.Setting
+
+		err := tx.Where(&models.Setting{Key: setting.Key}).First(&existing).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		if err := tx.Save(setting).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&models.SettingChange{
+			Key:       setting.Key,
+			OldValue:  existing.Value,
+			NewValue:  setting.Value,
+			ChangedBy: actor,
+			ClientIP:  clientIP,
+		}).Error
+	})
+}
+
+// DeleteSetting removes a setting and records the change in the audit log.
+//
+// Returns:
+// - ErrSettingNotFound if no setting has the given key.
+func (bc *BaseController) DeleteSetting(key, actor, clientIP string) error {
+	return bc.DB.Transaction(func(tx *gorm.DB) error {
+		var existing models.Setting
+
+		err := tx.Where(&models.Setting{Key: key}).First(&existing).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrSettingNotFound
+		} else if err != nil {
+			return err
+		}
+
+		if err := tx.Delete(&existing).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&models.SettingChange{
+			Key:       key,
+			OldValue:  existing.Value,
+			ChangedBy: actor,
+			ClientIP:  clientIP,
+		}).Error
+	})
+}
+
+// ListSettingChanges returns the audit log of a setting, newest first.
+func (bc *BaseController) ListSettingChanges(key string) ([]models.SettingChange, error) {
+	changes := []models.SettingChange{}
+	err := bc.DB.Where(&models.SettingChange{Key: key}).Order("id DESC").Find(&changes).Error
+
+	return changes, err
+}
+
+// SettingValue decodes the value of a setting into target, so application
+// code can read runtime-tunable parameters.
+//
+// Returns:
+// - ErrSettingNotFound if no setting has the given key.
+// - An error if the value cannot be decoded into target.
+func (bc *BaseController) SettingValue(key string, target interface{}) error {
+	setting, err := bc.GetSetting(key)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(setting.Value, target)
+}
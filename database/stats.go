@@ -0,0 +1,174 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+// ErrUnknownTable is returned when statistics are requested for a table that
+// does not exist in the database.
+var ErrUnknownTable = errors.New("unknown table")
+
+// tableSize holds the sizes reported by MySQL's information_schema.
+type tableSize struct {
+	TableName   string
+	DataLength  int64
+	IndexLength int64
+}
+
+// GetDBStats returns the row count and size of every table in the database.
+//
+// Sizes are read from information_schema and are only available on MySQL. The
+// prepared statement cache statistics are included when DB_PREPARE_STMT is enabled,
+// and the query semaphore statistics when DB_MAX_CONCURRENT_QUERIES is set.
+//
+// Returns:
+// - The database statistics.
+// - An error if any query fails.
+func (bc *BaseController) GetDBStats() (models.DBStats, error) {
+	stats := models.DBStats{Tables: []models.TableStats{}}
+
+	tables, err := bc.DB.Migrator().GetTables()
+	if err != nil {
+		return stats, err
+	}
+
+	slices.Sort(tables)
+
+	sizes := map[string]tableSize{}
+
+	if bc.DB.Dialector.Name() == "mysql" {
+		var rows []tableSize
+
+		err := bc.DB.Raw("SELECT TABLE_NAME AS table_name, DATA_LENGTH AS data_length, " +
+			"INDEX_LENGTH AS index_length FROM information_schema.TABLES WHERE TABLE_SCHEMA = DATABASE()").
+			Scan(&rows).Error
+		if err != nil {
+			return stats, err
+		}
+
+		for _, row := range rows {
+			sizes[row.TableName] = row
+		}
+	}
+
+	for _, table := range tables {
+		var count int64
+		if err := bc.DB.Table(table).Count(&count).Error; err != nil {
+			return stats, err
+		}
+
+		tableStats := models.TableStats{
+			Table:      table,
+			Rows:       count,
+			DataBytes:  sizes[table].DataLength,
+			IndexBytes: sizes[table].IndexLength,
+		}
+
+		stats.Tables = append(stats.Tables, tableStats)
+		stats.TotalRows += tableStats.Rows
+		stats.TotalBytes += tableStats.DataBytes + tableStats.IndexBytes
+	}
+
+	if cache, ok := bc.DB.ConnPool.(*StatementCache); ok {
+		statements := cache.Stats()
+		stats.Statements = &statements
+	}
+
+	if semaphore := bc.QuerySemaphore(); semaphore != nil {
+		queries := semaphore.Stats()
+		stats.Queries = &queries
+	}
+
+	return stats, nil
+}
+
+// GetColumnStats computes per-column statistics of a table.
+//
+// Cardinality, null counts and min/max are computed over the whole table, while
+// the most frequent values are computed over the first sampleSize rows.
+//
+// Parameters:
+// - table: The name of the table. It must exist in the database.
+// - sampleSize: The maximum number of rows used to compute the top values.
+// - topN: The number of most frequent values returned per column.
+//
+// Returns:
+// - ErrUnknownTable if the table does not exist.
+// - An error if any query fails.
+func (bc *BaseController) GetColumnStats(table string, sampleSize, topN int) (models.TableColumnStats, error) {
+	result := models.TableColumnStats{Table: table, SampleSize: sampleSize, Columns: []models.ColumnStats{}}
+
+	tables, err := bc.DB.Migrator().GetTables()
+	if err != nil {
+		return result, err
+	}
+
+	// Only tables that exist are accepted, so the name is safe to quote into SQL
+	if !slices.Contains(tables, table) {
+		return result, fmt.Errorf("%w: %s", ErrUnknownTable, table)
+	}
+
+	columnTypes, err := bc.DB.Migrator().ColumnTypes(table)
+	if err != nil {
+		return result, err
+	}
+
+	quotedTable := bc.DB.Statement.Quote(table)
+
+	for _, columnType := range columnTypes {
+		column := columnType.Name()
+		quotedColumn := bc.DB.Statement.Quote(column)
+
+		columnStats := models.ColumnStats{
+			Column:    column,
+			Type:      columnType.DatabaseTypeName(),
+			TopValues: []models.ValueCount{},
+		}
+
+		row := bc.DB.Raw(fmt.Sprintf(
+			"SELECT COUNT(DISTINCT %[1]s), SUM(CASE WHEN %[1]s IS NULL THEN 1 ELSE 0 END), MIN(%[1]s), MAX(%[1]s) FROM %[2]s",
+			quotedColumn, quotedTable)).Row()
+
+		var nulls *int64
+		if err := row.Scan(&columnStats.Cardinality, &nulls, &columnStats.Min, &columnStats.Max); err != nil {
+			return result, err
+		}
+
+		if nulls != nil {
+			columnStats.Nulls = *nulls
+		}
+
+		rows, err := bc.DB.Raw(fmt.Sprintf(
+			"SELECT %[1]s, COUNT(*) AS occurrences FROM (SELECT %[1]s FROM %[2]s LIMIT ?) AS sample "+
+				"GROUP BY %[1]s ORDER BY occurrences DESC LIMIT ?",
+			quotedColumn, quotedTable), sampleSize, topN).Rows()
+		if err != nil {
+			return result, err
+		}
+
+		for rows.Next() {
+			var valueCount models.ValueCount
+			if err := rows.Scan(&valueCount.Value, &valueCount.Count); err != nil {
+				rows.Close()
+
+				return result, err
+			}
+
+			columnStats.TopValues = append(columnStats.TopValues, valueCount)
+		}
+
+		rows.Close()
+
+		if err := rows.Err(); err != nil {
+			return result, err
+		}
+
+		result.Columns = append(result.Columns, columnStats)
+	}
+
+	return result, nil
+}
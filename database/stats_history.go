@@ -0,0 +1,96 @@
+package database
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+// SnapshotStats stores the current statistics of every table in the stats_history table.
+//
+// Returns:
+// - An error if the statistics cannot be computed or stored.
+func (bc *BaseController) SnapshotStats() error {
+	stats, err := bc.GetDBStats()
+	if err != nil {
+		return err
+	}
+
+	if len(stats.Tables) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	snapshots := make([]models.StatsSnapshot, len(stats.Tables))
+
+	for i, table := range stats.Tables {
+		snapshots[i] = models.StatsSnapshot{
+			Table:      table.Table,
+			Rows:       table.Rows,
+			DataBytes:  table.DataBytes,
+			IndexBytes: table.IndexBytes,
+			TakenAt:    now,
+		}
+	}
+
+	return bc.DB.Create(&snapshots).Error
+}
+
+// GetStatsHistory returns the stored snapshots, oldest first.
+//
+// Parameters:
+// - table: The table whose snapshots are returned, or empty for every table.
+// - from: The earliest snapshot time, or the zero time for no lower bound.
+// - to: The latest snapshot time, or the zero time for no upper bound.
+//
+// Returns:
+// - The matching snapshots.
+// - An error if the query fails.
+func (bc *BaseController) GetStatsHistory(table string, from, to time.Time) ([]models.StatsSnapshot, error) {
+	snapshots := []models.StatsSnapshot{}
+	tx := bc.DB.Model(&models.StatsSnapshot{})
+
+	if table != "" {
+		tx = tx.Where("table_name = ?", table)
+	}
+
+	if !from.IsZero() {
+		tx = tx.Where("taken_at >= ?", from)
+	}
+
+	if !to.IsZero() {
+		tx = tx.Where("taken_at <= ?", to)
+	}
+
+	err := tx.Order("taken_at, id").Find(&snapshots).Error
+
+	return snapshots, err
+}
+
+// WatchStatsSnapshots stores a snapshot of the table statistics every interval
+// until the context is cancelled, then runs the growth monitor, if any.
+func (bc *BaseController) WatchStatsSnapshots(ctx context.Context, interval time.Duration, growth *GrowthMonitor) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := bc.SnapshotStats(); err != nil {
+				log.Println("Error storing stats snapshot:", err)
+
+				continue
+			}
+
+			if growth != nil {
+				if err := growth.Check(); err != nil {
+					log.Println("Error checking table growth:", err)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package database
+
+import (
+	"github.com/r4ulcl/api_template/utils/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AddUsage adds the counts metered by a replica to the stored daily rollups,
+// creating the missing ones.
+func (bc *BaseController) AddUsage(rollups []models.UsageRollup) error {
+	return bc.DB.Transaction(func(tx *gorm.DB) error {
+		for _, rollup := range rollups {
+			upsert := clause.OnConflict{
+				Columns: []clause.Column{{Name: "day"}, {Name: "principal"}, {Name: "account_type"}},
+				DoUpdates: clause.Assignments(map[string]interface{}{
+					"requests":      gorm.Expr("requests + ?", rollup.Requests),
+					"client_errors": gorm.Expr("client_errors + ?", rollup.ClientErrors),
+					"server_errors": gorm.Expr("server_errors + ?", rollup.ServerErrors),
+					"bytes_in":      gorm.Expr("bytes_in + ?", rollup.BytesIn),
+					"bytes_out":     gorm.Expr("bytes_out + ?", rollup.BytesOut),
+				}),
+			}
+
+			if err := tx.Clauses(upsert).Create(&rollup).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// ListUsage returns the daily usage rollups of a range of days, by day and principal.
+//
+// Parameters:
+// - from, to: The first and last days (YYYY-MM-DD), included.
+// - principal: Limits the rollups to a user or service account; empty returns every principal.
+// - accountType: Limits the rollups to "user" or "service"; empty returns both.
+func (bc *BaseController) ListUsage(from, to, principal string, accountType models.AccountType,
+) ([]models.UsageRollup, error) {
+	query := bc.DB.Where("day >= ? AND day <= ?", from, to)
+
+	if principal != "" {
+		query = query.Where("principal = ?", principal)
+	}
+
+	if accountType != "" {
+		query = query.Where("account_type = ?", accountType)
+	}
+
+	rollups := []models.UsageRollup{}
+	if err := query.Order("day, principal, account_type").Find(&rollups).Error; err != nil {
+		return nil, err
+	}
+
+	for i := range rollups {
+		rollups[i].ComputeErrorRate()
+	}
+
+	return rollups, nil
+}
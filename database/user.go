@@ -0,0 +1,159 @@
+package database
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/r4ulcl/api_template/utils"
+	"github.com/r4ulcl/api_template/utils/models"
+	"gorm.io/gorm"
+)
+
+// ErrAdminExists is returned when creating the first admin of a database that already has one.
+var ErrAdminExists = errors.New("an admin already exists")
+
+// normalizeUsernames lowercases the usernames stored before usernames became
+// case-insensitive.
+//
+// A user is left unchanged, and a warning is logged, if its lowercased
+// username already exists, so an admin can resolve the conflict by hand.
+func normalizeUsernames(db *gorm.DB) error {
+	var usernames []string
+	if err := db.Model(&models.User{}).Pluck("username", &usernames).Error; err != nil {
+		return err
+	}
+
+	existing := make(map[string]bool, len(usernames))
+	for _, username := range usernames {
+		existing[username] = true
+	}
+
+	for _, username := range usernames {
+		normalized := utils.NormalizeUsername(username)
+		if normalized == username {
+			continue
+		}
+
+		if existing[normalized] {
+			log.Printf("Cannot normalize username %q: %q already exists", username, normalized)
+
+			continue
+		}
+
+		err := db.Model(&models.User{}).Where("username = ?", username).Update("username", normalized).Error
+		if err != nil {
+			return err
+		}
+
+		existing[normalized] = true
+
+		log.Printf("Normalized username %q to %q", username, normalized)
+	}
+
+	return nil
+}
+
+// ApplyBootstrapUsers creates the users of the BOOTSTRAP_USERS file, and
+// resets the role, password and email of the existing ones to those of the
+// file. Users already matching the file are left untouched, so it can be
+// applied on every startup. Emails are stored as verified.
+//
+// Parameters:
+// - users: The users, with their password already hashed.
+//
+// Returns:
+// - The number of users created and updated.
+func (bc *BaseController) ApplyBootstrapUsers(users []models.User) (int, int, error) {
+	created, updated := 0, 0
+
+	err := bc.DB.Transaction(func(tx *gorm.DB) error {
+		for _, user := range users {
+			var existing models.User
+
+			err := tx.Where("username = ?", user.Username).Limit(1).Find(&existing).Error
+			if err != nil {
+				return err
+			}
+
+			if existing.Username != "" && existing.Role == user.Role && existing.Password == user.Password &&
+				sameEmail(existing.Email, user.Email) {
+				continue
+			}
+
+			now := time.Now()
+			if user.Email != nil {
+				user.EmailVerifiedAt = &now
+			}
+
+			if existing.Username == "" {
+				if err := tx.Create(&user).Error; err != nil {
+					return err
+				}
+
+				created++
+
+				continue
+			}
+
+			err = tx.Model(&models.User{}).Where("username = ?", user.Username).Updates(map[string]interface{}{
+				"role":              user.Role,
+				"password":          user.Password,
+				"email":             user.Email,
+				"email_verified_at": user.EmailVerifiedAt,
+			}).Error
+			if err != nil {
+				return err
+			}
+
+			updated++
+		}
+
+		return nil
+	})
+
+	return created, updated, err
+}
+
+// CountAdmins returns the number of users with the admin role.
+func (bc *BaseController) CountAdmins() (int64, error) {
+	var count int64
+	err := bc.DB.Model(&models.User{}).Where("role = ?", models.AdminRole).Count(&count).Error
+
+	return count, err
+}
+
+// CreateFirstAdmin creates an admin only if there is no admin yet. Users
+// without the admin role (e.g. self-registered ones) don't prevent it.
+//
+// The user is inserted, never overwritten, so a taken username fails with a
+// duplicate key error (see IsDuplicateKeyError) instead of replacing the account.
+//
+// Parameters:
+// - user: The admin, with its password already hashed.
+//
+// Returns:
+// - ErrAdminExists if an admin already exists.
+func (bc *BaseController) CreateFirstAdmin(user *models.User) error {
+	return bc.DB.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.User{}).Where("role = ?", models.AdminRole).Count(&count).Error; err != nil {
+			return err
+		}
+
+		if count > 0 {
+			return ErrAdminExists
+		}
+
+		return tx.Create(user).Error
+	})
+}
+
+// sameEmail reports whether two optional email addresses are equal.
+func sameEmail(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return *a == *b
+}
@@ -0,0 +1,87 @@
+package database
+
+import (
+	"strings"
+	"time"
+
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+// UserSearch selects the users returned by SearchUsers. Zero fields don't filter.
+type UserSearch struct {
+	// Username matches the usernames against a pattern where "*" matches any characters (e.g. "al*").
+	Username string
+
+	// Email matches the email addresses against a pattern like Username.
+	Email string
+
+	// Role only returns the users with a role.
+	Role models.Role
+
+	// CreatedAfter only returns the users created at or after a date.
+	CreatedAfter time.Time
+
+	// CreatedBefore only returns the users created before a date.
+	CreatedBefore time.Time
+
+	// Offset skips the first matching users, ordered by username.
+	Offset int
+
+	// Limit caps the number of users returned.
+	Limit int
+}
+
+// SearchUsers returns the users matching a search, ordered by username, as
+// summaries without their password hash.
+//
+// Returns:
+// - The users of the requested page.
+// - The number of users matching the search.
+func (bc *BaseController) SearchUsers(search UserSearch) ([]models.UserSummary, int64, error) {
+	tx := bc.DB.Model(&models.User{})
+
+	if search.Username != "" {
+		tx = tx.Where("username LIKE ? ESCAPE '!'", wildcardPattern(strings.ToLower(search.Username)))
+	}
+
+	if search.Email != "" {
+		tx = tx.Where("email LIKE ? ESCAPE '!'", wildcardPattern(strings.ToLower(search.Email)))
+	}
+
+	if search.Role != "" {
+		tx = tx.Where("role = ?", search.Role)
+	}
+
+	if !search.CreatedAfter.IsZero() {
+		tx = tx.Where("created_at >= ?", search.CreatedAfter)
+	}
+
+	if !search.CreatedBefore.IsZero() {
+		tx = tx.Where("created_at < ?", search.CreatedBefore)
+	}
+
+	var total int64
+	if err := tx.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	users := []models.User{}
+	if err := tx.Order("username").Offset(search.Offset).Limit(search.Limit).Find(&users).Error; err != nil {
+		return nil, 0, err
+	}
+
+	summaries := make([]models.UserSummary, 0, len(users))
+	for _, user := range users {
+		summaries = append(summaries, models.NewUserSummary(user))
+	}
+
+	return summaries, total, nil
+}
+
+// wildcardPattern converts a pattern where "*" matches any characters to a
+// LIKE pattern escaped with "!", so "%" and "_" match themselves.
+func wildcardPattern(pattern string) string {
+	escaped := strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(pattern)
+
+	return strings.ReplaceAll(escaped, "*", "%")
+}
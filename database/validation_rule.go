@@ -0,0 +1,303 @@
+package database
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/r4ulcl/api_template/utils/models"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrValidationRuleNotFound is returned when a validation rule does not exist.
+	ErrValidationRuleNotFound = errors.New("validation rule not found")
+
+	// ErrValidationFailed is returned when a record breaks a validation rule.
+	ErrValidationFailed = errors.New("validation failed")
+)
+
+// ListValidationRules returns every validation rule ordered by ID.
+func (bc *BaseController) ListValidationRules() ([]models.ValidationRule, error) {
+	rules := []models.ValidationRule{}
+	err := bc.DB.Order("id").Find(&rules).Error
+
+	return rules, err
+}
+
+// GetValidationRule returns a validation rule by ID.
+//
+// Returns:
+// - ErrValidationRuleNotFound if no rule has the given ID.
+func (bc *BaseController) GetValidationRule(id uint) (models.ValidationRule, error) {
+	var rule models.ValidationRule
+
+	err := bc.DB.First(&rule, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return rule, ErrValidationRuleNotFound
+	}
+
+	return rule, err
+}
+
+// CreateValidationRule stores a new validation rule.
+func (bc *BaseController) CreateValidationRule(rule *models.ValidationRule) error {
+	rule.ID = 0
+
+	return bc.DB.Create(rule).Error
+}
+
+// UpdateValidationRule replaces an existing validation rule.
+//
+// Returns:
+// - ErrValidationRuleNotFound if no rule has the given ID.
+func (bc *BaseController) UpdateValidationRule(id uint, rule *models.ValidationRule) error {
+	var existing models.ValidationRule
+	if err := bc.DB.First(&existing, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrValidationRuleNotFound
+		}
+
+		return err
+	}
+
+	rule.ID = existing.ID
+	rule.CreatedAt = existing.CreatedAt
+
+	return bc.DB.Save(rule).Error
+}
+
+// DeleteValidationRule removes a validation rule.
+//
+// Returns:
+// - ErrValidationRuleNotFound if no rule has the given ID.
+func (bc *BaseController) DeleteValidationRule(id uint) error {
+	res := bc.DB.Delete(&models.ValidationRule{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return ErrValidationRuleNotFound
+	}
+
+	return nil
+}
+
+// compiledRule is an enabled validation rule with its params parsed once.
+type compiledRule struct {
+	rule    models.ValidationRule
+	pattern *regexp.Regexp
+	number  float64
+	values  []string
+}
+
+// ValidationRules keeps the enabled validation rules in memory, by resource, so
+// records can be checked on every create and update without querying the database.
+//
+// Call Reload after the rules change.
+type ValidationRules struct {
+	bc *BaseController
+
+	mu    sync.RWMutex
+	rules map[string][]compiledRule
+}
+
+// NewValidationRules creates an empty rule cache backed by the database.
+func NewValidationRules(bc *BaseController) *ValidationRules {
+	return &ValidationRules{bc: bc, rules: map[string][]compiledRule{}}
+}
+
+// Reload loads the enabled rules from the database. Rules with invalid params are skipped.
+func (vr *ValidationRules) Reload() error {
+	list, err := vr.bc.ListValidationRules()
+	if err != nil {
+		return err
+	}
+
+	rules := map[string][]compiledRule{}
+
+	for _, rule := range list {
+		if !rule.Enabled || rule.Validate() != nil {
+			continue
+		}
+
+		compiled := compiledRule{rule: rule}
+
+		switch rule.Rule {
+		case models.RuleRegex:
+			compiled.pattern = regexp.MustCompile(rule.Params)
+		case models.RuleMinLength, models.RuleMaxLength, models.RuleMin, models.RuleMax:
+			compiled.number, _ = strconv.ParseFloat(strings.TrimSpace(rule.Params), 64)
+		case models.RuleEnum:
+			for _, value := range strings.Split(rule.Params, ",") {
+				compiled.values = append(compiled.values, strings.TrimSpace(value))
+			}
+		}
+
+		rules[rule.Resource] = append(rules[rule.Resource], compiled)
+	}
+
+	vr.mu.Lock()
+	defer vr.mu.Unlock()
+
+	vr.rules = rules
+
+	return nil
+}
+
+// Validate checks a record against the enabled rules of its resource.
+// It is safe to call on a nil *ValidationRules.
+//
+// Parameters:
+// - resource: The name of the resource (e.g. "example1").
+// - model: A pointer to the struct being stored. Other values are ignored.
+//
+// Returns:
+// - ErrValidationFailed, with the messages of every broken rule.
+// - nil if the record is valid.
+func (vr *ValidationRules) Validate(resource string, model interface{}) error {
+	if vr == nil {
+		return nil
+	}
+
+	vr.mu.RLock()
+	rules := vr.rules[resource]
+	vr.mu.RUnlock()
+
+	val := reflect.ValueOf(model)
+	if len(rules) == 0 || val.Kind() != reflect.Ptr || val.IsNil() || val.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+
+	// Rules refer to the JSON names of the fields
+	record := map[string]interface{}{}
+
+	raw, err := json.Marshal(model)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(raw, &record); err != nil {
+		return err
+	}
+
+	var failures []string
+
+	for _, compiled := range rules {
+		if !compiled.check(record[compiled.rule.Field]) {
+			failures = append(failures, compiled.message())
+		}
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("%w: %s", ErrValidationFailed, strings.Join(failures, "; "))
+	}
+
+	return nil
+}
+
+// Describe adds the enabled rules of a resource to its JSON Schema, so clients
+// can check them before sending a record. It is safe to call on a nil *ValidationRules.
+//
+// Patterns are Go regular expressions, which match the JSON Schema (ECMA-262)
+// syntax for the common constructs.
+func (vr *ValidationRules) Describe(resource string, sch *models.JSONSchema) {
+	if vr == nil {
+		return
+	}
+
+	vr.mu.RLock()
+	rules := vr.rules[resource]
+	vr.mu.RUnlock()
+
+	for _, compiled := range rules {
+		property, ok := sch.Properties[compiled.rule.Field]
+		if !ok {
+			continue
+		}
+
+		number := compiled.number
+		length := int(number)
+
+		switch compiled.rule.Rule {
+		case models.RuleRequired:
+			if !slices.Contains(sch.Required, compiled.rule.Field) {
+				sch.Required = append(sch.Required, compiled.rule.Field)
+			}
+		case models.RuleRegex:
+			property.Pattern = compiled.rule.Params
+		case models.RuleMinLength:
+			property.MinLength = &length
+		case models.RuleMaxLength:
+			if property.MaxLength == nil || length < *property.MaxLength {
+				property.MaxLength = &length
+			}
+		case models.RuleMin:
+			property.Minimum = &number
+		case models.RuleMax:
+			property.Maximum = &number
+		case models.RuleEnum:
+			property.Enum = compiled.values
+		}
+	}
+}
+
+// check reports whether a field value passes the rule. Only "required" rejects missing values.
+func (cr compiledRule) check(value interface{}) bool {
+	if value == nil || value == "" {
+		return cr.rule.Rule != models.RuleRequired
+	}
+
+	switch cr.rule.Rule {
+	case models.RuleRegex:
+		return cr.pattern.MatchString(fmt.Sprint(value))
+	case models.RuleMinLength:
+		return float64(utf8.RuneCountInString(fmt.Sprint(value))) >= cr.number
+	case models.RuleMaxLength:
+		return float64(utf8.RuneCountInString(fmt.Sprint(value))) <= cr.number
+	case models.RuleMin:
+		number, ok := value.(float64)
+
+		return ok && number >= cr.number
+	case models.RuleMax:
+		number, ok := value.(float64)
+
+		return ok && number <= cr.number
+	case models.RuleEnum:
+		return slices.Contains(cr.values, fmt.Sprint(value))
+	default:
+		return true
+	}
+}
+
+// message returns the error message of the rule.
+func (cr compiledRule) message() string {
+	if cr.rule.Message != "" {
+		return cr.rule.Message
+	}
+
+	switch cr.rule.Rule {
+	case models.RuleRequired:
+		return cr.rule.Field + " is required"
+	case models.RuleRegex:
+		return cr.rule.Field + " must match " + cr.rule.Params
+	case models.RuleMinLength:
+		return cr.rule.Field + " must be at least " + cr.rule.Params + " characters long"
+	case models.RuleMaxLength:
+		return cr.rule.Field + " must be at most " + cr.rule.Params + " characters long"
+	case models.RuleMin:
+		return cr.rule.Field + " must be a number greater than or equal to " + cr.rule.Params
+	case models.RuleMax:
+		return cr.rule.Field + " must be a number lower than or equal to " + cr.rule.Params
+	default:
+		return cr.rule.Field + " must be one of " + cr.rule.Params
+	}
+}
@@ -24,163 +24,323 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/login": {
-            "post": {
+        "/admin/alerts": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Login using username and password, and return a JWT token for authorized access",
-                "consumes": [
+                "description": "Routes whose rolling p95 latency currently exceeds SLOW_ROUTE_THRESHOLD_MS, the metrics of the outbound\nHTTP calls per host (webhooks, notification channels, importers, OPA), and every active alert (slow\nroutes, tables growing faster than TABLE_GROWTH_THRESHOLD, outbound hosts with an open circuit and\ndependencies down).",
+                "produces": [
                     "application/json"
                 ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Slow routes, outbound hosts and alerts",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Alerts"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/audit/config": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Changes of settings, feature flags, validation and notification rules, service accounts, quotas and\nuser roles made through the admin endpoints, newest first, with their actor and the configuration\nbefore and after them. The entries can't be changed or deleted.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "authentication"
+                    "admin"
                 ],
-                "summary": "Login and generate JWT token",
+                "summary": "Configuration audit log",
                 "parameters": [
                     {
-                        "description": "Login request with username and password",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
+                        "enum": [
+                            "setting",
+                            "feature_flag",
+                            "validation_rule",
+                            "notification_rule",
+                            "service_account",
+                            "quota",
+                            "user_role"
+                        ],
+                        "type": "string",
+                        "description": "Kind of configuration",
+                        "name": "kind",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Key of the configuration within its kind (setting key, flag name, client ID, username or rule ID)",
+                        "name": "key",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Username who made the changes",
+                        "name": "actor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only the changes after this RFC 3339 timestamp",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only the entries older than this entry ID, to read the next page",
+                        "name": "before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of entries (default 100, max 1000)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.LoginRequest"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ConfigChange"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     }
+                }
+            }
+        },
+        "/admin/audit/config/export": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "The entries are chained by hash. /verify checks that none was altered, inserted or removed in the\ndatabase since it was recorded, and returns the hash of the last entry to keep elsewhere. /export\ndownloads a ZIP bundle of the entries (entries.ndjson) with a manifest of their count, hashes and chain\ncheck (manifest.json), its Ed25519 signature with AUDIT_SIGNING_KEY (manifest.sig) and the public key\n(public.pem); it answers 404 without AUDIT_SIGNING_KEY.",
+                "produces": [
+                    "application/json",
+                    "application/zip"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Verify and export the configuration audit log",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Only export the entries after this entry ID (/export)",
+                        "name": "after",
+                        "in": "query"
+                    }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.JWTResponse"
+                            "$ref": "#/definitions/models.AuditVerification"
                         }
                     },
                     "400": {
-                        "description": "Invalid input",
+                        "description": "Bad Request",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
-                            "type": "Object"
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/user": {
+        "/admin/audit/config/verify": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
-                    },
-                    {
-                        "ApiKeyAuth.": []
                     }
                 ],
-                "description": "Setup routes for administrative resources like users, servers, employees, etc.",
+                "description": "The entries are chained by hash. /verify checks that none was altered, inserted or removed in the\ndatabase since it was recorded, and returns the hash of the last entry to keep elsewhere. /export\ndownloads a ZIP bundle of the entries (entries.ndjson) with a manifest of their count, hashes and chain\ncheck (manifest.json), its Ed25519 signature with AUDIT_SIGNING_KEY (manifest.sig) and the public key\n(public.pem); it answers 404 without AUDIT_SIGNING_KEY.",
+                "produces": [
+                    "application/json",
+                    "application/zip"
+                ],
                 "tags": [
                     "admin"
                 ],
-                "summary": "Setup admin routes",
-                "responses": {}
+                "summary": "Verify and export the configuration audit log",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Only export the entries after this entry ID (/export)",
+                        "name": "after",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.AuditVerification"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
             }
         },
-        "/{resource}": {
+        "/admin/config": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Setup routes for CRUD operations on resources like users, servers, employees, etc.",
+                "description": "Parameters that can be changed without restarting (log_level, rate_limit_per_minute, cors_origins, maintenance_mode), with their effective value and source (default, env or settings). Change them with PUT /admin/settings/{key}.",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "user"
+                    "admin"
                 ],
-                "summary": "Setup GET resource routes",
-                "parameters": [
+                "summary": "Effective runtime configuration",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ConfigValue"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/counters/recompute": {
+            "post": {
+                "security": [
                     {
-                        "enum": [
-                            "example1",
-                            "example2",
-                            "exampleRelational"
-                        ],
-                        "type": "string",
-                        "description": "Resource type",
-                        "name": "resource",
-                        "in": "path",
-                        "required": true
+                        "ApiKeyAuth": []
                     }
                 ],
-                "responses": {}
-            },
-            "put": {
+                "description": "Recount every denormalized counter (e.g. example1.relational_count) from the rows it counts and\nreport how many records had a wrong value.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Recompute the counters",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.CounterRepair"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/dependencies": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Setup routes for administrative resources like users, servers, employees, etc.",
+                "description": "List, create, replace and delete the checks of the external services the API depends on (object\nstorage, OIDC provider...). Each enabled check GETs its url every interval seconds (at least 10), with\nits headers and timeout (default 5, at most 60 seconds); the dependency is up when the response has\nexpected_status (0 accepts any 2xx) and down after failure_threshold failed checks in a row (default 1).\nIts state is shown in /readyz, and its changes are sent to the \"dependency\" notification rules and to\nALERT_WEBHOOK_URL. POST /admin/dependencies/{id}/check checks a dependency now. Changing the url of a\ncheck resets its state to unknown.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
                     "admin"
                 ],
-                "summary": "Setup admin routes",
+                "summary": "Manage dependency health checks",
                 "parameters": [
                     {
-                        "enum": [
-                            "user",
-                            "example1",
-                            "example2",
-                            "exampleRelational"
-                        ],
-                        "type": "string",
-                        "description": "Resource type",
-                        "name": "resource",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "JSON request body for POST and PATCH operations",
-                        "name": "defaultRequest",
+                        "description": "Dependency check to store (for POST and PUT)",
+                        "name": "body",
                         "in": "body",
-                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.DefaultRequest"
+                            "$ref": "#/definitions/models.DependencyCheck"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.DependencyCheck"
+                            }
                         }
                     },
-                    {
-                        "description": "Example1 object to create",
-                        "name": "example1",
-                        "in": "body",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/models.Example1"
+                            "$ref": "#/definitions/models.DependencyCheck"
                         }
                     },
-                    {
-                        "description": "Example2 object to create",
-                        "name": "example2",
-                        "in": "body",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/models.Example2"
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    {
-                        "description": "Example2 object to create",
-                        "name": "example2",
-                        "in": "body",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/models.Example2"
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     }
-                ],
-                "responses": {}
+                }
             },
             "post": {
                 "security": [
@@ -188,258 +348,9765 @@ const docTemplate = `{
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Setup routes for administrative resources like users, servers, employees, etc.",
+                "description": "List, create, replace and delete the checks of the external services the API depends on (object\nstorage, OIDC provider...). Each enabled check GETs its url every interval seconds (at least 10), with\nits headers and timeout (default 5, at most 60 seconds); the dependency is up when the response has\nexpected_status (0 accepts any 2xx) and down after failure_threshold failed checks in a row (default 1).\nIts state is shown in /readyz, and its changes are sent to the \"dependency\" notification rules and to\nALERT_WEBHOOK_URL. POST /admin/dependencies/{id}/check checks a dependency now. Changing the url of a\ncheck resets its state to unknown.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
                     "admin"
                 ],
-                "summary": "Setup admin routes",
+                "summary": "Manage dependency health checks",
                 "parameters": [
                     {
-                        "enum": [
-                            "user",
-                            "example1",
-                            "example2",
-                            "exampleRelational"
-                        ],
-                        "type": "string",
-                        "description": "Resource type",
-                        "name": "resource",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "JSON request body for POST and PATCH operations",
-                        "name": "defaultRequest",
+                        "description": "Dependency check to store (for POST and PUT)",
+                        "name": "body",
                         "in": "body",
-                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.DefaultRequest"
+                            "$ref": "#/definitions/models.DependencyCheck"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.DependencyCheck"
+                            }
                         }
                     },
-                    {
-                        "description": "Example1 object to create",
-                        "name": "example1",
-                        "in": "body",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/models.Example1"
+                            "$ref": "#/definitions/models.DependencyCheck"
                         }
                     },
-                    {
-                        "description": "Example2 object to create",
-                        "name": "example2",
-                        "in": "body",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/models.Example2"
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    {
-                        "description": "Example2 object to create",
-                        "name": "example2",
-                        "in": "body",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/models.Example2"
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     }
-                ],
-                "responses": {}
+                }
             }
         },
-        "/{resource}/{id}": {
-            "get": {
+        "/admin/dependencies/{id}": {
+            "put": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Setup routes for CRUD operations on resources like users, servers, employees, etc.",
+                "description": "List, create, replace and delete the checks of the external services the API depends on (object\nstorage, OIDC provider...). Each enabled check GETs its url every interval seconds (at least 10), with\nits headers and timeout (default 5, at most 60 seconds); the dependency is up when the response has\nexpected_status (0 accepts any 2xx) and down after failure_threshold failed checks in a row (default 1).\nIts state is shown in /readyz, and its changes are sent to the \"dependency\" notification rules and to\nALERT_WEBHOOK_URL. POST /admin/dependencies/{id}/check checks a dependency now. Changing the url of a\ncheck resets its state to unknown.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "user"
+                    "admin"
                 ],
-                "summary": "Setup GET resource routes",
+                "summary": "Manage dependency health checks",
                 "parameters": [
                     {
-                        "enum": [
-                            "example1",
-                            "example2",
-                            "exampleRelational"
-                        ],
-                        "type": "string",
-                        "description": "Resource type",
-                        "name": "resource",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Resource ID (for operations on specific resources)",
+                        "type": "integer",
+                        "description": "Dependency check ID (for PUT, DELETE and check)",
                         "name": "id",
                         "in": "path"
+                    },
+                    {
+                        "description": "Dependency check to store (for POST and PUT)",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.DependencyCheck"
+                        }
                     }
                 ],
-                "responses": {}
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.DependencyCheck"
+                            }
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.DependencyCheck"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
             },
             "delete": {
                 "security": [
                     {
                         "ApiKeyAuth": []
-                    },
-                    {
-                        "ApiKeyAuth.": []
                     }
                 ],
-                "description": "Setup routes for administrative resources like users, servers, employees, etc.",
+                "description": "List, create, replace and delete the checks of the external services the API depends on (object\nstorage, OIDC provider...). Each enabled check GETs its url every interval seconds (at least 10), with\nits headers and timeout (default 5, at most 60 seconds); the dependency is up when the response has\nexpected_status (0 accepts any 2xx) and down after failure_threshold failed checks in a row (default 1).\nIts state is shown in /readyz, and its changes are sent to the \"dependency\" notification rules and to\nALERT_WEBHOOK_URL. POST /admin/dependencies/{id}/check checks a dependency now. Changing the url of a\ncheck resets its state to unknown.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
                     "admin"
                 ],
-                "summary": "Setup admin routes",
+                "summary": "Manage dependency health checks",
                 "parameters": [
                     {
-                        "enum": [
-                            "user",
-                            "example1",
-                            "example2",
-                            "exampleRelational"
-                        ],
-                        "type": "string",
-                        "description": "Resource type",
-                        "name": "resource",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Resource ID (for operations on specific resources)",
+                        "type": "integer",
+                        "description": "Dependency check ID (for PUT, DELETE and check)",
                         "name": "id",
                         "in": "path"
+                    },
+                    {
+                        "description": "Dependency check to store (for POST and PUT)",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.DependencyCheck"
+                        }
                     }
                 ],
-                "responses": {}
-            },
-            "patch": {
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.DependencyCheck"
+                            }
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.DependencyCheck"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/dependencies/{id}/check": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Setup routes for administrative resources like users, servers, employees, etc.",
+                "description": "List, create, replace and delete the checks of the external services the API depends on (object\nstorage, OIDC provider...). Each enabled check GETs its url every interval seconds (at least 10), with\nits headers and timeout (default 5, at most 60 seconds); the dependency is up when the response has\nexpected_status (0 accepts any 2xx) and down after failure_threshold failed checks in a row (default 1).\nIts state is shown in /readyz, and its changes are sent to the \"dependency\" notification rules and to\nALERT_WEBHOOK_URL. POST /admin/dependencies/{id}/check checks a dependency now. Changing the url of a\ncheck resets its state to unknown.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
                     "admin"
                 ],
-                "summary": "Setup admin routes",
+                "summary": "Manage dependency health checks",
                 "parameters": [
                     {
-                        "enum": [
-                            "user",
-                            "example1",
-                            "example2",
-                            "exampleRelational"
-                        ],
-                        "type": "string",
-                        "description": "Resource type",
-                        "name": "resource",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Resource ID (for operations on specific resources)",
+                        "type": "integer",
+                        "description": "Dependency check ID (for PUT, DELETE and check)",
                         "name": "id",
                         "in": "path"
                     },
                     {
-                        "description": "JSON request body for POST and PATCH operations",
-                        "name": "defaultRequest",
+                        "description": "Dependency check to store (for POST and PUT)",
+                        "name": "body",
                         "in": "body",
-                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.DefaultRequest"
+                            "$ref": "#/definitions/models.DependencyCheck"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.DependencyCheck"
+                            }
                         }
                     },
-                    {
-                        "description": "Example1 object to create",
-                        "name": "example1",
-                        "in": "body",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/models.Example1"
+                            "$ref": "#/definitions/models.DependencyCheck"
                         }
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/email-rules": {
+            "get": {
+                "security": [
                     {
-                        "description": "Example2 object to create",
-                        "name": "example2",
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List, create, replace and delete the rules converting the received emails (the EMAIL_IMAP_URL mailbox\nand the Mailgun inbound route) into records. The enabled rules are checked by priority, lowest first;\nthe first whose from_pattern, to_pattern and subject_pattern (regular expressions, empty matches\neverything) match the email creates a record of its resource. The mapping maps the resource fields to\nsubject, body, text, html, from, from_name, to, date, message_id, attachments (JSON array with the\nbase64 content), attachment_names, or subject.\u003cgroup\u003e for a named group of the subject_pattern. The\nprimary key must be mapped, e.g. to message_id, so a redelivered email is not created twice.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Manage email rules",
+                "parameters": [
+                    {
+                        "description": "Email rule to store (for POST and PUT)",
+                        "name": "body",
                         "in": "body",
                         "schema": {
-                            "$ref": "#/definitions/models.Example2"
+                            "$ref": "#/definitions/models.EmailRule"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.EmailRule"
+                            }
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.EmailRule"
                         }
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
                     {
-                        "description": "Example2 object to create",
-                        "name": "example2",
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List, create, replace and delete the rules converting the received emails (the EMAIL_IMAP_URL mailbox\nand the Mailgun inbound route) into records. The enabled rules are checked by priority, lowest first;\nthe first whose from_pattern, to_pattern and subject_pattern (regular expressions, empty matches\neverything) match the email creates a record of its resource. The mapping maps the resource fields to\nsubject, body, text, html, from, from_name, to, date, message_id, attachments (JSON array with the\nbase64 content), attachment_names, or subject.\u003cgroup\u003e for a named group of the subject_pattern. The\nprimary key must be mapped, e.g. to message_id, so a redelivered email is not created twice.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Manage email rules",
+                "parameters": [
+                    {
+                        "description": "Email rule to store (for POST and PUT)",
+                        "name": "body",
                         "in": "body",
                         "schema": {
-                            "$ref": "#/definitions/models.Example2"
+                            "$ref": "#/definitions/models.EmailRule"
                         }
                     }
                 ],
-                "responses": {}
-            }
-        }
-    },
-    "definitions": {
-        "models.DefaultRequest": {
-            "type": "object",
-            "properties": {
-                "field": {
-                    "description": "Field is a placeholder for data that might be required in some requests.",
-                    "type": "string"
-                }
-            }
-        },
-        "models.Example1": {
-            "type": "object",
-            "properties": {
-                "field1": {
-                    "description": "Field1 is the primary key of the Example1 table.",
-                    "type": "string"
-                },
-                "field2": {
-                    "description": "Field2 stores additional data related to Example1.",
-                    "type": "string"
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.EmailRule"
+                            }
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.EmailRule"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
                 }
             }
         },
-        "models.Example2": {
-            "type": "object",
-            "properties": {
-                "field1": {
+        "/admin/email-rules/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List, create, replace and delete the rules converting the received emails (the EMAIL_IMAP_URL mailbox\nand the Mailgun inbound route) into records. The enabled rules are checked by priority, lowest first;\nthe first whose from_pattern, to_pattern and subject_pattern (regular expressions, empty matches\neverything) match the email creates a record of its resource. The mapping maps the resource fields to\nsubject, body, text, html, from, from_name, to, date, message_id, attachments (JSON array with the\nbase64 content), attachment_names, or subject.\u003cgroup\u003e for a named group of the subject_pattern. The\nprimary key must be mapped, e.g. to message_id, so a redelivered email is not created twice.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Manage email rules",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Email rule ID (for PUT and DELETE)",
+                        "name": "id",
+                        "in": "path"
+                    },
+                    {
+                        "description": "Email rule to store (for POST and PUT)",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.EmailRule"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.EmailRule"
+                            }
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.EmailRule"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List, create, replace and delete the rules converting the received emails (the EMAIL_IMAP_URL mailbox\nand the Mailgun inbound route) into records. The enabled rules are checked by priority, lowest first;\nthe first whose from_pattern, to_pattern and subject_pattern (regular expressions, empty matches\neverything) match the email creates a record of its resource. The mapping maps the resource fields to\nsubject, body, text, html, from, from_name, to, date, message_id, attachments (JSON array with the\nbase64 content), attachment_names, or subject.\u003cgroup\u003e for a named group of the subject_pattern. The\nprimary key must be mapped, e.g. to message_id, so a redelivered email is not created twice.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Manage email rules",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Email rule ID (for PUT and DELETE)",
+                        "name": "id",
+                        "in": "path"
+                    },
+                    {
+                        "description": "Email rule to store (for POST and PUT)",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.EmailRule"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.EmailRule"
+                            }
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.EmailRule"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/export": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Start an export of every record of the resources, e.g. with anonymize=true to share a realistic dataset\nwithout personal data. Works like POST /{resource}/export, with the records of one resource per file,\nnamed after the resource. Anonymizing replaces the fields tagged with ` + "`" + `anonymize` + "`" + ` (hash, faker=\u003ckind\u003e or\nnullify) with pseudonyms consistent across the dump, so the references between records still match.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Dump the resources",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comma-separated resources to dump (default all)",
+                        "name": "resources",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "csv"
+                        ],
+                        "type": "string",
+                        "description": "Format of the files",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Replace the personal data of the records",
+                        "name": "anonymize",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/models.ExportJob"
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "URL of the export"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Too many concurrent requests of the route group",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/flags": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List, create or replace, and delete feature flags. A flag without roles or users applies to everyone.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Manage feature flags",
+                "parameters": [
+                    {
+                        "description": "Flag to store (for PUT)",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.FeatureFlag"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.FeatureFlag"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/flags/{name}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List, create or replace, and delete feature flags. A flag without roles or users applies to everyone.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Manage feature flags",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Flag name (for PUT and DELETE)",
+                        "name": "name",
+                        "in": "path"
+                    },
+                    {
+                        "description": "Flag to store (for PUT)",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.FeatureFlag"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.FeatureFlag"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List, create or replace, and delete feature flags. A flag without roles or users applies to everyone.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Manage feature flags",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Flag name (for PUT and DELETE)",
+                        "name": "name",
+                        "in": "path"
+                    },
+                    {
+                        "description": "Flag to store (for PUT)",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.FeatureFlag"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.FeatureFlag"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/generate": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Create count synthetic records of a resource in a background job, like a bulk import, to exercise the\npagination or load test quickly. Only registered with DEV_MODE. The values follow the column types:\nunique for the primary keys and unique columns, one of the values of enums, existing records for the\nforeign keys. Answers 202 with the job, polled at the URL of the Location header.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Generate test records",
+                "parameters": [
+                    {
+                        "enum": [
+                            "user",
+                            "example1",
+                            "example2",
+                            "exampleRelational",
+                            "announcements"
+                        ],
+                        "type": "string",
+                        "description": "Resource type",
+                        "name": "resource",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of records, up to 100000",
+                        "name": "count",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/models.BulkJob"
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "URL of the job"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Step-up authentication required (users)",
+                        "schema": {
+                            "$ref": "#/definitions/models.StepUpResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "A foreign key references an empty table",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Too many concurrent requests of the route group",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/hooks": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List, create, replace and delete the inbound hooks receiving the payloads third-party services POST to\n/hooks/{name}. The secret signs the payloads (HMAC-SHA256) and is never returned (has_secret tells if\nthere is one); an empty secret on PUT keeps the stored one. The record, or array of records, at\nrecords_path is mapped to the resource with the mapping (resource field → dot path in the payload), like\nthe importers, and created, updated or upserted by primary key as the action says.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Manage inbound webhooks",
+                "parameters": [
+                    {
+                        "description": "Inbound hook to store (for POST and PUT)",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.InboundHook"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.InboundHook"
+                            }
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.InboundHook"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List, create, replace and delete the inbound hooks receiving the payloads third-party services POST to\n/hooks/{name}. The secret signs the payloads (HMAC-SHA256) and is never returned (has_secret tells if\nthere is one); an empty secret on PUT keeps the stored one. The record, or array of records, at\nrecords_path is mapped to the resource with the mapping (resource field → dot path in the payload), like\nthe importers, and created, updated or upserted by primary key as the action says.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Manage inbound webhooks",
+                "parameters": [
+                    {
+                        "description": "Inbound hook to store (for POST and PUT)",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.InboundHook"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.InboundHook"
+                            }
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.InboundHook"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/hooks/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List, create, replace and delete the inbound hooks receiving the payloads third-party services POST to\n/hooks/{name}. The secret signs the payloads (HMAC-SHA256) and is never returned (has_secret tells if\nthere is one); an empty secret on PUT keeps the stored one. The record, or array of records, at\nrecords_path is mapped to the resource with the mapping (resource field → dot path in the payload), like\nthe importers, and created, updated or upserted by primary key as the action says.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Manage inbound webhooks",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Inbound hook ID (for PUT and DELETE)",
+                        "name": "id",
+                        "in": "path"
+                    },
+                    {
+                        "description": "Inbound hook to store (for POST and PUT)",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.InboundHook"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.InboundHook"
+                            }
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.InboundHook"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List, create, replace and delete the inbound hooks receiving the payloads third-party services POST to\n/hooks/{name}. The secret signs the payloads (HMAC-SHA256) and is never returned (has_secret tells if\nthere is one); an empty secret on PUT keeps the stored one. The record, or array of records, at\nrecords_path is mapped to the resource with the mapping (resource field → dot path in the payload), like\nthe importers, and created, updated or upserted by primary key as the action says.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Manage inbound webhooks",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Inbound hook ID (for PUT and DELETE)",
+                        "name": "id",
+                        "in": "path"
+                    },
+                    {
+                        "description": "Inbound hook to store (for POST and PUT)",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.InboundHook"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.InboundHook"
+                            }
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.InboundHook"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/importers": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List, create, replace and delete importers pulling a JSON array of records from an external URL (with\nthe headers of the importer, e.g. Authorization), mapping their fields to a resource with the mapping\n(resource field → dot path in the external record), and upserting them by primary key. Importers with an\ninterval run on a schedule; POST /run runs one now and returns the created, updated and failed records.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Manage importers from external REST APIs",
+                "parameters": [
+                    {
+                        "description": "Importer to store (for POST and PUT)",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.Importer"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Importer"
+                            }
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Importer"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List, create, replace and delete importers pulling a JSON array of records from an external URL (with\nthe headers of the importer, e.g. Authorization), mapping their fields to a resource with the mapping\n(resource field → dot path in the external record), and upserting them by primary key. Importers with an\ninterval run on a schedule; POST /run runs one now and returns the created, updated and failed records.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Manage importers from external REST APIs",
+                "parameters": [
+                    {
+                        "description": "Importer to store (for POST and PUT)",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.Importer"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Importer"
+                            }
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Importer"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/importers/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List, create, replace and delete importers pulling a JSON array of records from an external URL (with\nthe headers of the importer, e.g. Authorization), mapping their fields to a resource with the mapping\n(resource field → dot path in the external record), and upserting them by primary key. Importers with an\ninterval run on a schedule; POST /run runs one now and returns the created, updated and failed records.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Manage importers from external REST APIs",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Importer ID (for PUT, DELETE and POST /run)",
+                        "name": "id",
+                        "in": "path"
+                    },
+                    {
+                        "description": "Importer to store (for POST and PUT)",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.Importer"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Importer"
+                            }
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Importer"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List, create, replace and delete importers pulling a JSON array of records from an external URL (with\nthe headers of the importer, e.g. Authorization), mapping their fields to a resource with the mapping\n(resource field → dot path in the external record), and upserting them by primary key. Importers with an\ninterval run on a schedule; POST /run runs one now and returns the created, updated and failed records.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Manage importers from external REST APIs",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Importer ID (for PUT, DELETE and POST /run)",
+                        "name": "id",
+                        "in": "path"
+                    },
+                    {
+                        "description": "Importer to store (for POST and PUT)",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.Importer"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Importer"
+                            }
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Importer"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/importers/{id}/run": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Runs an importer whether it is scheduled or not. Records failing to import (invalid, over the quota...)\nare listed in \"failed\" and don't stop the others; the summary is stored in last_error.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Run an importer now",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Importer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ImportResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/leader": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "The instance running the scheduled jobs (stats snapshots, reports, importers, export cleanup) among the\nreplicas sharing the database, its lease, and whether the instance answering is the leader.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Scheduler leader",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.LeaderStatus"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/notification-rules": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List, create, replace and delete rules that send Slack or Microsoft Teams messages on resource events or bursts of 5xx errors.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Manage notification rules",
+                "parameters": [
+                    {
+                        "description": "Rule to store (for POST and PUT)",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.NotificationRule"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.NotificationRule"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List, create, replace and delete rules that send Slack or Microsoft Teams messages on resource events or bursts of 5xx errors.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Manage notification rules",
+                "parameters": [
+                    {
+                        "description": "Rule to store (for POST and PUT)",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.NotificationRule"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.NotificationRule"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/notification-rules/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List, create, replace and delete rules that send Slack or Microsoft Teams messages on resource events or bursts of 5xx errors.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Manage notification rules",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Rule ID (for PUT and DELETE)",
+                        "name": "id",
+                        "in": "path"
+                    },
+                    {
+                        "description": "Rule to store (for POST and PUT)",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.NotificationRule"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.NotificationRule"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List, create, replace and delete rules that send Slack or Microsoft Teams messages on resource events or bursts of 5xx errors.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Manage notification rules",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Rule ID (for PUT and DELETE)",
+                        "name": "id",
+                        "in": "path"
+                    },
+                    {
+                        "description": "Rule to store (for POST and PUT)",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.NotificationRule"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.NotificationRule"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/outbound": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Routes whose rolling p95 latency currently exceeds SLOW_ROUTE_THRESHOLD_MS, the metrics of the outbound\nHTTP calls per host (webhooks, notification channels, importers, OPA), and every active alert (slow\nroutes, tables growing faster than TABLE_GROWTH_THRESHOLD, outbound hosts with an open circuit and\ndependencies down).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Slow routes, outbound hosts and alerts",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Alerts"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/quotas": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List, create, replace and delete the quotas capping the records each owner may create,\ne.g. {\"resource\": \"example2\", \"owner\": \"\", \"max_rows\": 1000}. The owner of a record is the user\nwho created it; an empty owner is the default quota of the resource. Creates over the quota get 403.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Manage quotas",
+                "parameters": [
+                    {
+                        "description": "Quota to store (for POST and PUT)",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.Quota"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Quota"
+                            }
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Quota"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List, create, replace and delete the quotas capping the records each owner may create,\ne.g. {\"resource\": \"example2\", \"owner\": \"\", \"max_rows\": 1000}. The owner of a record is the user\nwho created it; an empty owner is the default quota of the resource. Creates over the quota get 403.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Manage quotas",
+                "parameters": [
+                    {
+                        "description": "Quota to store (for POST and PUT)",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.Quota"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Quota"
+                            }
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Quota"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/quotas/usage": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Number of records of each owner of the resources with a quota, next to the quota applying to them.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Quota usage",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.QuotaUsage"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/quotas/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List, create, replace and delete the quotas capping the records each owner may create,\ne.g. {\"resource\": \"example2\", \"owner\": \"\", \"max_rows\": 1000}. The owner of a record is the user\nwho created it; an empty owner is the default quota of the resource. Creates over the quota get 403.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Manage quotas",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Quota ID (for PUT and DELETE)",
+                        "name": "id",
+                        "in": "path"
+                    },
+                    {
+                        "description": "Quota to store (for POST and PUT)",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.Quota"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Quota"
+                            }
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Quota"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List, create, replace and delete the quotas capping the records each owner may create,\ne.g. {\"resource\": \"example2\", \"owner\": \"\", \"max_rows\": 1000}. The owner of a record is the user\nwho created it; an empty owner is the default quota of the resource. Creates over the quota get 403.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Manage quotas",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Quota ID (for PUT and DELETE)",
+                        "name": "id",
+                        "in": "path"
+                    },
+                    {
+                        "description": "Quota to store (for POST and PUT)",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.Quota"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Quota"
+                            }
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Quota"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/reports": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List, create, replace and delete reports running a saved query (view) of the admin every interval\nseconds. Each run is compared with the previous one and the new, changed and removed rows are sent\nto the emails and/or webhook. The first run is a baseline and sends nothing. POST /run runs it now.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Manage scheduled reports",
+                "parameters": [
+                    {
+                        "description": "Report to store (for POST and PUT)",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.Report"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Report"
+                            }
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Report"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List, create, replace and delete reports running a saved query (view) of the admin every interval\nseconds. Each run is compared with the previous one and the new, changed and removed rows are sent\nto the emails and/or webhook. The first run is a baseline and sends nothing. POST /run runs it now.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Manage scheduled reports",
+                "parameters": [
+                    {
+                        "description": "Report to store (for POST and PUT)",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.Report"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Report"
+                            }
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Report"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/reports/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List, create, replace and delete reports running a saved query (view) of the admin every interval\nseconds. Each run is compared with the previous one and the new, changed and removed rows are sent\nto the emails and/or webhook. The first run is a baseline and sends nothing. POST /run runs it now.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Manage scheduled reports",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Report ID (for PUT, DELETE and POST /run)",
+                        "name": "id",
+                        "in": "path"
+                    },
+                    {
+                        "description": "Report to store (for POST and PUT)",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.Report"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Report"
+                            }
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Report"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List, create, replace and delete reports running a saved query (view) of the admin every interval\nseconds. Each run is compared with the previous one and the new, changed and removed rows are sent\nto the emails and/or webhook. The first run is a baseline and sends nothing. POST /run runs it now.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Manage scheduled reports",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Report ID (for PUT, DELETE and POST /run)",
+                        "name": "id",
+                        "in": "path"
+                    },
+                    {
+                        "description": "Report to store (for POST and PUT)",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.Report"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Report"
+                            }
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Report"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/reports/{id}/run": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List, create, replace and delete reports running a saved query (view) of the admin every interval\nseconds. Each run is compared with the previous one and the new, changed and removed rows are sent\nto the emails and/or webhook. The first run is a baseline and sends nothing. POST /run runs it now.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Manage scheduled reports",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Report ID (for PUT, DELETE and POST /run)",
+                        "name": "id",
+                        "in": "path"
+                    },
+                    {
+                        "description": "Report to store (for POST and PUT)",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.Report"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Report"
+                            }
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Report"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/routes": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Every route of the router with its methods, the role required (public, user or admin) and the model type of resource routes.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Registered routes",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.RouteInfo"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/schema/graph": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "The tables of the models and their relationships as nodes and edges, to render an ER diagram. The\nrelationships come from the GORM tags of the models and, on MySQL, the foreign keys of\ninformation_schema; \"source\" tells which of them declares each table and relationship.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Schema graph",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SchemaGraph"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/service-accounts": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Non-interactive clients that get tokens from POST /oauth/token. The client secret is only returned on creation and rotation. Scopes are comma-separated \"\u003cresource\u003e:\u003cread|write|*\u003e\" entries, where \"*\" matches any resource.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Service accounts",
+                "parameters": [
+                    {
+                        "description": "Service account (for POST and PUT)",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.ServiceAccount"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ServiceAccount"
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.ServiceAccountCredentials"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Non-interactive clients that get tokens from POST /oauth/token. The client secret is only returned on creation and rotation. Scopes are comma-separated \"\u003cresource\u003e:\u003cread|write|*\u003e\" entries, where \"*\" matches any resource.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Service accounts",
+                "parameters": [
+                    {
+                        "description": "Service account (for POST and PUT)",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.ServiceAccount"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ServiceAccount"
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.ServiceAccountCredentials"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/service-accounts/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Non-interactive clients that get tokens from POST /oauth/token. The client secret is only returned on creation and rotation. Scopes are comma-separated \"\u003cresource\u003e:\u003cread|write|*\u003e\" entries, where \"*\" matches any resource.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Service accounts",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Client ID (for operations on a specific account)",
+                        "name": "id",
+                        "in": "path"
+                    },
+                    {
+                        "description": "Service account (for POST and PUT)",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.ServiceAccount"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ServiceAccount"
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.ServiceAccountCredentials"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Non-interactive clients that get tokens from POST /oauth/token. The client secret is only returned on creation and rotation. Scopes are comma-separated \"\u003cresource\u003e:\u003cread|write|*\u003e\" entries, where \"*\" matches any resource.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Service accounts",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Client ID (for operations on a specific account)",
+                        "name": "id",
+                        "in": "path"
+                    },
+                    {
+                        "description": "Service account (for POST and PUT)",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.ServiceAccount"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ServiceAccount"
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.ServiceAccountCredentials"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/service-accounts/{id}/secret": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Non-interactive clients that get tokens from POST /oauth/token. The client secret is only returned on creation and rotation. Scopes are comma-separated \"\u003cresource\u003e:\u003cread|write|*\u003e\" entries, where \"*\" matches any resource.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Service accounts",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Client ID (for operations on a specific account)",
+                        "name": "id",
+                        "in": "path"
+                    },
+                    {
+                        "description": "Service account (for POST and PUT)",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.ServiceAccount"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ServiceAccount"
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.ServiceAccountCredentials"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/settings": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Typed key-value parameters adjustable without redeploying. Every change is recorded in the setting history.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Manage runtime settings",
+                "parameters": [
+                    {
+                        "description": "Setting to store (for PUT); value must match type (string, int, float, bool or json)",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.Setting"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Setting"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/settings/{key}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Typed key-value parameters adjustable without redeploying. Every change is recorded in the setting history.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Manage runtime settings",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Setting key",
+                        "name": "key",
+                        "in": "path"
+                    },
+                    {
+                        "description": "Setting to store (for PUT); value must match type (string, int, float, bool or json)",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.Setting"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Setting"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Typed key-value parameters adjustable without redeploying. Every change is recorded in the setting history.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Manage runtime settings",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Setting key",
+                        "name": "key",
+                        "in": "path"
+                    },
+                    {
+                        "description": "Setting to store (for PUT); value must match type (string, int, float, bool or json)",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.Setting"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Setting"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Typed key-value parameters adjustable without redeploying. Every change is recorded in the setting history.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Manage runtime settings",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Setting key",
+                        "name": "key",
+                        "in": "path"
+                    },
+                    {
+                        "description": "Setting to store (for PUT); value must match type (string, int, float, bool or json)",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.Setting"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Setting"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/settings/{key}/history": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Typed key-value parameters adjustable without redeploying. Every change is recorded in the setting history.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Manage runtime settings",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Setting key",
+                        "name": "key",
+                        "in": "path"
+                    },
+                    {
+                        "description": "Setting to store (for PUT); value must match type (string, int, float, bool or json)",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.Setting"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Setting"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/slow-routes": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Routes whose rolling p95 latency currently exceeds SLOW_ROUTE_THRESHOLD_MS, the metrics of the outbound\nHTTP calls per host (webhooks, notification channels, importers, OPA), and every active alert (slow\nroutes, tables growing faster than TABLE_GROWTH_THRESHOLD, outbound hosts with an open circuit and\ndependencies down).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Slow routes, outbound hosts and alerts",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Alerts"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/stats": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Row counts and sizes per table, per-column cardinality, null counts, min/max and sampled top values,\nand the history of the table statistics stored every STATS_SNAPSHOT_INTERVAL seconds.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Database statistics",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 10000,
+                        "description": "Number of rows sampled for top values",
+                        "name": "sample",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 5,
+                        "description": "Number of top values per column",
+                        "name": "top",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Table name (for the history)",
+                        "name": "table",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Earliest snapshot, RFC 3339 (for the history)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Latest snapshot, RFC 3339 (for the history)",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.StatsSnapshot"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Too many concurrent requests of the route group",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/stats/history": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Row counts and sizes per table, per-column cardinality, null counts, min/max and sampled top values,\nand the history of the table statistics stored every STATS_SNAPSHOT_INTERVAL seconds.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Database statistics",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 10000,
+                        "description": "Number of rows sampled for top values",
+                        "name": "sample",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 5,
+                        "description": "Number of top values per column",
+                        "name": "top",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Table name (for the history)",
+                        "name": "table",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Earliest snapshot, RFC 3339 (for the history)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Latest snapshot, RFC 3339 (for the history)",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.StatsSnapshot"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Too many concurrent requests of the route group",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/stats/{table}/columns": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Row counts and sizes per table, per-column cardinality, null counts, min/max and sampled top values,\nand the history of the table statistics stored every STATS_SNAPSHOT_INTERVAL seconds.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Database statistics",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Table name (for column statistics)",
+                        "name": "table",
+                        "in": "path"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10000,
+                        "description": "Number of rows sampled for top values",
+                        "name": "sample",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 5,
+                        "description": "Number of top values per column",
+                        "name": "top",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Table name (for the history)",
+                        "name": "table",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Earliest snapshot, RFC 3339 (for the history)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Latest snapshot, RFC 3339 (for the history)",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.StatsSnapshot"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Too many concurrent requests of the route group",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/usage": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Daily rollups (UTC) of the authenticated requests of each user and service account: requests, 4xx and\n5xx responses, error rate and bytes read and written, e.g. for internal chargeback or to spot abusive\nclients. The counts are metered by every replica and flushed every USAGE_FLUSH_INTERVAL seconds.",
+                "produces": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Usage per user and service account",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "First day, YYYY-MM-DD (default: 29 days before to)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last day, YYYY-MM-DD (default: today)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Username or client ID",
+                        "name": "principal",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "user or service",
+                        "name": "account_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "json (default) or csv",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.UsageRollup"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/validation-rules": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List, create, replace and delete rules checked on create and update in addition to the struct tags,\ne.g. {\"resource\": \"example1\", \"field\": \"field2\", \"rule\": \"regex\", \"params\": \"^[a-z]+$\", \"enabled\": true}.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Manage validation rules",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Rule ID (for PUT and DELETE)",
+                        "name": "id",
+                        "in": "path"
+                    },
+                    {
+                        "description": "Rule to store (for POST and PUT)",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.ValidationRule"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ValidationRule"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List, create, replace and delete rules checked on create and update in addition to the struct tags,\ne.g. {\"resource\": \"example1\", \"field\": \"field2\", \"rule\": \"regex\", \"params\": \"^[a-z]+$\", \"enabled\": true}.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Manage validation rules",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Rule ID (for PUT and DELETE)",
+                        "name": "id",
+                        "in": "path"
+                    },
+                    {
+                        "description": "Rule to store (for POST and PUT)",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.ValidationRule"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ValidationRule"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/validation-rules/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List, create, replace and delete rules checked on create and update in addition to the struct tags,\ne.g. {\"resource\": \"example1\", \"field\": \"field2\", \"rule\": \"regex\", \"params\": \"^[a-z]+$\", \"enabled\": true}.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Manage validation rules",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Rule ID (for PUT and DELETE)",
+                        "name": "id",
+                        "in": "path"
+                    },
+                    {
+                        "description": "Rule to store (for POST and PUT)",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.ValidationRule"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ValidationRule"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List, create, replace and delete rules checked on create and update in addition to the struct tags,\ne.g. {\"resource\": \"example1\", \"field\": \"field2\", \"rule\": \"regex\", \"params\": \"^[a-z]+$\", \"enabled\": true}.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Manage validation rules",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Rule ID (for PUT and DELETE)",
+                        "name": "id",
+                        "in": "path"
+                    },
+                    {
+                        "description": "Rule to store (for POST and PUT)",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.ValidationRule"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ValidationRule"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/announcements": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Active announcements (e.g. maintenance notices). Admins can pass all=true to include scheduled and expired ones, and manage them with POST/PUT /announcements, PATCH/DELETE /announcements/{id}.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "announcements"
+                ],
+                "summary": "List announcements",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Include inactive announcements (admins only)",
+                        "name": "all",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Announcement"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/bootstrap": {
+            "post": {
+                "description": "Creates the first admin of a database without admin, with the one-time token of BOOTSTRAP_TOKEN or\nprinted at startup, and returns a JWT for it. The endpoint disables itself once an admin exists.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "authentication"
+                ],
+                "summary": "Create the first admin",
+                "parameters": [
+                    {
+                        "description": "Bootstrap token and admin account",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.BootstrapRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.JWTResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/exports/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get the status and progress of an export: records exported and files written. Once done, each file has\na signed download link, valid until the export expires (EXPORT_TTL). Users see their own exports, admins\nevery export.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "exports"
+                ],
+                "summary": "Get an export",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Export ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ExportJob"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/exports/{id}/parts/{number}": {
+            "get": {
+                "description": "Download a file of an export. The signed link is the authorization, so download managers can use it\nwithout a token. Range requests resume interrupted downloads (206 with Content-Range), and the ETag is the\nSHA-256 of the file, for If-Range.",
+                "produces": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "tags": [
+                    "exports"
+                ],
+                "summary": "Download an export file",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Export ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "File number, starting at 1",
+                        "name": "number",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Expiry of the link (Unix time)",
+                        "name": "expires",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Signature of the link",
+                        "name": "signature",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Byte range to download, e.g. bytes=1048576-",
+                        "name": "Range",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "206": {
+                        "description": "Partial Content",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/ext/{service}/": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Forwards the request to the upstream service configured in PROXY_SERVICES, replacing the bearer token with signed X-Auth-* identity headers. The path after /ext/{service} is sent to the service.",
+                "tags": [
+                    "proxy"
+                ],
+                "summary": "Proxy to auxiliary services",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Service name",
+                        "name": "service",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Response of the service",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Forwards the request to the upstream service configured in PROXY_SERVICES, replacing the bearer token with signed X-Auth-* identity headers. The path after /ext/{service} is sent to the service.",
+                "tags": [
+                    "proxy"
+                ],
+                "summary": "Proxy to auxiliary services",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Service name",
+                        "name": "service",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Response of the service",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Forwards the request to the upstream service configured in PROXY_SERVICES, replacing the bearer token with signed X-Auth-* identity headers. The path after /ext/{service} is sent to the service.",
+                "tags": [
+                    "proxy"
+                ],
+                "summary": "Proxy to auxiliary services",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Service name",
+                        "name": "service",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Response of the service",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Forwards the request to the upstream service configured in PROXY_SERVICES, replacing the bearer token with signed X-Auth-* identity headers. The path after /ext/{service} is sent to the service.",
+                "tags": [
+                    "proxy"
+                ],
+                "summary": "Proxy to auxiliary services",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Service name",
+                        "name": "service",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Response of the service",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/flags": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "State of every feature flag for the authenticated user, as a map of flag name to boolean.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "flags"
+                ],
+                "summary": "Evaluate feature flags",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "boolean"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/healthz": {
+            "get": {
+                "description": "/healthz reports that the process is alive, /readyz reports whether the database is reachable, and\nthe state of the dependencies monitored with /admin/dependencies. A dependency down turns the status\nto \"degraded\" without failing the probe.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Liveness and readiness probes",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.HealthResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/models.HealthResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/hooks/{name}": {
+            "post": {
+                "description": "Stores the records of a JSON payload POSTed by a third-party service. The payload is authenticated by\nits signature instead of a token: the HMAC-SHA256 of the body with the secret of the hook, in its\nsignature_header (default X-Signature-256) after its signature_prefix (e.g. \"sha256=\"), hex or base64.\nFailed records (invalid, over the quota, existing on create...) are listed in \"failed\" without stopping\nthe others, and summarized in the last_error of the hook.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "hooks"
+                ],
+                "summary": "Receive an inbound webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Name of the hook",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Signature of the payload (the header of the hook)",
+                        "name": "X-Signature-256",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.HookResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/inbound/mailgun": {
+            "post": {
+                "description": "Converts an email forwarded by a Mailgun inbound route (forward action) into a record, by the email\nrules. The request is authenticated by its signature instead of a token: the hex HMAC-SHA256 of\ntimestamp+token with MAILGUN_SIGNING_KEY, at most 15 minutes old. Answers 404 without the key. An email\nno rule matches, or whose record fails, is still accepted (200) with the outcome, not to be retried.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "hooks"
+                ],
+                "summary": "Receive an email forwarded by Mailgun",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Timestamp of the signature",
+                        "name": "timestamp",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Random token of the signature",
+                        "name": "token",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Signature",
+                        "name": "signature",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.EmailIngestResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get the status and progress of a bulk import: records processed, created and failed, and the errors\nof the first 100 failed records. Users see their own jobs, admins every job.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Get a bulk import job",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.BulkJob"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/login": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Login using username and password, and return a JWT token for authorized access\nAfter LOGIN_CHALLENGE_AFTER failed logins from a client IP within LOGIN_FAILURE_WINDOW seconds, the\nlogins need the token of a solved challenge (CHALLENGE_PROVIDER: hcaptcha, recaptcha or turnstile) in\nX-Challenge-Token, and answer 401 with the provider and site key without it; after LOGIN_BLOCK_AFTER\nfailures they answer 429 until the window ends. A successful login clears the failures.\nWith remember_me, the response also has a device token, only exchangeable for new session tokens at\nPOST /login/device for DEVICE_TOKEN_TTL days, and revocable with DELETE /me/devices/{id}.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "authentication"
+                ],
+                "summary": "Login and generate JWT token",
+                "parameters": [
+                    {
+                        "description": "Login request with username and password",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.LoginRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Token of the solved challenge, once required",
+                        "name": "X-Challenge-Token",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.JWTResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized, or challenge required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ChallengeResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many failed logins",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "The challenge cannot be verified",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/login/device": {
+            "post": {
+                "description": "Exchanges the device token returned by a login with remember_me for a fresh session token with the\ncurrent role of the user. Device tokens are only accepted here, not by the API routes. The failures\ncount towards the login throttling of the client IP.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "authentication"
+                ],
+                "summary": "Login with a device token",
+                "parameters": [
+                    {
+                        "description": "Device token",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.DeviceLoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.JWTResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/devices": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Lists the devices with a device token of the current user, and revokes one or all of them. A revoked\ndevice token can no longer be exchanged; the session tokens already issued stay valid until they\nexpire.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "authentication"
+                ],
+                "summary": "Remembered devices",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.DeviceToken"
+                            }
+                        }
+                    },
+                    "204": {
+                        "description": "Device revoked"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Lists the devices with a device token of the current user, and revokes one or all of them. A revoked\ndevice token can no longer be exchanged; the session tokens already issued stay valid until they\nexpire.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "authentication"
+                ],
+                "summary": "Remembered devices",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.DeviceToken"
+                            }
+                        }
+                    },
+                    "204": {
+                        "description": "Device revoked"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/devices/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Lists the devices with a device token of the current user, and revokes one or all of them. A revoked\ndevice token can no longer be exchanged; the session tokens already issued stay valid until they\nexpire.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "authentication"
+                ],
+                "summary": "Remembered devices",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Device ID (for DELETE /me/devices/{id})",
+                        "name": "id",
+                        "in": "path"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.DeviceToken"
+                            }
+                        }
+                    },
+                    "204": {
+                        "description": "Device revoked"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/favorites": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Records pinned by the authenticated user, most recent first. With expand=true, each favorite also holds\nits record; records deleted since, or not visible to the user, have none.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "preferences"
+                ],
+                "summary": "User favorites",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Include the pinned records (for GET)",
+                        "name": "expand",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.UserFavorite"
+                            }
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.UserFavorite"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/favorites/{resource}/{id}": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Records pinned by the authenticated user, most recent first. With expand=true, each favorite also holds\nits record; records deleted since, or not visible to the user, have none.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "preferences"
+                ],
+                "summary": "User favorites",
+                "parameters": [
+                    {
+                        "enum": [
+                            "example1",
+                            "example2",
+                            "exampleRelational"
+                        ],
+                        "type": "string",
+                        "description": "Resource type (for POST and DELETE)",
+                        "name": "resource",
+                        "in": "path"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Resource ID (for POST and DELETE)",
+                        "name": "id",
+                        "in": "path"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Include the pinned records (for GET)",
+                        "name": "expand",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.UserFavorite"
+                            }
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.UserFavorite"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Records pinned by the authenticated user, most recent first. With expand=true, each favorite also holds\nits record; records deleted since, or not visible to the user, have none.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "preferences"
+                ],
+                "summary": "User favorites",
+                "parameters": [
+                    {
+                        "enum": [
+                            "example1",
+                            "example2",
+                            "exampleRelational"
+                        ],
+                        "type": "string",
+                        "description": "Resource type (for POST and DELETE)",
+                        "name": "resource",
+                        "in": "path"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Resource ID (for POST and DELETE)",
+                        "name": "id",
+                        "in": "path"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Include the pinned records (for GET)",
+                        "name": "expand",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.UserFavorite"
+                            }
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.UserFavorite"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/notifications": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Notifications sent to the authenticated user by the server hooks: a record assigned to them\n(record_assigned) and, for admins, a notification rule that could not be delivered (webhook_failed).\nGET returns the 100 most recent; the read endpoints return the remaining unread count.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "preferences"
+                ],
+                "summary": "User notification inbox",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Only return the unread notifications (for GET /me/notifications)",
+                        "name": "unread",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.UnreadCount"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/notifications/read": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Notifications sent to the authenticated user by the server hooks: a record assigned to them\n(record_assigned) and, for admins, a notification rule that could not be delivered (webhook_failed).\nGET returns the 100 most recent; the read endpoints return the remaining unread count.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "preferences"
+                ],
+                "summary": "User notification inbox",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Only return the unread notifications (for GET /me/notifications)",
+                        "name": "unread",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.UnreadCount"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/notifications/unread-count": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Notifications sent to the authenticated user by the server hooks: a record assigned to them\n(record_assigned) and, for admins, a notification rule that could not be delivered (webhook_failed).\nGET returns the 100 most recent; the read endpoints return the remaining unread count.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "preferences"
+                ],
+                "summary": "User notification inbox",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Only return the unread notifications (for GET /me/notifications)",
+                        "name": "unread",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.UnreadCount"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/notifications/{id}/read": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Notifications sent to the authenticated user by the server hooks: a record assigned to them\n(record_assigned) and, for admins, a notification rule that could not be delivered (webhook_failed).\nGET returns the 100 most recent; the read endpoints return the remaining unread count.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "preferences"
+                ],
+                "summary": "User notification inbox",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Notification ID (for POST /me/notifications/{id}/read)",
+                        "name": "id",
+                        "in": "path"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only return the unread notifications (for GET /me/notifications)",
+                        "name": "unread",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.UnreadCount"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/preferences": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Key-value UI settings of the authenticated user (e.g. page size, theme). PUT merges the given keys; keys set to null are removed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "preferences"
+                ],
+                "summary": "User preferences",
+                "parameters": [
+                    {
+                        "description": "Preferences to store (for PUT)",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Key-value UI settings of the authenticated user (e.g. page size, theme). PUT merges the given keys; keys set to null are removed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "preferences"
+                ],
+                "summary": "User preferences",
+                "parameters": [
+                    {
+                        "description": "Preferences to store (for PUT)",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/step-up": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Checks the password of the current user again and returns a fresh token. With STEP_UP_MAX_AGE, the\ndestructive actions (deleting, merging or restoring records, granting roles, importing users) answer\n401 with models.StepUpResponse to tokens issued longer ago. The wrong passwords count towards the login\nthrottling of the client IP.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "authentication"
+                ],
+                "summary": "Step-up authentication",
+                "parameters": [
+                    {
+                        "description": "Password of the user, e.g. {\\",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.JWTResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/verify-email": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Sends a new verification link. An optional email in the body replaces the address of the user, which then has to be verified.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "authentication"
+                ],
+                "summary": "Resend email verification",
+                "parameters": [
+                    {
+                        "description": "Optional new email, e.g. {\\",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/oauth/token": {
+            "post": {
+                "description": "OAuth 2.0 client credentials grant. Send grant_type=client_credentials with client_id and client_secret (or HTTP Basic authentication); the optional space-separated scope must be a subset of the scopes of the account.",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "authentication"
+                ],
+                "summary": "Issue a service account token",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Must be client_credentials",
+                        "name": "grant_type",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client ID of the service account",
+                        "name": "client_id",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client secret of the service account",
+                        "name": "client_secret",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Requested scopes, space-separated",
+                        "name": "scope",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.TokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/openapi.json": {
+            "get": {
+                "description": "The Swagger 2.0 document of the routes registered on this instance, with a path per resource instead of\n/{resource}, typed with the model of the resource, and an operationId per operation, to generate typed\nclients from (see ` + "`" + `make clients` + "`" + `). Built once, on the first request.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "documentation"
+                ],
+                "summary": "OpenAPI document of the deployment",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/query": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Run up to 20 named reads concurrently, each one as GET /{resource} with the same filters, sort, fields\nand pagination, e.g. {\"latest\": {\"resource\": \"example1\", \"sort\": [\"-field1\"], \"per_page\": 5},\n\"todo\": {\"resource\": \"example2\", \"filters\": {\"field2\": \"todo\"}}}. The results are keyed by name and\nhold the status, records and total of each read, so one failing read does not fail the others.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "resources"
+                ],
+                "summary": "Batch reads",
+                "parameters": [
+                    {
+                        "description": "Reads keyed by name",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "$ref": "#/definitions/models.QueryOperation"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "$ref": "#/definitions/models.QueryResult"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Too many concurrent requests of the route group",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "description": "/healthz reports that the process is alive, /readyz reports whether the database is reachable, and\nthe state of the dependencies monitored with /admin/dependencies. A dependency down turns the status\nto \"degraded\" without failing the probe.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Liveness and readiness probes",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.HealthResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/models.HealthResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/register": {
+            "post": {
+                "description": "POST /register creates a user with the \"user\" role and emails a verification link when an email is given. It is only served with ALLOW_REGISTRATION=true. GET /verify confirms the email with the token of the link.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "authentication"
+                ],
+                "summary": "Register and verify email",
+                "parameters": [
+                    {
+                        "description": "New user (for POST /register)",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.RegisterRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Verification token (for GET /verify)",
+                        "name": "token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/resources": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Names of the resources that can be listed and filtered with GET /{resource}.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "views"
+                ],
+                "summary": "List resources",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/sync": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Pull the changes of the subsets of resources a client mirrors and push its local changes, e.g.\n{\"token\": \"...\", \"resources\": {\"example1\": {\"filters\": {\"field2\": \"foo\"}}}, \"changes\": [{\"resource\":\n\"example1\", \"id\": \"a\", \"op\": \"upsert\", \"record\": {\"field2\": \"foo\"}, \"base_updated_at\": \"...\"}]}.\nSend the token of the response with the next sync. Deleted records, and records leaving the subset,\nare returned as tombstones in \"deleted\". \"reset\" asks the client to reload a resource. Pushed changes\n(admins only) are a conflict when the record changed on the server after base_updated_at; the result\nholds a hint, the server copy and the fields that differ.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "resources"
+                ],
+                "summary": "Sync offline-first clients",
+                "parameters": [
+                    {
+                        "description": "Token, mirrored subsets and local changes",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.SyncRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SyncResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "ApiKeyAuth.": []
+                    }
+                ],
+                "description": "Setup routes for administrative resources like users, servers, employees, etc.",
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Setup admin routes",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Fail with 412 if the record was modified after this HTTP date",
+                        "name": "If-Unmodified-Since",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "401": {
+                        "description": "Step-up authentication required (DELETE)",
+                        "schema": {
+                            "$ref": "#/definitions/models.StepUpResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/search": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Searches the users by username and email patterns (\"*\" matches any characters), role and creation date,\nordered by username and paginated. Users are returned without their credentials.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Search users",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Username pattern, e.g. al*",
+                        "name": "username",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Email pattern, e.g. *@example.com",
+                        "name": "email",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "admin",
+                            "user"
+                        ],
+                        "type": "string",
+                        "description": "Role",
+                        "name": "role",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only users created at or after this RFC 3339 timestamp",
+                        "name": "created_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only users created before this RFC 3339 timestamp",
+                        "name": "created_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number, starting at 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 50, max 1000)",
+                        "name": "per_page",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.UserSummary"
+                            }
+                        },
+                        "headers": {
+                            "X-Total-Count": {
+                                "type": "integer",
+                                "description": "Number of matching users"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/verify": {
+            "get": {
+                "description": "POST /register creates a user with the \"user\" role and emails a verification link when an email is given. It is only served with ALLOW_REGISTRATION=true. GET /verify confirms the email with the token of the link.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "authentication"
+                ],
+                "summary": "Register and verify email",
+                "parameters": [
+                    {
+                        "description": "New user (for POST /register)",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.RegisterRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Verification token (for GET /verify)",
+                        "name": "token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/views": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List, create or replace, and delete named views. A view is executed with GET /{resource}?view=name.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "views"
+                ],
+                "summary": "Manage saved queries",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Limit the list to one resource",
+                        "name": "resource",
+                        "in": "query"
+                    },
+                    {
+                        "description": "View to store (for POST)",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.SavedQueryRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.SavedQuery"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List, create or replace, and delete named views. A view is executed with GET /{resource}?view=name.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "views"
+                ],
+                "summary": "Manage saved queries",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Limit the list to one resource",
+                        "name": "resource",
+                        "in": "query"
+                    },
+                    {
+                        "description": "View to store (for POST)",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.SavedQueryRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.SavedQuery"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/views/{name}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List, create or replace, and delete named views. A view is executed with GET /{resource}?view=name.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "views"
+                ],
+                "summary": "Manage saved queries",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Limit the list to one resource",
+                        "name": "resource",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "View name (for DELETE)",
+                        "name": "name",
+                        "in": "path"
+                    },
+                    {
+                        "description": "View to store (for POST)",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.SavedQueryRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.SavedQuery"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/{resource}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Setup routes for CRUD operations on resources like users, servers, employees, etc.\nResources registered in protoFields answer application/x-protobuf when asked with Accept; their messages\nare described by the .proto file of GET /{resource}/proto.",
+                "produces": [
+                    "application/json",
+                    "application/x-protobuf"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Setup GET resource routes",
+                "parameters": [
+                    {
+                        "enum": [
+                            "example1",
+                            "example2",
+                            "exampleRelational"
+                        ],
+                        "type": "string",
+                        "description": "Resource type",
+                        "name": "resource",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number, starting at 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Records per page (default 50, max 1000)",
+                        "name": "per_page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Indent the JSON response (every endpoint accepts it)",
+                        "name": "pretty",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a previous list: 304 if the list did not change",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "With If-None-Match, \\",
+                        "name": "A-IM",
+                        "in": "header"
+                    },
+                    {
+                        "enum": [
+                            "read_committed",
+                            "repeatable_read",
+                            "serializable"
+                        ],
+                        "type": "string",
+                        "description": "Read the list in a read-only transaction at this level",
+                        "name": "X-Isolation-Level",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated fields compared by /{resource}/duplicates",
+                        "name": "fields",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "226": {
+                        "description": "IM Used",
+                        "schema": {
+                            "$ref": "#/definitions/models.ChangeSet"
+                        }
+                    },
+                    "304": {
+                        "description": "Not Modified"
+                    },
+                    "503": {
+                        "description": "Too many concurrent requests of the route group, or the database is busy",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Setup routes for administrative resources like users, servers, employees, etc.\nRoles other than admin may PATCH the fields listed in writableFields (e.g. \"user\" may change example1.field2\nand example1.meta). Changing another field answers 403 with the forbidden fields; fields sent with their\nstored value are not changes.\nPOST accepts an application/x-ndjson (or JSON Lines) body with one record per line, created in chunks\nwhile it is read; it answers 200 with the models.IngestResult, the records created and the failed ones.",
+                "consumes": [
+                    "application/json",
+                    "application/x-ndjson"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Setup admin routes",
+                "parameters": [
+                    {
+                        "enum": [
+                            "user",
+                            "example1",
+                            "example2",
+                            "exampleRelational",
+                            "announcements"
+                        ],
+                        "type": "string",
+                        "description": "Resource type",
+                        "name": "resource",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Fail with 412 if the record was modified after this HTTP date (PUT and PATCH)",
+                        "name": "If-Unmodified-Since",
+                        "in": "header"
+                    },
+                    {
+                        "description": "JSON request body for POST and PATCH operations",
+                        "name": "defaultRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.DefaultRequest"
+                        }
+                    },
+                    {
+                        "description": "Example1 object to create",
+                        "name": "example1",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.Example1"
+                        }
+                    },
+                    {
+                        "description": "Example2 object to create",
+                        "name": "example2",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.Example2"
+                        }
+                    },
+                    {
+                        "description": "Announcement object to create",
+                        "name": "announcements",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.Announcement"
+                        }
+                    },
+                    {
+                        "description": "Example2 object to create",
+                        "name": "example2",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.Example2"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.IngestResult"
+                        }
+                    },
+                    "401": {
+                        "description": "Step-up authentication required (granting a role, ingesting users)",
+                        "schema": {
+                            "$ref": "#/definitions/models.StepUpResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ForbiddenFieldsResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Setup routes for administrative resources like users, servers, employees, etc.\nRoles other than admin may PATCH the fields listed in writableFields (e.g. \"user\" may change example1.field2\nand example1.meta). Changing another field answers 403 with the forbidden fields; fields sent with their\nstored value are not changes.\nPOST accepts an application/x-ndjson (or JSON Lines) body with one record per line, created in chunks\nwhile it is read; it answers 200 with the models.IngestResult, the records created and the failed ones.",
+                "consumes": [
+                    "application/json",
+                    "application/x-ndjson"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Setup admin routes",
+                "parameters": [
+                    {
+                        "enum": [
+                            "user",
+                            "example1",
+                            "example2",
+                            "exampleRelational",
+                            "announcements"
+                        ],
+                        "type": "string",
+                        "description": "Resource type",
+                        "name": "resource",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Fail with 412 if the record was modified after this HTTP date (PUT and PATCH)",
+                        "name": "If-Unmodified-Since",
+                        "in": "header"
+                    },
+                    {
+                        "description": "JSON request body for POST and PATCH operations",
+                        "name": "defaultRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.DefaultRequest"
+                        }
+                    },
+                    {
+                        "description": "Example1 object to create",
+                        "name": "example1",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.Example1"
+                        }
+                    },
+                    {
+                        "description": "Example2 object to create",
+                        "name": "example2",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.Example2"
+                        }
+                    },
+                    {
+                        "description": "Announcement object to create",
+                        "name": "announcements",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.Announcement"
+                        }
+                    },
+                    {
+                        "description": "Example2 object to create",
+                        "name": "example2",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.Example2"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.IngestResult"
+                        }
+                    },
+                    "401": {
+                        "description": "Step-up authentication required (granting a role, ingesting users)",
+                        "schema": {
+                            "$ref": "#/definitions/models.StepUpResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ForbiddenFieldsResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/{resource}/changes": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Records created, updated or deleted after the \"since\" watermark, oldest first. Pass the returned\nwatermark as \"since\" of the next request. With \"wait\", the request waits up to that many seconds for\na change (long polling). Users other than admins get the records no longer published as deleted.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "resources"
+                ],
+                "summary": "Changes of a resource",
+                "parameters": [
+                    {
+                        "enum": [
+                            "example1",
+                            "example2"
+                        ],
+                        "type": "string",
+                        "description": "Resource type",
+                        "name": "resource",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC 3339 watermark",
+                        "name": "since",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Seconds to wait for a change (0 to 30)",
+                        "name": "wait",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ChangeSet"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "Gone",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/{resource}/duplicates": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Setup routes for CRUD operations on resources like users, servers, employees, etc.\nResources registered in protoFields answer application/x-protobuf when asked with Accept; their messages\nare described by the .proto file of GET /{resource}/proto.",
+                "produces": [
+                    "application/json",
+                    "application/x-protobuf"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Setup GET resource routes",
+                "parameters": [
+                    {
+                        "enum": [
+                            "example1",
+                            "example2",
+                            "exampleRelational"
+                        ],
+                        "type": "string",
+                        "description": "Resource type",
+                        "name": "resource",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number, starting at 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Records per page (default 50, max 1000)",
+                        "name": "per_page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Indent the JSON response (every endpoint accepts it)",
+                        "name": "pretty",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a previous list: 304 if the list did not change",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "With If-None-Match, \\",
+                        "name": "A-IM",
+                        "in": "header"
+                    },
+                    {
+                        "enum": [
+                            "read_committed",
+                            "repeatable_read",
+                            "serializable"
+                        ],
+                        "type": "string",
+                        "description": "Read the list in a read-only transaction at this level",
+                        "name": "X-Isolation-Level",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated fields compared by /{resource}/duplicates",
+                        "name": "fields",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "226": {
+                        "description": "IM Used",
+                        "schema": {
+                            "$ref": "#/definitions/models.ChangeSet"
+                        }
+                    },
+                    "304": {
+                        "description": "Not Modified"
+                    },
+                    "503": {
+                        "description": "Too many concurrent requests of the route group, or the database is busy",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/{resource}/export": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Start an export of the records matching the filters and sort order of the query (as in GET /{resource}).\nAnswers 202 with the export, whose progress and, once done, signed download links are read at the URL\nof the Location header. The records are written to files of at most EXPORT_PART_RECORDS records, each a\nJSON array or a CSV file with a header row, both accepted by POST /{resource}/import.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Export records",
+                "parameters": [
+                    {
+                        "enum": [
+                            "user",
+                            "example1",
+                            "example2",
+                            "exampleRelational",
+                            "announcements"
+                        ],
+                        "type": "string",
+                        "description": "Resource type",
+                        "name": "resource",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "csv"
+                        ],
+                        "type": "string",
+                        "description": "Format of the files",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Replace the personal data of the records (see POST /admin/export)",
+                        "name": "anonymize",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/models.ExportJob"
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "URL of the export"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Too many concurrent requests of the route group",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/{resource}/import": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Accept a JSON array or a CSV file (header row with the JSON field names) of records, and create them in\na background job, in chunks of DB_BATCH_SIZE records. Answers 202 with the job, whose progress (records\nprocessed, created and failed, with their errors) is polled at the URL of the Location header.",
+                "consumes": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Bulk import records",
+                "parameters": [
+                    {
+                        "enum": [
+                            "user",
+                            "example1",
+                            "example2",
+                            "exampleRelational",
+                            "announcements"
+                        ],
+                        "type": "string",
+                        "description": "Resource type",
+                        "name": "resource",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "JSON array of records, or CSV file",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.DefaultRequest"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/models.BulkJob"
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "URL of the job"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Step-up authentication required (users)",
+                        "schema": {
+                            "$ref": "#/definitions/models.StepUpResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Too many concurrent requests of the route group",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/{resource}/merge": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Merge records into a survivor: its empty fields are filled from the merged records, the rows of other\nresources referencing them are re-pointed to it, and the merged records are deleted, in one transaction.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Merge records",
+                "parameters": [
+                    {
+                        "enum": [
+                            "example1",
+                            "example2",
+                            "exampleRelational"
+                        ],
+                        "type": "string",
+                        "description": "Resource type",
+                        "name": "resource",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Survivor and merged IDs",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.MergeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MergeResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Step-up authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.StepUpResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/{resource}/proto": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Setup routes for CRUD operations on resources like users, servers, employees, etc.\nResources registered in protoFields answer application/x-protobuf when asked with Accept; their messages\nare described by the .proto file of GET /{resource}/proto.",
+                "produces": [
+                    "application/json",
+                    "application/x-protobuf"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Setup GET resource routes",
+                "parameters": [
+                    {
+                        "enum": [
+                            "example1",
+                            "example2",
+                            "exampleRelational"
+                        ],
+                        "type": "string",
+                        "description": "Resource type",
+                        "name": "resource",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number, starting at 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Records per page (default 50, max 1000)",
+                        "name": "per_page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Indent the JSON response (every endpoint accepts it)",
+                        "name": "pretty",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a previous list: 304 if the list did not change",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "With If-None-Match, \\",
+                        "name": "A-IM",
+                        "in": "header"
+                    },
+                    {
+                        "enum": [
+                            "read_committed",
+                            "repeatable_read",
+                            "serializable"
+                        ],
+                        "type": "string",
+                        "description": "Read the list in a read-only transaction at this level",
+                        "name": "X-Isolation-Level",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated fields compared by /{resource}/duplicates",
+                        "name": "fields",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "226": {
+                        "description": "IM Used",
+                        "schema": {
+                            "$ref": "#/definitions/models.ChangeSet"
+                        }
+                    },
+                    "304": {
+                        "description": "Not Modified"
+                    },
+                    "503": {
+                        "description": "Too many concurrent requests of the route group, or the database is busy",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/{resource}/schema": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Setup routes for CRUD operations on resources like users, servers, employees, etc.\nResources registered in protoFields answer application/x-protobuf when asked with Accept; their messages\nare described by the .proto file of GET /{resource}/proto.",
+                "produces": [
+                    "application/json",
+                    "application/x-protobuf"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Setup GET resource routes",
+                "parameters": [
+                    {
+                        "enum": [
+                            "example1",
+                            "example2",
+                            "exampleRelational"
+                        ],
+                        "type": "string",
+                        "description": "Resource type",
+                        "name": "resource",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number, starting at 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Records per page (default 50, max 1000)",
+                        "name": "per_page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Indent the JSON response (every endpoint accepts it)",
+                        "name": "pretty",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a previous list: 304 if the list did not change",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "With If-None-Match, \\",
+                        "name": "A-IM",
+                        "in": "header"
+                    },
+                    {
+                        "enum": [
+                            "read_committed",
+                            "repeatable_read",
+                            "serializable"
+                        ],
+                        "type": "string",
+                        "description": "Read the list in a read-only transaction at this level",
+                        "name": "X-Isolation-Level",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated fields compared by /{resource}/duplicates",
+                        "name": "fields",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "226": {
+                        "description": "IM Used",
+                        "schema": {
+                            "$ref": "#/definitions/models.ChangeSet"
+                        }
+                    },
+                    "304": {
+                        "description": "Not Modified"
+                    },
+                    "503": {
+                        "description": "Too many concurrent requests of the route group, or the database is busy",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/{resource}/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Setup routes for CRUD operations on resources like users, servers, employees, etc.\nResources registered in protoFields answer application/x-protobuf when asked with Accept; their messages\nare described by the .proto file of GET /{resource}/proto.",
+                "produces": [
+                    "application/json",
+                    "application/x-protobuf"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Setup GET resource routes",
+                "parameters": [
+                    {
+                        "enum": [
+                            "example1",
+                            "example2",
+                            "exampleRelational"
+                        ],
+                        "type": "string",
+                        "description": "Resource type",
+                        "name": "resource",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Resource ID (for operations on specific resources)",
+                        "name": "id",
+                        "in": "path"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number, starting at 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Records per page (default 50, max 1000)",
+                        "name": "per_page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Indent the JSON response (every endpoint accepts it)",
+                        "name": "pretty",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a previous list: 304 if the list did not change",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "With If-None-Match, \\",
+                        "name": "A-IM",
+                        "in": "header"
+                    },
+                    {
+                        "enum": [
+                            "read_committed",
+                            "repeatable_read",
+                            "serializable"
+                        ],
+                        "type": "string",
+                        "description": "Read the list in a read-only transaction at this level",
+                        "name": "X-Isolation-Level",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated fields compared by /{resource}/duplicates",
+                        "name": "fields",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "226": {
+                        "description": "IM Used",
+                        "schema": {
+                            "$ref": "#/definitions/models.ChangeSet"
+                        }
+                    },
+                    "304": {
+                        "description": "Not Modified"
+                    },
+                    "503": {
+                        "description": "Too many concurrent requests of the route group, or the database is busy",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "ApiKeyAuth.": []
+                    }
+                ],
+                "description": "Setup routes for administrative resources like users, servers, employees, etc.",
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Setup admin routes",
+                "parameters": [
+                    {
+                        "enum": [
+                            "user",
+                            "example1",
+                            "example2",
+                            "exampleRelational",
+                            "announcements"
+                        ],
+                        "type": "string",
+                        "description": "Resource type",
+                        "name": "resource",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Resource ID (for operations on specific resources)",
+                        "name": "id",
+                        "in": "path"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Fail with 412 if the record was modified after this HTTP date",
+                        "name": "If-Unmodified-Since",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "401": {
+                        "description": "Step-up authentication required (DELETE)",
+                        "schema": {
+                            "$ref": "#/definitions/models.StepUpResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Setup routes for administrative resources like users, servers, employees, etc.\nRoles other than admin may PATCH the fields listed in writableFields (e.g. \"user\" may change example1.field2\nand example1.meta). Changing another field answers 403 with the forbidden fields; fields sent with their\nstored value are not changes.\nPOST accepts an application/x-ndjson (or JSON Lines) body with one record per line, created in chunks\nwhile it is read; it answers 200 with the models.IngestResult, the records created and the failed ones.",
+                "consumes": [
+                    "application/json",
+                    "application/x-ndjson"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Setup admin routes",
+                "parameters": [
+                    {
+                        "enum": [
+                            "user",
+                            "example1",
+                            "example2",
+                            "exampleRelational",
+                            "announcements"
+                        ],
+                        "type": "string",
+                        "description": "Resource type",
+                        "name": "resource",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Resource ID (for operations on specific resources)",
+                        "name": "id",
+                        "in": "path"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Fail with 412 if the record was modified after this HTTP date (PUT and PATCH)",
+                        "name": "If-Unmodified-Since",
+                        "in": "header"
+                    },
+                    {
+                        "description": "JSON request body for POST and PATCH operations",
+                        "name": "defaultRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.DefaultRequest"
+                        }
+                    },
+                    {
+                        "description": "Example1 object to create",
+                        "name": "example1",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.Example1"
+                        }
+                    },
+                    {
+                        "description": "Example2 object to create",
+                        "name": "example2",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.Example2"
+                        }
+                    },
+                    {
+                        "description": "Announcement object to create",
+                        "name": "announcements",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.Announcement"
+                        }
+                    },
+                    {
+                        "description": "Example2 object to create",
+                        "name": "example2",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.Example2"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.IngestResult"
+                        }
+                    },
+                    "401": {
+                        "description": "Step-up authentication required (granting a role, ingesting users)",
+                        "schema": {
+                            "$ref": "#/definitions/models.StepUpResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ForbiddenFieldsResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/{resource}/{id}/lock": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Lock a record so other admins get 423 Locked when updating, deleting or transitioning it until the\nlock is released or expires (RECORD_LOCK_TTL). Locking again renews the lock. Unlocking a record\nlocked by another admin requires force=true.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Lock a record while editing it",
+                "parameters": [
+                    {
+                        "enum": [
+                            "user",
+                            "example1",
+                            "example2",
+                            "exampleRelational",
+                            "announcements"
+                        ],
+                        "type": "string",
+                        "description": "Resource type",
+                        "name": "resource",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Resource ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Release a lock held by another admin (unlock only)",
+                        "name": "force",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.RecordLock"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "423": {
+                        "description": "Locked",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Lock a record so other admins get 423 Locked when updating, deleting or transitioning it until the\nlock is released or expires (RECORD_LOCK_TTL). Locking again renews the lock. Unlocking a record\nlocked by another admin requires force=true.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Lock a record while editing it",
+                "parameters": [
+                    {
+                        "enum": [
+                            "user",
+                            "example1",
+                            "example2",
+                            "exampleRelational",
+                            "announcements"
+                        ],
+                        "type": "string",
+                        "description": "Resource type",
+                        "name": "resource",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Resource ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Release a lock held by another admin (unlock only)",
+                        "name": "force",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.RecordLock"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "423": {
+                        "description": "Locked",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/{resource}/{id}/unlock": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Lock a record so other admins get 423 Locked when updating, deleting or transitioning it until the\nlock is released or expires (RECORD_LOCK_TTL). Locking again renews the lock. Unlocking a record\nlocked by another admin requires force=true.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Lock a record while editing it",
+                "parameters": [
+                    {
+                        "enum": [
+                            "user",
+                            "example1",
+                            "example2",
+                            "exampleRelational",
+                            "announcements"
+                        ],
+                        "type": "string",
+                        "description": "Resource type",
+                        "name": "resource",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Resource ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Release a lock held by another admin (unlock only)",
+                        "name": "force",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.RecordLock"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "423": {
+                        "description": "Locked",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/{resource}/{id}/{action}": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Apply a lifecycle action: publish (draft → published), unpublish (published → draft),\narchive (draft or published → archived) or restore (archived → draft).\nUsers other than admins only see published records.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Change the lifecycle status of a record",
+                "parameters": [
+                    {
+                        "enum": [
+                            "example1"
+                        ],
+                        "type": "string",
+                        "description": "Resource type",
+                        "name": "resource",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Resource ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "publish",
+                            "unpublish",
+                            "archive",
+                            "restore"
+                        ],
+                        "type": "string",
+                        "description": "Lifecycle action",
+                        "name": "action",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Example1"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Step-up authentication required (restore)",
+                        "schema": {
+                            "$ref": "#/definitions/models.StepUpResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "423": {
+                        "description": "Locked",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "models.AccountType": {
+            "type": "string",
+            "enum": [
+                "user",
+                "service"
+            ],
+            "x-enum-varnames": [
+                "UserAccountType",
+                "ServiceAccountType"
+            ]
+        },
+        "models.Alerts": {
+            "type": "object",
+            "properties": {
+                "dependencies_down": {
+                    "description": "DependenciesDown lists the monitored dependencies that are down.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.DependencyHealth"
+                    }
+                },
+                "open_circuits": {
+                    "description": "OpenCircuits lists the outbound hosts whose requests are rejected after consecutive failures.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.OutboundHost"
+                    }
+                },
+                "slow_routes": {
+                    "description": "SlowRoutes lists the routes whose p95 latency exceeds the threshold.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SlowRoute"
+                    }
+                },
+                "table_growth": {
+                    "description": "TableGrowth lists the tables growing faster than the threshold.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TableGrowth"
+                    }
+                }
+            }
+        },
+        "models.Announcement": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "CreatedAt is the timestamp of when the announcement was created, set by the server.",
+                    "type": "string"
+                },
+                "created_by": {
+                    "description": "CreatedBy is the username of the admin who created the announcement, set by the server.",
+                    "type": "string"
+                },
+                "ends_at": {
+                    "description": "EndsAt is when the announcement stops being active. Empty means never.",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "ID is the auto-incremented primary key of the announcement.",
+                    "type": "integer"
+                },
+                "level": {
+                    "description": "Level is the severity used by front-ends to style it: \"info\", \"warning\" or \"critical\".",
+                    "type": "string",
+                    "enum": [
+                        "info",
+                        "warning",
+                        "critical"
+                    ]
+                },
+                "message": {
+                    "description": "Message is the body of the announcement.",
+                    "type": "string"
+                },
+                "starts_at": {
+                    "description": "StartsAt is when the announcement becomes active. Empty means immediately.",
+                    "type": "string"
+                },
+                "title": {
+                    "description": "Title is a short summary of the announcement.",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "description": "UpdatedAt is the timestamp of the last modification to the announcement.",
+                    "type": "string"
+                }
+            }
+        },
+        "models.AuditVerification": {
+            "type": "object",
+            "properties": {
+                "broken_id": {
+                    "description": "BrokenID is the ID of the first entry breaking the chain.",
+                    "type": "integer"
+                },
+                "entries": {
+                    "description": "Entries is the number of chained entries checked.",
+                    "type": "integer"
+                },
+                "error": {
+                    "description": "Error explains how the chain is broken.",
+                    "type": "string"
+                },
+                "head_hash": {
+                    "description": "HeadHash is the hash of the last entry checked. Recording it elsewhere\ndetects the removal of the latest entries on later checks.",
+                    "type": "string"
+                },
+                "head_id": {
+                    "description": "HeadID is the ID of the last entry checked.",
+                    "type": "integer"
+                },
+                "unchained": {
+                    "description": "Unchained is the number of entries recorded before the chain, which can't be checked.",
+                    "type": "integer"
+                },
+                "valid": {
+                    "description": "Valid is false if an entry was altered, inserted or removed.",
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.BootstrapRequest": {
+            "type": "object",
+            "required": [
+                "password",
+                "token",
+                "username"
+            ],
+            "properties": {
+                "email": {
+                    "description": "Email is the optional email address of the admin, considered verified.",
+                    "type": "string"
+                },
+                "password": {
+                    "description": "Password is the admin's password, which will be hashed before storage.",
+                    "type": "string"
+                },
+                "token": {
+                    "description": "Token is the bootstrap token, from BOOTSTRAP_TOKEN or printed at startup.",
+                    "type": "string"
+                },
+                "username": {
+                    "description": "Username is the unique identifier for the admin.",
+                    "type": "string"
+                }
+            }
+        },
+        "models.BulkJob": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "description": "Created is the number of records created.",
+                    "type": "integer"
+                },
+                "created_at": {
+                    "description": "CreatedAt is the timestamp of when the job was submitted.",
+                    "type": "string"
+                },
+                "error": {
+                    "description": "Error is the reason a failed job stopped.",
+                    "type": "string"
+                },
+                "failed": {
+                    "description": "Failed is the number of records that could not be created.",
+                    "type": "integer"
+                },
+                "failures": {
+                    "description": "Failures lists the first MaxBulkJobFailures records that could not be created, with their error.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ImportFailure"
+                    }
+                },
+                "finished_at": {
+                    "description": "FinishedAt is the timestamp of when the job ended, or null while it runs.",
+                    "type": "string"
+                },
+                "format": {
+                    "description": "Format is the format of the payload: \"json\" or \"csv\", or \"generated\" for\nthe synthetic records of POST /admin/generate.",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "ID is the auto-incremented primary key of the job.",
+                    "type": "integer"
+                },
+                "owner": {
+                    "description": "Owner is the user who submitted the import, the author of the records.",
+                    "type": "string"
+                },
+                "processed": {
+                    "description": "Processed is the number of records processed so far, created or failed.",
+                    "type": "integer"
+                },
+                "resource": {
+                    "description": "Resource is the resource receiving the records (e.g. \"example1\").",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Status is the status of the job.",
+                    "enum": [
+                        "queued",
+                        "running",
+                        "done",
+                        "failed"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.BulkJobStatus"
+                        }
+                    ]
+                },
+                "total": {
+                    "description": "Total is the number of records of the payload.",
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "description": "UpdatedAt is the timestamp of the last progress of the job.",
+                    "type": "string"
+                }
+            }
+        },
+        "models.BulkJobStatus": {
+            "type": "string",
+            "enum": [
+                "queued",
+                "running",
+                "done",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "BulkJobQueued",
+                "BulkJobRunning",
+                "BulkJobDone",
+                "BulkJobFailed"
+            ]
+        },
+        "models.ChallengeResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "description": "Error is \"Challenge required\", or \"Invalid challenge\" if the token was rejected.",
+                    "type": "string"
+                },
+                "header": {
+                    "description": "Header is the header carrying the token of the solved challenge on the next login.",
+                    "type": "string"
+                },
+                "provider": {
+                    "description": "Provider is the challenge provider: \"hcaptcha\", \"recaptcha\" or \"turnstile\".",
+                    "type": "string"
+                },
+                "request_id": {
+                    "description": "RequestID identifies the request in the server logs, when available.",
+                    "type": "string"
+                },
+                "site_key": {
+                    "description": "SiteKey is the public key of the site at the provider, to render the challenge.",
+                    "type": "string"
+                }
+            }
+        },
+        "models.ChangeSet": {
+            "type": "object",
+            "properties": {
+                "changed": {
+                    "description": "Changed holds the records created or updated after the watermark, oldest first."
+                },
+                "deleted": {
+                    "description": "Deleted lists the tokenized IDs of the records deleted after the watermark\n(or no longer visible to the user).",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "has_more": {
+                    "description": "HasMore is true when more changes are available right away with the new watermark.",
+                    "type": "boolean"
+                },
+                "watermark": {
+                    "description": "Watermark is the value of \"since\" for the next request.",
+                    "type": "string"
+                }
+            }
+        },
+        "models.ColumnStats": {
+            "type": "object",
+            "properties": {
+                "cardinality": {
+                    "description": "Cardinality is the number of distinct non-null values.",
+                    "type": "integer"
+                },
+                "column": {
+                    "description": "Column is the name of the column.",
+                    "type": "string"
+                },
+                "max": {
+                    "description": "Max is the largest value of the column.",
+                    "type": "string"
+                },
+                "min": {
+                    "description": "Min is the smallest value of the column.",
+                    "type": "string"
+                },
+                "nulls": {
+                    "description": "Nulls is the number of rows where the column is null.",
+                    "type": "integer"
+                },
+                "top_values": {
+                    "description": "TopValues lists the most frequent values found in the sampled rows.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ValueCount"
+                    }
+                },
+                "type": {
+                    "description": "Type is the database type of the column.",
+                    "type": "string"
+                }
+            }
+        },
+        "models.ConfigAction": {
+            "type": "string",
+            "enum": [
+                "created",
+                "updated",
+                "deleted"
+            ],
+            "x-enum-varnames": [
+                "ConfigCreated",
+                "ConfigUpdated",
+                "ConfigDeleted"
+            ]
+        },
+        "models.ConfigChange": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "description": "Action is \"created\", \"updated\" or \"deleted\".",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.ConfigAction"
+                        }
+                    ]
+                },
+                "actor": {
+                    "description": "Actor is the username of the admin or service account who made the change.",
+                    "type": "string"
+                },
+                "actor_type": {
+                    "description": "ActorType tells users and service accounts apart.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.AccountType"
+                        }
+                    ]
+                },
+                "after": {
+                    "description": "After is the JSON-encoded configuration after the change, empty if it was deleted.",
+                    "type": "object"
+                },
+                "before": {
+                    "description": "Before is the JSON-encoded configuration before the change, empty if it was created.",
+                    "type": "object"
+                },
+                "changed_at": {
+                    "description": "ChangedAt is the timestamp of the change.",
+                    "type": "string"
+                },
+                "client_ip": {
+                    "description": "ClientIP is the IP address the change was requested from.",
+                    "type": "string"
+                },
+                "hash": {
+                    "description": "Hash is the hex-encoded SHA-256 of the entry and PrevHash (see ComputeHash).\nIt is empty for the entries recorded before the chain.",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "ID is the auto-incremented primary key of the entry.",
+                    "type": "integer"
+                },
+                "key": {
+                    "description": "Key identifies the changed configuration within its kind: the key of a setting,\nthe name of a flag, the client ID of a service account, a username or a rule ID.",
+                    "type": "string"
+                },
+                "kind": {
+                    "description": "Kind is the kind of the changed configuration (e.g. \"feature_flag\").",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.ConfigKind"
+                        }
+                    ]
+                },
+                "prev_hash": {
+                    "description": "PrevHash is the Hash of the previous entry, empty for the first one.",
+                    "type": "string"
+                }
+            }
+        },
+        "models.ConfigKind": {
+            "type": "string",
+            "enum": [
+                "setting",
+                "feature_flag",
+                "validation_rule",
+                "notification_rule",
+                "service_account",
+                "quota",
+                "user_role"
+            ],
+            "x-enum-varnames": [
+                "ConfigSetting",
+                "ConfigFeatureFlag",
+                "ConfigValidationRule",
+                "ConfigNotificationRule",
+                "ConfigServiceAccount",
+                "ConfigQuota",
+                "ConfigUserRole"
+            ]
+        },
+        "models.ConfigValue": {
+            "type": "object",
+            "properties": {
+                "key": {
+                    "description": "Key is the name of the parameter, which is also the key of the setting overriding it.",
+                    "type": "string"
+                },
+                "source": {
+                    "description": "Source is where the value comes from: \"default\", \"env\" or \"settings\".",
+                    "type": "string"
+                },
+                "value": {
+                    "description": "Value is the effective value."
+                }
+            }
+        },
+        "models.CounterRepair": {
+            "type": "object",
+            "properties": {
+                "child": {
+                    "description": "Child is the table of the counted rows.",
+                    "type": "string"
+                },
+                "column": {
+                    "description": "Column is the counter column.",
+                    "type": "string"
+                },
+                "fixed": {
+                    "description": "Fixed is the number of parents whose counter was wrong.",
+                    "type": "integer"
+                },
+                "table": {
+                    "description": "Table is the table of the parent model, holding the counter.",
+                    "type": "string"
+                }
+            }
+        },
+        "models.DBHealth": {
+            "type": "object",
+            "properties": {
+                "available": {
+                    "description": "Available is false while the circuit breaker is open.",
+                    "type": "boolean"
+                },
+                "consecutive_failures": {
+                    "description": "ConsecutiveFailures is the number of failed health checks in a row.",
+                    "type": "integer"
+                },
+                "last_check": {
+                    "description": "LastCheck is the time of the last health check.",
+                    "type": "string"
+                },
+                "last_error": {
+                    "description": "LastError is the error returned by the last failed health check.",
+                    "type": "string"
+                },
+                "unavailable_since": {
+                    "description": "UnavailableSince is the time the circuit breaker opened, if open.",
+                    "type": "string"
+                }
+            }
+        },
+        "models.DefaultRequest": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "description": "Field is a placeholder for data that might be required in some requests.",
+                    "type": "string"
+                }
+            }
+        },
+        "models.DependencyCheck": {
+            "type": "object",
+            "properties": {
+                "consecutive_failures": {
+                    "description": "ConsecutiveFailures is the number of failed checks in a row.",
+                    "type": "integer"
+                },
+                "created_at": {
+                    "description": "CreatedAt is the timestamp of when the check was created.",
+                    "type": "string"
+                },
+                "down_since": {
+                    "description": "DownSince is the timestamp the dependency went down, or null if it is not down.",
+                    "type": "string"
+                },
+                "enabled": {
+                    "description": "Enabled allows pausing the checks of a dependency without deleting it.",
+                    "type": "boolean"
+                },
+                "expected_status": {
+                    "description": "ExpectedStatus is the HTTP status of a healthy response. 0 accepts any 2xx status.",
+                    "type": "integer"
+                },
+                "failure_threshold": {
+                    "description": "FailureThreshold is the number of failed checks in a row marking the dependency down (default 1).",
+                    "type": "integer"
+                },
+                "headers": {
+                    "description": "Headers is a JSON object of the headers sent with the request, e.g. {\"Authorization\": \"Bearer ...\"}.",
+                    "type": "object"
+                },
+                "id": {
+                    "description": "ID is the auto-incremented primary key of the check.",
+                    "type": "integer"
+                },
+                "interval": {
+                    "description": "Interval is the number of seconds between two checks (at least MinDependencyInterval).",
+                    "type": "integer"
+                },
+                "last_checked_at": {
+                    "description": "LastCheckedAt is the timestamp of the last check, or null if it was never checked.",
+                    "type": "string"
+                },
+                "last_error": {
+                    "description": "LastError is the error of the last check, or empty if it succeeded.",
+                    "type": "string"
+                },
+                "last_latency_ms": {
+                    "description": "LastLatencyMs is the duration of the last check, in milliseconds.",
+                    "type": "integer"
+                },
+                "last_status_code": {
+                    "description": "LastStatusCode is the HTTP status of the last check, or 0 if it got no response.",
+                    "type": "integer"
+                },
+                "name": {
+                    "description": "Name is a unique, human readable name of the dependency, shown in /readyz.",
+                    "type": "string"
+                },
+                "state": {
+                    "description": "State is \"unknown\", \"up\" or \"down\", maintained by the checks.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.DependencyState"
+                        }
+                    ]
+                },
+                "timeout": {
+                    "description": "Timeout is the number of seconds a check may take (default 5, at most MaxDependencyTimeout).",
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "description": "UpdatedAt is the timestamp of the last modification to the check.",
+                    "type": "string"
+                },
+                "url": {
+                    "description": "URL is the http(s) URL requested with GET, e.g. the health endpoint of the service.",
+                    "type": "string"
+                }
+            }
+        },
+        "models.DependencyHealth": {
+            "type": "object",
+            "properties": {
+                "down_since": {
+                    "description": "DownSince is the timestamp the dependency went down, if it is down.",
+                    "type": "string"
+                },
+                "last_checked_at": {
+                    "description": "LastCheckedAt is the timestamp of the last check, or null if it was never checked.",
+                    "type": "string"
+                },
+                "last_latency_ms": {
+                    "description": "LastLatencyMs is the duration of the last check, in milliseconds.",
+                    "type": "integer"
+                },
+                "last_status_code": {
+                    "description": "LastStatusCode is the HTTP status of the last check, or 0 if it got no response.",
+                    "type": "integer"
+                },
+                "name": {
+                    "description": "Name is the name of the dependency.",
+                    "type": "string"
+                },
+                "state": {
+                    "description": "State is \"unknown\", \"up\" or \"down\".",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.DependencyState"
+                        }
+                    ]
+                }
+            }
+        },
+        "models.DependencyState": {
+            "type": "string",
+            "enum": [
+                "unknown",
+                "up",
+                "down"
+            ],
+            "x-enum-varnames": [
+                "DependencyUnknown",
+                "DependencyUp",
+                "DependencyDown"
+            ]
+        },
+        "models.DeviceLoginRequest": {
+            "type": "object",
+            "required": [
+                "device_token"
+            ],
+            "properties": {
+                "device_token": {
+                    "description": "DeviceToken is the token returned by a login with remember_me.",
+                    "type": "string"
+                }
+            }
+        },
+        "models.DeviceToken": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "CreatedAt is the timestamp of the login that issued the token.",
+                    "type": "string"
+                },
+                "expires_at": {
+                    "description": "ExpiresAt is when the token stops being exchangeable.",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "ID is the generated identifier of the device, used to revoke it.",
+                    "type": "string"
+                },
+                "last_used_at": {
+                    "description": "LastUsedAt is the timestamp of the last exchange of the token.",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "Name is a label of the device, given at login or taken from its User-Agent.",
+                    "type": "string"
+                },
+                "username": {
+                    "description": "Username is the user the token logs in.",
+                    "type": "string"
+                }
+            }
+        },
+        "models.EmailIngestResult": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "description": "Error explains why the record was not created.",
+                    "type": "string"
+                },
+                "message_id": {
+                    "description": "MessageID is the Message-ID of the email.",
+                    "type": "string"
+                },
+                "record_id": {
+                    "description": "RecordID is the primary key of the created record, or empty if it failed.",
+                    "type": "string"
+                },
+                "resource": {
+                    "description": "Resource is the resource of the record.",
+                    "type": "string"
+                },
+                "rule": {
+                    "description": "Rule is the name of the rule matching the email, or empty if none did.",
+                    "type": "string"
+                }
+            }
+        },
+        "models.EmailRule": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "CreatedAt is the timestamp of when the rule was created.",
+                    "type": "string"
+                },
+                "enabled": {
+                    "description": "Enabled allows turning a rule off without deleting it.",
+                    "type": "boolean"
+                },
+                "from_pattern": {
+                    "description": "FromPattern, ToPattern and SubjectPattern are regular expressions the\nsender, one of the recipients and the subject must match. Empty matches\nevery email. The named groups of SubjectPattern (e.g. ` + "`" + `\\[#(?P\u003cticket\u003e\\d+)\\]` + "`" + `)\ncan be mapped to fields as \"subject.\u003cgroup\u003e\".",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "ID is the auto-incremented primary key of the rule.",
+                    "type": "integer"
+                },
+                "last_error": {
+                    "description": "LastError is the error of the record of the last email matched, or empty if it was created.",
+                    "type": "string"
+                },
+                "last_matched_at": {
+                    "description": "LastMatchedAt is the timestamp of the last email matched, or null if none was.",
+                    "type": "string"
+                },
+                "mapping": {
+                    "description": "Mapping is a JSON object mapping the JSON names of the fields of the\nresource to values of the email (see EmailSources), e.g.\n{\"field1\": \"message_id\", \"field2\": \"subject\"}.",
+                    "type": "object"
+                },
+                "name": {
+                    "description": "Name is a unique, human readable name of the rule.",
+                    "type": "string"
+                },
+                "owner": {
+                    "description": "Owner is the admin who stored the rule, the author of the records.",
+                    "type": "string"
+                },
+                "priority": {
+                    "description": "Priority orders the rules, the lowest first.",
+                    "type": "integer"
+                },
+                "resource": {
+                    "description": "Resource is the resource of the created records (e.g. \"example2\").",
+                    "type": "string"
+                },
+                "subject_pattern": {
+                    "type": "string"
+                },
+                "to_pattern": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "description": "UpdatedAt is the timestamp of the last modification to the rule.",
+                    "type": "string"
+                }
+            }
+        },
+        "models.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "description": "Error contains a descriptive error message.",
+                    "type": "string"
+                },
+                "request_id": {
+                    "description": "RequestID identifies the request in the server logs, when available.",
+                    "type": "string"
+                }
+            }
+        },
+        "models.Example1": {
+            "type": "object",
+            "properties": {
+                "field1": {
+                    "description": "Field1 is the primary key of the Example1 table.",
+                    "type": "string"
+                },
+                "field2": {
+                    "description": "Field2 stores additional data related to Example1.",
+                    "type": "string"
+                },
+                "meta": {
+                    "description": "Meta stores optional semi-structured data, filterable by path (e.g. filter[meta.color]=red).",
+                    "type": "object"
+                },
+                "relational_count": {
+                    "description": "RelationalCount is the number of ExampleRelational rows referencing the record,\nmaintained by the server (see database.Counter).",
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "Status is the lifecycle status, changed with POST /example1/{id}/{action}. New records\nare drafts; rows stored before the lifecycle existed default to published.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.LifecycleStatus"
+                        }
+                    ]
+                },
+                "updated_at": {
+                    "description": "UpdatedAt is the timestamp of the last modification, checked against the\nIf-Unmodified-Since header of writes.",
+                    "type": "string"
+                }
+            }
+        },
+        "models.Example2": {
+            "type": "object",
+            "properties": {
+                "assigned_to": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "field1": {
+                    "type": "string"
+                },
+                "field2": {
+                    "type": "string"
+                },
+                "location": {
+                    "$ref": "#/definitions/models.GeoPoint"
+                },
+                "reference": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ExportJob": {
+            "type": "object",
+            "properties": {
+                "anonymized": {
+                    "description": "Anonymized tells whether the personal data of the records was replaced,\naccording to the \"anonymize\" tag of their fields (see utils.Anonymizer).",
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "description": "CreatedAt is the timestamp of when the export was requested.",
+                    "type": "string"
+                },
+                "error": {
+                    "description": "Error is the reason a failed job stopped.",
+                    "type": "string"
+                },
+                "expires_at": {
+                    "description": "ExpiresAt is the timestamp from which the parts and their links are deleted, or null while it runs.",
+                    "type": "string"
+                },
+                "exported": {
+                    "description": "Exported is the number of records written so far.",
+                    "type": "integer"
+                },
+                "finished_at": {
+                    "description": "FinishedAt is the timestamp of when the job ended, or null while it runs.",
+                    "type": "string"
+                },
+                "format": {
+                    "description": "Format is the format of the parts: \"json\" (a JSON array per part) or \"csv\"\n(with a header row per part), both accepted by the bulk imports.",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "ID is the auto-incremented primary key of the job.",
+                    "type": "integer"
+                },
+                "owner": {
+                    "description": "Owner is the user who requested the export.",
+                    "type": "string"
+                },
+                "parts": {
+                    "description": "Parts lists the files written so far.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ExportPart"
+                    }
+                },
+                "query": {
+                    "description": "Query holds the filters and sort order of the export, as URL query parameters.",
+                    "type": "string"
+                },
+                "resource": {
+                    "description": "Resource is the resource exported (e.g. \"example1\"), or \"dump\" for the\ndumps of several resources, listed in Query.",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Status is the status of the job.",
+                    "enum": [
+                        "queued",
+                        "running",
+                        "done",
+                        "failed"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.BulkJobStatus"
+                        }
+                    ]
+                },
+                "total": {
+                    "description": "Total is the number of records matching the query when the export started.",
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "description": "UpdatedAt is the timestamp of the last progress of the job.",
+                    "type": "string"
+                }
+            }
+        },
+        "models.ExportPart": {
+            "type": "object",
+            "properties": {
+                "bytes": {
+                    "description": "Bytes is the size of the file.",
+                    "type": "integer"
+                },
+                "number": {
+                    "description": "Number is the position of the part, starting at 1.",
+                    "type": "integer"
+                },
+                "records": {
+                    "description": "Records is the number of records of the part.",
+                    "type": "integer"
+                },
+                "resource": {
+                    "description": "Resource is the resource of the records of the part.",
+                    "type": "string"
+                },
+                "sha256": {
+                    "description": "SHA256 is the hex-encoded checksum of the file, also its ETag.",
+                    "type": "string"
+                },
+                "url": {
+                    "description": "URL is the signed download link of the part, valid until the export expires.",
+                    "type": "string"
+                }
+            }
+        },
+        "models.FeatureFlag": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "CreatedAt is the timestamp of when the flag was created.",
+                    "type": "string"
+                },
+                "description": {
+                    "description": "Description explains what the flag gates.",
+                    "type": "string"
+                },
+                "enabled": {
+                    "description": "Enabled turns the flag on. A disabled flag is off for everyone.",
+                    "type": "boolean"
+                },
+                "name": {
+                    "description": "Name is the unique identifier of the flag (e.g. \"new_dashboard\").",
+                    "type": "string"
+                },
+                "roles": {
+                    "description": "Roles is a comma-separated list of roles the flag is limited to (e.g. \"admin\").",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "description": "UpdatedAt is the timestamp of the last modification to the flag.",
+                    "type": "string"
+                },
+                "users": {
+                    "description": "Users is a comma-separated list of usernames the flag is limited to.",
+                    "type": "string"
+                }
+            }
+        },
+        "models.ForbiddenFieldsResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "description": "Error contains a descriptive error message.",
+                    "type": "string"
+                },
+                "fields": {
+                    "description": "Fields lists the JSON names of the forbidden fields.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.GeoPoint": {
+            "type": "object",
+            "properties": {
+                "lat": {
+                    "description": "Lat is the latitude in degrees, between -90 and 90.",
+                    "type": "number"
+                },
+                "lon": {
+                    "description": "Lon is the longitude in degrees, between -180 and 180.",
+                    "type": "number"
+                }
+            }
+        },
+        "models.HealthResponse": {
+            "type": "object",
+            "properties": {
+                "database": {
+                    "description": "Database contains the state of the database connection.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.DBHealth"
+                        }
+                    ]
+                },
+                "dependencies": {
+                    "description": "Dependencies contains the state of the monitored external services (see DependencyCheck).",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.DependencyHealth"
+                    }
+                },
+                "status": {
+                    "description": "Status is \"ok\" when the service is ready, \"degraded\" when it is ready but a\ndependency is down, and \"unavailable\" otherwise.",
+                    "type": "string"
+                }
+            }
+        },
+        "models.HookAction": {
+            "type": "string",
+            "enum": [
+                "create",
+                "update",
+                "upsert"
+            ],
+            "x-enum-varnames": [
+                "HookActionCreate",
+                "HookActionUpdate",
+                "HookActionUpsert"
+            ]
+        },
+        "models.HookResult": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "description": "Created is the number of new records.",
+                    "type": "integer"
+                },
+                "failed": {
+                    "description": "Failed lists the records that could not be stored, with their error.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ImportFailure"
+                    }
+                },
+                "hook": {
+                    "description": "Hook is the name of the hook.",
+                    "type": "string"
+                },
+                "received": {
+                    "description": "Received is the number of records in the payload.",
+                    "type": "integer"
+                },
+                "received_at": {
+                    "description": "ReceivedAt is the timestamp of the payload.",
+                    "type": "string"
+                },
+                "resource": {
+                    "description": "Resource is the resource receiving the records.",
+                    "type": "string"
+                },
+                "updated": {
+                    "description": "Updated is the number of records replaced.",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ImportFailure": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "description": "Error explains why the record was not imported.",
+                    "type": "string"
+                },
+                "index": {
+                    "description": "Index is the position of the record in the response, starting at 0.",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ImportResult": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "description": "Created is the number of new records.",
+                    "type": "integer"
+                },
+                "failed": {
+                    "description": "Failed lists the records that could not be imported, with their error.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ImportFailure"
+                    }
+                },
+                "fetched": {
+                    "description": "Fetched is the number of records in the response.",
+                    "type": "integer"
+                },
+                "importer": {
+                    "description": "Importer is the name of the importer.",
+                    "type": "string"
+                },
+                "ran_at": {
+                    "description": "RanAt is the timestamp of the run.",
+                    "type": "string"
+                },
+                "resource": {
+                    "description": "Resource is the resource receiving the records.",
+                    "type": "string"
+                },
+                "updated": {
+                    "description": "Updated is the number of records replaced.",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Importer": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "CreatedAt is the timestamp of when the importer was created.",
+                    "type": "string"
+                },
+                "enabled": {
+                    "description": "Enabled allows pausing the schedule of an importer without deleting it.",
+                    "type": "boolean"
+                },
+                "headers": {
+                    "description": "Headers is a JSON object of the headers sent with the request, e.g. {\"Authorization\": \"Bearer ...\"}.",
+                    "type": "object"
+                },
+                "id": {
+                    "description": "ID is the auto-incremented primary key of the importer.",
+                    "type": "integer"
+                },
+                "interval": {
+                    "description": "Interval is the number of seconds between two scheduled runs (at least\nMinImporterInterval), or 0 for an importer only run on demand.",
+                    "type": "integer"
+                },
+                "last_error": {
+                    "description": "LastError is the error of the last run, or empty if it succeeded.",
+                    "type": "string"
+                },
+                "last_run_at": {
+                    "description": "LastRunAt is the timestamp of the last run, or null if it never ran.",
+                    "type": "string"
+                },
+                "mapping": {
+                    "description": "Mapping is a JSON object mapping the JSON names of the fields of the\nresource to the dot paths of their values in each external record, e.g.\n{\"field1\": \"code\", \"field2\": \"attributes.label\"}. Empty imports the\nrecords as they are.",
+                    "type": "object"
+                },
+                "name": {
+                    "description": "Name is a unique, human readable name of the importer.",
+                    "type": "string"
+                },
+                "owner": {
+                    "description": "Owner is the admin who stored the importer, the author of the imported records.",
+                    "type": "string"
+                },
+                "records_path": {
+                    "description": "RecordsPath is the dot path of the array of records in the response\n(e.g. \"data.items\"). Empty if the response is the array.",
+                    "type": "string"
+                },
+                "resource": {
+                    "description": "Resource is the resource receiving the records (e.g. \"example1\").",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "description": "UpdatedAt is the timestamp of the last modification to the importer.",
+                    "type": "string"
+                },
+                "url": {
+                    "description": "URL is the http(s) URL fetched with GET.",
+                    "type": "string"
+                }
+            }
+        },
+        "models.InboundHook": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "description": "Action is \"create\", \"update\" or \"upsert\".",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.HookAction"
+                        }
+                    ]
+                },
+                "created_at": {
+                    "description": "CreatedAt is the timestamp of when the hook was created.",
+                    "type": "string"
+                },
+                "enabled": {
+                    "description": "Enabled allows rejecting the payloads of a hook without deleting it.",
+                    "type": "boolean"
+                },
+                "has_secret": {
+                    "description": "HasSecret reports whether the hook has a secret, since it is not returned.",
+                    "type": "boolean"
+                },
+                "id": {
+                    "description": "ID is the auto-incremented primary key of the hook.",
+                    "type": "integer"
+                },
+                "last_error": {
+                    "description": "LastError is the error of the last payload, or empty if it succeeded.",
+                    "type": "string"
+                },
+                "last_received_at": {
+                    "description": "LastReceivedAt is the timestamp of the last signed payload, or null if none was received.",
+                    "type": "string"
+                },
+                "mapping": {
+                    "description": "Mapping is a JSON object mapping the JSON names of the fields of the\nresource to the dot paths of their values in each received record, like\nthe mapping of the importers. Empty stores the records as they are.",
+                    "type": "object"
+                },
+                "name": {
+                    "description": "Name is the unique name of the hook in its URL (letters, digits, \"_\" and \"-\").",
+                    "type": "string"
+                },
+                "owner": {
+                    "description": "Owner is the admin who stored the hook, the author of the records.",
+                    "type": "string"
+                },
+                "records_path": {
+                    "description": "RecordsPath is the dot path of the record, or of the array of records,\nin the payload (e.g. \"data.object\"). Empty if it is the payload.",
+                    "type": "string"
+                },
+                "resource": {
+                    "description": "Resource is the resource receiving the records (e.g. \"example1\").",
+                    "type": "string"
+                },
+                "secret": {
+                    "description": "Secret is the key of the HMAC-SHA256 signature of the payloads. It is\nnever returned; empty on update keeps the stored one.",
+                    "type": "string"
+                },
+                "signature_encoding": {
+                    "description": "SignatureEncoding is \"hex\" (default) or \"base64\".",
+                    "type": "string"
+                },
+                "signature_header": {
+                    "description": "SignatureHeader is the header of the signature (default X-Signature-256).",
+                    "type": "string"
+                },
+                "signature_prefix": {
+                    "description": "SignaturePrefix is written before the signature in its header, e.g.\n\"sha256=\" for the GitHub style X-Hub-Signature-256.",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "description": "UpdatedAt is the timestamp of the last modification to the hook.",
+                    "type": "string"
+                }
+            }
+        },
+        "models.InboxNotification": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "CreatedAt is the timestamp of when the notification was sent.",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "ID is the auto-incremented primary key of the notification.",
+                    "type": "integer"
+                },
+                "kind": {
+                    "description": "Kind is the hook that sent the notification, e.g. \"record_assigned\".",
+                    "type": "string"
+                },
+                "read": {
+                    "description": "Read is set once the user marks the notification as read.",
+                    "type": "boolean"
+                },
+                "read_at": {
+                    "description": "ReadAt is the timestamp of when the notification was marked as read.",
+                    "type": "string"
+                },
+                "record_id": {
+                    "description": "RecordID is the tokenized ID of the record the notification is about, if any.",
+                    "type": "string"
+                },
+                "resource": {
+                    "description": "Resource is the resource of the record the notification is about, if any.",
+                    "type": "string"
+                },
+                "text": {
+                    "description": "Text is the body of the notification.",
+                    "type": "string"
+                },
+                "title": {
+                    "description": "Title is a one-line summary of the notification.",
+                    "type": "string"
+                }
+            }
+        },
+        "models.IngestResult": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "description": "Created is the number of records created.",
+                    "type": "integer"
+                },
+                "error": {
+                    "description": "Error is the reason the ingestion stopped before the end of the body.",
+                    "type": "string"
+                },
+                "failed": {
+                    "description": "Failed is the number of records that could not be created.",
+                    "type": "integer"
+                },
+                "failures": {
+                    "description": "Failures lists the first MaxBulkJobFailures records that could not be\ncreated, with their error. Index is the position of the record among the\nlines with a record, starting at 0.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ImportFailure"
+                    }
+                },
+                "received": {
+                    "description": "Received is the number of records of the body read before the end or the error.",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.JWTResponse": {
+            "type": "object",
+            "properties": {
+                "device_id": {
+                    "description": "DeviceID identifies the device token, to revoke it with DELETE /me/devices/{id}.",
+                    "type": "string"
+                },
+                "device_token": {
+                    "description": "DeviceToken is the device token of a login with remember_me, only returned once.",
+                    "type": "string"
+                },
+                "token": {
+                    "description": "Token is the JWT token assigned to the authenticated user.",
+                    "type": "string"
+                }
+            }
+        },
+        "models.LeaderLease": {
+            "type": "object",
+            "properties": {
+                "acquired_at": {
+                    "description": "AcquiredAt is the timestamp of when the holder became the leader.",
+                    "type": "string"
+                },
+                "expires_at": {
+                    "description": "ExpiresAt is when the lease is taken over by another instance if it is not renewed.",
+                    "type": "string"
+                },
+                "holder": {
+                    "description": "Holder is the ID of the leading instance (INSTANCE_ID).",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "Name is the name of the election (e.g. \"scheduler\").",
+                    "type": "string"
+                },
+                "renewed_at": {
+                    "description": "RenewedAt is the timestamp of the last renewal of the lease.",
+                    "type": "string"
+                }
+            }
+        },
+        "models.LeaderStatus": {
+            "type": "object",
+            "properties": {
+                "instance": {
+                    "description": "Instance is the ID of the instance answering.",
+                    "type": "string"
+                },
+                "is_leader": {
+                    "description": "IsLeader is true if the instance answering leads.",
+                    "type": "boolean"
+                },
+                "jobs": {
+                    "description": "Jobs lists the jobs that only run on the leader.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "lease": {
+                    "description": "Lease is the current lease, with an empty holder if no instance leads.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.LeaderLease"
+                        }
+                    ]
+                }
+            }
+        },
+        "models.LifecycleStatus": {
+            "type": "string",
+            "enum": [
+                "draft",
+                "published",
+                "archived"
+            ],
+            "x-enum-varnames": [
+                "StatusDraft",
+                "StatusPublished",
+                "StatusArchived"
+            ]
+        },
+        "models.LoginRequest": {
+            "type": "object",
+            "required": [
+                "password",
+                "username"
+            ],
+            "properties": {
+                "device": {
+                    "description": "Device is an optional label of the device remembered, by default its User-Agent.",
+                    "type": "string"
+                },
+                "password": {
+                    "description": "Password is the user's password used for authentication.",
+                    "type": "string"
+                },
+                "remember_me": {
+                    "description": "RememberMe also issues a device token, exchangeable for new session tokens at POST /login/device.",
+                    "type": "boolean"
+                },
+                "username": {
+                    "description": "Username is the unique identifier for the user attempting to log in.",
+                    "type": "string"
+                }
+            }
+        },
+        "models.MergeRequest": {
+            "type": "object",
+            "properties": {
+                "ids": {
+                    "description": "IDs lists the tokenized IDs of the records merged into the survivor and deleted.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "survivor": {
+                    "description": "Survivor is the tokenized ID of the record that is kept.",
+                    "type": "string"
+                }
+            }
+        },
+        "models.MergeResult": {
+            "type": "object",
+            "properties": {
+                "merged": {
+                    "description": "Merged lists the tokenized IDs of the deleted records.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "references": {
+                    "description": "References is the number of rows of other resources re-pointed to the survivor.",
+                    "type": "integer"
+                },
+                "survivor": {
+                    "description": "Survivor is the kept record, with its empty fields filled from the merged records."
+                }
+            }
+        },
+        "models.NotificationRule": {
+            "type": "object",
+            "properties": {
+                "channel": {
+                    "description": "Channel is the notification driver: \"slack\", \"teams\" or \"telegram\".",
+                    "type": "string"
+                },
+                "created_at": {
+                    "description": "CreatedAt is the timestamp of when the rule was created.",
+                    "type": "string"
+                },
+                "enabled": {
+                    "description": "Enabled allows turning a rule off without deleting it.",
+                    "type": "boolean"
+                },
+                "event": {
+                    "description": "Event is \"created\", \"updated\", \"deleted\", \"error_burst\", \"table_growth\", \"dependency\", or empty\nfor every change.",
+                    "type": "string"
+                },
+                "filter": {
+                    "description": "Filter is a query string the record must match (e.g. \"field2=critical\").",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "ID is the auto-incremented primary key of the rule.",
+                    "type": "integer"
+                },
+                "name": {
+                    "description": "Name is a human-readable description of the rule.",
+                    "type": "string"
+                },
+                "resource": {
+                    "description": "Resource limits the rule to one resource (or table, for table_growth, or dependency, for\ndependency). Empty matches every resource.",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "description": "UpdatedAt is the timestamp of the last modification to the rule.",
+                    "type": "string"
+                },
+                "webhook_url": {
+                    "description": "WebhookURL is the incoming webhook URL of the channel (Slack and Teams only).",
+                    "type": "string"
+                }
+            }
+        },
+        "models.OutboundHost": {
+            "type": "object",
+            "properties": {
+                "avg_latency_ms": {
+                    "description": "AvgLatencyMs is the average duration of an attempt in milliseconds.",
+                    "type": "number"
+                },
+                "circuit_open": {
+                    "description": "CircuitOpen is true while the requests to the host are rejected after consecutive failures.",
+                    "type": "boolean"
+                },
+                "failures": {
+                    "description": "Failures is the number of attempts failing with a network error, a 429 or a 5xx.",
+                    "type": "integer"
+                },
+                "host": {
+                    "description": "Host is the host (and port, if any) called.",
+                    "type": "string"
+                },
+                "last_error": {
+                    "description": "LastError is the error of the last failed attempt.",
+                    "type": "string"
+                },
+                "last_failure_at": {
+                    "description": "LastFailureAt is the time of the last failed attempt, or null.",
+                    "type": "string"
+                },
+                "rejected": {
+                    "description": "Rejected is the number of attempts not sent because the circuit was open.",
+                    "type": "integer"
+                },
+                "requests": {
+                    "description": "Requests is the number of attempts sent, retries included.",
+                    "type": "integer"
+                },
+                "retries": {
+                    "description": "Retries is the number of attempts that retried a failed one.",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.QueryOperation": {
+            "type": "object",
+            "properties": {
+                "fields": {
+                    "description": "Fields restricts the returned fields. Primary keys are always returned.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "filters": {
+                    "description": "Filters maps query parameters to their value, e.g. {\"field2\": \"foo\"} or\n{\"filter[field1][like]\": \"a%\"}, as in the query string of GET /{resource}.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "page": {
+                    "description": "Page is the page to return, starting at 1. Zero disables pagination unless PerPage is set.",
+                    "type": "integer"
+                },
+                "per_page": {
+                    "description": "PerPage is the page size. Zero disables pagination unless Page is set.",
+                    "type": "integer"
+                },
+                "resource": {
+                    "description": "Resource is the resource to read (e.g. \"example1\").",
+                    "type": "string"
+                },
+                "sort": {
+                    "description": "Sort lists the fields used to order the results. A leading \"-\" sorts descending.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.QueryResult": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "description": "Data holds the records, or is null if the read failed."
+                },
+                "error": {
+                    "description": "Error describes why the read failed.",
+                    "type": "string"
+                },
+                "per_page": {
+                    "description": "PerPage is the page size applied when the one asked for was capped by LIST_BYTE_BUDGET, as in the X-Per-Page header.",
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "Status is the HTTP status GET /{resource} would have answered.",
+                    "type": "integer"
+                },
+                "total": {
+                    "description": "Total is the number of matching records, as in the X-Total-Count header.",
+                    "type": "integer"
+                },
+                "total_is_estimate": {
+                    "description": "TotalIsEstimate is true when Total is a database estimate (see COUNT_ESTIMATE_THRESHOLD).",
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.Quota": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "CreatedAt is the timestamp of the creation of the quota.",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "ID is the auto-incremented primary key of the quota.",
+                    "type": "integer"
+                },
+                "max_rows": {
+                    "description": "MaxRows is the largest number of records per owner. Zero forbids new records.",
+                    "type": "integer"
+                },
+                "owner": {
+                    "description": "Owner is the username the quota applies to. Empty applies to every owner\nwithout a quota of their own, or to the whole resource if it has no owner field.",
+                    "type": "string"
+                },
+                "resource": {
+                    "description": "Resource is the resource the quota applies to (e.g. \"example2\").",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "description": "UpdatedAt is the timestamp of the last change of the quota.",
+                    "type": "string"
+                }
+            }
+        },
+        "models.QuotaUsage": {
+            "type": "object",
+            "properties": {
+                "exceeded": {
+                    "description": "Exceeded is true when the owner can't create records anymore.",
+                    "type": "boolean"
+                },
+                "max_rows": {
+                    "description": "MaxRows is the quota of the owner.",
+                    "type": "integer"
+                },
+                "owner": {
+                    "description": "Owner is the username owning the records, empty for resources without owner field.",
+                    "type": "string"
+                },
+                "resource": {
+                    "description": "Resource is the resource of the quota (e.g. \"example2\").",
+                    "type": "string"
+                },
+                "rows": {
+                    "description": "Rows is the number of records the owner has.",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.RecordLock": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "CreatedAt is the timestamp of when the lock was first taken.",
+                    "type": "string"
+                },
+                "expires_at": {
+                    "description": "ExpiresAt is when the lock is released if it is not renewed.",
+                    "type": "string"
+                },
+                "locked_by": {
+                    "description": "LockedBy is the username of the user holding the lock.",
+                    "type": "string"
+                },
+                "record_id": {
+                    "description": "RecordID is the tokenized ID of the locked record.",
+                    "type": "string"
+                },
+                "resource": {
+                    "description": "Resource is the name of the resource of the locked record (e.g. \"example1\").",
+                    "type": "string"
+                }
+            }
+        },
+        "models.RegisterRequest": {
+            "type": "object",
+            "required": [
+                "password",
+                "username"
+            ],
+            "properties": {
+                "email": {
+                    "description": "Email is the optional email address of the new user. A verification link is sent to it.",
+                    "type": "string"
+                },
+                "password": {
+                    "description": "Password is the new user's password, which will be hashed before storage.",
+                    "type": "string"
+                },
+                "role": {
+                    "description": "Role specifies whether the user is an \"admin\" or \"user\". It is ignored by\nthe public registration endpoint, which always creates regular users.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Role"
+                        }
+                    ]
+                },
+                "username": {
+                    "description": "Username is the unique identifier for the new user.",
+                    "type": "string"
+                }
+            }
+        },
+        "models.Report": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "CreatedAt is the timestamp of when the report was created.",
+                    "type": "string"
+                },
+                "emails": {
+                    "description": "Emails is a comma-separated list of addresses receiving the differences.",
+                    "type": "string"
+                },
+                "enabled": {
+                    "description": "Enabled allows pausing a report without deleting it.",
+                    "type": "boolean"
+                },
+                "id": {
+                    "description": "ID is the auto-incremented primary key of the report.",
+                    "type": "integer"
+                },
+                "interval": {
+                    "description": "Interval is the number of seconds between two runs (at least MinReportInterval).",
+                    "type": "integer"
+                },
+                "last_error": {
+                    "description": "LastError is the error of the last run, or empty if it succeeded.",
+                    "type": "string"
+                },
+                "last_run_at": {
+                    "description": "LastRunAt is the timestamp of the last run, or null if it never ran.",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "Name is a unique, human readable name of the report.",
+                    "type": "string"
+                },
+                "owner": {
+                    "description": "Owner is the user the view is resolved for: their own views and the shared ones.\nIt is set to the admin creating the report.",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "description": "UpdatedAt is the timestamp of the last modification to the report.",
+                    "type": "string"
+                },
+                "view": {
+                    "description": "View is the name of the saved query run by the report (see SavedQuery).",
+                    "type": "string"
+                },
+                "webhook_url": {
+                    "description": "WebhookURL receives the differences as a JSON payload. Empty disables it.",
+                    "type": "string"
+                }
+            }
+        },
+        "models.Role": {
+            "type": "string",
+            "enum": [
+                "admin",
+                "user"
+            ],
+            "x-enum-comments": {
+                "AdminRole": "@Enum admin",
+                "UserRole": "@Enum user"
+            },
+            "x-enum-varnames": [
+                "AdminRole",
+                "UserRole"
+            ]
+        },
+        "models.RouteInfo": {
+            "type": "object",
+            "properties": {
+                "methods": {
+                    "description": "Methods lists the HTTP methods accepted by the route. Empty means any method.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "model": {
+                    "description": "Model is the Go type of the resource served by the route, if any (e.g. \"models.Example1\").",
+                    "type": "string"
+                },
+                "path": {
+                    "description": "Path is the path template of the route (e.g. \"/example1/{id}\").",
+                    "type": "string"
+                },
+                "prefix": {
+                    "description": "Prefix is true when the route matches every path starting with Path.",
+                    "type": "boolean"
+                },
+                "role": {
+                    "description": "Role is the access level required: \"public\", \"user\" or \"admin\".",
+                    "type": "string"
+                }
+            }
+        },
+        "models.SavedQuery": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "CreatedAt is the timestamp of when the view was created.",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "ID is the auto-incremented primary key of the saved query.",
+                    "type": "integer"
+                },
+                "name": {
+                    "description": "Name is the identifier used in the ?view= query parameter.",
+                    "type": "string"
+                },
+                "owner": {
+                    "description": "Owner is the username of the user who created the view.",
+                    "type": "string"
+                },
+                "query": {
+                    "description": "Query is the raw query string stored for the view\n(e.g. \"field2=foo\u0026sort=-field1\u0026fields=field1,field2\").",
+                    "type": "string"
+                },
+                "resource": {
+                    "description": "Resource is the resource the view applies to (e.g. \"example1\").",
+                    "type": "string"
+                },
+                "shared": {
+                    "description": "Shared makes the view visible to all users.",
+                    "type": "boolean"
+                },
+                "updated_at": {
+                    "description": "UpdatedAt is the timestamp of the last modification to the view.",
+                    "type": "string"
+                }
+            }
+        },
+        "models.SavedQueryRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "resource"
+            ],
+            "properties": {
+                "name": {
+                    "description": "Name is the identifier used in the ?view= query parameter.",
+                    "type": "string"
+                },
+                "query": {
+                    "description": "Query is the raw query string (filters, sort and fields).",
+                    "type": "string"
+                },
+                "resource": {
+                    "description": "Resource is the resource the view applies to.",
+                    "type": "string"
+                },
+                "shared": {
+                    "description": "Shared makes the view visible to all users (admins only).",
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.SchemaColumn": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "description": "Field is the JSON name of the field in the API, or empty if it is not exposed.",
+                    "type": "string"
+                },
+                "foreign_key": {
+                    "description": "ForeignKey is true for the columns referencing another table.",
+                    "type": "boolean"
+                },
+                "name": {
+                    "description": "Name is the column name.",
+                    "type": "string"
+                },
+                "primary_key": {
+                    "description": "PrimaryKey is true for the columns of the primary key.",
+                    "type": "boolean"
+                },
+                "type": {
+                    "description": "Type is the data type of the column, as declared in the model.",
+                    "type": "string"
+                },
+                "unique": {
+                    "description": "Unique is true for the columns with a unique constraint of their own.",
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.SchemaEdge": {
+            "type": "object",
+            "properties": {
+                "cardinality": {
+                    "description": "Cardinality is \"many-to-one\" or \"one-to-one\", from From to To.",
+                    "type": "string",
+                    "enum": [
+                        "many-to-one",
+                        "one-to-one"
+                    ]
+                },
+                "columns": {
+                    "description": "Columns lists the foreign key columns of From.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "from": {
+                    "description": "From is the referencing table.",
+                    "type": "string"
+                },
+                "references": {
+                    "description": "References lists the columns of To they reference, in the same order.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "source": {
+                    "description": "Source is SchemaSourceModel, SchemaSourceDatabase or SchemaSourceBoth.",
+                    "type": "string"
+                },
+                "to": {
+                    "description": "To is the referenced table.",
+                    "type": "string"
+                }
+            }
+        },
+        "models.SchemaGraph": {
+            "type": "object",
+            "properties": {
+                "edges": {
+                    "description": "Edges lists the relationships, from the referencing table to the referenced one.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SchemaEdge"
+                    }
+                },
+                "nodes": {
+                    "description": "Nodes lists the tables, sorted by name.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SchemaNode"
+                    }
+                }
+            }
+        },
+        "models.SchemaNode": {
+            "type": "object",
+            "properties": {
+                "columns": {
+                    "description": "Columns lists the columns of the model, in order. Empty for a table only found in the database.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SchemaColumn"
+                    }
+                },
+                "id": {
+                    "description": "ID is the table name, referenced by the edges.",
+                    "type": "string"
+                },
+                "model": {
+                    "description": "Model is the name of the Go model of the table, or empty for a table only found in the database.",
+                    "type": "string"
+                },
+                "source": {
+                    "description": "Source is SchemaSourceModel or SchemaSourceDatabase.",
+                    "type": "string"
+                }
+            }
+        },
+        "models.ServiceAccount": {
+            "type": "object",
+            "properties": {
+                "client_id": {
+                    "description": "ClientID is the generated unique identifier of the account (e.g. \"sa-1f2e...\").",
+                    "type": "string"
+                },
+                "created_at": {
+                    "description": "CreatedAt is the timestamp of when the account was created.",
+                    "type": "string"
+                },
+                "created_by": {
+                    "description": "CreatedBy is the admin who created the account.",
+                    "type": "string"
+                },
+                "description": {
+                    "description": "Description explains what the account is used for.",
+                    "type": "string"
+                },
+                "disabled": {
+                    "description": "Disabled blocks new tokens for the account.",
+                    "type": "boolean"
+                },
+                "last_used_at": {
+                    "description": "LastUsedAt is the timestamp of the last token issued to the account.",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "Name is a human-readable label for the account.",
+                    "type": "string"
+                },
+                "role": {
+                    "description": "Role is the role of the tokens issued to the account, \"user\" by default.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Role"
+                        }
+                    ]
+                },
+                "scopes": {
+                    "description": "Scopes is a comma-separated list of \"\u003cresource\u003e:\u003caction\u003e\" entries, where action is\n\"read\" or \"write\" and \"*\" matches any resource or action (e.g. \"example1:read,*:read\").",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "description": "UpdatedAt is the timestamp of the last modification to the account.",
+                    "type": "string"
+                }
+            }
+        },
+        "models.ServiceAccountCredentials": {
+            "type": "object",
+            "properties": {
+                "client_id": {
+                    "description": "ClientID is the generated unique identifier of the account (e.g. \"sa-1f2e...\").",
+                    "type": "string"
+                },
+                "client_secret": {
+                    "description": "ClientSecret is the plain client secret.",
+                    "type": "string"
+                },
+                "created_at": {
+                    "description": "CreatedAt is the timestamp of when the account was created.",
+                    "type": "string"
+                },
+                "created_by": {
+                    "description": "CreatedBy is the admin who created the account.",
+                    "type": "string"
+                },
+                "description": {
+                    "description": "Description explains what the account is used for.",
+                    "type": "string"
+                },
+                "disabled": {
+                    "description": "Disabled blocks new tokens for the account.",
+                    "type": "boolean"
+                },
+                "last_used_at": {
+                    "description": "LastUsedAt is the timestamp of the last token issued to the account.",
                     "type": "string"
                 },
-                "field2": {
+                "name": {
+                    "description": "Name is a human-readable label for the account.",
+                    "type": "string"
+                },
+                "role": {
+                    "description": "Role is the role of the tokens issued to the account, \"user\" by default.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Role"
+                        }
+                    ]
+                },
+                "scopes": {
+                    "description": "Scopes is a comma-separated list of \"\u003cresource\u003e:\u003caction\u003e\" entries, where action is\n\"read\" or \"write\" and \"*\" matches any resource or action (e.g. \"example1:read,*:read\").",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "description": "UpdatedAt is the timestamp of the last modification to the account.",
                     "type": "string"
                 }
             }
         },
-        "models.JWTResponse": {
+        "models.Setting": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "description": "Description explains what the setting controls.",
+                    "type": "string"
+                },
+                "key": {
+                    "description": "Key is the unique name of the setting (e.g. \"max_page_size\").",
+                    "type": "string"
+                },
+                "type": {
+                    "description": "Type is the type of the value: \"string\", \"int\", \"float\", \"bool\" or \"json\".",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.SettingType"
+                        }
+                    ]
+                },
+                "updated_at": {
+                    "description": "UpdatedAt is the timestamp of the last modification to the setting.",
+                    "type": "string"
+                },
+                "updated_by": {
+                    "description": "UpdatedBy is the username of the admin who last changed the setting.",
+                    "type": "string"
+                },
+                "value": {
+                    "description": "Value is the JSON-encoded value of the setting.",
+                    "type": "object"
+                }
+            }
+        },
+        "models.SettingType": {
+            "type": "string",
+            "enum": [
+                "string",
+                "int",
+                "float",
+                "bool",
+                "json"
+            ],
+            "x-enum-varnames": [
+                "SettingString",
+                "SettingInt",
+                "SettingFloat",
+                "SettingBool",
+                "SettingJSON"
+            ]
+        },
+        "models.SlowRoute": {
+            "type": "object",
+            "properties": {
+                "method": {
+                    "description": "Method is the HTTP method of the route.",
+                    "type": "string"
+                },
+                "p95_ms": {
+                    "description": "P95Ms is the rolling p95 latency in milliseconds.",
+                    "type": "number"
+                },
+                "route": {
+                    "description": "Route is the path template of the route (e.g. \"/example1/{id}\").",
+                    "type": "string"
+                },
+                "samples": {
+                    "description": "Samples is the number of requests used to compute the p95 latency.",
+                    "type": "integer"
+                },
+                "slow_since": {
+                    "description": "SlowSince is the time the route first exceeded the threshold.",
+                    "type": "string"
+                },
+                "threshold_ms": {
+                    "description": "ThresholdMs is the configured threshold in milliseconds.",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.StatsSnapshot": {
+            "type": "object",
+            "properties": {
+                "data_bytes": {
+                    "description": "DataBytes is the size of the table data in bytes (MySQL only).",
+                    "type": "integer"
+                },
+                "id": {
+                    "description": "ID is the auto-incremented primary key of the snapshot.",
+                    "type": "integer"
+                },
+                "index_bytes": {
+                    "description": "IndexBytes is the size of the table indexes in bytes (MySQL only).",
+                    "type": "integer"
+                },
+                "rows": {
+                    "description": "Rows is the number of rows in the table.",
+                    "type": "integer"
+                },
+                "table": {
+                    "description": "Table is the name of the table.",
+                    "type": "string"
+                },
+                "taken_at": {
+                    "description": "TakenAt is the timestamp of the snapshot.",
+                    "type": "string"
+                }
+            }
+        },
+        "models.StepUpResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "description": "Error describes why the request was rejected.",
+                    "type": "string"
+                },
+                "max_age": {
+                    "description": "MaxAge is how many seconds ago the user must have authenticated.",
+                    "type": "integer"
+                },
+                "request_id": {
+                    "description": "RequestID identifies the request in the server logs, when available.",
+                    "type": "string"
+                },
+                "step_up_url": {
+                    "description": "StepUpURL is where a fresh token is requested with the password of the user.",
+                    "type": "string"
+                }
+            }
+        },
+        "models.SyncChange": {
+            "type": "object",
+            "properties": {
+                "base_updated_at": {
+                    "description": "BaseUpdatedAt is the updated_at of the server copy the change is based on,\nomitted for records created offline. The change is a conflict if the record\nwas updated after it.",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "ID is the tokenized ID of the record.",
+                    "type": "string"
+                },
+                "op": {
+                    "description": "Op is the operation: \"upsert\" or \"delete\".",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.SyncOp"
+                        }
+                    ]
+                },
+                "record": {
+                    "description": "Record holds the fields to store, for upserts. Omitted fields keep their server value.",
+                    "type": "object"
+                },
+                "resource": {
+                    "description": "Resource is the resource of the record (e.g. \"example1\").",
+                    "type": "string"
+                }
+            }
+        },
+        "models.SyncHint": {
+            "type": "string",
+            "enum": [
+                "server_changed",
+                "server_deleted",
+                "already_exists"
+            ],
+            "x-enum-varnames": [
+                "SyncServerChanged",
+                "SyncServerDeleted",
+                "SyncAlreadyExists"
+            ]
+        },
+        "models.SyncOp": {
+            "type": "string",
+            "enum": [
+                "upsert",
+                "delete"
+            ],
+            "x-enum-varnames": [
+                "SyncUpsert",
+                "SyncDelete"
+            ]
+        },
+        "models.SyncRequest": {
             "type": "object",
             "properties": {
+                "changes": {
+                    "description": "Changes lists the local changes to push, applied in order before the pull.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SyncChange"
+                    }
+                },
+                "resources": {
+                    "description": "Resources maps the resources to pull to the subset of their records the client mirrors.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/models.SyncSubset"
+                    }
+                },
                 "token": {
-                    "description": "Token is the JWT token assigned to the authenticated user.",
+                    "description": "Token is the token returned by the previous sync of the client, empty for the first one.",
                     "type": "string"
                 }
             }
         },
-        "models.LoginRequest": {
+        "models.SyncResourceChanges": {
             "type": "object",
-            "required": [
-                "password",
-                "username"
+            "properties": {
+                "changed": {
+                    "description": "Changed holds the records of the subset created or updated since the previous sync, oldest first."
+                },
+                "deleted": {
+                    "description": "Deleted lists the tokenized IDs of the records deleted, or no longer in the\nsubset, since the previous sync (tombstones).",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "has_more": {
+                    "description": "HasMore is true when more changes are available right away with the new token.",
+                    "type": "boolean"
+                },
+                "reset": {
+                    "description": "Reset is true when the client must drop its local copy of the resource before\napplying the changes, which then hold the whole subset: on the first sync, after\nthe subset changed, or when the previous sync is older than the deletion retention.",
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.SyncResponse": {
+            "type": "object",
+            "properties": {
+                "resources": {
+                    "description": "Resources maps the pulled resources to their changes since the previous sync.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/models.SyncResourceChanges"
+                    }
+                },
+                "results": {
+                    "description": "Results holds the outcome of the pushed changes, in order.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SyncResult"
+                    }
+                },
+                "token": {
+                    "description": "Token is the token to send with the next sync of the client.",
+                    "type": "string"
+                }
+            }
+        },
+        "models.SyncResult": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "description": "Error describes why the change was rejected.",
+                    "type": "string"
+                },
+                "fields": {
+                    "description": "Fields lists the fields of the change whose value differs from the server\ncopy, for \"server_changed\" and \"already_exists\" conflicts.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "hint": {
+                    "description": "Hint explains a conflict: \"server_changed\", \"server_deleted\" or \"already_exists\".",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.SyncHint"
+                        }
+                    ]
+                },
+                "id": {
+                    "description": "ID is the tokenized ID of the record of the change.",
+                    "type": "string"
+                },
+                "resource": {
+                    "description": "Resource is the resource of the change.",
+                    "type": "string"
+                },
+                "server": {
+                    "description": "Server is the server copy of the record in conflict, or the stored record once applied."
+                },
+                "status": {
+                    "description": "Status is \"applied\", \"conflict\" or \"rejected\".",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.SyncStatus"
+                        }
+                    ]
+                }
+            }
+        },
+        "models.SyncStatus": {
+            "type": "string",
+            "enum": [
+                "applied",
+                "conflict",
+                "rejected"
             ],
+            "x-enum-varnames": [
+                "SyncApplied",
+                "SyncConflict",
+                "SyncRejected"
+            ]
+        },
+        "models.SyncSubset": {
+            "type": "object",
             "properties": {
-                "password": {
-                    "description": "Password is the user's password used for authentication.",
+                "filters": {
+                    "description": "Filters maps query parameters to their value, as in the query string of\nGET /{resource} (e.g. {\"field2\": \"foo\"}). Empty mirrors every record.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.TableColumnStats": {
+            "type": "object",
+            "properties": {
+                "columns": {
+                    "description": "Columns contains the statistics of every column.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ColumnStats"
+                    }
+                },
+                "sample_size": {
+                    "description": "SampleSize is the maximum number of rows used to compute the top values.",
+                    "type": "integer"
+                },
+                "table": {
+                    "description": "Table is the name of the table.",
+                    "type": "string"
+                }
+            }
+        },
+        "models.TableGrowth": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "description": "From is the value of the metric at the start of the window.",
+                    "type": "integer"
+                },
+                "growth_percent": {
+                    "description": "GrowthPercent is the growth of the metric over the window, in percent.",
+                    "type": "number"
+                },
+                "metric": {
+                    "description": "Metric is the growing metric: \"rows\" or \"bytes\" (data and index size).",
+                    "type": "string"
+                },
+                "since": {
+                    "description": "Since is the time of the snapshot at the start of the window.",
+                    "type": "string"
+                },
+                "table": {
+                    "description": "Table is the name of the table.",
+                    "type": "string"
+                },
+                "threshold_percent": {
+                    "description": "ThresholdPercent is the configured growth threshold, in percent.",
+                    "type": "number"
+                },
+                "to": {
+                    "description": "To is the latest value of the metric.",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.TokenResponse": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "description": "AccessToken is the JWT to send as a Bearer token.",
+                    "type": "string"
+                },
+                "expires_in": {
+                    "description": "ExpiresIn is the lifetime of the token in seconds.",
+                    "type": "integer"
+                },
+                "scope": {
+                    "description": "Scope is the space-separated list of granted scopes.",
+                    "type": "string"
+                },
+                "token_type": {
+                    "description": "TokenType is always \"Bearer\".",
+                    "type": "string"
+                }
+            }
+        },
+        "models.UnreadCount": {
+            "type": "object",
+            "properties": {
+                "unread": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.UsageRollup": {
+            "type": "object",
+            "properties": {
+                "account_type": {
+                    "description": "AccountType is \"user\" or \"service\".",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.AccountType"
+                        }
+                    ]
+                },
+                "bytes_in": {
+                    "description": "BytesIn is the number of bytes of the request bodies read.",
+                    "type": "integer"
+                },
+                "bytes_out": {
+                    "description": "BytesOut is the number of bytes of the response bodies written.",
+                    "type": "integer"
+                },
+                "client_errors": {
+                    "description": "ClientErrors is the number of requests answered with a 4xx status.",
+                    "type": "integer"
+                },
+                "day": {
+                    "description": "Day is the day of the requests, as YYYY-MM-DD in UTC.",
+                    "type": "string"
+                },
+                "error_rate": {
+                    "description": "ErrorRate is the share of the requests answered with a 4xx or 5xx status, from 0 to 1.",
+                    "type": "number"
+                },
+                "principal": {
+                    "description": "Principal is the username of the user, or the client ID of the service account.",
+                    "type": "string"
+                },
+                "requests": {
+                    "description": "Requests is the number of authenticated requests.",
+                    "type": "integer"
+                },
+                "server_errors": {
+                    "description": "ServerErrors is the number of requests answered with a 5xx status.",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.User": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "CreatedAt is the timestamp of when the user was created.",
+                    "type": "string"
+                },
+                "email": {
+                    "description": "Email is the optional email address of the user, unique and stored in lower case.",
+                    "type": "string"
+                },
+                "email_verified_at": {
+                    "description": "EmailVerifiedAt is the timestamp of when the user confirmed the email address.",
+                    "type": "string"
+                },
+                "role": {
+                    "description": "Role defines the user's permissions, either \"admin\" or \"user\".",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Role"
+                        }
+                    ]
+                },
+                "updated_at": {
+                    "description": "UpdatedAt is the timestamp of the last modification to the user record.",
                     "type": "string"
                 },
                 "username": {
-                    "description": "Username is the unique identifier for the user attempting to log in.",
+                    "description": "Username is the unique identifier for the user.\nIt serves as the primary key in the database and is stored in lower case.",
+                    "type": "string"
+                }
+            }
+        },
+        "models.UserFavorite": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "CreatedAt is when the record was pinned.",
+                    "type": "string"
+                },
+                "record": {
+                    "description": "Record is the pinned record, only set when the favorites are listed with\nexpand=true and the record still exists and is visible to the user.",
+                    "type": "object"
+                },
+                "record_id": {
+                    "description": "RecordID is the tokenized ID of the record, as used in /{resource}/{id}.",
+                    "type": "string"
+                },
+                "resource": {
+                    "description": "Resource is the name of the resource of the record (e.g. \"example1\").",
+                    "type": "string"
+                }
+            }
+        },
+        "models.UserSummary": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "CreatedAt is the timestamp of when the user was created.",
+                    "type": "string"
+                },
+                "email": {
+                    "description": "Email is the email address of the user, if any.",
+                    "type": "string"
+                },
+                "email_verified": {
+                    "description": "EmailVerified is true once the user confirmed the email address.",
+                    "type": "boolean"
+                },
+                "role": {
+                    "description": "Role is the role of the user.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Role"
+                        }
+                    ]
+                },
+                "updated_at": {
+                    "description": "UpdatedAt is the timestamp of the last modification to the user record.",
+                    "type": "string"
+                },
+                "username": {
+                    "description": "Username is the unique identifier of the user.",
+                    "type": "string"
+                }
+            }
+        },
+        "models.ValidationRule": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "CreatedAt is the timestamp of when the rule was created.",
+                    "type": "string"
+                },
+                "enabled": {
+                    "description": "Enabled allows turning a rule off without deleting it.",
+                    "type": "boolean"
+                },
+                "field": {
+                    "description": "Field is the JSON name of the checked field (e.g. \"field2\").",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "ID is the auto-incremented primary key of the rule.",
+                    "type": "integer"
+                },
+                "message": {
+                    "description": "Message is the error returned when the check fails. Empty uses a generic message.",
+                    "type": "string"
+                },
+                "params": {
+                    "description": "Params is the parameter of the check (e.g. \"^[a-z]+$\" for regex or \"10\" for max_length).",
+                    "type": "string"
+                },
+                "resource": {
+                    "description": "Resource is the resource the rule applies to (e.g. \"example1\").",
+                    "type": "string"
+                },
+                "rule": {
+                    "description": "Rule is the check: \"required\", \"regex\", \"min_length\", \"max_length\", \"min\", \"max\" or \"enum\".",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.ValidationRuleType"
+                        }
+                    ]
+                },
+                "updated_at": {
+                    "description": "UpdatedAt is the timestamp of the last modification to the rule.",
+                    "type": "string"
+                }
+            }
+        },
+        "models.ValidationRuleType": {
+            "type": "string",
+            "enum": [
+                "required",
+                "regex",
+                "min_length",
+                "max_length",
+                "min",
+                "max",
+                "enum"
+            ],
+            "x-enum-varnames": [
+                "RuleRequired",
+                "RuleRegex",
+                "RuleMinLength",
+                "RuleMaxLength",
+                "RuleMin",
+                "RuleMax",
+                "RuleEnum"
+            ]
+        },
+        "models.ValueCount": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "description": "Count is the number of sampled rows holding the value.",
+                    "type": "integer"
+                },
+                "value": {
+                    "description": "Value is the column value, or null.",
                     "type": "string"
                 }
             }
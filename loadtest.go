@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/r4ulcl/api_template/client"
+	"github.com/r4ulcl/api_template/client/loadtest"
+)
+
+// runLoadTest runs a load test against a running instance, e.g. in the CI
+// after starting the server, and prints its report. It backs the "loadtest"
+// command: api_template loadtest -url http://localhost:8080 -rate 200 -duration 30s.
+//
+// Parameters:
+// - args: The arguments after "loadtest".
+//
+// Returns:
+// - The process exit code: exitFailure if the test can't run or a threshold
+// is exceeded (a performance regression), 0 otherwise.
+func runLoadTest(args []string) int {
+	flags := flag.NewFlagSet("loadtest", flag.ContinueOnError)
+
+	baseURL := flags.String("url", "http://localhost:"+publicPort, "URL of the API")
+	username := flags.String("user", "admin", "username of the requests; creates and updates need an admin")
+	password := flags.String("password", os.Getenv("ADMIN_PASSWORD"), "password of the user (default $ADMIN_PASSWORD)")
+	token := flags.String("token", os.Getenv("LOADTEST_TOKEN"), "token used instead of logging in ($LOADTEST_TOKEN)")
+	resource := flags.String("resource", "example1", "resource of the requests")
+	mix := flags.String("mix", "list=4,get=3,create=2,update=1", "relative weight of the operations")
+	rate := flags.Float64("rate", 50, "requests per second, 0 for as fast as the workers answer")
+	duration := flags.Duration("duration", 30*time.Second, "how long the requests are sent")
+	workers := flags.Int("workers", 64, "largest number of requests in flight")
+	timeout := flags.Duration("timeout", 10*time.Second, "timeout of each request")
+	pageSize := flags.Int("page-size", 20, "per_page of the lists")
+	seed := flags.Int("seed", 20, "records created before the test for the gets and updates")
+	createBody := flags.String("create-body", `{"field1":"{id}","field2":"loadtest"}`,
+		"JSON body of the creates; {id} is replaced by the unique ID of the record")
+	updateBody := flags.String("update-body", `{"field2":"{id}"}`,
+		"JSON body of the updates; {id} is replaced by a unique value")
+	cleanup := flags.Bool("cleanup", true, "delete the records created by the test")
+	output := flags.String("output", "", "file the JSON report is written to (- for stdout)")
+	baselineFile := flags.String("baseline", "", "JSON report of a previous run to compare with")
+	maxRegression := flags.Float64("max-regression", 0.2,
+		"largest increase of the p95 and p99 of each operation over the baseline (0.2 for 20%)")
+	maxErrorRateIncrease := flags.Float64("max-error-rate-increase", 0.01,
+		"largest increase of the error rate of each operation over the baseline (0.01 for 1 point)")
+	maxErrorRate := flags.Float64("max-error-rate", 0, "largest error rate (0.01 for 1%), 0 disables it")
+	maxP99 := flags.Duration("max-p99", 0, "largest p99 latency, 0 disables it")
+
+	if err := flags.Parse(args); errors.Is(err, flag.ErrHelp) {
+		return 0
+	} else if err != nil {
+		return exitFailure
+	}
+
+	weights, err := parseMix(*mix)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+
+		return exitFailure
+	}
+
+	var baseline *loadtest.Report
+
+	if *baselineFile != "" {
+		data, err := os.ReadFile(*baselineFile)
+		if err == nil {
+			err = json.Unmarshal(data, &baseline)
+		}
+
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid baseline: %v\n", err)
+
+			return exitFailure
+		}
+	}
+
+	// The connections are reused by the workers, as by the clients of the API
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = *workers
+
+	apiClient, err := client.New(client.Config{
+		BaseURL:    *baseURL,
+		Username:   *username,
+		Password:   *password,
+		Token:      *token,
+		HTTPClient: &http.Client{Transport: transport},
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+
+		return exitFailure
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	report, err := loadtest.Run(ctx, loadtest.Config{
+		Client:     apiClient,
+		BaseURL:    *baseURL,
+		Resource:   *resource,
+		Mix:        weights,
+		Rate:       *rate,
+		Duration:   *duration,
+		Workers:    *workers,
+		Timeout:    *timeout,
+		PageSize:   *pageSize,
+		Seed:       *seed,
+		CreateBody: *createBody,
+		UpdateBody: *updateBody,
+		Cleanup:    *cleanup,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Load test failed: %v\n", err)
+
+		return exitFailure
+	}
+
+	if *output == "-" {
+		_ = json.NewEncoder(os.Stdout).Encode(report)
+	} else {
+		report.Print(os.Stdout)
+
+		if *output != "" {
+			data, _ := json.MarshalIndent(report, "", "  ")
+			if err := os.WriteFile(*output, data, 0o600); err != nil {
+				fmt.Fprintf(os.Stderr, "Error writing the report: %v\n", err)
+
+				return exitFailure
+			}
+		}
+	}
+
+	failures := loadtest.Compare(report, baseline, loadtest.Thresholds{
+		MaxErrorRate:         *maxErrorRate,
+		MaxP99:               *maxP99,
+		MaxRegression:        *maxRegression,
+		MaxErrorRateIncrease: *maxErrorRateIncrease,
+	})
+	for _, failure := range failures {
+		fmt.Fprintf(os.Stderr, "FAIL: %s\n", failure)
+	}
+
+	if len(failures) > 0 {
+		return exitFailure
+	}
+
+	return 0
+}
+
+// parseMix parses the weights of the operations of a load test, e.g. "list=4,get=3".
+func parseMix(value string) (map[string]int, error) {
+	weights := map[string]int{}
+
+	for _, entry := range strings.Split(value, ",") {
+		op, weight, ok := strings.Cut(strings.TrimSpace(entry), "=")
+
+		count, err := strconv.Atoi(weight)
+		if !ok || err != nil {
+			return nil, fmt.Errorf("invalid mix entry %q, expected operation=weight", entry)
+		}
+
+		weights[op] = count
+	}
+
+	return weights, nil
+}
@@ -1,16 +1,30 @@
 package main
 
 import (
+	"context"
+	"flag"
+	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/r4ulcl/api_template/api/controllers"
+	"github.com/r4ulcl/api_template/api/middlewares"
 	"github.com/r4ulcl/api_template/api/routes"
 	"github.com/r4ulcl/api_template/database"
 	_ "github.com/r4ulcl/api_template/docs"
 	"github.com/r4ulcl/api_template/utils"
+	"github.com/r4ulcl/api_template/utils/events"
+	"github.com/r4ulcl/api_template/utils/lock"
+	"github.com/r4ulcl/api_template/utils/mailbox"
 	"github.com/r4ulcl/api_template/utils/models"
+	"github.com/r4ulcl/api_template/utils/notify"
+	"github.com/r4ulcl/api_template/web"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"golang.org/x/sync/singleflight"
 )
 
 // @title Admin API Documentation
@@ -35,21 +49,354 @@ import (
 // @name Authorization
 // @description JWT to login
 
+// eventBufferSize is the number of resource events queued before new ones are dropped.
+const eventBufferSize = 1024
+
+// publicPort is the port of the public listener.
+const publicPort = "8080"
+
 // main is the entry point of the application.
 // It loads the configuration, connects to the database,
 // creates a default admin user, initializes controllers,
 // sets up the router, and starts the HTTP server.
+// With --selftest, it only checks the configuration and the database and exits.
+// With --wait-for-db or --migrate-only, it only waits for the database, and
+// applies the migrations, and exits. The "loadtest" command load tests a
+// running instance instead (see runLoadTest).
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "loadtest" {
+		os.Exit(runLoadTest(os.Args[2:]))
+	}
+
+	selfTest := flag.Bool("selftest", false, "check the configuration, database and migrations, then exit")
+	waitForDB := flag.Bool("wait-for-db", false, "wait until the database answers (DB_WAIT_TIMEOUT), then exit")
+	migrateOnly := flag.Bool("migrate-only", false, "wait for the database and apply the migrations, then exit")
+	flag.Parse()
+
 	// Load application configuration
 	cfg := utils.LoadConfig()
 
+	// Report misconfigurations without starting the server
+	if *selfTest {
+		os.Exit(runSelfTest(cfg))
+	}
+
+	// Run the database tasks of the init containers without starting the server
+	if *waitForDB || *migrateOnly {
+		os.Exit(runDBTask(cfg, *migrateOnly))
+	}
+
 	// Connect to the database using loaded configuration
-	database.ConnectDB(cfg)
+	if err := database.ConnectDB(cfg); err != nil {
+		log.Println(err)
+		os.Exit(dbExitCode(err))
+	}
+
+	// Named locks shared by the replicas, for the hooks and custom actions
+	locker, err := lock.New(database.DB)
+	if err != nil {
+		log.Fatalf("Error creating the locker: %v", err)
+	}
+
+	lock.Default = locker
 
 	// Initialize controllers
-	authController := &controllers.AuthController{Secret: cfg.JWTSecret}
-	baseController := &database.BaseController{DB: database.DB}
-	controller := &controllers.Controller{BC: baseController}
+	baseController := &database.BaseController{
+		DB:                     database.DB,
+		CountEstimateThreshold: int64(cfg.CountEstimateThreshold),
+		QueryLimits: database.QueryCostLimits{
+			MaxInValues:        cfg.QueryMaxInValues,
+			LargeTableRows:     int64(cfg.QueryLargeTableRows),
+			MaxUnindexedOffset: cfg.QueryMaxUnindexedOffset,
+		},
+		Retry: database.RetryPolicy{
+			MaxAttempts: cfg.DBRetryAttempts,
+			BaseDelay:   time.Duration(cfg.DBRetryBaseDelay) * time.Millisecond,
+			MaxDelay:    time.Second,
+		},
+		BatchSize: cfg.DBBatchSize,
+	}
+	authController := &controllers.AuthController{
+		Secret: cfg.JWTSecret,
+		BC:     baseController,
+		Mailer: &utils.Mailer{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.SMTPFrom,
+		},
+		PublicURL:                cfg.PublicURL,
+		EmailTokenTTL:            time.Duration(cfg.EmailTokenTTL) * time.Hour,
+		LoginByEmail:             cfg.LoginByEmail,
+		AllowRegistration:        cfg.AllowRegistration,
+		RequireEmailVerification: cfg.RequireEmailVerification,
+		ServiceTokenTTL:          time.Duration(cfg.ServiceTokenTTL) * time.Second,
+		DeviceTokenTTL:           time.Duration(cfg.DeviceTokenTTL) * 24 * time.Hour,
+	}
+
+	if cfg.TLSClientCAFile != "" {
+		authController.Certificates = baseController
+	}
+	controller := &controllers.Controller{
+		BC:      baseController,
+		Latency: middlewares.NewSlowRouteTracker(cfg.SlowRouteThresholdMs, cfg.SlowRouteWindow, cfg.AlertWebhookURL),
+		Runtime: utils.NewRuntimeConfig(cfg),
+		LockTTL: time.Duration(cfg.RecordLockTTL) * time.Second,
+		Mailer:  authController.Mailer,
+		Exports: controllers.ExportSettings{
+			Dir:         cfg.ExportDir,
+			PartRecords: cfg.ExportPartRecords,
+			TTL:         time.Duration(cfg.ExportTTL) * time.Hour,
+			Secret:      []byte(cfg.JWTSecret),
+		},
+		TxPolicy:   routes.TxPolicy,
+		ProtoCodec: routes.ProtoCodec,
+	}
+
+	// Find the real client IP behind the trusted proxies
+	controller.TrustedProxies, err = utils.ParseCIDRs(cfg.TrustedProxies)
+	if err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
+
+	// Restrict the client IPs allowed globally and on the admin routes
+	controller.IPFilter, err = middlewares.NewIPFilter(cfg.IPAllowlist, cfg.IPDenylist)
+	if err != nil {
+		log.Fatalf("Invalid IP_ALLOWLIST or IP_DENYLIST: %v", err)
+	}
+
+	controller.AdminIPFilter, err = middlewares.NewIPFilter(cfg.AdminIPAllowlist, cfg.AdminIPDenylist)
+	if err != nil {
+		log.Fatalf("Invalid ADMIN_IP_ALLOWLIST or ADMIN_IP_DENYLIST: %v", err)
+	}
+
+	// Limit the writes of each user per resource, e.g. to stop runaway imports
+	controller.WriteLimiter, err = middlewares.NewWriteRateLimiter(cfg.WriteRateLimits)
+	if err != nil {
+		log.Fatalf("Invalid WRITE_RATE_LIMITS: %v", err)
+	}
+
+	// Limit the expensive requests in flight per route group, e.g. the exports
+	controller.Concurrency, err = middlewares.NewConcurrencyLimiter(cfg.ConcurrencyLimits,
+		time.Duration(cfg.ConcurrencyQueueTimeout)*time.Second)
+	if err != nil {
+		log.Fatalf("Invalid CONCURRENCY_LIMITS: %v", err)
+	}
+
+	// Throttle the failed logins per client IP, apart from the rate limit
+	challenge, err := utils.NewChallenge(cfg.ChallengeProvider, cfg.ChallengeSecret)
+	if err != nil {
+		log.Fatalf("Invalid CHALLENGE_PROVIDER or CHALLENGE_SECRET: %v", err)
+	}
+
+	controller.LoginThrottle = middlewares.NewLoginThrottle(challenge, cfg.ChallengeSiteKey, cfg.LoginChallengeAfter,
+		cfg.LoginBlockAfter, time.Duration(cfg.LoginFailureWindow)*time.Second)
+	controller.StepUp = middlewares.NewStepUp(time.Duration(cfg.StepUpMaxAge) * time.Minute)
+
+	// Forward /ext/{service}/ to the auxiliary services
+	if cfg.ProxyServices != "" {
+		controller.Proxy, err = utils.NewServiceProxy(cfg.ProxyServices, cfg.ProxySigningSecret)
+		if err != nil {
+			log.Fatalf("Invalid PROXY_SERVICES or PROXY_SIGNING_SECRET: %v", err)
+		}
+	}
+
+	// Delegate the authorization of the admin routes to OPA or a policy file
+	controller.Policy, err = utils.NewPolicy(cfg.PolicyOPAURL, cfg.PolicyFile)
+	if err != nil {
+		log.Fatalf("Invalid POLICY_OPA_URL or POLICY_FILE: %v", err)
+	}
+
+	// Serve the front-end bundle at /
+	switch {
+	case cfg.StaticDir != "":
+		if _, err := os.Stat(filepath.Join(cfg.StaticDir, "index.html")); err != nil {
+			log.Fatalf("Invalid STATIC_DIR: %v", err)
+		}
+
+		controller.Static = utils.NewSPAHandler(os.DirFS(cfg.StaticDir))
+	case cfg.StaticEmbed:
+		controller.Static = utils.NewSPAHandler(web.Bundle())
+	}
+
+	if cfg.Console {
+		controller.Console = utils.NewSPAHandler(web.Console())
+	}
+
+	if cfg.DevMode {
+		log.Println("Development mode: POST /admin/generate creates synthetic records")
+
+		controller.DevMode = true
+	}
+
+	// Share the reads of identical concurrent GET requests
+	if cfg.RequestCoalescing {
+		controller.Coalescer = &singleflight.Group{}
+	}
+
+	// Cap the page size of the lists of large records
+	controller.PageBudget = database.NewPageBudget(baseController, int64(cfg.ListByteBudget))
+
+	// Apply the runtime parameters stored in the settings and keep them up to date
+	database.DB.Logger = database.NewRuntimeLogger(controller.Runtime)
+	controller.ReloadRuntimeConfig()
+
+	go baseController.WatchRuntimeConfig(context.Background(), controller.Runtime,
+		time.Duration(cfg.RuntimeConfigInterval)*time.Second)
+
+	// Watch the database connection so requests fail fast while it is down
+	controller.Health = database.NewHealthChecker(database.DB,
+		time.Duration(cfg.DBHealthInterval)*time.Second, cfg.DBBreakerFailures)
+	go controller.Health.Start(context.Background())
+
+	// Publish resource change events to the configured message bus
+	controller.Events = events.NewBus(eventBufferSize)
+
+	if cfg.EventBus != "" {
+		publisher, err := events.NewPublisher(cfg.EventBus, cfg.EventBusURL, cfg.EventBusTopic)
+		if err != nil {
+			log.Println("Error initializing event bus:", err)
+		} else {
+			controller.Events.Register(publisher)
+		}
+	}
+
+	if cfg.MQTTBroker != "" {
+		setupMQTTBridge(cfg, controller)
+	}
+
+	// Cache the feature flags checked on every request
+	controller.Flags = database.NewFeatureFlags(baseController)
+	controller.ReloadFeatureFlags()
+
+	// Cache the validation rules checked on every create and update
+	controller.Validation = database.NewValidationRules(baseController)
+	controller.ReloadValidationRules()
+
+	// Send Slack/Teams notifications for resource events and bursts of server errors
+	controller.Notifications = notify.NewDispatcher(cfg.ErrorBurstThreshold,
+		time.Duration(cfg.ErrorBurstWindow)*time.Second)
+	controller.Events.Register(controller.Notifications)
+	controller.Notifications.SetFailureReporter(baseController)
+
+	if cfg.TelegramBotToken != "" {
+		bot := notify.NewTelegramBot(cfg.TelegramBotToken, cfg.TelegramChatID)
+		bot.HandleCommand("stats", controller.StatsSummary)
+		controller.Notifications.RegisterChannel("telegram", bot)
+
+		go bot.Run(context.Background())
+	}
+
+	controller.ReloadNotificationRules()
+
+	// Run the scheduled jobs on one of the replicas sharing the database
+	instanceID := cfg.InstanceID
+	if instanceID == "" {
+		hostname, _ := os.Hostname()
+		instanceID = fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	}
+
+	controller.Leader = database.NewLeaderElector(baseController, database.SchedulerElection, instanceID,
+		time.Duration(cfg.LeaderLeaseTTL)*time.Second)
+	go controller.Leader.Start(context.Background())
+
+	// Store the table statistics periodically to chart their growth, and flag the tables growing too fast
+	if cfg.StatsSnapshotInterval > 0 {
+		if cfg.TableGrowthThreshold > 0 {
+			controller.Growth = database.NewGrowthMonitor(baseController,
+				time.Duration(cfg.TableGrowthWindow)*time.Second, float64(cfg.TableGrowthThreshold),
+				cfg.AlertWebhookURL, controller.Notifications)
+		}
+
+		controller.Leader.Run(context.Background(), "stats_snapshots", func(ctx context.Context) {
+			baseController.WatchStatsSnapshots(ctx, time.Duration(cfg.StatsSnapshotInterval)*time.Second, controller.Growth)
+		})
+	}
+
+	// Run the scheduled reports and send the differences with their previous run
+	if cfg.ReportCheckInterval > 0 {
+		controller.Leader.Run(context.Background(), "reports", func(ctx context.Context) {
+			controller.WatchReports(ctx, time.Duration(cfg.ReportCheckInterval)*time.Second, routes.NewResourceSlice)
+		})
+	}
+
+	// Run the scheduled importers pulling records from external APIs
+	if cfg.ImportCheckInterval > 0 {
+		controller.Leader.Run(context.Background(), "importers", func(ctx context.Context) {
+			controller.WatchImporters(ctx, time.Duration(cfg.ImportCheckInterval)*time.Second, routes.NewResourceModel)
+		})
+	}
+
+	// Meter the requests of each user and service account; every replica flushes its own counts
+	if cfg.UsageFlushInterval > 0 {
+		controller.Usage = middlewares.NewUsageMeter()
+
+		go controller.Usage.Run(context.Background(), time.Duration(cfg.UsageFlushInterval)*time.Second,
+			baseController.AddUsage)
+	}
+
+	// Check the external services the API depends on, for /readyz and the alerts
+	controller.AlertWebhookURL = cfg.AlertWebhookURL
+
+	if cfg.DependencyCheckInterval > 0 {
+		controller.Leader.Run(context.Background(), "dependency_checks", func(ctx context.Context) {
+			controller.WatchDependencies(ctx, time.Duration(cfg.DependencyCheckInterval)*time.Second)
+		})
+	}
+
+	// Sign the exports of the audit log
+	controller.AuditSigningKey, err = utils.ParseSigningKey(cfg.AuditSigningKey)
+	if err != nil {
+		log.Fatalf("Invalid AUDIT_SIGNING_KEY: %v", err)
+	}
+
+	// Convert the emails of the mailbox into records, by the stored email rules
+	controller.MailgunSigningKey = cfg.MailgunSigningKey
+
+	if cfg.EmailIMAPURL != "" && cfg.EmailPollInterval > 0 {
+		box, err := mailbox.New(cfg.EmailIMAPURL)
+		if err != nil {
+			log.Fatalf("Invalid EMAIL_IMAP_URL: %v", err)
+		}
+
+		controller.Leader.Run(context.Background(), "email_ingest", func(ctx context.Context) {
+			controller.WatchMailbox(ctx, time.Duration(cfg.EmailPollInterval)*time.Second, box, routes.NewResourceModel)
+		})
+	}
+
+	// The payloads of the bulk imports are only kept in memory, so the unfinished jobs are lost
+	if interrupted, err := baseController.FailInterruptedBulkJobs(time.Now()); err != nil {
+		log.Println("Error failing the interrupted bulk jobs:", err)
+	} else if interrupted > 0 {
+		log.Printf("%d bulk jobs interrupted by the restart marked as failed", interrupted)
+	}
+
+	if interrupted, err := baseController.FailInterruptedExportJobs(time.Now()); err != nil {
+		log.Println("Error failing the interrupted exports:", err)
+	} else if interrupted > 0 {
+		log.Printf("%d exports interrupted by the restart marked as failed", interrupted)
+	}
+
+	// Delete the expired exports and their files
+	if controller.Exports.Dir != "" {
+		controller.Leader.Run(context.Background(), "export_cleanup", func(ctx context.Context) {
+			controller.WatchExports(ctx, time.Hour)
+		})
+	}
+
+	reporters := middlewares.Reporters{controller.Notifications}
+
+	if cfg.SentryDSN != "" {
+		reporter, err := utils.NewSentryReporter(cfg.SentryDSN, cfg.SentryEnvironment)
+		if err != nil {
+			log.Println("Error initializing Sentry:", err)
+		} else {
+			reporters = append(reporters, reporter)
+		}
+	}
+
+	controller.Reporter = reporters
 
 	username := "admin"
 	user := models.User{
@@ -58,21 +405,165 @@ func main() {
 		Password: cfg.AdminPassword,
 	}
 
-	user, err := authController.RegisterUser(user)
+	user, err = authController.RegisterUser(user)
 	if err != nil {
 		log.Println("Error creating admin user")
 	} else {
 		log.Println("User created: ", user)
 	}
 
+	// Create the accounts of the environment (e.g. the test accounts of staging)
+	if cfg.BootstrapUsers != "" {
+		users, err := utils.LoadBootstrapUsers(cfg.BootstrapUsers)
+		if err != nil {
+			log.Fatalf("Invalid BOOTSTRAP_USERS: %v", err)
+		}
+
+		created, updated, err := baseController.ApplyBootstrapUsers(users)
+		if err != nil {
+			log.Fatalf("Error applying BOOTSTRAP_USERS: %v", err)
+		}
+
+		log.Printf("Bootstrap users: %d created, %d updated, %d unchanged", created, updated, len(users)-created-updated)
+	}
+
+	// Without ADMIN_PASSWORD or an admin in BOOTSTRAP_USERS, the first admin is created with POST /bootstrap
+	if count, err := baseController.CountAdmins(); err != nil {
+		log.Println("Error counting the admins:", err)
+	} else if count == 0 {
+		authController.BootstrapToken = cfg.BootstrapToken
+		if authController.BootstrapToken == "" {
+			authController.BootstrapToken, err = utils.NewBootstrapToken()
+			if err != nil {
+				log.Fatalf("Error generating the bootstrap token: %v", err)
+			}
+
+			fmt.Println("Bootstrap token:", authController.BootstrapToken)
+		}
+
+		log.Println("No admin exists: create the first admin with POST /bootstrap")
+	}
+
 	// Setup the router
 	r := routes.SetupRouter(controller, authController, cfg.JWTSecret)
 
+	var handler http.Handler = r
+
+	// Keep the management routes off the public port, on a listener of their own
+	if cfg.AdminListenAddr != "" {
+		handler = routes.PublicHandler(r)
+
+		adminSrv := newServer(cfg, routes.ManagementHandler(r))
+		adminSrv.Addr = cfg.AdminListenAddr
+
+		log.Println("Serving the management routes on", cfg.AdminListenAddr)
+
+		go func() { log.Fatal(listen(cfg, adminSrv)) }()
+	}
+
+	if cfg.TLSCertFile == "" && cfg.TLSClientCAFile != "" {
+		log.Println("TLS_CLIENT_CA_FILE is ignored without TLS_CERT_FILE and TLS_KEY_FILE")
+	}
+
+	if cfg.TLSCertFile != "" && cfg.H2C {
+		log.Println("H2C is ignored with TLS, HTTP/2 is negotiated over TLS instead")
+	}
+
+	log.Fatal(listen(cfg, newServer(cfg, handler)))
+}
+
+// listen serves HTTP, or HTTPS when a certificate is configured, verifying the
+// client certificates if a client CA is configured.
+func listen(cfg *utils.Config, srv *http.Server) error {
+	if cfg.TLSCertFile == "" {
+		return srv.ListenAndServe()
+	}
+
+	tlsConfig, err := utils.NewServerTLSConfig(cfg.TLSClientCAFile, cfg.TLSClientAuth)
+	if err != nil {
+		return fmt.Errorf("TLS configuration failed: %w", err)
+	}
+
+	srv.TLSConfig = tlsConfig
+
+	return srv.ListenAndServeTLS(cfg.TLSCertFile, cfg.TLSKeyFile)
+}
+
+// newServer creates the HTTP server with the timeouts, keep-alive and HTTP/2
+// settings of the configuration.
+//
+// HTTP/2 is always available over TLS. With H2C, it is also served without
+// TLS, for internal deployments behind proxies that speak HTTP/2 in clear text.
+func newServer(cfg *utils.Config, handler http.Handler) *http.Server {
+	h2 := &http2.Server{
+		MaxConcurrentStreams: uint32(max(cfg.HTTP2MaxConcurrentStreams, 0)),
+		IdleTimeout:          time.Duration(cfg.HTTPIdleTimeout) * time.Second,
+	}
+
+	if cfg.H2C && cfg.TLSCertFile == "" {
+		handler = h2c.NewHandler(handler, h2)
+	}
+
 	srv := &http.Server{
-		Addr:         ":8080",
-		Handler:      r,
-		ReadTimeout:  5 * time.Second,
-		WriteTimeout: 10 * time.Second,
+		Addr:              ":" + publicPort,
+		Handler:           handler,
+		ReadTimeout:       time.Duration(cfg.HTTPReadTimeout) * time.Second,
+		ReadHeaderTimeout: time.Duration(cfg.HTTPReadHeaderTimeout) * time.Second,
+		WriteTimeout:      time.Duration(cfg.HTTPWriteTimeout) * time.Second,
+		IdleTimeout:       time.Duration(cfg.HTTPIdleTimeout) * time.Second,
+		MaxHeaderBytes:    cfg.HTTPMaxHeaderBytes,
+	}
+
+	srv.SetKeepAlivesEnabled(cfg.HTTPKeepAlive)
+
+	if err := http2.ConfigureServer(srv, h2); err != nil {
+		log.Println("Error configuring HTTP/2:", err)
+	}
+
+	return srv
+}
+
+// setupMQTTBridge connects to the MQTT broker, registers it as an event publisher
+// and, if a command topic is configured, executes the commands received on it
+// as the configured service user.
+func setupMQTTBridge(cfg *utils.Config, controller *controllers.Controller) {
+	bridge, err := events.NewMQTTBridge(events.MQTTOptions{
+		Broker:      cfg.MQTTBroker,
+		ClientID:    cfg.MQTTClientID,
+		Username:    cfg.MQTTUsername,
+		Password:    cfg.MQTTPassword,
+		TopicPrefix: cfg.MQTTTopicPrefix,
+	})
+	if err != nil {
+		log.Println("Error connecting to MQTT broker:", err)
+
+		return
+	}
+
+	controller.Events.Register(bridge)
+
+	if cfg.MQTTCommandTopic == "" {
+		return
+	}
+
+	// Commands are writes, so they must run as an existing admin user
+	var serviceUser models.User
+	if err := controller.BC.GetRecordsByID(&serviceUser, utils.NormalizeUsername(cfg.MQTTServiceUser)); err != nil ||
+		serviceUser.Role != models.AdminRole {
+		log.Printf("MQTT commands disabled: service user %q not found or not an admin", cfg.MQTTServiceUser)
+
+		return
+	}
+
+	err = bridge.SubscribeCommands(cfg.MQTTCommandTopic, func(cmd models.ResourceCommand) error {
+		model, ok := routes.NewResourceModel(cmd.Resource)
+		if !ok {
+			return fmt.Errorf("unknown resource %q", cmd.Resource)
+		}
+
+		return controller.ApplyCommand(cmd, model, serviceUser.Username)
+	})
+	if err != nil {
+		log.Println("Error subscribing to MQTT command topic:", err)
 	}
-	log.Fatal(srv.ListenAndServe())
 }
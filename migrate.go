@@ -0,0 +1,79 @@
+package main
+
+import (
+	"errors"
+	"log"
+
+	"github.com/r4ulcl/api_template/database"
+	"github.com/r4ulcl/api_template/utils"
+)
+
+// Exit codes of the process, so init containers and orchestrators can tell
+// the failures apart.
+const (
+	// exitFailure is a failed self-test or an invalid configuration.
+	exitFailure = 1
+
+	// exitDBUnavailable is a database that didn't answer within DB_WAIT_TIMEOUT.
+	exitDBUnavailable = 2
+
+	// exitMigrationFailed is a migration that failed.
+	exitMigrationFailed = 3
+
+	// exitPendingMigrations is a server refusing to start with pending migrations (DB_AUTO_MIGRATE=false).
+	exitPendingMigrations = 4
+)
+
+// dbExitCode returns the exit code of an error of database.ConnectDB or database.WaitForDB.
+func dbExitCode(err error) int {
+	switch {
+	case errors.Is(err, database.ErrDBUnavailable):
+		return exitDBUnavailable
+	case errors.Is(err, database.ErrMigrationFailed):
+		return exitMigrationFailed
+	case errors.Is(err, database.ErrPendingMigrations):
+		return exitPendingMigrations
+	}
+
+	return exitFailure
+}
+
+// runDBTask waits for the database and, with migrate, applies the migrations,
+// without starting the server. It backs --wait-for-db and --migrate-only,
+// e.g. for the init containers of a Kubernetes deployment.
+//
+// Parameters:
+// - cfg: The loaded configuration.
+// - migrate: Whether to apply the migrations once the database answers.
+//
+// Returns:
+// - The process exit code: 0 on success, exitDBUnavailable or exitMigrationFailed otherwise.
+func runDBTask(cfg *utils.Config, migrate bool) int {
+	db, err := database.WaitForDB(cfg)
+	if err != nil {
+		log.Println(err)
+
+		return exitDBUnavailable
+	}
+
+	if !migrate {
+		return 0
+	}
+
+	pending, err := database.PendingMigrations(db)
+	if err == nil {
+		log.Printf("%d pending migrations", len(pending))
+
+		err = database.Migrate(db.Debug())
+	}
+
+	if err != nil {
+		log.Println("Migration failed:", err)
+
+		return exitMigrationFailed
+	}
+
+	log.Println("Migrations applied")
+
+	return 0
+}
@@ -0,0 +1,340 @@
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/r4ulcl/api_template/api/middlewares"
+	"github.com/r4ulcl/api_template/database"
+	"github.com/r4ulcl/api_template/utils"
+	"gorm.io/gorm"
+)
+
+// defaultJWTSecret is the JWT_SECRET used when the variable is not set.
+const defaultJWTSecret = "your_jwt_secret_key"
+
+// minJWTSecretLength is the minimum JWT_SECRET length in bytes (256 bits for HS256).
+const minJWTSecretLength = 32
+
+// Self-test check results.
+const (
+	checkOK   = "OK"
+	checkWarn = "WARN"
+	checkFail = "FAIL"
+)
+
+// selfTestCheck is the result of one self-test check.
+type selfTestCheck struct {
+	Name   string
+	Status string
+	Detail string
+}
+
+// runSelfTest checks the configuration, the database connection and the pending
+// migrations without starting the server, and prints a report to stdout.
+//
+// Nothing is written to the database.
+//
+// Parameters:
+// - cfg: The loaded configuration.
+//
+// Returns:
+// - The process exit code: 1 if any check failed, 0 otherwise.
+func runSelfTest(cfg *utils.Config) int {
+	checks := []selfTestCheck{
+		checkJWT(cfg),
+		checkAdminPassword(cfg),
+		checkBootstrapUsers(cfg),
+		checkNetwork(cfg),
+		checkProxy(cfg),
+		checkWriteLimits(cfg),
+		checkPolicy(cfg),
+		checkTLS(cfg),
+		checkStatic(cfg),
+	}
+
+	checks = append(checks, checkDatabase(cfg)...)
+
+	exitCode := 0
+
+	for _, check := range checks {
+		fmt.Printf("[%-4s] %s: %s\n", check.Status, check.Name, check.Detail)
+
+		if check.Status == checkFail {
+			exitCode = exitFailure
+		}
+	}
+
+	if exitCode != 0 {
+		fmt.Println("Self-test failed")
+	} else {
+		fmt.Println("Self-test passed")
+	}
+
+	return exitCode
+}
+
+// checkJWT validates the JWT secret and token lifetimes, and signs and parses a test token.
+func checkJWT(cfg *utils.Config) selfTestCheck {
+	check := selfTestCheck{Name: "jwt", Status: checkFail}
+
+	switch {
+	case cfg.JWTSecret == "":
+		check.Detail = "JWT_SECRET is empty"
+	case cfg.JWTSecret == defaultJWTSecret:
+		check.Detail = "JWT_SECRET is the default value"
+	case len(cfg.JWTSecret) < minJWTSecretLength:
+		check.Detail = fmt.Sprintf("JWT_SECRET must be at least %d bytes long", minJWTSecretLength)
+	case cfg.ServiceTokenTTL <= 0:
+		check.Detail = "SERVICE_TOKEN_TTL must be positive"
+	case cfg.EmailTokenTTL <= 0:
+		check.Detail = "EMAIL_TOKEN_TTL must be positive"
+	default:
+		token, err := utils.GenerateJWT("selftest", "user", cfg.JWTSecret)
+		if err == nil {
+			_, err = utils.ParseJWT(token, cfg.JWTSecret)
+		}
+
+		if err != nil {
+			check.Detail = "test token failed: " + err.Error()
+
+			return check
+		}
+
+		check.Status = checkOK
+		check.Detail = "secret and token lifetimes are valid"
+	}
+
+	return check
+}
+
+// checkAdminPassword warns when the default admin user would be created
+// without a password, unless the first admin is provisioned with BOOTSTRAP_TOKEN.
+func checkAdminPassword(cfg *utils.Config) selfTestCheck {
+	if cfg.AdminPassword == "" && cfg.BootstrapToken != "" {
+		return selfTestCheck{Name: "admin", Status: checkOK, Detail: "the first admin is created with POST /bootstrap"}
+	}
+
+	if cfg.AdminPassword == "" {
+		return selfTestCheck{Name: "admin", Status: checkWarn,
+			Detail: "ADMIN_PASSWORD is empty, the bootstrap token is printed at startup if there is no admin"}
+	}
+
+	return selfTestCheck{Name: "admin", Status: checkOK, Detail: "ADMIN_PASSWORD is set"}
+}
+
+// checkBootstrapUsers reads the BOOTSTRAP_USERS file, if any.
+func checkBootstrapUsers(cfg *utils.Config) selfTestCheck {
+	if cfg.BootstrapUsers == "" {
+		return selfTestCheck{Name: "bootstrap_users", Status: checkOK, Detail: "disabled"}
+	}
+
+	users, err := utils.LoadBootstrapUsers(cfg.BootstrapUsers)
+	if err != nil {
+		return selfTestCheck{Name: "bootstrap_users", Status: checkFail, Detail: "invalid BOOTSTRAP_USERS: " + err.Error()}
+	}
+
+	return selfTestCheck{Name: "bootstrap_users", Status: checkOK, Detail: fmt.Sprintf("%d users", len(users))}
+}
+
+// checkNetwork parses the trusted proxies and the IP allowlists and denylists.
+func checkNetwork(cfg *utils.Config) selfTestCheck {
+	check := selfTestCheck{Name: "network", Status: checkFail}
+
+	if _, err := utils.ParseCIDRs(cfg.TrustedProxies); err != nil {
+		check.Detail = "invalid TRUSTED_PROXIES: " + err.Error()
+
+		return check
+	}
+
+	if _, err := middlewares.NewIPFilter(cfg.IPAllowlist, cfg.IPDenylist); err != nil {
+		check.Detail = "invalid IP_ALLOWLIST or IP_DENYLIST: " + err.Error()
+
+		return check
+	}
+
+	if _, err := middlewares.NewIPFilter(cfg.AdminIPAllowlist, cfg.AdminIPDenylist); err != nil {
+		check.Detail = "invalid ADMIN_IP_ALLOWLIST or ADMIN_IP_DENYLIST: " + err.Error()
+
+		return check
+	}
+
+	if cfg.AdminListenAddr != "" {
+		if _, port, err := net.SplitHostPort(cfg.AdminListenAddr); err != nil || port == publicPort {
+			check.Detail = "ADMIN_LISTEN_ADDR must be a host:port other than the public port " + publicPort
+
+			return check
+		}
+	}
+
+	check.Status = checkOK
+	check.Detail = "trusted proxies, IP lists and listen addresses are valid"
+
+	return check
+}
+
+// checkProxy parses the auxiliary services, if any.
+func checkProxy(cfg *utils.Config) selfTestCheck {
+	if cfg.ProxyServices == "" {
+		return selfTestCheck{Name: "proxy", Status: checkOK, Detail: "disabled"}
+	}
+
+	if _, err := utils.NewServiceProxy(cfg.ProxyServices, cfg.ProxySigningSecret); err != nil {
+		return selfTestCheck{Name: "proxy", Status: checkFail,
+			Detail: "invalid PROXY_SERVICES or PROXY_SIGNING_SECRET: " + err.Error()}
+	}
+
+	return selfTestCheck{Name: "proxy", Status: checkOK, Detail: "services are valid"}
+}
+
+// checkWriteLimits parses the write rate limits, if any.
+func checkWriteLimits(cfg *utils.Config) selfTestCheck {
+	if cfg.WriteRateLimits == "" {
+		return selfTestCheck{Name: "write_limits", Status: checkOK, Detail: "disabled"}
+	}
+
+	if _, err := middlewares.NewWriteRateLimiter(cfg.WriteRateLimits); err != nil {
+		return selfTestCheck{Name: "write_limits", Status: checkFail, Detail: "invalid WRITE_RATE_LIMITS: " + err.Error()}
+	}
+
+	return selfTestCheck{Name: "write_limits", Status: checkOK, Detail: "limits are valid"}
+}
+
+// checkPolicy loads the authorization policy, if any.
+func checkPolicy(cfg *utils.Config) selfTestCheck {
+	policy, err := utils.NewPolicy(cfg.PolicyOPAURL, cfg.PolicyFile)
+
+	switch {
+	case err != nil:
+		return selfTestCheck{Name: "policy", Status: checkFail,
+			Detail: "invalid POLICY_OPA_URL or POLICY_FILE: " + err.Error()}
+	case policy == nil:
+		return selfTestCheck{Name: "policy", Status: checkOK, Detail: "disabled, admin routes are admin-only"}
+	case cfg.PolicyOPAURL != "":
+		return selfTestCheck{Name: "policy", Status: checkOK, Detail: "admin routes decided by OPA"}
+	}
+
+	return selfTestCheck{Name: "policy", Status: checkOK, Detail: "admin routes decided by the policy file"}
+}
+
+// checkTLS loads the server certificate and the client CA, if configured.
+func checkTLS(cfg *utils.Config) selfTestCheck {
+	check := selfTestCheck{Name: "tls", Status: checkFail}
+
+	if cfg.TLSCertFile == "" {
+		check.Status = checkOK
+		check.Detail = "disabled, serving plain HTTP"
+
+		if cfg.TLSClientCAFile != "" {
+			check.Status = checkWarn
+			check.Detail = "TLS_CLIENT_CA_FILE is ignored without TLS_CERT_FILE and TLS_KEY_FILE"
+		}
+
+		return check
+	}
+
+	if _, err := tls.LoadX509KeyPair(cfg.TLSCertFile, cfg.TLSKeyFile); err != nil {
+		check.Detail = "invalid TLS_CERT_FILE or TLS_KEY_FILE: " + err.Error()
+
+		return check
+	}
+
+	if _, err := utils.NewServerTLSConfig(cfg.TLSClientCAFile, cfg.TLSClientAuth); err != nil {
+		check.Detail = err.Error()
+
+		return check
+	}
+
+	check.Status = checkOK
+	check.Detail = "certificate loaded"
+
+	return check
+}
+
+// checkStatic verifies that the front-end directory contains an index.html.
+func checkStatic(cfg *utils.Config) selfTestCheck {
+	if cfg.StaticDir == "" {
+		return selfTestCheck{Name: "static", Status: checkOK, Detail: "no STATIC_DIR"}
+	}
+
+	if _, err := os.Stat(filepath.Join(cfg.StaticDir, "index.html")); err != nil {
+		return selfTestCheck{Name: "static", Status: checkFail, Detail: "invalid STATIC_DIR: " + err.Error()}
+	}
+
+	return selfTestCheck{Name: "static", Status: checkOK, Detail: cfg.StaticDir}
+}
+
+// checkDatabase connects to the database once and lists the pending migrations.
+func checkDatabase(cfg *utils.Config) []selfTestCheck {
+	db, err := database.OpenDB(cfg)
+	if err == nil {
+		// gorm.Open doesn't always connect, so the connection is checked explicitly
+		if sqlDB, dbErr := db.DB(); dbErr != nil {
+			err = dbErr
+		} else {
+			err = sqlDB.Ping()
+		}
+	}
+
+	if err != nil {
+		return []selfTestCheck{{Name: "database", Status: checkFail, Detail: err.Error()}}
+	}
+
+	checks := []selfTestCheck{{Name: "database", Status: checkOK,
+		Detail: fmt.Sprintf("connected to %s:%s/%s", cfg.DBHost, cfg.DBPort, cfg.DBName)}}
+
+	checks = append(checks, checkDatabaseEncryption(cfg, db))
+
+	pending, err := database.PendingMigrations(db)
+
+	switch {
+	case err != nil:
+		checks = append(checks, selfTestCheck{Name: "migrations", Status: checkFail, Detail: err.Error()})
+	case len(pending) == 0:
+		checks = append(checks, selfTestCheck{Name: "migrations", Status: checkOK, Detail: "schema is up to date"})
+	case !cfg.DBAutoMigrate:
+		checks = append(checks, selfTestCheck{Name: "migrations", Status: checkFail,
+			Detail: fmt.Sprintf("%d pending, the server refuses to start with DB_AUTO_MIGRATE=false: %s",
+				len(pending), strings.Join(pending, ", "))})
+	default:
+		checks = append(checks, selfTestCheck{Name: "migrations", Status: checkOK,
+			Detail: fmt.Sprintf("%d pending, applied at startup: %s", len(pending), strings.Join(pending, ", "))})
+	}
+
+	return checks
+}
+
+// checkDatabaseEncryption verifies that the connection is encrypted when DB_TLS
+// requires it, and reports the tables encrypted at rest.
+func checkDatabaseEncryption(cfg *utils.Config, db *gorm.DB) selfTestCheck {
+	check := selfTestCheck{Name: "database encryption", Status: checkFail}
+
+	status, err := database.Encryption(db)
+	if err != nil {
+		check.Status = checkWarn
+		check.Detail = "not reported by the server: " + err.Error()
+
+		return check
+	}
+
+	transit := "connection not encrypted"
+	if status.Cipher != "" {
+		transit = "connection encrypted with " + status.Cipher
+	}
+
+	check.Detail = fmt.Sprintf("%s, %d of %d tables encrypted at rest", transit, status.EncryptedTables, status.Tables)
+
+	if cfg.DBTLS != "" && status.Cipher == "" {
+		check.Detail += ", although DB_TLS is set"
+
+		return check
+	}
+
+	check.Status = checkOK
+
+	return check
+}
@@ -0,0 +1,144 @@
+package testsupport
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// GoldenDir is the directory, relative to the package under test, where golden responses are stored.
+const GoldenDir = "testdata/golden"
+
+// UpdateGoldenEnv is the environment variable that, when set to a non-empty value,
+// records the responses as the new golden files instead of comparing them.
+const UpdateGoldenEnv = "UPDATE_GOLDEN"
+
+// Placeholders replacing the values that change between runs.
+const (
+	timestampPlaceholder = "<timestamp>"
+	idPlaceholder        = "<id>"
+)
+
+// defaultVolatileKeys lists the JSON keys whose values are replaced by a placeholder
+// because they change between runs.
+var defaultVolatileKeys = []string{"id", "request_id", "token", "access_token", "client_secret"}
+
+// goldenNameReplacer matches the characters not allowed in golden file names.
+var goldenNameReplacer = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+// goldenResponse is the content of a golden file.
+type goldenResponse struct {
+	Status int         `json:"status"`
+	Body   interface{} `json:"body"`
+}
+
+// AssertGolden compares a response with the golden file of the given name.
+//
+// The status code and the JSON body are compared after normalization: timestamps
+// and the values of volatile keys (id, request_id, token, ... plus the given
+// extra keys) are replaced by placeholders, so only changes in the response shape
+// and in the stable values fail the test.
+//
+// Run the tests with UPDATE_GOLDEN=1 to record the current responses as the
+// golden files, then review and commit them.
+//
+// Parameters:
+// - t: The test comparing the response.
+// - name: The name of the golden file (e.g. "example1_list"), without extension.
+// - rec: The recorded response.
+// - volatileKeys: Extra JSON keys whose values are ignored (case-insensitive).
+func AssertGolden(t testing.TB, name string, rec *httptest.ResponseRecorder, volatileKeys ...string) {
+	t.Helper()
+
+	keys := slices.Clone(defaultVolatileKeys)
+	for _, key := range volatileKeys {
+		keys = append(keys, strings.ToLower(key))
+	}
+
+	got, err := normalizeResponse(rec, keys)
+	if err != nil {
+		t.Fatalf("testsupport: normalizing response %q: %v", name, err)
+	}
+
+	path := filepath.Join(GoldenDir, goldenNameReplacer.ReplaceAllString(name, "_")+".json")
+
+	if os.Getenv(UpdateGoldenEnv) != "" {
+		if err := os.MkdirAll(GoldenDir, 0o755); err != nil {
+			t.Fatalf("testsupport: creating %s: %v", GoldenDir, err)
+		}
+
+		if err := os.WriteFile(path, got, 0o600); err != nil {
+			t.Fatalf("testsupport: writing golden file: %v", err)
+		}
+
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("testsupport: reading golden file (run with %s=1 to create it): %v", UpdateGoldenEnv, err)
+	}
+
+	if !bytes.Equal(bytes.TrimSpace(want), bytes.TrimSpace(got)) {
+		t.Errorf("testsupport: response %q differs from %s\n--- want\n%s\n--- got\n%s", name, path, want, got)
+	}
+}
+
+// normalizeResponse returns the indented JSON of the status and the normalized body.
+//
+// Bodies that are not JSON are stored as a string.
+func normalizeResponse(rec *httptest.ResponseRecorder, volatileKeys []string) ([]byte, error) {
+	var body interface{}
+
+	if raw := bytes.TrimSpace(rec.Body.Bytes()); len(raw) > 0 {
+		if err := json.Unmarshal(raw, &body); err != nil {
+			body = string(raw)
+		}
+	}
+
+	var out bytes.Buffer
+
+	// Keep the placeholders readable instead of escaping "<" and ">"
+	encoder := json.NewEncoder(&out)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(goldenResponse{Status: rec.Code, Body: normalizeValue(body, volatileKeys)}); err != nil {
+		return nil, err
+	}
+
+	return out.Bytes(), nil
+}
+
+// normalizeValue replaces the timestamps and the values of the volatile keys in a decoded JSON value.
+func normalizeValue(value interface{}, volatileKeys []string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if slices.Contains(volatileKeys, strings.ToLower(key)) && item != nil {
+				v[key] = idPlaceholder
+
+				continue
+			}
+
+			v[key] = normalizeValue(item, volatileKeys)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalizeValue(item, volatileKeys)
+		}
+	case string:
+		if _, err := time.Parse(time.RFC3339Nano, v); err == nil {
+			return timestampPlaceholder
+		}
+	}
+
+	return value
+}
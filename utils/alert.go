@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/r4ulcl/api_template/utils/httpclient"
+)
+
+// SendWebhookAlert posts an alert message to a webhook URL.
+//
+// The payload uses the {"text": "..."} format understood by Slack incoming
+// webhooks, plus a "details" object with the structured alert data.
+//
+// Returns an error if the request fails or the webhook answers with a non-2xx status.
+func SendWebhookAlert(url, text string, details interface{}) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"text":    text,
+		"details": details,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpclient.Default.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
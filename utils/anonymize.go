@@ -0,0 +1,138 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Word lists of the fake values, deliberately small and obviously fictional.
+var (
+	fakeFirstNames = []string{
+		"Alex", "Ana", "Ben", "Carla", "Dan", "Elena", "Femi", "Grace", "Hugo", "Ines",
+		"Jon", "Kira", "Leo", "Maya", "Nico", "Olga", "Pablo", "Quinn", "Rosa", "Sam",
+	}
+	fakeLastNames = []string{
+		"Adams", "Brown", "Costa", "Diaz", "Evans", "Fischer", "Garcia", "Hansen", "Ito", "Jensen",
+		"Kim", "Lopez", "Moreau", "Novak", "Okafor", "Petrov", "Rossi", "Silva", "Tanaka", "Weber",
+	}
+	fakeWords = []string{
+		"lorem", "ipsum", "dolor", "sit", "amet", "consectetur", "adipiscing", "elit", "sed", "do",
+		"eiusmod", "tempor", "incididunt", "ut", "labore", "et", "dolore", "magna", "aliqua", "enim",
+	}
+)
+
+// maxFakeWords is the largest number of words of a fake text.
+const maxFakeWords = 64
+
+// Anonymizer replaces the personal data of records according to the
+// "anonymize" tag of their fields, to share realistic datasets without PII.
+//
+// The tag holds one strategy:
+// - hash: Replace a string with a pseudonym, the first 16 hex characters of its keyed hash.
+// - faker=<kind>: Replace a string with a fake value of a kind: name, email, phone, ip or text.
+// - nullify: Replace the value with the zero value of its type (empty, null or 0).
+//
+// For example `anonymize:"faker=email"`. The same value always gets the same
+// replacement from an anonymizer, so the references between records (e.g.
+// usernames) still match, but the replacements are keyed with a random key,
+// so the values can't be recovered by hashing guesses. Empty values are kept.
+// String and *string fields are replaced, and nested structs anonymized too.
+type Anonymizer struct {
+	key []byte
+}
+
+// NewAnonymizer creates an anonymizer with a random key, e.g. for each dump.
+func NewAnonymizer() (*Anonymizer, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+
+	return &Anonymizer{key: key}, nil
+}
+
+// Anonymize replaces the tagged fields of a record. A nil anonymizer leaves it as is.
+//
+// Parameters:
+// - model: A pointer to the struct to anonymize. Other values are ignored.
+func (a *Anonymizer) Anonymize(model interface{}) {
+	val := reflect.ValueOf(model)
+	if a == nil || val.Kind() != reflect.Ptr || val.IsNil() || val.Elem().Kind() != reflect.Struct {
+		return
+	}
+
+	a.anonymizeStruct(val.Elem())
+}
+
+// anonymizeStruct applies the anonymize strategies to the fields of a struct value.
+func (a *Anonymizer) anonymizeStruct(val reflect.Value) {
+	typ := val.Type()
+
+	for i := range val.NumField() {
+		field := val.Field(i)
+		if !field.CanSet() {
+			continue
+		}
+
+		tag, ok := typ.Field(i).Tag.Lookup("anonymize")
+
+		switch {
+		case ok && tag == "nullify":
+			field.Set(reflect.Zero(field.Type()))
+		case field.Kind() == reflect.Struct:
+			a.anonymizeStruct(field)
+		case !ok:
+			continue
+		case field.Kind() == reflect.String:
+			field.SetString(a.AnonymizeString(field.String(), tag))
+		case field.Kind() == reflect.Ptr && !field.IsNil() && field.Elem().Kind() == reflect.String:
+			field.Elem().SetString(a.AnonymizeString(field.Elem().String(), tag))
+		}
+	}
+}
+
+// AnonymizeString applies an anonymize strategy to a string. See Anonymizer
+// for the strategies; unknown ones hash the value.
+func (a *Anonymizer) AnonymizeString(value, strategy string) string {
+	if value == "" || strategy == "nullify" {
+		return ""
+	}
+
+	mac := hmac.New(sha256.New, a.key)
+	mac.Write([]byte(value))
+	sum := mac.Sum(nil)
+
+	pick := func(list []string, i int) string {
+		return list[int(sum[i%len(sum)])%len(list)]
+	}
+
+	switch strings.TrimPrefix(strategy, "faker=") {
+	case "name":
+		return pick(fakeFirstNames, 0) + " " + pick(fakeLastNames, 1)
+	case "email":
+		// The hash keeps the addresses unique; example.com is reserved for examples
+		return strings.ToLower(pick(fakeFirstNames, 0)+"."+pick(fakeLastNames, 1)) + "." +
+			hex.EncodeToString(sum[2:6]) + "@example.com"
+	case "phone":
+		// 555-01xx numbers are reserved for fiction
+		return fmt.Sprintf("+1-555-01%02d", binary.BigEndian.Uint16(sum[2:4])%100)
+	case "ip":
+		// 192.0.2.0/24 is reserved for documentation
+		return fmt.Sprintf("192.0.2.%d", sum[0])
+	case "text":
+		words := make([]string, min(max(len(strings.Fields(value)), 1), maxFakeWords))
+		for i := range words {
+			words[i] = pick(fakeWords, i)
+		}
+
+		return strings.Join(words, " ")
+	default:
+		return hex.EncodeToString(sum)[:16]
+	}
+}
@@ -0,0 +1,41 @@
+package utils
+
+import "reflect"
+
+// AssigneeTag is the value of the "notify" tag marking the string fields holding
+// the username a record is assigned to, e.g. `notify:"assignee"`.
+const AssigneeTag = "assignee"
+
+// Assignees returns the non-empty values of the fields of a record tagged
+// `notify:"assignee"`, including those of embedded structs.
+//
+// Parameters:
+// - model: A struct, or a pointer to one. Other values, including nil, have no assignees.
+func Assignees(model interface{}) []string {
+	val := reflect.Indirect(reflect.ValueOf(model))
+	if val.Kind() != reflect.Struct {
+		return nil
+	}
+
+	return appendAssignees(nil, val)
+}
+
+// appendAssignees appends the assignees of a struct value.
+func appendAssignees(assignees []string, val reflect.Value) []string {
+	typ := val.Type()
+
+	for i := range val.NumField() {
+		field := typ.Field(i)
+
+		switch {
+		case field.Tag.Get("notify") == AssigneeTag && field.Type.Kind() == reflect.String:
+			if username := val.Field(i).String(); username != "" {
+				assignees = append(assignees, username)
+			}
+		case embeddedStruct(field):
+			assignees = appendAssignees(assignees, val.Field(i))
+		}
+	}
+
+	return assignees
+}
@@ -26,17 +26,52 @@ func CheckPassword(hashedPassword, password string) error {
 	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
 }
 
+// AuthMethodPassword is the "amr" claim value of the tokens issued after checking the password.
+const AuthMethodPassword = "pwd"
+
 // GenerateJWT generates a signed JWT token containing a username and role.
 //
 // The token is signed using the provided secret key and has a validity period
-// of 240 hours.
+// of 240 hours. It records a password authentication made now.
 //
 // Returns the generated JWT token as a string and an error if signing fails.
 func GenerateJWT(username string, role string, secret string) (string, error) {
+	return GenerateSessionJWT(username, role, time.Now(), []string{AuthMethodPassword}, secret)
+}
+
+// GenerateSessionJWT generates a signed JWT token for a user, like GenerateJWT,
+// recording when and how the user authenticated in the "auth_time" and "amr"
+// claims (RFC 8176), e.g. to require a recent authentication for destructive actions.
+//
+// Returns the generated JWT token as a string and an error if signing fails.
+func GenerateSessionJWT(username, role string, authTime time.Time, amr []string, secret string) (string, error) {
+	claims := jwt.MapClaims{
+		"username":  username,
+		"role":      role,
+		"auth_time": authTime.Unix(),
+		"amr":       amr,
+		"exp":       time.Now().Add(time.Hour * 240).Unix(), // 240-hour expiration
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+
+	return token.SignedString([]byte(secret))
+}
+
+// GenerateServiceJWT generates a signed JWT token for a service account.
+//
+// Besides the username (the client ID) and role, the token carries the granted
+// scopes, space-separated, the "service" account type and, as "auth_time", when
+// the client authenticated with its secret.
+//
+// Returns the generated JWT token as a string and an error if signing fails.
+func GenerateServiceJWT(clientID, role, scope string, ttl time.Duration, secret string) (string, error) {
 	claims := jwt.MapClaims{
-		"username": username,
-		"role":     role,
-		"exp":      time.Now().Add(time.Hour * 240).Unix(), // 24-hour expiration
+		"username":     clientID,
+		"role":         role,
+		"scope":        scope,
+		"account_type": "service",
+		"auth_time":    time.Now().Unix(),
+		"exp":          time.Now().Add(ttl).Unix(),
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
@@ -0,0 +1,125 @@
+package utils
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/r4ulcl/api_template/utils/models"
+	"golang.org/x/crypto/bcrypt"
+	"gopkg.in/yaml.v3"
+)
+
+// BootstrapUser is an account of the BOOTSTRAP_USERS file.
+type BootstrapUser struct {
+	// Username is the username, stored in lower case.
+	Username string `yaml:"username"`
+
+	// Role is "admin" or "user". Empty means "user".
+	Role models.Role `yaml:"role"`
+
+	// PasswordHash is the bcrypt hash of the password, e.g. from "htpasswd -bnBC 10 '' password".
+	PasswordHash string `yaml:"password_hash"`
+
+	// Email is the optional email address, considered verified.
+	Email string `yaml:"email"`
+}
+
+// bootstrapFile is the format of the BOOTSTRAP_USERS file.
+type bootstrapFile struct {
+	Users []BootstrapUser `yaml:"users"`
+}
+
+// LoadBootstrapUsers reads the accounts of a BOOTSTRAP_USERS file, in YAML or
+// JSON (a subset of YAML):
+//
+//	users:
+//	  - username: alice
+//	    role: admin
+//	    password_hash: $2a$10$...
+//	  - username: bob
+//	    password_hash: $2a$10$...
+//	    email: bob@example.com
+//
+// Plain passwords are not accepted, so the file can be committed with the
+// configuration of an environment.
+//
+// Returns:
+// - The users, ready to be stored.
+// - An error naming the first invalid entry.
+func LoadBootstrapUsers(path string) ([]models.User, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file bootstrapFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid bootstrap users file: %w", err)
+	}
+
+	users := make([]models.User, 0, len(file.Users))
+	seen := map[string]bool{}
+
+	for i, entry := range file.Users {
+		user, err := entry.user()
+		if err == nil && seen[user.Username] {
+			err = errors.New("duplicate username")
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("bootstrap user %d (%q): %w", i+1, entry.Username, err)
+		}
+
+		seen[user.Username] = true
+		users = append(users, user)
+	}
+
+	return users, nil
+}
+
+// user validates an entry and converts it to a user.
+func (b BootstrapUser) user() (models.User, error) {
+	user := models.User{
+		Username: NormalizeUsername(b.Username),
+		Role:     b.Role,
+		Password: b.PasswordHash,
+	}
+
+	if user.Username == "" {
+		return user, errors.New("username is required")
+	}
+
+	if user.Role == "" {
+		user.Role = models.UserRole
+	}
+
+	if !slices.Contains(user.Role.EnumValues(), string(user.Role)) {
+		return user, fmt.Errorf("invalid role %q", user.Role)
+	}
+
+	if _, err := bcrypt.Cost([]byte(b.PasswordHash)); err != nil {
+		return user, errors.New("password_hash must be a bcrypt hash")
+	}
+
+	if b.Email != "" {
+		email := SanitizeString(b.Email, "trim,lower")
+		user.Email = &email
+	}
+
+	return user, nil
+}
+
+// NewBootstrapToken returns a random token for POST /bootstrap, used when
+// BOOTSTRAP_TOKEN is not set.
+func NewBootstrapToken() (string, error) {
+	raw := make([]byte, 24)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(raw), nil
+}
@@ -0,0 +1,124 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/r4ulcl/api_template/utils/httpclient"
+)
+
+// ErrChallengeFailed is returned for the challenge tokens the provider rejects.
+var ErrChallengeFailed = errors.New("challenge failed")
+
+// Challenge verifies the tokens of a human verification challenge (CAPTCHA)
+// solved by the client, e.g. before a login after too many failures.
+type Challenge interface {
+	// Provider returns the name of the provider, told to the clients to render the challenge.
+	Provider() string
+
+	// Verify checks a token with the provider.
+	//
+	// Returns:
+	// - ErrChallengeFailed if the token is invalid, expired or already used.
+	// - Another error if the provider cannot be reached.
+	Verify(ctx context.Context, token, remoteIP string) error
+}
+
+// challengeURLs are the siteverify endpoints of the supported providers, which share the same API.
+var challengeURLs = map[string]string{
+	"hcaptcha":  "https://api.hcaptcha.com/siteverify",
+	"recaptcha": "https://www.google.com/recaptcha/api/siteverify",
+	"turnstile": "https://challenges.cloudflare.com/turnstile/v0/siteverify",
+}
+
+// challengeClient calls the providers. Verifications are on the path of the
+// logins, so they are not retried: a token can only be verified once anyway.
+var challengeClient = &httpclient.Client{HTTP: &http.Client{Timeout: 5 * time.Second}}
+
+// NewChallenge returns the challenge of a provider: "hcaptcha", "recaptcha"
+// or "turnstile", or nil without provider.
+//
+// Parameters:
+// - provider: The name of the provider, or empty to disable the challenges.
+// - secret: The secret key of the site, given by the provider.
+func NewChallenge(provider, secret string) (Challenge, error) {
+	if provider == "" {
+		return nil, nil
+	}
+
+	verifyURL, ok := challengeURLs[strings.ToLower(provider)]
+	if !ok {
+		return nil, fmt.Errorf("unknown challenge provider %q (expected hcaptcha, recaptcha or turnstile)", provider)
+	}
+
+	if secret == "" {
+		return nil, errors.New("the challenge provider needs a secret key")
+	}
+
+	return &SiteVerifyChallenge{Name: strings.ToLower(provider), URL: verifyURL, Secret: secret}, nil
+}
+
+// SiteVerifyChallenge verifies the tokens with the siteverify API shared by
+// hCaptcha, reCAPTCHA and Cloudflare Turnstile.
+type SiteVerifyChallenge struct {
+	// Name is the name of the provider.
+	Name string
+
+	// URL is the siteverify endpoint of the provider.
+	URL string
+
+	// Secret is the secret key of the site.
+	Secret string
+}
+
+// Provider returns the name of the provider.
+func (c *SiteVerifyChallenge) Provider() string {
+	return c.Name
+}
+
+// Verify posts the token, the secret and the IP of the client to the
+// siteverify endpoint and checks its "success" field.
+func (c *SiteVerifyChallenge) Verify(ctx context.Context, token, remoteIP string) error {
+	form := url.Values{"secret": {c.Secret}, "response": {token}}
+	if remoteIP != "" {
+		form.Set("remoteip", remoteIP)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.URL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := challengeClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned status %d", c.Name, resp.StatusCode)
+	}
+
+	var result struct {
+		Success    bool     `json:"success"`
+		ErrorCodes []string `json:"error-codes"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return err
+	}
+
+	if !result.Success {
+		return fmt.Errorf("%w: %s", ErrChallengeFailed, strings.Join(result.ErrorCodes, ", "))
+	}
+
+	return nil
+}
@@ -0,0 +1,116 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+// ErrInvalidEnum is returned when an enum field holds a value that is not allowed.
+var ErrInvalidEnum = errors.New("invalid enum value")
+
+// enumType is the reflect type of the models.Enum interface.
+var enumType = reflect.TypeOf((*models.Enum)(nil)).Elem()
+
+// EnumValues returns the allowed values of a struct field, or nil if the field is not an enum.
+//
+// A field is an enum when it has an "enums" tag with comma-separated values
+// (e.g. `enums:"info,warning,critical"`, the tag swag also reads for the OpenAPI
+// documentation), or when its type implements models.Enum (e.g. models.Role).
+// The tag takes precedence over the type.
+func EnumValues(field reflect.StructField) []string {
+	if tag, ok := field.Tag.Lookup("enums"); ok {
+		values := strings.Split(tag, ",")
+		for i := range values {
+			values[i] = strings.TrimSpace(values[i])
+		}
+
+		return values
+	}
+
+	typ := field.Type
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	if typ.Implements(enumType) {
+		if enum, ok := reflect.Zero(typ).Interface().(models.Enum); ok {
+			return enum.EnumValues()
+		}
+	}
+
+	return nil
+}
+
+// ValidateEnums checks that the enum fields of a struct hold allowed values.
+//
+// Empty values are accepted, so omitted fields keep their defaults. Nested
+// struct fields are checked too.
+//
+// Parameters:
+// - model: A pointer to the struct to check. Other values are ignored.
+//
+// Returns:
+// - ErrInvalidEnum, listing the allowed values of the first invalid field.
+// - nil if every enum field is valid.
+func ValidateEnums(model interface{}) error {
+	val := reflect.ValueOf(model)
+	if val.Kind() != reflect.Ptr || val.IsNil() || val.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+
+	return validateEnumStruct(val.Elem())
+}
+
+// validateEnumStruct checks the enum fields of a struct value.
+func validateEnumStruct(val reflect.Value) error {
+	typ := val.Type()
+
+	for i := range val.NumField() {
+		field := val.Field(i)
+		structField := typ.Field(i)
+
+		if !structField.IsExported() {
+			continue
+		}
+
+		if field.Kind() == reflect.Struct {
+			if err := validateEnumStruct(field); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		allowed := EnumValues(structField)
+		if allowed == nil {
+			continue
+		}
+
+		if field.Kind() == reflect.Ptr {
+			if field.IsNil() {
+				continue
+			}
+
+			field = field.Elem()
+		}
+
+		value := fmt.Sprint(field.Interface())
+		if value == "" || slices.Contains(allowed, value) {
+			continue
+		}
+
+		name := strings.Split(structField.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			name = structField.Name
+		}
+
+		return fmt.Errorf("%w: %s must be one of %s", ErrInvalidEnum, name, strings.Join(allowed, ", "))
+	}
+
+	return nil
+}
@@ -0,0 +1,122 @@
+// Package events dispatches resource change events to message buses and
+// other subscribers.
+package events
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+// publishTimeout is the maximum time a publisher has to deliver one event.
+const publishTimeout = 10 * time.Second
+
+// Publisher delivers resource events to an external system.
+type Publisher interface {
+	// Publish delivers a single event.
+	Publish(ctx context.Context, event models.ResourceEvent) error
+
+	// Close releases the resources held by the publisher.
+	Close() error
+}
+
+// Bus dispatches events to every registered publisher asynchronously, so a
+// slow or unavailable message bus never delays API responses.
+type Bus struct {
+	mu         sync.RWMutex
+	publishers []Publisher
+	queue      chan models.ResourceEvent
+	done       chan struct{}
+}
+
+// NewBus creates a bus and starts its dispatch loop.
+//
+// Parameters:
+// - bufferSize: The number of events that can be queued before new ones are dropped.
+func NewBus(bufferSize int) *Bus {
+	bus := &Bus{
+		queue: make(chan models.ResourceEvent, bufferSize),
+		done:  make(chan struct{}),
+	}
+
+	go bus.run()
+
+	return bus
+}
+
+// Register adds a publisher to the bus.
+func (b *Bus) Register(publisher Publisher) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.publishers = append(b.publishers, publisher)
+}
+
+// Publish queues an event for delivery. The ID and timestamp are filled in
+// when empty. A nil bus discards the event.
+func (b *Bus) Publish(event models.ResourceEvent) {
+	if b == nil {
+		return
+	}
+
+	if event.ID == "" {
+		event.ID = newEventID()
+	}
+
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now().UTC()
+	}
+
+	select {
+	case b.queue <- event:
+	default:
+		log.Printf("Event queue full, dropping %s event for %s/%s", event.Type, event.Resource, event.RecordID)
+	}
+}
+
+// Close stops accepting events, delivers the queued ones and closes every publisher.
+func (b *Bus) Close() {
+	close(b.queue)
+	<-b.done
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, publisher := range b.publishers {
+		if err := publisher.Close(); err != nil {
+			log.Println("Error closing event publisher:", err)
+		}
+	}
+}
+
+// run delivers queued events until the queue is closed.
+func (b *Bus) run() {
+	defer close(b.done)
+
+	for event := range b.queue {
+		b.mu.RLock()
+		publishers := b.publishers
+		b.mu.RUnlock()
+
+		for _, publisher := range publishers {
+			ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+			if err := publisher.Publish(ctx, event); err != nil {
+				log.Printf("Error publishing %s event for %s/%s: %v", event.Type, event.Resource, event.RecordID, err)
+			}
+			cancel()
+		}
+	}
+}
+
+// newEventID generates a random 16-byte hex-encoded event ID.
+func newEventID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,52 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/r4ulcl/api_template/utils/models"
+	"github.com/segmentio/kafka-go"
+)
+
+// KafkaPublisher publishes events to a Kafka topic.
+//
+// Messages are keyed by "{resource}:{record_id}" so all the events of a record
+// land in the same partition and keep their order.
+type KafkaPublisher struct {
+	writer *kafka.Writer
+}
+
+// NewKafkaPublisher creates a publisher for a topic.
+//
+// Parameters:
+// - brokers: Comma-separated list of broker addresses (e.g. "kafka:9092").
+// - topic: The topic events are written to.
+func NewKafkaPublisher(brokers, topic string) *KafkaPublisher {
+	return &KafkaPublisher{
+		writer: &kafka.Writer{
+			Addr:                   kafka.TCP(strings.Split(brokers, ",")...),
+			Topic:                  topic,
+			Balancer:               &kafka.Hash{},
+			AllowAutoTopicCreation: true,
+		},
+	}
+}
+
+// Publish sends the event as JSON.
+func (kp *KafkaPublisher) Publish(ctx context.Context, event models.ResourceEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	return kp.writer.WriteMessages(ctx, kafka.Message{
+		Key:   []byte(event.Resource + ":" + event.RecordID),
+		Value: payload,
+	})
+}
+
+// Close flushes pending messages and closes the writer.
+func (kp *KafkaPublisher) Close() error {
+	return kp.writer.Close()
+}
@@ -0,0 +1,117 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+// mqttTimeout is the maximum time to wait for the broker to acknowledge an operation.
+const mqttTimeout = 10 * time.Second
+
+// errMQTTTimeout is returned when the broker does not acknowledge an operation in time.
+var errMQTTTimeout = errors.New("timeout waiting for MQTT broker")
+
+// CommandHandler executes a command received from the message bus.
+type CommandHandler func(cmd models.ResourceCommand) error
+
+// MQTTOptions holds the connection settings of the MQTT bridge.
+type MQTTOptions struct {
+	Broker      string // Broker URL (e.g. "tcp://mosquitto:1883")
+	ClientID    string // Client identifier
+	Username    string // Optional broker username
+	Password    string // Optional broker password
+	TopicPrefix string // Prefix of the event topics (e.g. "api")
+}
+
+// MQTTBridge publishes events to topics named "{prefix}/{resource}/{id}/{event}"
+// and can subscribe to a command topic to receive write requests.
+type MQTTBridge struct {
+	client mqtt.Client
+	prefix string
+}
+
+// NewMQTTBridge connects to the MQTT broker. The client reconnects automatically.
+func NewMQTTBridge(opts MQTTOptions) (*MQTTBridge, error) {
+	clientOpts := mqtt.NewClientOptions().
+		AddBroker(opts.Broker).
+		SetClientID(opts.ClientID).
+		SetUsername(opts.Username).
+		SetPassword(opts.Password).
+		SetAutoReconnect(true).
+		SetConnectRetry(true)
+
+	client := mqtt.NewClient(clientOpts)
+
+	token := client.Connect()
+	if !token.WaitTimeout(mqttTimeout) {
+		return nil, errMQTTTimeout
+	}
+
+	if err := token.Error(); err != nil {
+		return nil, err
+	}
+
+	return &MQTTBridge{client: client, prefix: opts.TopicPrefix}, nil
+}
+
+// Publish sends the event as JSON with QoS 1.
+func (mb *MQTTBridge) Publish(_ context.Context, event models.ResourceEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	topic := mb.prefix + "/" + event.Resource + "/" + event.RecordID + "/" + string(event.Type)
+
+	return waitToken(mb.client.Publish(topic, 1, false, payload))
+}
+
+// SubscribeCommands executes every command received on the topic with the handler.
+//
+// The outcome of each command is published as a models.CommandResult to
+// "{topic}/result".
+func (mb *MQTTBridge) SubscribeCommands(topic string, handler CommandHandler) error {
+	return waitToken(mb.client.Subscribe(topic, 1, func(_ mqtt.Client, msg mqtt.Message) {
+		var cmd models.ResourceCommand
+
+		result := models.CommandResult{Status: "ok"}
+
+		err := json.Unmarshal(msg.Payload(), &cmd)
+		if err == nil {
+			result.ID = cmd.ID
+			err = handler(cmd)
+		}
+
+		if err != nil {
+			log.Printf("Error processing MQTT command %q: %v", cmd.ID, err)
+
+			result.Status = "error"
+			result.Error = err.Error()
+		}
+
+		payload, _ := json.Marshal(result)
+		mb.client.Publish(topic+"/result", 1, false, payload)
+	}))
+}
+
+// Close disconnects from the broker.
+func (mb *MQTTBridge) Close() error {
+	mb.client.Disconnect(uint(mqttTimeout.Milliseconds()))
+
+	return nil
+}
+
+// waitToken waits for an MQTT operation to complete.
+func waitToken(token mqtt.Token) error {
+	if !token.WaitTimeout(mqttTimeout) {
+		return errMQTTTimeout
+	}
+
+	return token.Error()
+}
@@ -0,0 +1,48 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/nats-io/nats.go"
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+// NATSPublisher publishes events to NATS subjects named
+// "{prefix}.{resource}.{type}" (e.g. "api.events.example1.created").
+type NATSPublisher struct {
+	conn   *nats.Conn
+	prefix string
+}
+
+// NewNATSPublisher connects to a NATS server. The client reconnects automatically.
+//
+// Parameters:
+// - url: The NATS server URL (e.g. "nats://localhost:4222").
+// - prefix: The subject prefix.
+func NewNATSPublisher(url, prefix string) (*NATSPublisher, error) {
+	conn, err := nats.Connect(url, nats.Name("api_template"), nats.MaxReconnects(-1))
+	if err != nil {
+		return nil, err
+	}
+
+	return &NATSPublisher{conn: conn, prefix: prefix}, nil
+}
+
+// Publish sends the event as JSON.
+func (np *NATSPublisher) Publish(_ context.Context, event models.ResourceEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	return np.conn.Publish(np.prefix+"."+event.Resource+"."+string(event.Type), payload)
+}
+
+// Close flushes pending messages and closes the connection.
+func (np *NATSPublisher) Close() error {
+	err := np.conn.Drain()
+	np.conn.Close()
+
+	return err
+}
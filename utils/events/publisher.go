@@ -0,0 +1,23 @@
+package events
+
+import "fmt"
+
+// NewPublisher creates the message bus publisher selected in the configuration.
+//
+// Parameters:
+// - kind: "nats" or "kafka".
+// - url: The NATS server URL or the comma-separated Kafka brokers.
+// - topic: The NATS subject prefix or the Kafka topic.
+//
+// Returns:
+// - The publisher, or an error if the kind is unknown or the connection fails.
+func NewPublisher(kind, url, topic string) (Publisher, error) {
+	switch kind {
+	case "nats":
+		return NewNATSPublisher(url, topic)
+	case "kafka":
+		return NewKafkaPublisher(url, topic), nil
+	default:
+		return nil, fmt.Errorf("unknown event bus %q (expected nats or kafka)", kind)
+	}
+}
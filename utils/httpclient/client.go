@@ -0,0 +1,278 @@
+// Package httpclient is the shared client of the outbound HTTP calls (webhooks,
+// notification channels, importers, policy servers...): timeouts, retries
+// with jitter, a circuit breaker per host, and per-host metrics.
+package httpclient
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+const (
+	// defaultMaxRetries is the number of retries of a failed request.
+	defaultMaxRetries = 2
+
+	// defaultBaseDelay is the delay before the first retry, doubled on each retry.
+	defaultBaseDelay = 200 * time.Millisecond
+
+	// defaultMaxDelay caps the delay between two attempts, including the Retry-After of the server.
+	defaultMaxDelay = 5 * time.Second
+
+	// failureThreshold is the number of consecutive failures of a host opening its circuit.
+	failureThreshold = 5
+
+	// openDuration is how long an open circuit rejects the requests to its host.
+	openDuration = 30 * time.Second
+)
+
+// ErrCircuitOpen is returned, without sending the request, while the circuit
+// of the host is open after too many consecutive failures.
+var ErrCircuitOpen = errors.New("circuit open")
+
+// Client sends outbound requests. Failed attempts (network errors, 429 and
+// 5xx responses) are retried with exponential backoff and jitter when the
+// body can be replayed, and every client shares the circuit breakers and the
+// metrics of the hosts.
+type Client struct {
+	// HTTP sends each attempt. Its Timeout bounds an attempt, not the retries.
+	HTTP *http.Client
+
+	// MaxRetries is the number of retries after the first attempt. Zero disables retries.
+	MaxRetries int
+
+	// BaseDelay is the delay before the first retry, doubled on each retry, with jitter.
+	BaseDelay time.Duration
+
+	// MaxDelay caps the delay between two attempts.
+	MaxDelay time.Duration
+}
+
+// New creates a client with the default retries.
+//
+// Parameters:
+// - timeout: The maximum time of each attempt.
+func New(timeout time.Duration) *Client {
+	return &Client{
+		HTTP:       &http.Client{Timeout: timeout},
+		MaxRetries: defaultMaxRetries,
+		BaseDelay:  defaultBaseDelay,
+		MaxDelay:   defaultMaxDelay,
+	}
+}
+
+// Default is the client of the calls without specific needs, with a 10 second timeout.
+var Default = New(10 * time.Second)
+
+// Do sends a request, retrying the failed attempts, and returns the last
+// response. As with http.Client, a non-2xx response is not an error: the
+// caller checks the status and closes the body.
+//
+// Returns:
+// - ErrCircuitOpen if the circuit of the host is open.
+// - The error of the last attempt if every attempt failed without a response.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	host := hosts.get(req.URL.Host)
+
+	for attempt := 0; ; attempt++ {
+		if !host.allow(time.Now()) {
+			return nil, fmt.Errorf("%w for %s", ErrCircuitOpen, req.URL.Host)
+		}
+
+		start := time.Now()
+		resp, err := c.HTTP.Do(req)
+		failed := err != nil || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+
+		host.record(start, time.Since(start), failed, attempt > 0, resp, err)
+
+		if !failed || attempt >= c.MaxRetries || !replayable(req) {
+			return resp, err
+		}
+
+		delay := c.backoff(attempt, resp)
+
+		if resp != nil {
+			resp.Body.Close()
+		}
+
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(delay):
+		}
+
+		if req.GetBody != nil {
+			if req.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// backoff returns the delay before the next attempt: the Retry-After of the
+// response if any, otherwise BaseDelay doubled on each attempt with "full
+// jitter", capped by MaxDelay.
+func (c *Client) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			return min(time.Duration(seconds)*time.Second, c.MaxDelay)
+		}
+	}
+
+	ceiling := min(c.BaseDelay<<attempt, c.MaxDelay)
+	if ceiling <= 0 {
+		return 0
+	}
+
+	return time.Duration(rand.Int64N(int64(ceiling)) + 1)
+}
+
+// replayable reports whether the body of a request can be sent again.
+func replayable(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// hostState holds the circuit breaker and the metrics of a host.
+type hostState struct {
+	mu sync.Mutex
+
+	// consecutive is the number of failed attempts since the last success.
+	consecutive int
+
+	// openUntil is the end of the open state of the circuit, zero if closed.
+	openUntil time.Time
+
+	// probing is true while the single attempt allowed after the circuit opened is running.
+	probing bool
+
+	metrics models.OutboundHost
+	latency time.Duration
+}
+
+// allow reports whether an attempt may be sent. Once the circuit has been open
+// for openDuration, a single attempt probes the host: its success closes the
+// circuit, its failure opens it again.
+func (h *hostState) allow(now time.Time) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.openUntil.IsZero() {
+		return true
+	}
+
+	if now.Before(h.openUntil) || h.probing {
+		h.metrics.Rejected++
+
+		return false
+	}
+
+	h.probing = true
+
+	return true
+}
+
+// record updates the circuit and the metrics with the result of an attempt.
+func (h *hostState) record(start time.Time, elapsed time.Duration, failed, retry bool, resp *http.Response, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.metrics.Requests++
+	h.latency += elapsed
+	h.metrics.AvgLatencyMs = float64(h.latency.Microseconds()) / 1000 / float64(h.metrics.Requests)
+	h.probing = false
+
+	if retry {
+		h.metrics.Retries++
+	}
+
+	if !failed {
+		h.consecutive = 0
+		h.openUntil = time.Time{}
+
+		return
+	}
+
+	h.metrics.Failures++
+	h.consecutive++
+
+	lastFailure := start
+	h.metrics.LastFailureAt = &lastFailure
+
+	if err != nil {
+		h.metrics.LastError = err.Error()
+	} else {
+		h.metrics.LastError = "status " + strconv.Itoa(resp.StatusCode)
+	}
+
+	if h.consecutive >= failureThreshold || !h.openUntil.IsZero() {
+		h.openUntil = start.Add(elapsed + openDuration)
+	}
+}
+
+// snapshot returns the metrics of the host.
+func (h *hostState) snapshot(now time.Time) models.OutboundHost {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	metrics := h.metrics
+	metrics.CircuitOpen = !h.openUntil.IsZero() && now.Before(h.openUntil)
+
+	if metrics.LastFailureAt != nil {
+		lastFailure := *metrics.LastFailureAt
+		metrics.LastFailureAt = &lastFailure
+	}
+
+	return metrics
+}
+
+// hostRegistry holds the state of every host called.
+type hostRegistry struct {
+	mu    sync.Mutex
+	hosts map[string]*hostState
+}
+
+// hosts is shared by every client, so a host failing for one call is known to the others.
+var hosts = &hostRegistry{hosts: map[string]*hostState{}}
+
+// get returns the state of a host, created on first use.
+func (r *hostRegistry) get(host string) *hostState {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state, ok := r.hosts[host]
+	if !ok {
+		state = &hostState{metrics: models.OutboundHost{Host: host}}
+		r.hosts[host] = state
+	}
+
+	return state
+}
+
+// Metrics returns the metrics of every host called since startup, sorted by host.
+func Metrics() []models.OutboundHost {
+	hosts.mu.Lock()
+	states := make([]*hostState, 0, len(hosts.hosts))
+
+	for _, state := range hosts.hosts {
+		states = append(states, state)
+	}
+	hosts.mu.Unlock()
+
+	now := time.Now()
+	metrics := make([]models.OutboundHost, 0, len(states))
+
+	for _, state := range states {
+		metrics = append(metrics, state.snapshot(now))
+	}
+
+	sort.Slice(metrics, func(i, j int) bool { return metrics[i].Host < metrics[j].Host })
+
+	return metrics
+}
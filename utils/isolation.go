@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// isolationLevels associates the names accepted by ParseIsolationLevel with the levels.
+var isolationLevels = map[string]sql.IsolationLevel{
+	"read_uncommitted": sql.LevelReadUncommitted,
+	"read_committed":   sql.LevelReadCommitted,
+	"repeatable_read":  sql.LevelRepeatableRead,
+	"serializable":     sql.LevelSerializable,
+}
+
+// ParseIsolationLevel parses the name of a transaction isolation level
+// (e.g. "serializable" or "REPEATABLE READ"). An empty name is the default
+// level of the database server.
+//
+// Returns an error naming the invalid level.
+func ParseIsolationLevel(name string) (sql.IsolationLevel, error) {
+	key := strings.ToLower(strings.Join(strings.FieldsFunc(name, func(r rune) bool {
+		return r == ' ' || r == '_' || r == '-'
+	}), "_"))
+	if key == "" {
+		return sql.LevelDefault, nil
+	}
+
+	level, ok := isolationLevels[key]
+	if !ok {
+		return sql.LevelDefault, fmt.Errorf("invalid isolation level %q", name)
+	}
+
+	return level, nil
+}
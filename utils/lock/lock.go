@@ -0,0 +1,224 @@
+// Package lock serializes critical sections across the replicas sharing the
+// database, with named locks: MySQL GET_LOCK on MySQL, and locks of the
+// process on other databases (e.g. the SQLite database of the tests).
+//
+// The package doesn't import the models, so their GORM hooks can use it.
+package lock
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// namePrefix keeps the lock names of the application apart from the ones
+	// of other applications of the MySQL server, which shares them.
+	namePrefix = "api_template:"
+
+	// maxNameLength is the longest lock name accepted by MySQL.
+	maxNameLength = 64
+
+	// pollInterval is how long one GET_LOCK call waits, so the wait can be
+	// cancelled with the context.
+	pollInterval = time.Second
+)
+
+// ErrNoLocker is returned by Lock before the startup sets Default.
+var ErrNoLocker = errors.New("no locker configured")
+
+// Default is the locker of the application database, set at startup, for
+// the code without access to the controllers (e.g. the GORM hooks of the models).
+var Default *Locker
+
+// Locker takes named locks shared by the replicas.
+type Locker struct {
+	db    *sql.DB
+	mysql bool
+
+	// local holds the locks of the process on other databases, a channel with
+	// room for one holder per name.
+	local sync.Map
+}
+
+// Handle is a held lock, released by Unlock or when its TTL expires.
+type Handle struct {
+	name    string
+	release func()
+	timer   *time.Timer
+	once    sync.Once
+	err     error
+}
+
+// New creates a locker on a database.
+func New(db *gorm.DB) (*Locker, error) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Locker{db: sqlDB, mysql: db.Dialector.Name() == "mysql"}, nil
+}
+
+// Lock takes a named lock with the Default locker.
+func Lock(ctx context.Context, name string, ttl time.Duration) (*Handle, error) {
+	if Default == nil {
+		return nil, ErrNoLocker
+	}
+
+	return Default.Lock(ctx, name, ttl)
+}
+
+// With runs a function holding a named lock of the Default locker.
+func With(ctx context.Context, name string, ttl time.Duration, fn func() error) error {
+	l, err := Lock(ctx, name, ttl)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = l.Unlock() }()
+
+	return fn()
+}
+
+// Lock takes a named lock, waiting for its holder to release it until the
+// context is done.
+//
+// The lock is released by Unlock or, if the holder never calls it, after
+// the TTL, so a stuck holder doesn't block the other replicas forever. On
+// MySQL, the lock is also released if the process dies, since the server
+// drops the locks of closed connections.
+//
+// Parameters:
+// - ctx: Bounds the wait for the lock.
+// - name: The name of the lock, shared by the replicas (e.g. "invoice:42").
+// - ttl: How long the lock is held at most. Zero holds it until Unlock.
+//
+// Returns:
+// - The held lock.
+// - The error of the context if it is done before the lock is free.
+func (lk *Locker) Lock(ctx context.Context, name string, ttl time.Duration) (*Handle, error) {
+	var (
+		l   *Handle
+		err error
+	)
+
+	if lk.mysql {
+		l, err = lk.lockMySQL(ctx, name)
+	} else {
+		l, err = lk.lockLocal(ctx, name)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if ttl > 0 {
+		l.timer = time.AfterFunc(ttl, func() {
+			log.Printf("Lock %q released after its TTL of %s", name, ttl)
+
+			_ = l.Unlock()
+		})
+	}
+
+	return l, nil
+}
+
+// lockMySQL takes a lock with GET_LOCK on a connection of its own, which
+// holds the lock until RELEASE_LOCK.
+func (lk *Locker) lockMySQL(ctx context.Context, name string) (*Handle, error) {
+	key := mysqlName(name)
+
+	conn, err := lk.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		var acquired sql.NullInt64
+
+		err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", key, int(pollInterval.Seconds())).Scan(&acquired)
+		if err == nil && !acquired.Valid {
+			err = fmt.Errorf("GET_LOCK failed for %q", name)
+		}
+
+		if err != nil {
+			_ = conn.Close()
+
+			return nil, err
+		}
+
+		if acquired.Int64 == 1 {
+			break
+		}
+
+		if err := ctx.Err(); err != nil {
+			_ = conn.Close()
+
+			return nil, err
+		}
+	}
+
+	l := &Handle{name: name}
+	l.release = func() {
+		// The connection is closed anyway, which also drops the lock
+		_, l.err = conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK(?)", key)
+		if err := conn.Close(); l.err == nil {
+			l.err = err
+		}
+	}
+
+	return l, nil
+}
+
+// lockLocal takes a lock of the process.
+func (lk *Locker) lockLocal(ctx context.Context, name string) (*Handle, error) {
+	value, _ := lk.local.LoadOrStore(name, make(chan struct{}, 1))
+	slot := value.(chan struct{})
+
+	select {
+	case slot <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	return &Handle{name: name, release: func() { <-slot }}, nil
+}
+
+// Unlock releases the lock. Later calls do nothing.
+func (l *Handle) Unlock() error {
+	l.once.Do(func() {
+		if l.timer != nil {
+			l.timer.Stop()
+		}
+
+		l.release()
+	})
+
+	return l.err
+}
+
+// Name returns the name of the lock.
+func (l *Handle) Name() string {
+	return l.name
+}
+
+// mysqlName returns the MySQL name of a lock, hashed if it is too long.
+func mysqlName(name string) string {
+	key := namePrefix + name
+	if len(key) <= maxNameLength {
+		return key
+	}
+
+	sum := sha256.Sum256([]byte(name))
+
+	return namePrefix + hex.EncodeToString(sum[:])[:maxNameLength-len(namePrefix)]
+}
@@ -0,0 +1,176 @@
+// Package mailbox reads the emails converted into records: it parses MIME
+// messages into their text, HTML and attachments, and polls an IMAP mailbox
+// for the unseen ones. It only uses the standard library, so it supports the
+// IMAP commands the polling needs and nothing more.
+package mailbox
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+// maxParts is the largest number of MIME parts read from a message.
+const maxParts = 100
+
+// Message is a received email.
+type Message struct {
+	// MessageID is the Message-ID header, without the angle brackets.
+	MessageID string
+
+	// From is the address of the sender, and FromName its display name.
+	From     string
+	FromName string
+
+	// To lists the addresses of the recipients.
+	To []string
+
+	// Subject is the decoded subject.
+	Subject string
+
+	// Date is the Date header, or zero if it is missing or invalid.
+	Date time.Time
+
+	// Text and HTML are the first text/plain and text/html parts of the body.
+	Text string
+	HTML string
+
+	// Attachments are the parts sent as attachments or with a file name.
+	Attachments []Attachment
+}
+
+// Attachment is a file attached to a Message.
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Content     []byte
+}
+
+// Body returns the text of the message, or its HTML if it has no text part.
+func (m *Message) Body() string {
+	if m.Text != "" {
+		return m.Text
+	}
+
+	return m.HTML
+}
+
+// header is the common interface of the headers of the messages and of their parts.
+type header interface {
+	Get(key string) string
+}
+
+// Parse parses a raw email (RFC 5322 with MIME parts).
+//
+// Returns:
+// - An error if the headers can't be read or a part is malformed.
+func Parse(raw []byte) (*Message, error) {
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := new(mime.WordDecoder)
+
+	m := &Message{MessageID: strings.Trim(strings.TrimSpace(msg.Header.Get("Message-Id")), "<>")}
+
+	if m.Subject, err = decoder.DecodeHeader(msg.Header.Get("Subject")); err != nil {
+		m.Subject = msg.Header.Get("Subject")
+	}
+
+	if from, err := mail.ParseAddress(msg.Header.Get("From")); err == nil {
+		m.From, m.FromName = from.Address, from.Name
+	}
+
+	if to, err := msg.Header.AddressList("To"); err == nil {
+		for _, address := range to {
+			m.To = append(m.To, address.Address)
+		}
+	}
+
+	if date, err := msg.Header.Date(); err == nil {
+		m.Date = date
+	}
+
+	parts := 0
+	if err := m.readPart(msg.Header, msg.Body, &parts); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// readPart reads a part of the body, and its sub-parts if it is multipart.
+func (m *Message) readPart(h header, body io.Reader, parts *int) error {
+	if *parts++; *parts > maxParts {
+		return errors.New("mailbox: too many MIME parts")
+	}
+
+	mediaType, params, err := mime.ParseMediaType(h.Get("Content-Type"))
+	if err != nil {
+		mediaType, params = "text/plain", map[string]string{}
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		reader := multipart.NewReader(body, params["boundary"])
+
+		for {
+			// NextPart decodes the quoted-printable parts
+			part, err := reader.NextPart()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			if err != nil {
+				return err
+			}
+
+			if err := m.readPart(part.Header, part, parts); err != nil {
+				return err
+			}
+		}
+	}
+
+	content, err := io.ReadAll(decodeTransfer(h.Get("Content-Transfer-Encoding"), body))
+	if err != nil {
+		return err
+	}
+
+	disposition, dispositionParams, _ := mime.ParseMediaType(h.Get("Content-Disposition"))
+
+	filename := dispositionParams["filename"]
+	if filename == "" {
+		filename = params["name"]
+	}
+
+	switch {
+	case disposition == "attachment" || filename != "":
+		m.Attachments = append(m.Attachments, Attachment{Filename: filename, ContentType: mediaType, Content: content})
+	case mediaType == "text/plain" && m.Text == "":
+		m.Text = string(content)
+	case mediaType == "text/html" && m.HTML == "":
+		m.HTML = string(content)
+	}
+
+	return nil
+}
+
+// decodeTransfer decodes the Content-Transfer-Encoding of a part.
+func decodeTransfer(encoding string, body io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		// The decoder skips the line breaks
+		return base64.NewDecoder(base64.StdEncoding, body)
+	case "quoted-printable":
+		return quotedprintable.NewReader(body)
+	default:
+		return body
+	}
+}
@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"net/smtp"
+	"strings"
+)
+
+// Mailer sends plain-text emails through an SMTP server.
+//
+// If no host is configured, emails are written to the log instead, which is
+// convenient during development.
+type Mailer struct {
+	Host     string // SMTP server host; empty logs emails instead of sending them
+	Port     string // SMTP server port (e.g. "587")
+	Username string // Optional SMTP username
+	Password string // Optional SMTP password
+	From     string // Sender address
+}
+
+// Send sends an email.
+//
+// Parameters:
+// - to: The recipient address.
+// - subject: The subject of the email.
+// - body: The plain-text body of the email.
+//
+// Returns an error if the SMTP server rejects the email.
+func (m *Mailer) Send(to, subject, body string) error {
+	if strings.ContainsAny(to+subject, "\r\n") {
+		return fmt.Errorf("invalid email header")
+	}
+
+	if m == nil || m.Host == "" {
+		log.Printf("Email to %s (SMTP not configured):\nSubject: %s\n\n%s", to, subject, body)
+
+		return nil
+	}
+
+	var auth smtp.Auth
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+
+	msg := "From: " + m.From + "\r\n" +
+		"To: " + to + "\r\n" +
+		"Subject: " + subject + "\r\n" +
+		"Content-Type: text/plain; charset=UTF-8\r\n" +
+		"\r\n" + body
+
+	return smtp.SendMail(net.JoinHostPort(m.Host, m.Port), auth, m.From, []string{to}, []byte(msg))
+}
@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// Announcement is a broadcast message shown to every user, like a maintenance notice.
+//
+// An announcement is active while the current time is between StartsAt and
+// EndsAt. Empty bounds are open-ended.
+type Announcement struct {
+	// ID is the auto-incremented primary key of the announcement.
+	ID uint `gorm:"primaryKey" json:"id"`
+
+	// Title is a short summary of the announcement.
+	Title string `json:"title" sanitize:"nfc,strip,trim"`
+
+	// Message is the body of the announcement.
+	Message string `json:"message" sanitize:"nfc,strip,trim"`
+
+	// Level is the severity used by front-ends to style it: "info", "warning" or "critical".
+	Level string `gorm:"default:info" json:"level" enums:"info,warning,critical" populate:"default=info"`
+
+	// StartsAt is when the announcement becomes active. Empty means immediately.
+	StartsAt *time.Time `json:"starts_at"`
+
+	// EndsAt is when the announcement stops being active. Empty means never.
+	EndsAt *time.Time `json:"ends_at"`
+
+	// CreatedBy is the username of the admin who created the announcement, set by the server.
+	CreatedBy string `json:"created_by" populate:"user" anonymize:"hash"`
+
+	// CreatedAt is the timestamp of when the announcement was created, set by the server.
+	CreatedAt time.Time `json:"created_at" populate:"now"`
+
+	// UpdatedAt is the timestamp of the last modification to the announcement.
+	UpdatedAt time.Time `json:"updated_at"`
+}
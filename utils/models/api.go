@@ -9,6 +9,12 @@ type LoginRequest struct {
 
 	// Password is the user's password used for authentication.
 	Password string `binding:"required" json:"password"`
+
+	// RememberMe also issues a device token, exchangeable for new session tokens at POST /login/device.
+	RememberMe bool `json:"remember_me,omitempty"`
+
+	// Device is an optional label of the device remembered, by default its User-Agent.
+	Device string `json:"device,omitempty"`
 }
 
 // JWTResponse represents the response containing a JWT token.
@@ -17,11 +23,17 @@ type LoginRequest struct {
 type JWTResponse struct {
 	// Token is the JWT token assigned to the authenticated user.
 	Token string `json:"token"`
+
+	// DeviceToken is the device token of a login with remember_me, only returned once.
+	DeviceToken string `json:"device_token,omitempty"`
+
+	// DeviceID identifies the device token, to revoke it with DELETE /me/devices/{id}.
+	DeviceID string `json:"device_id,omitempty"`
 }
 
 // RegisterRequest represents the request payload for user registration.
 //
-// It contains the username, password, and role of the new user.
+// It contains the username, password, email, and role of the new user.
 type RegisterRequest struct {
 	// Username is the unique identifier for the new user.
 	Username string `binding:"required" json:"username"`
@@ -29,10 +41,30 @@ type RegisterRequest struct {
 	// Password is the new user's password, which will be hashed before storage.
 	Password string `binding:"required" json:"password"`
 
-	// Role specifies whether the user is an "admin" or "user".
+	// Email is the optional email address of the new user. A verification link is sent to it.
+	Email string `json:"email"`
+
+	// Role specifies whether the user is an "admin" or "user". It is ignored by
+	// the public registration endpoint, which always creates regular users.
 	Role Role `json:"role"`
 }
 
+// BootstrapRequest represents the request payload creating the first admin of
+// an empty database with the one-time bootstrap token.
+type BootstrapRequest struct {
+	// Token is the bootstrap token, from BOOTSTRAP_TOKEN or printed at startup.
+	Token string `binding:"required" json:"token"`
+
+	// Username is the unique identifier for the admin.
+	Username string `binding:"required" json:"username"`
+
+	// Password is the admin's password, which will be hashed before storage.
+	Password string `binding:"required" json:"password"`
+
+	// Email is the optional email address of the admin, considered verified.
+	Email string `json:"email"`
+}
+
 // DefaultRequest represents a minimal request structure.
 //
 // It contains a single field, which can be used for generic request handling.
@@ -47,4 +79,52 @@ type DefaultRequest struct {
 type ErrorResponse struct {
 	// Error contains a descriptive error message.
 	Error string `json:"error"`
+
+	// RequestID identifies the request in the server logs, when available.
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// StepUpResponse is the body of the 401 responses of the destructive actions
+// whose user authenticated too long ago.
+type StepUpResponse struct {
+	// Error describes why the request was rejected.
+	Error string `json:"error"`
+
+	// MaxAge is how many seconds ago the user must have authenticated.
+	MaxAge int `json:"max_age"`
+
+	// StepUpURL is where a fresh token is requested with the password of the user.
+	StepUpURL string `json:"step_up_url"`
+
+	// RequestID identifies the request in the server logs, when available.
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// ChallengeResponse is the 401 response of a login needing a solved
+// challenge (CAPTCHA), after too many failed logins from the client.
+type ChallengeResponse struct {
+	// Error is "Challenge required", or "Invalid challenge" if the token was rejected.
+	Error string `json:"error"`
+
+	// Provider is the challenge provider: "hcaptcha", "recaptcha" or "turnstile".
+	Provider string `json:"provider"`
+
+	// SiteKey is the public key of the site at the provider, to render the challenge.
+	SiteKey string `json:"site_key,omitempty"`
+
+	// Header is the header carrying the token of the solved challenge on the next login.
+	Header string `json:"header"`
+
+	// RequestID identifies the request in the server logs, when available.
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// ForbiddenFieldsResponse is the 403 response of a write changing fields the
+// role of the user may not write.
+type ForbiddenFieldsResponse struct {
+	// Error contains a descriptive error message.
+	Error string `json:"error"`
+
+	// Fields lists the JSON names of the forbidden fields.
+	Fields []string `json:"fields"`
 }
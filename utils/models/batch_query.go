@@ -0,0 +1,45 @@
+package models
+
+// QueryOperation is a named read of POST /query, equivalent to GET /{resource}
+// with the same query parameters.
+type QueryOperation struct {
+	// Resource is the resource to read (e.g. "example1").
+	Resource string `json:"resource"`
+
+	// Filters maps query parameters to their value, e.g. {"field2": "foo"} or
+	// {"filter[field1][like]": "a%"}, as in the query string of GET /{resource}.
+	Filters map[string]string `json:"filters,omitempty"`
+
+	// Sort lists the fields used to order the results. A leading "-" sorts descending.
+	Sort []string `json:"sort,omitempty"`
+
+	// Fields restricts the returned fields. Primary keys are always returned.
+	Fields []string `json:"fields,omitempty"`
+
+	// Page is the page to return, starting at 1. Zero disables pagination unless PerPage is set.
+	Page int `json:"page,omitempty"`
+
+	// PerPage is the page size. Zero disables pagination unless Page is set.
+	PerPage int `json:"per_page,omitempty"`
+}
+
+// QueryResult is the result of a QueryOperation.
+type QueryResult struct {
+	// Status is the HTTP status GET /{resource} would have answered.
+	Status int `json:"status"`
+
+	// Data holds the records, or is null if the read failed.
+	Data interface{} `json:"data,omitempty"`
+
+	// Total is the number of matching records, as in the X-Total-Count header.
+	Total int64 `json:"total"`
+
+	// TotalIsEstimate is true when Total is a database estimate (see COUNT_ESTIMATE_THRESHOLD).
+	TotalIsEstimate bool `json:"total_is_estimate,omitempty"`
+
+	// PerPage is the page size applied when the one asked for was capped by LIST_BYTE_BUDGET, as in the X-Per-Page header.
+	PerPage int `json:"per_page,omitempty"`
+
+	// Error describes why the read failed.
+	Error string `json:"error,omitempty"`
+}
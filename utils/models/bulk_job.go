@@ -0,0 +1,91 @@
+package models
+
+import "time"
+
+// BulkJobStatus is the status of a BulkJob.
+type BulkJobStatus string
+
+const (
+	// BulkJobQueued means the job was accepted and has not started yet.
+	BulkJobQueued BulkJobStatus = "queued"
+
+	// BulkJobRunning means the records are being inserted.
+	BulkJobRunning BulkJobStatus = "running"
+
+	// BulkJobDone means every record was processed, possibly with failures.
+	BulkJobDone BulkJobStatus = "done"
+
+	// BulkJobFailed means the job stopped before processing every record (see Error).
+	BulkJobFailed BulkJobStatus = "failed"
+)
+
+// MaxBulkJobFailures is the number of failed records a BulkJob keeps the error of.
+const MaxBulkJobFailures = 100
+
+// BulkJob is a bulk import of records (a large JSON array or CSV file) run in
+// the background, whose progress is polled with GET /jobs/{id}.
+type BulkJob struct {
+	// ID is the auto-incremented primary key of the job.
+	ID uint `gorm:"primaryKey" json:"id"`
+
+	// Resource is the resource receiving the records (e.g. "example1").
+	Resource string `gorm:"size:64" json:"resource"`
+
+	// Owner is the user who submitted the import, the author of the records.
+	Owner string `gorm:"size:191;index" json:"owner"`
+
+	// Format is the format of the payload: "json" or "csv", or "generated" for
+	// the synthetic records of POST /admin/generate.
+	Format string `gorm:"size:16" json:"format"`
+
+	// Status is the status of the job.
+	Status BulkJobStatus `gorm:"size:16" json:"status" enums:"queued,running,done,failed"`
+
+	// Total is the number of records of the payload.
+	Total int `json:"total"`
+
+	// Processed is the number of records processed so far, created or failed.
+	Processed int `json:"processed"`
+
+	// Created is the number of records created.
+	Created int `json:"created"`
+
+	// Failed is the number of records that could not be created.
+	Failed int `json:"failed"`
+
+	// Failures lists the first MaxBulkJobFailures records that could not be created, with their error.
+	Failures []ImportFailure `gorm:"serializer:json;type:text" json:"failures"`
+
+	// Error is the reason a failed job stopped.
+	Error string `gorm:"type:text" json:"error,omitempty"`
+
+	// CreatedAt is the timestamp of when the job was submitted.
+	CreatedAt time.Time `json:"created_at"`
+
+	// UpdatedAt is the timestamp of the last progress of the job.
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// FinishedAt is the timestamp of when the job ended, or null while it runs.
+	FinishedAt *time.Time `json:"finished_at"`
+}
+
+// IngestResult is the outcome of a POST /{resource} with an NDJSON body, whose
+// records are created in chunks while the body is read.
+type IngestResult struct {
+	// Received is the number of records of the body read before the end or the error.
+	Received int `json:"received"`
+
+	// Created is the number of records created.
+	Created int `json:"created"`
+
+	// Failed is the number of records that could not be created.
+	Failed int `json:"failed"`
+
+	// Failures lists the first MaxBulkJobFailures records that could not be
+	// created, with their error. Index is the position of the record among the
+	// lines with a record, starting at 0.
+	Failures []ImportFailure `json:"failures"`
+
+	// Error is the reason the ingestion stopped before the end of the body.
+	Error string `json:"error,omitempty"`
+}
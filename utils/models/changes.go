@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// RecordDeletion records that a record was deleted, so GET /{resource}/changes
+// can report it to the clients syncing the resource.
+type RecordDeletion struct {
+	// ID is the auto-incremented primary key of the deletion.
+	ID uint `gorm:"primaryKey" json:"-"`
+
+	// Resource is the name of the resource of the deleted record (e.g. "example1").
+	Resource string `gorm:"size:64;index:idx_record_deletion_resource_time" json:"resource"`
+
+	// RecordID is the tokenized ID of the deleted record.
+	RecordID string `gorm:"size:191" json:"record_id"`
+
+	// DeletedAt is the timestamp of the deletion.
+	DeletedAt time.Time `gorm:"index:idx_record_deletion_resource_time" json:"deleted_at"`
+}
+
+// ChangeSet is the response of GET /{resource}/changes.
+type ChangeSet struct {
+	// Changed holds the records created or updated after the watermark, oldest first.
+	Changed interface{} `json:"changed"`
+
+	// Deleted lists the tokenized IDs of the records deleted after the watermark
+	// (or no longer visible to the user).
+	Deleted []string `json:"deleted"`
+
+	// Watermark is the value of "since" for the next request.
+	Watermark time.Time `json:"watermark"`
+
+	// HasMore is true when more changes are available right away with the new watermark.
+	HasMore bool `json:"has_more"`
+}
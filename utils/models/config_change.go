@@ -0,0 +1,210 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+// ConfigKind is the kind of configuration changed in a ConfigChange.
+type ConfigKind string
+
+const (
+	// ConfigSetting is a runtime setting, managed with /admin/settings.
+	ConfigSetting ConfigKind = "setting"
+
+	// ConfigFeatureFlag is a feature flag, managed with /admin/flags.
+	ConfigFeatureFlag ConfigKind = "feature_flag"
+
+	// ConfigValidationRule is a validation rule, managed with /admin/validation-rules.
+	ConfigValidationRule ConfigKind = "validation_rule"
+
+	// ConfigNotificationRule is a notification rule and its webhook, managed with /admin/notification-rules.
+	ConfigNotificationRule ConfigKind = "notification_rule"
+
+	// ConfigServiceAccount is a service account and its scopes, managed with /admin/service-accounts.
+	ConfigServiceAccount ConfigKind = "service_account"
+
+	// ConfigQuota is a quota, managed with /admin/quotas.
+	ConfigQuota ConfigKind = "quota"
+
+	// ConfigUserRole is the role of a user, changed with the admin /user routes.
+	ConfigUserRole ConfigKind = "user_role"
+)
+
+// ConfigAction is the action of a ConfigChange.
+type ConfigAction string
+
+const (
+	// ConfigCreated means the configuration did not exist before the change.
+	ConfigCreated ConfigAction = "created"
+
+	// ConfigUpdated means the configuration was replaced.
+	ConfigUpdated ConfigAction = "updated"
+
+	// ConfigDeleted means the configuration was removed.
+	ConfigDeleted ConfigAction = "deleted"
+)
+
+// ConfigChange is an entry of the audit log of the configuration and permission
+// changes made through the admin endpoints. Entries are never updated or deleted.
+//
+// The entries are chained: each one stores the hash of the previous one, so
+// altering, inserting or removing an entry afterwards breaks the chain.
+type ConfigChange struct {
+	// ID is the auto-incremented primary key of the entry.
+	ID uint `gorm:"primaryKey" json:"id"`
+
+	// Kind is the kind of the changed configuration (e.g. "feature_flag").
+	Kind ConfigKind `gorm:"size:32;index:idx_config_change_kind_key" json:"kind"`
+
+	// Key identifies the changed configuration within its kind: the key of a setting,
+	// the name of a flag, the client ID of a service account, a username or a rule ID.
+	Key string `gorm:"size:191;index:idx_config_change_kind_key" json:"key"`
+
+	// Action is "created", "updated" or "deleted".
+	Action ConfigAction `gorm:"size:16" json:"action"`
+
+	// Before is the JSON-encoded configuration before the change, empty if it was created.
+	Before json.RawMessage `gorm:"type:text" json:"before,omitempty" swaggertype:"object"`
+
+	// After is the JSON-encoded configuration after the change, empty if it was deleted.
+	After json.RawMessage `gorm:"type:text" json:"after,omitempty" swaggertype:"object"`
+
+	// Actor is the username of the admin or service account who made the change.
+	Actor string `gorm:"size:191;index" json:"actor"`
+
+	// ActorType tells users and service accounts apart.
+	ActorType AccountType `gorm:"size:16" json:"actor_type,omitempty"`
+
+	// ClientIP is the IP address the change was requested from.
+	ClientIP string `gorm:"size:45" json:"client_ip,omitempty"`
+
+	// ChangedAt is the timestamp of the change.
+	ChangedAt time.Time `gorm:"autoCreateTime;index" json:"changed_at"`
+
+	// PrevHash is the Hash of the previous entry, empty for the first one.
+	PrevHash string `gorm:"size:64" json:"prev_hash,omitempty"`
+
+	// Hash is the hex-encoded SHA-256 of the entry and PrevHash (see ComputeHash).
+	// It is empty for the entries recorded before the chain.
+	Hash string `gorm:"size:64" json:"hash,omitempty"`
+}
+
+// ComputeHash returns the hash chaining an entry: the hex-encoded SHA-256 of
+// the JSON array of its kind, key, action, before, after, actor, actor type,
+// client IP, change time (RFC 3339 in UTC) and PrevHash. The ID is left out,
+// the chain already orders the entries.
+func (c *ConfigChange) ComputeHash() string {
+	content, _ := json.Marshal([]string{
+		string(c.Kind), c.Key, string(c.Action), string(c.Before), string(c.After), c.Actor,
+		string(c.ActorType), c.ClientIP, c.ChangedAt.UTC().Format(time.RFC3339Nano), c.PrevHash,
+	})
+	sum := sha256.Sum256(content)
+
+	return hex.EncodeToString(sum[:])
+}
+
+// AuditVerification is the result of checking the chain of the audit log.
+type AuditVerification struct {
+	// Valid is false if an entry was altered, inserted or removed.
+	Valid bool `json:"valid"`
+
+	// Entries is the number of chained entries checked.
+	Entries int64 `json:"entries"`
+
+	// Unchained is the number of entries recorded before the chain, which can't be checked.
+	Unchained int64 `json:"unchained,omitempty"`
+
+	// HeadID is the ID of the last entry checked.
+	HeadID uint `json:"head_id,omitempty"`
+
+	// HeadHash is the hash of the last entry checked. Recording it elsewhere
+	// detects the removal of the latest entries on later checks.
+	HeadHash string `json:"head_hash,omitempty"`
+
+	// BrokenID is the ID of the first entry breaking the chain.
+	BrokenID uint `json:"broken_id,omitempty"`
+
+	// Error explains how the chain is broken.
+	Error string `json:"error,omitempty"`
+}
+
+// NewAuditVerification starts checking a chain from the hash of the entry
+// before the first one checked, empty from the start of the log.
+func NewAuditVerification(prevHash string) *AuditVerification {
+	return &AuditVerification{Valid: true, HeadHash: prevHash}
+}
+
+// Check checks the next entry of the chain, in ID order.
+//
+// Returns:
+// - false once the chain is broken; the next entries are not checked.
+func (v *AuditVerification) Check(change ConfigChange) bool {
+	if !v.Valid {
+		return false
+	}
+
+	switch {
+	case change.Hash == "" && v.Entries > 0:
+		v.Error = "the entry has no hash"
+	case change.Hash == "":
+		v.Unchained++
+		v.HeadID = change.ID
+
+		return true
+	case change.PrevHash != v.HeadHash:
+		v.Error = "the entry doesn't follow the previous one: an entry was inserted or removed"
+	case change.ComputeHash() != change.Hash:
+		v.Error = "the entry was altered"
+	default:
+		v.Entries++
+		v.HeadID, v.HeadHash = change.ID, change.Hash
+
+		return true
+	}
+
+	v.Valid = false
+	v.BrokenID = change.ID
+
+	return false
+}
+
+// AuditManifest describes a signed export of the audit log: the manifest.json
+// file of the bundle, whose Ed25519 signature is manifest.sig.
+type AuditManifest struct {
+	// Log is the exported audit log, "config_changes".
+	Log string `json:"log"`
+
+	// Entries is the number of entries exported in entries.ndjson.
+	Entries int64 `json:"entries"`
+
+	// FirstID and LastID are the IDs of the first and last entries exported.
+	FirstID uint `json:"first_id,omitempty"`
+	LastID  uint `json:"last_id,omitempty"`
+
+	// PrevHash is the hash of the entry before the first one exported, empty from the start of the log.
+	PrevHash string `json:"prev_hash,omitempty"`
+
+	// HeadHash is the hash of the last entry exported.
+	HeadHash string `json:"head_hash,omitempty"`
+
+	// EntriesSHA256 is the hex-encoded SHA-256 of entries.ndjson.
+	EntriesSHA256 string `json:"entries_sha256"`
+
+	// ChainValid tells whether the chain of the exported entries was intact when exported.
+	ChainValid bool `json:"chain_valid"`
+
+	// BrokenID is the ID of the first exported entry breaking the chain.
+	BrokenID uint `json:"broken_id,omitempty"`
+
+	// Algorithm is the algorithm of the signature, "Ed25519".
+	Algorithm string `json:"algorithm"`
+
+	// ExportedBy is the admin who exported the entries.
+	ExportedBy string `json:"exported_by"`
+
+	// ExportedAt is the timestamp of the export.
+	ExportedAt time.Time `json:"exported_at"`
+}
@@ -0,0 +1,16 @@
+package models
+
+// CounterRepair is the result of recomputing a counter with POST /admin/counters/recompute.
+type CounterRepair struct {
+	// Table is the table of the parent model, holding the counter.
+	Table string `json:"table"`
+
+	// Column is the counter column.
+	Column string `json:"column"`
+
+	// Child is the table of the counted rows.
+	Child string `json:"child"`
+
+	// Fixed is the number of parents whose counter was wrong.
+	Fixed int64 `json:"fixed"`
+}
@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 // Example1 represents a database table storing example data.
 //
 // This struct is mapped to a table where Field1 serves as the primary key.
@@ -9,12 +11,32 @@ type Example1 struct {
 
 	// Field2 stores additional data related to Example1.
 	Field2 string `gorm:"column:field2" json:"field2"`
+
+	// Meta stores optional semi-structured data, filterable by path (e.g. filter[meta.color]=red).
+	Meta JSON `gorm:"column:meta" json:"meta" swaggertype:"object"`
+
+	// Status is the lifecycle status, changed with POST /example1/{id}/{action}. New records
+	// are drafts; rows stored before the lifecycle existed default to published.
+	Status LifecycleStatus `gorm:"column:status;size:16;default:published;index" json:"status" populate:"value=draft"`
+
+	// RelationalCount is the number of ExampleRelational rows referencing the record,
+	// maintained by the server (see database.Counter).
+	RelationalCount int64 `gorm:"column:relational_count;->;not null;default:0" json:"relational_count" populate:"value=0"`
+
+	// UpdatedAt is the timestamp of the last modification, checked against the
+	// If-Unmodified-Since header of writes.
+	UpdatedAt time.Time `gorm:"column:updated_at;index" json:"updated_at" populate:"now"`
 }
 
 // Example2 represents another database table storing example data.
 type Example2 struct {
-	Field1 string `gorm:"column:field1;primaryKey" json:"field1"`
-	Field2 string `gorm:"column:field2"            json:"field2"`
+	Field1     string    `gorm:"column:field1;primaryKey"          json:"field1"`
+	Field2     string    `gorm:"column:field2"                     json:"field2"`
+	Location   GeoPoint  `gorm:"embedded;embeddedPrefix:location_" json:"location" anonymize:"nullify"`
+	AssignedTo string    `gorm:"column:assigned_to;size:191;index" json:"assigned_to" notify:"assignee" anonymize:"hash"`
+	Reference  string    `gorm:"column:reference;size:32;index"    json:"reference" populate:"sequence=EX2-{year}-{seq:4}"`
+	CreatedBy  string    `gorm:"column:created_by;size:191;index"  json:"created_by" populate:"user" anonymize:"hash"`
+	UpdatedAt  time.Time `gorm:"column:updated_at;index"           json:"updated_at" populate:"now"`
 }
 
 // ExampleRelational represents a relational table connecting Example1 and Example2.
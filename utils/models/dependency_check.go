@@ -0,0 +1,176 @@
+package models
+
+import (
+	"errors"
+	"net/url"
+	"time"
+)
+
+// EventDependency is the rule event matching the dependencies going down or recovering.
+const EventDependency = "dependency"
+
+const (
+	// MinDependencyInterval is the shortest interval, in seconds, between two checks of a dependency.
+	MinDependencyInterval = 10
+
+	// MaxDependencyTimeout is the longest timeout, in seconds, of a check.
+	MaxDependencyTimeout = 60
+)
+
+// DependencyState is the health of a dependency, as seen by its last checks.
+type DependencyState string
+
+const (
+	// DependencyUnknown is the state of a dependency not checked yet.
+	DependencyUnknown DependencyState = "unknown"
+
+	// DependencyUp is the state of a dependency answering the expected status.
+	DependencyUp DependencyState = "up"
+
+	// DependencyDown is the state of a dependency that failed FailureThreshold checks in a row.
+	DependencyDown DependencyState = "down"
+)
+
+// DependencyCheck monitors an external service the API depends on (object
+// storage, OIDC provider...) with a GET request on a schedule. Its state is
+// shown in /readyz, and its changes are sent to the alerting channels.
+type DependencyCheck struct {
+	// ID is the auto-incremented primary key of the check.
+	ID uint `gorm:"primaryKey" json:"id"`
+
+	// Name is a unique, human readable name of the dependency, shown in /readyz.
+	Name string `gorm:"size:191;uniqueIndex" json:"name"`
+
+	// URL is the http(s) URL requested with GET, e.g. the health endpoint of the service.
+	URL string `gorm:"type:text" json:"url"`
+
+	// Headers is a JSON object of the headers sent with the request, e.g. {"Authorization": "Bearer ..."}.
+	Headers JSON `gorm:"type:text" json:"headers,omitempty" swaggertype:"object"`
+
+	// Interval is the number of seconds between two checks (at least MinDependencyInterval).
+	Interval int `json:"interval"`
+
+	// ExpectedStatus is the HTTP status of a healthy response. 0 accepts any 2xx status.
+	ExpectedStatus int `json:"expected_status"`
+
+	// Timeout is the number of seconds a check may take (default 5, at most MaxDependencyTimeout).
+	Timeout int `json:"timeout"`
+
+	// FailureThreshold is the number of failed checks in a row marking the dependency down (default 1).
+	FailureThreshold int `json:"failure_threshold"`
+
+	// Enabled allows pausing the checks of a dependency without deleting it.
+	Enabled bool `json:"enabled"`
+
+	// State is "unknown", "up" or "down", maintained by the checks.
+	State DependencyState `gorm:"size:16;default:unknown" json:"state"`
+
+	// ConsecutiveFailures is the number of failed checks in a row.
+	ConsecutiveFailures int `json:"consecutive_failures"`
+
+	// LastCheckedAt is the timestamp of the last check, or null if it was never checked.
+	LastCheckedAt *time.Time `json:"last_checked_at"`
+
+	// LastStatusCode is the HTTP status of the last check, or 0 if it got no response.
+	LastStatusCode int `json:"last_status_code"`
+
+	// LastLatencyMs is the duration of the last check, in milliseconds.
+	LastLatencyMs int64 `json:"last_latency_ms"`
+
+	// LastError is the error of the last check, or empty if it succeeded.
+	LastError string `gorm:"type:text" json:"last_error"`
+
+	// DownSince is the timestamp the dependency went down, or null if it is not down.
+	DownSince *time.Time `json:"down_since"`
+
+	// CreatedAt is the timestamp of when the check was created.
+	CreatedAt time.Time `json:"created_at"`
+
+	// UpdatedAt is the timestamp of the last modification to the check.
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Validate checks that the check is complete, and that its URL, headers,
+// interval, expected status and timeout are valid.
+func (d DependencyCheck) Validate() error {
+	if d.Name == "" || d.URL == "" {
+		return errors.New("name and url are required")
+	}
+
+	target, err := url.Parse(d.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return errors.New("invalid url")
+	}
+
+	if d.Interval < MinDependencyInterval {
+		return errors.New("interval must be at least 10 seconds")
+	}
+
+	if d.ExpectedStatus != 0 && (d.ExpectedStatus < 100 || d.ExpectedStatus > 599) {
+		return errors.New("expected_status must be 0 (any 2xx) or an HTTP status")
+	}
+
+	if d.Timeout < 0 || d.Timeout > MaxDependencyTimeout {
+		return errors.New("timeout must be between 0 (5 seconds) and 60 seconds")
+	}
+
+	if d.FailureThreshold < 0 {
+		return errors.New("failure_threshold must not be negative")
+	}
+
+	if _, err := d.HeaderValues(); err != nil {
+		return errors.New("headers must be a JSON object of strings")
+	}
+
+	return nil
+}
+
+// HeaderValues returns the headers sent with the request.
+func (d DependencyCheck) HeaderValues() (map[string]string, error) {
+	return stringMap(d.Headers)
+}
+
+// Healthy reports whether an HTTP status is the expected one.
+func (d DependencyCheck) Healthy(status int) bool {
+	if d.ExpectedStatus == 0 {
+		return status >= 200 && status <= 299
+	}
+
+	return status == d.ExpectedStatus
+}
+
+// TimeoutDuration returns how long a check may take.
+func (d DependencyCheck) TimeoutDuration() time.Duration {
+	if d.Timeout <= 0 {
+		return 5 * time.Second
+	}
+
+	return time.Duration(d.Timeout) * time.Second
+}
+
+// Threshold returns the number of failed checks in a row marking the dependency down.
+func (d DependencyCheck) Threshold() int {
+	return max(d.FailureThreshold, 1)
+}
+
+// DependencyHealth is the state of a dependency shown in /readyz. It leaves
+// out the URL and the error, since /readyz is not authenticated.
+type DependencyHealth struct {
+	// Name is the name of the dependency.
+	Name string `json:"name"`
+
+	// State is "unknown", "up" or "down".
+	State DependencyState `json:"state"`
+
+	// LastCheckedAt is the timestamp of the last check, or null if it was never checked.
+	LastCheckedAt *time.Time `json:"last_checked_at"`
+
+	// LastStatusCode is the HTTP status of the last check, or 0 if it got no response.
+	LastStatusCode int `json:"last_status_code"`
+
+	// LastLatencyMs is the duration of the last check, in milliseconds.
+	LastLatencyMs int64 `json:"last_latency_ms"`
+
+	// DownSince is the timestamp the dependency went down, if it is down.
+	DownSince *time.Time `json:"down_since,omitempty"`
+}
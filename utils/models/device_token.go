@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// DeviceToken is a long-lived "remember me" token of a user on a device.
+//
+// It can only be exchanged for a fresh session token at POST /login/device:
+// the API routes only accept session tokens. Only the SHA-256 hash of the
+// token is stored, and each device can be revoked on its own.
+type DeviceToken struct {
+	// ID is the generated identifier of the device, used to revoke it.
+	ID string `gorm:"primaryKey;size:191" json:"id"`
+
+	// TokenHash is the hex-encoded SHA-256 hash of the device token.
+	TokenHash string `gorm:"size:64;uniqueIndex" json:"-"`
+
+	// Username is the user the token logs in.
+	Username string `gorm:"size:191;index" json:"username"`
+
+	// Name is a label of the device, given at login or taken from its User-Agent.
+	Name string `json:"name"`
+
+	// ExpiresAt is when the token stops being exchangeable.
+	ExpiresAt time.Time `json:"expires_at"`
+
+	// LastUsedAt is the timestamp of the last exchange of the token.
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+
+	// CreatedAt is the timestamp of the login that issued the token.
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// DeviceLoginRequest represents the request payload exchanging a device token for a session token.
+type DeviceLoginRequest struct {
+	// DeviceToken is the token returned by a login with remember_me.
+	DeviceToken string `binding:"required" json:"device_token"`
+}
@@ -0,0 +1,34 @@
+package models
+
+// DuplicateGroup is a set of records sharing the same values on the compared fields.
+type DuplicateGroup struct {
+	// Values holds the shared values, by field name.
+	Values map[string]interface{} `json:"values"`
+
+	// Count is the number of records in the group.
+	Count int64 `json:"count"`
+
+	// IDs lists the tokenized IDs of the records in the group.
+	IDs []string `json:"ids"`
+}
+
+// MergeRequest is the body of POST /{resource}/merge.
+type MergeRequest struct {
+	// Survivor is the tokenized ID of the record that is kept.
+	Survivor string `json:"survivor"`
+
+	// IDs lists the tokenized IDs of the records merged into the survivor and deleted.
+	IDs []string `json:"ids"`
+}
+
+// MergeResult is the response of POST /{resource}/merge.
+type MergeResult struct {
+	// Survivor is the kept record, with its empty fields filled from the merged records.
+	Survivor interface{} `json:"survivor"`
+
+	// Merged lists the tokenized IDs of the deleted records.
+	Merged []string `json:"merged"`
+
+	// References is the number of rows of other resources re-pointed to the survivor.
+	References int64 `json:"references"`
+}
@@ -0,0 +1,129 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// EmailSources lists the values of a received email a field can be mapped to.
+// "subject.<group>" maps a named group of the subject pattern instead.
+var EmailSources = []string{
+	"subject", "body", "text", "html", "from", "from_name", "to", "date", "message_id",
+	"attachments", "attachment_names",
+}
+
+// EmailRule converts the received emails it matches into records of a
+// resource, e.g. support emails into tickets. The emails are checked against
+// the enabled rules by priority; the first matching rule creates the record.
+type EmailRule struct {
+	// ID is the auto-incremented primary key of the rule.
+	ID uint `gorm:"primaryKey" json:"id"`
+
+	// Name is a unique, human readable name of the rule.
+	Name string `gorm:"size:191;uniqueIndex" json:"name"`
+
+	// Resource is the resource of the created records (e.g. "example2").
+	Resource string `gorm:"size:64" json:"resource"`
+
+	// FromPattern, ToPattern and SubjectPattern are regular expressions the
+	// sender, one of the recipients and the subject must match. Empty matches
+	// every email. The named groups of SubjectPattern (e.g. `\[#(?P<ticket>\d+)\]`)
+	// can be mapped to fields as "subject.<group>".
+	FromPattern    string `gorm:"size:191" json:"from_pattern"`
+	ToPattern      string `gorm:"size:191" json:"to_pattern"`
+	SubjectPattern string `gorm:"size:191" json:"subject_pattern"`
+
+	// Mapping is a JSON object mapping the JSON names of the fields of the
+	// resource to values of the email (see EmailSources), e.g.
+	// {"field1": "message_id", "field2": "subject"}.
+	Mapping JSON `gorm:"type:text" json:"mapping" swaggertype:"object"`
+
+	// Priority orders the rules, the lowest first.
+	Priority int `json:"priority"`
+
+	// Enabled allows turning a rule off without deleting it.
+	Enabled bool `json:"enabled"`
+
+	// Owner is the admin who stored the rule, the author of the records.
+	Owner string `gorm:"size:191" json:"owner"`
+
+	// LastMatchedAt is the timestamp of the last email matched, or null if none was.
+	LastMatchedAt *time.Time `json:"last_matched_at"`
+
+	// LastError is the error of the record of the last email matched, or empty if it was created.
+	LastError string `gorm:"type:text" json:"last_error"`
+
+	// CreatedAt is the timestamp of when the rule was created.
+	CreatedAt time.Time `json:"created_at"`
+
+	// UpdatedAt is the timestamp of the last modification to the rule.
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Validate checks that the rule is complete, and that its patterns and
+// mapping are valid.
+func (e EmailRule) Validate() error {
+	if e.Name == "" || e.Resource == "" {
+		return errors.New("name and resource are required")
+	}
+
+	patterns := map[string]string{
+		"from_pattern": e.FromPattern, "to_pattern": e.ToPattern, "subject_pattern": e.SubjectPattern,
+	}
+
+	for name, pattern := range patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("%s is not a valid regular expression: %w", name, err)
+		}
+	}
+
+	mapping, err := e.FieldMapping()
+	if err != nil || len(mapping) == 0 {
+		return errors.New("mapping must be a non-empty JSON object of strings")
+	}
+
+	groups := regexp.MustCompile(e.SubjectPattern).SubexpNames()
+
+	for field, source := range mapping {
+		if group, ok := strings.CutPrefix(source, "subject."); ok {
+			if group == "" || !slices.Contains(groups, group) {
+				return fmt.Errorf("mapping of %s: subject_pattern has no group %q", field, group)
+			}
+
+			continue
+		}
+
+		if !slices.Contains(EmailSources, source) {
+			return fmt.Errorf("mapping of %s: unknown source %q", field, source)
+		}
+	}
+
+	return nil
+}
+
+// FieldMapping returns the sources of the values of the fields of the resource, by JSON name.
+func (e EmailRule) FieldMapping() (map[string]string, error) {
+	return stringMap(e.Mapping)
+}
+
+// EmailIngestResult is the outcome of a received email.
+type EmailIngestResult struct {
+	// MessageID is the Message-ID of the email.
+	MessageID string `json:"message_id"`
+
+	// Rule is the name of the rule matching the email, or empty if none did.
+	Rule string `json:"rule,omitempty"`
+
+	// Resource is the resource of the record.
+	Resource string `json:"resource,omitempty"`
+
+	// RecordID is the primary key of the created record, or empty if it failed.
+	RecordID string `json:"record_id,omitempty"`
+
+	// Error explains why the record was not created.
+	Error string `json:"error,omitempty"`
+}
@@ -0,0 +1,10 @@
+package models
+
+// Enum is implemented by the named types with a fixed set of values (e.g. Role).
+//
+// Struct fields of these types are validated on create and update, and listed
+// as enums in the JSON Schema of their resource.
+type Enum interface {
+	// EnumValues returns the allowed values.
+	EnumValues() []string
+}
@@ -0,0 +1,96 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// EventType identifies the kind of change described by a ResourceEvent.
+type EventType string
+
+const (
+	// EventCreated is emitted when a record is created.
+	EventCreated EventType = "created" // @Enum created
+
+	// EventUpdated is emitted when a record is updated.
+	EventUpdated EventType = "updated" // @Enum updated
+
+	// EventDeleted is emitted when a record is deleted.
+	EventDeleted EventType = "deleted" // @Enum deleted
+)
+
+// ResourceEvent is the JSON document published to the message bus for every
+// change made through the API.
+//
+// Consumers can rely on RecordID being the tokenized primary key used in the
+// API URLs (composite keys joined with "-").
+type ResourceEvent struct {
+	// ID uniquely identifies the event.
+	ID string `json:"id"`
+
+	// Type is the kind of change: "created", "updated" or "deleted".
+	Type EventType `json:"type"`
+
+	// Resource is the name of the changed resource (e.g. "example1").
+	Resource string `json:"resource"`
+
+	// RecordID is the tokenized primary key of the changed record.
+	RecordID string `json:"record_id"`
+
+	// Actor is the username of the user who made the change.
+	Actor string `json:"actor,omitempty"`
+
+	// ActorType is the kind of account of the actor: "user" or "service".
+	ActorType AccountType `json:"actor_type,omitempty"`
+
+	// ClientIP is the IP address the change was requested from.
+	ClientIP string `json:"client_ip,omitempty"`
+
+	// Timestamp is the time the change was made.
+	Timestamp time.Time `json:"timestamp"`
+
+	// Data is the record after the change. It is omitted for deletions.
+	Data interface{} `json:"data,omitempty"`
+}
+
+// CommandAction identifies the operation requested by a ResourceCommand.
+type CommandAction string
+
+const (
+	// CommandCreate creates a new record.
+	CommandCreate CommandAction = "create"
+
+	// CommandUpdate updates the fields of an existing record.
+	CommandUpdate CommandAction = "update"
+)
+
+// ResourceCommand is a write request received from a message bus (e.g. the
+// MQTT command topic) instead of the HTTP API.
+type ResourceCommand struct {
+	// ID is an optional identifier echoed in the command result.
+	ID string `json:"id,omitempty"`
+
+	// Action is "create" or "update".
+	Action CommandAction `json:"action"`
+
+	// Resource is the name of the target resource (e.g. "example1").
+	Resource string `json:"resource"`
+
+	// RecordID is the tokenized primary key of the record to update.
+	RecordID string `json:"record_id,omitempty"`
+
+	// Data contains the fields of the record, as in the HTTP request body.
+	Data json.RawMessage `json:"data"`
+}
+
+// CommandResult is published after a ResourceCommand has been processed.
+type CommandResult struct {
+	// ID is the identifier of the processed command.
+	ID string `json:"id,omitempty"`
+
+	// Status is "ok" or "error".
+	Status string `json:"status"`
+
+	// Error describes why the command failed.
+	Error string `json:"error,omitempty"`
+}
@@ -0,0 +1,78 @@
+package models
+
+import "time"
+
+// ExportJob is an export of the records of a resource, written in the
+// background to files of at most EXPORT_PART_RECORDS records. The parts are
+// downloaded with signed links that expire with the export, and support
+// Range requests so interrupted downloads can be resumed.
+type ExportJob struct {
+	// ID is the auto-incremented primary key of the job.
+	ID uint `gorm:"primaryKey" json:"id"`
+
+	// Resource is the resource exported (e.g. "example1"), or "dump" for the
+	// dumps of several resources, listed in Query.
+	Resource string `gorm:"size:64" json:"resource"`
+
+	// Owner is the user who requested the export.
+	Owner string `gorm:"size:191;index" json:"owner"`
+
+	// Format is the format of the parts: "json" (a JSON array per part) or "csv"
+	// (with a header row per part), both accepted by the bulk imports.
+	Format string `gorm:"size:16" json:"format"`
+
+	// Query holds the filters and sort order of the export, as URL query parameters.
+	Query string `gorm:"type:text" json:"query"`
+
+	// Anonymized tells whether the personal data of the records was replaced,
+	// according to the "anonymize" tag of their fields (see utils.Anonymizer).
+	Anonymized bool `json:"anonymized"`
+
+	// Status is the status of the job.
+	Status BulkJobStatus `gorm:"size:16" json:"status" enums:"queued,running,done,failed"`
+
+	// Total is the number of records matching the query when the export started.
+	Total int64 `json:"total"`
+
+	// Exported is the number of records written so far.
+	Exported int64 `json:"exported"`
+
+	// Parts lists the files written so far.
+	Parts []ExportPart `gorm:"serializer:json;type:text" json:"parts"`
+
+	// Error is the reason a failed job stopped.
+	Error string `gorm:"type:text" json:"error,omitempty"`
+
+	// CreatedAt is the timestamp of when the export was requested.
+	CreatedAt time.Time `json:"created_at"`
+
+	// UpdatedAt is the timestamp of the last progress of the job.
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// FinishedAt is the timestamp of when the job ended, or null while it runs.
+	FinishedAt *time.Time `json:"finished_at"`
+
+	// ExpiresAt is the timestamp from which the parts and their links are deleted, or null while it runs.
+	ExpiresAt *time.Time `gorm:"index" json:"expires_at"`
+}
+
+// ExportPart is a file of an ExportJob.
+type ExportPart struct {
+	// Number is the position of the part, starting at 1.
+	Number int `json:"number"`
+
+	// Resource is the resource of the records of the part.
+	Resource string `json:"resource,omitempty"`
+
+	// Records is the number of records of the part.
+	Records int `json:"records"`
+
+	// Bytes is the size of the file.
+	Bytes int64 `json:"bytes"`
+
+	// SHA256 is the hex-encoded checksum of the file, also its ETag.
+	SHA256 string `json:"sha256"`
+
+	// URL is the signed download link of the part, valid until the export expires.
+	URL string `json:"url,omitempty"`
+}
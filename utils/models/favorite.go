@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// UserFavorite is a record pinned by a user.
+type UserFavorite struct {
+	// Username is the owner of the favorite.
+	Username string `gorm:"primaryKey;size:191" json:"-"`
+
+	// Resource is the name of the resource of the record (e.g. "example1").
+	Resource string `gorm:"primaryKey;size:64" json:"resource"`
+
+	// RecordID is the tokenized ID of the record, as used in /{resource}/{id}.
+	RecordID string `gorm:"primaryKey;size:191" json:"record_id"`
+
+	// CreatedAt is when the record was pinned.
+	CreatedAt time.Time `json:"created_at"`
+
+	// Record is the pinned record, only set when the favorites are listed with
+	// expand=true and the record still exists and is visible to the user.
+	Record interface{} `gorm:"-" json:"record,omitempty" swaggertype:"object"`
+}
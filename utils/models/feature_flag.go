@@ -0,0 +1,63 @@
+package models
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// FeatureFlag gates functionality per user or role.
+//
+// A flag that is enabled without roles or users applies to everyone. When
+// roles or users are set, it only applies to the matching users.
+type FeatureFlag struct {
+	// Name is the unique identifier of the flag (e.g. "new_dashboard").
+	Name string `gorm:"primaryKey;size:191" json:"name"`
+
+	// Description explains what the flag gates.
+	Description string `json:"description"`
+
+	// Enabled turns the flag on. A disabled flag is off for everyone.
+	Enabled bool `json:"enabled"`
+
+	// Roles is a comma-separated list of roles the flag is limited to (e.g. "admin").
+	Roles string `json:"roles"`
+
+	// Users is a comma-separated list of usernames the flag is limited to.
+	Users string `json:"users"`
+
+	// CreatedAt is the timestamp of when the flag was created.
+	CreatedAt time.Time `json:"created_at"`
+
+	// UpdatedAt is the timestamp of the last modification to the flag.
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// EnabledFor reports whether the flag is on for a user.
+func (f FeatureFlag) EnabledFor(username, role string) bool {
+	if !f.Enabled {
+		return false
+	}
+
+	roles := splitCSV(f.Roles)
+	users := splitCSV(f.Users)
+
+	if len(roles) == 0 && len(users) == 0 {
+		return true
+	}
+
+	return slices.Contains(roles, role) || slices.Contains(users, username)
+}
+
+// splitCSV splits a comma-separated list, dropping empty entries.
+func splitCSV(value string) []string {
+	items := []string{}
+
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
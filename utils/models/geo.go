@@ -0,0 +1,17 @@
+package models
+
+// GeoPoint is a physical location, stored as latitude and longitude columns.
+//
+// Embed it in a model with a column prefix, e.g.
+//
+//	Location GeoPoint `gorm:"embedded;embeddedPrefix:location_" json:"location"`
+//
+// to filter by radius with filter[location][near]=lat,lon,radius_km and sort by
+// the distance to that point with sort=location.
+type GeoPoint struct {
+	// Lat is the latitude in degrees, between -90 and 90.
+	Lat float64 `gorm:"column:lat" json:"lat"`
+
+	// Lon is the longitude in degrees, between -180 and 180.
+	Lon float64 `gorm:"column:lon" json:"lon"`
+}
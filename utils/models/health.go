@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// DBHealth represents the state of the database connection as seen by the health checker.
+type DBHealth struct {
+	// Available is false while the circuit breaker is open.
+	Available bool `json:"available"`
+
+	// ConsecutiveFailures is the number of failed health checks in a row.
+	ConsecutiveFailures int `json:"consecutive_failures"`
+
+	// LastError is the error returned by the last failed health check.
+	LastError string `json:"last_error,omitempty"`
+
+	// LastCheck is the time of the last health check.
+	LastCheck time.Time `json:"last_check"`
+
+	// UnavailableSince is the time the circuit breaker opened, if open.
+	UnavailableSince *time.Time `json:"unavailable_since,omitempty"`
+}
+
+// HealthResponse represents the response of the readiness endpoint.
+type HealthResponse struct {
+	// Status is "ok" when the service is ready, "degraded" when it is ready but a
+	// dependency is down, and "unavailable" otherwise.
+	Status string `json:"status"`
+
+	// Database contains the state of the database connection.
+	Database *DBHealth `json:"database,omitempty"`
+
+	// Dependencies contains the state of the monitored external services (see DependencyCheck).
+	Dependencies []DependencyHealth `json:"dependencies,omitempty"`
+}
@@ -0,0 +1,144 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"time"
+)
+
+// MinImporterInterval is the shortest interval, in seconds, between two scheduled runs of an importer.
+const MinImporterInterval = 60
+
+// Importer pulls JSON records from an external REST API and upserts them into
+// a resource, once on demand or on a schedule, e.g. to seed reference data.
+type Importer struct {
+	// ID is the auto-incremented primary key of the importer.
+	ID uint `gorm:"primaryKey" json:"id"`
+
+	// Name is a unique, human readable name of the importer.
+	Name string `gorm:"size:191;uniqueIndex" json:"name"`
+
+	// Resource is the resource receiving the records (e.g. "example1").
+	Resource string `gorm:"size:64" json:"resource"`
+
+	// URL is the http(s) URL fetched with GET.
+	URL string `gorm:"type:text" json:"url"`
+
+	// Headers is a JSON object of the headers sent with the request, e.g. {"Authorization": "Bearer ..."}.
+	Headers JSON `gorm:"type:text" json:"headers,omitempty" swaggertype:"object"`
+
+	// RecordsPath is the dot path of the array of records in the response
+	// (e.g. "data.items"). Empty if the response is the array.
+	RecordsPath string `gorm:"size:191" json:"records_path"`
+
+	// Mapping is a JSON object mapping the JSON names of the fields of the
+	// resource to the dot paths of their values in each external record, e.g.
+	// {"field1": "code", "field2": "attributes.label"}. Empty imports the
+	// records as they are.
+	Mapping JSON `gorm:"type:text" json:"mapping,omitempty" swaggertype:"object"`
+
+	// Interval is the number of seconds between two scheduled runs (at least
+	// MinImporterInterval), or 0 for an importer only run on demand.
+	Interval int `json:"interval"`
+
+	// Enabled allows pausing the schedule of an importer without deleting it.
+	Enabled bool `json:"enabled"`
+
+	// Owner is the admin who stored the importer, the author of the imported records.
+	Owner string `gorm:"size:191" json:"owner"`
+
+	// LastRunAt is the timestamp of the last run, or null if it never ran.
+	LastRunAt *time.Time `json:"last_run_at"`
+
+	// LastError is the error of the last run, or empty if it succeeded.
+	LastError string `gorm:"type:text" json:"last_error"`
+
+	// CreatedAt is the timestamp of when the importer was created.
+	CreatedAt time.Time `json:"created_at"`
+
+	// UpdatedAt is the timestamp of the last modification to the importer.
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Validate checks that the importer is complete, and that its URL, headers
+// and mapping are valid.
+func (i Importer) Validate() error {
+	if i.Name == "" || i.Resource == "" || i.URL == "" {
+		return errors.New("name, resource and url are required")
+	}
+
+	source, err := url.Parse(i.URL)
+	if err != nil || (source.Scheme != "http" && source.Scheme != "https") || source.Host == "" {
+		return errors.New("invalid url")
+	}
+
+	if i.Interval != 0 && i.Interval < MinImporterInterval {
+		return errors.New("interval must be 0 (on demand only) or at least 60 seconds")
+	}
+
+	if _, err := i.HeaderValues(); err != nil {
+		return errors.New("headers must be a JSON object of strings")
+	}
+
+	if _, err := i.FieldMapping(); err != nil {
+		return errors.New("mapping must be a JSON object of strings")
+	}
+
+	return nil
+}
+
+// HeaderValues returns the headers sent with the request.
+func (i Importer) HeaderValues() (map[string]string, error) {
+	return stringMap(i.Headers)
+}
+
+// FieldMapping returns the dot paths of the values of the fields of the resource, by JSON name.
+func (i Importer) FieldMapping() (map[string]string, error) {
+	return stringMap(i.Mapping)
+}
+
+// stringMap decodes a JSON object of strings. An empty document has no entry.
+func stringMap(document JSON) (map[string]string, error) {
+	values := map[string]string{}
+	if len(document) == 0 {
+		return values, nil
+	}
+
+	err := json.Unmarshal(document, &values)
+
+	return values, err
+}
+
+// ImportResult is the result of an importer run.
+type ImportResult struct {
+	// Importer is the name of the importer.
+	Importer string `json:"importer"`
+
+	// Resource is the resource receiving the records.
+	Resource string `json:"resource"`
+
+	// RanAt is the timestamp of the run.
+	RanAt time.Time `json:"ran_at"`
+
+	// Fetched is the number of records in the response.
+	Fetched int `json:"fetched"`
+
+	// Created is the number of new records.
+	Created int `json:"created"`
+
+	// Updated is the number of records replaced.
+	Updated int `json:"updated"`
+
+	// Failed lists the records that could not be imported, with their error.
+	Failed []ImportFailure `json:"failed"`
+}
+
+// ImportFailure is a record an importer run could not import.
+type ImportFailure struct {
+	// Index is the position of the record in the response, starting at 0.
+	Index int `json:"index"`
+
+	// Error explains why the record was not imported.
+	Error string `json:"error"`
+}
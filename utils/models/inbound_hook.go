@@ -0,0 +1,167 @@
+package models
+
+import (
+	"errors"
+	"regexp"
+	"slices"
+	"time"
+)
+
+// HookAction is what an inbound hook does with the records it receives.
+type HookAction string
+
+const (
+	// HookActionCreate creates the records, failing for the existing ones.
+	HookActionCreate HookAction = "create"
+
+	// HookActionUpdate replaces existing records, failing for the missing ones.
+	HookActionUpdate HookAction = "update"
+
+	// HookActionUpsert creates the records, or replaces them if they exist (like the importers).
+	HookActionUpsert HookAction = "upsert"
+)
+
+// HookSignatureHex and HookSignatureBase64 are the encodings of the signatures.
+const (
+	HookSignatureHex    = "hex"
+	HookSignatureBase64 = "base64"
+)
+
+// DefaultHookSignatureHeader is the header of the signatures when the hook doesn't set one.
+const DefaultHookSignatureHeader = "X-Signature-256"
+
+// MinHookSecretLength is the shortest secret accepted for an inbound hook.
+const MinHookSecretLength = 16
+
+// hookName matches the names allowed in the URL of the hooks.
+var hookName = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// InboundHook receives the JSON payloads a third-party service POSTs to
+// /hooks/{name}, signed with a shared secret, and maps them to records of a
+// resource, so the service pushes data into the API without custom code.
+type InboundHook struct {
+	// ID is the auto-incremented primary key of the hook.
+	ID uint `gorm:"primaryKey" json:"id"`
+
+	// Name is the unique name of the hook in its URL (letters, digits, "_" and "-").
+	Name string `gorm:"size:64;uniqueIndex" json:"name"`
+
+	// Resource is the resource receiving the records (e.g. "example1").
+	Resource string `gorm:"size:64" json:"resource"`
+
+	// Action is "create", "update" or "upsert".
+	Action HookAction `gorm:"size:16" json:"action"`
+
+	// Secret is the key of the HMAC-SHA256 signature of the payloads. It is
+	// never returned; empty on update keeps the stored one.
+	Secret string `gorm:"size:191" json:"secret,omitempty"`
+
+	// HasSecret reports whether the hook has a secret, since it is not returned.
+	HasSecret bool `gorm:"-" json:"has_secret"`
+
+	// SignatureHeader is the header of the signature (default X-Signature-256).
+	SignatureHeader string `gorm:"size:64" json:"signature_header"`
+
+	// SignaturePrefix is written before the signature in its header, e.g.
+	// "sha256=" for the GitHub style X-Hub-Signature-256.
+	SignaturePrefix string `gorm:"size:32" json:"signature_prefix"`
+
+	// SignatureEncoding is "hex" (default) or "base64".
+	SignatureEncoding string `gorm:"size:16" json:"signature_encoding"`
+
+	// RecordsPath is the dot path of the record, or of the array of records,
+	// in the payload (e.g. "data.object"). Empty if it is the payload.
+	RecordsPath string `gorm:"size:191" json:"records_path"`
+
+	// Mapping is a JSON object mapping the JSON names of the fields of the
+	// resource to the dot paths of their values in each received record, like
+	// the mapping of the importers. Empty stores the records as they are.
+	Mapping JSON `gorm:"type:text" json:"mapping,omitempty" swaggertype:"object"`
+
+	// Enabled allows rejecting the payloads of a hook without deleting it.
+	Enabled bool `json:"enabled"`
+
+	// Owner is the admin who stored the hook, the author of the records.
+	Owner string `gorm:"size:191" json:"owner"`
+
+	// LastReceivedAt is the timestamp of the last signed payload, or null if none was received.
+	LastReceivedAt *time.Time `json:"last_received_at"`
+
+	// LastError is the error of the last payload, or empty if it succeeded.
+	LastError string `gorm:"type:text" json:"last_error"`
+
+	// CreatedAt is the timestamp of when the hook was created.
+	CreatedAt time.Time `json:"created_at"`
+
+	// UpdatedAt is the timestamp of the last modification to the hook.
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Validate checks that the hook is complete, and that its name, action,
+// signature and mapping are valid. The secret is checked by the caller, since
+// it may be kept on update.
+func (h InboundHook) Validate() error {
+	if h.Name == "" || h.Resource == "" {
+		return errors.New("name and resource are required")
+	}
+
+	if !hookName.MatchString(h.Name) {
+		return errors.New("name must be 1 to 64 letters, digits, _ or -")
+	}
+
+	if !slices.Contains([]HookAction{HookActionCreate, HookActionUpdate, HookActionUpsert}, h.Action) {
+		return errors.New("action must be create, update or upsert")
+	}
+
+	if h.Secret != "" && len(h.Secret) < MinHookSecretLength {
+		return errors.New("secret must be at least 16 characters")
+	}
+
+	if h.SignatureEncoding != "" && h.SignatureEncoding != HookSignatureHex && h.SignatureEncoding != HookSignatureBase64 {
+		return errors.New("signature_encoding must be hex or base64")
+	}
+
+	if _, err := h.FieldMapping(); err != nil {
+		return errors.New("mapping must be a JSON object of strings")
+	}
+
+	return nil
+}
+
+// FieldMapping returns the dot paths of the values of the fields of the resource, by JSON name.
+func (h InboundHook) FieldMapping() (map[string]string, error) {
+	return stringMap(h.Mapping)
+}
+
+// Header returns the header of the signature.
+func (h InboundHook) Header() string {
+	if h.SignatureHeader == "" {
+		return DefaultHookSignatureHeader
+	}
+
+	return h.SignatureHeader
+}
+
+// HookResult is the result of a payload received by an inbound hook.
+type HookResult struct {
+	// Hook is the name of the hook.
+	Hook string `json:"hook"`
+
+	// Resource is the resource receiving the records.
+	Resource string `json:"resource"`
+
+	// ReceivedAt is the timestamp of the payload.
+	ReceivedAt time.Time `json:"received_at"`
+
+	// Received is the number of records in the payload.
+	Received int `json:"received"`
+
+	// Created is the number of new records.
+	Created int `json:"created"`
+
+	// Updated is the number of records replaced.
+	Updated int `json:"updated"`
+
+	// Failed lists the records that could not be stored, with their error.
+	Failed []ImportFailure `json:"failed"`
+}
@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// Kinds of the notifications of the user inbox.
+const (
+	// InboxAssigned tells a user that a record was assigned to them.
+	InboxAssigned = "record_assigned"
+
+	// InboxComment tells a user that a comment was added to a record they follow.
+	InboxComment = "comment_added"
+
+	// InboxWebhookFailed tells the admins that a notification rule could not be delivered.
+	InboxWebhookFailed = "webhook_failed"
+)
+
+// InboxNotification is a message in the inbox of a user, read with GET /me/notifications.
+type InboxNotification struct {
+	// ID is the auto-incremented primary key of the notification.
+	ID uint `gorm:"primaryKey" json:"id"`
+
+	// Username is the recipient of the notification.
+	Username string `gorm:"size:191;index:idx_inbox_user_read,priority:1" json:"-"`
+
+	// Kind is the hook that sent the notification, e.g. "record_assigned".
+	Kind string `gorm:"size:32" json:"kind"`
+
+	// Title is a one-line summary of the notification.
+	Title string `json:"title"`
+
+	// Text is the body of the notification.
+	Text string `gorm:"type:text" json:"text"`
+
+	// Resource is the resource of the record the notification is about, if any.
+	Resource string `gorm:"size:64" json:"resource,omitempty"`
+
+	// RecordID is the tokenized ID of the record the notification is about, if any.
+	RecordID string `gorm:"size:191" json:"record_id,omitempty"`
+
+	// Read is set once the user marks the notification as read.
+	Read bool `gorm:"column:is_read;index:idx_inbox_user_read,priority:2" json:"read"`
+
+	// CreatedAt is the timestamp of when the notification was sent.
+	CreatedAt time.Time `json:"created_at"`
+
+	// ReadAt is the timestamp of when the notification was marked as read.
+	ReadAt *time.Time `json:"read_at,omitempty"`
+}
+
+// UnreadCount is the number of unread notifications of a user.
+type UnreadCount struct {
+	Unread int64 `json:"unread"`
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"database/sql/driver"
+	"fmt"
+)
+
+// JSON is a semi-structured JSON document stored in a JSON column (text on
+// databases without a JSON type).
+//
+// Fields of this type can be filtered by path with the dot syntax, e.g.
+// filter[meta.color][eq]=red matches the records whose "meta" field has
+// {"color": "red"}.
+type JSON []byte
+
+// jsonNull is the encoding of an empty document, shared so the lists of records
+// without a document don't allocate it once per record. encoding/json copies it.
+var jsonNull = []byte("null")
+
+// MarshalJSON returns the document as is, or null if it is empty.
+func (j JSON) MarshalJSON() ([]byte, error) {
+	if len(j) == 0 {
+		return jsonNull, nil
+	}
+
+	return j, nil
+}
+
+// UnmarshalJSON stores a copy of the document. null clears it.
+func (j *JSON) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*j = nil
+
+		return nil
+	}
+
+	*j = append((*j)[:0], data...)
+
+	return nil
+}
+
+// Value stores the document as a string, or NULL if it is empty.
+func (j JSON) Value() (driver.Value, error) {
+	if len(j) == 0 {
+		return nil, nil
+	}
+
+	return string(j), nil
+}
+
+// Scan reads the document from a JSON or text column.
+func (j *JSON) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*j = nil
+	case []byte:
+		*j = append((*j)[:0], v...)
+	case string:
+		*j = JSON(v)
+	default:
+		return fmt.Errorf("cannot scan %T into models.JSON", value)
+	}
+
+	return nil
+}
+
+// GormDataType maps the field to a "json" column.
+func (JSON) GormDataType() string {
+	return "json"
+}
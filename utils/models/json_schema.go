@@ -0,0 +1,59 @@
+package models
+
+// JSONSchemaDraft is the JSON Schema version of the generated schemas.
+const JSONSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchema is the JSON Schema of a resource, generated from its model so
+// front-ends can build forms and validate records client-side.
+type JSONSchema struct {
+	// Schema is the JSON Schema version.
+	Schema string `json:"$schema"`
+
+	// Title is the name of the resource.
+	Title string `json:"title"`
+
+	// Type is always "object".
+	Type string `json:"type"`
+
+	// Properties describes the fields of the resource, by JSON name.
+	Properties map[string]*JSONSchemaProperty `json:"properties"`
+
+	// Required lists the fields that must be present.
+	Required []string `json:"required"`
+}
+
+// JSONSchemaProperty describes a field of a resource.
+type JSONSchemaProperty struct {
+	// Type is the JSON type ("string", "integer", ...), or a list including "null" for optional values.
+	Type interface{} `json:"type,omitempty" swaggertype:"string"`
+
+	// Format is the string format (e.g. "date-time").
+	Format string `json:"format,omitempty"`
+
+	// MinLength is the minimum number of characters of a string.
+	MinLength *int `json:"minLength,omitempty"`
+
+	// MaxLength is the maximum number of characters of a string.
+	MaxLength *int `json:"maxLength,omitempty"`
+
+	// Minimum is the minimum value of a number.
+	Minimum *float64 `json:"minimum,omitempty"`
+
+	// Maximum is the maximum value of a number.
+	Maximum *float64 `json:"maximum,omitempty"`
+
+	// Pattern is the regular expression a string must match.
+	Pattern string `json:"pattern,omitempty"`
+
+	// Properties describes the fields of a nested object.
+	Properties map[string]*JSONSchemaProperty `json:"properties,omitempty"`
+
+	// Enum lists the allowed values.
+	Enum []string `json:"enum,omitempty"`
+
+	// Default is the value used when the field is omitted.
+	Default interface{} `json:"default,omitempty" swaggertype:"string"`
+
+	// ReadOnly is set for the fields managed by the server (e.g. timestamps).
+	ReadOnly bool `json:"readOnly,omitempty"`
+}
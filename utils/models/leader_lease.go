@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// LeaderLease records which instance leads a group of replicas sharing the
+// database, e.g. to run the scheduled jobs on exactly one of them. The leader
+// renews the lease before it expires; other instances take it over once it
+// has expired.
+type LeaderLease struct {
+	// Name is the name of the election (e.g. "scheduler").
+	Name string `gorm:"primaryKey;size:64" json:"name"`
+
+	// Holder is the ID of the leading instance (INSTANCE_ID).
+	Holder string `gorm:"size:191" json:"holder"`
+
+	// AcquiredAt is the timestamp of when the holder became the leader.
+	AcquiredAt time.Time `json:"acquired_at"`
+
+	// RenewedAt is the timestamp of the last renewal of the lease.
+	RenewedAt time.Time `json:"renewed_at"`
+
+	// ExpiresAt is when the lease is taken over by another instance if it is not renewed.
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Active reports whether the lease is still held at the given time.
+func (l LeaderLease) Active(now time.Time) bool {
+	return now.Before(l.ExpiresAt)
+}
+
+// LeaderStatus describes an election as seen by the instance answering.
+type LeaderStatus struct {
+	// Lease is the current lease, with an empty holder if no instance leads.
+	Lease LeaderLease `json:"lease"`
+
+	// Instance is the ID of the instance answering.
+	Instance string `json:"instance"`
+
+	// IsLeader is true if the instance answering leads.
+	IsLeader bool `json:"is_leader"`
+
+	// Jobs lists the jobs that only run on the leader.
+	Jobs []string `json:"jobs"`
+}
@@ -0,0 +1,37 @@
+package models
+
+// LifecycleStatus is the publication status of a record with a lifecycle.
+type LifecycleStatus string
+
+const (
+	// StatusDraft is the status of new records, only visible to admins.
+	StatusDraft LifecycleStatus = "draft"
+
+	// StatusPublished is the status of the records visible to every user.
+	StatusPublished LifecycleStatus = "published"
+
+	// StatusArchived is the status of retired records, only visible to admins.
+	StatusArchived LifecycleStatus = "archived"
+)
+
+// EnumValues returns the valid lifecycle statuses.
+func (LifecycleStatus) EnumValues() []string {
+	return []string{string(StatusDraft), string(StatusPublished), string(StatusArchived)}
+}
+
+// LifecycleTransition is the change of status applied by a lifecycle action.
+type LifecycleTransition struct {
+	// From lists the statuses the action applies to.
+	From []LifecycleStatus
+
+	// To is the resulting status.
+	To LifecycleStatus
+}
+
+// LifecycleActions maps the actions of POST /{resource}/{id}/{action} to their transition.
+var LifecycleActions = map[string]LifecycleTransition{
+	"publish":   {From: []LifecycleStatus{StatusDraft}, To: StatusPublished},
+	"unpublish": {From: []LifecycleStatus{StatusPublished}, To: StatusDraft},
+	"archive":   {From: []LifecycleStatus{StatusDraft, StatusPublished}, To: StatusArchived},
+	"restore":   {From: []LifecycleStatus{StatusArchived}, To: StatusDraft},
+}
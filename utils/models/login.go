@@ -13,13 +13,18 @@ const (
 	UserRole Role = "user" // @Enum user
 )
 
+// EnumValues returns the valid roles.
+func (Role) EnumValues() []string {
+	return []string{string(AdminRole), string(UserRole)}
+}
+
 // User represents a system user.
 //
 // It contains authentication details and metadata like creation and update timestamps.
 type User struct {
 	// Username is the unique identifier for the user.
-	// It serves as the primary key in the database.
-	Username string `gorm:"primaryKey" json:"username"`
+	// It serves as the primary key in the database and is stored in lower case.
+	Username string `gorm:"primaryKey" json:"username" sanitize:"trim,lower" anonymize:"hash"`
 
 	// Password stores the hashed password for authentication.
 	// The JSON tag omits this field in API responses for security reasons.
@@ -28,9 +33,69 @@ type User struct {
 	// Role defines the user's permissions, either "admin" or "user".
 	Role Role `json:"role"`
 
+	// Email is the optional email address of the user, unique and stored in lower case.
+	Email *string `gorm:"size:191;uniqueIndex" json:"email,omitempty" sanitize:"trim,lower" anonymize:"faker=email"`
+
+	// EmailVerifiedAt is the timestamp of when the user confirmed the email address.
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+
+	// CreatedAt is the timestamp of when the user was created.
+	CreatedAt time.Time `json:"created_at"`
+
+	// UpdatedAt is the timestamp of the last modification to the user record.
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// EmailVerification is a pending email address confirmation.
+//
+// Only the SHA-256 hash of the token sent by email is stored.
+type EmailVerification struct {
+	// TokenHash is the hex-encoded SHA-256 hash of the verification token.
+	TokenHash string `gorm:"primaryKey;size:64" json:"-"`
+
+	// Username is the user whose email is being verified.
+	Username string `gorm:"size:191;index" json:"username"`
+
+	// Email is the address the token was sent to.
+	Email string `json:"email"`
+
+	// ExpiresAt is when the token stops being valid.
+	ExpiresAt time.Time `json:"expires_at"`
+
+	// CreatedAt is the timestamp of when the token was sent.
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// UserSummary is the public view of a user returned by the user search,
+// without the credentials.
+type UserSummary struct {
+	// Username is the unique identifier of the user.
+	Username string `json:"username"`
+
+	// Role is the role of the user.
+	Role Role `json:"role"`
+
+	// Email is the email address of the user, if any.
+	Email *string `json:"email,omitempty"`
+
+	// EmailVerified is true once the user confirmed the email address.
+	EmailVerified bool `json:"email_verified"`
+
 	// CreatedAt is the timestamp of when the user was created.
 	CreatedAt time.Time `json:"created_at"`
 
 	// UpdatedAt is the timestamp of the last modification to the user record.
 	UpdatedAt time.Time `json:"updated_at"`
 }
+
+// NewUserSummary returns the summary of a user.
+func NewUserSummary(user User) UserSummary {
+	return UserSummary{
+		Username:      user.Username,
+		Role:          user.Role,
+		Email:         user.Email,
+		EmailVerified: user.EmailVerifiedAt != nil,
+		CreatedAt:     user.CreatedAt,
+		UpdatedAt:     user.UpdatedAt,
+	}
+}
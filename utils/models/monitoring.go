@@ -0,0 +1,94 @@
+package models
+
+import "time"
+
+// SlowRoute represents a route whose rolling p95 latency exceeds the configured threshold.
+type SlowRoute struct {
+	// Route is the path template of the route (e.g. "/example1/{id}").
+	Route string `json:"route"`
+
+	// Method is the HTTP method of the route.
+	Method string `json:"method"`
+
+	// P95Ms is the rolling p95 latency in milliseconds.
+	P95Ms float64 `json:"p95_ms"`
+
+	// ThresholdMs is the configured threshold in milliseconds.
+	ThresholdMs int `json:"threshold_ms"`
+
+	// Samples is the number of requests used to compute the p95 latency.
+	Samples int `json:"samples"`
+
+	// SlowSince is the time the route first exceeded the threshold.
+	SlowSince time.Time `json:"slow_since"`
+}
+
+// TableGrowth represents a table whose row count or size grows faster than the configured rate.
+type TableGrowth struct {
+	// Table is the name of the table.
+	Table string `json:"table"`
+
+	// Metric is the growing metric: "rows" or "bytes" (data and index size).
+	Metric string `json:"metric"`
+
+	// From is the value of the metric at the start of the window.
+	From int64 `json:"from"`
+
+	// To is the latest value of the metric.
+	To int64 `json:"to"`
+
+	// GrowthPercent is the growth of the metric over the window, in percent.
+	GrowthPercent float64 `json:"growth_percent"`
+
+	// ThresholdPercent is the configured growth threshold, in percent.
+	ThresholdPercent float64 `json:"threshold_percent"`
+
+	// Since is the time of the snapshot at the start of the window.
+	Since time.Time `json:"since"`
+}
+
+// Alerts lists the conditions currently alerting.
+type Alerts struct {
+	// SlowRoutes lists the routes whose p95 latency exceeds the threshold.
+	SlowRoutes []SlowRoute `json:"slow_routes"`
+
+	// TableGrowth lists the tables growing faster than the threshold.
+	TableGrowth []TableGrowth `json:"table_growth"`
+
+	// OpenCircuits lists the outbound hosts whose requests are rejected after consecutive failures.
+	OpenCircuits []OutboundHost `json:"open_circuits"`
+
+	// DependenciesDown lists the monitored dependencies that are down.
+	DependenciesDown []DependencyHealth `json:"dependencies_down"`
+}
+
+// OutboundHost holds the metrics of the outbound HTTP calls to a host, made
+// through the shared client (webhooks, notification channels, importers...).
+type OutboundHost struct {
+	// Host is the host (and port, if any) called.
+	Host string `json:"host"`
+
+	// Requests is the number of attempts sent, retries included.
+	Requests int64 `json:"requests"`
+
+	// Failures is the number of attempts failing with a network error, a 429 or a 5xx.
+	Failures int64 `json:"failures"`
+
+	// Retries is the number of attempts that retried a failed one.
+	Retries int64 `json:"retries"`
+
+	// Rejected is the number of attempts not sent because the circuit was open.
+	Rejected int64 `json:"rejected"`
+
+	// AvgLatencyMs is the average duration of an attempt in milliseconds.
+	AvgLatencyMs float64 `json:"avg_latency_ms"`
+
+	// CircuitOpen is true while the requests to the host are rejected after consecutive failures.
+	CircuitOpen bool `json:"circuit_open"`
+
+	// LastError is the error of the last failed attempt.
+	LastError string `json:"last_error,omitempty"`
+
+	// LastFailureAt is the time of the last failed attempt, or null.
+	LastFailureAt *time.Time `json:"last_failure_at"`
+}
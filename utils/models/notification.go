@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+// EventErrorBurst is the rule event matching bursts of 5xx responses.
+const EventErrorBurst = "error_burst"
+
+// EventTableGrowth is the rule event matching tables growing faster than the configured rate.
+const EventTableGrowth = "table_growth"
+
+// NotificationRule defines when a message is sent to an ops channel.
+//
+// A rule matches resource change events (optionally limited to a resource, an
+// event type and a filter on the record fields), bursts of 5xx responses or
+// tables growing too fast (optionally limited to a table with Resource), or
+// dependencies going down or recovering (optionally limited to a dependency
+// with Resource).
+type NotificationRule struct {
+	// ID is the auto-incremented primary key of the rule.
+	ID uint `gorm:"primaryKey" json:"id"`
+
+	// Name is a human-readable description of the rule.
+	Name string `json:"name"`
+
+	// Channel is the notification driver: "slack", "teams" or "telegram".
+	Channel string `json:"channel"`
+
+	// WebhookURL is the incoming webhook URL of the channel (Slack and Teams only).
+	WebhookURL string `json:"webhook_url"`
+
+	// Resource limits the rule to one resource (or table, for table_growth, or dependency, for
+	// dependency). Empty matches every resource.
+	Resource string `json:"resource"`
+
+	// Event is "created", "updated", "deleted", "error_burst", "table_growth", "dependency", or empty
+	// for every change.
+	Event string `json:"event"`
+
+	// Filter is a query string the record must match (e.g. "field2=critical").
+	Filter string `json:"filter"`
+
+	// Enabled allows turning a rule off without deleting it.
+	Enabled bool `json:"enabled"`
+
+	// CreatedAt is the timestamp of when the rule was created.
+	CreatedAt time.Time `json:"created_at"`
+
+	// UpdatedAt is the timestamp of the last modification to the rule.
+	UpdatedAt time.Time `json:"updated_at"`
+}
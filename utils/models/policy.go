@@ -0,0 +1,30 @@
+package models
+
+// PolicyInput is what an authorization policy decides on (see utils.Policy).
+// It is sent as the "input" document to OPA.
+type PolicyInput struct {
+	// Username is the username of the user or the client ID of the service account.
+	Username string `json:"username"`
+
+	// Role is the role of the account.
+	Role Role `json:"role"`
+
+	// AccountType tells users and service accounts apart.
+	AccountType AccountType `json:"account_type"`
+
+	// Resource is the resource of the request: the first path segment, or the
+	// second one for "/admin/..." routes (e.g. "example1").
+	Resource string `json:"resource"`
+
+	// Method is the HTTP method of the request.
+	Method string `json:"method"`
+
+	// Path is the URL path of the request.
+	Path string `json:"path"`
+
+	// RecordID is the tokenized ID of the record of /{resource}/{id} routes.
+	RecordID string `json:"record_id,omitempty"`
+
+	// Record is the stored record of /{resource}/{id} routes, if it exists.
+	Record interface{} `json:"record,omitempty"`
+}
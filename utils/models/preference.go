@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// UserPreference is a single UI setting of a user, stored as a JSON value
+// (e.g. key "theme" with value "\"dark\"", or key "page_size" with value "50").
+type UserPreference struct {
+	// Username is the owner of the preference.
+	Username string `gorm:"primaryKey;size:191" json:"username"`
+
+	// Key is the name of the preference.
+	Key string `gorm:"primaryKey;size:191" json:"key"`
+
+	// Value is the JSON-encoded value of the preference.
+	Value string `gorm:"type:text" json:"value"`
+
+	// UpdatedAt is the timestamp of the last modification to the preference.
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,17 @@
+package models
+
+// Principal is an authenticated user or service account, independently of how
+// it authenticated (bearer token or client certificate).
+type Principal struct {
+	// Username is the username of the user or the client ID of the service account.
+	Username string
+
+	// Role is the role of the account.
+	Role Role
+
+	// AccountType tells users and service accounts apart.
+	AccountType AccountType
+
+	// Scopes limits the requests of service accounts. It is nil for users.
+	Scopes []string
+}
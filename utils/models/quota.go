@@ -0,0 +1,60 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// Quota caps the number of records of a resource each owner may create. The
+// owner of a record is the user stored in its `populate:"user"` field (e.g.
+// Example2.CreatedBy); resources without one are capped as a whole.
+type Quota struct {
+	// ID is the auto-incremented primary key of the quota.
+	ID uint `gorm:"primaryKey" json:"id"`
+
+	// Resource is the resource the quota applies to (e.g. "example2").
+	Resource string `gorm:"size:64;uniqueIndex:idx_quota_resource_owner" json:"resource"`
+
+	// Owner is the username the quota applies to. Empty applies to every owner
+	// without a quota of their own, or to the whole resource if it has no owner field.
+	Owner string `gorm:"size:191;uniqueIndex:idx_quota_resource_owner" json:"owner"`
+
+	// MaxRows is the largest number of records per owner. Zero forbids new records.
+	MaxRows int64 `json:"max_rows"`
+
+	// CreatedAt is the timestamp of the creation of the quota.
+	CreatedAt time.Time `json:"created_at"`
+
+	// UpdatedAt is the timestamp of the last change of the quota.
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Validate checks the required fields of a quota.
+func (q Quota) Validate() error {
+	switch {
+	case q.Resource == "":
+		return errors.New("resource is required")
+	case q.MaxRows < 0:
+		return errors.New("max_rows must not be negative")
+	}
+
+	return nil
+}
+
+// QuotaUsage is the usage of a quota by an owner, returned by GET /admin/quotas/usage.
+type QuotaUsage struct {
+	// Resource is the resource of the quota (e.g. "example2").
+	Resource string `json:"resource"`
+
+	// Owner is the username owning the records, empty for resources without owner field.
+	Owner string `json:"owner"`
+
+	// Rows is the number of records the owner has.
+	Rows int64 `json:"rows"`
+
+	// MaxRows is the quota of the owner.
+	MaxRows int64 `json:"max_rows"`
+
+	// Exceeded is true when the owner can't create records anymore.
+	Exceeded bool `json:"exceeded"`
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// RecordLock marks a record as being edited by a user, so other users can't
+// update or delete it until the lock is released or expires.
+type RecordLock struct {
+	// Resource is the name of the resource of the locked record (e.g. "example1").
+	Resource string `gorm:"primaryKey;size:64" json:"resource"`
+
+	// RecordID is the tokenized ID of the locked record.
+	RecordID string `gorm:"primaryKey;size:191" json:"record_id"`
+
+	// LockedBy is the username of the user holding the lock.
+	LockedBy string `gorm:"size:191" json:"locked_by"`
+
+	// ExpiresAt is when the lock is released if it is not renewed.
+	ExpiresAt time.Time `json:"expires_at"`
+
+	// CreatedAt is the timestamp of when the lock was first taken.
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Active reports whether the lock is still held at the given time.
+func (l RecordLock) Active(now time.Time) bool {
+	return now.Before(l.ExpiresAt)
+}
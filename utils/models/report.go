@@ -0,0 +1,150 @@
+package models
+
+import (
+	"errors"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// MinReportInterval is the shortest interval, in seconds, between two runs of a report.
+const MinReportInterval = 60
+
+// Report runs a saved query on a schedule and sends the differences with its
+// previous run (new, changed and removed rows) by email and/or webhook.
+type Report struct {
+	// ID is the auto-incremented primary key of the report.
+	ID uint `gorm:"primaryKey" json:"id"`
+
+	// Name is a unique, human readable name of the report.
+	Name string `gorm:"size:191;uniqueIndex" json:"name"`
+
+	// View is the name of the saved query run by the report (see SavedQuery).
+	View string `gorm:"size:191" json:"view"`
+
+	// Owner is the user the view is resolved for: their own views and the shared ones.
+	// It is set to the admin creating the report.
+	Owner string `gorm:"size:191" json:"owner"`
+
+	// Interval is the number of seconds between two runs (at least MinReportInterval).
+	Interval int `json:"interval"`
+
+	// Emails is a comma-separated list of addresses receiving the differences.
+	Emails string `json:"emails"`
+
+	// WebhookURL receives the differences as a JSON payload. Empty disables it.
+	WebhookURL string `json:"webhook_url"`
+
+	// Enabled allows pausing a report without deleting it.
+	Enabled bool `json:"enabled"`
+
+	// LastRunAt is the timestamp of the last run, or null if it never ran.
+	LastRunAt *time.Time `json:"last_run_at"`
+
+	// LastError is the error of the last run, or empty if it succeeded.
+	LastError string `gorm:"type:text" json:"last_error"`
+
+	// CreatedAt is the timestamp of when the report was created.
+	CreatedAt time.Time `json:"created_at"`
+
+	// UpdatedAt is the timestamp of the last modification to the report.
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Validate checks that the report is complete and its recipients are valid.
+func (r Report) Validate() error {
+	if r.Name == "" || r.View == "" {
+		return errors.New("name and view are required")
+	}
+
+	if r.Interval < MinReportInterval {
+		return errors.New("interval must be at least 60 seconds")
+	}
+
+	if r.Emails == "" && r.WebhookURL == "" {
+		return errors.New("emails or webhook_url is required")
+	}
+
+	for _, address := range r.Recipients() {
+		if _, err := mail.ParseAddress(address); err != nil {
+			return errors.New("invalid email: " + address)
+		}
+	}
+
+	if r.WebhookURL != "" {
+		webhook, err := url.Parse(r.WebhookURL)
+		if err != nil || (webhook.Scheme != "http" && webhook.Scheme != "https") || webhook.Host == "" {
+			return errors.New("invalid webhook_url")
+		}
+	}
+
+	return nil
+}
+
+// Recipients returns the email addresses of the report.
+func (r Report) Recipients() []string {
+	var recipients []string
+
+	for _, address := range strings.Split(r.Emails, ",") {
+		if address = strings.TrimSpace(address); address != "" {
+			recipients = append(recipients, address)
+		}
+	}
+
+	return recipients
+}
+
+// ReportSnapshot holds the rows returned by the last run of a report, compared
+// with the rows of the next run.
+type ReportSnapshot struct {
+	// ReportID is the ID of the report, and the primary key of the snapshot.
+	ReportID uint `gorm:"primaryKey" json:"report_id"`
+
+	// Rows is a JSON object of the rows keyed by their tokenized ID.
+	Rows string `gorm:"type:longtext" json:"-"`
+
+	// TakenAt is the timestamp of the run that returned the rows.
+	TakenAt time.Time `json:"taken_at"`
+}
+
+// ReportChange is a row returned by two consecutive runs of a report with different values.
+type ReportChange struct {
+	// ID is the tokenized ID of the row.
+	ID string `json:"id"`
+
+	// Fields lists the JSON names of the changed fields.
+	Fields []string `json:"fields"`
+}
+
+// ReportDiff is the result of a report run: the differences with the previous run.
+type ReportDiff struct {
+	// Report is the name of the report.
+	Report string `json:"report"`
+
+	// Resource is the resource queried by the report (e.g. "example1").
+	Resource string `json:"resource"`
+
+	// RanAt is the timestamp of the run.
+	RanAt time.Time `json:"ran_at"`
+
+	// Baseline is true on the first run, which has nothing to compare with and sends nothing.
+	Baseline bool `json:"baseline"`
+
+	// Rows is the number of rows returned by the run.
+	Rows int `json:"rows"`
+
+	// New lists the IDs of the rows not returned by the previous run.
+	New []string `json:"new"`
+
+	// Changed lists the rows returned by both runs with different values.
+	Changed []ReportChange `json:"changed"`
+
+	// Removed lists the IDs of the rows returned by the previous run only.
+	Removed []string `json:"removed"`
+}
+
+// Empty reports whether the run found no difference.
+func (d ReportDiff) Empty() bool {
+	return len(d.New) == 0 && len(d.Changed) == 0 && len(d.Removed) == 0
+}
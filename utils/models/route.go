@@ -0,0 +1,31 @@
+package models
+
+// Route access levels reported by GET /admin/routes.
+const (
+	// RouteAccessPublic routes don't require authentication.
+	RouteAccessPublic = "public"
+
+	// RouteAccessUser routes require any authenticated user or service account.
+	RouteAccessUser = "user"
+
+	// RouteAccessAdmin routes require the admin role.
+	RouteAccessAdmin = "admin"
+)
+
+// RouteInfo describes a route registered in the router.
+type RouteInfo struct {
+	// Path is the path template of the route (e.g. "/example1/{id}").
+	Path string `json:"path"`
+
+	// Prefix is true when the route matches every path starting with Path.
+	Prefix bool `json:"prefix,omitempty"`
+
+	// Methods lists the HTTP methods accepted by the route. Empty means any method.
+	Methods []string `json:"methods"`
+
+	// Role is the access level required: "public", "user" or "admin".
+	Role string `json:"role"`
+
+	// Model is the Go type of the resource served by the route, if any (e.g. "models.Example1").
+	Model string `json:"model,omitempty"`
+}
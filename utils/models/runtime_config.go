@@ -0,0 +1,13 @@
+package models
+
+// ConfigValue is the effective value of a runtime configuration parameter.
+type ConfigValue struct {
+	// Key is the name of the parameter, which is also the key of the setting overriding it.
+	Key string `json:"key"`
+
+	// Value is the effective value.
+	Value interface{} `json:"value"`
+
+	// Source is where the value comes from: "default", "env" or "settings".
+	Source string `json:"source"`
+}
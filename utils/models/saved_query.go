@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+// SavedQuery represents a named combination of filters, sort order and field
+// selection that can be executed later via GET /{resource}?view=name.
+//
+// A view belongs to the user that created it. Admins may mark a view as shared,
+// making it available to every authenticated user.
+type SavedQuery struct {
+	// ID is the auto-incremented primary key of the saved query.
+	ID uint `gorm:"primaryKey" json:"id"`
+
+	// Name is the identifier used in the ?view= query parameter.
+	Name string `gorm:"size:191;uniqueIndex:idx_saved_query_owner_name" json:"name"`
+
+	// Owner is the username of the user who created the view.
+	Owner string `gorm:"size:191;uniqueIndex:idx_saved_query_owner_name" json:"owner"`
+
+	// Resource is the resource the view applies to (e.g. "example1").
+	Resource string `json:"resource"`
+
+	// Query is the raw query string stored for the view
+	// (e.g. "field2=foo&sort=-field1&fields=field1,field2").
+	Query string `json:"query"`
+
+	// Shared makes the view visible to all users.
+	Shared bool `json:"shared"`
+
+	// CreatedAt is the timestamp of when the view was created.
+	CreatedAt time.Time `json:"created_at"`
+
+	// UpdatedAt is the timestamp of the last modification to the view.
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SavedQueryRequest represents the request payload for creating or updating a view.
+type SavedQueryRequest struct {
+	// Name is the identifier used in the ?view= query parameter.
+	Name string `binding:"required" json:"name"`
+
+	// Resource is the resource the view applies to.
+	Resource string `binding:"required" json:"resource"`
+
+	// Query is the raw query string (filters, sort and fields).
+	Query string `json:"query"`
+
+	// Shared makes the view visible to all users (admins only).
+	Shared bool `json:"shared"`
+}
@@ -0,0 +1,80 @@
+package models
+
+// Sources of the nodes and edges of a SchemaGraph.
+const (
+	// SchemaSourceModel is a table or relationship declared by the models (GORM tags).
+	SchemaSourceModel = "model"
+
+	// SchemaSourceDatabase is a table or foreign key only found in the database (information_schema).
+	SchemaSourceDatabase = "database"
+
+	// SchemaSourceBoth is a relationship declared by the models and enforced by a foreign key.
+	SchemaSourceBoth = "both"
+)
+
+// SchemaGraph describes the tables and their relationships, as nodes and
+// edges ready to render an ER diagram.
+type SchemaGraph struct {
+	// Nodes lists the tables, sorted by name.
+	Nodes []SchemaNode `json:"nodes"`
+
+	// Edges lists the relationships, from the referencing table to the referenced one.
+	Edges []SchemaEdge `json:"edges"`
+}
+
+// SchemaNode is a table of a SchemaGraph.
+type SchemaNode struct {
+	// ID is the table name, referenced by the edges.
+	ID string `json:"id"`
+
+	// Model is the name of the Go model of the table, or empty for a table only found in the database.
+	Model string `json:"model,omitempty"`
+
+	// Source is SchemaSourceModel or SchemaSourceDatabase.
+	Source string `json:"source"`
+
+	// Columns lists the columns of the model, in order. Empty for a table only found in the database.
+	Columns []SchemaColumn `json:"columns"`
+}
+
+// SchemaColumn is a column of a SchemaNode.
+type SchemaColumn struct {
+	// Name is the column name.
+	Name string `json:"name"`
+
+	// Field is the JSON name of the field in the API, or empty if it is not exposed.
+	Field string `json:"field,omitempty"`
+
+	// Type is the data type of the column, as declared in the model.
+	Type string `json:"type"`
+
+	// PrimaryKey is true for the columns of the primary key.
+	PrimaryKey bool `json:"primary_key"`
+
+	// ForeignKey is true for the columns referencing another table.
+	ForeignKey bool `json:"foreign_key"`
+
+	// Unique is true for the columns with a unique constraint of their own.
+	Unique bool `json:"unique"`
+}
+
+// SchemaEdge is a relationship of a SchemaGraph.
+type SchemaEdge struct {
+	// From is the referencing table.
+	From string `json:"from"`
+
+	// To is the referenced table.
+	To string `json:"to"`
+
+	// Columns lists the foreign key columns of From.
+	Columns []string `json:"columns"`
+
+	// References lists the columns of To they reference, in the same order.
+	References []string `json:"references"`
+
+	// Cardinality is "many-to-one" or "one-to-one", from From to To.
+	Cardinality string `json:"cardinality" enums:"many-to-one,one-to-one"`
+
+	// Source is SchemaSourceModel, SchemaSourceDatabase or SchemaSourceBoth.
+	Source string `json:"source"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// Sequence is a named counter handing out increasing numbers, e.g. for the
+// reference numbers of a resource (see BaseController.NextSequence).
+type Sequence struct {
+	// Name identifies the sequence, e.g. "example2.reference:EX2-2026-{seq:4}".
+	Name string `gorm:"primaryKey;size:191" json:"name"`
+
+	// Value is the last number handed out.
+	Value int64 `gorm:"not null;default:0" json:"value"`
+
+	// UpdatedAt is the timestamp of the last number handed out.
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,125 @@
+package models
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// AccountType tells users and service accounts apart in tokens and audit events.
+type AccountType string
+
+const (
+	// UserAccountType is a person logging in with a username and password.
+	UserAccountType AccountType = "user"
+
+	// ServiceAccountType is a machine client using the client credentials grant.
+	ServiceAccountType AccountType = "service"
+)
+
+// ScopeRead and ScopeWrite are the actions a scope grants on a resource.
+const (
+	ScopeRead  = "read"
+	ScopeWrite = "write"
+)
+
+// ServiceAccount is a non-interactive client created by an admin.
+//
+// It gets access tokens from POST /oauth/token with its client ID and secret,
+// which does not expire. Its requests are limited to its scopes.
+type ServiceAccount struct {
+	// ClientID is the generated unique identifier of the account (e.g. "sa-1f2e...").
+	ClientID string `gorm:"primaryKey;size:191" json:"client_id"`
+
+	// Name is a human-readable label for the account.
+	Name string `json:"name" sanitize:"trim"`
+
+	// Description explains what the account is used for.
+	Description string `json:"description"`
+
+	// SecretHash is the bcrypt hash of the client secret.
+	SecretHash string `json:"-"`
+
+	// Role is the role of the tokens issued to the account, "user" by default.
+	Role Role `gorm:"default:user" json:"role"`
+
+	// Scopes is a comma-separated list of "<resource>:<action>" entries, where action is
+	// "read" or "write" and "*" matches any resource or action (e.g. "example1:read,*:read").
+	Scopes string `json:"scopes"`
+
+	// Disabled blocks new tokens for the account.
+	Disabled bool `json:"disabled"`
+
+	// CreatedBy is the admin who created the account.
+	CreatedBy string `json:"created_by"`
+
+	// LastUsedAt is the timestamp of the last token issued to the account.
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+
+	// CreatedAt is the timestamp of when the account was created.
+	CreatedAt time.Time `json:"created_at"`
+
+	// UpdatedAt is the timestamp of the last modification to the account.
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ServiceAccountCredentials is returned once when a service account is created
+// or its secret is rotated. The secret can't be retrieved afterwards.
+type ServiceAccountCredentials struct {
+	ServiceAccount
+
+	// ClientSecret is the plain client secret.
+	ClientSecret string `json:"client_secret"`
+}
+
+// TokenResponse is the OAuth 2.0 access token response.
+type TokenResponse struct {
+	// AccessToken is the JWT to send as a Bearer token.
+	AccessToken string `json:"access_token"`
+
+	// TokenType is always "Bearer".
+	TokenType string `json:"token_type"`
+
+	// ExpiresIn is the lifetime of the token in seconds.
+	ExpiresIn int `json:"expires_in"`
+
+	// Scope is the space-separated list of granted scopes.
+	Scope string `json:"scope"`
+}
+
+// ScopeList returns the scopes of the account.
+func (sa ServiceAccount) ScopeList() []string {
+	return splitCSV(sa.Scopes)
+}
+
+// ValidScope reports whether a scope has the "<resource>:<action>" format.
+func ValidScope(scope string) bool {
+	resource, action, ok := strings.Cut(scope, ":")
+
+	return ok && resource != "" && !strings.ContainsAny(resource, " ,") &&
+		slices.Contains([]string{ScopeRead, ScopeWrite, "*"}, action)
+}
+
+// ScopeAllows reports whether any of the scopes grants an action on a resource.
+func ScopeAllows(scopes []string, resource, action string) bool {
+	for _, scope := range scopes {
+		r, a, ok := strings.Cut(scope, ":")
+		if !ok {
+			continue
+		}
+
+		if (r == "*" || r == resource) && (a == "*" || a == action) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ScopeCovers reports whether the granted scopes include a requested scope,
+// so clients can ask for a subset of the scopes of their account.
+func ScopeCovers(granted []string, requested string) bool {
+	resource, action, ok := strings.Cut(requested, ":")
+
+	return ok && ScopeAllows(granted, resource, action)
+}
@@ -0,0 +1,108 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// SettingType is the type of the value of a setting.
+type SettingType string
+
+const (
+	// SettingString is a JSON string value.
+	SettingString SettingType = "string"
+
+	// SettingInt is a JSON integer value.
+	SettingInt SettingType = "int"
+
+	// SettingFloat is a JSON number value.
+	SettingFloat SettingType = "float"
+
+	// SettingBool is a JSON boolean value.
+	SettingBool SettingType = "bool"
+
+	// SettingJSON is any JSON value.
+	SettingJSON SettingType = "json"
+)
+
+// ErrInvalidSetting is returned when a setting value does not match its type.
+var ErrInvalidSetting = errors.New("setting value does not match its type")
+
+// Setting is a runtime-tunable parameter (e.g. a rate limit or a page size cap)
+// that admins can change without redeploying.
+type Setting struct {
+	// Key is the unique name of the setting (e.g. "max_page_size").
+	Key string `gorm:"primaryKey;size:191" json:"key"`
+
+	// Type is the type of the value: "string", "int", "float", "bool" or "json".
+	Type SettingType `gorm:"size:16" json:"type"`
+
+	// Value is the JSON-encoded value of the setting.
+	Value json.RawMessage `gorm:"type:text" json:"value" swaggertype:"object"`
+
+	// Description explains what the setting controls.
+	Description string `json:"description"`
+
+	// UpdatedBy is the username of the admin who last changed the setting.
+	UpdatedBy string `json:"updated_by"`
+
+	// UpdatedAt is the timestamp of the last modification to the setting.
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Validate checks that the value matches the type of the setting.
+func (s Setting) Validate() error {
+	var err error
+
+	switch s.Type {
+	case SettingString:
+		var value string
+		err = json.Unmarshal(s.Value, &value)
+	case SettingInt:
+		var value int64
+		err = json.Unmarshal(s.Value, &value)
+	case SettingFloat:
+		var value float64
+		err = json.Unmarshal(s.Value, &value)
+	case SettingBool:
+		var value bool
+		err = json.Unmarshal(s.Value, &value)
+	case SettingJSON:
+		if !json.Valid(s.Value) {
+			err = ErrInvalidSetting
+		}
+	default:
+		return errors.New("setting type must be string, int, float, bool or json")
+	}
+
+	if err != nil {
+		return ErrInvalidSetting
+	}
+
+	return nil
+}
+
+// SettingChange is an audit entry recording a change to a setting.
+type SettingChange struct {
+	// ID is the auto-incremented primary key of the change.
+	ID uint `gorm:"primaryKey" json:"id"`
+
+	// Key is the key of the changed setting.
+	Key string `gorm:"size:191;index" json:"key"`
+
+	// OldValue is the JSON-encoded value before the change, empty if the setting was created.
+	OldValue json.RawMessage `gorm:"type:text" json:"old_value" swaggertype:"object"`
+
+	// NewValue is the JSON-encoded value after the change, empty if the setting was deleted.
+	NewValue json.RawMessage `gorm:"type:text" json:"new_value" swaggertype:"object"`
+
+	// ChangedBy is the username of the admin who made the change.
+	ChangedBy string `json:"changed_by"`
+
+	// ClientIP is the IP address the change was requested from.
+	ClientIP string `gorm:"size:45" json:"client_ip,omitempty"`
+
+	// ChangedAt is the timestamp of the change.
+	ChangedAt time.Time `gorm:"autoCreateTime" json:"changed_at"`
+}
@@ -0,0 +1,154 @@
+package models
+
+import "time"
+
+// TableStats holds the size information of a single database table.
+type TableStats struct {
+	// Table is the name of the table.
+	Table string `json:"table"`
+
+	// Rows is the number of rows in the table.
+	Rows int64 `json:"rows"`
+
+	// DataBytes is the size of the table data in bytes (MySQL only).
+	DataBytes int64 `json:"data_bytes"`
+
+	// IndexBytes is the size of the table indexes in bytes (MySQL only).
+	IndexBytes int64 `json:"index_bytes"`
+}
+
+// DBStats represents the summary returned by the stats endpoint.
+type DBStats struct {
+	// Tables contains the statistics of every table in the database.
+	Tables []TableStats `json:"tables"`
+
+	// TotalRows is the sum of the rows of all tables.
+	TotalRows int64 `json:"total_rows"`
+
+	// TotalBytes is the sum of the data and index sizes of all tables.
+	TotalBytes int64 `json:"total_bytes"`
+
+	// Announcements lists the active announcements, so dashboards show them next to the statistics.
+	Announcements []Announcement `json:"announcements"`
+
+	// Statements holds the prepared statement cache statistics, if DB_PREPARE_STMT is enabled.
+	Statements *StatementCacheStats `json:"statements,omitempty"`
+
+	// Queries holds the usage of the query semaphore, if DB_MAX_CONCURRENT_QUERIES is set.
+	Queries *QuerySemaphoreStats `json:"queries,omitempty"`
+}
+
+// StatementCacheStats holds the usage of the prepared statement cache.
+type StatementCacheStats struct {
+	// Statements is the number of prepared statements in the cache.
+	Statements int `json:"statements"`
+
+	// Hits is the number of statements run with an already prepared statement.
+	Hits int64 `json:"hits"`
+
+	// Misses is the number of statements that had to be prepared first.
+	Misses int64 `json:"misses"`
+}
+
+// QuerySemaphoreStats holds the usage of the semaphore limiting the concurrent
+// database statements, and the time the statements waited for a slot.
+type QuerySemaphoreStats struct {
+	// Limit is the largest number of statements run at once.
+	Limit int `json:"limit"`
+
+	// InFlight is the number of statements running.
+	InFlight int `json:"in_flight"`
+
+	// Waiting is the number of statements waiting for a slot.
+	Waiting int64 `json:"waiting"`
+
+	// Acquired is the number of statements that got a slot since startup.
+	Acquired int64 `json:"acquired"`
+
+	// Waited is the number of statements that got a slot after waiting for it.
+	Waited int64 `json:"waited"`
+
+	// Rejected is the number of statements that failed after waiting too long.
+	Rejected int64 `json:"rejected"`
+
+	// TotalWaitMs is the time the statements waited for a slot, in milliseconds.
+	TotalWaitMs int64 `json:"total_wait_ms"`
+
+	// AvgWaitMs is the average wait of the statements that waited, in milliseconds.
+	AvgWaitMs float64 `json:"avg_wait_ms"`
+
+	// MaxWaitMs is the longest wait for a slot, in milliseconds.
+	MaxWaitMs int64 `json:"max_wait_ms"`
+}
+
+// ValueCount represents how many times a value appears in a column.
+type ValueCount struct {
+	// Value is the column value, or null.
+	Value *string `json:"value"`
+
+	// Count is the number of sampled rows holding the value.
+	Count int64 `json:"count"`
+}
+
+// ColumnStats holds data-quality statistics of a single column.
+type ColumnStats struct {
+	// Column is the name of the column.
+	Column string `json:"column"`
+
+	// Type is the database type of the column.
+	Type string `json:"type"`
+
+	// Cardinality is the number of distinct non-null values.
+	Cardinality int64 `json:"cardinality"`
+
+	// Nulls is the number of rows where the column is null.
+	Nulls int64 `json:"nulls"`
+
+	// Min is the smallest value of the column.
+	Min *string `json:"min"`
+
+	// Max is the largest value of the column.
+	Max *string `json:"max"`
+
+	// TopValues lists the most frequent values found in the sampled rows.
+	TopValues []ValueCount `json:"top_values"`
+}
+
+// TableColumnStats represents the response of the column statistics endpoint.
+type TableColumnStats struct {
+	// Table is the name of the table.
+	Table string `json:"table"`
+
+	// SampleSize is the maximum number of rows used to compute the top values.
+	SampleSize int `json:"sample_size"`
+
+	// Columns contains the statistics of every column.
+	Columns []ColumnStats `json:"columns"`
+}
+
+// StatsSnapshot is the size of a table at a point in time, stored periodically
+// in the stats_history table to chart growth trends.
+type StatsSnapshot struct {
+	// ID is the auto-incremented primary key of the snapshot.
+	ID uint `gorm:"primaryKey" json:"id"`
+
+	// Table is the name of the table.
+	Table string `gorm:"column:table_name;size:191;index:idx_stats_history_table_taken" json:"table"`
+
+	// Rows is the number of rows in the table.
+	Rows int64 `json:"rows"`
+
+	// DataBytes is the size of the table data in bytes (MySQL only).
+	DataBytes int64 `json:"data_bytes"`
+
+	// IndexBytes is the size of the table indexes in bytes (MySQL only).
+	IndexBytes int64 `json:"index_bytes"`
+
+	// TakenAt is the timestamp of the snapshot.
+	TakenAt time.Time `gorm:"index:idx_stats_history_table_taken" json:"taken_at"`
+}
+
+// TableName stores the snapshots in the stats_history table.
+func (StatsSnapshot) TableName() string {
+	return "stats_history"
+}
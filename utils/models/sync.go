@@ -0,0 +1,143 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// SyncOp is the operation of a local change pushed with POST /sync.
+type SyncOp string
+
+const (
+	// SyncUpsert creates the record, or updates it if it exists.
+	SyncUpsert SyncOp = "upsert"
+
+	// SyncDelete deletes the record.
+	SyncDelete SyncOp = "delete"
+)
+
+// SyncStatus is the outcome of a pushed change.
+type SyncStatus string
+
+const (
+	// SyncApplied means the change was stored.
+	SyncApplied SyncStatus = "applied"
+
+	// SyncConflict means the server copy changed since the client read it; the
+	// change was not stored and the result holds the server copy.
+	SyncConflict SyncStatus = "conflict"
+
+	// SyncRejected means the change is invalid or not allowed, and must not be retried as is.
+	SyncRejected SyncStatus = "rejected"
+)
+
+// SyncHint tells the client how a conflict happened, to resolve it.
+type SyncHint string
+
+const (
+	// SyncServerChanged means the record was updated on the server after BaseUpdatedAt.
+	// Merge the server copy and push again with its updated_at as BaseUpdatedAt.
+	SyncServerChanged SyncHint = "server_changed"
+
+	// SyncServerDeleted means the record was deleted on the server. Push it again
+	// without BaseUpdatedAt to recreate it, or drop the local copy.
+	SyncServerDeleted SyncHint = "server_deleted"
+
+	// SyncAlreadyExists means a record created offline has the ID of an existing
+	// record. Merge the server copy, or push it again with its updated_at to replace it.
+	SyncAlreadyExists SyncHint = "already_exists"
+)
+
+// SyncRequest is the body of POST /sync.
+type SyncRequest struct {
+	// Token is the token returned by the previous sync of the client, empty for the first one.
+	Token string `json:"token,omitempty"`
+
+	// Resources maps the resources to pull to the subset of their records the client mirrors.
+	Resources map[string]SyncSubset `json:"resources,omitempty"`
+
+	// Changes lists the local changes to push, applied in order before the pull.
+	Changes []SyncChange `json:"changes,omitempty"`
+}
+
+// SyncSubset is the subset of the records of a resource mirrored by a client.
+type SyncSubset struct {
+	// Filters maps query parameters to their value, as in the query string of
+	// GET /{resource} (e.g. {"field2": "foo"}). Empty mirrors every record.
+	Filters map[string]string `json:"filters,omitempty"`
+}
+
+// SyncChange is a local change pushed with POST /sync.
+type SyncChange struct {
+	// Resource is the resource of the record (e.g. "example1").
+	Resource string `json:"resource"`
+
+	// ID is the tokenized ID of the record.
+	ID string `json:"id"`
+
+	// Op is the operation: "upsert" or "delete".
+	Op SyncOp `json:"op"`
+
+	// Record holds the fields to store, for upserts. Omitted fields keep their server value.
+	Record json.RawMessage `json:"record,omitempty" swaggertype:"object"`
+
+	// BaseUpdatedAt is the updated_at of the server copy the change is based on,
+	// omitted for records created offline. The change is a conflict if the record
+	// was updated after it.
+	BaseUpdatedAt *time.Time `json:"base_updated_at,omitempty"`
+}
+
+// SyncResult is the outcome of a SyncChange, in the order of the changes.
+type SyncResult struct {
+	// Resource is the resource of the change.
+	Resource string `json:"resource"`
+
+	// ID is the tokenized ID of the record of the change.
+	ID string `json:"id"`
+
+	// Status is "applied", "conflict" or "rejected".
+	Status SyncStatus `json:"status"`
+
+	// Hint explains a conflict: "server_changed", "server_deleted" or "already_exists".
+	Hint SyncHint `json:"hint,omitempty"`
+
+	// Server is the server copy of the record in conflict, or the stored record once applied.
+	Server interface{} `json:"server,omitempty"`
+
+	// Fields lists the fields of the change whose value differs from the server
+	// copy, for "server_changed" and "already_exists" conflicts.
+	Fields []string `json:"fields,omitempty"`
+
+	// Error describes why the change was rejected.
+	Error string `json:"error,omitempty"`
+}
+
+// SyncResponse is the response of POST /sync.
+type SyncResponse struct {
+	// Token is the token to send with the next sync of the client.
+	Token string `json:"token"`
+
+	// Resources maps the pulled resources to their changes since the previous sync.
+	Resources map[string]SyncResourceChanges `json:"resources"`
+
+	// Results holds the outcome of the pushed changes, in order.
+	Results []SyncResult `json:"results"`
+}
+
+// SyncResourceChanges is the changes of a resource pulled with POST /sync.
+type SyncResourceChanges struct {
+	// Changed holds the records of the subset created or updated since the previous sync, oldest first.
+	Changed interface{} `json:"changed"`
+
+	// Deleted lists the tokenized IDs of the records deleted, or no longer in the
+	// subset, since the previous sync (tombstones).
+	Deleted []string `json:"deleted"`
+
+	// HasMore is true when more changes are available right away with the new token.
+	HasMore bool `json:"has_more"`
+
+	// Reset is true when the client must drop its local copy of the resource before
+	// applying the changes, which then hold the whole subset: on the first sync, after
+	// the subset changed, or when the previous sync is older than the deletion retention.
+	Reset bool `json:"reset"`
+}
@@ -0,0 +1,53 @@
+package models
+
+// UsageDayFormat is the format of the days of the usage rollups.
+const UsageDayFormat = "2006-01-02"
+
+// UsageRollup is the usage of the API by a user or service account during a
+// day (UTC): its requests, their errors and the bytes transferred, e.g. for
+// internal chargeback or to spot abusive clients.
+type UsageRollup struct {
+	// Day is the day of the requests, as YYYY-MM-DD in UTC.
+	Day string `gorm:"size:10;primaryKey" json:"day"`
+
+	// Principal is the username of the user, or the client ID of the service account.
+	Principal string `gorm:"size:191;primaryKey" json:"principal"`
+
+	// AccountType is "user" or "service".
+	AccountType AccountType `gorm:"size:16;primaryKey" json:"account_type"`
+
+	// Requests is the number of authenticated requests.
+	Requests int64 `json:"requests"`
+
+	// ClientErrors is the number of requests answered with a 4xx status.
+	ClientErrors int64 `json:"client_errors"`
+
+	// ServerErrors is the number of requests answered with a 5xx status.
+	ServerErrors int64 `json:"server_errors"`
+
+	// BytesIn is the number of bytes of the request bodies read.
+	BytesIn int64 `json:"bytes_in"`
+
+	// BytesOut is the number of bytes of the response bodies written.
+	BytesOut int64 `json:"bytes_out"`
+
+	// ErrorRate is the share of the requests answered with a 4xx or 5xx status, from 0 to 1.
+	ErrorRate float64 `gorm:"-" json:"error_rate"`
+}
+
+// Add adds the counters of another rollup to the rollup.
+func (u *UsageRollup) Add(other UsageRollup) {
+	u.Requests += other.Requests
+	u.ClientErrors += other.ClientErrors
+	u.ServerErrors += other.ServerErrors
+	u.BytesIn += other.BytesIn
+	u.BytesOut += other.BytesOut
+}
+
+// ComputeErrorRate sets ErrorRate from the counters.
+func (u *UsageRollup) ComputeErrorRate() {
+	u.ErrorRate = 0
+	if u.Requests > 0 {
+		u.ErrorRate = float64(u.ClientErrors+u.ServerErrors) / float64(u.Requests)
+	}
+}
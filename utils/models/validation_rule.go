@@ -0,0 +1,101 @@
+package models
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ValidationRuleType is the check applied by a validation rule.
+type ValidationRuleType string
+
+const (
+	// RuleRequired rejects missing, null and empty values.
+	RuleRequired ValidationRuleType = "required"
+
+	// RuleRegex rejects strings not matching the regular expression in the params.
+	RuleRegex ValidationRuleType = "regex"
+
+	// RuleMinLength rejects strings shorter than the number of characters in the params.
+	RuleMinLength ValidationRuleType = "min_length"
+
+	// RuleMaxLength rejects strings longer than the number of characters in the params.
+	RuleMaxLength ValidationRuleType = "max_length"
+
+	// RuleMin rejects numbers lower than the number in the params.
+	RuleMin ValidationRuleType = "min"
+
+	// RuleMax rejects numbers greater than the number in the params.
+	RuleMax ValidationRuleType = "max"
+
+	// RuleEnum rejects values not in the comma-separated list in the params.
+	RuleEnum ValidationRuleType = "enum"
+)
+
+// ValidationRule is a constraint on a resource field, checked on create and
+// update in addition to the struct tags, so operators can tighten the
+// constraints without recompiling.
+type ValidationRule struct {
+	// ID is the auto-incremented primary key of the rule.
+	ID uint `gorm:"primaryKey" json:"id"`
+
+	// Resource is the resource the rule applies to (e.g. "example1").
+	Resource string `gorm:"size:64;index" json:"resource"`
+
+	// Field is the JSON name of the checked field (e.g. "field2").
+	Field string `gorm:"size:64" json:"field"`
+
+	// Rule is the check: "required", "regex", "min_length", "max_length", "min", "max" or "enum".
+	Rule ValidationRuleType `gorm:"size:16" json:"rule"`
+
+	// Params is the parameter of the check (e.g. "^[a-z]+$" for regex or "10" for max_length).
+	Params string `gorm:"type:text" json:"params"`
+
+	// Message is the error returned when the check fails. Empty uses a generic message.
+	Message string `json:"message"`
+
+	// Enabled allows turning a rule off without deleting it.
+	Enabled bool `json:"enabled"`
+
+	// CreatedAt is the timestamp of when the rule was created.
+	CreatedAt time.Time `json:"created_at"`
+
+	// UpdatedAt is the timestamp of the last modification to the rule.
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Validate checks that the rule is complete and its params match its type.
+func (v ValidationRule) Validate() error {
+	if v.Resource == "" || v.Field == "" {
+		return errors.New("resource and field are required")
+	}
+
+	var err error
+
+	switch v.Rule {
+	case RuleRequired:
+	case RuleRegex:
+		_, err = regexp.Compile(v.Params)
+	case RuleMinLength, RuleMaxLength:
+		var length int
+		if length, err = strconv.Atoi(strings.TrimSpace(v.Params)); err == nil && length < 0 {
+			err = errors.New("negative length")
+		}
+	case RuleMin, RuleMax:
+		_, err = strconv.ParseFloat(strings.TrimSpace(v.Params), 64)
+	case RuleEnum:
+		if strings.TrimSpace(v.Params) == "" {
+			err = errors.New("empty list")
+		}
+	default:
+		return errors.New("rule must be required, regex, min_length, max_length, min, max or enum")
+	}
+
+	if err != nil {
+		return errors.New("invalid params for rule " + string(v.Rule) + ": " + err.Error())
+	}
+
+	return nil
+}
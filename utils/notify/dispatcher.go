@@ -0,0 +1,286 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+// FailureReporter receives the notifications that could not be delivered.
+type FailureReporter interface {
+	ReportNotificationFailure(rule models.NotificationRule, err error)
+}
+
+// Dispatcher evaluates notification rules against resource change events,
+// bursts of 5xx responses, fast-growing tables and dependencies going down, and
+// sends the matching notifications.
+//
+// It implements events.Publisher to receive change events from the event bus,
+// middlewares.ErrorReporter to receive server errors and database.GrowthReporter
+// to receive table growth alerts.
+type Dispatcher struct {
+	burstThreshold int
+	burstWindow    time.Duration
+
+	mu          sync.Mutex
+	rules       []models.NotificationRule
+	channels    map[string]Notifier
+	failures    FailureReporter
+	windowStart time.Time
+	windowCount int
+	burstSent   bool
+}
+
+// NewDispatcher creates a dispatcher with no rules.
+//
+// Parameters:
+// - burstThreshold: The number of 5xx responses within the window that triggers an error burst.
+// - burstWindow: The length of the window used to count 5xx responses.
+func NewDispatcher(burstThreshold int, burstWindow time.Duration) *Dispatcher {
+	return &Dispatcher{
+		burstThreshold: burstThreshold,
+		burstWindow:    burstWindow,
+		channels:       map[string]Notifier{},
+	}
+}
+
+// RegisterChannel adds a channel whose notifier is configured by the application
+// (e.g. "telegram") instead of by the webhook URL of each rule.
+func (d *Dispatcher) RegisterChannel(channel string, notifier Notifier) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.channels[channel] = notifier
+}
+
+// SetFailureReporter sets the reporter told about the notifications that could not be delivered.
+func (d *Dispatcher) SetFailureReporter(reporter FailureReporter) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.failures = reporter
+}
+
+// HasChannel reports whether rules can use the channel.
+func (d *Dispatcher) HasChannel(channel string) bool {
+	if channel == "slack" || channel == "teams" {
+		return true
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	_, ok := d.channels[channel]
+
+	return ok
+}
+
+// SetRules replaces the rules evaluated by the dispatcher. Disabled rules are ignored.
+func (d *Dispatcher) SetRules(rules []models.NotificationRule) {
+	enabled := []models.NotificationRule{}
+
+	for _, rule := range rules {
+		if rule.Enabled {
+			enabled = append(enabled, rule)
+		}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.rules = enabled
+}
+
+// Publish sends a notification for every rule matching the event.
+func (d *Dispatcher) Publish(ctx context.Context, event models.ResourceEvent) error {
+	var record map[string]interface{}
+
+	for _, rule := range d.currentRules() {
+		if rule.Event == models.EventErrorBurst || rule.Event == models.EventTableGrowth ||
+			rule.Event == models.EventDependency ||
+			(rule.Resource != "" && rule.Resource != event.Resource) ||
+			(rule.Event != "" && rule.Event != string(event.Type)) {
+			continue
+		}
+
+		if rule.Filter != "" {
+			if record == nil {
+				record = toMap(event.Data)
+			}
+
+			if !MatchesFilter(rule.Filter, record) {
+				continue
+			}
+		}
+
+		actor := event.Actor
+		if event.ActorType == models.ServiceAccountType {
+			actor = "service account " + actor
+		}
+
+		msg := Message{
+			Title: fmt.Sprintf("[%s] %s %s", rule.Name, event.Resource, event.Type),
+			Text:  fmt.Sprintf("Record %s was %s by %s at %s", event.RecordID, event.Type, actor, event.Timestamp.Format(time.RFC3339)),
+		}
+
+		d.send(ctx, rule, msg)
+	}
+
+	return nil
+}
+
+// Close implements events.Publisher.
+func (d *Dispatcher) Close() error {
+	return nil
+}
+
+// ReportPanic counts a recovered panic as a 5xx response.
+func (d *Dispatcher) ReportPanic(r *http.Request, _ interface{}) {
+	d.ReportError(r, http.StatusInternalServerError, "")
+}
+
+// ReportError counts a 5xx response and notifies the error burst rules once per
+// window when the threshold is reached.
+func (d *Dispatcher) ReportError(r *http.Request, status int, _ string) {
+	d.mu.Lock()
+
+	now := time.Now()
+	if now.Sub(d.windowStart) > d.burstWindow {
+		d.windowStart = now
+		d.windowCount = 0
+		d.burstSent = false
+	}
+
+	d.windowCount++
+
+	if d.burstSent || d.windowCount < d.burstThreshold {
+		d.mu.Unlock()
+
+		return
+	}
+
+	d.burstSent = true
+	count := d.windowCount
+	rules := d.rules
+	d.mu.Unlock()
+
+	msg := Message{
+		Title: "Burst of server errors",
+		Text: fmt.Sprintf("%d responses with status 5xx in the last %s (latest: %d on %s %s)",
+			count, d.burstWindow, status, r.Method, r.URL.Path),
+	}
+
+	for _, rule := range rules {
+		if rule.Event == models.EventErrorBurst {
+			go d.send(context.Background(), rule, msg)
+		}
+	}
+}
+
+// ReportTableGrowth notifies the table_growth rules matching the table.
+func (d *Dispatcher) ReportTableGrowth(growth models.TableGrowth) {
+	msg := Message{
+		Title: "Table growing too fast: " + growth.Table,
+		Text: fmt.Sprintf("%s of %s grew %.1f%% (%d to %d) since %s, above the %.1f%% threshold",
+			growth.Metric, growth.Table, growth.GrowthPercent, growth.From, growth.To,
+			growth.Since.Format(time.RFC3339), growth.ThresholdPercent),
+	}
+
+	for _, rule := range d.currentRules() {
+		if rule.Event == models.EventTableGrowth && (rule.Resource == "" || rule.Resource == growth.Table) {
+			go d.send(context.Background(), rule, msg)
+		}
+	}
+}
+
+// ReportDependency notifies the dependency rules matching a dependency that
+// just went down or recovered.
+func (d *Dispatcher) ReportDependency(check models.DependencyCheck) {
+	msg := Message{
+		Title: "Dependency recovered: " + check.Name,
+		Text:  fmt.Sprintf("%s answers %d again, in %d ms", check.URL, check.LastStatusCode, check.LastLatencyMs),
+	}
+
+	if check.State == models.DependencyDown {
+		msg = Message{
+			Title: "Dependency down: " + check.Name,
+			Text: fmt.Sprintf("%s failed %d checks in a row: %s", check.URL, check.ConsecutiveFailures,
+				check.LastError),
+		}
+	}
+
+	for _, rule := range d.currentRules() {
+		if rule.Event == models.EventDependency && (rule.Resource == "" || rule.Resource == check.Name) {
+			go d.send(context.Background(), rule, msg)
+		}
+	}
+}
+
+// currentRules returns the rules under lock.
+func (d *Dispatcher) currentRules() []models.NotificationRule {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.rules
+}
+
+// MatchesFilter reports whether a record matches every key=value pair of a query-string filter.
+func MatchesFilter(filter string, record map[string]interface{}) bool {
+	values, err := url.ParseQuery(filter)
+	if err != nil {
+		return false
+	}
+
+	for key := range values {
+		value, ok := record[key]
+		if !ok || fmt.Sprint(value) != values.Get(key) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// send delivers a message through the rule's channel, logging failures.
+func (d *Dispatcher) send(ctx context.Context, rule models.NotificationRule, msg Message) {
+	d.mu.Lock()
+	notifier, ok := d.channels[rule.Channel]
+	failures := d.failures
+	d.mu.Unlock()
+
+	var err error
+	if !ok {
+		notifier, err = NewNotifier(rule.Channel, rule.WebhookURL)
+	}
+
+	if err == nil {
+		err = notifier.Notify(ctx, msg)
+	}
+
+	if err != nil {
+		log.Printf("Error sending notification for rule %d (%s): %v", rule.ID, rule.Name, err)
+
+		if failures != nil {
+			failures.ReportNotificationFailure(rule, err)
+		}
+	}
+}
+
+// toMap converts a record into a map keyed by its JSON field names.
+func toMap(data interface{}) map[string]interface{} {
+	record := map[string]interface{}{}
+
+	raw, err := json.Marshal(data)
+	if err == nil {
+		_ = json.Unmarshal(raw, &record)
+	}
+
+	return record
+}
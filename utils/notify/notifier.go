@@ -0,0 +1,107 @@
+// Package notify sends messages to ops channels like Slack or Microsoft Teams.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/r4ulcl/api_template/utils/httpclient"
+)
+
+// httpTimeout is the maximum time a webhook call can take.
+const httpTimeout = 10 * time.Second
+
+// webhookClient posts to the webhooks and the Telegram API, with retries.
+var webhookClient = httpclient.New(httpTimeout)
+
+// Message is a channel-agnostic notification.
+type Message struct {
+	// Title is a short summary of the notification.
+	Title string
+
+	// Text is the body of the notification.
+	Text string
+}
+
+// Notifier delivers messages to a channel.
+type Notifier interface {
+	Notify(ctx context.Context, msg Message) error
+}
+
+// NewNotifier creates the notifier for a channel kind.
+//
+// Parameters:
+// - channel: "slack" or "teams".
+// - webhookURL: The incoming webhook URL of the channel.
+//
+// Returns:
+// - The notifier, or an error if the channel is unknown.
+func NewNotifier(channel, webhookURL string) (Notifier, error) {
+	switch channel {
+	case "slack":
+		return &SlackNotifier{WebhookURL: webhookURL}, nil
+	case "teams":
+		return &TeamsNotifier{WebhookURL: webhookURL}, nil
+	default:
+		return nil, fmt.Errorf("unknown channel %q (expected slack or teams)", channel)
+	}
+}
+
+// SlackNotifier posts messages to a Slack incoming webhook.
+type SlackNotifier struct {
+	WebhookURL string
+}
+
+// Notify posts the message using Slack's mrkdwn format.
+func (sn *SlackNotifier) Notify(ctx context.Context, msg Message) error {
+	return postJSON(ctx, sn.WebhookURL, map[string]string{
+		"text": "*" + msg.Title + "*\n" + msg.Text,
+	})
+}
+
+// TeamsNotifier posts messages to a Microsoft Teams incoming webhook.
+type TeamsNotifier struct {
+	WebhookURL string
+}
+
+// Notify posts the message as a Teams MessageCard.
+func (tn *TeamsNotifier) Notify(ctx context.Context, msg Message) error {
+	return postJSON(ctx, tn.WebhookURL, map[string]string{
+		"@type":    "MessageCard",
+		"@context": "http://schema.org/extensions",
+		"summary":  msg.Title,
+		"title":    msg.Title,
+		"text":     msg.Text,
+	})
+}
+
+// postJSON posts a JSON payload and checks for a 2xx response.
+func postJSON(ctx context.Context, url string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
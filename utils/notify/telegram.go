@@ -0,0 +1,211 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/r4ulcl/api_template/utils/httpclient"
+)
+
+const (
+	// telegramAPIURL is the base URL of the Telegram Bot API.
+	telegramAPIURL = "https://api.telegram.org"
+
+	// telegramPollTimeout is the long-polling timeout of getUpdates in seconds.
+	telegramPollTimeout = 30
+
+	// telegramRetryDelay is the wait after a failed getUpdates call.
+	telegramRetryDelay = 5 * time.Second
+)
+
+// pollClient long-polls getUpdates. It doesn't retry: the poll loop waits
+// telegramRetryDelay and polls again.
+var pollClient = &httpclient.Client{HTTP: &http.Client{Timeout: telegramPollTimeout*time.Second + httpTimeout}}
+
+// CommandFunc answers a bot command with the text to reply.
+type CommandFunc func(ctx context.Context) (string, error)
+
+// TelegramBot sends notifications to a Telegram chat and answers simple
+// commands (e.g. "/stats") sent from that chat.
+type TelegramBot struct {
+	token  string
+	chatID string
+
+	mu       sync.Mutex
+	commands map[string]CommandFunc
+}
+
+// telegramUpdate is the subset of a Telegram update used by the bot.
+type telegramUpdate struct {
+	UpdateID int64 `json:"update_id"`
+	Message  *struct {
+		Chat struct {
+			ID int64 `json:"id"`
+		} `json:"chat"`
+		Text string `json:"text"`
+	} `json:"message"`
+}
+
+// NewTelegramBot creates a bot.
+//
+// Parameters:
+// - token: The bot token given by @BotFather.
+// - chatID: The chat that receives notifications. Only commands from this chat are answered.
+func NewTelegramBot(token, chatID string) *TelegramBot {
+	return &TelegramBot{
+		token:    token,
+		chatID:   chatID,
+		commands: map[string]CommandFunc{},
+	}
+}
+
+// Notify sends the message to the configured chat.
+func (tb *TelegramBot) Notify(ctx context.Context, msg Message) error {
+	return tb.sendMessage(ctx, tb.chatID, msg.Title+"\n"+msg.Text)
+}
+
+// HandleCommand registers the handler of a command, without the leading slash.
+func (tb *TelegramBot) HandleCommand(name string, handler CommandFunc) {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	tb.commands[name] = handler
+}
+
+// Run polls Telegram for new messages and answers the commands until the
+// context is canceled.
+func (tb *TelegramBot) Run(ctx context.Context) {
+	var offset int64
+
+	for ctx.Err() == nil {
+		updates, err := tb.getUpdates(ctx, offset)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Println("Error polling Telegram:", err)
+
+				select {
+				case <-ctx.Done():
+				case <-time.After(telegramRetryDelay):
+				}
+			}
+
+			continue
+		}
+
+		for _, update := range updates {
+			offset = update.UpdateID + 1
+
+			if update.Message == nil || strconv.FormatInt(update.Message.Chat.ID, 10) != tb.chatID {
+				continue
+			}
+
+			tb.handleMessage(ctx, update.Message.Text)
+		}
+	}
+}
+
+// handleMessage runs the command contained in a message and replies with its result.
+func (tb *TelegramBot) handleMessage(ctx context.Context, text string) {
+	fields := strings.Fields(text)
+	if len(fields) == 0 || !strings.HasPrefix(fields[0], "/") {
+		return
+	}
+
+	// Commands sent in groups look like "/stats@my_bot"
+	name, _, _ := strings.Cut(strings.TrimPrefix(fields[0], "/"), "@")
+
+	tb.mu.Lock()
+	handler, ok := tb.commands[name]
+	names := make([]string, 0, len(tb.commands))
+
+	for command := range tb.commands {
+		names = append(names, "/"+command)
+	}
+	tb.mu.Unlock()
+
+	var reply string
+
+	if ok {
+		var err error
+
+		reply, err = handler(ctx)
+		if err != nil {
+			reply = "Error: " + err.Error()
+		}
+	} else {
+		sort.Strings(names)
+		reply = "Available commands: " + strings.Join(names, ", ")
+	}
+
+	if err := tb.sendMessage(ctx, tb.chatID, reply); err != nil {
+		log.Println("Error answering Telegram command:", err)
+	}
+}
+
+// sendMessage sends a text message to a chat.
+func (tb *TelegramBot) sendMessage(ctx context.Context, chatID, text string) error {
+	err := postJSON(ctx, tb.methodURL("sendMessage"), map[string]string{
+		"chat_id": chatID,
+		"text":    text,
+	})
+
+	return tb.redact(err)
+}
+
+// getUpdates long-polls the updates received after the offset.
+func (tb *TelegramBot) getUpdates(ctx context.Context, offset int64) ([]telegramUpdate, error) {
+	query := url.Values{}
+	query.Set("offset", strconv.FormatInt(offset, 10))
+	query.Set("timeout", strconv.Itoa(telegramPollTimeout))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, tb.methodURL("getUpdates")+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := pollClient.Do(req)
+	if err != nil {
+		return nil, tb.redact(err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		OK          bool             `json:"ok"`
+		Description string           `json:"description"`
+		Result      []telegramUpdate `json:"result"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+
+	if !body.OK {
+		return nil, fmt.Errorf("telegram returned status %d: %s", resp.StatusCode, body.Description)
+	}
+
+	return body.Result, nil
+}
+
+// redact removes the bot token from errors that include the request URL.
+func (tb *TelegramBot) redact(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return errors.New(strings.ReplaceAll(err.Error(), tb.token, "<token>"))
+}
+
+// methodURL returns the URL of a Bot API method.
+func (tb *TelegramBot) methodURL(method string) string {
+	return telegramAPIURL + "/bot" + tb.token + "/" + method
+}
@@ -0,0 +1,330 @@
+// Package openapi builds the OpenAPI (Swagger 2.0) document of a deployment
+// from the one generated by swag: the generic /{resource} paths are expanded
+// into one path per resource registered on the router, with the schema of its
+// model, so the clients generated from it are typed per resource.
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+// resourceParam is the path parameter of the generic resource paths.
+const resourceParam = "{resource}"
+
+// operations lists the keys of the operations of a path item.
+var operations = []string{"get", "put", "post", "delete", "options", "head", "patch"}
+
+// routeVariable matches the variables of the gorilla/mux path templates with a
+// pattern (e.g. {id:[0-9]+}), documented without it.
+var routeVariable = regexp.MustCompile(`\{(\w+):[^}]+\}`)
+
+// timeType is the type of the time fields, documented as date-time strings.
+var timeType = reflect.TypeOf(time.Time{})
+
+// jsonMarshaler is the interface of the types with their own JSON encoding.
+var jsonMarshaler = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+// Resource is a resource of the deployment.
+type Resource struct {
+	// Name is the name of the resource in the paths (e.g. "example1").
+	Name string
+
+	// Model is the struct type of its records (e.g. models.Example1).
+	Model reflect.Type
+}
+
+// Build returns the OpenAPI document of a deployment.
+//
+// Only the operations of the routes registered on the router are kept, so
+// the features turned off are not documented. Each operation of a path with
+// {resource} becomes one operation per resource it is registered for, tagged
+// with the name of the resource. The CRUD operations (/{resource} and
+// /{resource}/{id}) take and return the model of the resource, added to the
+// definitions if swag didn't document it. Every operation gets an operationId
+// (e.g. getExample1ById), used by the generators to name the methods.
+//
+// Parameters:
+// - doc: The document generated by swag (docs.SwaggerInfo.ReadDoc()).
+// - resources: The resources of the deployment.
+// - routes: The routes of the router (see models.RouteInfo).
+//
+// Returns:
+// - The document, or an error if doc is not valid JSON.
+func Build(doc []byte, resources []Resource, routes []models.RouteInfo) (map[string]interface{}, error) {
+	var spec map[string]interface{}
+	if err := json.Unmarshal(doc, &spec); err != nil {
+		return nil, err
+	}
+
+	definitions, _ := spec["definitions"].(map[string]interface{})
+	if definitions == nil {
+		definitions = map[string]interface{}{}
+		spec["definitions"] = definitions
+	}
+
+	registered := map[string]bool{}
+
+	for _, route := range routes {
+		path := routeVariable.ReplaceAllString(route.Path, "{$1}")
+
+		// The routes without methods match them all (e.g. the proxy routes)
+		if len(route.Methods) == 0 {
+			registered["* "+path] = true
+		}
+
+		for _, method := range route.Methods {
+			registered[strings.ToLower(method)+" "+path] = true
+		}
+	}
+
+	isRegistered := func(method, path string) bool {
+		return registered[method+" "+path] || registered["* "+path]
+	}
+
+	paths, _ := spec["paths"].(map[string]interface{})
+	expanded := map[string]interface{}{}
+
+	for path, item := range paths {
+		pathItem, _ := item.(map[string]interface{})
+
+		for _, method := range operations {
+			operation, ok := pathItem[method].(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			// Some routes take the resource as a variable (e.g. /me/favorites/{resource}/{id})
+			if isRegistered(method, path) {
+				addOperation(expanded, path, method, operation)
+
+				continue
+			}
+
+			if !strings.Contains(path, resourceParam) {
+				continue
+			}
+
+			for _, resource := range resources {
+				resourcePath := strings.ReplaceAll(path, resourceParam, resource.Name)
+				if !isRegistered(method, resourcePath) {
+					continue
+				}
+
+				ref := definitionRef(definitions, resource.Model)
+				addOperation(expanded, resourcePath, method, resourceOperation(operation, path, method, resource, ref))
+			}
+		}
+	}
+
+	spec["paths"] = expanded
+
+	return spec, nil
+}
+
+// addOperation adds an operation to the paths, with an operationId if it has none.
+func addOperation(paths map[string]interface{}, path, method string, operation map[string]interface{}) {
+	if _, ok := operation["operationId"]; !ok {
+		operation["operationId"] = operationID(method, path)
+	}
+
+	pathItem, ok := paths[path].(map[string]interface{})
+	if !ok {
+		pathItem = map[string]interface{}{}
+		paths[path] = pathItem
+	}
+
+	pathItem[method] = operation
+}
+
+// resourceOperation returns the operation of a generic path for one resource:
+// without the resource parameter, tagged with the resource and, on the CRUD
+// paths, with the model of the resource as body and response.
+func resourceOperation(generic map[string]interface{}, path, method string, resource Resource,
+	ref map[string]interface{},
+) map[string]interface{} {
+	operation := deepCopy(generic)
+	operation["tags"] = []interface{}{resource.Name}
+
+	crud := path == "/"+resourceParam || path == "/"+resourceParam+"/{id}"
+	hasBody := false
+	params := []interface{}{}
+
+	list, _ := operation["parameters"].([]interface{})
+	for _, p := range list {
+		param, _ := p.(map[string]interface{})
+
+		switch {
+		case param["in"] == "path" && param["name"] == "resource":
+			continue
+		case param["in"] == "path":
+			// Path parameters are always required
+			param["required"] = true
+		case param["in"] == "body" && crud:
+			// The generic operations list the bodies of every resource
+			hasBody = true
+
+			continue
+		}
+
+		params = append(params, param)
+	}
+
+	if hasBody {
+		params = append(params, map[string]interface{}{
+			"name": "body", "in": "body", "required": true, "schema": ref,
+			"description": "The " + resource.Name + " record",
+		})
+	}
+
+	operation["parameters"] = params
+
+	if !crud {
+		return operation
+	}
+
+	responses, _ := operation["responses"].(map[string]interface{})
+	if responses == nil {
+		responses = map[string]interface{}{}
+		operation["responses"] = responses
+	}
+
+	switch {
+	case method == "get" && path == "/"+resourceParam:
+		responses["200"] = map[string]interface{}{
+			"description": "OK", "schema": map[string]interface{}{"type": "array", "items": ref},
+		}
+	case method == "get":
+		responses["200"] = map[string]interface{}{"description": "OK", "schema": ref}
+	case method == "post":
+		responses["201"] = map[string]interface{}{"description": "Created", "schema": ref}
+	}
+
+	return operation
+}
+
+// definitionRef returns the reference to the definition of a model, added to
+// the definitions from its Go type if swag didn't document it.
+func definitionRef(definitions map[string]interface{}, model reflect.Type) map[string]interface{} {
+	name := "models." + model.Name()
+	if _, ok := definitions[name]; !ok {
+		definitions[name] = schemaOf(model, map[reflect.Type]bool{})
+	}
+
+	return map[string]interface{}{"$ref": "#/definitions/" + name}
+}
+
+// schemaOf returns the schema of a Go type, from the JSON names of its fields.
+// Types already being described (recursive structs) are plain objects.
+func schemaOf(t reflect.Type, seen map[reflect.Type]bool) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == timeType {
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+
+	// e.g. models.JSON, whose documents have any shape
+	if t.Implements(jsonMarshaler) || reflect.PointerTo(t).Implements(jsonMarshaler) {
+		return map[string]interface{}{"type": "object"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string", "format": "byte"}
+		}
+
+		return map[string]interface{}{"type": "array", "items": schemaOf(t.Elem(), seen)}
+	case reflect.Struct:
+		if seen[t] {
+			return map[string]interface{}{"type": "object"}
+		}
+
+		seen[t] = true
+		defer delete(seen, t)
+
+		properties := map[string]interface{}{}
+		addProperties(properties, t, seen)
+
+		return map[string]interface{}{"type": "object", "properties": properties}
+	default:
+		return map[string]interface{}{"type": "object"}
+	}
+}
+
+// addProperties adds the schemas of the exported fields of a struct by JSON
+// name, with the fields of the embedded structs.
+func addProperties(properties map[string]interface{}, t reflect.Type, seen map[reflect.Type]bool) {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		if name == "" && field.Anonymous && field.Type.Kind() == reflect.Struct {
+			addProperties(properties, field.Type, seen)
+
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		properties[name] = schemaOf(field.Type, seen)
+	}
+}
+
+// operationID returns the operationId of an operation from its method and
+// path, e.g. getExample1ById for GET /example1/{id}.
+func operationID(method, path string) string {
+	var b strings.Builder
+
+	b.WriteString(method)
+
+	for _, segment := range strings.Split(path, "/") {
+		if strings.HasPrefix(segment, "{") {
+			b.WriteString("By")
+		}
+
+		words := strings.FieldsFunc(segment, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+		for _, word := range words {
+			b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+		}
+	}
+
+	return b.String()
+}
+
+// deepCopy copies an operation, so the copies of a generic operation can be
+// changed independently.
+func deepCopy(operation map[string]interface{}) map[string]interface{} {
+	encoded, _ := json.Marshal(operation)
+
+	var copied map[string]interface{}
+	_ = json.Unmarshal(encoded, &copied)
+
+	return copied
+}
@@ -0,0 +1,202 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/r4ulcl/api_template/utils/httpclient"
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+// Policy decides whether a request is authorized, replacing the admin-only
+// check of the admin routes.
+type Policy interface {
+	// Allow returns whether the request described by input is allowed.
+	// An error means no decision could be made, and the request is denied.
+	Allow(ctx context.Context, input models.PolicyInput) (bool, error)
+}
+
+// NewPolicy returns the policy of the configuration: an OPAPolicy with an OPA
+// URL, a FilePolicy with a policy file, or nil (admin-only routes) with neither.
+func NewPolicy(opaURL, file string) (Policy, error) {
+	switch {
+	case opaURL != "" && file != "":
+		return nil, errors.New("set only one of the OPA URL and the policy file")
+	case opaURL != "":
+		return &OPAPolicy{URL: opaURL}, nil
+	case file != "":
+		return LoadPolicyFile(file)
+	}
+
+	return nil, nil
+}
+
+// opaClient queries the OPA servers. Decisions are on the path of the admin
+// requests, so a failed query is retried once.
+var opaClient = &httpclient.Client{
+	HTTP:       &http.Client{Timeout: 5 * time.Second},
+	MaxRetries: 1,
+	BaseDelay:  50 * time.Millisecond,
+	MaxDelay:   time.Second,
+}
+
+// OPAPolicy delegates the decisions to an Open Policy Agent server, e.g. a
+// sidecar, through its data API.
+type OPAPolicy struct {
+	// URL is the data API URL of the decision, e.g. "http://localhost:8181/v1/data/api/allow".
+	URL string
+
+	// Client sends the queries. It is optional: without it, a shared client with a 5 second timeout is used.
+	Client *httpclient.Client
+}
+
+// Allow posts {"input": input} to the data API and reads the boolean "result"
+// of the response. An undefined decision (no "result") denies the request.
+func (p *OPAPolicy) Allow(ctx context.Context, input models.PolicyInput) (bool, error) {
+	body, err := json.Marshal(map[string]interface{}{"input": input})
+	if err != nil {
+		return false, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	client := p.Client
+	if client == nil {
+		client = opaClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("OPA returned status %d", resp.StatusCode)
+	}
+
+	var decision struct {
+		Result *bool `json:"result"`
+	}
+
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&decision); err != nil {
+		return false, fmt.Errorf("invalid OPA response: %w", err)
+	}
+
+	return decision.Result != nil && *decision.Result, nil
+}
+
+// FilePolicy is an embedded policy read from a file in the CSV format of the
+// Casbin RBAC policies:
+//
+//	p, admin, *, *
+//	p, user, example1, GET
+//	p, reporter, reports, (GET)|(POST)
+//	g, alice, reporter
+//
+// A "p" line allows a subject (a username or a role) an action (an HTTP method)
+// on an object (a resource). Objects are path patterns ("*" matches anything)
+// and actions are either a method, "*", or alternatives in parentheses joined
+// by "|". A "g" line gives a user an additional role.
+type FilePolicy struct {
+	rules  []policyRule
+	groups map[string][]string
+}
+
+// policyRule is a "p" line of a FilePolicy.
+type policyRule struct {
+	subject string
+	object  string
+	actions []string
+}
+
+// LoadPolicyFile reads a FilePolicy. Empty lines and lines starting with "#" are ignored.
+func LoadPolicyFile(name string) (*FilePolicy, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	policy := &FilePolicy{groups: map[string][]string{}}
+	scanner := bufio.NewScanner(file)
+
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		reader := csv.NewReader(strings.NewReader(text))
+		reader.TrimLeadingSpace = true
+
+		fields, err := reader.Read()
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		switch {
+		case fields[0] == "p" && len(fields) == 4:
+			policy.rules = append(policy.rules, policyRule{
+				subject: fields[1],
+				object:  fields[2],
+				actions: strings.Split(strings.NewReplacer("(", "", ")", "").Replace(fields[3]), "|"),
+			})
+		case fields[0] == "g" && len(fields) == 3:
+			policy.groups[fields[1]] = append(policy.groups[fields[1]], fields[2])
+		default:
+			return nil, fmt.Errorf("line %d: expected \"p, subject, object, action\" or \"g, user, role\"", line)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(policy.rules) == 0 {
+		return nil, errors.New("the policy has no \"p\" lines")
+	}
+
+	return policy, nil
+}
+
+// Allow returns whether a "p" line allows the user, its role or one of its
+// "g" roles the method on the resource.
+func (p *FilePolicy) Allow(_ context.Context, input models.PolicyInput) (bool, error) {
+	subjects := append([]string{input.Username, string(input.Role)}, p.groups[input.Username]...)
+
+	for _, rule := range p.rules {
+		if !slices.Contains(subjects, rule.subject) {
+			continue
+		}
+
+		if matched, _ := path.Match(rule.object, input.Resource); !matched {
+			continue
+		}
+
+		for _, action := range rule.actions {
+			if action == "*" || strings.EqualFold(action, input.Method) {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
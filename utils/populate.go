@@ -0,0 +1,268 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidPopulate is returned when a "populate" tag can't be applied to its field.
+var ErrInvalidPopulate = errors.New("invalid populate tag")
+
+const (
+	// PopulateUser sets the field to the username of the authenticated user.
+	PopulateUser = "user"
+
+	// PopulateNow sets the field to the current server time.
+	PopulateNow = "now"
+
+	// populateDefaultPrefix sets the field to a default value when the client leaves it empty.
+	populateDefaultPrefix = "default="
+
+	// populateValuePrefix sets the field to a fixed value, ignoring the client value.
+	populateValuePrefix = "value="
+
+	// populateSequencePrefix sets the field to the next number of a sequence when
+	// the record is inserted, ignoring the client value.
+	populateSequencePrefix = "sequence="
+)
+
+// timeType is the reflect type of time.Time.
+var timeType = reflect.TypeOf(time.Time{})
+
+// ServerPopulated reports whether a struct field is always set by the server,
+// i.e. it has a `populate:"user"`, `populate:"now"`, `populate:"value=..."` or
+// `populate:"sequence=..."` tag. Clients can't change it.
+func ServerPopulated(field reflect.StructField) bool {
+	tag := field.Tag.Get("populate")
+
+	return tag == PopulateUser || tag == PopulateNow || strings.HasPrefix(tag, populateValuePrefix) ||
+		strings.HasPrefix(tag, populateSequencePrefix)
+}
+
+// OwnerField returns the name of the field holding the owner of the records of
+// a struct type, i.e. its first field with a `populate:"user"` tag, and whether it has one.
+func OwnerField(typ reflect.Type) (string, bool) {
+	for i := range typ.NumField() {
+		if typ.Field(i).Tag.Get("populate") == PopulateUser {
+			return typ.Field(i).Name, true
+		}
+	}
+
+	return "", false
+}
+
+// SequenceTemplate returns the reference number template of a struct field from
+// its `populate:"sequence=..."` tag (e.g. "INV-{year}-{seq:4}"), and whether the
+// field has one. The numbers are assigned by the database layer on insert.
+func SequenceTemplate(field reflect.StructField) (string, bool) {
+	return strings.CutPrefix(field.Tag.Get("populate"), populateSequencePrefix)
+}
+
+// DefaultValue returns the default value of a struct field from its
+// `populate:"default=..."` tag, and whether the field has one.
+func DefaultValue(field reflect.StructField) (string, bool) {
+	return strings.CutPrefix(field.Tag.Get("populate"), populateDefaultPrefix)
+}
+
+// PopulateFields sets the fields of a new record according to their "populate" tag:
+// - user: The username of the authenticated user (e.g. CreatedBy).
+// - now: The current server time (e.g. CreatedAt).
+// - default=<value>: The value, when the client leaves the field empty.
+// - value=<value>: The value, always (e.g. the initial status of a record).
+// - sequence=<template>: Cleared here; the database layer sets the next reference
+// number when the record is inserted.
+//
+// The values sent by the client for "user", "now", "value" and "sequence" fields are ignored.
+// String, number, bool, time.Time and pointer fields are supported, and embedded
+// struct fields are populated too. Associations (e.g. a foreign key reference)
+// are left untouched, so GORM does not upsert them.
+//
+// Parameters:
+// - model: A pointer to the struct to populate. Other values are ignored.
+// - username: The authenticated user creating the record.
+// - now: The creation time.
+//
+// Returns:
+// - ErrInvalidPopulate if a tag does not fit the type of its field.
+func PopulateFields(model interface{}, username string, now time.Time) error {
+	val := reflect.ValueOf(model)
+	if val.Kind() != reflect.Ptr || val.IsNil() || val.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+
+	return populateStruct(val.Elem(), username, now)
+}
+
+// populateStruct applies the populate tags to the fields of a struct value.
+func populateStruct(val reflect.Value, username string, now time.Time) error {
+	typ := val.Type()
+
+	for i := range val.NumField() {
+		field := val.Field(i)
+		structField := typ.Field(i)
+
+		if !field.CanSet() {
+			continue
+		}
+
+		tag, ok := structField.Tag.Lookup("populate")
+		if !ok {
+			if embeddedStruct(structField) {
+				if err := populateStruct(field, username, now); err != nil {
+					return err
+				}
+			}
+
+			continue
+		}
+
+		var err error
+
+		switch {
+		case tag == PopulateUser:
+			err = setFieldValue(field, username)
+		case tag == PopulateNow:
+			err = setFieldTime(field, now)
+		case strings.HasPrefix(tag, populateValuePrefix):
+			err = setFieldValue(field, strings.TrimPrefix(tag, populateValuePrefix))
+		case strings.HasPrefix(tag, populateSequencePrefix):
+			if field.Kind() != reflect.String {
+				err = fmt.Errorf("%s is not a string", field.Type())
+			} else {
+				field.SetString("")
+			}
+		case strings.HasPrefix(tag, populateDefaultPrefix):
+			if field.IsZero() {
+				err = setFieldValue(field, strings.TrimPrefix(tag, populateDefaultPrefix))
+			}
+		default:
+			err = fmt.Errorf("unknown rule %q", tag)
+		}
+
+		if err != nil {
+			return fmt.Errorf("%w: field %s: %s", ErrInvalidPopulate, structField.Name, err.Error())
+		}
+	}
+
+	return nil
+}
+
+// KeepServerFields restores the server-populated fields (see ServerPopulated) of an
+// updated record from its stored version, so clients can't change them.
+//
+// Parameters:
+// - model: A pointer to the updated struct.
+// - original: The stored struct, or a pointer to it, of the same type.
+func KeepServerFields(model, original interface{}) {
+	val := reflect.ValueOf(model)
+	if val.Kind() != reflect.Ptr || val.IsNil() || val.Elem().Kind() != reflect.Struct {
+		return
+	}
+
+	orig := reflect.Indirect(reflect.ValueOf(original))
+	if orig.Type() != val.Elem().Type() {
+		return
+	}
+
+	keepStructFields(val.Elem(), orig)
+}
+
+// keepStructFields copies the server-populated fields of orig into val.
+func keepStructFields(val, orig reflect.Value) {
+	typ := val.Type()
+
+	for i := range val.NumField() {
+		field := val.Field(i)
+		if !field.CanSet() {
+			continue
+		}
+
+		switch {
+		case ServerPopulated(typ.Field(i)):
+			field.Set(orig.Field(i))
+		case embeddedStruct(typ.Field(i)):
+			keepStructFields(field, orig.Field(i))
+		}
+	}
+}
+
+// embeddedStruct reports whether a struct field is a struct whose columns are
+// stored in the table of its parent: an anonymous field or a `gorm:"embedded"` one.
+func embeddedStruct(field reflect.StructField) bool {
+	if field.Type.Kind() != reflect.Struct || field.Type == timeType {
+		return false
+	}
+
+	return field.Anonymous || slices.Contains(strings.Split(field.Tag.Get("gorm"), ";"), "embedded")
+}
+
+// setFieldTime sets a time.Time or *time.Time field.
+func setFieldTime(field reflect.Value, now time.Time) error {
+	switch field.Type() {
+	case timeType:
+		field.Set(reflect.ValueOf(now))
+	case reflect.PointerTo(timeType):
+		field.Set(reflect.ValueOf(&now))
+	default:
+		return fmt.Errorf("%s is not a time", field.Type())
+	}
+
+	return nil
+}
+
+// setFieldValue parses a string into a field of kind string, bool, int, uint or
+// float, or a pointer to one of them.
+func setFieldValue(field reflect.Value, value string) error {
+	if field.Kind() == reflect.Ptr {
+		ptr := reflect.New(field.Type().Elem())
+		if err := setFieldValue(ptr.Elem(), value); err != nil {
+			return err
+		}
+
+		field.Set(ptr)
+
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+
+		field.SetBool(parsed)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		parsed, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		field.SetInt(parsed)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		parsed, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		field.SetUint(parsed)
+	case reflect.Float32, reflect.Float64:
+		parsed, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		field.SetFloat(parsed)
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+
+	return nil
+}
@@ -0,0 +1,492 @@
+// Package protobuf encodes the records of the resources in the protocol
+// buffers wire format, smaller and cheaper to parse than JSON for the
+// integrations exchanging large batches. There is no generated code: each
+// message is described by the field numbers registered for its model (see
+// NewCodec), and Proto returns the .proto file clients generate theirs from.
+package protobuf
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"mime"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MediaType is the media type of the protobuf messages.
+const MediaType = "application/x-protobuf"
+
+// ListSuffix ends the name of the message holding a list of records
+// (e.g. Example1List), given in the messageType parameter of the media type.
+const ListSuffix = "List"
+
+// maxFieldNumber is the largest field number allowed by protobuf.
+const maxFieldNumber = 1<<29 - 1
+
+// identifier matches the names allowed for the messages and their fields.
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// timeType is the type of the time fields, sent as RFC 3339 strings.
+var timeType = reflect.TypeOf(time.Time{})
+
+// jsonMarshaler is the interface of the types with their own JSON encoding.
+var jsonMarshaler = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+// kind is the protobuf type of a field.
+type kind int
+
+const (
+	kindString kind = iota
+	kindBool
+	kindInt
+	kindUint
+	kindFloat
+	kindDouble
+	kindBytes
+	kindTime // string, RFC 3339
+	kindJSON // string, the JSON encoding of the value
+)
+
+// protoTypes are the names of the types in the .proto file.
+var protoTypes = map[kind]string{
+	kindString: "string", kindBool: "bool", kindInt: "int64", kindUint: "uint64", kindFloat: "float",
+	kindDouble: "double", kindBytes: "bytes", kindTime: "string", kindJSON: "string",
+}
+
+// field describes a field of a message.
+type field struct {
+	number  int
+	name    string // The JSON name of the field
+	index   []int  // The index of the field in the struct of the model
+	kind    kind
+	pointer bool // Pointers are optional fields, omitted when nil
+}
+
+// Codec encodes and decodes the records of a model as protobuf messages.
+type Codec struct {
+	name     string
+	fields   []*field
+	byNumber map[int]*field
+}
+
+// NewCodec creates the codec of a model.
+//
+// The fields are mapped by type: strings, booleans, integers, floats and
+// []byte to the matching protobuf types, time.Time to RFC 3339 strings, and
+// any other type (structs, maps, types with their own JSON encoding) to the
+// string of its JSON encoding. Pointers are optional fields.
+//
+// Parameters:
+// - modelType: The struct type of the model (e.g. models.Example1).
+// - numbers: The field number of each JSON field sent. Numbers must never change or be reused.
+//
+// Returns:
+// - An error if a field doesn't exist or a number is invalid or duplicated.
+func NewCodec(modelType reflect.Type, numbers map[string]int) (*Codec, error) {
+	if modelType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("protobuf: %s is not a struct", modelType)
+	}
+
+	codec := &Codec{name: modelType.Name(), byNumber: map[int]*field{}}
+
+	structFields := jsonFields(modelType)
+
+	for name, number := range numbers {
+		structField, ok := structFields[name]
+		if !ok {
+			return nil, fmt.Errorf("protobuf: %s has no field %q", codec.name, name)
+		}
+
+		if !identifier.MatchString(name) {
+			return nil, fmt.Errorf("protobuf: %q is not a valid field name", name)
+		}
+
+		if number < 1 || number > maxFieldNumber || (number >= 19000 && number <= 19999) {
+			return nil, fmt.Errorf("protobuf: invalid number %d of field %q", number, name)
+		}
+
+		if other, ok := codec.byNumber[number]; ok {
+			return nil, fmt.Errorf("protobuf: fields %q and %q share the number %d", other.name, name, number)
+		}
+
+		f := &field{number: number, name: name, index: structField.Index}
+
+		fieldType := structField.Type
+		if fieldType.Kind() == reflect.Ptr {
+			f.pointer = true
+			fieldType = fieldType.Elem()
+		}
+
+		f.kind = kindOf(fieldType)
+		codec.fields = append(codec.fields, f)
+		codec.byNumber[number] = f
+	}
+
+	sort.Slice(codec.fields, func(i, j int) bool { return codec.fields[i].number < codec.fields[j].number })
+
+	return codec, nil
+}
+
+// jsonFields returns the exported fields of a struct by JSON name.
+func jsonFields(structType reflect.Type) map[string]reflect.StructField {
+	fields := map[string]reflect.StructField{}
+
+	for i := range structType.NumField() {
+		structField := structType.Field(i)
+		if !structField.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(structField.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		if name == "" {
+			name = structField.Name
+		}
+
+		fields[name] = structField
+	}
+
+	return fields
+}
+
+// kindOf returns the protobuf type of a Go type.
+func kindOf(t reflect.Type) kind {
+	if t == timeType {
+		return kindTime
+	}
+
+	if t.Implements(jsonMarshaler) || reflect.PointerTo(t).Implements(jsonMarshaler) {
+		return kindJSON
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return kindString
+	case reflect.Bool:
+		return kindBool
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return kindInt
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return kindUint
+	case reflect.Float32:
+		return kindFloat
+	case reflect.Float64:
+		return kindDouble
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return kindBytes
+		}
+	}
+
+	return kindJSON
+}
+
+// Name returns the name of the message of a record.
+func (c *Codec) Name() string {
+	return c.name
+}
+
+// ListName returns the name of the message of a list of records.
+func (c *Codec) ListName() string {
+	return c.name + ListSuffix
+}
+
+// Proto returns the .proto file describing the messages of the codec.
+//
+// Parameters:
+// - resource: The name of the resource, for the comments.
+func (c *Codec) Proto(resource string) string {
+	var b strings.Builder
+
+	b.WriteString("syntax = \"proto3\";\n\npackage api;\n\n")
+	fmt.Fprintf(&b, "// %s is a record of the %s resource.\nmessage %s {\n", c.name, resource, c.name)
+
+	for _, f := range c.fields {
+		label := ""
+		if f.pointer {
+			label = "optional "
+		}
+
+		comment := ""
+
+		switch f.kind {
+		case kindTime:
+			comment = " // RFC 3339"
+		case kindJSON:
+			comment = " // JSON"
+		}
+
+		fmt.Fprintf(&b, "  %s%s %s = %d;%s\n", label, protoTypes[f.kind], f.name, f.number, comment)
+	}
+
+	fmt.Fprintf(&b, "}\n\n// %s is a list of records of the %s resource.\n", c.ListName(), resource)
+	fmt.Fprintf(&b, "message %s {\n  repeated %s items = 1;\n}\n", c.ListName(), c.name)
+
+	return b.String()
+}
+
+// ContentType returns the media type of a message: a record, or a list of
+// records when list is true.
+func (c *Codec) ContentType(list bool) string {
+	name := c.name
+	if list {
+		name = c.ListName()
+	}
+
+	return MediaType + "; messageType=" + name
+}
+
+// IsProtobuf reports whether a Content-Type is protobuf, and whether the
+// message is a list of records (its messageType ends with ListSuffix).
+func IsProtobuf(contentType string) (protobuf, list bool) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil || (mediaType != MediaType && mediaType != "application/protobuf") {
+		return false, false
+	}
+
+	// The names of the parameters are lowercased
+	return true, strings.HasSuffix(params["messagetype"], ListSuffix)
+}
+
+// Accepted reports whether an Accept header asks for protobuf.
+func Accepted(accept string) bool {
+	for _, part := range strings.Split(accept, ",") {
+		if protobuf, _ := IsProtobuf(strings.TrimSpace(part)); protobuf {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Marshal encodes a record.
+//
+// Parameters:
+// - record: A pointer to a struct of the model of the codec.
+func (c *Codec) Marshal(record interface{}) ([]byte, error) {
+	return c.appendMessage(nil, reflect.Indirect(reflect.ValueOf(record)))
+}
+
+// MarshalList encodes a list of records, each in the repeated field 1.
+//
+// Parameters:
+// - records: A pointer to a slice of the model of the codec.
+func (c *Codec) MarshalList(records interface{}) ([]byte, error) {
+	slice := reflect.Indirect(reflect.ValueOf(records))
+
+	var (
+		b       []byte
+		message []byte
+		err     error
+	)
+
+	for i := range slice.Len() {
+		if message, err = c.appendMessage(message[:0], slice.Index(i)); err != nil {
+			return nil, err
+		}
+
+		b = appendTag(b, 1, wireBytes)
+		b = appendBytes(b, message)
+	}
+
+	return b, nil
+}
+
+// appendMessage appends the encoding of a struct.
+func (c *Codec) appendMessage(b []byte, record reflect.Value) ([]byte, error) {
+	for _, f := range c.fields {
+		value := record.FieldByIndex(f.index)
+
+		if f.pointer {
+			if value.IsNil() {
+				continue
+			}
+
+			value = value.Elem()
+		} else if value.IsZero() {
+			// Like proto3, the zero values are not sent
+			continue
+		}
+
+		var err error
+		if b, err = f.appendValue(b, value); err != nil {
+			return nil, fmt.Errorf("protobuf: field %q: %w", f.name, err)
+		}
+	}
+
+	return b, nil
+}
+
+// appendValue appends a field with its value.
+func (f *field) appendValue(b []byte, value reflect.Value) ([]byte, error) {
+	switch f.kind {
+	case kindString:
+		return appendBytes(appendTag(b, f.number, wireBytes), []byte(value.String())), nil
+	case kindBool:
+		v := uint64(0)
+		if value.Bool() {
+			v = 1
+		}
+
+		return appendVarint(appendTag(b, f.number, wireVarint), v), nil
+	case kindInt:
+		return appendVarint(appendTag(b, f.number, wireVarint), uint64(value.Int())), nil
+	case kindUint:
+		return appendVarint(appendTag(b, f.number, wireVarint), value.Uint()), nil
+	case kindFloat:
+		return appendFixed32(appendTag(b, f.number, wireFixed32), math.Float32bits(float32(value.Float()))), nil
+	case kindDouble:
+		return appendFixed64(appendTag(b, f.number, wireFixed64), math.Float64bits(value.Float())), nil
+	case kindBytes:
+		return appendBytes(appendTag(b, f.number, wireBytes), value.Bytes()), nil
+	case kindTime:
+		text := value.Interface().(time.Time).Format(time.RFC3339Nano)
+
+		return appendBytes(appendTag(b, f.number, wireBytes), []byte(text)), nil
+	default:
+		encoded, err := json.Marshal(addressable(value).Interface())
+		if err != nil || string(encoded) == "null" {
+			return b, err
+		}
+
+		return appendBytes(appendTag(b, f.number, wireBytes), encoded), nil
+	}
+}
+
+// Unmarshal decodes a record. Unknown fields are skipped.
+//
+// Parameters:
+// - data: The encoded message.
+// - record: A pointer to a struct of the model of the codec.
+func (c *Codec) Unmarshal(data []byte, record interface{}) error {
+	value := reflect.ValueOf(record)
+	if value.Kind() != reflect.Ptr || value.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("protobuf: %T is not a pointer to a struct", record)
+	}
+
+	return c.decodeMessage(data, value.Elem())
+}
+
+// UnmarshalList decodes a list of records. Fields other than 1 are skipped.
+//
+// Parameters:
+// - data: The encoded message.
+// - records: A pointer to a slice of the model of the codec.
+func (c *Codec) UnmarshalList(data []byte, records interface{}) error {
+	slice := reflect.ValueOf(records)
+	if slice.Kind() != reflect.Ptr || slice.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("protobuf: %T is not a pointer to a slice", records)
+	}
+
+	slice = slice.Elem()
+
+	return eachField(data, func(number int, wire wireType, value []byte, _ uint64) error {
+		if number != 1 || wire != wireBytes {
+			return nil
+		}
+
+		record := reflect.New(slice.Type().Elem()).Elem()
+		if err := c.decodeMessage(value, record); err != nil {
+			return err
+		}
+
+		slice.Set(reflect.Append(slice, record))
+
+		return nil
+	})
+}
+
+// decodeMessage decodes a message into a struct.
+func (c *Codec) decodeMessage(data []byte, record reflect.Value) error {
+	return eachField(data, func(number int, wire wireType, value []byte, scalar uint64) error {
+		f, ok := c.byNumber[number]
+		if !ok {
+			return nil
+		}
+
+		if wire != f.wireType() {
+			return fmt.Errorf("protobuf: field %q has wire type %d instead of %d", f.name, wire, f.wireType())
+		}
+
+		target := record.FieldByIndex(f.index)
+		if f.pointer {
+			if target.IsNil() {
+				target.Set(reflect.New(target.Type().Elem()))
+			}
+
+			target = target.Elem()
+		}
+
+		if err := f.setValue(target, value, scalar); err != nil {
+			return fmt.Errorf("protobuf: field %q: %w", f.name, err)
+		}
+
+		return nil
+	})
+}
+
+// wireType returns the wire type of the values of a field.
+func (f *field) wireType() wireType {
+	switch f.kind {
+	case kindBool, kindInt, kindUint:
+		return wireVarint
+	case kindFloat:
+		return wireFixed32
+	case kindDouble:
+		return wireFixed64
+	default:
+		return wireBytes
+	}
+}
+
+// setValue sets a field to a decoded value: the bytes of the length-delimited
+// values, or the number of the others.
+func (f *field) setValue(target reflect.Value, value []byte, scalar uint64) error {
+	switch f.kind {
+	case kindString:
+		target.SetString(string(value))
+	case kindBool:
+		target.SetBool(scalar != 0)
+	case kindInt:
+		target.SetInt(int64(scalar))
+	case kindUint:
+		target.SetUint(scalar)
+	case kindFloat:
+		target.SetFloat(float64(math.Float32frombits(uint32(scalar))))
+	case kindDouble:
+		target.SetFloat(math.Float64frombits(scalar))
+	case kindBytes:
+		target.SetBytes(append([]byte{}, value...))
+	case kindTime:
+		parsed, err := time.Parse(time.RFC3339Nano, string(value))
+		if err != nil {
+			return err
+		}
+
+		target.Set(reflect.ValueOf(parsed))
+	default:
+		return json.Unmarshal(value, addressable(target).Interface())
+	}
+
+	return nil
+}
+
+// addressable returns a pointer to a value, so the methods with a pointer
+// receiver (e.g. UnmarshalJSON) are used.
+func addressable(value reflect.Value) reflect.Value {
+	if value.CanAddr() {
+		return value.Addr()
+	}
+
+	pointer := reflect.New(value.Type())
+	pointer.Elem().Set(value)
+
+	return pointer
+}
@@ -0,0 +1,185 @@
+package protobuf
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// wireType is the encoding of a field value, stored in the low bits of its tag.
+type wireType int
+
+const (
+	wireVarint  wireType = 0
+	wireFixed64 wireType = 1
+	wireBytes   wireType = 2
+	wireFixed32 wireType = 5
+)
+
+// errTruncated is returned for the messages ending in the middle of a field.
+var errTruncated = errors.New("protobuf: truncated message")
+
+// appendTag appends the tag of a field.
+func appendTag(b []byte, number int, wire wireType) []byte {
+	return appendVarint(b, uint64(number)<<3|uint64(wire))
+}
+
+// appendVarint appends a varint, the encoding of protobuf integers.
+func appendVarint(b []byte, v uint64) []byte {
+	return binary.AppendUvarint(b, v)
+}
+
+// appendFixed32 appends a little-endian 32-bit value.
+func appendFixed32(b []byte, v uint32) []byte {
+	return binary.LittleEndian.AppendUint32(b, v)
+}
+
+// appendFixed64 appends a little-endian 64-bit value.
+func appendFixed64(b []byte, v uint64) []byte {
+	return binary.LittleEndian.AppendUint64(b, v)
+}
+
+// appendBytes appends a length-delimited value.
+func appendBytes(b, v []byte) []byte {
+	return append(appendVarint(b, uint64(len(v))), v...)
+}
+
+// eachField calls a function with each field of a message: its number, wire
+// type, and value, as bytes for length-delimited values, or as a number.
+func eachField(data []byte, fn func(number int, wire wireType, value []byte, scalar uint64) error) error {
+	for len(data) > 0 {
+		tag, n := binary.Uvarint(data)
+		if n <= 0 {
+			return errTruncated
+		}
+
+		data = data[n:]
+		number, wire := int(tag>>3), wireType(tag&7)
+
+		var (
+			value  []byte
+			scalar uint64
+		)
+
+		switch wire {
+		case wireVarint:
+			if scalar, n = binary.Uvarint(data); n <= 0 {
+				return errTruncated
+			}
+		case wireFixed64:
+			if n = 8; len(data) < n {
+				return errTruncated
+			}
+
+			scalar = binary.LittleEndian.Uint64(data)
+		case wireFixed32:
+			if n = 4; len(data) < n {
+				return errTruncated
+			}
+
+			scalar = uint64(binary.LittleEndian.Uint32(data))
+		case wireBytes:
+			length, m := binary.Uvarint(data)
+			if m <= 0 || uint64(len(data)-m) < length {
+				return errTruncated
+			}
+
+			value = data[m : m+int(length)]
+			n = m + int(length)
+		default:
+			return fmt.Errorf("protobuf: unsupported wire type %d", wire)
+		}
+
+		data = data[n:]
+
+		if number < 1 {
+			return fmt.Errorf("protobuf: invalid field number %d", number)
+		}
+
+		if err := fn(number, wire, value, scalar); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ListReader reads the records of a list message one by one, so a large list
+// is decoded while it is received.
+type ListReader struct {
+	r        *bufio.Reader
+	maxBytes int
+}
+
+// NewListReader creates a reader of the records of a list message.
+//
+// Parameters:
+// - r: The encoded list.
+// - maxBytes: The largest record accepted.
+func NewListReader(r io.Reader, maxBytes int) *ListReader {
+	return &ListReader{r: bufio.NewReader(r), maxBytes: maxBytes}
+}
+
+// Next returns the encoding of the next record, decoded with Unmarshal, or
+// io.EOF after the last one. Fields other than 1 are skipped.
+func (lr *ListReader) Next() ([]byte, error) {
+	for {
+		tag, err := binary.ReadUvarint(lr.r)
+		if err != nil {
+			return nil, err
+		}
+
+		number, wire := int(tag>>3), wireType(tag&7)
+
+		var length uint64
+
+		switch wire {
+		case wireVarint:
+			_, err = binary.ReadUvarint(lr.r)
+		case wireFixed64:
+			_, err = lr.r.Discard(8)
+		case wireFixed32:
+			_, err = lr.r.Discard(4)
+		case wireBytes:
+			if length, err = binary.ReadUvarint(lr.r); err == nil && length > uint64(lr.maxBytes) {
+				return nil, fmt.Errorf("protobuf: a record is longer than %d bytes", lr.maxBytes)
+			}
+		default:
+			return nil, fmt.Errorf("protobuf: unsupported wire type %d", wire)
+		}
+
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+
+		if wire != wireBytes {
+			continue
+		}
+
+		if number != 1 {
+			if _, err := lr.r.Discard(int(length)); err != nil {
+				return nil, unexpectedEOF(err)
+			}
+
+			continue
+		}
+
+		record := make([]byte, length)
+		if _, err := io.ReadFull(lr.r, record); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+
+		return record, nil
+	}
+}
+
+// unexpectedEOF reports the end of the input in the middle of a field as truncated.
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return errTruncated
+	}
+
+	return err
+}
@@ -0,0 +1,150 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+// Identity headers sent to the upstream services. Clients can't set them, since
+// incoming headers with the same names are removed.
+const (
+	HeaderAuthUser        = "X-Auth-User"
+	HeaderAuthRole        = "X-Auth-Role"
+	HeaderAuthAccountType = "X-Auth-Account-Type"
+	HeaderAuthTimestamp   = "X-Auth-Timestamp"
+	HeaderAuthSignature   = "X-Auth-Signature"
+)
+
+// ServiceProxy forwards requests to named upstream services, adding the
+// identity of the caller as signed headers.
+type ServiceProxy struct {
+	secret  []byte
+	proxies map[string]*httputil.ReverseProxy
+}
+
+// NewServiceProxy creates a proxy for the given upstream services.
+//
+// Parameters:
+// - services: Comma-separated "name=url" pairs (e.g. "billing=http://billing:9000").
+// - secret: The key used to sign the identity headers.
+//
+// Returns:
+// - The proxy, or an error if a service is malformed or the secret is empty.
+func NewServiceProxy(services, secret string) (*ServiceProxy, error) {
+	if secret == "" {
+		return nil, fmt.Errorf("a signing secret is required")
+	}
+
+	sp := &ServiceProxy{secret: []byte(secret), proxies: map[string]*httputil.ReverseProxy{}}
+
+	for _, item := range strings.Split(services, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		name, rawURL, ok := strings.Cut(item, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid service %q, expected name=url", item)
+		}
+
+		target, err := url.Parse(strings.TrimSpace(rawURL))
+		if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+			return nil, fmt.Errorf("invalid URL for service %q", name)
+		}
+
+		sp.proxies[strings.TrimSpace(name)] = sp.newReverseProxy(target)
+	}
+
+	return sp, nil
+}
+
+// Has reports whether a service is configured. It returns false on a nil proxy.
+func (sp *ServiceProxy) Has(name string) bool {
+	if sp == nil {
+		return false
+	}
+
+	_, ok := sp.proxies[name]
+
+	return ok
+}
+
+// Forward proxies a request to a service.
+//
+// Parameters:
+// - w: The HTTP response writer.
+// - r: The incoming request.
+// - name: The name of the service, which must exist (see Has).
+// - path: The path of the request on the service.
+// - caller: The authenticated caller, sent as signed headers.
+func (sp *ServiceProxy) Forward(w http.ResponseWriter, r *http.Request, name, path string, caller models.Principal) {
+	out := r.Clone(r.Context())
+	out.URL.Path = path
+	out.URL.RawPath = ""
+
+	// The bearer token is for this API only, and identity headers can't come from the client
+	out.Header.Del("Authorization")
+
+	for _, header := range []string{HeaderAuthUser, HeaderAuthRole, HeaderAuthAccountType, HeaderAuthTimestamp,
+		HeaderAuthSignature} {
+		out.Header.Del(header)
+	}
+
+	out.Header.Set(HeaderAuthUser, caller.Username)
+	out.Header.Set(HeaderAuthRole, string(caller.Role))
+	out.Header.Set(HeaderAuthAccountType, string(caller.AccountType))
+	out.Header.Set(HeaderAuthTimestamp, strconv.FormatInt(time.Now().Unix(), 10))
+
+	sp.proxies[name].ServeHTTP(w, out)
+}
+
+// SignIdentity returns the hex-encoded HMAC-SHA256 of the identity headers,
+// the method and the path of a proxied request, one per line:
+//
+//	user\nrole\naccount_type\ntimestamp\nMETHOD\n/path
+//
+// The path is the one received by the upstream service. Upstream services
+// recompute the signature with the shared secret to trust the headers, and
+// should reject old timestamps.
+func SignIdentity(secret []byte, header http.Header, method, path string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(strings.Join([]string{
+		header.Get(HeaderAuthUser), header.Get(HeaderAuthRole), header.Get(HeaderAuthAccountType),
+		header.Get(HeaderAuthTimestamp), method, path,
+	}, "\n")))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// newReverseProxy creates the reverse proxy of an upstream service.
+func (sp *ServiceProxy) newReverseProxy(target *url.URL) *httputil.ReverseProxy {
+	return &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			path := pr.Out.URL.Path
+			pr.SetURL(target)
+			pr.Out.URL.Path = strings.TrimRight(target.Path, "/") + path
+			pr.Out.URL.RawPath = ""
+			pr.SetXForwarded()
+			pr.Out.Header.Set(HeaderAuthSignature, SignIdentity(sp.secret, pr.Out.Header, pr.Out.Method, pr.Out.URL.Path))
+		},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			log.Printf("Error proxying %s %s to %s: %v", r.Method, r.URL.Path, target.Host, err)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadGateway)
+			_ = json.NewEncoder(w).Encode(models.ErrorResponse{Error: "Upstream service unavailable"})
+		},
+	}
+}
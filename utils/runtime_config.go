@@ -0,0 +1,209 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/r4ulcl/api_template/utils/models"
+)
+
+// Keys of the runtime configuration parameters. They are also the keys of the
+// settings overriding them.
+const (
+	RuntimeLogLevel    = "log_level"
+	RuntimeRateLimit   = "rate_limit_per_minute"
+	RuntimeCORSOrigins = "cors_origins"
+	RuntimeMaintenance = "maintenance_mode"
+)
+
+// RuntimeKeys lists the parameters that can be changed at runtime.
+var RuntimeKeys = []string{RuntimeCORSOrigins, RuntimeLogLevel, RuntimeMaintenance, RuntimeRateLimit}
+
+// LogLevels lists the accepted values of the log_level parameter.
+var LogLevels = []string{"silent", "error", "warn", "info"}
+
+// RuntimeConfig holds the configuration parameters that are safe to change
+// while the application runs.
+//
+// Startup values come from the environment (or defaults) and are overridden
+// by settings with the same key whenever Apply is called.
+type RuntimeConfig struct {
+	mu      sync.RWMutex
+	base    map[string]models.ConfigValue
+	current map[string]models.ConfigValue
+}
+
+// NewRuntimeConfig creates the runtime configuration from the startup configuration.
+func NewRuntimeConfig(cfg *Config) *RuntimeConfig {
+	base := map[string]models.ConfigValue{
+		RuntimeLogLevel:    {Key: RuntimeLogLevel, Value: cfg.LogLevel, Source: envSource("LOG_LEVEL")},
+		RuntimeRateLimit:   {Key: RuntimeRateLimit, Value: cfg.RateLimitPerMinute, Source: envSource("RATE_LIMIT_PER_MINUTE")},
+		RuntimeCORSOrigins: {Key: RuntimeCORSOrigins, Value: cfg.CORSOrigins, Source: envSource("CORS_ORIGINS")},
+		RuntimeMaintenance: {Key: RuntimeMaintenance, Value: cfg.MaintenanceMode, Source: envSource("MAINTENANCE_MODE")},
+	}
+
+	return &RuntimeConfig{base: base, current: base}
+}
+
+// Apply recomputes the effective values from the startup values and the given
+// settings (key to JSON value). Settings with an invalid value are ignored.
+//
+// Returns the keys whose effective value changed.
+func (rc *RuntimeConfig) Apply(settings map[string]json.RawMessage) []string {
+	current := make(map[string]models.ConfigValue, len(rc.base))
+
+	for key, value := range rc.base {
+		current[key] = value
+
+		raw, ok := settings[key]
+		if !ok {
+			continue
+		}
+
+		parsed, err := parseRuntimeValue(key, raw)
+		if err != nil {
+			log.Printf("Ignoring setting %s: %v", key, err)
+
+			continue
+		}
+
+		current[key] = models.ConfigValue{Key: key, Value: parsed, Source: "settings"}
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	changed := []string{}
+
+	for _, key := range RuntimeKeys {
+		if rc.current[key] != current[key] {
+			changed = append(changed, key)
+			log.Printf("Runtime config %s set to %v (source: %s)", key, current[key].Value, current[key].Source)
+		}
+	}
+
+	rc.current = current
+
+	return changed
+}
+
+// Values returns the effective value and source of every parameter, ordered by key.
+func (rc *RuntimeConfig) Values() []models.ConfigValue {
+	rc.mu.RLock()
+	defer rc.mu.RUnlock()
+
+	values := make([]models.ConfigValue, 0, len(RuntimeKeys))
+	for _, key := range RuntimeKeys {
+		values = append(values, rc.current[key])
+	}
+
+	return values
+}
+
+// LogLevel returns the database log level. It is safe to call on a nil *RuntimeConfig.
+func (rc *RuntimeConfig) LogLevel() string {
+	value, _ := rc.get(RuntimeLogLevel).(string)
+
+	return value
+}
+
+// RateLimit returns the requests allowed per client and minute, 0 meaning
+// unlimited. It is safe to call on a nil *RuntimeConfig.
+func (rc *RuntimeConfig) RateLimit() int {
+	value, _ := rc.get(RuntimeRateLimit).(int)
+
+	return value
+}
+
+// CORSOrigins returns the origins allowed to call the API from a browser.
+// It is safe to call on a nil *RuntimeConfig.
+func (rc *RuntimeConfig) CORSOrigins() []string {
+	value, _ := rc.get(RuntimeCORSOrigins).(string)
+
+	origins := []string{}
+
+	for _, origin := range strings.Split(value, ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			origins = append(origins, origin)
+		}
+	}
+
+	return origins
+}
+
+// MaintenanceMode reports whether writes are restricted to admins.
+// It is safe to call on a nil *RuntimeConfig.
+func (rc *RuntimeConfig) MaintenanceMode() bool {
+	value, _ := rc.get(RuntimeMaintenance).(bool)
+
+	return value
+}
+
+// get returns the effective value of a parameter, or nil.
+func (rc *RuntimeConfig) get(key string) interface{} {
+	if rc == nil {
+		return nil
+	}
+
+	rc.mu.RLock()
+	defer rc.mu.RUnlock()
+
+	return rc.current[key].Value
+}
+
+// ValidateRuntimeSetting checks the JSON value of a setting overriding a runtime
+// parameter. Settings with other keys are always valid.
+func ValidateRuntimeSetting(key string, raw json.RawMessage) error {
+	if !slices.Contains(RuntimeKeys, key) {
+		return nil
+	}
+
+	_, err := parseRuntimeValue(key, raw)
+
+	return err
+}
+
+// parseRuntimeValue decodes and validates the JSON value of a parameter.
+func parseRuntimeValue(key string, raw json.RawMessage) (interface{}, error) {
+	switch key {
+	case RuntimeRateLimit:
+		var value int
+		if err := json.Unmarshal(raw, &value); err != nil || value < 0 {
+			return nil, fmt.Errorf("expected a non-negative integer, got %s", raw)
+		}
+
+		return value, nil
+	case RuntimeMaintenance:
+		var value bool
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return nil, fmt.Errorf("expected a boolean, got %s", raw)
+		}
+
+		return value, nil
+	default:
+		var value string
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return nil, fmt.Errorf("expected a string, got %s", raw)
+		}
+
+		if key == RuntimeLogLevel && !slices.Contains(LogLevels, value) {
+			return nil, fmt.Errorf("expected one of %s, got %q", strings.Join(LogLevels, ", "), value)
+		}
+
+		return value, nil
+	}
+}
+
+// envSource returns "env" if the environment variable is set, "default" otherwise.
+func envSource(key string) string {
+	if _, ok := os.LookupEnv(key); ok {
+		return "env"
+	}
+
+	return "default"
+}
@@ -0,0 +1,101 @@
+package utils
+
+import (
+	"html"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// htmlTagPattern matches HTML comments, script and style elements with their
+// content, and any other tag.
+var htmlTagPattern = regexp.MustCompile(`(?is)<!--.*?-->|<script[^>]*>.*?</script\s*>|<style[^>]*>.*?</style\s*>|<[^>]*>`)
+
+// Sanitize cleans the string fields of a struct according to their "sanitize" tag.
+//
+// The tag holds comma-separated rules, applied in this order:
+// - nfc / nfkc: Normalize the unicode representation.
+// - strip: Remove HTML tags, comments and script/style elements.
+// - escape: Escape HTML special characters (<, >, &, ' and ").
+// - trim: Remove leading and trailing whitespace.
+// - lower: Convert to lower case.
+//
+// For example `sanitize:"trim,strip"`. Fields without the tag are left as is.
+// String, *string and nested struct fields are supported.
+//
+// Parameters:
+// - model: A pointer to the struct to sanitize. Other values are ignored.
+func Sanitize(model interface{}) {
+	val := reflect.ValueOf(model)
+	if val.Kind() != reflect.Ptr || val.IsNil() || val.Elem().Kind() != reflect.Struct {
+		return
+	}
+
+	sanitizeStruct(val.Elem())
+}
+
+// sanitizeStruct applies the sanitize rules to the fields of a struct value.
+func sanitizeStruct(val reflect.Value) {
+	typ := val.Type()
+
+	for i := range val.NumField() {
+		field := val.Field(i)
+		if !field.CanSet() {
+			continue
+		}
+
+		tag, ok := typ.Field(i).Tag.Lookup("sanitize")
+
+		switch {
+		case field.Kind() == reflect.Struct:
+			sanitizeStruct(field)
+		case !ok:
+			continue
+		case field.Kind() == reflect.String:
+			field.SetString(SanitizeString(field.String(), tag))
+		case field.Kind() == reflect.Ptr && !field.IsNil() && field.Elem().Kind() == reflect.String:
+			field.Elem().SetString(SanitizeString(field.Elem().String(), tag))
+		}
+	}
+}
+
+// SanitizeString applies comma-separated sanitize rules to a string.
+// See Sanitize for the available rules.
+func SanitizeString(value, rules string) string {
+	set := map[string]bool{}
+	for _, rule := range strings.Split(rules, ",") {
+		set[strings.TrimSpace(rule)] = true
+	}
+
+	if set["nfkc"] {
+		value = norm.NFKC.String(value)
+	} else if set["nfc"] {
+		value = norm.NFC.String(value)
+	}
+
+	if set["strip"] {
+		value = htmlTagPattern.ReplaceAllString(value, "")
+	}
+
+	if set["escape"] {
+		value = html.EscapeString(value)
+	}
+
+	if set["trim"] {
+		value = strings.TrimSpace(value)
+	}
+
+	if set["lower"] {
+		value = strings.ToLower(value)
+	}
+
+	return value
+}
+
+// NormalizeUsername returns the canonical form of a username (trimmed and
+// lower case), so "Admin" and "admin" are the same user.
+func NormalizeUsername(username string) string {
+	return SanitizeString(username, "trim,lower")
+}
@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// SentryReporter forwards panics and server errors to Sentry.
+type SentryReporter struct{}
+
+// NewSentryReporter initializes the Sentry SDK.
+//
+// Parameters:
+// - dsn: The Sentry DSN of the project.
+// - environment: The environment name attached to every event (e.g. "production").
+//
+// Returns:
+// - The reporter, or an error if the DSN is invalid.
+func NewSentryReporter(dsn, environment string) (*SentryReporter, error) {
+	err := sentry.Init(sentry.ClientOptions{
+		Dsn:              dsn,
+		Environment:      environment,
+		AttachStacktrace: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &SentryReporter{}, nil
+}
+
+// ReportPanic sends the recovered panic value to Sentry with the request context.
+func (sr *SentryReporter) ReportPanic(r *http.Request, recovered interface{}) {
+	hub := requestHub(r)
+	hub.RecoverWithContext(r.Context(), recovered)
+}
+
+// ReportError sends a 5xx response to Sentry with the request context.
+func (sr *SentryReporter) ReportError(r *http.Request, status int, body string) {
+	hub := requestHub(r)
+	hub.WithScope(func(scope *sentry.Scope) {
+		scope.SetTag("status", fmt.Sprint(status))
+		scope.SetExtra("response_body", body)
+		hub.CaptureMessage(fmt.Sprintf("HTTP %d on %s %s", status, r.Method, r.URL.Path))
+	})
+}
+
+// Flush waits until the buffered events are sent or the timeout expires.
+func (sr *SentryReporter) Flush(timeout time.Duration) {
+	sentry.Flush(timeout)
+}
+
+// requestHub clones the current hub and attaches the request to its scope.
+func requestHub(r *http.Request) *sentry.Hub {
+	hub := sentry.CurrentHub().Clone()
+	hub.Scope().SetRequest(r)
+
+	return hub
+}
@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"strings"
+)
+
+// ParseSigningKey decodes an Ed25519 private key from its base64-encoded
+// 32-byte seed, e.g. generated with "openssl rand -base64 32".
+//
+// Returns:
+// - nil without error for an empty value, which disables the signatures.
+// - An error if the value is not the base64 encoding of 32 bytes.
+func ParseSigningKey(encoded string) (ed25519.PrivateKey, error) {
+	encoded = strings.TrimSpace(encoded)
+	if encoded == "" {
+		return nil, nil
+	}
+
+	seed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, errors.New("the signing key must be 32 bytes encoded in base64")
+	}
+
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+// PublicKeyPEM returns the PEM-encoded (PKIX) public key of a signing key,
+// which tools like openssl use to verify its signatures.
+func PublicKeyPEM(key ed25519.PrivateKey) ([]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), nil
+}
@@ -0,0 +1,123 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+// minHashLength is the minimum length of the content hash of fingerprinted file names.
+const minHashLength = 8
+
+// spaHandler serves a single-page application from a file system.
+type spaHandler struct {
+	fsys       fs.FS
+	fileServer http.Handler
+}
+
+// NewSPAHandler creates a handler serving a single-page application.
+//
+// Existing files are served as is. Other paths without a file extension fall
+// back to index.html, so the client-side router handles them (history mode).
+// index.html is always revalidated, while fingerprinted assets are cached for a year.
+//
+// Parameters:
+// - fsys: The file system with the front-end bundle, with index.html at its root.
+//
+// Returns:
+// - The HTTP handler.
+func NewSPAHandler(fsys fs.FS) http.Handler {
+	return &spaHandler{fsys: fsys, fileServer: http.FileServer(http.FS(fsys))}
+}
+
+// ServeHTTP serves a file of the bundle, or index.html for client-side routes.
+func (h *spaHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/")
+	if name == "" {
+		name = "index.html"
+	}
+
+	info, err := fs.Stat(h.fsys, name)
+	if err == nil && info.IsDir() {
+		name = path.Join(name, "index.html")
+		_, err = fs.Stat(h.fsys, name)
+	}
+
+	switch {
+	case err == nil:
+	case errors.Is(err, fs.ErrNotExist) && path.Ext(name) == "":
+		name = "index.html"
+	default:
+		http.NotFound(w, r)
+
+		return
+	}
+
+	if path.Base(name) == "index.html" {
+		w.Header().Set("Cache-Control", "no-cache")
+		h.serveIndex(w, r, name)
+
+		return
+	}
+
+	if isFingerprinted(path.Base(name)) {
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	} else {
+		w.Header().Set("Cache-Control", "no-cache")
+	}
+
+	r2 := r.Clone(r.Context())
+	r2.URL.Path = "/" + name
+	h.fileServer.ServeHTTP(w, r2)
+}
+
+// serveIndex serves an index.html file directly, since http.FileServer
+// redirects requests for index.html to the directory. The ETag lets clients
+// revalidate it cheaply.
+func (h *spaHandler) serveIndex(w http.ResponseWriter, r *http.Request, name string) {
+	content, err := fs.ReadFile(h.fsys, name)
+	if err != nil {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	sum := sha256.Sum256(content)
+	w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:8])+`"`)
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	http.ServeContent(w, r, name, time.Time{}, bytes.NewReader(content))
+}
+
+// isFingerprinted reports whether a file name contains a content hash (e.g.
+// "app.3f9a2c1b.js" or "index-B2kx9Qa1.css"), so it can be cached forever:
+// its last "." or "-" separated part before the extension has at least
+// minHashLength letters, digits or underscores, including a digit.
+func isFingerprinted(base string) bool {
+	stem := strings.TrimSuffix(base, path.Ext(base))
+
+	hash := stem[strings.LastIndexAny(stem, ".-")+1:]
+	if len(hash) < minHashLength || len(hash) == len(stem) {
+		return false
+	}
+
+	hasDigit := false
+
+	for _, c := range hash {
+		switch {
+		case c >= '0' && c <= '9':
+			hasDigit = true
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c == '_':
+		default:
+			return false
+		}
+	}
+
+	return hasDigit
+}
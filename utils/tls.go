@@ -0,0 +1,144 @@
+package utils
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Client certificate modes of NewServerTLSConfig.
+const (
+	// ClientAuthOptional verifies client certificates when presented; clients
+	// without one can still use bearer tokens.
+	ClientAuthOptional = "optional"
+
+	// ClientAuthRequire rejects connections without a valid client certificate.
+	ClientAuthRequire = "require"
+)
+
+// NewServerTLSConfig creates the TLS configuration of the server.
+//
+// If a client CA file is given, mutual TLS is enabled and client certificates
+// are verified against it.
+//
+// Parameters:
+// - clientCAFile: PEM file with the CA certificates of the clients; empty disables mutual TLS.
+// - clientAuth: ClientAuthOptional or ClientAuthRequire.
+//
+// Returns:
+// - The TLS configuration, or an error if the CA file can't be read or the mode is unknown.
+func NewServerTLSConfig(clientCAFile, clientAuth string) (*tls.Config, error) {
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if clientCAFile == "" {
+		return cfg, nil
+	}
+
+	pem, err := os.ReadFile(clientCAFile)
+	if err != nil {
+		return nil, err
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, errors.New("no certificates found in " + clientCAFile)
+	}
+
+	cfg.ClientCAs = pool
+
+	switch clientAuth {
+	case ClientAuthOptional, "":
+		cfg.ClientAuth = tls.VerifyClientCertIfGiven
+	case ClientAuthRequire:
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	default:
+		return nil, fmt.Errorf("invalid client auth mode %q", clientAuth)
+	}
+
+	return cfg, nil
+}
+
+// Database TLS modes of NewDBTLSConfig.
+const (
+	// DBTLSVerify requires TLS and verifies the certificate and the name of the database server.
+	DBTLSVerify = "true"
+
+	// DBTLSSkipVerify requires TLS without verifying the database server, e.g.
+	// for managed services with self-signed certificates. The connection is
+	// encrypted but not protected from a man in the middle.
+	DBTLSSkipVerify = "skip-verify"
+)
+
+// NewDBTLSConfig creates the TLS configuration of the database connections.
+//
+// Parameters:
+// - mode: DBTLSVerify or DBTLSSkipVerify; empty disables TLS.
+// - caFile: PEM file with the CA certificates of the server; empty uses the system CAs.
+// - certFile: PEM file with the client certificate; empty presents no certificate.
+// - keyFile: PEM file with the private key of the client certificate.
+//
+// Returns:
+// - The TLS configuration, or nil if TLS is disabled.
+// - An error if the mode is unknown or a file can't be loaded.
+func NewDBTLSConfig(mode, caFile, certFile, keyFile string) (*tls.Config, error) {
+	if mode == "" {
+		if caFile != "" || certFile != "" {
+			return nil, errors.New("DB_TLS_CA_FILE and DB_TLS_CERT_FILE require DB_TLS")
+		}
+
+		return nil, nil
+	}
+
+	if mode != DBTLSVerify && mode != DBTLSSkipVerify {
+		return nil, fmt.Errorf("invalid DB_TLS mode %q, expected %q or %q", mode, DBTLSVerify, DBTLSSkipVerify)
+	}
+
+	// The MySQL driver fills ServerName with the database host when verifying
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12, InsecureSkipVerify: mode == DBTLSSkipVerify}
+
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, err
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New("no certificates found in " + caFile)
+		}
+
+		cfg.RootCAs = pool
+	}
+
+	if certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, err
+		}
+
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
+
+// CertificateNames returns the names identifying a client certificate: the
+// common name first, then the DNS, email and URI subject alternative names.
+func CertificateNames(cert *x509.Certificate) []string {
+	names := []string{}
+
+	if cert.Subject.CommonName != "" {
+		names = append(names, cert.Subject.CommonName)
+	}
+
+	names = append(names, cert.DNSNames...)
+	names = append(names, cert.EmailAddresses...)
+
+	for _, uri := range cert.URIs {
+		names = append(names, uri.String())
+	}
+
+	return names
+}
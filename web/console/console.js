@@ -0,0 +1,400 @@
+// Interactive API console: builds requests against the API with the filter
+// syntax of GET /{resource}, sends them with the stored token and saves them
+// as views (POST /views). A request is shared as a link to
+// /console/#resource?query, or as a shared view by admins.
+'use strict';
+
+const tokenKey = 'api-console-token';
+const listParams = ['sort', 'fields', 'page', 'per_page'];
+
+const $ = (id) => document.getElementById(id);
+
+let token = sessionStorage.getItem(tokenKey) || '';
+let username = '';
+
+// claims decodes the payload of a JWT, only to display who is logged in.
+function claims(jwt) {
+  try {
+    const payload = jwt.split('.')[1].replace(/-/g, '+').replace(/_/g, '/');
+    return JSON.parse(atob(payload));
+  } catch {
+    return {};
+  }
+}
+
+// api sends a request with the token, returning the response.
+function api(method, path, body) {
+  const headers = { Authorization: 'Bearer ' + token };
+  const init = { method, headers };
+
+  if (body !== undefined && body !== '') {
+    headers['Content-Type'] = 'application/json';
+    init.body = body;
+  }
+
+  return fetch(path, init);
+}
+
+// showError displays an error in the response panel.
+function showError(message) {
+  $('status').textContent = 'Error';
+  $('headers').textContent = '';
+  $('output').textContent = message;
+}
+
+// encodeQuery encodes query parameters, keeping the brackets of the filter
+// keys readable.
+function encodeQuery(params) {
+  return params
+    .map(([key, value]) => encodeURIComponent(key).replace(/%5B/g, '[').replace(/%5D/g, ']') + '=' +
+      encodeURIComponent(value))
+    .join('&');
+}
+
+// showAuthError displays an error of the authentication.
+function showAuthError(message) {
+  $('auth-error').textContent = message;
+  $('auth-error').hidden = false;
+}
+
+// buildQuery returns the query parameters of the builder.
+function buildQuery() {
+  const params = [];
+
+  for (const row of document.querySelectorAll('#filters .filter')) {
+    const field = row.querySelector('.field').value.trim();
+    const op = row.querySelector('.op').value;
+    const value = row.querySelector('.value').value;
+
+    if (field !== '') {
+      params.push([op === 'eq' ? `filter[${field}]` : `filter[${field}][${op}]`, value]);
+    }
+  }
+
+  const inputs = { sort: 'sort', fields: 'fields', page: 'page', per_page: 'per-page' };
+  for (const name of listParams) {
+    const value = $(inputs[name]).value.trim();
+    if (value !== '') {
+      params.push([name, value]);
+    }
+  }
+
+  return encodeQuery(params);
+}
+
+// buildPath returns the path and query of the request.
+function buildPath() {
+  const resource = $('resource').value;
+  const id = $('record-id').value.trim();
+  const query = buildQuery();
+
+  let path = '/' + encodeURIComponent(resource);
+  if (id !== '') {
+    path += '/' + encodeURIComponent(id);
+  }
+
+  return query === '' ? path : path + '?' + query;
+}
+
+// refreshURL updates the preview of the request.
+function refreshURL() {
+  $('url').textContent = $('method').value + ' ' + buildPath();
+}
+
+// addFilter adds a filter row to the builder.
+function addFilter(field = '', op = 'eq', value = '') {
+  const row = $('filter-row').content.firstElementChild.cloneNode(true);
+  const fieldInput = row.querySelector('.field');
+  const valueInput = row.querySelector('.value');
+
+  // Suggest the values of enum fields
+  const suggest = () => {
+    const list = 'enum-' + fieldInput.value.trim();
+    if (document.getElementById(list)) {
+      valueInput.setAttribute('list', list);
+    } else {
+      valueInput.removeAttribute('list');
+    }
+  };
+
+  fieldInput.value = field;
+  row.querySelector('.op').value = op;
+  valueInput.value = value;
+  suggest();
+
+  fieldInput.addEventListener('input', suggest);
+  row.querySelector('.remove').addEventListener('click', () => {
+    row.remove();
+    refreshURL();
+  });
+
+  $('filters').appendChild(row);
+  refreshURL();
+}
+
+// applyQuery fills the builder from a query string, e.g. of a saved view.
+function applyQuery(query) {
+  $('filters').replaceChildren();
+  $('sort').value = '';
+  $('fields').value = '';
+  $('page').value = '';
+  $('per-page').value = '';
+
+  for (const [key, value] of new URLSearchParams(query)) {
+    const filter = key.match(/^filter\[([^\]]+)\](?:\[([^\]]+)\])?$/);
+
+    if (filter) {
+      addFilter(filter[1], filter[2] || 'eq', value);
+    } else if (key === 'per_page') {
+      $('per-page').value = value;
+    } else if (listParams.includes(key)) {
+      $(key).value = value;
+    } else if (key !== 'view') {
+      addFilter(key, 'eq', value);
+    }
+  }
+
+  refreshURL();
+}
+
+// loadSchema reads the fields of a resource and the values of its enum fields.
+async function loadSchema(resource) {
+  $('field-names').replaceChildren();
+  $('enums').replaceChildren();
+
+  const res = await api('GET', '/' + encodeURIComponent(resource) + '/schema');
+  if (!res.ok) {
+    return;
+  }
+
+  const schema = await res.json();
+  for (const [name, property] of Object.entries(schema.properties || {})) {
+    const option = document.createElement('option');
+    option.value = name;
+    $('field-names').appendChild(option);
+
+    if (Array.isArray(property.enum)) {
+      const list = document.createElement('datalist');
+      list.id = 'enum-' + name;
+
+      for (const value of property.enum) {
+        const item = document.createElement('option');
+        item.value = value;
+        list.appendChild(item);
+      }
+
+      $('enums').appendChild(list);
+    }
+  }
+}
+
+// loadViews lists the saved views of the resource.
+async function loadViews() {
+  const res = await api('GET', '/views?resource=' + encodeURIComponent($('resource').value));
+  const views = res.ok ? await res.json() : [];
+
+  $('views').replaceChildren();
+
+  for (const view of views) {
+    const item = document.createElement('li');
+    const load = document.createElement('button');
+
+    load.type = 'button';
+    load.textContent = view.name;
+    load.title = view.query;
+    load.addEventListener('click', () => applyQuery(view.query));
+    item.appendChild(load);
+
+    if (view.shared) {
+      item.append(' shared by ' + view.owner);
+    }
+
+    if (view.owner === username) {
+      const remove = document.createElement('button');
+
+      remove.type = 'button';
+      remove.className = 'remove';
+      remove.textContent = '×';
+      remove.title = 'Delete the saved request';
+      remove.addEventListener('click', async () => {
+        await api('DELETE', '/views/' + encodeURIComponent(view.name));
+        loadViews();
+      });
+      item.appendChild(remove);
+    }
+
+    $('views').appendChild(item);
+  }
+}
+
+// selectResource switches the builder to a resource.
+async function selectResource(resource) {
+  $('resource').value = resource;
+  refreshURL();
+  await Promise.all([loadSchema(resource), loadViews()]);
+}
+
+// applyHash loads a shared link: /console/#resource?query.
+async function applyHash() {
+  const hash = location.hash.slice(1);
+  if (hash === '') {
+    return;
+  }
+
+  const [encoded, ...query] = hash.split('?');
+  const resource = decodeURIComponent(encoded);
+  if ([...$('resource').options].some((option) => option.value === resource)) {
+    await selectResource(resource);
+  }
+
+  applyQuery(query.join('?'));
+}
+
+// start shows the console once authenticated.
+async function start() {
+  const res = await api('GET', '/resources');
+  if (!res.ok) {
+    logout();
+    showAuthError('The token was rejected (HTTP ' + res.status + ').');
+
+    return;
+  }
+
+  $('auth-error').hidden = true;
+  username = claims(token).username || '';
+  $('whoami').textContent = username + ' (' + (claims(token).role || 'unknown role') + ')';
+  $('login').hidden = true;
+  $('session').hidden = false;
+  $('console').hidden = false;
+
+  $('resource').replaceChildren();
+  for (const resource of await res.json()) {
+    const option = document.createElement('option');
+    option.textContent = resource;
+    $('resource').appendChild(option);
+  }
+
+  await selectResource($('resource').value);
+  await applyHash();
+}
+
+// logout forgets the token.
+function logout() {
+  token = '';
+  sessionStorage.removeItem(tokenKey);
+  $('login').hidden = false;
+  $('session').hidden = true;
+  $('console').hidden = true;
+}
+
+// useToken stores a token and starts the console.
+function useToken(value) {
+  token = value;
+  sessionStorage.setItem(tokenKey, token);
+  start();
+}
+
+$('login').addEventListener('submit', async (event) => {
+  event.preventDefault();
+
+  const res = await fetch('/login', {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify({ username: $('username').value, password: $('password').value }),
+  });
+  const body = await res.json().catch(() => ({}));
+
+  $('password').value = '';
+
+  if (!res.ok || !body.token) {
+    showAuthError(body.error || 'Login failed (HTTP ' + res.status + ').');
+
+    return;
+  }
+
+  useToken(body.token);
+});
+
+$('use-token').addEventListener('click', () => {
+  if ($('token').value.trim() !== '') {
+    useToken($('token').value.trim());
+    $('token').value = '';
+  }
+});
+
+$('logout').addEventListener('click', logout);
+$('add-filter').addEventListener('click', () => addFilter());
+$('resource').addEventListener('change', () => selectResource($('resource').value));
+$('builder').addEventListener('input', refreshURL);
+$('builder').addEventListener('change', refreshURL);
+
+$('send').addEventListener('click', async () => {
+  $('status').textContent = '...';
+
+  let res;
+  try {
+    res = await api($('method').value, buildPath(), $('method').value === 'GET' ? undefined : $('body').value);
+  } catch (err) {
+    showError(String(err));
+
+    return;
+  }
+
+  const text = await res.text();
+  $('status').textContent = res.status + ' ' + res.statusText;
+  $('headers').textContent = [...res.headers].map(([name, value]) => name + ': ' + value).join('\n');
+
+  try {
+    $('output').textContent = JSON.stringify(JSON.parse(text), null, 2);
+  } catch {
+    $('output').textContent = text;
+  }
+});
+
+$('copy-curl').addEventListener('click', () => {
+  const method = $('method').value;
+  const quote = (value) => "'" + value.replace(/'/g, "'\\''") + "'";
+
+  let command = 'curl -X ' + method + ' ' + quote(location.origin + buildPath()) +
+    ' -H "Authorization: Bearer $TOKEN"';
+  if (method !== 'GET' && $('body').value.trim() !== '') {
+    command += " -H 'Content-Type: application/json' -d " + quote($('body').value);
+  }
+
+  navigator.clipboard.writeText(command);
+});
+
+$('copy-link').addEventListener('click', () => {
+  const query = buildQuery();
+  const hash = $('resource').value + (query === '' ? '' : '?' + query);
+
+  history.replaceState(null, '', '#' + hash);
+  navigator.clipboard.writeText(location.href);
+});
+
+$('save').addEventListener('submit', async (event) => {
+  event.preventDefault();
+
+  const res = await api('POST', '/views', JSON.stringify({
+    name: $('view-name').value.trim(),
+    resource: $('resource').value,
+    query: buildQuery(),
+    shared: $('view-shared').checked,
+  }));
+
+  if (!res.ok) {
+    const body = await res.json().catch(() => ({}));
+    showError(body.error || 'The request could not be saved (HTTP ' + res.status + ').');
+
+    return;
+  }
+
+  $('view-name').value = '';
+  loadViews();
+});
+
+window.addEventListener('hashchange', applyHash);
+
+if (token !== '') {
+  start();
+}
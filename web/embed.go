@@ -0,0 +1,38 @@
+// Package web embeds the front-end bundle served at / when STATIC_EMBED is set,
+// and the interactive API console served at /console/ when CONSOLE_ENABLED is set.
+//
+// Replace the contents of the dist directory with the build output of the
+// front-end (e.g. "npm run build") before compiling the binary. The console
+// is plain HTML and JavaScript, without a build step.
+package web
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed all:dist
+var dist embed.FS
+
+//go:embed console
+var console embed.FS
+
+// Bundle returns the embedded front-end bundle, rooted at the dist directory.
+func Bundle() fs.FS {
+	bundle, err := fs.Sub(dist, "dist")
+	if err != nil {
+		panic(err) // The directory is embedded at compile time
+	}
+
+	return bundle
+}
+
+// Console returns the embedded API console, rooted at the console directory.
+func Console() fs.FS {
+	bundle, err := fs.Sub(console, "console")
+	if err != nil {
+		panic(err) // The directory is embedded at compile time
+	}
+
+	return bundle
+}